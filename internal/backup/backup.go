@@ -0,0 +1,301 @@
+// Package backup creates scheduled archives of the storage directory and database
+// and uploads them to off-site targets (WebDAV, Dropbox, Google Drive, S3, SFTP).
+package backup
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/user/gitnotepad/internal/config"
+	"github.com/user/gitnotepad/internal/database"
+	"github.com/user/gitnotepad/internal/encoding"
+)
+
+// TargetStatus is the result of the last upload to a target
+type TargetStatus struct {
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	LastUpload *time.Time `json:"last_upload,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+}
+
+// Status describes the last backup run
+type Status struct {
+	Enabled     bool           `json:"enabled"`
+	Running     bool           `json:"running"`
+	LastRun     *time.Time     `json:"last_run,omitempty"`
+	LastArchive string         `json:"last_archive,omitempty"`
+	LastSize    int64          `json:"last_size,omitempty"`
+	LastError   string         `json:"last_error,omitempty"`
+	NextRun     *time.Time     `json:"next_run,omitempty"`
+	Targets     []TargetStatus `json:"targets"`
+}
+
+// Manager runs backups on a schedule and keeps their status
+type Manager struct {
+	config *config.Config
+	db     *database.DB
+	client *http.Client
+
+	mu     sync.Mutex
+	status Status
+}
+
+func New(cfg *config.Config, db *database.DB) *Manager {
+	m := &Manager{
+		config: cfg,
+		db:     db,
+		client: &http.Client{Timeout: 30 * time.Minute},
+	}
+	m.status.Enabled = cfg.Backup.Enabled
+	m.status.Targets = []TargetStatus{}
+	for _, t := range cfg.Backup.Targets {
+		m.status.Targets = append(m.status.Targets, TargetStatus{Name: t.Name, Type: t.Type})
+	}
+	return m
+}
+
+// Start runs backups every configured interval (no-op when backups are disabled)
+func (m *Manager) Start() {
+	if !m.config.Backup.Enabled {
+		return
+	}
+
+	interval := time.Duration(m.config.Backup.Interval) * time.Hour
+	go func() {
+		for {
+			next := time.Now().Add(interval)
+			m.mu.Lock()
+			m.status.NextRun = &next
+			m.mu.Unlock()
+
+			time.Sleep(interval)
+			if err := m.Run(); err != nil {
+				encoding.Warn("Backup failed: %v", err)
+			}
+		}
+	}()
+	encoding.Info("Backup scheduler started (every %d hours, %d targets)", m.config.Backup.Interval, len(m.config.Backup.Targets))
+}
+
+// Status returns a copy of the current backup status
+func (m *Manager) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := m.status
+	status.Targets = append([]TargetStatus{}, m.status.Targets...)
+	return status
+}
+
+// Run creates an archive and uploads it to every target
+// Upload failures are recorded per target; the error is only returned when the archive could not be created
+func (m *Manager) Run() error {
+	m.mu.Lock()
+	if m.status.Running {
+		m.mu.Unlock()
+		return fmt.Errorf("backup already running")
+	}
+	m.status.Running = true
+	m.mu.Unlock()
+
+	now := time.Now()
+	archivePath, size, err := m.createArchive(now)
+
+	m.mu.Lock()
+	m.status.LastRun = &now
+	if err != nil {
+		m.status.LastError = err.Error()
+		m.status.Running = false
+		m.mu.Unlock()
+		return err
+	}
+	m.status.LastArchive = filepath.Base(archivePath)
+	m.status.LastSize = size
+	m.status.LastError = ""
+	m.mu.Unlock()
+
+	encoding.Info("Backup created: %s (%d bytes)", archivePath, size)
+
+	secrets, err := LoadSecrets(m.config.Backup.SecretsFile)
+	if err != nil {
+		encoding.Warn("Failed to load backup secrets: %v", err)
+	}
+
+	for i, targetConfig := range m.config.Backup.Targets {
+		err := m.upload(targetConfig, secrets[targetConfig.Name], archivePath)
+
+		m.mu.Lock()
+		if err != nil {
+			m.status.Targets[i].LastError = err.Error()
+		} else {
+			uploaded := time.Now()
+			m.status.Targets[i].LastUpload = &uploaded
+			m.status.Targets[i].LastError = ""
+		}
+		m.mu.Unlock()
+
+		if err != nil {
+			encoding.Warn("Backup upload to %s failed: %v", targetConfig.Name, err)
+		} else {
+			encoding.Info("Backup uploaded to %s", targetConfig.Name)
+		}
+	}
+
+	m.pruneArchives()
+
+	m.mu.Lock()
+	m.status.Running = false
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *Manager) upload(targetConfig config.BackupTargetConfig, secret Secret, archivePath string) error {
+	target, err := newTarget(targetConfig, secret, m.client)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	return target.Upload(ctx, filepath.Base(archivePath), archivePath)
+}
+
+// createArchive zips the storage directory with a consistent database snapshot
+func (m *Manager) createArchive(now time.Time) (string, int64, error) {
+	backupDir, err := filepath.Abs(m.config.Backup.Dir)
+	if err != nil {
+		return "", 0, err
+	}
+	if err := os.MkdirAll(backupDir, 0700); err != nil {
+		return "", 0, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	storagePath, err := filepath.Abs(m.config.Storage.Path)
+	if err != nil {
+		return "", 0, err
+	}
+	dbPath, _ := filepath.Abs(m.config.Database.Path)
+
+	// VACUUM INTO copies the live database consistently; retry while a write holds the lock
+	snapshot := filepath.Join(backupDir, ".gitnotepad.db.snapshot")
+	for attempt := 1; ; attempt++ {
+		os.Remove(snapshot)
+		_, err = m.db.Exec("VACUUM INTO ?", snapshot)
+		if err == nil {
+			break
+		}
+		if attempt == 5 {
+			return "", 0, fmt.Errorf("failed to snapshot database: %w", err)
+		}
+		time.Sleep(time.Second)
+	}
+	defer os.Remove(snapshot)
+
+	name := fmt.Sprintf("gitnotepad-%s.zip", now.Format("20060102-150405"))
+	archivePath := filepath.Join(backupDir, name)
+	tmpPath := archivePath + ".tmp"
+
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create archive: %w", err)
+	}
+	zw := zip.NewWriter(file)
+
+	walkErr := filepath.WalkDir(storagePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path == backupDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// The database is added from the snapshot
+		if strings.HasPrefix(path, dbPath) {
+			return nil
+		}
+		relPath, err := filepath.Rel(storagePath, path)
+		if err != nil {
+			return nil
+		}
+		return addFile(zw, path, "storage/"+filepath.ToSlash(relPath))
+	})
+	if walkErr == nil {
+		walkErr = addFile(zw, snapshot, "gitnotepad.db")
+	}
+
+	if err := zw.Close(); err != nil && walkErr == nil {
+		walkErr = err
+	}
+	if err := file.Close(); err != nil && walkErr == nil {
+		walkErr = err
+	}
+	if walkErr != nil {
+		os.Remove(tmpPath)
+		return "", 0, fmt.Errorf("failed to write archive: %w", walkErr)
+	}
+
+	if err := os.Rename(tmpPath, archivePath); err != nil {
+		os.Remove(tmpPath)
+		return "", 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return "", 0, err
+	}
+	return archivePath, info.Size(), nil
+}
+
+func addFile(zw *zip.Writer, path, name string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return nil // Removed while walking
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return nil
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return err
+}
+
+// pruneArchives removes the oldest local archives beyond the configured count
+func (m *Manager) pruneArchives() {
+	matches, err := filepath.Glob(filepath.Join(m.config.Backup.Dir, "gitnotepad-*.zip"))
+	if err != nil || len(matches) <= m.config.Backup.Keep {
+		return
+	}
+
+	sort.Strings(matches) // Names sort by timestamp
+	for _, path := range matches[:len(matches)-m.config.Backup.Keep] {
+		if err := os.Remove(path); err != nil {
+			encoding.Warn("Failed to remove old backup %s: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	dropboxTokenURL  = "https://api.dropboxapi.com/oauth2/token"
+	dropboxUploadURL = "https://content.dropboxapi.com/2/files/upload_session"
+	dropboxChunkSize = 64 << 20 // Single uploads are limited to 150MB, so archives go through upload sessions
+)
+
+// dropboxTarget uploads archives into a Dropbox folder
+type dropboxTarget struct {
+	folder string
+	secret Secret
+	client *http.Client
+}
+
+func (t *dropboxTarget) Upload(ctx context.Context, name, path string) error {
+	token, err := accessToken(ctx, t.client, dropboxTokenURL, t.secret)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	type cursor struct {
+		SessionID string `json:"session_id"`
+		Offset    int64  `json:"offset"`
+	}
+
+	// Start the session with the first chunk
+	chunk := make([]byte, dropboxChunkSize)
+	n, err := io.ReadFull(file, chunk)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return err
+	}
+	resp, err := t.call(ctx, token, "/start", map[string]bool{"close": false}, chunk[:n])
+	if err != nil {
+		return fmt.Errorf("dropbox upload failed: %w", err)
+	}
+	var session struct {
+		SessionID string `json:"session_id"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&session)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("dropbox upload failed: %w", err)
+	}
+
+	offset := int64(n)
+	for {
+		n, err := io.ReadFull(file, chunk)
+		if n == 0 {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		arg := map[string]interface{}{"cursor": cursor{SessionID: session.SessionID, Offset: offset}, "close": false}
+		resp, err := t.call(ctx, token, "/append_v2", arg, chunk[:n])
+		if err != nil {
+			return fmt.Errorf("dropbox upload failed: %w", err)
+		}
+		resp.Body.Close()
+		offset += int64(n)
+	}
+
+	remotePath := "/" + strings.Trim(t.folder, "/") + "/" + name
+	remotePath = strings.Replace(remotePath, "//", "/", 1)
+	arg := map[string]interface{}{
+		"cursor": cursor{SessionID: session.SessionID, Offset: offset},
+		"commit": map[string]string{"path": remotePath, "mode": "overwrite"},
+	}
+	resp, err = t.call(ctx, token, "/finish", arg, nil)
+	if err != nil {
+		return fmt.Errorf("dropbox upload failed: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// call sends a content upload request with its JSON argument in the Dropbox-API-Arg header
+func (t *dropboxTarget) call(ctx context.Context, token, endpoint string, arg interface{}, body []byte) (*http.Response, error) {
+	argJSON, err := json.Marshal(arg)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dropboxUploadURL+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Dropbox-API-Arg", string(argJSON))
+	return doRequest(t.client, req)
+}
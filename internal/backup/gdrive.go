@@ -0,0 +1,82 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+const (
+	googleTokenURL  = "https://oauth2.googleapis.com/token"
+	googleUploadURL = "https://www.googleapis.com/upload/drive/v3/files?uploadType=resumable"
+)
+
+// googleDriveTarget uploads archives into a Google Drive folder (by folder ID, or My Drive root)
+type googleDriveTarget struct {
+	folderID string
+	secret   Secret
+	client   *http.Client
+}
+
+func (t *googleDriveTarget) Upload(ctx context.Context, name, path string) error {
+	token, err := accessToken(ctx, t.client, googleTokenURL, t.secret)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	// Resumable upload: create the session with metadata, then send the file
+	metadata := map[string]interface{}{"name": name}
+	if t.folderID != "" {
+		metadata["parents"] = []string{t.folderID}
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, googleUploadURL, bytes.NewReader(metadataJSON))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("X-Upload-Content-Type", "application/zip")
+
+	resp, err := doRequest(t.client, req)
+	if err != nil {
+		return fmt.Errorf("google drive upload failed: %w", err)
+	}
+	resp.Body.Close()
+	sessionURL := resp.Header.Get("Location")
+	if sessionURL == "" {
+		return fmt.Errorf("google drive upload failed: no upload session")
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodPut, sessionURL, file)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/zip")
+
+	resp, err = doRequest(t.client, req)
+	if err != nil {
+		return fmt.Errorf("google drive upload failed: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
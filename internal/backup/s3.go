@@ -0,0 +1,250 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3DefaultRegion = "us-east-1"
+	s3PartSize      = 64 << 20 // Archives larger than this go through a multipart upload (single PUTs stop at 5GB)
+)
+
+// s3Target uploads archives into an S3 bucket, or any S3-compatible storage (MinIO, Backblaze B2, Cloudflare R2, ...)
+// Requests use path-style addressing and AWS Signature Version 4
+type s3Target struct {
+	endpoint string // Scheme and host, e.g. "https://s3.eu-west-1.amazonaws.com"
+	bucket   string
+	region   string
+	prefix   string // Key prefix ("folder") the archives are stored under
+	secret   Secret
+	client   *http.Client
+}
+
+func newS3Target(endpoint, bucket, region, prefix string, secret Secret, client *http.Client) *s3Target {
+	if region == "" {
+		region = s3DefaultRegion
+	}
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	prefix = strings.Trim(prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &s3Target{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		bucket:   bucket,
+		region:   region,
+		prefix:   prefix,
+		secret:   secret,
+		client:   client,
+	}
+}
+
+func (t *s3Target) Upload(ctx context.Context, name, path string) error {
+	if t.secret.AccessKeyID == "" || t.secret.SecretAccessKey == "" {
+		return fmt.Errorf("no access_key_id or secret_access_key in secrets file")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	key := t.prefix + name
+	if info.Size() <= s3PartSize {
+		resp, err := t.do(ctx, http.MethodPut, key, nil, file, info.Size())
+		if err != nil {
+			return fmt.Errorf("s3 upload failed: %w", err)
+		}
+		resp.Body.Close()
+		return nil
+	}
+	if err := t.uploadParts(ctx, key, file, info.Size()); err != nil {
+		return fmt.Errorf("s3 upload failed: %w", err)
+	}
+	return nil
+}
+
+// uploadParts uploads a large archive in parts; an unfinished upload is aborted so the bucket isn't billed for it
+func (t *s3Target) uploadParts(ctx context.Context, key string, file *os.File, size int64) error {
+	resp, err := t.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, 0)
+	if err != nil {
+		return err
+	}
+	var initiate struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err = xml.NewDecoder(resp.Body).Decode(&initiate)
+	resp.Body.Close()
+	if err != nil || initiate.UploadID == "" {
+		return fmt.Errorf("invalid response to multipart upload request")
+	}
+
+	type part struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	}
+	var parts []part
+	err = func() error {
+		for offset, number := int64(0), 1; offset < size; offset, number = offset+s3PartSize, number+1 {
+			length := min(s3PartSize, size-offset)
+			query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {initiate.UploadID}}
+			resp, err := t.do(ctx, http.MethodPut, key, query, io.NewSectionReader(file, offset, length), length)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			parts = append(parts, part{PartNumber: number, ETag: resp.Header.Get("ETag")})
+		}
+
+		body, err := xml.Marshal(struct {
+			XMLName xml.Name `xml:"CompleteMultipartUpload"`
+			Parts   []part   `xml:"Part"`
+		}{Parts: parts})
+		if err != nil {
+			return err
+		}
+		resp, err := t.do(ctx, http.MethodPost, key, url.Values{"uploadId": {initiate.UploadID}}, bytes.NewReader(body), int64(len(body)))
+		if err != nil {
+			return err
+		}
+		// Completion can fail after a 200 status; the error is then in the body
+		var result struct {
+			XMLName xml.Name
+			Message string `xml:"Message"`
+		}
+		decodeErr := xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if decodeErr == nil && result.XMLName.Local == "Error" {
+			return fmt.Errorf("failed to complete multipart upload: %s", result.Message)
+		}
+		return nil
+	}()
+
+	if err != nil {
+		if resp, abortErr := t.do(context.Background(), http.MethodDelete, key, url.Values{"uploadId": {initiate.UploadID}}, nil, 0); abortErr == nil {
+			resp.Body.Close()
+		}
+	}
+	return err
+}
+
+// do sends a signed request for an object of the bucket
+func (t *s3Target) do(ctx context.Context, method, key string, query url.Values, body io.Reader, length int64) (*http.Response, error) {
+	path := "/" + s3Escape(t.bucket) + "/" + s3Escape(key)
+	rawQuery := s3Query(query)
+	target := t.endpoint + path
+	if rawQuery != "" {
+		target += "?" + rawQuery
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = length
+	}
+	if method == http.MethodPut && query == nil {
+		req.Header.Set("Content-Type", "application/zip")
+	}
+	t.sign(req, path, rawQuery, time.Now().UTC())
+	return doRequest(t.client, req)
+}
+
+// sign adds an AWS Signature Version 4 Authorization header
+// The payload is not hashed (UNSIGNED-PAYLOAD), so archives are streamed without being read twice
+func (t *s3Target) sign(req *http.Request, path, rawQuery string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + t.region + "/s3/aws4_request"
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": "UNSIGNED-PAYLOAD",
+		"x-amz-date":           amzDate,
+	}
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		headers["content-type"] = contentType
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method, path, rawQuery, canonicalHeaders.String(), signedHeaders, "UNSIGNED-PAYLOAD",
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + t.secret.SecretAccessKey)
+	for _, part := range []string{now.Format("20060102"), t.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		t.secret.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3Escape encodes a key for the canonical request: everything but unreserved characters, keeping "/"
+func s3Escape(key string) string {
+	var sb strings.Builder
+	for _, b := range []byte(key) {
+		if b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || strings.IndexByte("-_.~/", b) >= 0 {
+			sb.WriteByte(b)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", b)
+		}
+	}
+	return sb.String()
+}
+
+// s3Query returns the canonical query string: parameters sorted by name, each encoded like a key
+func s3Query(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var params []string
+	for _, name := range names {
+		params = append(params, strings.ReplaceAll(s3Escape(name), "/", "%2F")+"="+strings.ReplaceAll(s3Escape(query.Get(name)), "/", "%2F"))
+	}
+	return strings.Join(params, "&")
+}
@@ -0,0 +1,50 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Secret holds the credentials of a backup target
+// WebDAV uses username/password; Dropbox and Google Drive use an access token
+// or a refresh token with the OAuth client ID and secret; S3 uses an access key;
+// SFTP uses the username with a private key (optionally with its passphrase) or a password
+type Secret struct {
+	Username        string `yaml:"username,omitempty"`
+	Password        string `yaml:"password,omitempty"`
+	AccessToken     string `yaml:"access_token,omitempty"`
+	RefreshToken    string `yaml:"refresh_token,omitempty"`
+	ClientID        string `yaml:"client_id,omitempty"`
+	ClientSecret    string `yaml:"client_secret,omitempty"`
+	AccessKeyID     string `yaml:"access_key_id,omitempty"`
+	SecretAccessKey string `yaml:"secret_access_key,omitempty"`
+	PrivateKey      string `yaml:"private_key,omitempty"` // PEM, e.g. the contents of ~/.ssh/id_ed25519
+	Passphrase      string `yaml:"passphrase,omitempty"`
+}
+
+type secretsFile struct {
+	Backup map[string]Secret `yaml:"backup"` // Target name -> credentials
+}
+
+// LoadSecrets reads target credentials from the secrets file (kept out of config.yaml)
+// A missing file is not an error
+func LoadSecrets(path string) (map[string]Secret, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]Secret{}, nil
+		}
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	var secrets secretsFile
+	if err := yaml.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+	if secrets.Backup == nil {
+		secrets.Backup = map[string]Secret{}
+	}
+	return secrets.Backup, nil
+}
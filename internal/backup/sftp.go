@@ -0,0 +1,129 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// sftpTarget uploads archives into a directory of an SSH server
+// The server's host key must match the configured fingerprint ("SHA256:...", as printed by ssh-keygen -lf)
+type sftpTarget struct {
+	address string // host:port
+	dir     string
+	hostKey string
+	secret  Secret
+}
+
+func newSFTPTarget(rawURL, dir, hostKey string, secret Secret) *sftpTarget {
+	address := strings.TrimSuffix(strings.TrimPrefix(rawURL, "sftp://"), "/")
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "22")
+	}
+	if dir == "" {
+		dir = "."
+	}
+	return &sftpTarget{address: address, dir: dir, hostKey: hostKey, secret: secret}
+}
+
+func (t *sftpTarget) Upload(ctx context.Context, name, filePath string) error {
+	auth, err := t.auth()
+	if err != nil {
+		return err
+	}
+	sshConfig := &ssh.ClientConfig{
+		User: t.secret.Username,
+		Auth: auth,
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			if fingerprint := ssh.FingerprintSHA256(key); fingerprint != t.hostKey {
+				return fmt.Errorf("host key %s does not match host_key", fingerprint)
+			}
+			return nil
+		},
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", t.address)
+	if err != nil {
+		return fmt.Errorf("sftp upload failed: %w", err)
+	}
+	// Closing the connection ends a transfer that outlives the context
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, t.address, sshConfig)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("sftp upload failed: %w", err)
+	}
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+	defer sshClient.Close()
+
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		return fmt.Errorf("sftp upload failed: %w", err)
+	}
+	defer client.Close()
+
+	if err := client.MkdirAll(t.dir); err != nil {
+		return fmt.Errorf("sftp upload failed: %w", err)
+	}
+
+	src, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	// Upload under a temporary name so an interrupted transfer never looks like a complete archive
+	remotePath := path.Join(t.dir, name)
+	partPath := remotePath + ".part"
+	dst, err := client.Create(partPath)
+	if err != nil {
+		return fmt.Errorf("sftp upload failed: %w", err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		client.Remove(partPath)
+		return fmt.Errorf("sftp upload failed: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		client.Remove(partPath)
+		return fmt.Errorf("sftp upload failed: %w", err)
+	}
+	if err := client.PosixRename(partPath, remotePath); err != nil {
+		if err := client.Rename(partPath, remotePath); err != nil {
+			client.Remove(partPath)
+			return fmt.Errorf("sftp upload failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// auth returns the SSH authentication methods: the private key when given, otherwise the password
+func (t *sftpTarget) auth() ([]ssh.AuthMethod, error) {
+	if t.secret.PrivateKey != "" {
+		var signer ssh.Signer
+		var err error
+		if t.secret.Passphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(t.secret.PrivateKey), []byte(t.secret.Passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey([]byte(t.secret.PrivateKey))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid private_key in secrets file: %w", err)
+		}
+		return []ssh.AuthMethod{ssh.PublicKeys(signer)}, nil
+	}
+	if t.secret.Password != "" {
+		return []ssh.AuthMethod{ssh.Password(t.secret.Password)}, nil
+	}
+	return nil, fmt.Errorf("no private_key or password in secrets file")
+}
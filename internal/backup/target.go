@@ -0,0 +1,97 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/user/gitnotepad/internal/config"
+)
+
+// Target uploads backup archives to an off-site location
+type Target interface {
+	Upload(ctx context.Context, name, path string) error
+}
+
+func newTarget(cfg config.BackupTargetConfig, secret Secret, client *http.Client) (Target, error) {
+	switch cfg.Type {
+	case "webdav":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("webdav target %s has no url", cfg.Name)
+		}
+		return &webDAVTarget{url: cfg.URL, secret: secret, client: client}, nil
+	case "dropbox":
+		return &dropboxTarget{folder: cfg.Folder, secret: secret, client: client}, nil
+	case "gdrive":
+		return &googleDriveTarget{folderID: cfg.Folder, secret: secret, client: client}, nil
+	case "s3":
+		if cfg.Bucket == "" {
+			return nil, fmt.Errorf("s3 target %s has no bucket", cfg.Name)
+		}
+		return newS3Target(cfg.URL, cfg.Bucket, cfg.Region, cfg.Folder, secret, client), nil
+	case "sftp":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("sftp target %s has no url", cfg.Name)
+		}
+		if cfg.HostKey == "" {
+			return nil, fmt.Errorf("sftp target %s has no host_key", cfg.Name)
+		}
+		return newSFTPTarget(cfg.URL, cfg.Folder, cfg.HostKey, secret), nil
+	default:
+		return nil, fmt.Errorf("unknown backup target type: %s", cfg.Type)
+	}
+}
+
+// doRequest sends a request and returns the response, or an error for non-2xx status codes
+func doRequest(client *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Host, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// accessToken returns the configured access token, or exchanges the refresh token for a new one
+func accessToken(ctx context.Context, client *http.Client, tokenURL string, secret Secret) (string, error) {
+	if secret.RefreshToken == "" {
+		if secret.AccessToken == "" {
+			return "", fmt.Errorf("no access token or refresh token in secrets file")
+		}
+		return secret.AccessToken, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {secret.RefreshToken},
+		"client_id":     {secret.ClientID},
+		"client_secret": {secret.ClientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := doRequest(client, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("failed to refresh access token: invalid response")
+	}
+	return token.AccessToken, nil
+}
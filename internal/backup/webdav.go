@@ -0,0 +1,61 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// webDAVTarget uploads archives into a WebDAV collection (Nextcloud, ownCloud, NAS, ...)
+type webDAVTarget struct {
+	url    string
+	secret Secret
+	client *http.Client
+}
+
+func (t *webDAVTarget) Upload(ctx context.Context, name, path string) error {
+	collection := strings.TrimSuffix(t.url, "/") + "/"
+
+	// Create the collection if needed; 405 means it already exists
+	req, err := http.NewRequestWithContext(ctx, "MKCOL", collection, nil)
+	if err != nil {
+		return err
+	}
+	t.authorize(req)
+	if resp, err := t.client.Do(req); err == nil {
+		resp.Body.Close()
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodPut, collection+name, file)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/zip")
+	t.authorize(req)
+
+	resp, err := doRequest(t.client, req)
+	if err != nil {
+		return fmt.Errorf("webdav upload failed: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (t *webDAVTarget) authorize(req *http.Request) {
+	if t.secret.Username != "" || t.secret.Password != "" {
+		req.SetBasicAuth(t.secret.Username, t.secret.Password)
+	}
+}
@@ -0,0 +1,745 @@
+// Package clip extracts the readable part of a web page and converts it to markdown.
+// Scripts, navigation, sidebars and comment sections are dropped and the block holding most
+// of the paragraph text is kept, in the manner of Mozilla's Readability.
+package clip
+
+import (
+	"errors"
+	"io"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// ErrNoContent is returned when a page has no readable text
+var ErrNoContent = errors.New("no readable content found")
+
+var (
+	unlikelyPattern = regexp.MustCompile(`(?i)-ad-|ai2html|banner|breadcrumbs|combx|comment|community|cover-wrap|disqus|extra|footer|gdpr|header|legends|menu|related|remark|replies|rss|shoutbox|sidebar|skyscraper|social|sponsor|supplemental|ad-break|agegate|pagination|pager|popup|yom-remote`)
+	maybePattern    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positivePattern = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|pagination|post|text|blog|story`)
+	negativePattern = regexp.MustCompile(`(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+	spacePattern    = regexp.MustCompile(`\s+`)
+	blankLines      = regexp.MustCompile(`\n{3,}`)
+	mdEscaper       = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", "&lt;", ">", "&gt;")
+)
+
+// Tags removed before scoring; they never hold article text
+var removedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true, atom.Form: true,
+	atom.Nav: true, atom.Aside: true, atom.Footer: true, atom.Button: true, atom.Input: true,
+	atom.Select: true, atom.Textarea: true, atom.Svg: true, atom.Canvas: true, atom.Object: true,
+	atom.Embed: true, atom.Template: true, atom.Link: true, atom.Meta: true, atom.Dialog: true,
+}
+
+// Tags rendered as blocks; everything else is inline text
+var blockTags = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Blockquote: true, atom.Dd: true, atom.Details: true,
+	atom.Div: true, atom.Dl: true, atom.Dt: true, atom.Figcaption: true, atom.Figure: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Header: true, atom.Hr: true, atom.Li: true, atom.Main: true, atom.Ol: true, atom.P: true,
+	atom.Pre: true, atom.Section: true, atom.Summary: true, atom.Table: true, atom.Ul: true,
+}
+
+// Article is the readable content of a page
+type Article struct {
+	Title     string
+	Byline    string
+	SiteName  string
+	Excerpt   string
+	Published time.Time // Zero when the page does not say
+	Markdown  string
+	Images    []string // Absolute URLs of the images in Markdown, in order of appearance
+}
+
+// Extract parses an HTML page and returns its readable content
+// contentType is the Content-Type of the response, used to decode non-UTF-8 pages;
+// pageURL resolves relative links and images
+func Extract(r io.Reader, contentType string, pageURL *url.URL) (*Article, error) {
+	reader, err := charset.NewReader(r, contentType)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := html.Parse(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	article := &Article{}
+	base := readMeta(doc, pageURL, article)
+
+	body := findFirst(doc, atom.Body)
+	if body == nil {
+		return nil, ErrNoContent
+	}
+	prune(body)
+
+	c := &converter{base: base, seen: make(map[string]bool)}
+	var sb strings.Builder
+	for _, n := range contentNodes(body) {
+		c.block(&sb, n)
+	}
+
+	markdown := blankLines.ReplaceAllString(strings.TrimSpace(sb.String()), "\n\n")
+	if article.Title == "" {
+		if h1 := findFirst(body, atom.H1); h1 != nil {
+			article.Title = innerText(h1)
+		}
+	}
+	// The note title already shows the heading the article starts with
+	if first, rest, _ := strings.Cut(markdown, "\n"); strings.HasPrefix(first, "# ") &&
+		strings.EqualFold(strings.TrimSpace(first[2:]), mdEscaper.Replace(article.Title)) {
+		markdown = strings.TrimSpace(rest)
+	}
+	if markdown == "" {
+		return nil, ErrNoContent
+	}
+
+	article.Markdown = markdown
+	article.Images = c.images
+	return article, nil
+}
+
+// Markdown converts the children of an HTML node, such as the body of a note exported by another app,
+// to markdown without picking a readable part; rewrite maps each link and image reference
+// to the URL written ("" drops the link and keeps its text)
+func Markdown(n *html.Node, rewrite func(ref string) string) string {
+	c := &converter{rewrite: rewrite, seen: make(map[string]bool)}
+	var sb strings.Builder
+	c.children(&sb, n)
+	return blankLines.ReplaceAllString(strings.TrimSpace(sb.String()), "\n\n")
+}
+
+// readMeta fills the title, byline, site name, excerpt and date from the head and returns
+// the URL relative links resolve against (<base href> when present)
+func readMeta(doc *html.Node, pageURL *url.URL, article *Article) *url.URL {
+	base := pageURL
+	var docTitle string
+	meta := make(map[string]string)
+
+	walk(doc, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Title:
+			if docTitle == "" {
+				docTitle = innerText(n)
+			}
+		case atom.Base:
+			if href := attr(n, "href"); href != "" && base == pageURL {
+				if u, err := pageURL.Parse(href); err == nil {
+					base = u
+				}
+			}
+		case atom.Meta:
+			key := strings.ToLower(attr(n, "property"))
+			if key == "" {
+				key = strings.ToLower(attr(n, "name"))
+			}
+			if content := strings.TrimSpace(attr(n, "content")); key != "" && content != "" && meta[key] == "" {
+				meta[key] = content
+			}
+		case atom.Body:
+			return false
+		}
+		return true
+	})
+
+	article.SiteName = firstOf(meta["og:site_name"], meta["application-name"])
+	article.Byline = firstOf(meta["author"], meta["article:author"], meta["twitter:creator"])
+	article.Excerpt = firstOf(meta["og:description"], meta["description"], meta["twitter:description"])
+	article.Title = firstOf(meta["og:title"], meta["twitter:title"])
+	if article.Title == "" {
+		article.Title = trimSiteName(docTitle, article.SiteName)
+	}
+	for _, key := range []string{"article:published_time", "og:published_time", "date", "dc.date"} {
+		if t, err := time.Parse(time.RFC3339, meta[key]); err == nil {
+			article.Published = t
+			break
+		}
+	}
+	return base
+}
+
+// trimSiteName removes a " | Site" or " - Site" suffix from a document title
+func trimSiteName(title, siteName string) string {
+	for _, sep := range []string{" | ", " - ", " — ", " · ", " :: "} {
+		if i := strings.LastIndex(title, sep); i > 0 {
+			suffix := strings.TrimSpace(title[i+len(sep):])
+			if siteName == "" || strings.EqualFold(suffix, siteName) {
+				return strings.TrimSpace(title[:i])
+			}
+		}
+	}
+	return title
+}
+
+// prune removes elements that never hold article text: scripts, navigation, hidden elements
+// and blocks whose class or id look like comments, sidebars or ads
+func prune(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		switch {
+		case child.Type == html.CommentNode:
+			n.RemoveChild(child)
+		case child.Type == html.ElementNode && shouldRemove(child):
+			n.RemoveChild(child)
+		default:
+			prune(child)
+		}
+		child = next
+	}
+}
+
+func shouldRemove(n *html.Node) bool {
+	if removedTags[n.DataAtom] {
+		return true
+	}
+	if _, hidden := attrOK(n, "hidden"); hidden || attr(n, "aria-hidden") == "true" ||
+		strings.Contains(strings.ReplaceAll(attr(n, "style"), " ", ""), "display:none") {
+		return true
+	}
+	if attr(n, "role") == "navigation" || attr(n, "role") == "complementary" {
+		return true
+	}
+	switch n.DataAtom {
+	case atom.Article, atom.Main, atom.Body, atom.A, atom.Table, atom.Tbody, atom.Tr, atom.Td, atom.Th:
+		return false
+	}
+	match := attr(n, "class") + " " + attr(n, "id")
+	return unlikelyPattern.MatchString(match) && !maybePattern.MatchString(match)
+}
+
+// contentNodes returns the nodes that make up the article: the highest scoring block and
+// the siblings next to it that also look like content
+func contentNodes(body *html.Node) []*html.Node {
+	scores := make(map[*html.Node]float64)
+	var candidates []*html.Node
+
+	addScore := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(n)
+			candidates = append(candidates, n)
+		}
+		scores[n] += score
+	}
+
+	walk(body, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.P, atom.Td, atom.Pre, atom.Blockquote:
+		default:
+			return true
+		}
+		text := innerText(n)
+		if len([]rune(text)) < 25 {
+			return false
+		}
+		score := 1 + float64(strings.Count(text, ",")+strings.Count(text, "，")+strings.Count(text, "、"))
+		score += math.Min(float64(len([]rune(text)))/100, 3)
+		addScore(n.Parent, score)
+		if n.Parent != nil {
+			addScore(n.Parent.Parent, score/2)
+		}
+		return false
+	})
+
+	var top *html.Node
+	topScore := 0.0
+	for _, n := range candidates {
+		scores[n] *= 1 - linkDensity(n)
+		if scores[n] > topScore {
+			top, topScore = n, scores[n]
+		}
+	}
+	if top == nil {
+		// No paragraphs: fall back to <article>, <main> or the whole body
+		for _, a := range []atom.Atom{atom.Article, atom.Main} {
+			if n := findFirst(body, a); n != nil {
+				return []*html.Node{n}
+			}
+		}
+		return []*html.Node{body}
+	}
+	if top.DataAtom == atom.Body || top.Parent == nil {
+		return []*html.Node{top}
+	}
+
+	threshold := math.Max(10, topScore*0.2)
+	var nodes []*html.Node
+	for sibling := top.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		if sibling.Type != html.ElementNode {
+			continue
+		}
+		include := sibling == top
+		if score, ok := scores[sibling]; ok && score >= threshold {
+			include = true
+		}
+		if sibling.DataAtom == atom.P {
+			text := innerText(sibling)
+			if len([]rune(text)) > 80 && linkDensity(sibling) < 0.25 {
+				include = true
+			}
+		}
+		if include {
+			nodes = append(nodes, sibling)
+		}
+	}
+	return nodes
+}
+
+// initialScore weighs a candidate by its tag and by its class and id
+func initialScore(n *html.Node) float64 {
+	score := 0.0
+	switch n.DataAtom {
+	case atom.Div, atom.Article, atom.Main:
+		score += 5
+	case atom.Pre, atom.Td, atom.Blockquote, atom.Section:
+		score += 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		score -= 3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score -= 5
+	}
+	for _, name := range []string{attr(n, "class"), attr(n, "id")} {
+		if name == "" {
+			continue
+		}
+		if negativePattern.MatchString(name) {
+			score -= 25
+		}
+		if positivePattern.MatchString(name) {
+			score += 25
+		}
+	}
+	return score
+}
+
+// linkDensity is the share of a node's text that is inside links
+func linkDensity(n *html.Node) float64 {
+	total := len(innerText(n))
+	if total == 0 {
+		return 0
+	}
+	linked := 0
+	walk(n, func(c *html.Node) bool {
+		if c.DataAtom == atom.A {
+			linked += len(innerText(c))
+			return false
+		}
+		return true
+	})
+	return float64(linked) / float64(total)
+}
+
+// converter renders HTML nodes as markdown and collects the images it meets
+type converter struct {
+	base    *url.URL
+	rewrite func(ref string) string // Replaces resolving against base when set
+	images  []string
+	seen    map[string]bool
+}
+
+// children renders the children of a block, grouping runs of inline content into paragraphs
+func (c *converter) children(sb *strings.Builder, n *html.Node) {
+	var inline strings.Builder
+	flush := func() {
+		paragraph(sb, cleanInline(inline.String()))
+		inline.Reset()
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && blockTags[child.DataAtom] {
+			flush()
+			c.block(sb, child)
+		} else {
+			inline.WriteString(c.inline(child))
+		}
+	}
+	flush()
+}
+
+// block renders a block element followed by a blank line
+func (c *converter) block(sb *strings.Builder, n *html.Node) {
+	if n.Type != html.ElementNode || !blockTags[n.DataAtom] {
+		paragraph(sb, cleanInline(c.inline(n)))
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		if text := strings.TrimSpace(c.inlineChildren(n)); text != "" {
+			paragraph(sb, strings.Repeat("#", level)+" "+strings.ReplaceAll(text, "\n", " "))
+		}
+	case atom.P, atom.Dt, atom.Summary:
+		paragraph(sb, cleanInline(c.inlineChildren(n)))
+	case atom.Figcaption:
+		if text := strings.TrimSpace(c.inlineChildren(n)); text != "" {
+			paragraph(sb, "*"+text+"*")
+		}
+	case atom.Hr:
+		paragraph(sb, "---")
+	case atom.Pre:
+		c.pre(sb, n)
+	case atom.Blockquote:
+		var inner strings.Builder
+		c.children(&inner, n)
+		if text := strings.TrimSpace(inner.String()); text != "" {
+			lines := strings.Split(text, "\n")
+			for i, line := range lines {
+				lines[i] = strings.TrimRight("> "+line, " ")
+			}
+			paragraph(sb, strings.Join(lines, "\n"))
+		}
+	case atom.Ul, atom.Ol:
+		c.list(sb, n)
+	case atom.Table:
+		c.table(sb, n)
+	default:
+		c.children(sb, n)
+	}
+}
+
+// pre renders preformatted text as a fenced code block
+func (c *converter) pre(sb *strings.Builder, n *html.Node) {
+	code := strings.Trim(textContent(n), "\n")
+	if strings.TrimSpace(code) == "" {
+		return
+	}
+	lang := ""
+	for _, node := range []*html.Node{n, findFirst(n, atom.Code)} {
+		if node == nil {
+			continue
+		}
+		for _, class := range strings.Fields(attr(node, "class")) {
+			if l, ok := strings.CutPrefix(class, "language-"); ok {
+				lang = l
+			} else if l, ok := strings.CutPrefix(class, "lang-"); ok {
+				lang = l
+			}
+		}
+	}
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	paragraph(sb, fence+lang+"\n"+code+"\n"+fence)
+}
+
+// list renders ul/ol items; nested lists are indented under their item
+// Other elements in the list (misplaced by broken markup) are rendered as blocks after it
+func (c *converter) list(sb *strings.Builder, n *html.Node) {
+	var items []string
+	flush := func() {
+		paragraph(sb, strings.Join(items, "\n"))
+		items = nil
+	}
+	number := 1
+	if start := attr(n, "start"); start != "" {
+		if v, err := strconv.Atoi(start); err == nil {
+			number = v
+		}
+	}
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li {
+			if li.Type == html.ElementNode || strings.TrimSpace(li.Data) != "" {
+				flush()
+				c.block(sb, li)
+			}
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+
+		var inner strings.Builder
+		c.children(&inner, li)
+		text := strings.TrimSpace(inner.String())
+		if !strings.Contains(text, "```") {
+			text = blankLines.ReplaceAllString(strings.ReplaceAll(text, "\n\n", "\n"), "\n")
+		}
+		indent := strings.Repeat(" ", len(marker))
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			if i > 0 && line != "" {
+				lines[i] = indent + line
+			}
+		}
+		items = append(items, marker+strings.Join(lines, "\n"))
+	}
+	flush()
+}
+
+// table renders a data table as a markdown table; layout tables are rendered as blocks
+func (c *converter) table(sb *strings.Builder, n *html.Node) {
+	var rows [][]string
+	layout := false
+	walk(n, func(node *html.Node) bool {
+		if node != n && node.DataAtom == atom.Table {
+			layout = true
+			return false
+		}
+		if node.DataAtom == atom.Tr {
+			var cells []string
+			for cell := node.FirstChild; cell != nil; cell = cell.NextSibling {
+				if cell.DataAtom != atom.Td && cell.DataAtom != atom.Th {
+					continue
+				}
+				walk(cell, func(inner *html.Node) bool {
+					if inner != cell && inner.Type == html.ElementNode && blockTags[inner.DataAtom] && inner.DataAtom != atom.P {
+						layout = true
+					}
+					return true
+				})
+				text := strings.ReplaceAll(strings.TrimSpace(c.inlineChildren(cell)), "\n", " ")
+				cells = append(cells, strings.ReplaceAll(text, "|", `\|`))
+			}
+			if len(cells) > 0 {
+				rows = append(rows, cells)
+			}
+			return false
+		}
+		return true
+	})
+
+	if layout || len(rows) == 0 {
+		c.children(sb, n)
+		return
+	}
+
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	var lines []string
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	paragraph(sb, strings.Join(lines, "\n"))
+}
+
+// inline renders inline content; whitespace is collapsed as a browser would
+func (c *converter) inline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return mdEscaper.Replace(spacePattern.ReplaceAllString(n.Data, " "))
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		return "  \n"
+	case atom.Strong, atom.B:
+		return wrap(c.inlineChildren(n), "**")
+	case atom.Em, atom.I, atom.Cite:
+		return wrap(c.inlineChildren(n), "*")
+	case atom.Del, atom.S, atom.Strike:
+		return wrap(c.inlineChildren(n), "~~")
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		code := spacePattern.ReplaceAllString(textContent(n), " ")
+		if strings.TrimSpace(code) == "" {
+			return code
+		}
+		tick := "`"
+		for strings.Contains(code, tick) {
+			tick += "`"
+		}
+		return tick + code + tick
+	case atom.A:
+		text := c.inlineChildren(n)
+		href := c.resolve(attr(n, "href"))
+		if href == "" || strings.TrimSpace(text) == "" {
+			return text
+		}
+		return wrapSpace(text, func(inner string) string { return "[" + inner + "](" + href + ")" })
+	case atom.Img:
+		return c.image(n)
+	case atom.Picture:
+		if img := findFirst(n, atom.Img); img != nil {
+			return c.image(img)
+		}
+		return ""
+	default:
+		if blockTags[n.DataAtom] {
+			// Block inside inline content (e.g. a <div> in a link): keep its text on the line
+			return " " + c.inlineChildren(n) + " "
+		}
+		return c.inlineChildren(n)
+	}
+}
+
+func (c *converter) inlineChildren(n *html.Node) string {
+	var sb strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		sb.WriteString(c.inline(child))
+	}
+	return sb.String()
+}
+
+// image renders an image and records its absolute URL; lazy-loaded sources are picked up
+// from data-src and srcset
+func (c *converter) image(n *html.Node) string {
+	src := ""
+	for _, key := range []string{"data-src", "data-original", "data-lazy-src", "src"} {
+		if v := strings.TrimSpace(attr(n, key)); v != "" && !strings.HasPrefix(v, "data:") {
+			src = v
+			break
+		}
+	}
+	if src == "" {
+		if srcset := strings.TrimSpace(firstOf(attr(n, "srcset"), attr(n, "data-srcset"))); srcset != "" {
+			src = strings.Fields(strings.Split(srcset, ",")[0])[0]
+		}
+	}
+	abs := c.resolve(src)
+	if abs == "" {
+		return ""
+	}
+	if !c.seen[abs] {
+		c.seen[abs] = true
+		c.images = append(c.images, abs)
+	}
+	alt := mdEscaper.Replace(spacePattern.ReplaceAllString(attr(n, "alt"), " "))
+	return "![" + strings.TrimSpace(alt) + "](" + abs + ")"
+}
+
+// resolve makes a link absolute; only http(s) links are kept
+func (c *converter) resolve(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "#") {
+		return ""
+	}
+	if c.rewrite != nil {
+		if ref = c.rewrite(ref); ref == "" {
+			return ""
+		}
+	} else {
+		u, err := c.base.Parse(ref)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return ""
+		}
+		ref = u.String()
+	}
+	// Keep the markdown link syntax intact
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(ref)
+}
+
+// paragraph writes a block of text followed by a blank line
+func paragraph(sb *strings.Builder, text string) {
+	text = strings.Trim(text, "\n ")
+	if text == "" {
+		return
+	}
+	sb.WriteString(text)
+	sb.WriteString("\n\n")
+}
+
+// cleanInline trims the spaces around each line of inline text, keeping hard line breaks
+func cleanInline(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		hard := strings.HasSuffix(line, "  ")
+		lines[i] = strings.TrimSpace(line)
+		if hard && i < len(lines)-1 {
+			lines[i] += "  "
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrap surrounds text with an emphasis marker, keeping the spaces around it outside the marker
+func wrap(text, marker string) string {
+	if strings.TrimSpace(text) == "" {
+		return text
+	}
+	return wrapSpace(text, func(inner string) string { return marker + inner + marker })
+}
+
+func wrapSpace(text string, format func(string) string) string {
+	inner := strings.TrimSpace(text)
+	lead := text[:strings.Index(text, inner)]
+	trail := text[len(lead)+len(inner):]
+	return lead + format(inner) + trail
+}
+
+// walk visits n and its descendants depth-first; visit returns false to skip the children of a node
+func walk(n *html.Node, visit func(*html.Node) bool) {
+	if !visit(n) {
+		return
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, visit)
+	}
+}
+
+func findFirst(n *html.Node, a atom.Atom) *html.Node {
+	var found *html.Node
+	walk(n, func(c *html.Node) bool {
+		if found != nil {
+			return false
+		}
+		if c.Type == html.ElementNode && c.DataAtom == a {
+			found = c
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// textContent returns the text of a node and its descendants as is
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+	walk(n, func(c *html.Node) bool {
+		if c.Type == html.TextNode {
+			sb.WriteString(c.Data)
+		}
+		return true
+	})
+	return sb.String()
+}
+
+// innerText returns the text of a node with whitespace collapsed
+func innerText(n *html.Node) string {
+	return strings.TrimSpace(spacePattern.ReplaceAllString(textContent(n), " "))
+}
+
+func attrOK(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func attr(n *html.Node, key string) string {
+	v, _ := attrOK(n, key)
+	return v
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -14,39 +14,85 @@ import (
 )
 
 type Config struct {
-	Server     ServerConfig     `yaml:"server"`
-	Storage    StorageConfig    `yaml:"storage"`
-	Editor     EditorConfig     `yaml:"editor"`
-	Auth       AuthConfig       `yaml:"auth"`
-	Database   DatabaseConfig   `yaml:"database"`
-	Logging    LoggingConfig    `yaml:"logging"`
-	Encryption EncryptionConfig `yaml:"encryption"`
-	Daemon     DaemonConfig     `yaml:"daemon"`
-	Telegram   TelegramConfig   `yaml:"telegram"`
+	Server      ServerConfig      `yaml:"server"`
+	Storage     StorageConfig     `yaml:"storage"`
+	Editor      EditorConfig      `yaml:"editor"`
+	Auth        AuthConfig        `yaml:"auth"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Logging     LoggingConfig     `yaml:"logging"`
+	Encryption  EncryptionConfig  `yaml:"encryption"`
+	Daemon      DaemonConfig      `yaml:"daemon"`
+	Telegram    TelegramConfig    `yaml:"telegram"`
+	Journal     JournalConfig     `yaml:"journal"`
+	Backup      BackupConfig      `yaml:"backup"`
+	SEO         SEOConfig         `yaml:"seo"`
+	Analytics   AnalyticsConfig   `yaml:"analytics"`
+	SMTP        SMTPConfig        `yaml:"smtp"`
+	Export      ExportConfig      `yaml:"export"`
+	Fetch       FetchConfig       `yaml:"fetch"`
+	Upload      UploadConfig      `yaml:"upload"`
+	ShortLinks  ShortLinkConfig   `yaml:"shortlinks"`
+	Maintenance MaintenanceConfig `yaml:"maintenance"`
+	Trash       TrashConfig       `yaml:"trash"`
 }
 
 type EncryptionConfig struct {
 	Enabled bool   `yaml:"enabled"`
 	Salt    string `yaml:"salt"` // Base64 encoded salt for PBKDF2
+
+	// Keep session keys across restarts, wrapped with a key kept in MasterKeyFile
+	PersistKeys   bool   `yaml:"persist_keys"`
+	MasterKeyFile string `yaml:"master_key_file"`
+	KeyTTL        int    `yaml:"key_ttl"` // hours, defaults to the session timeout
 }
 
 type ServerConfig struct {
-	Port     int    `yaml:"port"`
-	Host     string `yaml:"host"`
-	BasePath string `yaml:"base_path"`
+	Port        int               `yaml:"port"`
+	Host        string            `yaml:"host"`
+	BasePath    string            `yaml:"base_path"`
+	TLS         TLSConfig         `yaml:"tls"`
+	Compression CompressionConfig `yaml:"compression"`
+}
+
+// CompressionConfig controls gzip compression of responses (pages, static assets, JSON and text attachments)
+type CompressionConfig struct {
+	Disabled          bool     `yaml:"disabled"`           // Send responses uncompressed (e.g. when the reverse proxy compresses them)
+	Level             int      `yaml:"level"`              // 1 (fastest) to 9 (smallest) (default: 6)
+	ExcludeExtensions []string `yaml:"exclude_extensions"` // More already-compressed file types to send as is (e.g. [".7z", ".heic"])
+}
+
+// TLSConfig serves HTTPS directly, with certificate files or certificates from Let's Encrypt (ACME)
+type TLSConfig struct {
+	Enabled  bool     `yaml:"enabled"`
+	CertFile string   `yaml:"cert_file"`
+	KeyFile  string   `yaml:"key_file"`
+	ACME     bool     `yaml:"acme"`      // Obtain and renew certificates automatically instead of cert_file/key_file
+	Domains  []string `yaml:"domains"`   // Host names certificates are requested for (required with acme)
+	Email    string   `yaml:"email"`     // Contact address for the ACME account (optional)
+	CacheDir string   `yaml:"cache_dir"` // Where ACME certificates are kept (default: ./data/certs)
+	HTTPPort int      `yaml:"http_port"` // Plain HTTP port redirecting to HTTPS and answering ACME challenges (0 = off)
 }
 
 type StorageConfig struct {
-	Path        string `yaml:"path"`
-	AutoInitGit bool   `yaml:"auto_init_git"`
+	Path               string `yaml:"path"`
+	AutoInitGit        bool   `yaml:"auto_init_git"`
+	AttachmentVersions int    `yaml:"attachment_versions"` // Prior versions kept when an attachment is replaced (-1: none)
 }
 
 type LoggingConfig struct {
-	Level    string `yaml:"level"`    // "debug", "info", "warn", "error" (default: "info")
-	Encoding string `yaml:"encoding"` // "utf-8" (default) or "euc-kr" for console output
-	File     bool   `yaml:"file"`     // Enable file logging
-	Dir      string `yaml:"dir"`      // Log directory
-	MaxAge   int    `yaml:"max_age"`  // Max days to retain old log files
+	Level     string          `yaml:"level"`      // "debug", "info", "warn", "error" (default: "info")
+	Encoding  string          `yaml:"encoding"`   // "utf-8" (default) or "euc-kr" for console output
+	File      bool            `yaml:"file"`       // Enable file logging
+	Dir       string          `yaml:"dir"`        // Log directory
+	MaxAge    int             `yaml:"max_age"`    // Max days to retain old log files
+	AccessLog AccessLogConfig `yaml:"access_log"` // One line per request at info level
+}
+
+// AccessLogConfig controls the request log (method, path, route, status, duration, user, IP)
+type AccessLogConfig struct {
+	Disabled      bool    `yaml:"disabled"`       // Don't log requests
+	SampleRate    float64 `yaml:"sample_rate"`    // Share of successful requests logged, 0-1 (default: 1); errors and slow requests are always logged
+	SlowThreshold int     `yaml:"slow_threshold"` // Milliseconds after which a request counts as slow (default: 1000)
 }
 
 type DaemonConfig struct {
@@ -59,10 +105,39 @@ type EditorConfig struct {
 }
 
 type AuthConfig struct {
-	Enabled           bool   `yaml:"enabled"`
-	SessionTimeout    int    `yaml:"session_timeout"` // hours
-	AdminUsername     string `yaml:"admin_username"`
-	AdminPasswordHash string `yaml:"admin_password_hash"` // SHA-512 hash
+	Enabled           bool         `yaml:"enabled"`
+	SessionTimeout    int          `yaml:"session_timeout"` // hours
+	AdminUsername     string       `yaml:"admin_username"`
+	AdminPasswordHash string       `yaml:"admin_password_hash"` // SHA-512 hash
+	TokenLifetime     int          `yaml:"token_lifetime"`      // Longest lifetime of API tokens in hours (default: 720)
+	ExtensionOrigins  []string     `yaml:"extension_origins"`   // Browser extension origins allowed to call the API (e.g. "chrome-extension://<id>")
+	CleanupInterval   int          `yaml:"cleanup_interval"`    // Hours between purges of expired sessions, API tokens and their encryption keys (default: 1)
+	Cookie            CookieConfig `yaml:"cookie"`
+	OIDC              OIDCConfig   `yaml:"oidc"`
+}
+
+// CookieConfig sets the attributes of the session cookie
+type CookieConfig struct {
+	Secure   string `yaml:"secure"`    // "auto" (HTTPS requests, including X-Forwarded-Proto: https from a proxy), "always" or "never" (default: auto)
+	SameSite string `yaml:"same_site"` // "lax", "strict" or "none" (default: not set, browsers treat it as lax)
+	Domain   string `yaml:"domain"`    // e.g. "example.com" to send the cookie to subdomains too (default: the requested host only)
+}
+
+// OIDCConfig enables signing in through an OpenID Connect provider (Authentik, Keycloak, Google, ...)
+// Users are created on their first sign-in
+type OIDCConfig struct {
+	Enabled        bool     `yaml:"enabled"`
+	Name           string   `yaml:"name"`   // Provider name on the login button (default: "SSO")
+	Issuer         string   `yaml:"issuer"` // e.g. "https://auth.example.com/application/o/gitnotepad/"
+	ClientID       string   `yaml:"client_id"`
+	ClientSecret   string   `yaml:"client_secret"`
+	RedirectURL    string   `yaml:"redirect_url"`    // Callback URL registered at the provider (default: <origin><base_path>/auth/oidc/callback)
+	Scopes         []string `yaml:"scopes"`          // default: openid, profile, email
+	UsernameClaim  string   `yaml:"username_claim"`  // Claim used as username (default: preferred_username, then the email's local part)
+	AllowedDomains []string `yaml:"allowed_domains"` // Only verified emails of these domains may sign in (empty = any)
+	AllowedGroups  []string `yaml:"allowed_groups"`  // Only members of these groups ("groups" claim) may sign in (empty = any)
+	AdminGroups    []string `yaml:"admin_groups"`    // Members of these groups are created as admins
+	LinkExisting   bool     `yaml:"link_existing"`   // Sign in existing users whose username matches (only for providers you control)
 }
 
 type DatabaseConfig struct {
@@ -70,11 +145,101 @@ type DatabaseConfig struct {
 }
 
 type TelegramConfig struct {
-	Enabled         bool    `yaml:"enabled"`
-	Token           string  `yaml:"token"`             // Telegram bot token from @BotFather
-	AllowedUsers    []int64 `yaml:"allowed_users"`     // List of allowed Telegram user IDs
-	DefaultFolder   string  `yaml:"default_folder"`    // Default folder for notes (e.g., "Telegram")
-	DefaultUsername string  `yaml:"default_username"`  // GitNotepad username to save notes as
+	Enabled         bool                 `yaml:"enabled"`
+	Token           string               `yaml:"token"`            // Telegram bot token from @BotFather
+	AllowedUsers    []int64              `yaml:"allowed_users"`    // List of allowed Telegram user IDs
+	DefaultFolder   string               `yaml:"default_folder"`   // Default folder for notes (e.g., "Telegram")
+	DefaultUsername string               `yaml:"default_username"` // GitNotepad username to save notes as
+	UserMap         map[int64]string     `yaml:"user_map"`         // Telegram user ID -> GitNotepad username (others save as default_username)
+	JournalMode     bool                 `yaml:"journal_mode"`     // Append text messages to today's journal note instead of creating a note each
+	Notify          TelegramNotifyConfig `yaml:"notify"`
+}
+
+// TelegramNotifyConfig sets which events the bot pushes to chats
+type TelegramNotifyConfig struct {
+	Chats        map[string][]int64 `yaml:"chats"`         // GitNotepad username -> chat IDs notified of that user's events
+	SharedNotes  bool               `yaml:"shared_notes"`  // Another user adds a note to a folder the user shares
+	LinkViews    bool               `yaml:"link_views"`    // A short link of the user is opened (at most once an hour per link)
+	Reminders    bool               `yaml:"reminders"`     // Notes due today, once a day
+	ReminderHour int                `yaml:"reminder_hour"` // Hour of the daily reminder in the user's time zone (default: 9)
+}
+
+type JournalConfig struct {
+	Folder      string `yaml:"folder"`       // Folder for daily notes (e.g., "Journal")
+	Template    string `yaml:"template"`     // Title of the template note inside the journal folder
+	TitleFormat string `yaml:"title_format"` // Go time layout for note titles (e.g., "2006-01-02")
+}
+
+type BackupConfig struct {
+	Enabled     bool                 `yaml:"enabled"`
+	Interval    int                  `yaml:"interval"`     // Hours between backups (default: 24)
+	Dir         string               `yaml:"dir"`          // Local archive directory
+	Keep        int                  `yaml:"keep"`         // Number of local archives to keep
+	SecretsFile string               `yaml:"secrets_file"` // Target credentials (tokens, passwords)
+	Targets     []BackupTargetConfig `yaml:"targets"`
+}
+
+type BackupTargetConfig struct {
+	Name    string `yaml:"name"`     // Credentials key in the secrets file
+	Type    string `yaml:"type"`     // "webdav", "dropbox", "gdrive", "s3" or "sftp"
+	URL     string `yaml:"url"`      // WebDAV collection URL, S3 endpoint (empty = AWS) or SFTP server (sftp://host:port)
+	Folder  string `yaml:"folder"`   // Dropbox folder path, Google Drive folder ID, S3 key prefix or SFTP directory
+	Bucket  string `yaml:"bucket"`   // S3 bucket
+	Region  string `yaml:"region"`   // S3 region (default us-east-1)
+	HostKey string `yaml:"host_key"` // SFTP server key fingerprint ("SHA256:...", from ssh-keygen -lf)
+}
+
+type SEOConfig struct {
+	Indexable bool   `yaml:"indexable"` // Allow search engines to index public links (serves /sitemap.xml)
+	SiteURL   string `yaml:"site_url"`  // Public scheme and host (e.g., "https://notes.example.com"); empty = from request
+}
+
+type AnalyticsConfig struct {
+	Disabled       bool `yaml:"disabled"`        // Stop counting views of public pages
+	UniqueVisitors bool `yaml:"unique_visitors"` // Count unique visitors by a daily-rotated hash of IP and user agent
+	RetentionDays  int  `yaml:"retention_days"`  // Days of daily view counts to keep (default: 365)
+	RecordIP       bool `yaml:"record_ip"`       // Keep the IP address of the last access of each short link
+}
+
+type SMTPConfig struct {
+	Host       string `yaml:"host"`        // SMTP server (empty = sending email is disabled)
+	Port       int    `yaml:"port"`        // 587 (STARTTLS) or 465 (TLS) (default: 587)
+	Username   string `yaml:"username"`    // Empty = no authentication
+	Password   string `yaml:"password"`
+	From       string `yaml:"from"`        // Sender (e.g., "Git Notepad <notes@example.com>")
+	PDFCommand string `yaml:"pdf_command"` // Deprecated: use export.pdf_command (still read when that is empty)
+}
+
+type ExportConfig struct {
+	PDFCommand string `yaml:"pdf_command"` // HTML to PDF converter reading stdin and writing stdout (e.g., "wkhtmltopdf --quiet - -")
+}
+
+type FetchConfig struct {
+	MaxSizeMB      int      `yaml:"max_size_mb"`     // Largest download in MB (default: 20)
+	TimeoutSeconds int      `yaml:"timeout_seconds"` // Time limit of a download (default: 30)
+	AllowedTypes   []string `yaml:"allowed_types"`   // MIME types, or prefixes ending in "/" (default: images, PDF, text)
+	AllowPrivate   bool     `yaml:"allow_private"`   // Allow fetching from loopback and private network addresses
+}
+
+// UploadConfig restricts uploaded files and images (including new versions of an attachment)
+// Allow and Deny take extensions (".pdf") and MIME types, or prefixes ending in "/" ("image/")
+type UploadConfig struct {
+	MaxSizeMB int      `yaml:"max_size_mb"` // Largest upload in MB (default: 100, -1: no limit)
+	Allow     []string `yaml:"allow"`       // Only these are accepted (empty = anything not denied)
+	Deny      []string `yaml:"deny"`        // Never accepted, even when allowed
+}
+
+type ShortLinkConfig struct {
+	CleanupInterval int `yaml:"cleanup_interval"` // Hours between removals of expired links, counted from midnight (default: 24)
+}
+
+type MaintenanceConfig struct {
+	Enabled bool   `yaml:"enabled"` // Start in read-only mode (admins can switch it at runtime)
+	Message string `yaml:"message"` // Shown in the banner of connected browsers
+}
+
+type TrashConfig struct {
+	RetentionDays int `yaml:"retention_days"` // Days deleted notes stay in the trash (default: 30, -1: keep until emptied)
 }
 
 // LoadResult contains the loaded config and migration status
@@ -114,9 +279,18 @@ func LoadWithMigrationCheck(path string) (*LoadResult, error) {
 	if cfg.Server.Host == "" {
 		cfg.Server.Host = "0.0.0.0"
 	}
+	if cfg.Server.TLS.CacheDir == "" {
+		cfg.Server.TLS.CacheDir = "./data/certs"
+	}
+	if cfg.Server.Compression.Level == 0 {
+		cfg.Server.Compression.Level = 6
+	}
 	if cfg.Storage.Path == "" {
 		cfg.Storage.Path = "./data"
 	}
+	if cfg.Storage.AttachmentVersions == 0 {
+		cfg.Storage.AttachmentVersions = 5
+	}
 	if cfg.Editor.DefaultType == "" {
 		cfg.Editor.DefaultType = "markdown"
 	}
@@ -126,6 +300,24 @@ func LoadWithMigrationCheck(path string) (*LoadResult, error) {
 	if cfg.Auth.SessionTimeout == 0 {
 		cfg.Auth.SessionTimeout = 168 // 7 days
 	}
+	if cfg.Auth.TokenLifetime == 0 {
+		cfg.Auth.TokenLifetime = 720 // 30 days
+	}
+	if cfg.Auth.CleanupInterval <= 0 {
+		cfg.Auth.CleanupInterval = 1
+	}
+	if cfg.Auth.OIDC.Name == "" {
+		cfg.Auth.OIDC.Name = "SSO"
+	}
+	if cfg.Auth.OIDC.UsernameClaim == "" {
+		cfg.Auth.OIDC.UsernameClaim = "preferred_username"
+	}
+	if cfg.Encryption.MasterKeyFile == "" {
+		cfg.Encryption.MasterKeyFile = "./data/master.key"
+	}
+	if cfg.Encryption.KeyTTL == 0 {
+		cfg.Encryption.KeyTTL = cfg.Auth.SessionTimeout
+	}
 	if cfg.Logging.Level == "" {
 		cfg.Logging.Level = "info"
 	}
@@ -138,6 +330,12 @@ func LoadWithMigrationCheck(path string) (*LoadResult, error) {
 	if cfg.Logging.MaxAge == 0 {
 		cfg.Logging.MaxAge = 30 // 30 days
 	}
+	if cfg.Logging.AccessLog.SampleRate == 0 {
+		cfg.Logging.AccessLog.SampleRate = 1
+	}
+	if cfg.Logging.AccessLog.SlowThreshold == 0 {
+		cfg.Logging.AccessLog.SlowThreshold = 1000
+	}
 	if cfg.Daemon.PidFile == "" {
 		cfg.Daemon.PidFile = "./gitnotepad.pid"
 	}
@@ -147,6 +345,60 @@ func LoadWithMigrationCheck(path string) (*LoadResult, error) {
 	if cfg.Telegram.DefaultUsername == "" {
 		cfg.Telegram.DefaultUsername = "admin"
 	}
+	if cfg.Telegram.Notify.ReminderHour == 0 {
+		cfg.Telegram.Notify.ReminderHour = 9
+	}
+	if cfg.Journal.Folder == "" {
+		cfg.Journal.Folder = "Journal"
+	}
+	if cfg.Journal.Template == "" {
+		cfg.Journal.Template = "Template"
+	}
+	if cfg.Journal.TitleFormat == "" {
+		cfg.Journal.TitleFormat = "2006-01-02"
+	}
+	if cfg.Backup.Interval == 0 {
+		cfg.Backup.Interval = 24
+	}
+	if cfg.Backup.Dir == "" {
+		cfg.Backup.Dir = "./backups"
+	}
+	if cfg.Backup.Keep == 0 {
+		cfg.Backup.Keep = 7
+	}
+	if cfg.Backup.SecretsFile == "" {
+		cfg.Backup.SecretsFile = "./secrets.yaml"
+	}
+
+	if cfg.SMTP.Port == 0 {
+		cfg.SMTP.Port = 587
+	}
+	if strings.TrimSpace(cfg.Export.PDFCommand) == "" {
+		cfg.Export.PDFCommand = cfg.SMTP.PDFCommand // Before export.pdf_command, the converter was configured under smtp
+	}
+	cfg.Export.PDFCommand = strings.TrimSpace(cfg.Export.PDFCommand)
+	if cfg.Analytics.RetentionDays == 0 {
+		cfg.Analytics.RetentionDays = 365
+	}
+	if cfg.Fetch.MaxSizeMB == 0 {
+		cfg.Fetch.MaxSizeMB = 20
+	}
+	if cfg.Fetch.TimeoutSeconds == 0 {
+		cfg.Fetch.TimeoutSeconds = 30
+	}
+	if len(cfg.Fetch.AllowedTypes) == 0 {
+		cfg.Fetch.AllowedTypes = DefaultFetchTypes
+	}
+	if cfg.Upload.MaxSizeMB == 0 {
+		cfg.Upload.MaxSizeMB = 100
+	}
+	if cfg.ShortLinks.CleanupInterval <= 0 {
+		cfg.ShortLinks.CleanupInterval = 24
+	}
+	if cfg.Trash.RetentionDays == 0 {
+		cfg.Trash.RetentionDays = 30
+	}
+	cfg.SEO.SiteURL = strings.TrimSuffix(cfg.SEO.SiteURL, "/")
 
 	// Normalize base_path: ensure it starts with "/" if not empty
 	if cfg.Server.BasePath != "" {
@@ -174,12 +426,15 @@ func getDefaultEncoding() string {
 func Default() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port: 8080,
-			Host: "0.0.0.0",
+			Port:        8080,
+			Host:        "0.0.0.0",
+			TLS:         TLSConfig{CacheDir: "./data/certs"},
+			Compression: CompressionConfig{Level: 6},
 		},
 		Storage: StorageConfig{
-			Path:        "./data",
-			AutoInitGit: true,
+			Path:               "./data",
+			AutoInitGit:        true,
+			AttachmentVersions: 5,
 		},
 		Editor: EditorConfig{
 			DefaultType: "markdown",
@@ -188,6 +443,8 @@ func Default() *Config {
 		Auth: AuthConfig{
 			Enabled:           true,
 			SessionTimeout:    168, // 7 days in hours
+			TokenLifetime:     720, // 30 days in hours
+			CleanupInterval:   1,
 			AdminUsername:     "admin",
 			AdminPasswordHash: "", // Will be set on first run
 		},
@@ -200,10 +457,16 @@ func Default() *Config {
 			File:     false,
 			Dir:      "./logs",
 			MaxAge:   30, // 30 days
+			AccessLog: AccessLogConfig{
+				SampleRate:    1,
+				SlowThreshold: 1000,
+			},
 		},
 		Encryption: EncryptionConfig{
-			Enabled: false,
-			Salt:    "", // Will be generated on first run if encryption enabled
+			Enabled:       false,
+			Salt:          "", // Will be generated on first run if encryption enabled
+			MasterKeyFile: "./data/master.key",
+			KeyTTL:        168, // 7 days in hours
 		},
 		Daemon: DaemonConfig{
 			PidFile: "./gitnotepad.pid",
@@ -214,10 +477,48 @@ func Default() *Config {
 			AllowedUsers:    []int64{},
 			DefaultFolder:   "Telegram",
 			DefaultUsername: "admin",
+			Notify: TelegramNotifyConfig{
+				ReminderHour: 9,
+			},
+		},
+		Journal: JournalConfig{
+			Folder:      "Journal",
+			Template:    "Template",
+			TitleFormat: "2006-01-02",
+		},
+		Backup: BackupConfig{
+			Enabled:     false,
+			Interval:    24,
+			Dir:         "./backups",
+			Keep:        7,
+			SecretsFile: "./secrets.yaml",
+		},
+		Analytics: AnalyticsConfig{
+			RetentionDays: 365,
+		},
+		SMTP: SMTPConfig{
+			Port: 587,
+		},
+		Fetch: FetchConfig{
+			MaxSizeMB:      20,
+			TimeoutSeconds: 30,
+			AllowedTypes:   DefaultFetchTypes,
+		},
+		Upload: UploadConfig{
+			MaxSizeMB: 100,
+		},
+		ShortLinks: ShortLinkConfig{
+			CleanupInterval: 24,
+		},
+		Trash: TrashConfig{
+			RetentionDays: 30,
 		},
 	}
 }
 
+// DefaultFetchTypes are the content types that can be attached from a URL by default
+var DefaultFetchTypes = []string{"image/", "application/pdf", "text/plain", "text/markdown", "text/csv"}
+
 // HashPassword creates a SHA-512 hash of the password
 func HashPassword(password string) string {
 	hash := sha512.Sum512([]byte(password))
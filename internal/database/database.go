@@ -44,7 +44,10 @@ func (db *DB) Migrate() error {
 			username TEXT UNIQUE NOT NULL,
 			password_hash TEXT NOT NULL,
 			is_admin BOOLEAN DEFAULT FALSE,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			read_only BOOLEAN NOT NULL DEFAULT FALSE,
+			upload_disabled BOOLEAN NOT NULL DEFAULT FALSE,
+			encryption_salt TEXT NOT NULL DEFAULT ''
 		)`,
 		// Sessions table
 		`CREATE TABLE IF NOT EXISTS sessions (
@@ -53,6 +56,9 @@ func (db *DB) Migrate() error {
 			token TEXT UNIQUE NOT NULL,
 			expires_at DATETIME NOT NULL,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			user_agent TEXT NOT NULL DEFAULT '',
+			ip TEXT NOT NULL DEFAULT '',
+			last_used_at DATETIME,
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 		)`,
 		// Indexes
@@ -82,6 +88,187 @@ func (db *DB) Migrate() error {
 			UNIQUE(user_id, parent_path)
 		)`,
 		`CREATE INDEX IF NOT EXISTS idx_folder_order_user ON folder_order(user_id)`,
+		// Note sort table (field and direction of the notes in each folder)
+		`CREATE TABLE IF NOT EXISTS note_sort (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			folder_path TEXT NOT NULL DEFAULT '',
+			field TEXT NOT NULL,
+			direction TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE(user_id, folder_path)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_note_sort_user ON note_sort(user_id)`,
+		// Folder metadata table (description, color, default note type)
+		`CREATE TABLE IF NOT EXISTS folder_meta (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			folder_path TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			color TEXT NOT NULL DEFAULT '',
+			default_type TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE(user_id, folder_path)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_folder_meta_user ON folder_meta(user_id)`,
+		// Folder shares table (internal sharing between users)
+		`CREATE TABLE IF NOT EXISTS folder_shares (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			owner_id INTEGER NOT NULL,
+			folder_path TEXT NOT NULL,
+			grantee_id INTEGER NOT NULL,
+			permission TEXT NOT NULL DEFAULT 'read',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (grantee_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE(owner_id, folder_path, grantee_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_folder_shares_grantee ON folder_shares(grantee_id)`,
+		// Note shares table (single notes shared between users)
+		`CREATE TABLE IF NOT EXISTS note_shares (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			owner_id INTEGER NOT NULL,
+			note_uid TEXT NOT NULL,
+			grantee_id INTEGER NOT NULL,
+			permission TEXT NOT NULL DEFAULT 'read',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (grantee_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE(owner_id, note_uid, grantee_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_note_shares_grantee ON note_shares(grantee_id)`,
+		// Folder rules table (per-folder access limits an admin sets on a user's own notes)
+		`CREATE TABLE IF NOT EXISTS folder_rules (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			folder_path TEXT NOT NULL,
+			permission TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE(user_id, folder_path)
+		)`,
+		// Note views table (last viewed time per note, for "continue where you left off")
+		`CREATE TABLE IF NOT EXISTS note_views (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			note_uid TEXT NOT NULL,
+			viewed_at DATETIME NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE(user_id, note_uid)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_note_views_user ON note_views(user_id, viewed_at)`,
+		// Audit log table (sharing and user management events for the admin activity feed)
+		`CREATE TABLE IF NOT EXISTS audit_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			username TEXT NOT NULL,
+			action TEXT NOT NULL,
+			target TEXT NOT NULL DEFAULT '',
+			detail TEXT NOT NULL DEFAULT '',
+			ip TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at)`,
+		// Git mirrors table (per-user remote the notes repository is pushed to after each commit)
+		`CREATE TABLE IF NOT EXISTS git_mirrors (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL UNIQUE,
+			url TEXT NOT NULL,
+			username TEXT NOT NULL DEFAULT '',
+			token TEXT NOT NULL DEFAULT '',
+			enabled INTEGER NOT NULL DEFAULT 1,
+			last_push DATETIME,
+			last_error TEXT NOT NULL DEFAULT '',
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		// Link views table (daily view counts of public pages per short link code)
+		`CREATE TABLE IF NOT EXISTS link_views (
+			code TEXT NOT NULL,
+			day TEXT NOT NULL,
+			views INTEGER NOT NULL DEFAULT 0,
+			visitors INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (code, day)
+		)`,
+		// Hashed visitors of the current day (only with analytics.unique_visitors, pruned daily)
+		`CREATE TABLE IF NOT EXISTS link_visitors (
+			code TEXT NOT NULL,
+			day TEXT NOT NULL,
+			hash TEXT NOT NULL,
+			PRIMARY KEY (code, day, hash)
+		)`,
+		// User preferences table (per-user settings such as the language, one row per setting)
+		`CREATE TABLE IF NOT EXISTS user_preferences (
+			user_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			value TEXT NOT NULL,
+			PRIMARY KEY (user_id, name),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		// API tokens table (scoped tokens for browser extensions, only the SHA-256 of the token is stored)
+		`CREATE TABLE IF NOT EXISTS api_tokens (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			token_hash TEXT UNIQUE NOT NULL,
+			name TEXT NOT NULL DEFAULT '',
+			scopes TEXT NOT NULL,
+			encrypted BOOLEAN NOT NULL DEFAULT FALSE,
+			expires_at DATETIME NOT NULL,
+			last_used_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_api_tokens_user ON api_tokens(user_id)`,
+		// Session encryption keys wrapped with the master key (encryption.persist_keys)
+		`CREATE TABLE IF NOT EXISTS session_keys (
+			id TEXT PRIMARY KEY,
+			wrapped_key TEXT NOT NULL,
+			expires_at DATETIME NOT NULL
+		)`,
+		// Accounts at an OpenID Connect provider linked to users (auth.oidc)
+		`CREATE TABLE IF NOT EXISTS oidc_identities (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			issuer TEXT NOT NULL,
+			subject TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(issuer, subject),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		// Short links table (/s/:code links to notes and folders; imported from .shortlinks.json on first start)
+		`CREATE TABLE IF NOT EXISTS short_links (
+			code TEXT PRIMARY KEY,
+			note_id TEXT NOT NULL DEFAULT '',
+			note_uid TEXT NOT NULL DEFAULT '',
+			folder_path TEXT NOT NULL DEFAULT '',
+			username TEXT NOT NULL DEFAULT '',
+			expires_at DATETIME,
+			created_at DATETIME NOT NULL,
+			is_public BOOLEAN NOT NULL DEFAULT FALSE,
+			blog BOOLEAN NOT NULL DEFAULT FALSE,
+			password_hash TEXT NOT NULL DEFAULT '',
+			view_count INTEGER NOT NULL DEFAULT 0,
+			max_views INTEGER NOT NULL DEFAULT 0,
+			last_access_at DATETIME,
+			last_access_ip TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_short_links_username ON short_links(username)`,
+		// Note links table (wiki-links between notes: target is the lowercased title, alias or note ID)
+		`CREATE TABLE IF NOT EXISTS note_links (
+			username TEXT NOT NULL,
+			source_uid TEXT NOT NULL,
+			target TEXT NOT NULL,
+			PRIMARY KEY (username, source_uid, target)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_note_links_target ON note_links(username, target)`,
+		// Telegram chats table (target folder chosen with the bot's /folder command)
+		`CREATE TABLE IF NOT EXISTS telegram_chats (
+			chat_id INTEGER PRIMARY KEY,
+			folder TEXT NOT NULL DEFAULT ''
+		)`,
 	}
 
 	for _, migration := range migrations {
@@ -90,9 +277,35 @@ func (db *DB) Migrate() error {
 		}
 	}
 
+	// Columns added to existing tables after their first release
+	columns := []struct{ table, column, definition string }{
+		{"sessions", "user_agent", "TEXT NOT NULL DEFAULT ''"},
+		{"sessions", "ip", "TEXT NOT NULL DEFAULT ''"},
+		{"sessions", "last_used_at", "DATETIME"},
+		{"users", "read_only", "BOOLEAN NOT NULL DEFAULT FALSE"},
+		{"users", "upload_disabled", "BOOLEAN NOT NULL DEFAULT FALSE"},
+		{"users", "encryption_salt", "TEXT NOT NULL DEFAULT ''"},
+	}
+	for _, col := range columns {
+		if err := db.addColumn(col.table, col.column, col.definition); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// addColumn adds a column to a table unless it already exists
+func (db *DB) addColumn(table, column, definition string) error {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count)
+	if err != nil || count > 0 {
+		return err
+	}
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
 // SeedAdminUser creates the initial admin user if no admin exists
 func (db *DB) SeedAdminUser(username, password string) error {
 	if username == "" || password == "" {
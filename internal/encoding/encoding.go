@@ -162,3 +162,17 @@ func IsEUCKR() bool {
 	lower := strings.ToLower(LogEncoding)
 	return lower == "euc-kr" || lower == "euckr"
 }
+
+// levelWriter sends everything written to it through the leveled logger
+type levelWriter int
+
+func (w levelWriter) Write(p []byte) (int, error) {
+	logWithLevel(int(w), "%s", strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
+
+// Writer returns an io.Writer that logs each write at the given level,
+// for libraries that log to a writer (e.g. gin's panic recovery)
+func Writer(level int) io.Writer {
+	return levelWriter(level)
+}
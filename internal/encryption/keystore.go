@@ -1,14 +1,38 @@
 package encryption
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/user/gitnotepad/internal/encoding"
 )
 
 // KeyStore stores encryption keys in memory, keyed by session token
+// Entries are indexed by a hash of the token so persisted keys never reveal it
 type KeyStore struct {
 	keys  map[string][]byte
 	mutex sync.RWMutex
+
+	// Optional persistence (see Persist)
+	persister KeyPersister
+	masterKey []byte
+	ttl       time.Duration
+}
+
+// KeyPersister saves keys wrapped with the master key so they survive a restart
+type KeyPersister interface {
+	SaveKey(id, wrapped string, expiresAt time.Time) error
+	LoadKeys() (map[string]string, error) // Unexpired wrapped keys by id
+	DeleteKey(id string) error
 }
 
 // Global key store instance
@@ -21,47 +45,131 @@ func GetKeyStore() *KeyStore {
 	return globalKeyStore
 }
 
+// keyID returns the index of a session token in the store
+func keyID(sessionToken string) string {
+	sum := sha256.Sum256([]byte(sessionToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// Persist makes the store save each key wrapped with masterKey, for ttl, and loads the keys
+// saved before a restart. Keys wrapped with another master key are skipped.
+func (ks *KeyStore) Persist(persister KeyPersister, masterKey []byte, ttl time.Duration) (int, error) {
+	if len(masterKey) != KeySize {
+		return 0, errors.New("invalid master key size")
+	}
+	saved, err := persister.LoadKeys()
+	if err != nil {
+		return 0, err
+	}
+
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+	ks.persister = persister
+	ks.masterKey = masterKey
+	ks.ttl = ttl
+
+	loaded := 0
+	for id, wrapped := range saved {
+		key, err := Decrypt(wrapped, masterKey)
+		if err != nil {
+			continue
+		}
+		ks.keys[id] = key
+		loaded++
+	}
+	return loaded, nil
+}
+
 // Store stores an encryption key for a session
 func (ks *KeyStore) Store(sessionToken string, key []byte) {
+	id := keyID(sessionToken)
 	ks.mutex.Lock()
 	defer ks.mutex.Unlock()
-	ks.keys[sessionToken] = key
+	ks.keys[id] = key
+
+	if ks.persister != nil {
+		wrapped, err := Encrypt(key, ks.masterKey)
+		if err == nil {
+			err = ks.persister.SaveKey(id, wrapped, time.Now().Add(ks.ttl))
+		}
+		if err != nil {
+			encoding.Warn("Failed to persist session key: %v", err)
+		}
+	}
 }
 
 // Get retrieves an encryption key for a session
 func (ks *KeyStore) Get(sessionToken string) ([]byte, bool) {
 	ks.mutex.RLock()
 	defer ks.mutex.RUnlock()
-	key, ok := ks.keys[sessionToken]
+	key, ok := ks.keys[keyID(sessionToken)]
 	return key, ok
 }
 
 // Delete removes an encryption key for a session
 func (ks *KeyStore) Delete(sessionToken string) {
+	id := keyID(sessionToken)
 	ks.mutex.Lock()
 	defer ks.mutex.Unlock()
-	delete(ks.keys, sessionToken)
+	delete(ks.keys, id)
+
+	if ks.persister != nil {
+		if err := ks.persister.DeleteKey(id); err != nil {
+			encoding.Warn("Failed to delete persisted session key: %v", err)
+		}
+	}
 }
 
-// Cleanup removes expired keys (should be called periodically)
-func (ks *KeyStore) Cleanup(validTokens map[string]bool) {
+// Cleanup removes the keys of sessions and tokens that are no longer valid, including their persisted copies,
+// and returns how many were removed
+func (ks *KeyStore) Cleanup(validTokens map[string]bool) int {
+	valid := make(map[string]bool, len(validTokens))
+	for token := range validTokens {
+		valid[keyID(token)] = true
+	}
+
 	ks.mutex.Lock()
 	defer ks.mutex.Unlock()
-	for token := range ks.keys {
-		if !validTokens[token] {
-			delete(ks.keys, token)
+	removed := 0
+	for id := range ks.keys {
+		if valid[id] {
+			continue
+		}
+		delete(ks.keys, id)
+		removed++
+		if ks.persister != nil {
+			if err := ks.persister.DeleteKey(id); err != nil {
+				encoding.Warn("Failed to delete persisted session key: %v", err)
+			}
 		}
 	}
+	return removed
 }
 
-// StartCleanupRoutine starts a goroutine that periodically cleans up expired keys
-func (ks *KeyStore) StartCleanupRoutine(interval time.Duration, getValidTokens func() map[string]bool) {
-	go func() {
-		ticker := time.NewTicker(interval)
-		defer ticker.Stop()
-		for range ticker.C {
-			validTokens := getValidTokens()
-			ks.Cleanup(validTokens)
+// LoadMasterKey reads the base64 master key from a file, creating the file with a random key
+// (readable only by the owner) when it does not exist
+func LoadMasterKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(key) != KeySize {
+			return nil, fmt.Errorf("invalid master key in %s", path)
 		}
-	}()
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read master key: %w", err)
+	}
+
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate master key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create master key directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write master key: %w", err)
+	}
+	return key, nil
 }
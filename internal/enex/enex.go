@@ -0,0 +1,354 @@
+// Package enex reads notes exported from Evernote (.enex files).
+// The body of a note is ENML, an XHTML subset; its images and files are base64 resources
+// that the body embeds by their MD5 hash.
+package enex
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/user/gitnotepad/internal/clip"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ErrNoNotes is returned for files that are not Evernote exports
+var ErrNoNotes = errors.New("no Evernote notes found")
+
+// Checkbox markers put in place of <en-todo> before conversion; private use characters survive
+// markdown escaping and are turned into task list items afterwards
+const (
+	todoOpen = "\ue000"
+	todoDone = "\ue001"
+)
+
+var (
+	todoLinePattern = regexp.MustCompile(`(?m)^([ \t]*)(?:[-*] |\d+\. )?([` + todoOpen + todoDone + `])[ \t]*`)
+	todoGapPattern  = regexp.MustCompile(`(?m)^([ \t]*- \[[ x]\] .*)\n\n([ \t]*- \[[ x]\] )`)
+)
+
+// Extensions for resources without a file name; mime.ExtensionsByType is used for other types
+var mimeExtensions = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"image/svg+xml":   ".svg",
+	"application/pdf": ".pdf",
+	"audio/mpeg":      ".mp3",
+	"audio/wav":       ".wav",
+	"audio/x-m4a":     ".m4a",
+	"text/plain":      ".txt",
+}
+
+// Resource is an image or file attached to a note
+type Resource struct {
+	Name     string // File name, made up from the MIME type when the export has none
+	MimeType string
+	Data     []byte
+	Hash     string // Hex MD5 of Data, as referenced by <en-media hash="...">
+}
+
+// IsImage reports whether the resource is an image
+func (r *Resource) IsImage() bool {
+	return strings.HasPrefix(r.MimeType, "image/")
+}
+
+// Note is an Evernote note
+type Note struct {
+	Title     string
+	Tags      []string
+	Created   time.Time
+	Modified  time.Time
+	SourceURL string // Page a web clip was taken from
+	Resources []*Resource
+
+	content string // ENML body
+}
+
+type xmlNote struct {
+	Title      string   `xml:"title"`
+	Content    string   `xml:"content"`
+	Created    string   `xml:"created"`
+	Updated    string   `xml:"updated"`
+	Tags       []string `xml:"tag"`
+	Attributes struct {
+		SourceURL string `xml:"source-url"`
+	} `xml:"note-attributes"`
+	Resources []struct {
+		Data       string `xml:"data"`
+		Mime       string `xml:"mime"`
+		Attributes struct {
+			FileName string `xml:"file-name"`
+		} `xml:"resource-attributes"`
+	} `xml:"resource"`
+}
+
+// Read returns the notes of an ENEX file
+// When the file is cut short or malformed, the notes read before the error are returned with it
+func Read(r io.Reader) ([]*Note, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+
+	var notes []*Note
+	export := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return notes, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "en-export":
+			export = true
+		case "note":
+			var xn xmlNote
+			if err := decoder.DecodeElement(&xn, &start); err != nil {
+				return notes, err
+			}
+			notes = append(notes, newNote(&xn))
+		}
+	}
+	if !export {
+		return nil, ErrNoNotes
+	}
+	return notes, nil
+}
+
+func newNote(xn *xmlNote) *Note {
+	note := &Note{
+		Title:     strings.TrimSpace(xn.Title),
+		SourceURL: strings.TrimSpace(xn.Attributes.SourceURL),
+		content:   xn.Content,
+	}
+	if note.Title == "" {
+		note.Title = "Untitled"
+	}
+	for _, tag := range xn.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			note.Tags = append(note.Tags, tag)
+		}
+	}
+	note.Created, _ = time.Parse("20060102T150405Z", strings.TrimSpace(xn.Created))
+	note.Modified, _ = time.Parse("20060102T150405Z", strings.TrimSpace(xn.Updated))
+	if note.Created.IsZero() {
+		note.Created = time.Now()
+	}
+	if note.Modified.Before(note.Created) {
+		note.Modified = note.Created
+	}
+
+	for _, xr := range xn.Resources {
+		data, err := base64.StdEncoding.DecodeString(strings.Map(dropSpace, xr.Data))
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		sum := md5.Sum(data)
+		res := &Resource{
+			Name:     strings.TrimSpace(xr.Attributes.FileName),
+			MimeType: strings.TrimSpace(xr.Mime),
+			Data:     data,
+			Hash:     hex.EncodeToString(sum[:]),
+		}
+		if res.Name == "" {
+			res.Name = "attachment" + extension(res.MimeType)
+		}
+		note.Resources = append(note.Resources, res)
+	}
+	return note
+}
+
+// dropSpace removes the line breaks and indentation of base64 data
+func dropSpace(r rune) rune {
+	if r == ' ' || r == '\n' || r == '\r' || r == '\t' {
+		return -1
+	}
+	return r
+}
+
+func extension(mimeType string) string {
+	if ext, ok := mimeExtensions[mimeType]; ok {
+		return ext
+	}
+	if exts, err := mime.ExtensionsByType(mimeType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// resource returns the resource with the given hash
+func (n *Note) resource(hash string) *Resource {
+	for _, res := range n.Resources {
+		if strings.EqualFold(res.Hash, hash) {
+			return res
+		}
+	}
+	return nil
+}
+
+// Markdown converts the note body to markdown
+// resolve receives each resource and returns the URL it is saved at (false leaves it out);
+// resources the body doesn't embed are listed at the end
+func (n *Note) Markdown(resolve func(res *Resource) (string, bool)) string {
+	doc, err := html.Parse(strings.NewReader(n.content))
+	if err != nil {
+		return ""
+	}
+	body := findElement(doc, "en-note")
+	if body == nil {
+		if body = findElement(doc, "body"); body == nil {
+			return ""
+		}
+	}
+
+	// ENML elements written as <en-media/> are not self-closing to an HTML parser:
+	// collect them first, then lift whatever they swallowed back out
+	var special []*html.Node
+	walk(body, func(node *html.Node) {
+		if node.Type == html.ElementNode && (node.Data == "en-media" || node.Data == "en-todo" || node.Data == "en-crypt") {
+			special = append(special, node)
+		}
+	})
+	for _, node := range special {
+		if node.Data != "en-crypt" {
+			after := node.NextSibling
+			for child := node.FirstChild; child != nil; {
+				next := child.NextSibling
+				node.RemoveChild(child)
+				node.Parent.InsertBefore(child, after)
+				child = next
+			}
+		}
+		node.Parent.InsertBefore(replacement(n, node), node)
+		node.Parent.RemoveChild(node)
+	}
+
+	used := make(map[*Resource]bool)
+	markdown := clip.Markdown(body, func(ref string) string {
+		if hash, ok := strings.CutPrefix(ref, "en-media:"); ok {
+			res := n.resource(hash)
+			if res == nil {
+				return ""
+			}
+			used[res] = true
+			newURL, ok := resolve(res)
+			if !ok {
+				return ""
+			}
+			return newURL
+		}
+		u, err := url.Parse(ref)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "mailto") {
+			return ""
+		}
+		return u.String()
+	})
+
+	// Checkboxes become task list items
+	markdown = todoLinePattern.ReplaceAllStringFunc(markdown, func(line string) string {
+		m := todoLinePattern.FindStringSubmatch(line)
+		if m[2] == todoDone {
+			return m[1] + "- [x] "
+		}
+		return m[1] + "- [ ] "
+	})
+	for todoGapPattern.MatchString(markdown) {
+		markdown = todoGapPattern.ReplaceAllString(markdown, "$1\n$2")
+	}
+	markdown = strings.NewReplacer(todoOpen, "☐", todoDone, "☑").Replace(markdown)
+
+	// Attachments that are not shown in the body
+	var extra []string
+	for _, res := range n.Resources {
+		if used[res] {
+			continue
+		}
+		newURL, ok := resolve(res)
+		if !ok {
+			continue
+		}
+		link := "[" + res.Name + "](" + strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(newURL) + ")"
+		if res.IsImage() {
+			link = "!" + link
+		}
+		extra = append(extra, link)
+	}
+	if len(extra) > 0 {
+		markdown = strings.TrimSpace(markdown + "\n\n" + strings.Join(extra, "\n\n"))
+	}
+	return markdown
+}
+
+// replacement returns the HTML the converter understands in place of an ENML element
+func replacement(n *Note, node *html.Node) *html.Node {
+	switch node.Data {
+	case "en-todo":
+		if attr(node, "checked") == "true" {
+			return &html.Node{Type: html.TextNode, Data: todoDone + " "}
+		}
+		return &html.Node{Type: html.TextNode, Data: todoOpen + " "}
+
+	case "en-media":
+		ref := "en-media:" + attr(node, "hash")
+		res := n.resource(attr(node, "hash"))
+		if res == nil {
+			return &html.Node{Type: html.TextNode}
+		}
+		if res.IsImage() {
+			return &html.Node{Type: html.ElementNode, Data: "img", DataAtom: atom.Img, Attr: []html.Attribute{
+				{Key: "src", Val: ref},
+				{Key: "alt", Val: res.Name},
+			}}
+		}
+		link := &html.Node{Type: html.ElementNode, Data: "a", DataAtom: atom.A, Attr: []html.Attribute{{Key: "href", Val: ref}}}
+		link.AppendChild(&html.Node{Type: html.TextNode, Data: res.Name})
+		return link
+
+	default:
+		// Text encrypted in Evernote can't be decrypted here
+		return &html.Node{Type: html.TextNode, Data: "[encrypted]"}
+	}
+}
+
+// walk visits n and its descendants depth-first
+func walk(n *html.Node, visit func(*html.Node)) {
+	visit(n)
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, visit)
+	}
+}
+
+func findElement(n *html.Node, name string) *html.Node {
+	var found *html.Node
+	walk(n, func(node *html.Node) {
+		if found == nil && node.Type == html.ElementNode && node.Data == name {
+			found = node
+		}
+	})
+	return found
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,188 @@
+// Package fetch downloads remote URLs on behalf of users (attachments, clipped pages).
+// Requests only go to public addresses: the resolved IP of every connection, including
+// redirects, is checked so a URL cannot reach the server itself or hosts on its network.
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/user/gitnotepad/internal/config"
+)
+
+var (
+	ErrInvalidURL     = errors.New("only http and https URLs can be fetched")
+	ErrBlockedAddress = errors.New("address is not allowed")
+	ErrTooLarge       = errors.New("response is too large")
+	ErrTypeNotAllowed = errors.New("content type is not allowed")
+)
+
+// Result is a downloaded response body
+type Result struct {
+	URL         string // Final URL after redirects
+	ContentType string // Media type without parameters (e.g. "image/png")
+	Charset     string // charset parameter of the Content-Type, if any
+	Filename    string // From Content-Disposition, otherwise the last path segment
+	Data        []byte
+}
+
+// Client downloads remote URLs with size, type and address restrictions
+type Client struct {
+	http         *http.Client
+	maxBytes     int64
+	allowedTypes []string
+}
+
+// New creates a client from the fetch config
+func New(cfg config.FetchConfig) *Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if cfg.AllowPrivate {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublic(ip) {
+				return ErrBlockedAddress
+			}
+			return nil
+		},
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // A proxy would be dialed instead of the target, bypassing the address check
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 15 * time.Second,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	return &Client{
+		http: &http.Client{
+			Transport: transport,
+			Timeout:   time.Duration(cfg.TimeoutSeconds) * time.Second,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 5 {
+					return errors.New("too many redirects")
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return ErrInvalidURL
+				}
+				return nil
+			},
+		},
+		maxBytes:     int64(cfg.MaxSizeMB) << 20,
+		allowedTypes: cfg.AllowedTypes,
+	}
+}
+
+// Get downloads a URL; accept lists the content types wanted by the caller
+// (nil = the types allowed in the config)
+func (c *Client) Get(ctx context.Context, rawURL string, accept []string) (*Result, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	req.Header.Set("User-Agent", "GitNotepad/1.0 (+fetch)")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrBlockedAddress) {
+			return nil, ErrBlockedAddress
+		}
+		return nil, fmt.Errorf("failed to fetch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to fetch: %s", resp.Status)
+	}
+	if resp.ContentLength > c.maxBytes {
+		return nil, ErrTooLarge
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "" {
+		mediaType = "application/octet-stream"
+	}
+	if accept == nil {
+		accept = c.allowedTypes
+	}
+	if !typeAllowed(mediaType, accept) {
+		return nil, ErrTypeNotAllowed
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, c.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if int64(len(data)) > c.maxBytes {
+		return nil, ErrTooLarge
+	}
+
+	return &Result{
+		URL:         resp.Request.URL.String(),
+		ContentType: mediaType,
+		Charset:     params["charset"],
+		Filename:    filename(resp),
+		Data:        data,
+	}, nil
+}
+
+// typeAllowed matches a media type against exact types and prefixes such as "image/"
+func typeAllowed(mediaType string, allowed []string) bool {
+	for _, t := range allowed {
+		if t == "*" || t == mediaType || (strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// filename returns the name of the downloaded file
+func filename(resp *http.Response) string {
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		return path.Base(params["filename"])
+	}
+	if name := path.Base(resp.Request.URL.Path); name != "/" && name != "." {
+		if unescaped, err := url.PathUnescape(name); err == nil {
+			return unescaped
+		}
+		return name
+	}
+	return resp.Request.URL.Hostname()
+}
+
+// isPublic reports whether an IP is a globally routable unicast address
+func isPublic(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		// Carrier-grade NAT (100.64.0.0/10), "this network" (0.0.0.0/8) and broadcast
+		if ip4[0] == 0 || (ip4[0] == 100 && ip4[1]&0xc0 == 64) || ip4.Equal(net.IPv4bcast) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,16 +1,21 @@
 package git
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 )
 
 type Repository struct {
@@ -18,6 +23,20 @@ type Repository struct {
 	repo *git.Repository
 }
 
+// commitHook is called with the repository path after every commit (e.g. to push a mirror)
+var commitHook func(repoPath string)
+
+// SetCommitHook registers a function called after every commit; it must not block
+func SetCommitHook(hook func(repoPath string)) {
+	commitHook = hook
+}
+
+func (r *Repository) afterCommit() {
+	if commitHook != nil {
+		commitHook(r.path)
+	}
+}
+
 type Commit struct {
 	Hash    string    `json:"hash"`
 	Message string    `json:"message"`
@@ -154,6 +173,8 @@ func (r *Repository) AddAndCommit(filePath, message string) error {
 		return fmt.Errorf("failed to commit: %w", err)
 	}
 
+	r.afterCommit()
+
 	return nil
 }
 
@@ -217,6 +238,99 @@ func (r *Repository) RemoveAndCommit(filePath, message string) error {
 		return fmt.Errorf("failed to commit: %w", err)
 	}
 
+	r.afterCommit()
+
+	return nil
+}
+
+// AddPathsAndCommit stages every change (including deletions) under the given paths and commits
+// Paths may be files or directories; used for operations touching several files at once
+// such as renaming a user's storage folder or merging notes
+func (r *Repository) AddPathsAndCommit(paths []string, message string) error {
+	if r.repo == nil {
+		if err := r.Open(); err != nil {
+			return err
+		}
+	}
+
+	w, err := r.repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	relPaths := make([]string, 0, len(paths))
+	for _, p := range paths {
+		relPath, err := filepath.Rel(r.path, p)
+		if err != nil {
+			relPath = filepath.Base(p)
+		}
+		// Convert to forward slashes for git
+		relPaths = append(relPaths, filepath.ToSlash(relPath))
+	}
+
+	worktreeStatus, err := w.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get status: %w", err)
+	}
+
+	// Stage each changed file under the given paths (w.Add cannot stage a removed directory)
+	for file, s := range worktreeStatus {
+		matched := false
+		for _, relPath := range relPaths {
+			if file == relPath || strings.HasPrefix(file, relPath+"/") {
+				matched = true
+				break
+			}
+		}
+		if !matched || s.Worktree == git.Unmodified {
+			continue
+		}
+
+		if s.Worktree == git.Deleted {
+			if _, err := w.Remove(file); err != nil {
+				return fmt.Errorf("failed to remove file: %w", err)
+			}
+		} else if _, err := w.Add(file); err != nil {
+			return fmt.Errorf("failed to add file: %w", err)
+		}
+	}
+
+	// Check if there are changes to commit
+	status, err := w.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get status: %w", err)
+	}
+
+	hasChanges := false
+	for _, s := range status {
+		if s.Staging == git.Added || s.Staging == git.Modified || s.Staging == git.Deleted ||
+			s.Staging == git.Renamed || s.Staging == git.Copied {
+			hasChanges = true
+			break
+		}
+	}
+
+	if !hasChanges {
+		return nil // No changes to commit
+	}
+
+	_, err = w.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "GitNotepad",
+			Email: "gitnotepad@local",
+			When:  time.Now(),
+		},
+	})
+
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil // Treat as no changes to commit
+		}
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	r.afterCommit()
+
 	return nil
 }
 
@@ -261,6 +375,42 @@ func (r *Repository) GetHistory(filePath string) ([]Commit, error) {
 	return commits, nil
 }
 
+// GetLog returns commits of the whole repository since a time, newest first (at most limit)
+func (r *Repository) GetLog(since time.Time, limit int) ([]Commit, error) {
+	if r.repo == nil {
+		if err := r.Open(); err != nil {
+			return []Commit{}, nil // No repository yet
+		}
+	}
+
+	iter, err := r.repo.Log(&git.LogOptions{
+		Since: &since,
+		Order: git.LogOrderCommitterTime,
+	})
+	if err != nil {
+		return []Commit{}, nil // Empty repository (no HEAD)
+	}
+
+	commits := []Commit{}
+	err = iter.ForEach(func(c *object.Commit) error {
+		if len(commits) >= limit {
+			return storer.ErrStop
+		}
+		commits = append(commits, Commit{
+			Hash:    c.Hash.String(),
+			Message: c.Message,
+			Author:  c.Author.Name,
+			Date:    c.Author.When,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log: %w", err)
+	}
+
+	return commits, nil
+}
+
 func (r *Repository) GetFileAtCommit(filePath, commitHash string) ([]byte, error) {
 	if r.repo == nil {
 		if err := r.Open(); err != nil {
@@ -299,6 +449,107 @@ func (r *Repository) GetFileAtCommit(filePath, commitHash string) ([]byte, error
 	return []byte(content), nil
 }
 
+// Push force-pushes all branches to a remote URL, authenticating with a username and token
+// The remote is not saved in the repository config, so the token never touches disk
+func (r *Repository) Push(ctx context.Context, remoteURL, username, token string) error {
+	if r.repo == nil {
+		if err := r.Open(); err != nil {
+			return err
+		}
+	}
+
+	remote := git.NewRemote(r.repo.Storer, &gitconfig.RemoteConfig{
+		Name: "mirror",
+		URLs: []string{remoteURL},
+	})
+	err := remote.PushContext(ctx, &git.PushOptions{
+		RemoteName: "mirror",
+		RefSpecs:   []gitconfig.RefSpec{"+refs/heads/*:refs/heads/*"},
+		Auth:       &githttp.BasicAuth{Username: username, Password: token},
+		Force:      true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("failed to push: %w", err)
+	}
+	return nil
+}
+
+// ErrCommitNotFound is returned when a commit hash is not in the repository
+var ErrCommitNotFound = errors.New("commit not found")
+
+// FileChange is a file added, modified or deleted between two commits
+type FileChange struct {
+	Path   string // Relative to the repository root, with forward slashes
+	Action string // "added", "modified" or "deleted"
+}
+
+// Head returns the hash of the current commit, or "" for an empty repository
+func (r *Repository) Head() (string, error) {
+	if r.repo == nil {
+		if err := r.Open(); err != nil {
+			return "", err
+		}
+	}
+
+	ref, err := r.repo.Head()
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	return ref.Hash().String(), nil
+}
+
+// ChangesSince returns the files changed between a commit and HEAD (renames are a delete and an add)
+func (r *Repository) ChangesSince(commitHash string) ([]FileChange, error) {
+	if r.repo == nil {
+		if err := r.Open(); err != nil {
+			return nil, err
+		}
+	}
+
+	from, err := r.repo.CommitObject(plumbing.NewHash(commitHash))
+	if err != nil {
+		return nil, ErrCommitNotFound
+	}
+	ref, err := r.repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	to, err := r.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+
+	fromTree, err := from.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff trees: %w", err)
+	}
+
+	result := make([]FileChange, 0, len(changes))
+	for _, change := range changes {
+		switch {
+		case change.From.Name == "":
+			result = append(result, FileChange{Path: change.To.Name, Action: "added"})
+		case change.To.Name == "":
+			result = append(result, FileChange{Path: change.From.Name, Action: "deleted"})
+		default:
+			result = append(result, FileChange{Path: change.To.Name, Action: "modified"})
+		}
+	}
+	return result, nil
+}
+
 func (r *Repository) GetPath() string {
 	return r.path
 }
@@ -1,35 +1,56 @@
 package handler
 
 import (
+	"fmt"
+	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/backup"
 	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/encryption"
+	"github.com/user/gitnotepad/internal/git"
 	"github.com/user/gitnotepad/internal/middleware"
 	"github.com/user/gitnotepad/internal/model"
 	"github.com/user/gitnotepad/internal/repository"
 )
 
 type AdminHandler struct {
-	userRepo    *repository.UserRepository
-	storagePath string
+	userRepo         *repository.UserRepository
+	shortLinkHandler *ShortLinkHandler
+	auditRepo        *repository.AuditRepository
+	noteLinkRepo     *repository.NoteLinkRepository
+	folderRuleRepo   *repository.FolderRuleRepository
+	backup           *backup.Manager
+	storagePath      string
 }
 
-func NewAdminHandler(userRepo *repository.UserRepository, storagePath string) *AdminHandler {
+func NewAdminHandler(userRepo *repository.UserRepository, shortLinkHandler *ShortLinkHandler, auditRepo *repository.AuditRepository, noteLinkRepo *repository.NoteLinkRepository, folderRuleRepo *repository.FolderRuleRepository, backupManager *backup.Manager, storagePath string) *AdminHandler {
 	return &AdminHandler{
-		userRepo:    userRepo,
-		storagePath: storagePath,
+		userRepo:         userRepo,
+		auditRepo:        auditRepo,
+		noteLinkRepo:     noteLinkRepo,
+		folderRuleRepo:   folderRuleRepo,
+		backup:           backupManager,
+		shortLinkHandler: shortLinkHandler,
+		storagePath:      storagePath,
 	}
 }
 
 // CreateUserRequest represents the request to create a new user
 type CreateUserRequest struct {
-	Username string `json:"username" binding:"required,min=3,max=32"`
-	Password string `json:"password" binding:"required,min=6"`
-	IsAdmin  bool   `json:"is_admin"`
+	Username       string `json:"username" binding:"required,min=3,max=32"`
+	Password       string `json:"password" binding:"required,min=6"`
+	IsAdmin        bool   `json:"is_admin"`
+	ReadOnly       bool   `json:"read_only"`
+	UploadDisabled bool   `json:"upload_disabled"`
 }
 
 // CreateUser creates a new user (admin only)
@@ -39,6 +60,10 @@ func (h *AdminHandler) CreateUser(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !isValidStorageName(req.Username) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid username"})
+		return
+	}
 
 	// Check if username already exists
 	existing, _ := h.userRepo.GetByUsername(req.Username)
@@ -48,8 +73,10 @@ func (h *AdminHandler) CreateUser(c *gin.Context) {
 	}
 
 	user := &model.User{
-		Username: req.Username,
-		IsAdmin:  req.IsAdmin,
+		Username:       req.Username,
+		IsAdmin:        req.IsAdmin,
+		ReadOnly:       req.ReadOnly,
+		UploadDisabled: req.UploadDisabled,
 	}
 
 	if err := user.SetPassword(req.Password); err != nil {
@@ -75,12 +102,15 @@ func (h *AdminHandler) CreateUser(c *gin.Context) {
 	if adminUser != nil {
 		adminName = adminUser.Username
 	}
-	encoding.Info("User created: username=%s, is_admin=%v, by=%s, ip=%s", user.Username, user.IsAdmin, adminName, c.ClientIP())
+	encoding.Info("User created: username=%s, %s, by=%s, ip=%s", user.Username, userFlags(user), adminName, c.ClientIP())
+	recordAudit(h.auditRepo, c, model.AuditUserCreate, user.Username, userFlags(user))
 
 	c.JSON(http.StatusCreated, gin.H{
-		"id":       user.ID,
-		"username": user.Username,
-		"is_admin": user.IsAdmin,
+		"id":              user.ID,
+		"username":        user.Username,
+		"is_admin":        user.IsAdmin,
+		"read_only":       user.ReadOnly,
+		"upload_disabled": user.UploadDisabled,
 	})
 }
 
@@ -92,14 +122,22 @@ func (h *AdminHandler) ListUsers(c *gin.Context) {
 		return
 	}
 
+	// Include storage usage when requested (walks every user directory)
+	withUsage := c.Query("usage") == "true"
+
 	// Return users without password hash
 	result := make([]gin.H, len(users))
 	for i, user := range users {
 		result[i] = gin.H{
-			"id":         user.ID,
-			"username":   user.Username,
-			"is_admin":   user.IsAdmin,
-			"created_at": user.CreatedAt,
+			"id":              user.ID,
+			"username":        user.Username,
+			"is_admin":        user.IsAdmin,
+			"read_only":       user.ReadOnly,
+			"upload_disabled": user.UploadDisabled,
+			"created_at":      user.CreatedAt,
+		}
+		if withUsage {
+			result[i]["usage"] = h.getUserUsage(user)
 		}
 	}
 
@@ -124,14 +162,7 @@ func (h *AdminHandler) DeleteUser(c *gin.Context) {
 
 	// Prevent deleting the last admin
 	if user.IsAdmin {
-		users, _ := h.userRepo.List()
-		adminCount := 0
-		for _, u := range users {
-			if u.IsAdmin {
-				adminCount++
-			}
-		}
-		if adminCount <= 1 {
+		if h.countAdmins() <= 1 {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Cannot delete the last admin"})
 			return
 		}
@@ -156,10 +187,95 @@ func (h *AdminHandler) DeleteUser(c *gin.Context) {
 		adminName = adminUser.Username
 	}
 	encoding.Info("User deleted: username=%s, by=%s, ip=%s", user.Username, adminName, c.ClientIP())
+	recordAudit(h.auditRepo, c, model.AuditUserDelete, user.Username, "")
 
 	c.JSON(http.StatusOK, gin.H{"message": "User deleted"})
 }
 
+// userFlags describes a user's admin flag and restrictions for logs and the audit trail
+func userFlags(user *model.User) string {
+	return fmt.Sprintf("is_admin=%v, read_only=%v, upload_disabled=%v", user.IsAdmin, user.ReadOnly, user.UploadDisabled)
+}
+
+// countAdmins returns the number of admin users
+func (h *AdminHandler) countAdmins() int {
+	users, _ := h.userRepo.List()
+	adminCount := 0
+	for _, u := range users {
+		if u.IsAdmin {
+			adminCount++
+		}
+	}
+	return adminCount
+}
+
+// UpdateUserRequest represents the request to update user attributes
+// Fields are optional; nil means unchanged
+type UpdateUserRequest struct {
+	IsAdmin        *bool `json:"is_admin"`
+	ReadOnly       *bool `json:"read_only"`
+	UploadDisabled *bool `json:"upload_disabled"`
+}
+
+// UpdateUser updates user attributes such as the admin flag and restrictions (admin only)
+func (h *AdminHandler) UpdateUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userRepo.GetByID(id)
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if req.IsAdmin != nil && *req.IsAdmin != user.IsAdmin {
+		// Prevent demoting the last admin
+		if user.IsAdmin && h.countAdmins() <= 1 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Cannot demote the last admin"})
+			return
+		}
+		user.IsAdmin = *req.IsAdmin
+	}
+	if req.ReadOnly != nil {
+		user.ReadOnly = *req.ReadOnly
+	}
+	if req.UploadDisabled != nil {
+		user.UploadDisabled = *req.UploadDisabled
+	}
+
+	if err := h.userRepo.Update(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+
+	// Log user update
+	adminUser := middleware.GetCurrentUser(c)
+	adminName := "unknown"
+	if adminUser != nil {
+		adminName = adminUser.Username
+	}
+	encoding.Info("User updated: username=%s, %s, by=%s, ip=%s", user.Username, userFlags(user), adminName, c.ClientIP())
+	recordAudit(h.auditRepo, c, model.AuditUserUpdate, user.Username, userFlags(user))
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":              user.ID,
+		"username":        user.Username,
+		"is_admin":        user.IsAdmin,
+		"read_only":       user.ReadOnly,
+		"upload_disabled": user.UploadDisabled,
+	})
+}
+
 // UpdatePasswordRequest represents the request to update password
 type UpdatePasswordRequest struct {
 	Password string `json:"password" binding:"required,min=6"`
@@ -203,6 +319,540 @@ func (h *AdminHandler) UpdatePassword(c *gin.Context) {
 		adminName = adminUser.Username
 	}
 	encoding.Info("Password changed: username=%s, by=%s, ip=%s", user.Username, adminName, c.ClientIP())
+	recordAudit(h.auditRepo, c, model.AuditUserPassword, user.Username, "")
 
 	c.JSON(http.StatusOK, gin.H{"message": "Password updated"})
 }
+
+// RenameUsernameRequest represents the request to rename a user
+type RenameUsernameRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=32"`
+}
+
+// isValidStorageName reports whether a username is safe to use as a storage directory name
+func isValidStorageName(name string) bool {
+	if strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\:*?"<>|`) {
+		return false
+	}
+	// Reserved top-level directories in the storage root
+	return name != "files" && name != "images"
+}
+
+// isValidUsername reports whether a name can be given to an account: 3 to 32 characters, safe as a storage directory name
+func isValidUsername(name string) bool {
+	length := utf8.RuneCountInString(name)
+	return length >= 3 && length <= 32 && isValidStorageName(name)
+}
+
+// RenameUsername renames a user, moving their storage directory and rewriting attachment URLs (admin only)
+// The rename is all or nothing: if any step fails, the directory, notes and database are restored
+func (h *AdminHandler) RenameUsername(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req RenameUsernameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !isValidStorageName(req.Username) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid username"})
+		return
+	}
+
+	user, err := h.userRepo.GetByID(id)
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	oldUsername := user.Username
+	newUsername := req.Username
+	if oldUsername == newUsername {
+		c.JSON(http.StatusOK, gin.H{"message": "Username unchanged"})
+		return
+	}
+
+	// Check if username already exists
+	existing, _ := h.userRepo.GetByUsername(newUsername)
+	if existing != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
+		return
+	}
+
+	oldDir := filepath.Join(h.storagePath, oldUsername)
+	newDir := filepath.Join(h.storagePath, newUsername)
+	if _, err := os.Stat(newDir); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Storage directory already exists"})
+		return
+	}
+
+	// Prepare the rewritten notes before changing anything
+	_, statErr := os.Stat(oldDir)
+	dirExists := statErr == nil
+	var rewrites []urlRewrite
+	if dirExists {
+		var encrypted int
+		rewrites, encrypted, err = planAttachmentURLRewrites(oldDir, oldUsername, newUsername)
+		if err != nil {
+			encoding.Error("Failed to read notes of %s for rename: %v", oldUsername, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read notes"})
+			return
+		}
+		// Attachment URLs in encrypted notes can't be rewritten without the user's key
+		if encrypted > 0 {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":           "User has encrypted notes whose attachment URLs can't be rewritten",
+				"encrypted_notes": encrypted,
+			})
+			return
+		}
+	}
+
+	// Move storage directory
+	if dirExists {
+		if err := os.Rename(oldDir, newDir); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rename storage directory"})
+			return
+		}
+	}
+	rollback := func() {
+		restoreURLRewrites(newDir, rewrites)
+		if dirExists {
+			if err := os.Rename(newDir, oldDir); err != nil {
+				encoding.Error("Failed to restore storage directory %s -> %s: %v", newDir, oldDir, err)
+			}
+		}
+	}
+
+	// Rewrite attachment URLs (/u/{old}/...) inside the user's notes
+	if err := applyURLRewrites(newDir, rewrites); err != nil {
+		encoding.Error("Failed to rewrite notes of %s: %v", oldUsername, err)
+		rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rewrite attachment URLs"})
+		return
+	}
+
+	// Update DB
+	user.Username = newUsername
+	if err := h.userRepo.Update(user); err != nil {
+		user.Username = oldUsername
+		rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update username"})
+		return
+	}
+
+	// Commit rewritten notes in the user's repository (moved along with the directory)
+	if len(rewrites) > 0 {
+		err := func() error {
+			userRepo, err := git.NewRepository(newDir)
+			if err != nil {
+				return err
+			}
+			if err := userRepo.Init(); err != nil {
+				return err
+			}
+			msg := fmt.Sprintf("Rename user: %s -> %s", oldUsername, newUsername)
+			return userRepo.AddPathsAndCommit([]string{filepath.Join(newDir, "notes")}, msg)
+		}()
+		if err != nil {
+			encoding.Error("Failed to commit user rename %s -> %s: %v", oldUsername, newUsername, err)
+			user.Username = oldUsername
+			if err := h.userRepo.Update(user); err != nil {
+				encoding.Error("Failed to restore username %s: %v", oldUsername, err)
+			}
+			rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit rename"})
+			return
+		}
+	}
+
+	// Update short links and drop cached attachment metadata
+	if h.shortLinkHandler != nil {
+		h.shortLinkHandler.RenameUser(oldUsername, newUsername)
+	}
+	if err := h.noteLinkRepo.RenameUser(oldUsername, newUsername); err != nil {
+		encoding.Warn("Failed to rename note links %s -> %s: %v", oldUsername, newUsername, err)
+	}
+	invalidateAttachmentMetadata(oldUsername)
+	invalidateAttachmentMetadata(newUsername)
+
+	// Log username change
+	adminUser := middleware.GetCurrentUser(c)
+	adminName := "unknown"
+	if adminUser != nil {
+		adminName = adminUser.Username
+	}
+	encoding.Info("Username changed: %s -> %s, rewritten=%d, by=%s, ip=%s",
+		oldUsername, newUsername, len(rewrites), adminName, c.ClientIP())
+	recordAudit(h.auditRepo, c, model.AuditUserRename, newUsername, "from="+oldUsername)
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":              user.ID,
+		"username":        user.Username,
+		"rewritten_notes": len(rewrites),
+	})
+}
+
+// urlRewrite is a note whose attachment URLs change with a user rename
+type urlRewrite struct {
+	relPath  string // Relative to the user's storage directory
+	original []byte
+	updated  []byte
+}
+
+// planAttachmentURLRewrites returns the notes of a user referring to /u/{old}/, with /u/{new}/ in their place,
+// and the number of encrypted notes, which can't be rewritten without the user's key
+func planAttachmentURLRewrites(userDir, oldUsername, newUsername string) (rewrites []urlRewrite, encrypted int, err error) {
+	oldPrefix := "/u/" + oldUsername + "/"
+	newPrefix := "/u/" + newUsername + "/"
+
+	notesPath := filepath.Join(userDir, "notes")
+	err = filepath.WalkDir(notesPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == notesPath {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		ext := filepath.Ext(path)
+		if ext != ".md" && ext != ".txt" && ext != ".adoc" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		content := string(data)
+		if encryption.IsEncrypted(content) {
+			encrypted++
+			return nil
+		}
+		if !strings.Contains(content, oldPrefix) {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(userDir, path)
+		if err != nil {
+			return err
+		}
+		rewrites = append(rewrites, urlRewrite{
+			relPath:  relPath,
+			original: data,
+			updated:  []byte(strings.ReplaceAll(content, oldPrefix, newPrefix)),
+		})
+		return nil
+	})
+	return rewrites, encrypted, err
+}
+
+// applyURLRewrites writes the rewritten notes into a user's storage directory
+func applyURLRewrites(userDir string, rewrites []urlRewrite) error {
+	for _, rw := range rewrites {
+		if err := os.WriteFile(filepath.Join(userDir, rw.relPath), rw.updated, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreURLRewrites writes back the content the notes had before applyURLRewrites
+func restoreURLRewrites(userDir string, rewrites []urlRewrite) {
+	for _, rw := range rewrites {
+		if err := os.WriteFile(filepath.Join(userDir, rw.relPath), rw.original, 0644); err != nil {
+			encoding.Error("Failed to restore %s: %v", rw.relPath, err)
+		}
+	}
+}
+
+// UserUsage describes storage consumed by a single user
+type UserUsage struct {
+	UserID          int64      `json:"user_id"`
+	Username        string     `json:"username"`
+	NotesBytes      int64      `json:"notes_bytes"`
+	AttachmentBytes int64      `json:"attachment_bytes"`
+	OtherBytes      int64      `json:"other_bytes"`
+	TotalBytes      int64      `json:"total_bytes"`
+	NoteCount       int        `json:"note_count"`
+	AttachmentCount int        `json:"attachment_count"`
+	LastActivity    *time.Time `json:"last_activity,omitempty"`
+	GitBytes        int64      `json:"git_bytes"` // Note history (.git)
+}
+
+// dirUsage walks a directory and returns total size, file count, and latest modification time
+// Files for which skip returns true are ignored
+func dirUsage(root string, skip func(path string, d fs.DirEntry) bool) (size int64, count int, latest time.Time) {
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if skip != nil && skip(path, d) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		size += info.Size()
+		count++
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return size, count, latest
+}
+
+// getUserUsage computes storage usage for a user
+func (h *AdminHandler) getUserUsage(user *model.User) UserUsage {
+	userDir := filepath.Join(h.storagePath, user.Username)
+	notesDir := filepath.Join(userDir, "notes")
+	filesDir := filepath.Join(userDir, "files")
+
+	usage := UserUsage{
+		UserID:   user.ID,
+		Username: user.Username,
+	}
+
+	var notesLatest, filesLatest time.Time
+	usage.NotesBytes, _, notesLatest = dirUsage(notesDir, nil)
+	filepath.WalkDir(notesDir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			ext := filepath.Ext(path)
+			if ext == ".md" || ext == ".txt" || ext == ".adoc" {
+				usage.NoteCount++
+			}
+		}
+		return nil
+	})
+
+	// Attachment metadata files (.filemeta.json, .imagemeta.json) are not counted as attachments
+	usage.AttachmentBytes, usage.AttachmentCount, filesLatest = dirUsage(filesDir, func(path string, d fs.DirEntry) bool {
+		return !d.IsDir() && strings.HasPrefix(d.Name(), ".")
+	})
+
+	// Each user directory is its own git repository
+	usage.GitBytes, _, _ = dirUsage(filepath.Join(userDir, ".git"), nil)
+
+	total, _, _ := dirUsage(userDir, nil)
+	usage.TotalBytes = total
+	usage.OtherBytes = total - usage.NotesBytes - usage.AttachmentBytes - usage.GitBytes
+
+	latest := notesLatest
+	if filesLatest.After(latest) {
+		latest = filesLatest
+	}
+	if !latest.IsZero() {
+		usage.LastActivity = &latest
+	}
+
+	return usage
+}
+
+// GetUserUsage returns storage usage for a user (admin only)
+func (h *AdminHandler) GetUserUsage(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, err := h.userRepo.GetByID(id)
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.getUserUsage(user))
+}
+
+// ActivityFeedItem is an entry of the admin activity feed (a note commit or an audit event)
+type ActivityFeedItem struct {
+	Time     time.Time `json:"time"`
+	Username string    `json:"username"`
+	Source   string    `json:"source"` // "git" or "audit"
+	Action   string    `json:"action"`
+	Target   string    `json:"target,omitempty"`
+	Detail   string    `json:"detail,omitempty"`
+	Commit   string    `json:"commit,omitempty"`
+}
+
+// Activity returns recent activity across all users, combining note history and audit events (admin only)
+// Query: since=YYYY-MM-DD (default 7 days ago), username (optional), limit (default 100, max 500)
+func (h *AdminHandler) Activity(c *gin.Context) {
+	since := time.Now().AddDate(0, 0, -7)
+	if s := c.Query("since"); s != "" {
+		t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since (YYYY-MM-DD)"})
+			return
+		}
+		since = t
+	}
+
+	limit := 100
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	username := c.Query("username")
+
+	users, err := h.userRepo.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list users"})
+		return
+	}
+
+	items := []ActivityFeedItem{}
+
+	// Note history: each user directory is its own git repository
+	for _, user := range users {
+		if username != "" && user.Username != username {
+			continue
+		}
+		userDir := filepath.Join(h.storagePath, user.Username)
+		if _, err := os.Stat(filepath.Join(userDir, ".git")); err != nil {
+			continue
+		}
+		userRepo, err := git.NewRepository(userDir)
+		if err != nil {
+			continue
+		}
+		commits, err := userRepo.GetLog(since, limit)
+		if err != nil {
+			encoding.Debug("Failed to read history of %s: %v", user.Username, err)
+			continue
+		}
+		for _, commit := range commits {
+			// Commit messages look like "Update note: Title"
+			message := strings.TrimSpace(commit.Message)
+			action, target, _ := strings.Cut(message, ": ")
+			items = append(items, ActivityFeedItem{
+				Time:     commit.Date,
+				Username: user.Username,
+				Source:   "git",
+				Action:   action,
+				Target:   target,
+				Commit:   commit.Hash,
+			})
+		}
+	}
+
+	if h.auditRepo != nil {
+		events, err := h.auditRepo.List(since, username, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list audit events"})
+			return
+		}
+		for _, event := range events {
+			items = append(items, ActivityFeedItem{
+				Time:     event.CreatedAt,
+				Username: event.Username,
+				Source:   "audit",
+				Action:   event.Action,
+				Target:   event.Target,
+				Detail:   event.Detail,
+			})
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Time.After(items[j].Time)
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// Stats returns server-wide statistics and the backup status (admin only)
+func (h *AdminHandler) Stats(c *gin.Context) {
+	users, err := h.userRepo.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list users"})
+		return
+	}
+
+	admins := 0
+	for _, user := range users {
+		if user.IsAdmin {
+			admins++
+		}
+	}
+
+	result := gin.H{
+		"users":  len(users),
+		"admins": admins,
+	}
+	if h.backup != nil {
+		result["backup"] = h.backup.Status()
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RunBackup starts a backup immediately (admin only)
+func (h *AdminHandler) RunBackup(c *gin.Context) {
+	if h.backup == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Backup is not available"})
+		return
+	}
+	if h.backup.Status().Running {
+		c.JSON(http.StatusConflict, gin.H{"error": "Backup already running"})
+		return
+	}
+
+	recordAudit(h.auditRepo, c, model.AuditBackupRun, "", "")
+
+	go func() {
+		if err := h.backup.Run(); err != nil {
+			encoding.Warn("Backup failed: %v", err)
+		}
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Backup started"})
+}
+
+// ListShortLinks returns the short links of all users with their owners (admin only)
+func (h *AdminHandler) ListShortLinks(c *gin.Context) {
+	if h.shortLinkHandler == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Short links are not available"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.shortLinkHandler.ListAll())
+}
+
+// CleanupShortLinks removes expired short links now instead of waiting for the scheduler (admin only)
+func (h *AdminHandler) CleanupShortLinks(c *gin.Context) {
+	if h.shortLinkHandler == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Short links are not available"})
+		return
+	}
+
+	removed := h.shortLinkHandler.CleanupExpired()
+	recordAudit(h.auditRepo, c, model.AuditShortLinkCleanup, "", fmt.Sprintf("%d removed", removed))
+
+	c.JSON(http.StatusOK, gin.H{"removed": removed})
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// SetFolderRuleRequest represents the request to limit a user's access to a folder of their notes
+type SetFolderRuleRequest struct {
+	FolderPath string                `json:"folder_path" binding:"required"`
+	Permission model.SharePermission `json:"permission" binding:"required"`
+}
+
+// ListFolderRules returns the folder rules of a user (admin only)
+func (h *AdminHandler) ListFolderRules(c *gin.Context) {
+	user := h.paramUser(c)
+	if user == nil {
+		return
+	}
+
+	rules, err := h.folderRuleRepo.ListByUser(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list folder rules"})
+		return
+	}
+	if rules == nil {
+		rules = []*model.FolderRule{}
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// SetFolderRule creates a folder rule for a user, or changes the permission of the existing one (admin only)
+// The folder doesn't have to exist yet
+func (h *AdminHandler) SetFolderRule(c *gin.Context) {
+	user := h.paramUser(c)
+	if user == nil {
+		return
+	}
+
+	var req SetFolderRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Permission != model.PermissionNone && !req.Permission.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid permission (none, read, write, manage)"})
+		return
+	}
+	folderPath, ok := cleanFolderPath(req.FolderPath)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+		return
+	}
+
+	rule := &model.FolderRule{
+		UserID:     user.ID,
+		FolderPath: folderPath,
+		Permission: req.Permission,
+	}
+	if err := h.folderRuleRepo.Upsert(rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save folder rule"})
+		return
+	}
+
+	adminUser := middleware.GetCurrentUser(c)
+	encoding.Info("Folder rule set: username=%s, folder=%s, permission=%s, by=%s, ip=%s",
+		user.Username, folderPath, rule.Permission, adminUser.Username, c.ClientIP())
+	recordAudit(h.auditRepo, c, model.AuditUserUpdate, user.Username, fmt.Sprintf("folder_rule=%s, permission=%s", folderPath, rule.Permission))
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteFolderRule removes a folder rule of a user (admin only)
+func (h *AdminHandler) DeleteFolderRule(c *gin.Context) {
+	user := h.paramUser(c)
+	if user == nil {
+		return
+	}
+
+	ruleID, err := strconv.ParseInt(c.Param("ruleId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+	deleted, err := h.folderRuleRepo.Delete(user.ID, ruleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete folder rule"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Folder rule not found"})
+		return
+	}
+
+	adminUser := middleware.GetCurrentUser(c)
+	encoding.Info("Folder rule deleted: username=%s, rule=%d, by=%s, ip=%s", user.Username, ruleID, adminUser.Username, c.ClientIP())
+	recordAudit(h.auditRepo, c, model.AuditUserUpdate, user.Username, fmt.Sprintf("folder_rule_deleted=%d", ruleID))
+
+	c.JSON(http.StatusOK, gin.H{"message": "Folder rule deleted"})
+}
+
+// paramUser returns the user named by the :id parameter, or writes the error response and returns nil
+func (h *AdminHandler) paramUser(c *gin.Context) *model.User {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return nil
+	}
+	user, err := h.userRepo.GetByID(id)
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil
+	}
+	return user
+}
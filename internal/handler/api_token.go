@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encryption"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// CreateTokenRequest exchanges the current session for an API token
+type CreateTokenRequest struct {
+	Name      string   `json:"name"`       // e.g. "Web clipper (Firefox)"
+	Scopes    []string `json:"scopes"`     // read, clip, write (default: read and clip)
+	ExpiresIn int      `json:"expires_in"` // Hours (default and maximum: auth.token_lifetime)
+}
+
+// CreateToken issues a scoped API token with a limited lifetime to the signed-in user,
+// so a browser extension, script or shortcut can call the API without the session cookie
+// The token is only returned here; the server keeps its SHA-256
+func (h *AuthHandler) CreateToken(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req CreateTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scopes := []string{model.ScopeRead, model.ScopeClip}
+	if len(req.Scopes) > 0 {
+		scopes = nil
+		for _, scope := range req.Scopes {
+			if !slices.Contains(model.APITokenScopes, scope) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scope (read, clip, write)"})
+				return
+			}
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	lifetime := h.config.Auth.TokenLifetime
+	if req.ExpiresIn > 0 && req.ExpiresIn < lifetime {
+		lifetime = req.ExpiresIn
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Browser extension"
+	}
+
+	token, secret := model.NewAPIToken(user.ID, name, scopes, time.Duration(lifetime)*time.Hour)
+
+	// Notes are encrypted with a key derived from the password at login: the token gets the key of this session
+	var key []byte
+	if h.config.Encryption.Enabled {
+		if key = middleware.GetEncryptionKey(c); key == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Sign in again to create a token (encryption key not available)"})
+			return
+		}
+		token.Encrypted = true
+	}
+
+	if err := h.tokenRepo.Create(token); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
+	if key != nil {
+		encryption.GetKeyStore().Store(token.TokenHash, key)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":         token.ID,
+		"token":      secret,
+		"name":       token.Name,
+		"scopes":     token.Scopes,
+		"expires_at": token.ExpiresAt,
+	})
+}
+
+// ListTokens returns the unexpired API tokens of the current user (without their values)
+func (h *AuthHandler) ListTokens(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	tokens, err := h.tokenRepo.ListByUser(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tokens"})
+		return
+	}
+	if tokens == nil {
+		tokens = []*model.APIToken{}
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// DeleteToken revokes an API token of the current user
+func (h *AuthHandler) DeleteToken(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token ID"})
+		return
+	}
+
+	// Drop the encryption key kept for the token
+	tokens, _ := h.tokenRepo.ListByUser(user.ID)
+	for _, token := range tokens {
+		if token.ID == id {
+			encryption.GetKeyStore().Delete(token.TokenHash)
+		}
+	}
+
+	deleted, err := h.tokenRepo.Delete(id, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete token"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked"})
+}
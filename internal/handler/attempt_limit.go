@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"sync"
+	"time"
+)
+
+// attemptLimiter slows down guessing of secrets such as link passphrases: once a key has failed
+// maxFailures times, its attempts are refused until the lockout has passed
+type attemptLimiter struct {
+	mu          sync.Mutex
+	maxFailures int
+	lockout     time.Duration
+	failures    map[string]*failedAttempts
+}
+
+type failedAttempts struct {
+	count       int
+	last        time.Time
+	lockedUntil time.Time
+}
+
+func newAttemptLimiter(maxFailures int, lockout time.Duration) *attemptLimiter {
+	return &attemptLimiter{
+		maxFailures: maxFailures,
+		lockout:     lockout,
+		failures:    make(map[string]*failedAttempts),
+	}
+}
+
+// Allow reports whether a key may make an attempt, and if not, how long until it may
+func (l *attemptLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, ok := l.failures[key]
+	if !ok {
+		return true, 0
+	}
+	if wait := time.Until(f.lockedUntil); wait > 0 {
+		return false, wait
+	}
+	return true, 0
+}
+
+// Fail records a failed attempt, locking the key out once it reaches maxFailures
+func (l *attemptLimiter) Fail(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop keys whose failures are older than the lockout
+	now := time.Now()
+	for k, f := range l.failures {
+		if now.Sub(f.last) > l.lockout && now.After(f.lockedUntil) {
+			delete(l.failures, k)
+		}
+	}
+
+	f, ok := l.failures[key]
+	if !ok {
+		f = &failedAttempts{}
+		l.failures[key] = f
+	}
+	f.count++
+	f.last = now
+	if f.count >= l.maxFailures {
+		f.count = 0
+		f.lockedUntil = now.Add(l.lockout)
+	}
+}
+
+// Reset forgets the failures of a key after a successful attempt
+func (l *attemptLimiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, key)
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/repository"
+)
+
+// recordAudit stores an audit event for the current user; failures are logged but never fail the request
+func recordAudit(audit *repository.AuditRepository, c *gin.Context, action, target, detail string) {
+	if audit == nil {
+		return
+	}
+
+	username := ""
+	if user := middleware.GetCurrentUser(c); user != nil {
+		username = user.Username
+	}
+
+	event := &model.AuditEvent{
+		Username: username,
+		Action:   action,
+		Target:   target,
+		Detail:   detail,
+		IP:       c.ClientIP(),
+	}
+	if err := audit.Create(event); err != nil {
+		encoding.Warn("Failed to record audit event %s: %v", action, err)
+	}
+}
@@ -3,6 +3,8 @@ package handler
 import (
 	"net/http"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,25 +14,44 @@ import (
 	"github.com/user/gitnotepad/internal/git"
 	"github.com/user/gitnotepad/internal/middleware"
 	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/oidc"
 	"github.com/user/gitnotepad/internal/repository"
 )
 
 const SessionDuration = 7 * 24 * time.Hour // 7 days
 
 type AuthHandler struct {
-	repo        *git.Repository
-	userRepo    *repository.UserRepository
-	sessionRepo *repository.SessionRepository
-	config      *config.Config
+	repo         *git.Repository
+	userRepo     *repository.UserRepository
+	sessionRepo  *repository.SessionRepository
+	tokenRepo    *repository.APITokenRepository
+	identityRepo *repository.OIDCIdentityRepository
+	config       *config.Config
+
+	oidc       *oidc.Provider // nil unless auth.oidc is enabled
+	oidcMu     sync.Mutex
+	oidcLogins map[string]*oidcLogin // Logins waiting for the provider's callback, by state
 }
 
-func NewAuthHandler(repo *git.Repository, userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, cfg *config.Config) *AuthHandler {
-	return &AuthHandler{
-		repo:        repo,
-		userRepo:    userRepo,
-		sessionRepo: sessionRepo,
-		config:      cfg,
+func NewAuthHandler(repo *git.Repository, userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, tokenRepo *repository.APITokenRepository, identityRepo *repository.OIDCIdentityRepository, cfg *config.Config) *AuthHandler {
+	h := &AuthHandler{
+		repo:         repo,
+		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
+		tokenRepo:    tokenRepo,
+		identityRepo: identityRepo,
+		config:       cfg,
 	}
+	if OIDCEnabled(cfg) {
+		h.oidc = oidc.New(oidc.Config{
+			Issuer:       cfg.Auth.OIDC.Issuer,
+			ClientID:     cfg.Auth.OIDC.ClientID,
+			ClientSecret: cfg.Auth.OIDC.ClientSecret,
+			Scopes:       cfg.Auth.OIDC.Scopes,
+		})
+		h.oidcLogins = make(map[string]*oidcLogin)
+	}
+	return h
 }
 
 // LoginRequest represents login credentials
@@ -56,32 +77,11 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// Create session
-	session := model.NewSession(user.ID, SessionDuration)
-	if err := h.sessionRepo.Create(session); err != nil {
+	if err := h.startSession(c, user, req.Password); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
 		return
 	}
 
-	// Derive and store encryption key if encryption is enabled
-	if h.config.Encryption.Enabled && h.config.Encryption.Salt != "" {
-		key, err := encryption.DeriveKey(req.Password, h.config.Encryption.Salt)
-		if err == nil {
-			encryption.GetKeyStore().Store(session.Token, key)
-		}
-	}
-
-	// Set cookie
-	c.SetCookie(
-		middleware.SessionCookieName,
-		session.Token,
-		int(SessionDuration.Seconds()),
-		"/",
-		"",
-		false, // secure (set true in production with HTTPS)
-		true,  // httpOnly
-	)
-
 	encoding.Info("Login success: username=%s, ip=%s, is_admin=%v", user.Username, clientIP, user.IsAdmin)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -94,6 +94,33 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	})
 }
 
+// startSession creates a session for the user and sets its cookie
+// password derives the encryption key when encryption is enabled (empty for single sign-on)
+func (h *AuthHandler) startSession(c *gin.Context, user *model.User, password string) error {
+	session := model.NewSession(user.ID, SessionDuration)
+	session.UserAgent = strings.ToValidUTF8(c.Request.UserAgent(), "")
+	if len(session.UserAgent) > 512 {
+		session.UserAgent = strings.ToValidUTF8(session.UserAgent[:512], "")
+	}
+	session.IP = c.ClientIP()
+	session.LastUsedAt = &session.CreatedAt
+	if err := h.sessionRepo.Create(session); err != nil {
+		return err
+	}
+
+	// Derive and store encryption key if encryption is enabled
+	if salt := user.KeySalt(h.config.Encryption.Salt); password != "" && h.config.Encryption.Enabled && salt != "" {
+		key, err := encryption.DeriveKey(password, salt)
+		if err == nil {
+			encryption.GetKeyStore().Store(session.Token, key)
+		}
+	}
+
+	// Set cookie
+	middleware.SetSessionCookie(c, h.config.Auth.Cookie, session.Token, int(SessionDuration.Seconds()))
+	return nil
+}
+
 // Logout handles session termination
 func (h *AuthHandler) Logout(c *gin.Context) {
 	clientIP := c.ClientIP()
@@ -113,7 +140,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 
 	encoding.Info("Logout: username=%s, ip=%s", username, clientIP)
 
-	c.SetCookie(middleware.SessionCookieName, "", -1, "/", "", false, true)
+	middleware.SetSessionCookie(c, h.config.Auth.Cookie, "", -1)
 	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
 }
 
@@ -126,9 +153,11 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"id":       user.ID,
-		"username": user.Username,
-		"is_admin": user.IsAdmin,
+		"id":              user.ID,
+		"username":        user.Username,
+		"is_admin":        user.IsAdmin,
+		"read_only":       user.ReadOnly,
+		"upload_disabled": user.UploadDisabled,
 	})
 }
 
@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/config"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/oidc"
+)
+
+const (
+	oidcStateCookie = "gitnotepad_oidc_state"
+	oidcLoginTTL    = 10 * time.Minute // Time allowed for signing in at the provider
+)
+
+// errUsernameTaken is returned when a new account's username belongs to a user it isn't linked to
+var errUsernameTaken = errors.New("username already taken")
+
+// oidcLogin is a login waiting for the provider's callback
+type oidcLogin struct {
+	request     *oidc.AuthRequest
+	redirectURL string
+	expires     time.Time
+}
+
+// OIDCEnabled reports whether users can sign in through the OpenID Connect provider
+// Encrypted notes need a key derived from the user's password, so single sign-on is not offered with encryption
+func OIDCEnabled(cfg *config.Config) bool {
+	return cfg.Auth.Enabled && cfg.Auth.OIDC.Enabled && !cfg.Encryption.Enabled
+}
+
+// OIDCLogin sends the browser to the provider's sign-in page
+func (h *AuthHandler) OIDCLogin(c *gin.Context) {
+	redirectURL := h.config.Auth.OIDC.RedirectURL
+	if redirectURL == "" {
+		redirectURL = requestOrigin(c) + h.config.Server.BasePath + "/auth/oidc/callback"
+	}
+
+	ar, err := h.oidc.AuthCodeURL(c.Request.Context(), redirectURL)
+	if err != nil {
+		h.oidcFail(c, "failed", err)
+		return
+	}
+
+	h.oidcMu.Lock()
+	now := time.Now()
+	for state, login := range h.oidcLogins {
+		if now.After(login.expires) {
+			delete(h.oidcLogins, state)
+		}
+	}
+	h.oidcLogins[ar.State] = &oidcLogin{request: ar, redirectURL: redirectURL, expires: now.Add(oidcLoginTTL)}
+	h.oidcMu.Unlock()
+
+	// The state cookie ties the callback to the browser that started the login
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, ar.State, int(oidcLoginTTL.Seconds()), "/", "", middleware.IsHTTPS(c), true)
+	c.Redirect(http.StatusFound, ar.URL)
+}
+
+// OIDCCallback finishes a login: the user linked to the provider account is signed in,
+// or created on the first sign-in
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	state := c.Query("state")
+	cookie, _ := c.Cookie(oidcStateCookie)
+	c.SetCookie(oidcStateCookie, "", -1, "/", "", middleware.IsHTTPS(c), true)
+
+	h.oidcMu.Lock()
+	login := h.oidcLogins[state]
+	delete(h.oidcLogins, state)
+	h.oidcMu.Unlock()
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		h.oidcFail(c, "failed", errors.New(providerErr+": "+c.Query("error_description")))
+		return
+	}
+	if state == "" || cookie != state || login == nil || time.Now().After(login.expires) {
+		h.oidcFail(c, "failed", errors.New("unknown or expired state"))
+		return
+	}
+
+	claims, err := h.oidc.Exchange(c.Request.Context(), c.Query("code"), login.redirectURL, login.request)
+	if err != nil {
+		h.oidcFail(c, "failed", err)
+		return
+	}
+	if !h.oidcAllowed(claims) {
+		h.oidcFail(c, "not_allowed", errors.New("subject="+claims.String("sub")+" email="+claims.String("email")))
+		return
+	}
+
+	user, err := h.oidcUser(claims)
+	if err != nil {
+		code := "failed"
+		if errors.Is(err, errUsernameTaken) {
+			code = "username_taken"
+		}
+		h.oidcFail(c, code, err)
+		return
+	}
+
+	if err := h.startSession(c, user, ""); err != nil {
+		h.oidcFail(c, "failed", err)
+		return
+	}
+	encoding.Info("Login success: username=%s, ip=%s, is_admin=%v, method=oidc", user.Username, c.ClientIP(), user.IsAdmin)
+	c.Redirect(http.StatusFound, h.config.Server.BasePath+"/")
+}
+
+// oidcFail logs a failed login and returns to the login page, which shows the reason
+func (h *AuthHandler) oidcFail(c *gin.Context, reason string, err error) {
+	encoding.Warn("Login failed: ip=%s, method=oidc, reason=%s: %v", c.ClientIP(), reason, err)
+	c.Redirect(http.StatusFound, h.config.Server.BasePath+"/login?oidc_error="+reason)
+}
+
+// oidcAllowed checks allowed_domains and allowed_groups
+func (h *AuthHandler) oidcAllowed(claims oidc.Claims) bool {
+	cfg := h.config.Auth.OIDC
+	if len(cfg.AllowedDomains) > 0 {
+		_, domain, ok := strings.Cut(claims.String("email"), "@")
+		if !ok || !claims.Bool("email_verified") || !slices.ContainsFunc(cfg.AllowedDomains, func(d string) bool {
+			return strings.EqualFold(strings.TrimPrefix(d, "@"), domain)
+		}) {
+			return false
+		}
+	}
+	if len(cfg.AllowedGroups) > 0 && !inGroups(claims, cfg.AllowedGroups) {
+		return false
+	}
+	return true
+}
+
+func inGroups(claims oidc.Claims, groups []string) bool {
+	for _, group := range claims.Strings("groups") {
+		if slices.Contains(groups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// oidcUser returns the user linked to the provider account, linking or creating one on the first sign-in
+func (h *AuthHandler) oidcUser(claims oidc.Claims) (*model.User, error) {
+	issuer := strings.TrimSuffix(h.config.Auth.OIDC.Issuer, "/")
+	subject := claims.String("sub")
+
+	userID, err := h.identityRepo.GetUserID(issuer, subject)
+	if err != nil {
+		return nil, err
+	}
+	if userID != 0 {
+		if user, err := h.userRepo.GetByID(userID); err != nil || user != nil {
+			return user, err
+		}
+		// The linked user was deleted: treat it as a first sign-in
+	}
+
+	username := oidcUsername(claims, h.config.Auth.OIDC.UsernameClaim)
+	if username == "" {
+		return nil, errors.New("no usable username in claims")
+	}
+	user, err := h.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil && !h.config.Auth.OIDC.LinkExisting {
+		return nil, errUsernameTaken
+	}
+
+	if user == nil {
+		// The password is random: the account signs in through the provider until an admin sets one
+		password := make([]byte, 32)
+		rand.Read(password)
+		user = &model.User{
+			Username: username,
+			IsAdmin:  inGroups(claims, h.config.Auth.OIDC.AdminGroups),
+		}
+		if err := user.SetPassword(base64.RawURLEncoding.EncodeToString(password)); err != nil {
+			return nil, err
+		}
+		if err := h.userRepo.Create(user); err != nil {
+			return nil, err
+		}
+		encoding.Info("User created: username=%s, is_admin=%v, by=oidc", user.Username, user.IsAdmin)
+	}
+
+	if err := h.identityRepo.Link(user.ID, issuer, subject); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// oidcUsernameSuffix is appended to provider usernames that can't name an account as they are (e.g. "files")
+const oidcUsernameSuffix = "-sso"
+
+// oidcUsername returns the username for a new account: the configured claim, else the local part of the email,
+// keeping only characters that are safe in a storage directory name
+// Names that are reserved or too short get oidcUsernameSuffix; empty if no valid name can be made
+func oidcUsername(claims oidc.Claims, claim string) string {
+	name := claims.String(claim)
+	if name == "" {
+		name, _, _ = strings.Cut(claims.String("email"), "@")
+	}
+	var sb strings.Builder
+	for _, r := range strings.TrimSpace(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(".-_@", r) {
+			sb.WriteRune(r)
+		}
+	}
+	username := strings.TrimLeft(sb.String(), ".")
+	if username == "" {
+		return ""
+	}
+	if len([]rune(username)) > 32 {
+		username = string([]rune(username)[:32])
+	}
+	if !isValidUsername(username) {
+		if runes := []rune(username); len(runes) > 32-len(oidcUsernameSuffix) {
+			username = string(runes[:32-len(oidcUsernameSuffix)])
+		}
+		username += oidcUsernameSuffix
+	}
+	if !isValidUsername(username) {
+		return ""
+	}
+	return username
+}
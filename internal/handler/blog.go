@@ -0,0 +1,297 @@
+package handler
+
+import (
+	"encoding/xml"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encryption"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+const (
+	blogPageSize  = 10
+	blogFeedSize  = 20
+	blogExcerptLn = 300 // Characters of plain text in feed descriptions
+)
+
+// BlogPost is a published note of a blog folder
+type BlogPost struct {
+	UID      string
+	Title    string
+	Type     string
+	Content  string
+	Created  time.Time
+	Modified time.Time
+}
+
+// blogLink returns the link of a published blog, or responds with an error page and returns nil
+func (h *ShortLinkHandler) blogLink(c *gin.Context) *ShortLinkInfo {
+	code := c.Param("code")
+
+	h.mu.RLock()
+	info, exists := h.links[code]
+	h.mu.RUnlock()
+
+	if !exists || info.FolderPath == "" || !info.IsPublic || !info.Blog {
+		c.String(http.StatusNotFound, tr(c, "Blog not found"))
+		return nil
+	}
+	if info.ExpiresAt != nil && info.ExpiresAt.Before(time.Now()) {
+		c.HTML(http.StatusGone, "expired.html", gin.H{
+			"basePath": h.basePath,
+			"lang":     middleware.Language(c),
+		})
+		return nil
+	}
+	return info
+}
+
+// blogPosts returns the notes of a blog folder (including subfolders), newest first
+// Password-protected, encrypted and archived notes are never published
+func (h *ShortLinkHandler) blogPosts(info *ShortLinkInfo) []BlogPost {
+	notesPath := filepath.Join(h.config.Storage.Path, info.Username, "notes")
+	folderPath := strings.ReplaceAll(info.FolderPath, FolderSeparator, "/")
+	folderDir := filepath.Join(notesPath, filepath.FromSlash(folderPath))
+
+	posts := []BlogPost{}
+	filepath.WalkDir(folderDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		ext := filepath.Ext(path)
+		if ext != ".md" && ext != ".txt" && ext != ".adoc" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil || encryption.IsEncrypted(string(data)) {
+			return nil
+		}
+		note, err := model.ParseNoteFromBytes(data, path)
+		if err != nil || note.Private || note.Archived || note.UID == "" {
+			return nil
+		}
+
+		posts = append(posts, BlogPost{
+			UID:      note.UID,
+			Title:    note.Title,
+			Type:     note.Type,
+			Content:  note.Content,
+			Created:  note.Created,
+			Modified: note.Modified,
+		})
+		return nil
+	})
+
+	sort.Slice(posts, func(i, j int) bool {
+		return posts[i].Created.After(posts[j].Created)
+	})
+	return posts
+}
+
+// blogTitle returns the display title of a blog: the last segment of its folder path
+func blogTitle(info *ShortLinkInfo) string {
+	folderPath := strings.ReplaceAll(info.FolderPath, FolderSeparator, "/")
+	return folderPath[strings.LastIndex(folderPath, "/")+1:]
+}
+
+// blogDescription returns the folder description set in folder metadata
+func (h *ShortLinkHandler) blogDescription(info *ShortLinkInfo) string {
+	if h.db == nil {
+		return ""
+	}
+	if meta := getFolderMetaByUsername(h.db, info.Username, strings.ReplaceAll(info.FolderPath, FolderSeparator, "/")); meta != nil {
+		return meta.Description
+	}
+	return ""
+}
+
+// Blog renders a page of a published folder, newest posts first (?page=N)
+func (h *ShortLinkHandler) Blog(c *gin.Context) {
+	info := h.blogLink(c)
+	if info == nil {
+		return
+	}
+
+	posts := h.blogPosts(info)
+	pages := (len(posts) + blogPageSize - 1) / blogPageSize
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 || (page > pages && page > 1) {
+		c.String(http.StatusNotFound, tr(c, "Page not found"))
+		return
+	}
+
+	start := (page - 1) * blogPageSize
+	end := start + blogPageSize
+	if end > len(posts) {
+		end = len(posts)
+	}
+
+	data := gin.H{
+		"basePath":    h.basePath,
+		"lang":        middleware.Language(c),
+		"code":        c.Param("code"),
+		"title":       blogTitle(info),
+		"description": h.blogDescription(info),
+		"posts":       posts[start:end],
+		"meta":        h.folderPageMeta(c, info, "/blog/"+c.Param("code")),
+		"page":        page,
+		"pages":       pages,
+	}
+	if page > 1 {
+		data["prevPage"] = page - 1
+	}
+	if page < pages {
+		data["nextPage"] = page + 1
+	}
+	h.recordView(c, c.Param("code"))
+	h.recordAccess(c, c.Param("code"))
+	c.HTML(http.StatusOK, "blog.html", data)
+}
+
+// BlogPost renders a single post of a published folder by note UID
+func (h *ShortLinkHandler) BlogPost(c *gin.Context) {
+	info := h.blogLink(c)
+	if info == nil {
+		return
+	}
+
+	uid := c.Param("uid")
+	for _, post := range h.blogPosts(info) {
+		if post.UID == uid {
+			h.recordView(c, c.Param("code"))
+			h.recordAccess(c, c.Param("code"))
+			c.HTML(http.StatusOK, "blog.html", gin.H{
+				"basePath":    h.basePath,
+				"lang":        middleware.Language(c),
+				"code":        c.Param("code"),
+				"title":       blogTitle(info),
+				"description": h.blogDescription(info),
+				"posts":       []BlogPost{post},
+				"meta":        h.postPageMeta(c, c.Param("code"), post),
+				"single":      true,
+			})
+			return
+		}
+	}
+	c.String(http.StatusNotFound, tr(c, "Post not found"))
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// BlogFeed returns the RSS 2.0 feed of a published folder
+func (h *ShortLinkHandler) BlogFeed(c *gin.Context) {
+	info := h.blogLink(c)
+	if info == nil {
+		return
+	}
+
+	blogURL := requestOrigin(c) + h.basePath + "/blog/" + c.Param("code")
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       blogTitle(info),
+			Link:        blogURL,
+			Description: h.blogDescription(info),
+		},
+	}
+
+	posts := h.blogPosts(info)
+	if len(posts) > blogFeedSize {
+		posts = posts[:blogFeedSize]
+	}
+	for i, post := range posts {
+		if i == 0 {
+			feed.Channel.LastBuildDate = post.Modified.Format(time.RFC1123Z)
+		}
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       post.Title,
+			Link:        blogURL + "/post/" + post.UID,
+			GUID:        rssGUID{Value: post.UID},
+			PubDate:     post.Created.Format(time.RFC1123Z),
+			Description: excerpt(post.Content, blogExcerptLn),
+		})
+	}
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to build feed")
+		return
+	}
+	c.Data(http.StatusOK, "application/rss+xml; charset=utf-8", append([]byte(xml.Header), data...))
+}
+
+// requestOrigin returns the scheme and host the request was made to (honoring reverse proxy headers)
+func requestOrigin(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = strings.TrimSpace(strings.Split(proto, ",")[0])
+	}
+	host := c.Request.Host
+	if forwarded := c.GetHeader("X-Forwarded-Host"); forwarded != "" {
+		host = strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	return scheme + "://" + host
+}
+
+// excerpt returns the first characters of a note as plain text (markup stripped roughly)
+func excerpt(content string, maxLen int) string {
+	var words []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#>*-+|`="))
+		if line == "" || strings.HasPrefix(line, "![") {
+			continue
+		}
+		words = append(words, line)
+	}
+	text := strings.Join(words, " ")
+	if utf8.RuneCountInString(text) > maxLen {
+		text = strings.TrimSpace(string([]rune(text)[:maxLen])) + "…"
+	}
+	return text
+}
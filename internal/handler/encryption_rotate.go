@@ -0,0 +1,326 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/config"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/encryption"
+	"github.com/user/gitnotepad/internal/git"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/repository"
+	"github.com/user/gitnotepad/internal/websocket"
+)
+
+// keyRotation allows one key rotation at a time, since it rewrites every encrypted file of a user
+var keyRotation sync.Mutex
+
+// rotationProgressInterval is how many files are re-encrypted between progress reports
+const rotationProgressInterval = 100
+
+// RotationResult reports the files a key rotation re-encrypted
+type RotationResult struct {
+	Reencrypted int `json:"reencrypted"`
+	Skipped     int `json:"skipped"` // Encrypted with another key, left as they are
+}
+
+// rotatedFile is a file re-encrypted into tmp, with its content before the rotation
+type rotatedFile struct {
+	path     string
+	tmp      string
+	original []byte
+	mode     fs.FileMode
+}
+
+// ReencryptStorage re-encrypts the files of a user's storage that are encrypted with oldKey (notes, trash, drafts)
+// with newKey, and commits the notes as a single "Re-encrypt notes" commit
+// Every file is re-encrypted into a temporary file before any is replaced, so a failure leaves them all as they were
+// progress, if set, is called with the number of files done and the total
+func ReencryptStorage(userPath string, oldKey, newKey []byte, progress func(done, total int)) (RotationResult, error) {
+	var result RotationResult
+	paths, err := encryptedFiles(userPath)
+	if err != nil {
+		return result, err
+	}
+
+	var files []rotatedFile
+	discard := func() {
+		for _, f := range files {
+			os.Remove(f.tmp)
+		}
+	}
+	for i, path := range paths {
+		f, err := reencryptFile(path, oldKey, newKey)
+		if err != nil {
+			discard()
+			return result, err
+		}
+		if f == nil {
+			result.Skipped++
+		} else {
+			files = append(files, *f)
+		}
+		if progress != nil && ((i+1)%rotationProgressInterval == 0 || i+1 == len(paths)) {
+			progress(i+1, len(paths))
+		}
+	}
+
+	for i, f := range files {
+		if err := os.Rename(f.tmp, f.path); err != nil {
+			// Put back the files replaced so far
+			for _, done := range files[:i] {
+				os.WriteFile(done.path, done.original, done.mode)
+			}
+			discard()
+			return result, fmt.Errorf("failed to replace %s: %w", f.path, err)
+		}
+	}
+	result.Reencrypted = len(files)
+
+	notesPath := filepath.Join(userPath, "notes") + string(filepath.Separator)
+	var notes []string
+	for _, f := range files {
+		if strings.HasPrefix(f.path, notesPath) {
+			notes = append(notes, f.path)
+		}
+	}
+	if len(notes) > 0 {
+		repo, err := git.NewRepository(userPath)
+		if err == nil {
+			err = repo.AddPathsAndCommit(notes, "Re-encrypt notes")
+		}
+		if err != nil {
+			encoding.Warn("Failed to commit re-encrypted notes: path=%s, error=%v", userPath, err)
+		}
+	}
+	return result, nil
+}
+
+// reencryptFile writes the file re-encrypted with newKey next to it
+// It returns nil when the file isn't encrypted with oldKey
+func reencryptFile(path string, oldKey, newKey []byte) (*rotatedFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := encryption.Decrypt(string(data), oldKey)
+	if err != nil {
+		return nil, nil
+	}
+	encrypted, err := encryption.Encrypt(plain, newKey)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp := path + ".rotate"
+	if err := os.WriteFile(tmp, []byte(encrypted), info.Mode().Perm()); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	return &rotatedFile{path: path, tmp: tmp, original: data, mode: info.Mode().Perm()}, nil
+}
+
+// encryptedFiles lists the files in a user's storage that start with the encryption prefix, leaving out Git's files
+func encryptedFiles(userPath string) ([]string, error) {
+	var paths []string
+	prefix := make([]byte, len(encryption.EncryptedPrefix))
+	err := filepath.WalkDir(userPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Left behind by a rotation that was interrupted
+		if !d.Type().IsRegular() || strings.HasSuffix(path, ".rotate") {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		n, _ := io.ReadFull(f, prefix)
+		f.Close()
+		if string(prefix[:n]) == encryption.EncryptedPrefix {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// EncryptionHandler manages the keys notes are encrypted with
+type EncryptionHandler struct {
+	config      *config.Config
+	userRepo    *repository.UserRepository
+	sessionRepo *repository.SessionRepository
+	tokenRepo   *repository.APITokenRepository
+	auditRepo   *repository.AuditRepository
+	hub         *websocket.Hub
+}
+
+func NewEncryptionHandler(cfg *config.Config, userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, tokenRepo *repository.APITokenRepository, auditRepo *repository.AuditRepository, hub *websocket.Hub) *EncryptionHandler {
+	return &EncryptionHandler{
+		config:      cfg,
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		tokenRepo:   tokenRepo,
+		auditRepo:   auditRepo,
+		hub:         hub,
+	}
+}
+
+// RotateEncryptionRequest represents the request to rotate a user's encryption key
+type RotateEncryptionRequest struct {
+	Username    string `json:"username"`                               // Default: the signed-in admin
+	Password    string `json:"password" binding:"required"`            // Current password of the user
+	NewPassword string `json:"new_password" binding:"omitempty,min=6"` // Also changes the user's password ("" = keep it, new salt only)
+}
+
+// Rotate re-encrypts a user's notes with a key derived from a new salt, and optionally a new password (admin only)
+// Progress is sent to the admin's browsers as encryption_progress messages
+// Signed-in sessions and API tokens of the user switch to the new key
+func (h *EncryptionHandler) Rotate(c *gin.Context) {
+	if !h.config.Encryption.Enabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Encryption is not enabled"})
+		return
+	}
+
+	var req RotateEncryptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	admin := middleware.GetCurrentUser(c)
+	username := req.Username
+	if username == "" && admin != nil {
+		username = admin.Username
+	}
+	user, err := h.userRepo.GetByUsername(username)
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if !user.CheckPassword(req.Password) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		return
+	}
+
+	if !keyRotation.TryLock() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Key rotation already in progress"})
+		return
+	}
+	defer keyRotation.Unlock()
+
+	newPassword := req.NewPassword
+	if newPassword == "" {
+		newPassword = req.Password
+	}
+	salt, err := encryption.GenerateSalt()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate salt"})
+		return
+	}
+	oldKey, err := encryption.DeriveKey(req.Password, user.KeySalt(h.config.Encryption.Salt))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to derive key"})
+		return
+	}
+	newKey, err := encryption.DeriveKey(newPassword, salt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to derive key"})
+		return
+	}
+
+	progress := func(done, total int) {
+		if h.hub != nil && admin != nil {
+			h.hub.BroadcastToUser(admin.Username, websocket.Message{
+				Type: websocket.MsgTypeEncryptionProgress,
+				Data: gin.H{"username": user.Username, "done": done, "total": total},
+			})
+		}
+	}
+	userPath := user.GetStoragePath(h.config.Storage.Path)
+	result, err := ReencryptStorage(userPath, oldKey, newKey, progress)
+	if err != nil {
+		encoding.Error("Key rotation failed: username=%s, error=%v", user.Username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to re-encrypt notes"})
+		return
+	}
+
+	user.EncryptionSalt = salt
+	if req.NewPassword != "" {
+		if err := user.SetPassword(req.NewPassword); err != nil {
+			h.rollback(userPath, newKey, oldKey)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+			return
+		}
+	}
+	if err := h.userRepo.Update(user); err != nil {
+		h.rollback(userPath, newKey, oldKey)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+	h.replaceKeys(user.ID, newKey)
+
+	adminName := "unknown"
+	if admin != nil {
+		adminName = admin.Username
+	}
+	encoding.Info("Encryption key rotated: username=%s, files=%d, skipped=%d, password_changed=%t, by=%s, ip=%s",
+		user.Username, result.Reencrypted, result.Skipped, req.NewPassword != "", adminName, c.ClientIP())
+	recordAudit(h.auditRepo, c, model.AuditEncryptionRotate, user.Username, fmt.Sprintf("%d files", result.Reencrypted))
+
+	c.JSON(http.StatusOK, gin.H{
+		"username":         user.Username,
+		"reencrypted":      result.Reencrypted,
+		"skipped":          result.Skipped,
+		"password_changed": req.NewPassword != "",
+	})
+}
+
+// rollback re-encrypts the files back to the key the user signs in with, after the user couldn't be updated
+func (h *EncryptionHandler) rollback(userPath string, newKey, oldKey []byte) {
+	if _, err := ReencryptStorage(userPath, newKey, oldKey, nil); err != nil {
+		encoding.Error("Key rotation rollback failed: path=%s, error=%v", userPath, err)
+	}
+}
+
+// replaceKeys gives the user's sessions and API tokens that hold a key the new one
+func (h *EncryptionHandler) replaceKeys(userID int64, key []byte) {
+	store := encryption.GetKeyStore()
+	var holders []string
+	if sessions, err := h.sessionRepo.ListByUser(userID); err == nil {
+		for _, session := range sessions {
+			holders = append(holders, session.Token)
+		}
+	}
+	if tokens, err := h.tokenRepo.ListByUser(userID); err == nil {
+		for _, token := range tokens {
+			holders = append(holders, token.TokenHash)
+		}
+	}
+	for _, holder := range holders {
+		if _, ok := store.Get(holder); ok {
+			store.Store(holder, key)
+		}
+	}
+}
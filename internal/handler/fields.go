@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestedFields returns the JSON keys listed in ?fields= (e.g. "id,title,modified,icon")
+// nil means the full objects were asked for. The id is always kept so clients can match results.
+func requestedFields(c *gin.Context) map[string]bool {
+	raw := strings.TrimSpace(c.Query("fields"))
+	if raw == "" {
+		return nil
+	}
+	fields := map[string]bool{"id": true}
+	for _, field := range strings.Split(raw, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields[field] = true
+		}
+	}
+	return fields
+}
+
+// trimFields returns an object or a list of objects with only the given JSON keys
+// Values are returned unchanged when fields is nil or they cannot be trimmed
+func trimFields(v interface{}, fields map[string]bool) interface{} {
+	if fields == nil {
+		return v
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return v
+	}
+
+	trim := func(item interface{}) interface{} {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return item
+		}
+		for key := range obj {
+			if !fields[key] {
+				delete(obj, key)
+			}
+		}
+		return obj
+	}
+
+	if list, ok := generic.([]interface{}); ok {
+		for i, item := range list {
+			list[i] = trim(item)
+		}
+		return list
+	}
+	return trim(generic)
+}
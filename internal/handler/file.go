@@ -13,8 +13,11 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/user/gitnotepad/internal/config"
 	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/fetch"
 	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
 )
 
 // FileMetadata stores the mapping between UUID filenames and original filenames
@@ -28,14 +31,20 @@ var fileMetadata = &FileMetadata{
 }
 
 type FileHandler struct {
-	storagePath string
-	basePath    string
+	storagePath  string
+	basePath     string
+	keepVersions int                 // Prior versions kept when a file is replaced
+	fetcher      *fetch.Client       // Downloads attachments from a URL
+	upload       config.UploadConfig // Size and type limits of uploads
 }
 
-func NewFileHandler(storagePath string, basePath string) *FileHandler {
+func NewFileHandler(storagePath string, basePath string, keepVersions int, fetcher *fetch.Client, upload config.UploadConfig) *FileHandler {
 	return &FileHandler{
-		storagePath: storagePath,
-		basePath:    basePath,
+		storagePath:  storagePath,
+		basePath:     basePath,
+		keepVersions: keepVersions,
+		fetcher:      fetcher,
+		upload:       upload,
 	}
 }
 
@@ -100,7 +109,7 @@ func (h *FileHandler) getOriginalName(username, uuidName string) string {
 
 // getUserFilesPath returns the user-specific files directory
 func (h *FileHandler) getUserFilesPath(c *gin.Context) string {
-	user := middleware.GetCurrentUser(c)
+	user := storageOwner(c)
 	if user == nil {
 		// Fallback to global files directory
 		return filepath.Join(h.storagePath, "files")
@@ -112,9 +121,21 @@ func (h *FileHandler) getUserFilesPath(c *gin.Context) string {
 }
 
 func (h *FileHandler) Upload(c *gin.Context) {
-	file, header, err := c.Request.FormFile("file")
+	// Uploading into a shared folder requires write permission (?owner=...&folder_path=...)
+	if !middleware.HasSharePermission(c, c.Query("folder_path"), model.PermissionWrite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission for shared folder"})
+		return
+	}
+
+	folder, ok := uploadFolder(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+		return
+	}
+
+	file, header, err := openUpload(c, h.upload, "file")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No file provided"})
+		uploadError(c, h.upload, err, "No file provided")
 		return
 	}
 	defer file.Close()
@@ -147,22 +168,31 @@ func (h *FileHandler) Upload(c *gin.Context) {
 		return
 	}
 
-	// Get username for URL
-	user := middleware.GetCurrentUser(c)
+	// Get username for URL (share owner when uploading into a shared folder)
+	user := storageOwner(c)
 	username := "shared"
 	if user != nil {
 		username = user.Username
 	}
 
-	// Save metadata mapping (UUID -> original filename)
+	// Save metadata mapping (UUID -> original filename) and the folder in the files area
 	h.saveMetadata(username, filename, originalName)
+	if folder != "" {
+		setFileFolder(h.storagePath, username, filename, folder)
+	}
 
 	// Return URL for the file (with base path and username)
 	fileURL := fmt.Sprintf("%s/u/%s/files/%s", h.basePath, username, filename)
+	// The type the file is served with, which players such as <audio> rely on
+	contentType, _, _ := strings.Cut(attachmentContentType(filename), ";")
+	if contentType == "" {
+		contentType, _, _ = strings.Cut(header.Header.Get("Content-Type"), ";")
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"url":          fileURL,
 		"filename":     filename,
 		"originalName": originalName,
+		"type":         strings.TrimSpace(contentType),
 	})
 }
 
@@ -215,7 +245,7 @@ func (h *FileHandler) Serve(c *gin.Context) {
 		encodedName := url.PathEscape(originalName)
 		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, safeFilename, encodedName))
 	}
-	c.File(filePath)
+	serveAttachment(c, filePath)
 }
 
 // deleteMetadata removes file metadata from disk for a user
@@ -253,8 +283,13 @@ func (h *FileHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	if !middleware.HasSharePermission(c, c.Query("folder_path"), model.PermissionWrite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission for shared folder"})
+		return
+	}
+
 	// Get user-specific files directory
-	user := middleware.GetCurrentUser(c)
+	user := storageOwner(c)
 	if user == nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -268,18 +303,34 @@ func (h *FileHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	// Delete the file
-	if err := os.Remove(filePath); err != nil {
+	if err := removeUploadedFile(h.storagePath, user.Username, filename); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete file"})
 		return
 	}
 
-	// Delete metadata
-	h.deleteMetadata(user.Username, filename)
-
 	c.JSON(http.StatusOK, gin.H{"message": "File deleted"})
 }
 
+// removeUploadedFile deletes an uploaded file or image with its metadata, folder and prior versions
+func removeUploadedFile(storagePath, username, filename string) error {
+	if err := os.Remove(filepath.Join(storagePath, username, "files", filename)); err != nil {
+		return err
+	}
+	// Images and other files keep their names in separate metadata files
+	// (looking the name up first loads the metadata, so deleting doesn't drop the other entries)
+	images := &ImageHandler{storagePath: storagePath}
+	files := &FileHandler{storagePath: storagePath}
+	if images.getOriginalName(username, filename) != "" {
+		images.deleteMetadata(username, filename)
+	}
+	if files.getOriginalName(username, filename) != "" {
+		files.deleteMetadata(username, filename)
+	}
+	setFileFolder(storagePath, username, filename, "")
+	os.RemoveAll(attachmentVersionsDir(storagePath, username, filename))
+	return nil
+}
+
 // ServeLegacy serves files from the legacy global files directory
 func (h *FileHandler) ServeLegacy(c *gin.Context) {
 	filename := c.Param("filename")
@@ -298,7 +349,7 @@ func (h *FileHandler) ServeLegacy(c *gin.Context) {
 		return
 	}
 
-	c.File(filePath)
+	serveAttachment(c, filePath)
 }
 
 // MigrateAttachmentMetadata migrates attachment filenames from notes to metadata files
@@ -403,6 +454,22 @@ func MigrateAttachmentMetadata(storagePath string) error {
 	return nil
 }
 
+// invalidateAttachmentMetadata drops cached file and image metadata (and file folders) for a user
+// The next access reloads it from disk (e.g. after the storage directory was renamed)
+func invalidateAttachmentMetadata(username string) {
+	fileMetadata.Lock()
+	delete(fileMetadata.cache, username)
+	fileMetadata.Unlock()
+
+	imageMetadata.Lock()
+	delete(imageMetadata.cache, username)
+	imageMetadata.Unlock()
+
+	fileFolders.Lock()
+	delete(fileFolders.cache, username)
+	fileFolders.Unlock()
+}
+
 // loadMetadataFile loads metadata from a JSON file
 func loadMetadataFile(path string) map[string]string {
 	data := make(map[string]string)
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/fetch"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"golang.org/x/text/unicode/norm"
+)
+
+// FetchFileRequest attaches a remote file by its URL
+type FetchFileRequest struct {
+	URL  string `json:"url" binding:"required"`
+	Name string `json:"name"` // Display name (default: name from the response or URL)
+}
+
+// Fetch downloads a remote URL server-side and stores it as an uploaded file or image
+// Query: dir (folder in the files area), folder_path (shared folder)
+func (h *FileHandler) Fetch(c *gin.Context) {
+	if !middleware.HasSharePermission(c, c.Query("folder_path"), model.PermissionWrite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission for shared folder"})
+		return
+	}
+
+	folder, ok := uploadFolder(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+		return
+	}
+
+	var req FetchFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := storageOwner(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	result, err := h.fetcher.Get(c.Request.Context(), req.URL, nil)
+	if err != nil {
+		fetchError(c, req.URL, err)
+		return
+	}
+
+	filename, originalName, err := saveFetchedFile(h.storagePath, user.Username, result, req.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
+		return
+	}
+	if folder != "" {
+		setFileFolder(h.storagePath, user.Username, filename, folder)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"url":          fmt.Sprintf("%s/u/%s/files/%s", h.basePath, user.Username, filename),
+		"filename":     filename,
+		"originalName": originalName,
+		"size":         len(result.Data),
+		"type":         result.ContentType,
+		"isImage":      strings.HasPrefix(result.ContentType, "image/"),
+		"source":       result.URL,
+	})
+}
+
+// fetchError responds with the status matching a download error
+func fetchError(c *gin.Context, rawURL string, err error) {
+	switch {
+	case errors.Is(err, fetch.ErrInvalidURL):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL"})
+	case errors.Is(err, fetch.ErrBlockedAddress):
+		c.JSON(http.StatusForbidden, gin.H{"error": "URL points to a blocked address"})
+	case errors.Is(err, fetch.ErrTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+	case errors.Is(err, fetch.ErrTypeNotAllowed):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "File type is not allowed"})
+	default:
+		encoding.Debug("Fetch %s: %v", rawURL, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to download URL"})
+	}
+}
+
+// saveFetchedFile stores a downloaded file in the files directory of a user under a UUID filename
+// name is the display name (default: the name from the response or URL)
+func saveFetchedFile(storagePath, username string, result *fetch.Result, name string) (filename, originalName string, err error) {
+	originalName = norm.NFC.String(strings.TrimSpace(name))
+	if originalName == "" {
+		originalName = result.Filename
+	}
+	originalName = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '/' || r == '\\' {
+			return -1
+		}
+		return r
+	}, originalName)
+
+	// Keep the extension of the name when it matches the content, otherwise use one for the content type
+	ext := strings.ToLower(filepath.Ext(originalName))
+	if ext == "" || mime.TypeByExtension(ext) == "" || !strings.HasPrefix(mime.TypeByExtension(ext), result.ContentType) {
+		ext = extensionForType(result.ContentType)
+		if filepath.Ext(originalName) == "" {
+			originalName += ext
+		}
+	}
+
+	userFilesPath := filepath.Join(storagePath, username, "files")
+	if err := os.MkdirAll(userFilesPath, 0755); err != nil {
+		return "", "", err
+	}
+	filename = uuid.New().String() + ext
+	if err := os.WriteFile(filepath.Join(userFilesPath, filename), result.Data, 0644); err != nil {
+		return "", "", err
+	}
+
+	// Images and other files keep their names in separate metadata files
+	if strings.HasPrefix(result.ContentType, "image/") {
+		err = (&ImageHandler{storagePath: storagePath}).saveMetadata(username, filename, originalName)
+	} else {
+		err = (&FileHandler{storagePath: storagePath}).saveMetadata(username, filename, originalName)
+	}
+	return filename, originalName, err
+}
+
+// extensionForType returns the file extension for a media type (".bin" when unknown)
+func extensionForType(mediaType string) string {
+	switch mediaType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/svg+xml":
+		return ".svg"
+	case "text/plain":
+		return ".txt"
+	case "text/markdown":
+		return ".md"
+	}
+	if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ".bin"
+}
@@ -0,0 +1,321 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// FileFolders stores the folder each uploaded file is organized in
+// Files stay flat on disk so their URLs never change; folders only exist in this mapping
+type FileFolders struct {
+	sync.RWMutex
+	cache map[string]map[string]string // username -> (uuid -> folder path)
+}
+
+var fileFolders = &FileFolders{
+	cache: make(map[string]map[string]string),
+}
+
+// fileFoldersPath returns the path to the folder mapping of a user
+func fileFoldersPath(storagePath, username string) string {
+	return filepath.Join(storagePath, username, "files", ".filefolders.json")
+}
+
+// loadFileFolders returns a copy of the folder mapping of a user
+func loadFileFolders(storagePath, username string) map[string]string {
+	fileFolders.Lock()
+	defer fileFolders.Unlock()
+
+	data, ok := fileFolders.cache[username]
+	if !ok {
+		data = loadMetadataFile(fileFoldersPath(storagePath, username))
+		fileFolders.cache[username] = data
+	}
+
+	result := make(map[string]string, len(data))
+	for k, v := range data {
+		result[k] = v
+	}
+	return result
+}
+
+// updateFileFolders applies a change to the folder mapping of a user and saves it
+func updateFileFolders(storagePath, username string, change func(folders map[string]string)) error {
+	fileFolders.Lock()
+	defer fileFolders.Unlock()
+
+	data, ok := fileFolders.cache[username]
+	if !ok {
+		data = loadMetadataFile(fileFoldersPath(storagePath, username))
+		fileFolders.cache[username] = data
+	}
+	change(data)
+
+	metaPath := fileFoldersPath(storagePath, username)
+	if len(data) == 0 {
+		os.Remove(metaPath)
+		return nil
+	}
+	return saveMetadataFile(metaPath, data)
+}
+
+// setFileFolder puts an uploaded file in a folder ("" for the top level)
+func setFileFolder(storagePath, username, filename, folder string) error {
+	return updateFileFolders(storagePath, username, func(folders map[string]string) {
+		if folder == "" {
+			delete(folders, filename)
+		} else {
+			folders[filename] = folder
+		}
+	})
+}
+
+// uploadFolder returns the cleaned ?dir= of an upload ("" when absent)
+func uploadFolder(c *gin.Context) (string, bool) {
+	dir := c.Query("dir")
+	if strings.Trim(dir, "/ ") == "" {
+		return "", true
+	}
+	return cleanFolderPath(dir)
+}
+
+// FileEntry is an uploaded file or image in the files browser
+type FileEntry struct {
+	Filename string    `json:"filename"`
+	Name     string    `json:"name"`
+	URL      string    `json:"url"`
+	Folder   string    `json:"folder"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	IsImage  bool      `json:"isImage"`
+}
+
+// FileFolderEntry is a subfolder in the files browser
+type FileFolderEntry struct {
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Count int    `json:"count"` // Files in the folder and its subfolders
+}
+
+// Browse lists the files and subfolders of a folder in the files area
+// Query: folder (default top level), q to search names in all folders instead
+func (h *FileHandler) Browse(c *gin.Context) {
+	if !middleware.HasSharePermission(c, c.Query("folder_path"), model.PermissionRead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission for shared folder"})
+		return
+	}
+
+	user := storageOwner(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	folder := ""
+	if f := c.Query("folder"); strings.Trim(f, "/ ") != "" {
+		var ok bool
+		if folder, ok = cleanFolderPath(f); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+			return
+		}
+	}
+	query := strings.ToLower(strings.TrimSpace(c.Query("q")))
+
+	entries, err := os.ReadDir(filepath.Join(h.storagePath, user.Username, "files"))
+	if err != nil && !os.IsNotExist(err) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read files"})
+		return
+	}
+
+	folders := loadFileFolders(h.storagePath, user.Username)
+	images := &ImageHandler{storagePath: h.storagePath}
+	files := []FileEntry{}
+	subfolders := make(map[string]int)
+
+	for _, entry := range entries {
+		// Metadata, versions and in-progress uploads are not files of the user
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || strings.HasSuffix(entry.Name(), ".upload") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		filename := entry.Name()
+		fileFolder := folders[filename]
+		fileEntry := FileEntry{
+			Filename: filename,
+			URL:      fmt.Sprintf("%s/u/%s/files/%s", h.basePath, user.Username, filename),
+			Folder:   fileFolder,
+			Size:     info.Size(),
+			Modified: info.ModTime(),
+		}
+		if name := images.getOriginalName(user.Username, filename); name != "" {
+			fileEntry.Name = name
+			fileEntry.IsImage = true
+		} else {
+			fileEntry.Name = h.getOriginalName(user.Username, filename)
+		}
+		if fileEntry.Name == "" {
+			fileEntry.Name = filename
+		}
+
+		if query != "" {
+			if strings.Contains(strings.ToLower(fileEntry.Name), query) {
+				files = append(files, fileEntry)
+			}
+			continue
+		}
+
+		switch {
+		case fileFolder == folder:
+			files = append(files, fileEntry)
+		case folder == "" || strings.HasPrefix(fileFolder, folder+"/"):
+			// Count towards the direct subfolder it is in
+			rest := fileFolder
+			if folder != "" {
+				rest = strings.TrimPrefix(fileFolder, folder+"/")
+			}
+			subfolders[strings.SplitN(rest, "/", 2)[0]]++
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return strings.ToLower(files[i].Name) < strings.ToLower(files[j].Name)
+	})
+
+	folderList := make([]FileFolderEntry, 0, len(subfolders))
+	for name, count := range subfolders {
+		path := name
+		if folder != "" {
+			path = folder + "/" + name
+		}
+		folderList = append(folderList, FileFolderEntry{Name: name, Path: path, Count: count})
+	}
+	sort.Slice(folderList, func(i, j int) bool {
+		return strings.ToLower(folderList[i].Name) < strings.ToLower(folderList[j].Name)
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"folder":  folder,
+		"folders": folderList,
+		"files":   files,
+	})
+}
+
+// MoveFileRequest puts an uploaded file in another folder
+type MoveFileRequest struct {
+	Folder string `json:"folder"` // "" for the top level
+}
+
+// Move puts an uploaded file or image in a folder of the files area (its URL does not change)
+func (h *FileHandler) Move(c *gin.Context) {
+	filename := c.Param("filename")
+	if !validAttachmentName(filename) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filename"})
+		return
+	}
+
+	if !middleware.HasSharePermission(c, c.Query("folder_path"), model.PermissionWrite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission for shared folder"})
+		return
+	}
+
+	var req MoveFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	folder := ""
+	if strings.Trim(req.Folder, "/ ") != "" {
+		var ok bool
+		if folder, ok = cleanFolderPath(req.Folder); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+			return
+		}
+	}
+
+	user := storageOwner(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if _, err := os.Stat(filepath.Join(h.storagePath, user.Username, "files", filename)); os.IsNotExist(err) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+
+	if err := setFileFolder(h.storagePath, user.Username, filename, folder); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move file"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"filename": filename, "folder": folder})
+}
+
+// RenameFileFolder renames or moves a folder of the files area with its subfolders
+func (h *FileHandler) RenameFileFolder(c *gin.Context) {
+	var req RenameFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// An empty old path would match every file folder
+	oldPath, ok := cleanFolderPath(req.OldPath)
+	if !ok || oldPath == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+		return
+	}
+	newPath, ok := cleanFolderPath(req.NewPath)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+		return
+	}
+	if !middleware.HasSharePermission(c, oldPath, model.PermissionWrite) || !middleware.HasSharePermission(c, newPath, model.PermissionWrite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission for shared folder"})
+		return
+	}
+	if strings.HasPrefix(newPath, oldPath+"/") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot move a folder into itself"})
+		return
+	}
+
+	user := storageOwner(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	moved := 0
+	err := updateFileFolders(h.storagePath, user.Username, func(folders map[string]string) {
+		for filename, folder := range folders {
+			if folder == oldPath || strings.HasPrefix(folder, oldPath+"/") {
+				folders[filename] = newPath + strings.TrimPrefix(folder, oldPath)
+				moved++
+			}
+		}
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rename folder"})
+		return
+	}
+	if moved == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"path": newPath, "moved_files": moved})
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/config"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// uploadFormOverhead is room in the request body for the multipart headers and other form fields
+const uploadFormOverhead = 1 << 20
+
+var (
+	errNoUpload         = errors.New("no file in the upload")
+	errUploadTooLarge   = errors.New("upload is too large")
+	errUploadTypeDenied = errors.New("upload type is not allowed")
+)
+
+// openUpload returns the file in a multipart form field, enforcing the upload config
+// The request body is cut off once it exceeds the size limit, so an oversized upload isn't read to the end
+func openUpload(c *gin.Context, cfg config.UploadConfig, field string) (multipart.File, *multipart.FileHeader, error) {
+	var limit int64 = -1
+	if cfg.MaxSizeMB > 0 {
+		limit = int64(cfg.MaxSizeMB) << 20
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit+uploadFormOverhead)
+	}
+
+	file, header, err := c.Request.FormFile(field)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, nil, errUploadTooLarge
+		}
+		return nil, nil, errNoUpload
+	}
+	if limit >= 0 && header.Size > limit {
+		file.Close()
+		return nil, nil, errUploadTooLarge
+	}
+	if !uploadAllowed(cfg, header) {
+		file.Close()
+		return nil, nil, errUploadTypeDenied
+	}
+	return file, header, nil
+}
+
+// uploadError responds with the status matching an openUpload error
+// missing is the message when the form has no file
+func uploadError(c *gin.Context, cfg config.UploadConfig, err error, missing string) {
+	switch {
+	case errors.Is(err, errUploadTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large", "max_size_mb": cfg.MaxSizeMB})
+	case errors.Is(err, errUploadTypeDenied):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "File type is not allowed"})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": missing})
+	}
+}
+
+// uploadAllowed checks the extension and type of an upload against upload.allow and upload.deny
+func uploadAllowed(cfg config.UploadConfig, header *multipart.FileHeader) bool {
+	return uploadTypeAllowed(cfg, header.Filename, header.Header.Get("Content-Type"))
+}
+
+// uploadTypeAllowed checks a file name and the type declared for the file against upload.allow and upload.deny
+// The type is the declared one, and the one registered for the extension
+func uploadTypeAllowed(cfg config.UploadConfig, name, declaredType string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	types := []string{}
+	if declared, _, err := mime.ParseMediaType(declaredType); err == nil && declared != "application/octet-stream" {
+		types = append(types, declared)
+	}
+	if byExt, _, err := mime.ParseMediaType(mime.TypeByExtension(ext)); err == nil {
+		types = append(types, byExt)
+	}
+
+	if uploadMatches(cfg.Deny, ext, types) {
+		return false
+	}
+	return len(cfg.Allow) == 0 || uploadMatches(cfg.Allow, ext, types)
+}
+
+// uploadMatches reports whether any entry of a list matches the extension or one of the types
+func uploadMatches(list []string, ext string, types []string) bool {
+	for _, entry := range list {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if strings.HasPrefix(entry, ".") {
+			if ext != "" && entry == ext {
+				return true
+			}
+			continue
+		}
+		for _, t := range types {
+			if entry == "*" || entry == t || (strings.HasSuffix(entry, "/") && strings.HasPrefix(t, entry)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SaveAttachment stores a file received outside the web app, such as by the Telegram bot, in a user's files directory
+// The upload limits apply, and the original name is recorded like for an upload
+func SaveAttachment(cfg *config.Config, username, name, mimeType string, src io.Reader) (model.Attachment, error) {
+	if !uploadTypeAllowed(cfg.Upload, name, mimeType) {
+		return model.Attachment{}, errUploadTypeDenied
+	}
+	var limit int64 = -1
+	if cfg.Upload.MaxSizeMB > 0 {
+		limit = int64(cfg.Upload.MaxSizeMB) << 20
+		src = io.LimitReader(src, limit+1)
+	}
+
+	filesPath := filepath.Join(cfg.Storage.Path, username, "files")
+	att, err := saveImportedAttachment(src, name, mimeType, filesPath, username, cfg.Server.BasePath)
+	if err != nil {
+		return att, err
+	}
+	filename := path.Base(att.URL)
+	if limit >= 0 && att.Size > limit {
+		os.Remove(filepath.Join(filesPath, filename))
+		return model.Attachment{}, errUploadTooLarge
+	}
+
+	// Load the names recorded so far, so saving doesn't replace them
+	files := &FileHandler{storagePath: cfg.Storage.Path}
+	files.loadMetadata(username)
+	if err := files.saveMetadata(username, filename, name); err != nil {
+		return att, err
+	}
+	return att, nil
+}
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// AttachmentVersion is a prior version of an uploaded file, kept when it was replaced
+type AttachmentVersion struct {
+	Version  string    `json:"version"` // Replacement time in Unix nanoseconds
+	Size     int64     `json:"size"`
+	Replaced time.Time `json:"replaced"`
+}
+
+// attachmentVersionsDir returns the directory holding prior versions of an uploaded file
+// e.g. data/{username}/files/.versions/{uuid.ext}/
+func attachmentVersionsDir(storagePath, username, filename string) string {
+	return filepath.Join(storagePath, username, "files", ".versions", filename)
+}
+
+// validAttachmentName rejects filenames that could escape the files directory
+func validAttachmentName(filename string) bool {
+	return filename != "" && !strings.Contains(filename, "..") && !strings.ContainsAny(filename, "/\\")
+}
+
+// UploadVersion replaces the content of an uploaded file or image, keeping its UUID filename
+// so the URLs in notes stay valid; the previous content is kept as a prior version
+func (h *FileHandler) UploadVersion(c *gin.Context) {
+	filename := c.Param("filename")
+	if !validAttachmentName(filename) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filename"})
+		return
+	}
+
+	if !middleware.HasSharePermission(c, c.Query("folder_path"), model.PermissionWrite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission for shared folder"})
+		return
+	}
+
+	user := storageOwner(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	filePath := filepath.Join(h.storagePath, user.Username, "files", filename)
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+
+	file, _, err := openUpload(c, h.upload, "file")
+	if err != nil {
+		uploadError(c, h.upload, err, "No file provided")
+		return
+	}
+	defer file.Close()
+
+	// Write the new content next to the file first, so a failed upload leaves the current version intact
+	tmpPath := filePath + ".upload"
+	dst, err := os.Create(tmpPath)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
+		return
+	}
+	_, err = io.Copy(dst, file)
+	dst.Close()
+	if err != nil {
+		os.Remove(tmpPath)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
+		return
+	}
+
+	// Keep the current content as a prior version
+	versionsDir := attachmentVersionsDir(h.storagePath, user.Username, filename)
+	if h.keepVersions > 0 {
+		version := strconv.FormatInt(time.Now().UnixNano(), 10)
+		if err := os.MkdirAll(versionsDir, 0755); err != nil {
+			os.Remove(tmpPath)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to keep previous version"})
+			return
+		}
+		if err := os.Rename(filePath, filepath.Join(versionsDir, version)); err != nil {
+			os.Remove(tmpPath)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to keep previous version"})
+			return
+		}
+	}
+
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		os.Remove(tmpPath)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
+		return
+	}
+
+	// Drop the oldest versions beyond the configured number
+	versions := listAttachmentVersions(versionsDir)
+	if keep := max(h.keepVersions, 0); len(versions) > keep {
+		for _, v := range versions[keep:] {
+			os.Remove(filepath.Join(versionsDir, v.Version))
+		}
+		versions = versions[:keep]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"url":      fmt.Sprintf("%s/u/%s/files/%s", h.basePath, user.Username, filename),
+		"filename": filename,
+		"versions": versions,
+	})
+}
+
+// ListVersions returns the prior versions of an uploaded file, newest first
+func (h *FileHandler) ListVersions(c *gin.Context) {
+	filename := c.Param("filename")
+	if !validAttachmentName(filename) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filename"})
+		return
+	}
+
+	if !middleware.HasSharePermission(c, c.Query("folder_path"), model.PermissionRead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission for shared folder"})
+		return
+	}
+
+	user := storageOwner(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	info, err := os.Stat(filepath.Join(h.storagePath, user.Username, "files", filename))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"filename": filename,
+		"size":     info.Size(),
+		"modified": info.ModTime(),
+		"versions": listAttachmentVersions(attachmentVersionsDir(h.storagePath, user.Username, filename)),
+	})
+}
+
+// ServeVersion downloads a prior version of an uploaded file under its original name
+func (h *FileHandler) ServeVersion(c *gin.Context) {
+	filename := c.Param("filename")
+	version := c.Param("version")
+	if !validAttachmentName(filename) || !validAttachmentName(version) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filename"})
+		return
+	}
+
+	if !middleware.HasSharePermission(c, c.Query("folder_path"), model.PermissionRead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission for shared folder"})
+		return
+	}
+
+	user := storageOwner(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	versionPath := filepath.Join(attachmentVersionsDir(h.storagePath, user.Username, filename), version)
+	if _, err := os.Stat(versionPath); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Version not found"})
+		return
+	}
+
+	originalName := h.getOriginalName(user.Username, filename)
+	if originalName == "" {
+		originalName = (&ImageHandler{storagePath: h.storagePath}).getOriginalName(user.Username, filename)
+	}
+	if originalName == "" {
+		originalName = filename
+	}
+	safeFilename := strings.ReplaceAll(originalName, `\`, `\\`)
+	safeFilename = strings.ReplaceAll(safeFilename, `"`, `\"`)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, safeFilename, url.PathEscape(originalName)))
+	if contentType := attachmentContentType(filename); contentType != "" {
+		c.Header("Content-Type", contentType)
+	}
+	setAttachmentETag(c, versionPath)
+	c.File(versionPath)
+}
+
+// listAttachmentVersions returns the prior versions in a versions directory, newest first
+func listAttachmentVersions(versionsDir string) []AttachmentVersion {
+	versions := []AttachmentVersion{}
+	entries, err := os.ReadDir(versionsDir)
+	if err != nil {
+		return versions
+	}
+	for _, entry := range entries {
+		nanos, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		versions = append(versions, AttachmentVersion{
+			Version:  entry.Name(),
+			Size:     info.Size(),
+			Replaced: time.Unix(0, nanos),
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Replaced.After(versions[j].Replaced)
+	})
+	return versions
+}
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"net/http"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/user/gitnotepad/internal/database"
@@ -110,3 +112,29 @@ func (h *FolderIconHandler) Delete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Icon deleted"})
 }
+
+// renameFolderIcons moves icons of a folder and its subfolders to a new path
+func renameFolderIcons(db *database.DB, userID int64, oldPath, newPath string) error {
+	_, err := db.Exec(
+		`UPDATE folder_icons SET folder_path = ? || substr(folder_path, ?)
+		 WHERE user_id = ? AND (folder_path = ? OR folder_path LIKE ? ESCAPE '\')`,
+		newPath, utf8.RuneCountInString(oldPath)+1, userID, oldPath, escapeLike(oldPath)+"/%",
+	)
+	return err
+}
+
+// deleteFolderIcons removes icons of a folder and its subfolders
+func deleteFolderIcons(db *database.DB, userID int64, folderPath string) error {
+	_, err := db.Exec(
+		`DELETE FROM folder_icons WHERE user_id = ? AND (folder_path = ? OR folder_path LIKE ? ESCAPE '\')`,
+		userID, folderPath, escapeLike(folderPath)+"/%",
+	)
+	return err
+}
+
+// escapeLike escapes LIKE wildcards so a path matches literally
+func escapeLike(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "%", `\%`)
+	return strings.ReplaceAll(s, "_", `\_`)
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"net/http"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/database"
+	"github.com/user/gitnotepad/internal/middleware"
+)
+
+type FolderMetaHandler struct {
+	db *database.DB
+}
+
+func NewFolderMetaHandler(db *database.DB) *FolderMetaHandler {
+	return &FolderMetaHandler{db: db}
+}
+
+// FolderMeta holds per-folder settings shown in the sidebar and folder share previews
+type FolderMeta struct {
+	Description string `json:"description,omitempty"`
+	Color       string `json:"color,omitempty"`        // Hex color (e.g. "#4a90d9")
+	DefaultType string `json:"default_type,omitempty"` // Note type for new notes: markdown, txt, asciidoc
+}
+
+type SetFolderMetaRequest struct {
+	FolderPath  string `json:"folder_path" binding:"required"`
+	Description string `json:"description" binding:"max=500"`
+	Color       string `json:"color"`
+	DefaultType string `json:"default_type"`
+}
+
+var folderColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// List returns metadata for all folders of the current user
+func (h *FolderMetaHandler) List(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		// Return empty map for unauthenticated users instead of 401
+		c.JSON(http.StatusOK, make(map[string]FolderMeta))
+		return
+	}
+
+	meta, err := loadFolderMeta(h.db, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch folder metadata"})
+		return
+	}
+
+	c.JSON(http.StatusOK, meta)
+}
+
+// Set creates or updates folder metadata
+func (h *FolderMetaHandler) Set(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req SetFolderMetaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	folderPath, ok := cleanFolderPath(req.FolderPath)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+		return
+	}
+	if req.Color != "" && !folderColorPattern.MatchString(req.Color) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid color (expected #rrggbb)"})
+		return
+	}
+	switch req.DefaultType {
+	case "", "markdown", "txt", "asciidoc":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid default type"})
+		return
+	}
+
+	// Upsert folder metadata
+	_, err := h.db.Exec(
+		`INSERT INTO folder_meta (user_id, folder_path, description, color, default_type) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(user_id, folder_path) DO UPDATE SET
+		   description = excluded.description, color = excluded.color, default_type = excluded.default_type,
+		   updated_at = CURRENT_TIMESTAMP`,
+		user.ID, folderPath, req.Description, req.Color, req.DefaultType,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save folder metadata"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Folder metadata saved"})
+}
+
+// Delete removes folder metadata
+func (h *FolderMetaHandler) Delete(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if c.Query("folder_path") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "folder_path is required"})
+		return
+	}
+	// Cleaned as on save, so "a/b/" finds the metadata stored for "a/b"
+	folderPath, ok := cleanFolderPath(c.Query("folder_path"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+		return
+	}
+
+	_, err := h.db.Exec(
+		"DELETE FROM folder_meta WHERE user_id = ? AND folder_path = ?",
+		user.ID, folderPath,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete folder metadata"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Folder metadata deleted"})
+}
+
+// loadFolderMeta returns all folder metadata of a user keyed by folder path
+func loadFolderMeta(db *database.DB, userID int64) (map[string]FolderMeta, error) {
+	rows, err := db.Query(
+		"SELECT folder_path, description, color, default_type FROM folder_meta WHERE user_id = ?",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]FolderMeta)
+	for rows.Next() {
+		var folderPath string
+		var meta FolderMeta
+		if err := rows.Scan(&folderPath, &meta.Description, &meta.Color, &meta.DefaultType); err != nil {
+			continue
+		}
+		result[folderPath] = meta
+	}
+	return result, nil
+}
+
+// getFolderMetaByUsername returns metadata of a single folder, or nil if none is set
+func getFolderMetaByUsername(db *database.DB, username, folderPath string) *FolderMeta {
+	var meta FolderMeta
+	err := db.QueryRow(
+		`SELECT fm.description, fm.color, fm.default_type FROM folder_meta fm
+		 JOIN users u ON u.id = fm.user_id
+		 WHERE u.username = ? AND fm.folder_path = ?`,
+		username, folderPath,
+	).Scan(&meta.Description, &meta.Color, &meta.DefaultType)
+	if err != nil {
+		return nil // No metadata (sql.ErrNoRows) or lookup failure
+	}
+	return &meta
+}
+
+// renameFolderMeta moves metadata of a folder and its subfolders to a new path
+func renameFolderMeta(db *database.DB, userID int64, oldPath, newPath string) error {
+	_, err := db.Exec(
+		`UPDATE folder_meta SET folder_path = ? || substr(folder_path, ?)
+		 WHERE user_id = ? AND (folder_path = ? OR folder_path LIKE ? ESCAPE '\')`,
+		newPath, utf8.RuneCountInString(oldPath)+1, userID, oldPath, escapeLike(oldPath)+"/%",
+	)
+	return err
+}
+
+// deleteFolderMeta removes metadata of a folder and its subfolders
+func deleteFolderMeta(db *database.DB, userID int64, folderPath string) error {
+	_, err := db.Exec(
+		`DELETE FROM folder_meta WHERE user_id = ? AND (folder_path = ? OR folder_path LIKE ? ESCAPE '\')`,
+		userID, folderPath, escapeLike(folderPath)+"/%",
+	)
+	return err
+}
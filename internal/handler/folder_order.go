@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"database/sql"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/user/gitnotepad/internal/database"
@@ -177,3 +180,151 @@ func (h *FolderOrderHandler) Delete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Order deleted"})
 }
+
+// renameFolderOrder updates stored folder order after a folder is renamed or moved
+// Rows for the folder and its subfolders are re-keyed, and the folder name is
+// replaced (same parent) or moved (different parent) in the parent order lists
+func renameFolderOrder(db *database.DB, userID int64, oldPath, newPath string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Re-key order rows of the folder itself and its descendants
+	_, err = tx.Exec(
+		`UPDATE folder_order SET parent_path = ? || substr(parent_path, ?), updated_at = ?
+		 WHERE user_id = ? AND (parent_path = ? OR parent_path LIKE ? ESCAPE '\')`,
+		newPath, utf8.RuneCountInString(oldPath)+1, time.Now(), userID, oldPath, escapeLike(oldPath)+"/%",
+	)
+	if err != nil {
+		return err
+	}
+
+	oldParent, oldName := splitFolderPath(oldPath)
+	newParent, newName := splitFolderPath(newPath)
+
+	oldOrder, err := loadFolderOrder(tx, userID, oldParent)
+	if err != nil {
+		return err
+	}
+
+	if oldParent == newParent {
+		// Same parent: rename in place to keep the position
+		if oldOrder != nil {
+			for i, name := range oldOrder {
+				if name == oldName {
+					oldOrder[i] = newName
+				}
+			}
+			if err := saveFolderOrder(tx, userID, oldParent, oldOrder); err != nil {
+				return err
+			}
+		}
+		return tx.Commit()
+	}
+
+	// Different parent: remove from old list, append to new list if one exists
+	if oldOrder != nil {
+		filtered := make([]string, 0, len(oldOrder))
+		for _, name := range oldOrder {
+			if name != oldName {
+				filtered = append(filtered, name)
+			}
+		}
+		if err := saveFolderOrder(tx, userID, oldParent, filtered); err != nil {
+			return err
+		}
+	}
+
+	newOrder, err := loadFolderOrder(tx, userID, newParent)
+	if err != nil {
+		return err
+	}
+	if newOrder != nil {
+		if err := saveFolderOrder(tx, userID, newParent, append(newOrder, newName)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// deleteFolderOrder removes stored order of a folder and its subfolders, and drops it from its parent list
+func deleteFolderOrder(db *database.DB, userID int64, folderPath string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`DELETE FROM folder_order WHERE user_id = ? AND (parent_path = ? OR parent_path LIKE ? ESCAPE '\')`,
+		userID, folderPath, escapeLike(folderPath)+"/%",
+	)
+	if err != nil {
+		return err
+	}
+
+	parent, name := splitFolderPath(folderPath)
+	order, err := loadFolderOrder(tx, userID, parent)
+	if err != nil {
+		return err
+	}
+	if order != nil {
+		filtered := make([]string, 0, len(order))
+		for _, n := range order {
+			if n != name {
+				filtered = append(filtered, n)
+			}
+		}
+		if err := saveFolderOrder(tx, userID, parent, filtered); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// splitFolderPath splits "a/b/c" into parent "a/b" and name "c" (parent is "" for top-level folders)
+func splitFolderPath(folderPath string) (parent, name string) {
+	idx := strings.LastIndex(folderPath, "/")
+	if idx == -1 {
+		return "", folderPath
+	}
+	return folderPath[:idx], folderPath[idx+1:]
+}
+
+// loadFolderOrder returns the stored order for a parent path, or nil if none exists
+func loadFolderOrder(tx *sql.Tx, userID int64, parentPath string) ([]string, error) {
+	var orderJSON string
+	err := tx.QueryRow(
+		"SELECT order_json FROM folder_order WHERE user_id = ? AND parent_path = ?",
+		userID, parentPath,
+	).Scan(&orderJSON)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var order []string
+	if err := json.Unmarshal([]byte(orderJSON), &order); err != nil {
+		return nil, nil
+	}
+	return order, nil
+}
+
+// saveFolderOrder overwrites the stored order for a parent path
+func saveFolderOrder(tx *sql.Tx, userID int64, parentPath string, order []string) error {
+	orderJSON, err := json.Marshal(order)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		"UPDATE folder_order SET order_json = ?, updated_at = ? WHERE user_id = ? AND parent_path = ?",
+		string(orderJSON), time.Now(), userID, parentPath,
+	)
+	return err
+}
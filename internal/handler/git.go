@@ -9,6 +9,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/user/gitnotepad/internal/git"
+	"github.com/user/gitnotepad/internal/index"
 	"github.com/user/gitnotepad/internal/middleware"
 	"github.com/user/gitnotepad/internal/model"
 )
@@ -22,21 +23,23 @@ func decodeGitNoteID(id string) string {
 		// Fallback to standard base64 (for backwards compatibility)
 		decoded, err = base64.StdEncoding.DecodeString(id)
 		if err != nil {
-			return id // Return original if decode fails
+			return normalizeName(id) // Return original if decode fails
 		}
 	}
-	return string(decoded)
+	return normalizeName(string(decoded))
 }
 
 type GitHandler struct {
-	repo     *git.Repository
-	basePath string
+	repo      *git.Repository
+	basePath  string
+	noteIndex *index.Index
 }
 
-func NewGitHandler(repo *git.Repository) *GitHandler {
+func NewGitHandler(repo *git.Repository, noteIndex *index.Index) *GitHandler {
 	return &GitHandler{
-		repo:     repo,
-		basePath: repo.GetPath(),
+		repo:      repo,
+		basePath:  repo.GetPath(),
+		noteIndex: noteIndex,
 	}
 }
 
@@ -77,9 +80,23 @@ func (h *GitHandler) getUserRepo(c *gin.Context) (*git.Repository, error) {
 	return repo, nil
 }
 
+// resolveNoteID maps a note UID to the current note ID so history follows the note's current file
+func (h *GitHandler) resolveNoteID(notesPath, id string) string {
+	if strings.Contains(id, "/") || noteFileExists(notesPath, id) {
+		return id
+	}
+	if resolved, ok := h.noteIndex.Resolve(notesPath, id, parsePlainNote); ok {
+		return resolved
+	}
+	return id
+}
+
 func (h *GitHandler) History(c *gin.Context) {
-	id := decodeGitNoteID(c.Param("id"))
 	notesPath := h.getNotesPath(c)
+	id := h.resolveNoteID(notesPath, decodeGitNoteID(c.Param("id")))
+	if folder, _ := splitFolderPath(id); denyShare(c, folder, model.PermissionRead) {
+		return
+	}
 
 	// Find the note file
 	var filePath string
@@ -125,9 +142,12 @@ func (h *GitHandler) History(c *gin.Context) {
 }
 
 func (h *GitHandler) Version(c *gin.Context) {
-	id := decodeGitNoteID(c.Param("id"))
 	commit := c.Param("commit")
 	notesPath := h.getNotesPath(c)
+	id := h.resolveNoteID(notesPath, decodeGitNoteID(c.Param("id")))
+	if folder, _ := splitFolderPath(id); denyShare(c, folder, model.PermissionRead) {
+		return
+	}
 
 	// Get user-specific repo
 	userRepo, err := h.getUserRepo(c)
@@ -182,6 +202,15 @@ func (h *GitHandler) Version(c *gin.Context) {
 	// Parse the content to extract note data
 	parsedContent := parseVersionContent(string(content))
 
+	// Content of a password-protected version is encrypted with the password the note had then
+	if version, err := model.ParseNoteFromBytes(content, filePath); err == nil && version.ContentLocked() {
+		if !version.Unlock(c.GetHeader("X-Note-Password")) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+			return
+		}
+		parsedContent = version.Content
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"commit":  commit,
 		"content": parsedContent,
@@ -13,7 +13,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/user/gitnotepad/internal/config"
 	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
 )
 
 // ImageMetadata stores the mapping between UUID filenames and original filenames
@@ -29,12 +31,14 @@ var imageMetadata = &ImageMetadata{
 type ImageHandler struct {
 	storagePath string
 	basePath    string
+	upload      config.UploadConfig // Size and type limits of uploads
 }
 
-func NewImageHandler(storagePath string, basePath string) *ImageHandler {
+func NewImageHandler(storagePath string, basePath string, upload config.UploadConfig) *ImageHandler {
 	return &ImageHandler{
 		storagePath: storagePath,
 		basePath:    basePath,
+		upload:      upload,
 	}
 }
 
@@ -99,7 +103,7 @@ func (h *ImageHandler) getOriginalName(username, uuidName string) string {
 
 // getUserFilesPath returns the user-specific files directory
 func (h *ImageHandler) getUserFilesPath(c *gin.Context) string {
-	user := middleware.GetCurrentUser(c)
+	user := storageOwner(c)
 	if user == nil {
 		// Fallback to global files directory
 		return filepath.Join(h.storagePath, "files")
@@ -111,9 +115,21 @@ func (h *ImageHandler) getUserFilesPath(c *gin.Context) string {
 }
 
 func (h *ImageHandler) Upload(c *gin.Context) {
-	file, header, err := c.Request.FormFile("image")
+	// Uploading into a shared folder requires write permission (?owner=...&folder_path=...)
+	if !middleware.HasSharePermission(c, c.Query("folder_path"), model.PermissionWrite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission for shared folder"})
+		return
+	}
+
+	folder, ok := uploadFolder(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+		return
+	}
+
+	file, header, err := openUpload(c, h.upload, "image")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No image provided"})
+		uploadError(c, h.upload, err, "No image provided")
 		return
 	}
 	defer file.Close()
@@ -121,9 +137,10 @@ func (h *ImageHandler) Upload(c *gin.Context) {
 	// Get original filename
 	originalName := header.Filename
 
-	// Validate content type
+	// Validate content type (images, and audio such as recorded voice memos)
 	contentType := header.Header.Get("Content-Type")
-	if !strings.HasPrefix(contentType, "image/") {
+	isAudio := strings.HasPrefix(contentType, "audio/")
+	if !strings.HasPrefix(contentType, "image/") && !isAudio {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file type"})
 		return
 	}
@@ -140,6 +157,10 @@ func (h *ImageHandler) Upload(c *gin.Context) {
 	case "image/svg+xml":
 		ext = ".svg"
 	}
+	if isAudio {
+		mediaType, _, _ := strings.Cut(contentType, ";")
+		ext = audioExtension(originalName, strings.TrimSpace(mediaType))
+	}
 
 	// Get user-specific files directory
 	userFilesPath := h.getUserFilesPath(c)
@@ -162,15 +183,23 @@ func (h *ImageHandler) Upload(c *gin.Context) {
 		return
 	}
 
-	// Get username for URL
-	user := middleware.GetCurrentUser(c)
+	// Get username for URL (share owner when uploading into a shared folder)
+	user := storageOwner(c)
 	username := "shared"
 	if user != nil {
 		username = user.Username
 	}
 
-	// Save metadata mapping (UUID -> original filename)
-	h.saveMetadata(username, filename, originalName)
+	// Save metadata mapping (UUID -> original filename) and the folder in the files area
+	// Audio is kept with the other files, so it isn't listed as an image
+	if isAudio {
+		(&FileHandler{storagePath: h.storagePath}).saveMetadata(username, filename, originalName)
+	} else {
+		h.saveMetadata(username, filename, originalName)
+	}
+	if folder != "" {
+		setFileFolder(h.storagePath, username, filename, folder)
+	}
 
 	// Return URL for the file (with base path and username)
 	fileURL := fmt.Sprintf("%s/u/%s/files/%s", h.basePath, username, filename)
@@ -178,6 +207,8 @@ func (h *ImageHandler) Upload(c *gin.Context) {
 		"url":          fileURL,
 		"filename":     filename,
 		"originalName": originalName,
+		"type":         attachmentContentType(filename),
+		"isImage":      !isAudio,
 	})
 }
 
@@ -230,7 +261,7 @@ func (h *ImageHandler) Serve(c *gin.Context) {
 		encodedName := url.PathEscape(originalName)
 		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, safeFilename, encodedName))
 	}
-	c.File(filePath)
+	serveAttachment(c, filePath)
 }
 
 // deleteMetadata removes image metadata from disk for a user
@@ -268,8 +299,13 @@ func (h *ImageHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	if !middleware.HasSharePermission(c, c.Query("folder_path"), model.PermissionWrite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission for shared folder"})
+		return
+	}
+
 	// Get user-specific files directory
-	user := middleware.GetCurrentUser(c)
+	user := storageOwner(c)
 	if user == nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -283,15 +319,11 @@ func (h *ImageHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	// Delete the file
-	if err := os.Remove(filePath); err != nil {
+	if err := removeUploadedFile(h.storagePath, user.Username, filename); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete image"})
 		return
 	}
 
-	// Delete metadata
-	h.deleteMetadata(user.Username, filename)
-
 	c.JSON(http.StatusOK, gin.H{"message": "Image deleted"})
 }
 
@@ -313,5 +345,5 @@ func (h *ImageHandler) ServeLegacy(c *gin.Context) {
 		return
 	}
 
-	c.File(filePath)
+	serveAttachment(c, filePath)
 }
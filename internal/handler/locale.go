@@ -0,0 +1,13 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/i18n"
+	"github.com/user/gitnotepad/internal/middleware"
+)
+
+// tr translates a message of an HTML page into the language of the request
+// (JSON errors are translated by the locale middleware)
+func tr(c *gin.Context, msg string) string {
+	return i18n.T(middleware.Language(c), msg)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/repository"
+	"github.com/user/gitnotepad/internal/websocket"
+)
+
+type MaintenanceHandler struct {
+	maintenance *middleware.Maintenance
+	hub         *websocket.Hub
+	auditRepo   *repository.AuditRepository
+}
+
+func NewMaintenanceHandler(maintenance *middleware.Maintenance, hub *websocket.Hub, auditRepo *repository.AuditRepository) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		maintenance: maintenance,
+		hub:         hub,
+		auditRepo:   auditRepo,
+	}
+}
+
+// SetMaintenanceRequest turns maintenance mode on or off
+type SetMaintenanceRequest struct {
+	Enabled *bool  `json:"enabled" binding:"required"`
+	Message string `json:"message" binding:"max=500"`
+}
+
+// Get returns whether the server is read-only, so the app can show the banner after (re)connecting
+func (h *MaintenanceHandler) Get(c *gin.Context) {
+	c.JSON(http.StatusOK, h.maintenance.Status())
+}
+
+// Set switches maintenance mode and tells every connected browser (admin only)
+// The change lasts until the next restart, which uses maintenance.enabled from the config again
+func (h *MaintenanceHandler) Set(c *gin.Context) {
+	var req SetMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.maintenance.Set(*req.Enabled, req.Message)
+	status := h.maintenance.Status()
+
+	detail := "off"
+	if status.Enabled {
+		detail = "on"
+	}
+	recordAudit(h.auditRepo, c, model.AuditMaintenance, "", detail)
+
+	if h.hub != nil {
+		h.hub.BroadcastToAll(websocket.Message{Type: websocket.MsgTypeMaintenance, Data: status})
+	}
+
+	c.JSON(http.StatusOK, status)
+}
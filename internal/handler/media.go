@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mediaTypes are the content types of audio and video attachments, which browsers only play
+// (and seek in) when they are served with the right type; system MIME tables often lack them
+var mediaTypes = map[string]string{
+	".mp4":  "video/mp4",
+	".m4v":  "video/mp4",
+	".mov":  "video/quicktime",
+	".webm": "video/webm",
+	".ogv":  "video/ogg",
+	".mp3":  "audio/mpeg",
+	".m4a":  "audio/mp4",
+	".m4b":  "audio/mp4",
+	".aac":  "audio/aac",
+	".weba": "audio/webm",
+	".ogg":  "audio/ogg",
+	".oga":  "audio/ogg",
+	".opus": "audio/ogg",
+	".wav":  "audio/wav",
+	".flac": "audio/flac",
+}
+
+// attachmentContentType returns the content type of an attachment from its extension ("" if unknown)
+func attachmentContentType(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if contentType, ok := mediaTypes[ext]; ok {
+		return contentType
+	}
+	return mime.TypeByExtension(ext)
+}
+
+// audioExtension returns the extension an audio upload is stored with: the one of its name when that
+// is a known audio extension, otherwise one for its content type
+func audioExtension(name, contentType string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if strings.HasPrefix(mediaTypes[ext], "audio/") {
+		return ext
+	}
+	return extensionForType(contentType)
+}
+
+// isMediaFile reports whether an attachment is audio or video
+func isMediaFile(name string) bool {
+	_, ok := mediaTypes[strings.ToLower(filepath.Ext(name))]
+	return ok
+}
+
+// serveAttachment sends an attachment file with its content type
+// Range requests are answered with 206 Partial Content, so video and audio can be streamed and scrubbed
+func serveAttachment(c *gin.Context, filePath string) {
+	if contentType := attachmentContentType(filePath); contentType != "" {
+		c.Header("Content-Type", contentType)
+	}
+	setAttachmentETag(c, filePath)
+	c.File(filePath)
+}
+
+// attachmentETags caches content hashes by path, so a file is only hashed again after it changed
+var attachmentETags = struct {
+	sync.Mutex
+	entries map[string]attachmentETag
+}{entries: make(map[string]attachmentETag)}
+
+type attachmentETag struct {
+	size    int64
+	modTime time.Time
+	etag    string
+}
+
+// setAttachmentETag sets an ETag from the file's content hash and lets the browser revalidate its copy:
+// http.ServeContent (c.File) then answers If-None-Match and If-Modified-Since with 304 Not Modified
+// Attachments can be replaced under the same name, so they are not cached without asking
+func setAttachmentETag(c *gin.Context, filePath string) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return
+	}
+
+	attachmentETags.Lock()
+	cached, ok := attachmentETags.entries[filePath]
+	attachmentETags.Unlock()
+	if !ok || cached.size != info.Size() || !cached.modTime.Equal(info.ModTime()) {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return
+		}
+		hash := sha256.New()
+		_, err = io.Copy(hash, f)
+		f.Close()
+		if err != nil {
+			return
+		}
+		cached = attachmentETag{
+			size:    info.Size(),
+			modTime: info.ModTime(),
+			etag:    `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`,
+		}
+		attachmentETags.Lock()
+		attachmentETags.entries[filePath] = cached
+		attachmentETags.Unlock()
+	}
+
+	c.Header("ETag", cached.etag)
+	c.Header("Cache-Control", "private, no-cache")
+}
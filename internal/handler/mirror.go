@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/mirror"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/repository"
+)
+
+type MirrorHandler struct {
+	mirrorRepo *repository.MirrorRepository
+	auditRepo  *repository.AuditRepository
+	service    *mirror.Service
+}
+
+func NewMirrorHandler(mirrorRepo *repository.MirrorRepository, auditRepo *repository.AuditRepository, service *mirror.Service) *MirrorHandler {
+	return &MirrorHandler{
+		mirrorRepo: mirrorRepo,
+		auditRepo:  auditRepo,
+		service:    service,
+	}
+}
+
+// MirrorResponse is the mirror configuration of the current user (the token itself is never returned)
+type MirrorResponse struct {
+	Configured bool `json:"configured"`
+	HasToken   bool `json:"has_token"`
+	*model.GitMirror
+}
+
+// SetMirrorRequest configures the remote the notes repository is pushed to
+type SetMirrorRequest struct {
+	URL      string  `json:"url" binding:"required"`
+	Username string  `json:"username"`
+	Token    *string `json:"token"`   // nil keeps the current token
+	Enabled  *bool   `json:"enabled"` // Defaults to true
+}
+
+func mirrorResponse(m *model.GitMirror) MirrorResponse {
+	if m == nil {
+		return MirrorResponse{}
+	}
+	return MirrorResponse{Configured: true, HasToken: m.Token != "", GitMirror: m}
+}
+
+// validMirrorURL accepts http(s) remotes only, so users cannot make the server push to local paths
+func validMirrorURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.User != nil {
+		return false
+	}
+	return u.Scheme == "https" || u.Scheme == "http"
+}
+
+// Get returns the git mirror of the current user
+func (h *MirrorHandler) Get(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	m, err := h.mirrorRepo.Get(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get git mirror"})
+		return
+	}
+
+	c.JSON(http.StatusOK, mirrorResponse(m))
+}
+
+// Set creates or updates the git mirror of the current user and schedules a first push
+func (h *MirrorHandler) Set(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req SetMirrorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.URL = strings.TrimSpace(req.URL)
+	if !validMirrorURL(req.URL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid remote URL (expected https://host/owner/repo.git without credentials)"})
+		return
+	}
+
+	existing, err := h.mirrorRepo.Get(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get git mirror"})
+		return
+	}
+
+	m := &model.GitMirror{
+		UserID:   user.ID,
+		URL:      req.URL,
+		Username: strings.TrimSpace(req.Username),
+		Enabled:  req.Enabled == nil || *req.Enabled,
+	}
+	if m.Username == "" {
+		m.Username = "git" // GitHub and GitLab accept any username with a token
+	}
+	if req.Token != nil {
+		m.Token = *req.Token
+	} else if existing != nil {
+		m.Token = existing.Token
+	}
+
+	if err := h.mirrorRepo.Upsert(m); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save git mirror"})
+		return
+	}
+	recordAudit(h.auditRepo, c, model.AuditMirrorSet, m.URL, "")
+
+	if m.Enabled {
+		h.service.Trigger(user.Username)
+	}
+
+	c.JSON(http.StatusOK, mirrorResponse(m))
+}
+
+// Delete removes the git mirror of the current user (the remote repository is left untouched)
+func (h *MirrorHandler) Delete(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.mirrorRepo.Delete(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete git mirror"})
+		return
+	}
+	recordAudit(h.auditRepo, c, model.AuditMirrorDelete, "", "")
+
+	c.JSON(http.StatusOK, gin.H{"message": "Git mirror removed"})
+}
+
+// Push pushes the notes repository to the mirror immediately and returns the updated status
+func (h *MirrorHandler) Push(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.service.PushNow(user.Username); err != nil {
+		switch {
+		case errors.Is(err, mirror.ErrNotConfigured):
+			c.JSON(http.StatusNotFound, gin.H{"error": "No git mirror configured"})
+		case errors.Is(err, mirror.ErrPushInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": "Push already in progress"})
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	m, err := h.mirrorRepo.Get(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get git mirror"})
+		return
+	}
+	c.JSON(http.StatusOK, mirrorResponse(m))
+}
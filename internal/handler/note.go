@@ -2,45 +2,90 @@ package handler
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/user/gitnotepad/internal/config"
+	"github.com/user/gitnotepad/internal/database"
 	"github.com/user/gitnotepad/internal/encoding"
 	"github.com/user/gitnotepad/internal/encryption"
+	"github.com/user/gitnotepad/internal/fetch"
 	"github.com/user/gitnotepad/internal/git"
+	"github.com/user/gitnotepad/internal/index"
 	"github.com/user/gitnotepad/internal/middleware"
 	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/notify"
+	"github.com/user/gitnotepad/internal/repository"
 	"github.com/user/gitnotepad/internal/websocket"
 )
 
 type NoteHandler struct {
-	repo     *git.Repository
-	config   *config.Config
-	basePath string
-	wsHub    *websocket.Hub
+	repo        *git.Repository
+	config      *config.Config
+	basePath    string
+	wsHub       *websocket.Hub
+	db          *database.DB
+	shares      *repository.ShareRepository
+	folderRules *repository.FolderRuleRepository
+	users       *repository.UserRepository
+	shortLinks  *ShortLinkHandler
+	noteIndex   *index.Index
+	auditRepo   *repository.AuditRepository
+	noteLinks   *repository.NoteLinkRepository
+	fetcher     *fetch.Client
+	events      *notify.Dispatcher
 }
 
-func NewNoteHandler(repo *git.Repository, cfg *config.Config, wsHub *websocket.Hub) *NoteHandler {
-	return &NoteHandler{
-		repo:     repo,
-		config:   cfg,
-		basePath: cfg.Storage.Path,
-		wsHub:    wsHub,
+func NewNoteHandler(repo *git.Repository, cfg *config.Config, wsHub *websocket.Hub, db *database.DB, shortLinks *ShortLinkHandler, noteIndex *index.Index, fetcher *fetch.Client) *NoteHandler {
+	h := &NoteHandler{
+		repo:       repo,
+		config:     cfg,
+		basePath:   cfg.Storage.Path,
+		wsHub:      wsHub,
+		db:         db,
+		shortLinks: shortLinks,
+		noteIndex:  noteIndex,
+		fetcher:    fetcher,
+	}
+	if db != nil {
+		h.shares = repository.NewShareRepository(db.DB)
+		h.folderRules = repository.NewFolderRuleRepository(db.DB)
+		h.users = repository.NewUserRepository(db.DB)
+		h.auditRepo = repository.NewAuditRepository(db.DB)
+		h.noteLinks = repository.NewNoteLinkRepository(db.DB)
+	}
+	return h
+}
+
+// SetEvents sets where notes added to shared folders are announced (e.g., to the Telegram bot)
+func (h *NoteHandler) SetEvents(events *notify.Dispatcher) {
+	h.events = events
+}
+
+// getEncryptionKey returns the session encryption key, or nil when accessing another user's shared notes
+// (their files are encrypted with the owner's key, which is not available here)
+func (h *NoteHandler) getEncryptionKey(c *gin.Context) []byte {
+	if middleware.GetShareOwner(c) != nil {
+		return nil
 	}
+	return middleware.GetEncryptionKey(c)
 }
 
 // getUserStoragePath returns the user-specific storage directory (root)
 func (h *NoteHandler) getUserStoragePath(c *gin.Context) string {
-	user := middleware.GetCurrentUser(c)
+	user := storageOwner(c)
 	if user == nil {
 		return h.basePath // Fallback (shouldn't happen with auth middleware)
 	}
@@ -132,6 +177,50 @@ func (h *NoteHandler) loadNoteFromBytes(data []byte, path string, encryptionKey
 	return model.ParseNoteFromBytes(data, path)
 }
 
+// findNote locates a note by ID (relative path without extension), trying each note extension
+// Returns the absolute file path and the loaded note, or nil if not found
+func (h *NoteHandler) findNote(notesPath, id string, encryptionKey []byte) (string, *model.Note) {
+	for _, ext := range []string{".md", ".txt", ".adoc"} {
+		filePath, _ := filepath.Abs(filepath.Join(notesPath, id+ext))
+		note, err := h.loadNoteFromFile(filePath, encryptionKey)
+		if err == nil {
+			return filePath, note
+		}
+	}
+	return "", nil
+}
+
+// noteFileExists reports whether a note file exists for the ID (relative path without extension)
+func noteFileExists(notesPath, id string) bool {
+	for _, ext := range []string{".md", ".txt", ".adoc"} {
+		if _, err := os.Stat(filepath.Join(notesPath, filepath.FromSlash(id)+ext)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveNoteID maps a stable note UID to the note's current ID (relative path without extension)
+// IDs that already point at a note file are returned unchanged
+func (h *NoteHandler) resolveNoteID(c *gin.Context, id string) string {
+	if id == "" || strings.Contains(id, "/") {
+		return id
+	}
+	notesPath := h.getNotesPath(c)
+	if noteFileExists(notesPath, id) {
+		return id
+	}
+
+	encryptionKey := h.getEncryptionKey(c)
+	resolved, ok := h.noteIndex.Resolve(notesPath, id, func(path string, data []byte) (*model.Note, error) {
+		return h.loadNoteFromBytes(data, path, encryptionKey)
+	})
+	if !ok {
+		return id
+	}
+	return resolved
+}
+
 // saveNoteToFile saves a note to file, encrypting if enabled
 func (h *NoteHandler) saveNoteToFile(note *model.Note, path string, encryptionKey []byte) error {
 	content, err := note.ToFileContent()
@@ -148,7 +237,12 @@ func (h *NoteHandler) saveNoteToFile(note *model.Note, path string, encryptionKe
 		content = []byte(encrypted)
 	}
 
-	return os.WriteFile(path, content, 0644)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return err
+	}
+	h.noteIndex.InvalidatePath(path)
+	h.recordNoteLinks(path, note)
+	return nil
 }
 
 // decodeNoteID base64-decodes the note ID from path parameter
@@ -160,10 +254,14 @@ func decodeNoteID(id string) string {
 		// Fallback to standard base64 (for backwards compatibility)
 		decoded, err = base64.StdEncoding.DecodeString(id)
 		if err != nil {
-			return id // Return original if decode fails
+			return normalizeName(id) // Return original if decode fails
 		}
 	}
-	return string(decoded)
+	// A UID such as "c321ff65-7e4f-..." is also valid base64url; its decoded bytes are not text
+	if !utf8.Valid(decoded) {
+		return normalizeName(id)
+	}
+	return normalizeName(string(decoded))
 }
 
 // FolderSeparator is the delimiter used in note titles to indicate folder paths
@@ -463,6 +561,95 @@ func MigrateNoteTitleFolderPath(storagePath string) error {
 	return nil
 }
 
+// MigrateNoteUIDs adds a stable UID to the frontmatter of notes that don't have one.
+// Notes named by UUID keep their filename as UID so existing links resolve to the same note.
+// Encrypted notes are skipped (they get a UID on their next update).
+func MigrateNoteUIDs(storagePath string) error {
+	entries, err := os.ReadDir(storagePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	migratedCount := 0
+
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		userDir := filepath.Join(storagePath, entry.Name())
+		notesDir := filepath.Join(userDir, "notes")
+		if _, err := os.Stat(notesDir); os.IsNotExist(err) {
+			continue
+		}
+
+		userMigrated := 0
+		err := filepath.WalkDir(notesDir, func(path string, d fs.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			ext := filepath.Ext(path)
+			if ext != ".md" && ext != ".txt" && ext != ".adoc" {
+				return nil
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil || encryption.IsEncrypted(string(data)) {
+				return nil
+			}
+
+			note, err := model.ParseNoteFromBytes(data, path)
+			if err != nil || note.UID != "" {
+				return nil
+			}
+
+			note.UID = newNoteUID(note.ID)
+			content, err := note.ToFileContent()
+			if err != nil {
+				return nil
+			}
+			if err := os.WriteFile(path, content, 0644); err != nil {
+				encoding.Warn("Failed to add UID to %s: %v", path, err)
+				return nil
+			}
+
+			userMigrated++
+			return nil
+		})
+		if err != nil {
+			encoding.Warn("Error walking %s: %v", userDir, err)
+		}
+
+		if userMigrated == 0 {
+			continue
+		}
+		migratedCount += userMigrated
+
+		// Commit in the user's own repository so the change doesn't linger as uncommitted
+		if userRepo, err := git.NewRepository(userDir); err == nil && userRepo.Init() == nil {
+			if err := userRepo.AddPathsAndCommit([]string{notesDir}, "Add stable note UIDs"); err != nil {
+				encoding.Debug("Git commit error: %v", err)
+			}
+		}
+	}
+
+	if migratedCount > 0 {
+		encoding.Info("Note UID migration: added UIDs to %d notes", migratedCount)
+	}
+
+	return nil
+}
+
 // getUserRepo returns a git repository for the user's storage path
 func (h *NoteHandler) getUserRepo(c *gin.Context) (*git.Repository, error) {
 	storagePath := h.getUserStoragePath(c)
@@ -479,23 +666,117 @@ func (h *NoteHandler) getUserRepo(c *gin.Context) (*git.Repository, error) {
 
 type NoteListItem struct {
 	ID         string    `json:"id"`
+	UID        string    `json:"uid,omitempty"`
 	FolderPath string    `json:"folder_path"`
 	Title      string    `json:"title"`
 	Type       string    `json:"type"`
 	Icon       string    `json:"icon,omitempty"`
 	Tags       []string  `json:"tags"`
+	Aliases    []string  `json:"aliases,omitempty"`
 	Private    bool      `json:"private"`
+	Archived   bool      `json:"archived,omitempty"`
 	Encrypted  bool      `json:"encrypted"`
+	Due        string    `json:"due,omitempty"`
 	Created    time.Time `json:"created"`
 	Modified   time.Time `json:"modified"`
 }
 
 func (h *NoteHandler) List(c *gin.Context) {
 	notesPath := h.getNotesPath(c)
-	encryptionKey := middleware.GetEncryptionKey(c)
+	encryptionKey := h.getEncryptionKey(c)
 	searchQuery := strings.ToLower(strings.TrimSpace(c.Query("q")))
+	loc := middleware.Location(c)
 
 	var notes []NoteListItem
+	if searchQuery == "" {
+		// Served from the note index, which only rereads files that changed since the last listing
+		entries := h.noteIndex.Entries(notesPath, func(path string, data []byte) (*model.Note, error) {
+			return h.loadNoteFromBytes(data, path, encryptionKey)
+		})
+		includeArchived := c.Query("include_archived") == "true"
+		sharedNotes := make(map[string]bool)
+		for _, uid := range middleware.SharedNoteUIDs(c, model.PermissionRead) {
+			sharedNotes[uid] = true
+		}
+		for _, entry := range entries {
+			// Archived notes are left out unless asked for; search (?q=) still finds them
+			if entry.Archived && !includeArchived {
+				continue
+			}
+			// Only notes inside folders shared with the current user, or shared on their own
+			if folder, _ := splitFolderPath(entry.ID); sharedNotes[entry.UID] || middleware.HasSharePermission(c, folder, model.PermissionRead) {
+				notes = append(notes, noteListItemFromEntry(entry, loc))
+			}
+		}
+	} else if notes = h.searchNoteList(c, notesPath, encryptionKey, searchQuery); notes == nil {
+		c.JSON(http.StatusOK, []NoteListItem{})
+		return
+	}
+
+	// ?tag= keeps only notes with that tag (case-insensitive)
+	if tag := strings.TrimSpace(c.Query("tag")); tag != "" {
+		tagged := []NoteListItem{}
+		for _, note := range notes {
+			for _, t := range note.Tags {
+				if strings.EqualFold(t, tag) {
+					tagged = append(tagged, note)
+					break
+				}
+			}
+		}
+		notes = tagged
+	}
+
+	// ?folder= keeps only notes directly inside that folder (empty = notes at the top level)
+	if folder, ok := c.GetQuery("folder"); ok {
+		folder = strings.Trim(strings.ReplaceAll(folder, FolderSeparator, "/"), "/")
+		inFolder := []NoteListItem{}
+		for _, note := range notes {
+			if parent, _ := splitFolderPath(note.ID); parent == folder {
+				inFolder = append(inFolder, note)
+			}
+		}
+		notes = inFolder
+	}
+
+	// Order the notes by ?sort=&order= across all folders, otherwise each folder's notes by the user's saved sort
+	order := noteOrder{sorts: NoteSortMap{}, override: NoteSort{Field: c.Query("sort"), Direction: c.DefaultQuery("order", "asc")}}
+	if user := middleware.GetCurrentUser(c); user != nil && h.db != nil {
+		if saved, err := loadNoteSorts(h.db, user.ID); err == nil {
+			order.sorts = saved
+		}
+	}
+	sortNoteList(notes, order)
+
+	// ?limit= returns one page of the list, continued by ?cursor= (from X-Next-Cursor) or ?offset=
+	// X-Total-Count has the number of notes before paging
+	c.Header("X-Total-Count", strconv.Itoa(len(notes)))
+	if cursor := c.Query("cursor"); cursor != "" {
+		after, ok := notesAfterCursor(notes, order, cursor)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		notes = after
+	} else if offset, err := strconv.Atoi(c.Query("offset")); err == nil && offset > 0 {
+		if offset > len(notes) {
+			offset = len(notes)
+		}
+		notes = notes[offset:]
+	}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil && limit > 0 && limit < len(notes) {
+		notes = notes[:limit]
+		c.Header("X-Next-Cursor", encodeNoteCursor(notes[limit-1]))
+	}
+
+	c.JSON(http.StatusOK, trimFields(notes, requestedFields(c)))
+}
+
+// searchNoteList walks the notes and returns the ones matching a search query in their title,
+// aliases, content or attachment names; nil if the notes directory cannot be read
+func (h *NoteHandler) searchNoteList(c *gin.Context, notesPath string, encryptionKey []byte, searchQuery string) []NoteListItem {
+	loc := middleware.Location(c)
+	notes := []NoteListItem{}
 
 	// Walk through all directories recursively
 	err := filepath.WalkDir(notesPath, func(path string, d fs.DirEntry, err error) error {
@@ -532,21 +813,25 @@ func (h *NoteHandler) List(c *gin.Context) {
 			return nil
 		}
 
-		// Search filter: check title, content, and attachments
-		if searchQuery != "" {
-			titleMatch := strings.Contains(strings.ToLower(note.Title), searchQuery)
-			contentMatch := strings.Contains(strings.ToLower(note.Content), searchQuery)
-			attachmentMatch := false
-			for _, att := range note.Attachments {
-				if strings.Contains(strings.ToLower(att.Name), searchQuery) {
-					attachmentMatch = true
-					break
-				}
+		// Search filter: check title, aliases, content, and attachments
+		titleMatch := strings.Contains(strings.ToLower(note.Title), searchQuery)
+		for _, alias := range note.Aliases {
+			if strings.Contains(strings.ToLower(alias), searchQuery) {
+				titleMatch = true
+				break
 			}
-			if !titleMatch && !contentMatch && !attachmentMatch {
-				return nil // Skip notes that don't match
+		}
+		contentMatch := strings.Contains(strings.ToLower(note.Content), searchQuery)
+		attachmentMatch := false
+		for _, att := range note.Attachments {
+			if strings.Contains(strings.ToLower(att.Name), searchQuery) {
+				attachmentMatch = true
+				break
 			}
 		}
+		if !titleMatch && !contentMatch && !attachmentMatch {
+			return nil // Skip notes that don't match
+		}
 
 		// Calculate relative path from notesPath for the ID
 		relPath, err := filepath.Rel(notesPath, path)
@@ -559,58 +844,58 @@ func (h *NoteHandler) List(c *gin.Context) {
 		// Remove extension to get ID
 		id := strings.TrimSuffix(relPath, ext)
 
+		// Only notes inside folders shared with the current user
+		if folder, _ := splitFolderPath(id); !middleware.HasSharePermission(c, folder, model.PermissionRead) {
+			return nil
+		}
+
+		h.noteIndex.Put(notesPath, note.UID, id)
+
 		notes = append(notes, NoteListItem{
 			ID:         id,
+			UID:        note.UID,
 			FolderPath: note.FolderPath,
 			Title:      note.Title,
 			Type:       note.Type,
 			Icon:       note.Icon,
 			Tags:       note.Tags,
+			Aliases:    note.Aliases,
 			Private:    note.Private,
+			Archived:   note.Archived,
 			Encrypted:  isEncrypted,
-			Created:    note.Created,
-			Modified:   note.Modified,
+			Due:        note.Due,
+			Created:    note.Created.In(loc),
+			Modified:   note.Modified.In(loc),
 		})
 
 		return nil
 	})
 
 	if err != nil {
-		c.JSON(http.StatusOK, []NoteListItem{})
-		return
+		return nil
 	}
+	return notes
 
-	c.JSON(http.StatusOK, notes)
 }
 
 // ListTags returns all unique tags used across all notes
 func (h *NoteHandler) ListTags(c *gin.Context) {
 	notesPath := h.getNotesPath(c)
-	encryptionKey := middleware.GetEncryptionKey(c)
+	encryptionKey := h.getEncryptionKey(c)
+	entries := h.noteIndex.Entries(notesPath, func(path string, data []byte) (*model.Note, error) {
+		return h.loadNoteFromBytes(data, path, encryptionKey)
+	})
 
 	tagSet := make(map[string]bool)
-
-	filepath.WalkDir(notesPath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil || d.IsDir() {
-			return nil
-		}
-
-		ext := filepath.Ext(path)
-		if ext != ".md" && ext != ".txt" && ext != ".adoc" {
-			return nil
-		}
-
-		note, err := h.loadNoteFromFile(path, encryptionKey)
-		if err != nil {
-			return nil
+	for _, entry := range entries {
+		// Only notes inside folders shared with the current user
+		if folder, _ := splitFolderPath(entry.ID); !middleware.HasSharePermission(c, folder, model.PermissionRead) {
+			continue
 		}
-
-		for _, tag := range note.Tags {
+		for _, tag := range entry.Tags {
 			tagSet[tag] = true
 		}
-
-		return nil
-	})
+	}
 
 	tags := make([]string, 0, len(tagSet))
 	for tag := range tagSet {
@@ -624,9 +909,12 @@ func (h *NoteHandler) ListTags(c *gin.Context) {
 }
 
 func (h *NoteHandler) Get(c *gin.Context) {
-	id := decodeNoteID(c.Param("id"))
+	id := h.resolveNoteID(c, decodeNoteID(c.Param("id")))
+	if h.denyNote(c, id, model.PermissionRead) {
+		return
+	}
 	notesPath := h.getNotesPath(c)
-	encryptionKey := middleware.GetEncryptionKey(c)
+	encryptionKey := h.getEncryptionKey(c)
 
 	// Try both extensions
 	var filePath string
@@ -648,6 +936,17 @@ func (h *NoteHandler) Get(c *gin.Context) {
 
 	// Set the correct ID with folder path (the decoded id from URL parameter)
 	note.ID = id
+	setNoteRevision(c, filePath)
+
+	// Accept: text/markdown (or text/plain, text/asciidoc) returns the raw body like /raw
+	c.Writer.Header().Add("Vary", "Accept")
+	if !note.Private && notModified(c, filePath) {
+		return
+	}
+	if format := c.NegotiateFormat(gin.MIMEJSON, "text/markdown", "text/plain", "text/asciidoc"); format != "" && format != gin.MIMEJSON {
+		h.writeRawNote(c, note)
+		return
+	}
 
 	// Check if private and needs password
 	if note.Private {
@@ -657,23 +956,114 @@ func (h *NoteHandler) Get(c *gin.Context) {
 		if password == "" {
 			c.JSON(http.StatusOK, gin.H{
 				"id":       note.ID,
+				"uid":      note.UID,
 				"title":    note.Title,
 				"type":     note.Type,
 				"private":  note.Private,
 				"locked":   true,
-				"created":  note.Created,
-				"modified": note.Modified,
+				"created":  note.Created.In(middleware.Location(c)),
+				"modified": note.Modified.In(middleware.Location(c)),
 			})
 			return
 		}
 
-		if !note.CheckPassword(password) {
+		if !note.Unlock(password) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
 			return
 		}
 	}
 
-	c.JSON(http.StatusOK, note)
+	h.recordView(c, note)
+
+	note.In(middleware.Location(c))
+	c.JSON(http.StatusOK, trimFields(note, requestedFields(c)))
+}
+
+// noteContentType returns the MIME type used when serving a note body directly
+func noteContentType(noteType string) string {
+	switch noteType {
+	case "txt":
+		return "text/plain; charset=utf-8"
+	case "asciidoc":
+		return "text/asciidoc; charset=utf-8"
+	default:
+		return "text/markdown; charset=utf-8"
+	}
+}
+
+// GetRaw returns the note body without frontmatter or JSON wrapper
+// Intended for curl, scripts and external tools that only need the content
+func (h *NoteHandler) GetRaw(c *gin.Context) {
+	id := h.resolveNoteID(c, decodeNoteID(c.Param("id")))
+	if h.denyNote(c, id, model.PermissionRead) {
+		return
+	}
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+
+	filePath, note := h.findNote(notesPath, id, encryptionKey)
+	if note == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+
+	if !note.Private && notModified(c, filePath) {
+		return
+	}
+
+	h.writeRawNote(c, note)
+}
+
+// notModified sets ETag and Last-Modified from a note file and responds 304 when the client's copy
+// is current (If-None-Match, or If-Modified-Since when no ETag is sent)
+// The ETag also covers the Accept header and query, which select the representation of the note
+func notModified(c *gin.Context, filePath string) bool {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return false
+	}
+
+	variant := fnv.New32a()
+	variant.Write([]byte(c.GetHeader("Accept") + "\x00" + c.Request.URL.RawQuery))
+	etag := fmt.Sprintf(`W/"%x-%x-%x"`, info.Size(), info.ModTime().UnixNano(), variant.Sum32())
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", info.ModTime().UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "private, no-cache")
+
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				c.Status(http.StatusNotModified)
+				return true
+			}
+		}
+		return false
+	}
+
+	if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil && !info.ModTime().Truncate(time.Second).After(since) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// writeRawNote responds with the note body; ?frontmatter=true includes the YAML front matter
+// Private notes require the password header, same as Get
+func (h *NoteHandler) writeRawNote(c *gin.Context, note *model.Note) {
+	if note.Private && !note.Unlock(c.GetHeader("X-Note-Password")) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		return
+	}
+
+	body := []byte(note.Content)
+	if c.Query("frontmatter") == "true" {
+		note.Password = "" // The password hash stays on the server
+		if content, err := note.ToFileContent(); err == nil {
+			body = content
+		}
+	}
+	c.Data(http.StatusOK, noteContentType(note.Type), body)
 }
 
 type CreateNoteRequest struct {
@@ -682,9 +1072,11 @@ type CreateNoteRequest struct {
 	Content     string             `json:"content"`
 	Type        string             `json:"type"`
 	Tags        []string           `json:"tags"`
+	Aliases     []string           `json:"aliases"`
 	Private     bool               `json:"private"`
 	Password    string             `json:"password"`
 	Attachments []model.Attachment `json:"attachments"`
+	Due         string             `json:"due"` // YYYY-MM-DD
 }
 
 func (h *NoteHandler) Create(c *gin.Context) {
@@ -697,12 +1089,22 @@ func (h *NoteHandler) Create(c *gin.Context) {
 	if req.Type == "" {
 		req.Type = h.config.Editor.DefaultType
 	}
+	if !validDueDate(req.Due) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid due date (YYYY-MM-DD)"})
+		return
+	}
+	req.Title = normalizeName(req.Title)
+	req.FolderPath = normalizeName(req.FolderPath)
+
+	if denyShare(c, req.FolderPath, model.PermissionWrite) {
+		return
+	}
 
 	notesPath := h.getNotesPath(c)
-	encryptionKey := middleware.GetEncryptionKey(c)
+	encryptionKey := h.getEncryptionKey(c)
 
-	// Use folder path from request directly
-	folderPath := req.FolderPath
+	// Reuse an existing folder that differs only by case
+	folderPath := canonicalFolderPath(notesPath, req.FolderPath)
 
 	if folderPath != "" {
 		// Validate folder path (prevent path traversal)
@@ -737,13 +1139,16 @@ func (h *NoteHandler) Create(c *gin.Context) {
 	now := time.Now()
 	note := &model.Note{
 		ID:          fullID,
+		UID:         id,
 		FolderPath:  folderPath,
 		Title:       req.Title,
 		Content:     req.Content,
 		Type:        req.Type,
 		Tags:        req.Tags,
+		Aliases:     normalizeAliases(req.Aliases),
 		Private:     req.Private,
 		Attachments: req.Attachments,
+		Due:         req.Due,
 		Created:     now,
 		Modified:    now,
 	}
@@ -766,11 +1171,30 @@ func (h *NoteHandler) Create(c *gin.Context) {
 	if userRepo, err := h.getUserRepo(c); err == nil {
 		userRepo.AddAndCommit(filePath, fmt.Sprintf("Create note: %s", note.Title))
 	}
+	h.noteIndex.Put(notesPath, note.UID, note.ID)
 
+	setNoteRevision(c, filePath)
+	note.In(middleware.Location(c))
 	c.JSON(http.StatusCreated, note)
 
 	// Broadcast note creation to other clients of the same user
 	h.broadcastNoteChange(c, websocket.MsgTypeNoteCreated, note.ID)
+
+	// Tell the owner of a shared folder about notes others add to it
+	if owner := middleware.GetShareOwner(c); owner != nil {
+		actor := ""
+		if user := middleware.GetCurrentUser(c); user != nil {
+			actor = user.Username
+		}
+		h.events.Publish(notify.Event{
+			Type:     notify.SharedNoteCreated,
+			Username: owner.Username,
+			Actor:    actor,
+			NoteID:   note.ID,
+			Folder:   note.FolderPath,
+			Title:    note.Title,
+		})
+	}
 }
 
 type UpdateNoteRequest struct {
@@ -780,16 +1204,23 @@ type UpdateNoteRequest struct {
 	Type        string             `json:"type"`
 	Icon        *string            `json:"icon,omitempty"`
 	Tags        []string           `json:"tags"`
+	Aliases     []string           `json:"aliases"` // nil = keep existing aliases
 	Private     bool               `json:"private"`
 	Password    *string            `json:"password"`
 	Attachments []model.Attachment `json:"attachments"`
-	Created     *time.Time         `json:"created,omitempty"`
+	Created     *string            `json:"created,omitempty"` // RFC 3339, or local time in the user's time zone
+	Due         *string            `json:"due,omitempty"`     // YYYY-MM-DD; nil = keep, "" = clear
+	// Modified time of the note the edit was based on; a newer note on disk is a conflict (nil = overwrite)
+	BaseModified *time.Time `json:"base_modified,omitempty"`
 }
 
 func (h *NoteHandler) Update(c *gin.Context) {
-	id := decodeNoteID(c.Param("id"))
+	id := h.resolveNoteID(c, decodeNoteID(c.Param("id")))
+	if h.denyNote(c, id, model.PermissionWrite) {
+		return
+	}
 	notesPath := h.getNotesPath(c)
-	encryptionKey := middleware.GetEncryptionKey(c)
+	encryptionKey := h.getEncryptionKey(c)
 
 	// Find existing note
 	var filePath string
@@ -814,20 +1245,54 @@ func (h *NoteHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.Due != nil && !validDueDate(*req.Due) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid due date (YYYY-MM-DD)"})
+		return
+	}
+	req.Title = normalizeName(req.Title)
+	req.FolderPath = canonicalFolderPath(notesPath, normalizeName(req.FolderPath))
+
+	// Moving out of a shared folder requires write permission on the target too
+	if folder, _ := splitFolderPath(id); req.FolderPath != folder && denyShare(c, req.FolderPath, model.PermissionWrite) {
+		return
+	}
 
 	// Check password for private notes (only when content is being modified)
-	// Allow folder move, date change, tags update without password
+	// Allow folder move, date change, tags update without password; the encrypted content is kept as it is
 	if note.Private {
-		contentChanged := req.Content != note.Content
-		if contentChanged {
-			password := c.GetHeader("X-Note-Password")
-			if !note.CheckPassword(password) {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
-				return
-			}
+		unlocked := note.Unlock(c.GetHeader("X-Note-Password"))
+		if !unlocked && req.Content != note.Content {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+			return
 		}
 	}
 
+	// The note was saved elsewhere (another device or tab) after the client loaded it:
+	// return both versions instead of overwriting
+	if req.BaseModified != nil && note.Modified.After(*req.BaseModified) {
+		current := *note
+		current.ID = id
+		if note.Private && !note.CheckPassword(c.GetHeader("X-Note-Password")) {
+			current.Content = ""
+		}
+		current.In(middleware.Location(c))
+		setNoteRevision(c, filePath)
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Note was changed on another device",
+			"current": current,
+			"yours": gin.H{
+				"title":   req.Title,
+				"content": req.Content,
+			},
+		})
+		return
+	}
+	// If-Match with the X-Note-Revision the client loaded: 412 when the file changed since
+	if revisionMismatch(c, filePath) {
+		return
+	}
+	draftUID := noteFileUID(filePath, note)
+
 	// Update fields
 	note.FolderPath = req.FolderPath
 	if req.Title != "" {
@@ -838,9 +1303,15 @@ func (h *NoteHandler) Update(c *gin.Context) {
 		note.Type = req.Type
 	}
 	note.Tags = req.Tags
+	if req.Aliases != nil {
+		note.Aliases = normalizeAliases(req.Aliases)
+	}
 	note.Private = req.Private
 	note.Attachments = req.Attachments
 	note.Modified = time.Now()
+	if note.UID == "" {
+		note.UID = newNoteUID(id)
+	}
 
 	// Update icon if provided
 	if req.Icon != nil {
@@ -849,12 +1320,29 @@ func (h *NoteHandler) Update(c *gin.Context) {
 
 	// Update created date if provided (for calendar drag & drop)
 	if req.Created != nil {
-		note.Created = *req.Created
+		created, err := parseUserTime(*req.Created, middleware.Location(c))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid created time"})
+			return
+		}
+		note.Created = created
+	}
+	if req.Due != nil {
+		note.Due = *req.Due
 	}
 
-	// Handle password change
+	// Handle password change; a note that is no longer private is stored as plain text again
+	// Both need the current password when the content is encrypted
+	if !note.Private && note.Password != "" {
+		noPassword := ""
+		req.Password = &noPassword
+	}
 	if req.Password != nil {
 		if err := note.SetPassword(*req.Password); err != nil {
+			if errors.Is(err, model.ErrNoteLocked) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set password"})
 			return
 		}
@@ -896,6 +1384,7 @@ func (h *NoteHandler) Update(c *gin.Context) {
 			encoding.Debug("Git commit error: %v", err)
 		}
 	}
+	h.removeDraft(c, draftUID)
 
 	// Calculate relative path from notesPath for the ID (consistent with List handler)
 	absNotesPath, _ := filepath.Abs(notesPath)
@@ -906,7 +1395,19 @@ func (h *NoteHandler) Update(c *gin.Context) {
 		// Remove extension to get ID
 		note.ID = strings.TrimSuffix(relPath, note.GetExtension())
 	}
+	h.noteIndex.Put(notesPath, note.UID, note.ID)
 
+	// Keep the short link pointing at the moved note
+	if note.ID != id && h.shortLinks != nil {
+		owner := ""
+		if user := storageOwner(c); user != nil {
+			owner = user.Username
+		}
+		h.shortLinks.MoveNote(owner, id, note.ID)
+	}
+
+	setNoteRevision(c, newFilePath)
+	note.In(middleware.Location(c))
 	c.JSON(http.StatusOK, note)
 
 	// Broadcast note update to other clients of the same user
@@ -914,9 +1415,12 @@ func (h *NoteHandler) Update(c *gin.Context) {
 }
 
 func (h *NoteHandler) Delete(c *gin.Context) {
-	id := decodeNoteID(c.Param("id"))
+	id := h.resolveNoteID(c, decodeNoteID(c.Param("id")))
+	if folder, _ := splitFolderPath(id); denyShare(c, folder, model.PermissionWrite) {
+		return
+	}
 	notesPath := h.getNotesPath(c)
-	encryptionKey := middleware.GetEncryptionKey(c)
+	encryptionKey := h.getEncryptionKey(c)
 
 	// Find existing note
 	var filePath string
@@ -945,19 +1449,27 @@ func (h *NoteHandler) Delete(c *gin.Context) {
 		}
 	}
 
-	if err := os.Remove(filePath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	// Move to trash; it is purged after trash.retention_days or restored with POST /api/trash/:id/restore
+	userPath, _ := filepath.Abs(h.getUserStoragePath(c))
+	trashID, entryDir, err := moveToTrash(userPath, notesPath, id+filepath.Ext(filePath))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move note to trash"})
 		return
 	}
 
 	// Git commit - use user-specific repo
 	if userRepo, err := h.getUserRepo(c); err == nil {
-		if err := userRepo.RemoveAndCommit(filePath, fmt.Sprintf("Delete note: %s", note.Title)); err != nil {
+		if err := userRepo.AddPathsAndCommit([]string{filePath, entryDir}, fmt.Sprintf("Delete note: %s (to trash)", note.Title)); err != nil {
 			encoding.Debug("Git commit error: %v", err)
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Note deleted"})
+	if note.UID != "" {
+		h.noteIndex.Remove(notesPath, note.UID)
+	}
+	h.removeDraft(c, noteFileUID(filePath, note))
+
+	c.JSON(http.StatusOK, gin.H{"message": "Note deleted", "trash_id": trashID})
 
 	// Broadcast note deletion to other clients of the same user
 	h.broadcastNoteChange(c, websocket.MsgTypeNoteDeleted, id)
@@ -965,11 +1477,14 @@ func (h *NoteHandler) Delete(c *gin.Context) {
 
 // broadcastNoteChange sends a WebSocket message to all clients of the current user
 func (h *NoteHandler) broadcastNoteChange(c *gin.Context, msgType string, noteID string) {
+	// Every create, update and delete is announced here; don't wait for the watcher to catch up
+	h.noteIndex.Invalidate(h.getNotesPath(c))
+
 	if h.wsHub == nil {
 		return
 	}
 	username := "default" // Used when auth is disabled
-	user := middleware.GetCurrentUser(c)
+	user := storageOwner(c)
 	if user != nil {
 		username = user.Username
 	}
@@ -981,11 +1496,14 @@ func (h *NoteHandler) broadcastNoteChange(c *gin.Context, msgType string, noteID
 
 // DecryptNote removes encryption from a note file
 func (h *NoteHandler) DecryptNote(c *gin.Context) {
-	id := c.Param("id")
+	id := h.resolveNoteID(c, c.Param("id"))
+	if folder, _ := splitFolderPath(id); denyShare(c, folder, model.PermissionWrite) {
+		return
+	}
 	notesPath := h.getNotesPath(c)
 
 	// Get encryption key from context
-	encryptionKey := middleware.GetEncryptionKey(c)
+	encryptionKey := h.getEncryptionKey(c)
 
 	var filePath string
 	var note *model.Note
@@ -1034,10 +1552,23 @@ func (h *NoteHandler) DecryptNote(c *gin.Context) {
 
 // Folder represents a directory in the note storage
 type Folder struct {
-	Name     string    `json:"name"`
-	Path     string    `json:"path"`
-	Created  time.Time `json:"created"`
-	Modified time.Time `json:"modified"`
+	Name               string    `json:"name"`
+	Path               string    `json:"path"`
+	NoteCount          int       `json:"note_count"`           // Notes directly in this folder
+	RecursiveNoteCount int       `json:"recursive_note_count"` // Notes in this folder and all subfolders
+	Size               int64     `json:"size"`                 // Total size of notes in bytes (recursive)
+	Description        string    `json:"description,omitempty"`
+	Color              string    `json:"color,omitempty"`
+	DefaultType        string    `json:"default_type,omitempty"`
+	Created            time.Time `json:"created"`
+	Modified           time.Time `json:"modified"`
+}
+
+// folderStats accumulates note counts and sizes per folder
+type folderStats struct {
+	direct    int
+	recursive int
+	size      int64
 }
 
 // ListFolders returns all folders in the user's notes directory (recursively)
@@ -1045,14 +1576,40 @@ func (h *NoteHandler) ListFolders(c *gin.Context) {
 	notesPath := h.getNotesPath(c)
 
 	var folders []Folder
+	stats := make(map[string]*folderStats)
 
 	err := filepath.WalkDir(notesPath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
 
-		// Only process directories
+		// Count note files for their folder and all ancestors
 		if !d.IsDir() {
+			ext := filepath.Ext(path)
+			if ext != ".md" && ext != ".txt" && ext != ".adoc" {
+				return nil
+			}
+			relDir, err := filepath.Rel(notesPath, filepath.Dir(path))
+			if err != nil || relDir == "." {
+				return nil
+			}
+			var size int64
+			if info, err := d.Info(); err == nil {
+				size = info.Size()
+			}
+			relDir = filepath.ToSlash(relDir)
+			for dir := relDir; dir != ""; dir, _ = splitFolderPath(dir) {
+				st := stats[dir]
+				if st == nil {
+					st = &folderStats{}
+					stats[dir] = st
+				}
+				if dir == relDir {
+					st.direct++
+				}
+				st.recursive++
+				st.size += size
+			}
 			return nil
 		}
 
@@ -1081,6 +1638,11 @@ func (h *NoteHandler) ListFolders(c *gin.Context) {
 		// Normalize path separators to forward slashes for cross-platform compatibility
 		relPath = filepath.ToSlash(relPath)
 
+		// Only folders shared with the current user
+		if !middleware.HasSharePermission(c, relPath, model.PermissionRead) {
+			return nil
+		}
+
 		folders = append(folders, Folder{
 			Name:     name,
 			Path:     relPath,
@@ -1096,6 +1658,25 @@ func (h *NoteHandler) ListFolders(c *gin.Context) {
 		return
 	}
 
+	// Folder metadata (description, color, default note type)
+	var meta map[string]FolderMeta
+	if user := storageOwner(c); user != nil && h.db != nil {
+		meta, _ = loadFolderMeta(h.db, user.ID)
+	}
+
+	for i := range folders {
+		if st := stats[folders[i].Path]; st != nil {
+			folders[i].NoteCount = st.direct
+			folders[i].RecursiveNoteCount = st.recursive
+			folders[i].Size = st.size
+		}
+		if m, ok := meta[folders[i].Path]; ok {
+			folders[i].Description = m.Description
+			folders[i].Color = m.Color
+			folders[i].DefaultType = m.DefaultType
+		}
+	}
+
 	c.JSON(http.StatusOK, folders)
 }
 
@@ -1113,7 +1694,8 @@ func (h *NoteHandler) CreateFolder(c *gin.Context) {
 	}
 
 	// Sanitize folder name
-	folderName := strings.TrimSpace(req.Name)
+	folderName := strings.TrimSpace(normalizeName(req.Name))
+	req.Path = normalizeName(req.Path)
 	if folderName == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Folder name is required"})
 		return
@@ -1125,6 +1707,10 @@ func (h *NoteHandler) CreateFolder(c *gin.Context) {
 		return
 	}
 
+	if denyShare(c, req.Path, model.PermissionWrite) {
+		return
+	}
+
 	notesPath := h.getNotesPath(c)
 
 	// Build full path
@@ -1146,6 +1732,14 @@ func (h *NoteHandler) CreateFolder(c *gin.Context) {
 		c.JSON(http.StatusConflict, gin.H{"error": "Folder already exists"})
 		return
 	}
+	relPath := folderName
+	if req.Path != "" {
+		relPath = req.Path + "/" + folderName
+	}
+	if existing := folderCollision(notesPath, relPath); existing != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": "A folder with the same name already exists", "existing": existing})
+		return
+	}
 
 	// Create folder
 	if err := os.MkdirAll(folderPath, 0755); err != nil {
@@ -1167,14 +1761,9 @@ func (h *NoteHandler) CreateFolder(c *gin.Context) {
 		}
 	}
 
-	relativePath := folderName
-	if req.Path != "" {
-		relativePath = req.Path + "/" + folderName
-	}
-
 	c.JSON(http.StatusCreated, Folder{
 		Name:     folderName,
-		Path:     relativePath,
+		Path:     relPath,
 		Created:  time.Now(),
 		Modified: time.Now(),
 	})
@@ -1182,14 +1771,21 @@ func (h *NoteHandler) CreateFolder(c *gin.Context) {
 
 // DeleteFolder deletes a folder from the user's notes directory
 func (h *NoteHandler) DeleteFolder(c *gin.Context) {
-	folderPath := c.Param("path")
-
-	// Prevent path traversal
-	if strings.Contains(folderPath, "..") {
+	// The folder is a query parameter, not part of the route, so other /folders routes can be added
+	folderPath, ok := cleanFolderPath(c.Query("path"))
+	if !ok {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
 		return
 	}
 
+	if denyShare(c, folderPath, model.PermissionManage) {
+		return
+	}
+	if middleware.RestrictedBelow(c, folderPath) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Folder contains restricted subfolders"})
+		return
+	}
+
 	notesPath := h.getNotesPath(c)
 	fullPath := filepath.Join(notesPath, folderPath)
 
@@ -1214,6 +1810,10 @@ func (h *NoteHandler) DeleteFolder(c *gin.Context) {
 
 	for _, entry := range entries {
 		if entry.Name() != ".gitkeep" {
+			if c.Query("recursive") == "true" {
+				h.deleteFolderRecursive(c, notesPath, folderPath)
+				return
+			}
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Folder is not empty"})
 			return
 		}
@@ -1236,6 +1836,378 @@ func (h *NoteHandler) DeleteFolder(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Folder deleted"})
 }
 
+// RenameFolderRequest represents the request to rename or move a folder
+type RenameFolderRequest struct {
+	OldPath string `json:"old_path" binding:"required"`
+	NewPath string `json:"new_path" binding:"required"`
+}
+
+// cleanFolderPath normalizes a folder path ("/a/b/" -> "a/b", NFC) and rejects traversal
+func cleanFolderPath(folderPath string) (string, bool) {
+	folderPath = strings.Trim(filepath.ToSlash(strings.TrimSpace(normalizeName(folderPath))), "/")
+	if folderPath == "" || strings.Contains(folderPath, "..") || strings.Contains(folderPath, "\\") {
+		return "", false
+	}
+	for _, part := range strings.Split(folderPath, "/") {
+		if part == "" || strings.HasPrefix(part, ".") {
+			return "", false
+		}
+	}
+	return folderPath, true
+}
+
+// CleanFolderPath normalizes a folder path for callers outside the handlers, such as the Telegram bot
+func CleanFolderPath(folderPath string) (string, bool) {
+	return cleanFolderPath(folderPath)
+}
+
+// RenameFolder renames or moves a folder, updating contained notes and folder metadata
+func (h *NoteHandler) RenameFolder(c *gin.Context) {
+	var req RenameFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	oldPath, ok := cleanFolderPath(req.OldPath)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+		return
+	}
+	newPath, ok := cleanFolderPath(req.NewPath)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+		return
+	}
+	if oldPath == newPath {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Folder path unchanged"})
+		return
+	}
+	if strings.HasPrefix(newPath, oldPath+"/") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot move a folder into itself"})
+		return
+	}
+	if denyShare(c, oldPath, model.PermissionManage) || denyShare(c, newPath, model.PermissionManage) {
+		return
+	}
+	if middleware.RestrictedBelow(c, oldPath) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Folder contains restricted subfolders"})
+		return
+	}
+
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+
+	// Move into an existing parent folder that differs only by case
+	if parent, name := splitFolderPath(newPath); parent != "" {
+		newPath = canonicalFolderPath(notesPath, parent) + "/" + name
+	}
+	oldFull := filepath.Join(notesPath, filepath.FromSlash(oldPath))
+	newFull := filepath.Join(notesPath, filepath.FromSlash(newPath))
+
+	// Check if folder exists
+	info, err := os.Stat(oldFull)
+	if os.IsNotExist(err) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+		return
+	}
+	if err != nil || !info.IsDir() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Not a folder"})
+		return
+	}
+	if _, err := os.Stat(newFull); err == nil && !strings.EqualFold(oldPath, newPath) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Folder already exists"})
+		return
+	}
+	if existing := folderCollision(notesPath, newPath); existing != "" && existing != oldPath {
+		c.JSON(http.StatusConflict, gin.H{"error": "A folder with the same name already exists", "existing": existing})
+		return
+	}
+
+	// Create target parent and move folder
+	if err := os.MkdirAll(filepath.Dir(newFull), 0755); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create folder"})
+		return
+	}
+	if err := os.Rename(oldFull, newFull); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rename folder"})
+		return
+	}
+
+	// Update folder_path (and folder prefix in title) of contained notes
+	oldTitlePrefix := strings.ReplaceAll(oldPath, "/", FolderSeparator) + FolderSeparator
+	newTitlePrefix := strings.ReplaceAll(newPath, "/", FolderSeparator) + FolderSeparator
+	updated, skipped := 0, 0
+
+	filepath.WalkDir(newFull, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		ext := filepath.Ext(path)
+		if ext != ".md" && ext != ".txt" && ext != ".adoc" {
+			return nil
+		}
+
+		note, err := h.loadNoteFromFile(path, encryptionKey)
+		if err != nil {
+			// Encrypted without key or unreadable; folder_path will be stale until next save
+			skipped++
+			return nil
+		}
+
+		relDir, err := filepath.Rel(notesPath, filepath.Dir(path))
+		if err != nil {
+			return nil
+		}
+		note.FolderPath = filepath.ToSlash(relDir)
+		if strings.HasPrefix(note.Title, oldTitlePrefix) {
+			note.Title = newTitlePrefix + strings.TrimPrefix(note.Title, oldTitlePrefix)
+		}
+
+		if err := h.saveNoteToFile(note, path, encryptionKey); err != nil {
+			skipped++
+			return nil
+		}
+		updated++
+		return nil
+	})
+
+	// Git commit
+	if userRepo, err := h.getUserRepo(c); err == nil {
+		if err := userRepo.AddPathsAndCommit([]string{oldFull, newFull}, fmt.Sprintf("Move folder: %s -> %s", oldPath, newPath)); err != nil {
+			encoding.Debug("Git commit error: %v", err)
+		}
+	}
+
+	// Update folder icons, order, metadata, shares, folder rules and short links
+	username := ""
+	if user := storageOwner(c); user != nil {
+		username = user.Username
+		if h.db != nil {
+			if err := renameFolderIcons(h.db, user.ID, oldPath, newPath); err != nil {
+				encoding.Warn("Failed to update folder icons for %s: %v", oldPath, err)
+			}
+			if err := renameFolderOrder(h.db, user.ID, oldPath, newPath); err != nil {
+				encoding.Warn("Failed to update folder order for %s: %v", oldPath, err)
+			}
+			if err := renameNoteSort(h.db, user.ID, oldPath, newPath); err != nil {
+				encoding.Warn("Failed to update note sort for %s: %v", oldPath, err)
+			}
+			if err := renameFolderMeta(h.db, user.ID, oldPath, newPath); err != nil {
+				encoding.Warn("Failed to update folder metadata for %s: %v", oldPath, err)
+			}
+			if err := h.shares.RenamePath(user.ID, oldPath, newPath); err != nil {
+				encoding.Warn("Failed to update folder shares for %s: %v", oldPath, err)
+			}
+			if err := h.folderRules.RenamePath(user.ID, oldPath, newPath); err != nil {
+				encoding.Warn("Failed to update folder rules for %s: %v", oldPath, err)
+			}
+		}
+	}
+	if h.shortLinks != nil {
+		h.shortLinks.RenameFolder(username, oldPath, newPath)
+	}
+
+	_, name := splitFolderPath(newPath)
+	c.JSON(http.StatusOK, gin.H{
+		"name":          name,
+		"path":          newPath,
+		"updated_notes": updated,
+		"skipped_notes": skipped,
+	})
+
+	// Note IDs under the folder changed; ask clients to reload
+	h.broadcastNoteChange(c, websocket.MsgTypeNotesRefresh, "")
+}
+
+// deleteFolderRecursive removes a non-empty folder in one git commit
+// The first request returns a confirmation token; the second must pass it as ?confirm=
+// Notes are moved to trash, or flattened into ?move_to= folder when given
+func (h *NoteHandler) deleteFolderRecursive(c *gin.Context, notesPath, folderPath string) {
+	cleanPath, ok := cleanFolderPath(folderPath)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+		return
+	}
+	fullPath := filepath.Join(notesPath, filepath.FromSlash(cleanPath))
+
+	username := ""
+	user := storageOwner(c)
+	if user != nil {
+		username = user.Username
+	}
+
+	// Collect contained notes
+	var noteFiles []string
+	filepath.WalkDir(fullPath, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			ext := filepath.Ext(path)
+			if ext == ".md" || ext == ".txt" || ext == ".adoc" {
+				noteFiles = append(noteFiles, path)
+			}
+		}
+		return nil
+	})
+
+	moveTo := ""
+	if c.Query("move_to") != "" {
+		target, ok := cleanFolderPath(c.Query("move_to"))
+		if !ok || target == cleanPath || strings.HasPrefix(target, cleanPath+"/") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target folder"})
+			return
+		}
+		moveTo = target
+	}
+
+	// Require confirmation token bound to this user and folder
+	token := c.Query("confirm")
+	if token == "" || !folderDeleteTokens.Consume(token, username, cleanPath) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":         "Confirmation required",
+			"confirm_token": folderDeleteTokens.Issue(username, cleanPath),
+			"note_count":    len(noteFiles),
+			"expires_in":    int(confirmTokenTTL.Seconds()),
+		})
+		return
+	}
+
+	userPath := h.getUserStoragePath(c)
+	changedPaths := []string{fullPath}
+	moved, skipped := 0, 0
+	trashID := ""
+
+	if moveTo != "" {
+		// Flatten notes into the target folder, then remove the tree
+		encryptionKey := h.getEncryptionKey(c)
+		targetFull := filepath.Join(notesPath, filepath.FromSlash(moveTo))
+		if err := os.MkdirAll(targetFull, 0755); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create folder"})
+			return
+		}
+		changedPaths = append(changedPaths, targetFull)
+		targetPrefix := strings.ReplaceAll(moveTo, "/", FolderSeparator) + FolderSeparator
+
+		for _, src := range noteFiles {
+			dst := filepath.Join(targetFull, filepath.Base(src))
+			if _, err := os.Stat(dst); err == nil {
+				skipped++
+				continue
+			}
+			if err := os.Rename(src, dst); err != nil {
+				skipped++
+				continue
+			}
+			moved++
+
+			// Update folder_path and title prefix (encrypted notes without key keep stale metadata)
+			note, err := h.loadNoteFromFile(dst, encryptionKey)
+			if err != nil {
+				continue
+			}
+			_, name := extractFolderPath(note.Title)
+			note.Title = targetPrefix + name
+			note.FolderPath = moveTo
+			h.saveNoteToFile(note, dst, encryptionKey)
+		}
+
+		if skipped > 0 {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":       "Some notes could not be moved; folder kept",
+				"moved_notes": moved,
+			})
+			if userRepo, err := h.getUserRepo(c); err == nil {
+				userRepo.AddPathsAndCommit(changedPaths, fmt.Sprintf("Move notes from folder: %s -> %s", cleanPath, moveTo))
+			}
+			return
+		}
+
+		if err := os.RemoveAll(fullPath); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete folder"})
+			return
+		}
+	} else {
+		// Move the whole tree to trash
+		id, dst, err := moveToTrash(userPath, notesPath, cleanPath)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move folder to trash"})
+			return
+		}
+		trashID = id
+		moved = len(noteFiles)
+		changedPaths = append(changedPaths, dst)
+	}
+
+	// Git commit (single commit for the whole tree)
+	if userRepo, err := h.getUserRepo(c); err == nil {
+		msg := fmt.Sprintf("Delete folder: %s (%d notes to trash)", cleanPath, moved)
+		if moveTo != "" {
+			msg = fmt.Sprintf("Delete folder: %s (%d notes moved to %s)", cleanPath, moved, moveTo)
+		}
+		if err := userRepo.AddPathsAndCommit(changedPaths, msg); err != nil {
+			encoding.Debug("Git commit error: %v", err)
+		}
+	}
+
+	// Remove folder icons, order, metadata, shares and folder rules of the deleted tree
+	if user != nil && h.db != nil {
+		if err := deleteFolderIcons(h.db, user.ID, cleanPath); err != nil {
+			encoding.Warn("Failed to delete folder icons for %s: %v", cleanPath, err)
+		}
+		if err := deleteFolderOrder(h.db, user.ID, cleanPath); err != nil {
+			encoding.Warn("Failed to delete folder order for %s: %v", cleanPath, err)
+		}
+		if err := deleteNoteSort(h.db, user.ID, cleanPath); err != nil {
+			encoding.Warn("Failed to delete note sort for %s: %v", cleanPath, err)
+		}
+		if err := deleteFolderMeta(h.db, user.ID, cleanPath); err != nil {
+			encoding.Warn("Failed to delete folder metadata for %s: %v", cleanPath, err)
+		}
+		if err := h.shares.DeletePath(user.ID, cleanPath); err != nil {
+			encoding.Warn("Failed to delete folder shares for %s: %v", cleanPath, err)
+		}
+		if err := h.folderRules.DeletePath(user.ID, cleanPath); err != nil {
+			encoding.Warn("Failed to delete folder rules for %s: %v", cleanPath, err)
+		}
+	}
+
+	encoding.Info("Folder deleted recursively: user=%s, path=%s, notes=%d, move_to=%s, trash=%s", username, cleanPath, moved, moveTo, trashID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Folder deleted",
+		"moved_notes": moved,
+		"move_to":     moveTo,
+		"trash_id":    trashID,
+	})
+
+	h.broadcastNoteChange(c, websocket.MsgTypeNotesRefresh, "")
+}
+
+// normalizeAliases trims aliases and drops empty or duplicate entries
+func normalizeAliases(aliases []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(aliases))
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(normalizeName(alias))
+		key := strings.ToLower(alias)
+		if alias == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, alias)
+	}
+	return result
+}
+
 func generateID() string {
 	return uuid.New().String()
 }
+
+// newNoteUID returns the UID for a note without one: the filename if it is already a UUID
+// (so existing links keep working), otherwise a new UUID
+func newNoteUID(id string) string {
+	base := filepath.Base(id)
+	if _, err := uuid.Parse(base); err == nil {
+		return base
+	}
+	return generateID()
+}
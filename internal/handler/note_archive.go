@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/websocket"
+)
+
+// Archive hides a note from the note list and calendar without deleting it
+func (h *NoteHandler) Archive(c *gin.Context) {
+	h.setArchived(c, true)
+}
+
+// Unarchive brings an archived note back to the note list
+func (h *NoteHandler) Unarchive(c *gin.Context) {
+	h.setArchived(c, false)
+}
+
+// setArchived updates the archived flag of a note and commits the change
+// Like moving a note, this does not need the password of a private note
+func (h *NoteHandler) setArchived(c *gin.Context, archived bool) {
+	id := h.resolveNoteID(c, decodeNoteID(c.Param("id")))
+	if folder, _ := splitFolderPath(id); denyShare(c, folder, model.PermissionWrite) {
+		return
+	}
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+
+	filePath, note := h.findNote(notesPath, id, encryptionKey)
+	if note == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+
+	if note.Archived != archived {
+		note.Archived = archived
+		if note.UID == "" {
+			note.UID = newNoteUID(id)
+		}
+		if err := h.saveNoteToFile(note, filePath, encryptionKey); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update note"})
+			return
+		}
+
+		action := "Archive"
+		if !archived {
+			action = "Unarchive"
+		}
+		if userRepo, err := h.getUserRepo(c); err == nil {
+			if err := userRepo.AddAndCommit(filePath, fmt.Sprintf("%s note: %s", action, note.Title)); err != nil {
+				encoding.Debug("Git commit error: %v", err)
+			}
+		}
+
+		h.broadcastNoteChange(c, websocket.MsgTypeNoteUpdated, id)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "archived": archived})
+}
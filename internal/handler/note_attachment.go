@@ -0,0 +1,350 @@
+package handler
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/websocket"
+	"golang.org/x/text/unicode/norm"
+)
+
+// RenameAttachmentRequest sets the display name of an uploaded file or image
+type RenameAttachmentRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// RenameAttachment changes the display (download) name of an uploaded file or image
+// The name is updated in .filemeta.json/.imagemeta.json and in the attachment entries of every note
+// referencing the file; the stored UUID filename and URLs stay the same
+func (h *NoteHandler) RenameAttachment(c *gin.Context) {
+	filename := c.Param("filename")
+
+	// Security: prevent path traversal
+	if strings.Contains(filename, "..") || strings.Contains(filename, "/") || strings.Contains(filename, "\\") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filename"})
+		return
+	}
+
+	if !middleware.HasSharePermission(c, c.Query("folder_path"), model.PermissionWrite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission for shared folder"})
+		return
+	}
+
+	var req RenameAttachmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name := norm.NFC.String(strings.TrimSpace(req.Name))
+	if name == "" || strings.ContainsAny(name, "/\\") || strings.ContainsFunc(name, unicode.IsControl) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid name"})
+		return
+	}
+
+	user := storageOwner(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if _, err := os.Stat(filepath.Join(h.basePath, user.Username, "files", filename)); os.IsNotExist(err) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+
+	// Images and other files keep their names in separate metadata files
+	images := &ImageHandler{storagePath: h.basePath}
+	files := &FileHandler{storagePath: h.basePath}
+	oldName := images.getOriginalName(user.Username, filename)
+	var err error
+	if oldName != "" {
+		err = images.saveMetadata(user.Username, filename, name)
+	} else {
+		oldName = files.getOriginalName(user.Username, filename)
+		err = files.saveMetadata(user.Username, filename, name)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file name"})
+		return
+	}
+
+	// Rename the attachment entries of the notes referencing the file
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+	var changed []string
+	skipped := 0
+
+	filepath.WalkDir(notesPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(path)
+		if ext != ".md" && ext != ".txt" && ext != ".adoc" {
+			return nil
+		}
+
+		note, err := h.loadNoteFromFile(path, encryptionKey)
+		if err != nil {
+			skipped++ // Encrypted without key or unreadable
+			return nil
+		}
+
+		found := false
+		for i, att := range note.Attachments {
+			if extractUUIDFromURL(att.URL) == filename && att.Name != name {
+				note.Attachments[i].Name = name
+				found = true
+			}
+		}
+		if !found {
+			return nil
+		}
+
+		if err := h.saveNoteToFile(note, path, encryptionKey); err != nil {
+			skipped++
+			return nil
+		}
+		changed = append(changed, path)
+		return nil
+	})
+
+	if len(changed) > 0 {
+		if userRepo, err := h.getUserRepo(c); err == nil {
+			if err := userRepo.AddPathsAndCommit(changed, fmt.Sprintf("Rename attachment: %s -> %s", oldName, name)); err != nil {
+				encoding.Debug("Git commit error: %v", err)
+			}
+		}
+		h.broadcastNoteChange(c, websocket.MsgTypeNotesRefresh, "")
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"filename":      filename,
+		"originalName":  name,
+		"updated_notes": len(changed),
+		"skipped_notes": skipped,
+	})
+}
+
+// AttachFileRequest adds an uploaded file or image to the attachments of a note
+type AttachFileRequest struct {
+	Filename string `json:"filename" binding:"required"` // UUID filename from POST /api/files or /api/images
+}
+
+// attachmentNote finds the note of an attachments request, responding with an error if it can't be used
+// Private notes require the password header, same as Get
+func (h *NoteHandler) attachmentNote(c *gin.Context, required model.SharePermission) (string, string, *model.Note, bool) {
+	id := h.resolveNoteID(c, decodeNoteID(c.Param("id")))
+	if h.denyNote(c, id, required) {
+		return "", "", nil, false
+	}
+
+	filePath, note := h.findNote(h.getNotesPath(c), id, h.getEncryptionKey(c))
+	if note == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return "", "", nil, false
+	}
+	if note.Private && !note.CheckPassword(c.GetHeader("X-Note-Password")) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		return "", "", nil, false
+	}
+	return id, filePath, note, true
+}
+
+// saveAttachments writes a note whose attachments changed, commits it and tells other clients
+func (h *NoteHandler) saveAttachments(c *gin.Context, id, filePath string, note *model.Note, message string) bool {
+	if err := h.saveNoteToFile(note, filePath, h.getEncryptionKey(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update note"})
+		return false
+	}
+	if userRepo, err := h.getUserRepo(c); err == nil {
+		if err := userRepo.AddAndCommit(filePath, message); err != nil {
+			encoding.Debug("Git commit error: %v", err)
+		}
+	}
+	h.broadcastNoteChange(c, websocket.MsgTypeNoteUpdated, id)
+	return true
+}
+
+// ListAttachments returns the attachments in the front matter of a note
+func (h *NoteHandler) ListAttachments(c *gin.Context) {
+	_, _, note, ok := h.attachmentNote(c, model.PermissionRead)
+	if !ok {
+		return
+	}
+
+	attachments := note.Attachments
+	if attachments == nil {
+		attachments = []model.Attachment{}
+	}
+	c.JSON(http.StatusOK, attachments)
+}
+
+// AttachFile adds an already uploaded file or image of the note's owner to the note's attachments
+// Attaching a file the note already has returns its existing entry
+func (h *NoteHandler) AttachFile(c *gin.Context) {
+	var req AttachFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filename := req.Filename
+	if strings.Contains(filename, "..") || strings.Contains(filename, "/") || strings.Contains(filename, "\\") || strings.HasPrefix(filename, ".") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filename"})
+		return
+	}
+
+	id, filePath, note, ok := h.attachmentNote(c, model.PermissionWrite)
+	if !ok {
+		return
+	}
+	owner := storageOwner(c)
+	if owner == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	for _, att := range note.Attachments {
+		if extractUUIDFromURL(att.URL) == filename {
+			c.JSON(http.StatusOK, att)
+			return
+		}
+	}
+
+	info, err := os.Stat(filepath.Join(h.basePath, owner.Username, "files", filename))
+	if err != nil || info.IsDir() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+
+	// Images and other files keep their names in separate metadata files
+	attachment := model.Attachment{
+		URL:  fmt.Sprintf("%s/u/%s/files/%s", h.config.Server.BasePath, owner.Username, filename),
+		Size: info.Size(),
+	}
+	attachment.Type, _, _ = strings.Cut(attachmentContentType(filename), ";")
+	if name := (&ImageHandler{storagePath: h.basePath}).getOriginalName(owner.Username, filename); name != "" {
+		attachment.Name = name
+		attachment.IsImage = true
+	} else {
+		attachment.Name = (&FileHandler{storagePath: h.basePath}).getOriginalName(owner.Username, filename)
+		attachment.IsImage = strings.HasPrefix(attachment.Type, "image/")
+	}
+	if attachment.Name == "" {
+		attachment.Name = filename
+	}
+
+	note.Attachments = append(note.Attachments, attachment)
+	if !h.saveAttachments(c, id, filePath, note, fmt.Sprintf("Attach file: %s -> %s", attachment.Name, note.Title)) {
+		return
+	}
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// DetachFile removes a file from the attachments of a note
+// With ?delete_file=true the uploaded file is deleted as well, unless another note (or the note's content)
+// still references it, or notes that can't be read (encrypted without the key) might
+func (h *NoteHandler) DetachFile(c *gin.Context) {
+	filename := c.Param("filename")
+	if strings.Contains(filename, "..") || strings.Contains(filename, "/") || strings.Contains(filename, "\\") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filename"})
+		return
+	}
+
+	id, filePath, note, ok := h.attachmentNote(c, model.PermissionWrite)
+	if !ok {
+		return
+	}
+
+	kept := make([]model.Attachment, 0, len(note.Attachments))
+	name := ""
+	for _, att := range note.Attachments {
+		if extractUUIDFromURL(att.URL) == filename {
+			name = att.Name
+			continue
+		}
+		kept = append(kept, att)
+	}
+	if len(kept) == len(note.Attachments) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+		return
+	}
+
+	note.Attachments = kept
+	if !h.saveAttachments(c, id, filePath, note, fmt.Sprintf("Detach file: %s <- %s", name, note.Title)) {
+		return
+	}
+
+	fileDeleted := false
+	references, skipped := 0, 0
+	if c.Query("delete_file") == "true" {
+		owner := storageOwner(c)
+		references, skipped = h.attachmentReferences(h.getNotesPath(c), filename, h.getEncryptionKey(c))
+		if owner != nil && references == 0 && skipped == 0 {
+			if err := removeUploadedFile(h.basePath, owner.Username, filename); err == nil {
+				fileDeleted = true
+			} else if !os.IsNotExist(err) {
+				encoding.Warn("Failed to delete detached file: user=%s, file=%s, error=%v", owner.Username, filename, err)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attachments":   kept,
+		"file_deleted":  fileDeleted,
+		"references":    references,
+		"skipped_notes": skipped,
+	})
+}
+
+// attachmentReferences counts the notes whose attachments or content reference an uploaded file,
+// and the notes that couldn't be read (encrypted without the key, or with a note password)
+func (h *NoteHandler) attachmentReferences(notesPath, filename string, encryptionKey []byte) (references, skipped int) {
+	filepath.WalkDir(notesPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(path)
+		if ext != ".md" && ext != ".txt" && ext != ".adoc" {
+			return nil
+		}
+
+		note, err := h.loadNoteFromFile(path, encryptionKey)
+		if err != nil || note.ContentLocked() {
+			skipped++
+			return nil
+		}
+		if strings.Contains(note.Content, filename) {
+			references++
+			return nil
+		}
+		for _, att := range note.Attachments {
+			if extractUUIDFromURL(att.URL) == filename {
+				references++
+				break
+			}
+		}
+		return nil
+	})
+	return references, skipped
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/database"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/encryption"
+	"github.com/user/gitnotepad/internal/index"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/repository"
+)
+
+// BacklinkItem is a note referencing another note with a wiki-link
+type BacklinkItem struct {
+	ID         string    `json:"id"`
+	UID        string    `json:"uid"`
+	Title      string    `json:"title"`
+	FolderPath string    `json:"folder_path"`
+	Modified   time.Time `json:"modified"`
+}
+
+// noteLinkKey normalizes a wiki-link target or note name for matching ([[Title]] matches case-insensitively)
+func noteLinkKey(name string) string {
+	return strings.ToLower(normalizeName(strings.TrimSpace(name)))
+}
+
+// noteLinkTargets returns the normalized wiki-link targets of a note
+func noteLinkTargets(note *model.Note) []string {
+	links := note.WikiLinks()
+	targets := make([]string, 0, len(links))
+	for _, link := range links {
+		targets = append(targets, noteLinkKey(link))
+	}
+	return targets
+}
+
+// noteFileUID returns the UID of a note, falling back to the file name like the metadata index
+func noteFileUID(path string, note *model.Note) string {
+	if note.UID != "" {
+		return note.UID
+	}
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// pathOwner returns the user directory a note file belongs to (first segment below the storage path)
+func (h *NoteHandler) pathOwner(path string) string {
+	basePath, _ := filepath.Abs(h.basePath)
+	absPath, _ := filepath.Abs(path)
+	rel, err := filepath.Rel(basePath, absPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]
+}
+
+// recordNoteLinks stores the wiki-links of a saved note in the link graph
+func (h *NoteHandler) recordNoteLinks(path string, note *model.Note) {
+	if h.noteLinks == nil {
+		return
+	}
+	if err := h.noteLinks.SetLinks(h.pathOwner(path), noteFileUID(path, note), noteLinkTargets(note)); err != nil {
+		encoding.Warn("Failed to save links of %s: %v", note.ID, err)
+	}
+}
+
+// Backlinks returns the notes linking to a note with [[title]], [[alias]] or [[note-ID]]
+// Notes in the trash and private notes are left out
+func (h *NoteHandler) Backlinks(c *gin.Context) {
+	id := h.resolveNoteID(c, decodeNoteID(c.Param("id")))
+	if folder, _ := splitFolderPath(id); denyShare(c, folder, model.PermissionRead) {
+		return
+	}
+
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+	entries := h.noteIndex.Entries(notesPath, func(path string, data []byte) (*model.Note, error) {
+		return h.loadNoteFromBytes(data, path, encryptionKey)
+	})
+
+	var target *index.Entry
+	byUID := make(map[string]*index.Entry, len(entries))
+	for i := range entries {
+		if entries[i].ID == id {
+			target = &entries[i]
+		}
+		byUID[entries[i].UID] = &entries[i]
+	}
+	if target == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+
+	items := []BacklinkItem{}
+	if h.noteLinks == nil {
+		c.JSON(http.StatusOK, gin.H{"backlinks": items})
+		return
+	}
+
+	names := []string{noteLinkKey(target.ID), noteLinkKey(target.UID)}
+	if target.Title != "" {
+		names = append(names, noteLinkKey(target.Title))
+	}
+	for _, alias := range target.Aliases {
+		names = append(names, noteLinkKey(alias))
+	}
+
+	sources, err := h.noteLinks.Sources(h.pathOwner(notesPath), names)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load backlinks"})
+		return
+	}
+
+	for _, uid := range sources {
+		entry, ok := byUID[uid]
+		if !ok || entry.ID == target.ID || entry.Private {
+			continue
+		}
+		if folder, _ := splitFolderPath(entry.ID); !middleware.HasSharePermission(c, folder, model.PermissionRead) {
+			continue
+		}
+		items = append(items, BacklinkItem{
+			ID:         entry.ID,
+			UID:        entry.UID,
+			Title:      entry.Title,
+			FolderPath: entry.FolderPath,
+			Modified:   entry.Modified,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Title < items[j].Title
+	})
+
+	c.JSON(http.StatusOK, gin.H{"backlinks": items})
+}
+
+// MigrateNoteLinks fills the link graph from the existing notes when it is still empty
+// Encrypted notes cannot be read at startup; their links are recorded the next time they are saved
+func MigrateNoteLinks(db *database.DB, storagePath string) error {
+	links := repository.NewNoteLinkRepository(db.DB)
+	if count, err := links.Count(); err != nil || count > 0 {
+		return err
+	}
+
+	entries, err := os.ReadDir(storagePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	migratedCount := 0
+
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		username := entry.Name()
+		notesDir := filepath.Join(storagePath, username, "notes")
+		if _, err := os.Stat(notesDir); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.WalkDir(notesDir, func(path string, d fs.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			ext := filepath.Ext(path)
+			if ext != ".md" && ext != ".txt" && ext != ".adoc" {
+				return nil
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil || encryption.IsEncrypted(string(data)) {
+				return nil
+			}
+
+			note, err := model.ParseNoteFromBytes(data, path)
+			if err != nil {
+				return nil
+			}
+			targets := noteLinkTargets(note)
+			if len(targets) == 0 {
+				return nil
+			}
+
+			if err := links.SetLinks(username, noteFileUID(path, note), targets); err != nil {
+				encoding.Warn("Failed to save links of %s: %v", path, err)
+				return nil
+			}
+			migratedCount++
+			return nil
+		})
+		if err != nil {
+			encoding.Warn("Error walking %s: %v", notesDir, err)
+		}
+	}
+
+	if migratedCount > 0 {
+		encoding.Info("Note link migration: recorded links of %d notes", migratedCount)
+	}
+
+	return nil
+}
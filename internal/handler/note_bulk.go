@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// maxBulkNotes limits the notes returned by one bulk request
+const maxBulkNotes = 200
+
+// Bulk returns several notes in one response, so a client that learned from /api/sync which notes
+// changed can fetch them without a request per note (the response is gzip-compressed as a whole)
+// Query: ids (comma-separated, each like the :id of /api/notes/:id), fields (JSON keys to keep)
+// Private notes are returned locked, without their content.
+func (h *NoteHandler) Bulk(c *gin.Context) {
+	var ids []string
+	for _, id := range strings.Split(c.Query("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Note IDs required"})
+		return
+	}
+	if len(ids) > maxBulkNotes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Too many notes: max %d", maxBulkNotes)})
+		return
+	}
+
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+	loc := middleware.Location(c)
+
+	notes := []interface{}{}
+	missing := []string{}
+	for _, rawID := range ids {
+		id := h.resolveNoteID(c, decodeNoteID(rawID))
+		folder, _ := splitFolderPath(id)
+		if !middleware.HasSharePermission(c, folder, model.PermissionRead) {
+			missing = append(missing, rawID)
+			continue
+		}
+		_, note := h.findNote(notesPath, id, encryptionKey)
+		if note == nil {
+			missing = append(missing, rawID)
+			continue
+		}
+		note.ID = id
+		note.In(loc)
+
+		if note.Private {
+			notes = append(notes, gin.H{
+				"id":       note.ID,
+				"uid":      note.UID,
+				"title":    note.Title,
+				"type":     note.Type,
+				"private":  note.Private,
+				"locked":   true,
+				"created":  note.Created,
+				"modified": note.Modified,
+			})
+			continue
+		}
+		notes = append(notes, note)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notes":   trimFields(notes, requestedFields(c)),
+		"missing": missing,
+	})
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+const calendarDateLayout = "2006-01-02"
+
+// userTimeLayouts are the accepted forms of times without a UTC offset, read in the user's time zone
+var userTimeLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04", calendarDateLayout}
+
+// CalendarDay holds the notes created or due on a day
+type CalendarDay struct {
+	Created []NoteListItem `json:"created"`
+	Due     []NoteListItem `json:"due,omitempty"`
+}
+
+// validDueDate reports whether due is empty or a YYYY-MM-DD date
+func validDueDate(due string) bool {
+	if due == "" {
+		return true
+	}
+	_, err := time.Parse(calendarDateLayout, due)
+	return err == nil
+}
+
+// parseUserTime parses an RFC 3339 time, or a local time in the user's time zone
+func parseUserTime(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	var err error
+	for _, layout := range userTimeLayouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// Calendar returns notes bucketed by day for a date range, served from the metadata index
+// Query: from, to (YYYY-MM-DD, inclusive, at most 366 days), due=true to also bucket by due date
+// Days follow the user's time zone
+func (h *NoteHandler) Calendar(c *gin.Context) {
+	loc := middleware.Location(c)
+	from, errFrom := time.ParseInLocation(calendarDateLayout, c.Query("from"), loc)
+	to, errTo := time.ParseInLocation(calendarDateLayout, c.Query("to"), loc)
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to are required (YYYY-MM-DD)"})
+		return
+	}
+	if to.Before(from) || to.Sub(from) > 366*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date range (at most 366 days)"})
+		return
+	}
+	end := to.AddDate(0, 0, 1)
+	withDue := c.Query("due") == "true"
+
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+	entries := h.noteIndex.Entries(notesPath, func(path string, data []byte) (*model.Note, error) {
+		return h.loadNoteFromBytes(data, path, encryptionKey)
+	})
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Created.Before(entries[j].Created)
+	})
+
+	days := make(map[string]*CalendarDay)
+	day := func(date string) *CalendarDay {
+		d, ok := days[date]
+		if !ok {
+			d = &CalendarDay{Created: []NoteListItem{}}
+			days[date] = d
+		}
+		return d
+	}
+
+	for _, entry := range entries {
+		if entry.Archived {
+			continue
+		}
+		if folder, _ := splitFolderPath(entry.ID); !middleware.HasSharePermission(c, folder, model.PermissionRead) {
+			continue
+		}
+
+		created := entry.Created.In(loc)
+		if !created.Before(from) && created.Before(end) {
+			d := day(created.Format(calendarDateLayout))
+			d.Created = append(d.Created, noteListItemFromEntry(entry, loc))
+		}
+
+		if withDue && entry.Due != "" {
+			// Dates compare as strings in YYYY-MM-DD
+			if entry.Due >= c.Query("from") && entry.Due <= c.Query("to") {
+				d := day(entry.Due)
+				d.Due = append(d.Due, noteListItemFromEntry(entry, loc))
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from": c.Query("from"),
+		"to":   c.Query("to"),
+		"days": days,
+	})
+}
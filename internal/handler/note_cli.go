@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/user/gitnotepad/internal/config"
+	"github.com/user/gitnotepad/internal/database"
+	"github.com/user/gitnotepad/internal/git"
+	"github.com/user/gitnotepad/internal/index"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// StorageNotes reads and writes a user's notes directly in storage, for the command line while the server is not running
+type StorageNotes struct {
+	h             *NoteHandler
+	userPath      string
+	notesPath     string
+	encryptionKey []byte
+}
+
+// NewStorageNotes opens the notes of a user, or the shared storage root when username is empty (authentication disabled)
+// encryptionKey is the user's derived key when encryption is enabled
+func NewStorageNotes(cfg *config.Config, db *database.DB, username string, encryptionKey []byte) *StorageNotes {
+	h := NewNoteHandler(nil, cfg, nil, db, nil, index.New(), nil)
+	userPath := h.basePath
+	if username != "" {
+		userPath = filepath.Join(h.basePath, username)
+	}
+	notesPath := filepath.Join(userPath, "notes")
+	os.MkdirAll(notesPath, 0755)
+	h.migrateExistingNotes(userPath, notesPath)
+
+	return &StorageNotes{h: h, userPath: userPath, notesPath: notesPath, encryptionKey: encryptionKey}
+}
+
+// Add creates a note and commits it, the same way the web UI does
+func (o *StorageNotes) Add(title, folder, content, noteType string, tags []string) (*model.Note, error) {
+	if noteType == "" {
+		noteType = o.h.config.Editor.DefaultType
+	}
+	folderPath := ""
+	if strings.Trim(folder, "/ ") != "" {
+		var ok bool
+		if folderPath, ok = cleanFolderPath(normalizeName(folder)); !ok {
+			return nil, fmt.Errorf("invalid folder path: %s", folder)
+		}
+		folderPath = canonicalFolderPath(o.notesPath, folderPath)
+	}
+
+	targetDir := filepath.Join(o.notesPath, folderPath)
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create folder: %w", err)
+	}
+
+	id := generateID()
+	fullID := id
+	if folderPath != "" {
+		fullID = folderPath + "/" + id
+	}
+	now := time.Now()
+	note := &model.Note{
+		ID:         fullID,
+		UID:        id,
+		FolderPath: folderPath,
+		Title:      normalizeName(title),
+		Content:    content,
+		Type:       noteType,
+		Tags:       tags,
+		Created:    now,
+		Modified:   now,
+	}
+
+	filePath, _ := filepath.Abs(filepath.Join(targetDir, id+note.GetExtension()))
+	if err := o.h.saveNoteToFile(note, filePath, o.encryptionKey); err != nil {
+		return nil, fmt.Errorf("failed to save note: %w", err)
+	}
+
+	repo, err := git.NewRepository(o.userPath)
+	if err == nil {
+		err = repo.Init()
+	}
+	if err == nil {
+		err = repo.AddAndCommit(filePath, fmt.Sprintf("Create note: %s", note.Title))
+	}
+	if err != nil {
+		return note, fmt.Errorf("note saved but not committed: %w", err)
+	}
+	return note, nil
+}
+
+// List returns the notes directly inside a folder ("" = all notes), most recently modified first
+// Archived notes are left out, like in the web UI
+func (o *StorageNotes) List(folder string) ([]NoteListItem, error) {
+	folder = strings.Trim(strings.ReplaceAll(folder, FolderSeparator, "/"), "/")
+
+	entries := o.h.noteIndex.Entries(o.notesPath, func(path string, data []byte) (*model.Note, error) {
+		return o.h.loadNoteFromBytes(data, path, o.encryptionKey)
+	})
+	notes := []NoteListItem{}
+	for _, entry := range entries {
+		if entry.Archived {
+			continue
+		}
+		if parent, _ := splitFolderPath(entry.ID); folder != "" && parent != folder {
+			continue
+		}
+		notes = append(notes, noteListItemFromEntry(entry, time.Local))
+	}
+	sort.SliceStable(notes, func(i, j int) bool {
+		return notes[i].Modified.After(notes[j].Modified)
+	})
+	return notes, nil
+}
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/clip"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/websocket"
+)
+
+// maxClipImages limits the images downloaded for one clipped page
+const maxClipImages = 50
+
+// ClipRequest creates a note from a web page
+type ClipRequest struct {
+	URL        string   `json:"url" binding:"required"`
+	HTML       string   `json:"html"`  // Page as captured by a browser extension (e.g. behind a login); fetched from url when empty
+	Title      string   `json:"title"` // Default: title of the page
+	FolderPath string   `json:"folder_path"`
+	Tags       []string `json:"tags"`
+}
+
+// Clip creates a markdown note from the readable content of a web page
+// The page is fetched server-side (or taken from html), its images are downloaded as attachments
+// and the note records the page URL in its front matter
+func (h *NoteHandler) Clip(c *gin.Context) {
+	var req ClipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	folderPath := ""
+	if strings.Trim(req.FolderPath, "/ ") != "" {
+		var ok bool
+		if folderPath, ok = cleanFolderPath(normalizeName(req.FolderPath)); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+			return
+		}
+	}
+	if denyShare(c, folderPath, model.PermissionWrite) {
+		return
+	}
+
+	user := storageOwner(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageURL, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || (pageURL.Scheme != "http" && pageURL.Scheme != "https") || pageURL.Host == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL"})
+		return
+	}
+
+	page := []byte(req.HTML)
+	contentType := "text/html; charset=utf-8"
+	if req.HTML == "" {
+		result, err := h.fetcher.Get(c.Request.Context(), pageURL.String(), []string{"text/html", "application/xhtml+xml"})
+		if err != nil {
+			fetchError(c, req.URL, err)
+			return
+		}
+		page = result.Data
+		contentType = "text/html; charset=" + result.Charset
+		if final, err := url.Parse(result.URL); err == nil {
+			pageURL = final
+		}
+	}
+
+	article, err := clip.Extract(bytes.NewReader(page), contentType, pageURL)
+	if err != nil {
+		if !errors.Is(err, clip.ErrNoContent) {
+			encoding.Debug("Clip %s: %v", pageURL, err)
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "No readable content found on the page"})
+		return
+	}
+
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+	folderPath = canonicalFolderPath(notesPath, folderPath)
+	targetDir := filepath.Join(notesPath, folderPath)
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create folder"})
+		return
+	}
+
+	// Download the images so the note does not depend on the page staying online;
+	// images that cannot be downloaded keep their remote URL
+	content := article.Markdown
+	var attachments []model.Attachment
+	for i, src := range article.Images {
+		if i >= maxClipImages {
+			break
+		}
+		result, err := h.fetcher.Get(c.Request.Context(), src, []string{"image/"})
+		if err != nil {
+			encoding.Debug("Clip image %s: %v", src, err)
+			continue
+		}
+		filename, name, err := saveFetchedFile(h.basePath, user.Username, result, "")
+		if err != nil {
+			encoding.Warn("Clip: failed to save image %s: %v", src, err)
+			continue
+		}
+		if folderPath != "" {
+			setFileFolder(h.basePath, user.Username, filename, folderPath)
+		}
+		local := fmt.Sprintf("%s/u/%s/files/%s", h.config.Server.BasePath, user.Username, filename)
+		content = strings.ReplaceAll(content, "]("+src+")", "]("+local+")")
+		attachments = append(attachments, model.Attachment{
+			Name:    name,
+			URL:     local,
+			Size:    int64(len(result.Data)),
+			Type:    result.ContentType,
+			IsImage: true,
+		})
+	}
+
+	title := normalizeName(strings.TrimSpace(req.Title))
+	if title == "" {
+		title = normalizeName(article.Title)
+	}
+	if title == "" {
+		title = pageURL.Hostname()
+	}
+
+	id := generateID()
+	now := time.Now()
+	note := &model.Note{
+		ID:          strings.TrimPrefix(folderPath+"/"+id, "/"),
+		UID:         id,
+		FolderPath:  folderPath,
+		Title:       title,
+		Content:     clipHeader(article, pageURL, middleware.Location(c)) + content + "\n",
+		Type:        "markdown",
+		Tags:        req.Tags,
+		Attachments: attachments,
+		Source:      pageURL.String(),
+		Created:     now,
+		Modified:    now,
+	}
+
+	filePath, _ := filepath.Abs(filepath.Join(targetDir, id+note.GetExtension()))
+	if err := h.saveNoteToFile(note, filePath, encryptionKey); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if userRepo, err := h.getUserRepo(c); err == nil {
+		if err := userRepo.AddAndCommit(filePath, fmt.Sprintf("Clip: %s", note.Title)); err != nil {
+			encoding.Debug("Git commit error: %v", err)
+		}
+	}
+	h.noteIndex.Put(notesPath, note.UID, note.ID)
+
+	note.In(middleware.Location(c))
+	c.JSON(http.StatusCreated, note)
+
+	h.broadcastNoteChange(c, websocket.MsgTypeNoteCreated, note.ID)
+}
+
+// clipHeader is the quote at the top of a clipped note naming where it came from
+// e.g. "> Clipped from [Example News](https://...) · Jane Doe · 2026-10-15"
+func clipHeader(article *clip.Article, pageURL *url.URL, loc *time.Location) string {
+	site := article.SiteName
+	if site == "" {
+		site = pageURL.Hostname()
+	}
+	parts := []string{fmt.Sprintf("Clipped from [%s](%s)", strings.NewReplacer("[", `\[`, "]", `\]`).Replace(site), pageURL)}
+	if article.Byline != "" {
+		parts = append(parts, article.Byline)
+	}
+	if !article.Published.IsZero() {
+		parts = append(parts, article.Published.In(loc).Format("2006-01-02"))
+	}
+
+	header := "> " + strings.Join(parts, " · ") + "\n"
+	if article.Excerpt != "" {
+		header += ">\n> " + strings.Join(strings.Fields(article.Excerpt), " ") + "\n"
+	}
+	return header + "\n"
+}
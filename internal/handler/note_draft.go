@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/encryption"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// NoteDraft is an autosaved working copy of a note
+// Drafts are kept in the user's .drafts/ folder, outside notes/, so they are never committed
+type NoteDraft struct {
+	Title        string    `json:"title"`
+	Content      string    `json:"content"`
+	BaseModified time.Time `json:"base_modified"` // Modified time of the note the draft was based on
+	SavedAt      time.Time `json:"saved_at"`
+}
+
+// SaveDraftRequest represents the request body for saving a draft
+type SaveDraftRequest struct {
+	Title        string     `json:"title"`
+	Content      string     `json:"content"`
+	BaseModified *time.Time `json:"base_modified"` // nil = the note as it is now
+}
+
+// draftPath returns the draft file of a note in the storage owner's directory
+func (h *NoteHandler) draftPath(c *gin.Context, uid string) string {
+	return filepath.Join(h.getUserStoragePath(c), ".drafts", uid+".json")
+}
+
+// draftNote finds the note a draft request is for, responding with an error if it can't be used
+// Drafts of private notes need the X-Note-Password header, like changing their content
+// The draft content of a note with a password is encrypted with the note's content key
+func (h *NoteHandler) draftNote(c *gin.Context, required model.SharePermission) (string, *model.Note, bool) {
+	id := h.resolveNoteID(c, decodeNoteID(c.Param("id")))
+	if h.denyNote(c, id, required) {
+		return "", nil, false
+	}
+
+	filePath, note := h.findNote(h.getNotesPath(c), id, h.getEncryptionKey(c))
+	if note == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return "", nil, false
+	}
+	if note.Private && !note.Unlock(c.GetHeader("X-Note-Password")) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		return "", nil, false
+	}
+	return noteFileUID(filePath, note), note, true
+}
+
+// GetDraft returns the draft of a note; stale is true when the note was saved after the draft was started
+func (h *NoteHandler) GetDraft(c *gin.Context) {
+	uid, note, ok := h.draftNote(c, model.PermissionRead)
+	if !ok {
+		return
+	}
+
+	data, err := os.ReadFile(h.draftPath(c, uid))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No draft"})
+		return
+	}
+	if encryption.IsEncrypted(string(data)) {
+		encryptionKey := h.getEncryptionKey(c)
+		if encryptionKey == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No draft"})
+			return
+		}
+		if data, err = encryption.Decrypt(string(data), encryptionKey); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No draft"})
+			return
+		}
+	}
+
+	var draft NoteDraft
+	if err := json.Unmarshal(data, &draft); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No draft"})
+		return
+	}
+	// Sealed with a previous password's key, the draft can't be read anymore
+	if draft.Content, err = note.OpenText(draft.Content); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No draft"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"title":         draft.Title,
+		"content":       draft.Content,
+		"base_modified": draft.BaseModified,
+		"saved_at":      draft.SavedAt,
+		"stale":         note.Modified.After(draft.BaseModified),
+	})
+}
+
+// SaveDraft stores a working copy of a note without changing the note or committing it
+func (h *NoteHandler) SaveDraft(c *gin.Context) {
+	uid, note, ok := h.draftNote(c, model.PermissionWrite)
+	if !ok {
+		return
+	}
+
+	var req SaveDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	content, err := note.SealText(req.Content)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save draft"})
+		return
+	}
+	draft := NoteDraft{
+		Title:        req.Title,
+		Content:      content,
+		BaseModified: note.Modified,
+		SavedAt:      time.Now(),
+	}
+	if req.BaseModified != nil {
+		draft.BaseModified = *req.BaseModified
+	}
+
+	data, err := json.Marshal(draft)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save draft"})
+		return
+	}
+	// Encrypt like the note itself
+	if encryptionKey := h.getEncryptionKey(c); h.config.Encryption.Enabled && encryptionKey != nil {
+		encrypted, err := encryption.Encrypt(data, encryptionKey)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save draft"})
+			return
+		}
+		data = []byte(encrypted)
+	}
+
+	path := h.draftPath(c, uid)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save draft"})
+		return
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save draft"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"saved_at": draft.SavedAt})
+}
+
+// DeleteDraft discards the draft of a note
+func (h *NoteHandler) DeleteDraft(c *gin.Context) {
+	uid, _, ok := h.draftNote(c, model.PermissionWrite)
+	if !ok {
+		return
+	}
+	h.removeDraft(c, uid)
+	c.JSON(http.StatusOK, gin.H{"message": "Draft deleted"})
+}
+
+// removeDraft deletes a draft file if there is one (after the note was saved)
+func (h *NoteHandler) removeDraft(c *gin.Context, uid string) {
+	if err := os.Remove(h.draftPath(c, uid)); err != nil && !os.IsNotExist(err) {
+		encoding.Warn("Failed to remove draft %s: %v", uid, err)
+	}
+}
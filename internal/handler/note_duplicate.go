@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/websocket"
+)
+
+// DuplicateNoteRequest represents the optional body for duplicating a note
+type DuplicateNoteRequest struct {
+	FolderPath *string `json:"folder_path"` // nil = same folder as the original
+	Title      string  `json:"title"`       // Empty = "Copy of <title>"
+}
+
+// Duplicate copies a note (content, type, tags, icon and attachment metadata) to a new note
+// in the same or another folder, e.g. to stamp out notes from a template
+// Private notes need the X-Note-Password header; the copy keeps their password
+func (h *NoteHandler) Duplicate(c *gin.Context) {
+	id := h.resolveNoteID(c, decodeNoteID(c.Param("id")))
+	if folder, _ := splitFolderPath(id); denyShare(c, folder, model.PermissionRead) {
+		return
+	}
+
+	var req DuplicateNoteRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+	}
+
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+
+	_, source := h.findNote(notesPath, id, encryptionKey)
+	if source == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+
+	if source.Private && !source.Unlock(c.GetHeader("X-Note-Password")) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		return
+	}
+
+	folderPath, _ := splitFolderPath(id)
+	if req.FolderPath != nil {
+		folderPath = strings.Trim(normalizeName(*req.FolderPath), "/")
+		if strings.Contains(folderPath, "..") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+			return
+		}
+		folderPath = canonicalFolderPath(notesPath, folderPath)
+	}
+	if denyShare(c, folderPath, model.PermissionWrite) {
+		return
+	}
+
+	targetDir := notesPath
+	if folderPath != "" {
+		targetDir = filepath.Join(notesPath, folderPath)
+		if err := os.MkdirAll(targetDir, 0755); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create folder"})
+			return
+		}
+	}
+
+	title := normalizeName(strings.TrimSpace(req.Title))
+	if title == "" {
+		title = "Copy of " + source.Title
+	}
+
+	newID := generateID()
+	fullID := newID
+	if folderPath != "" {
+		fullID = folderPath + "/" + newID
+	}
+
+	now := time.Now()
+	note := &model.Note{
+		ID:          fullID,
+		UID:         newID,
+		FolderPath:  folderPath,
+		Title:       title,
+		Content:     source.Content,
+		Type:        source.Type,
+		Icon:        source.Icon,
+		Tags:        source.Tags,
+		Private:     source.Private,
+		Attachments: source.Attachments,
+		Due:         source.Due,
+		Created:     now,
+		Modified:    now,
+	}
+	note.CopyProtection(source)
+
+	filePath, _ := filepath.Abs(filepath.Join(targetDir, newID+note.GetExtension()))
+	if err := h.saveNoteToFile(note, filePath, encryptionKey); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to duplicate note"})
+		return
+	}
+
+	// Git commit - use user-specific repo
+	if userRepo, err := h.getUserRepo(c); err == nil {
+		userRepo.AddAndCommit(filePath, fmt.Sprintf("Duplicate note: %s -> %s", source.Title, note.Title))
+	}
+	h.noteIndex.Put(notesPath, note.UID, note.ID)
+
+	note.In(middleware.Location(c))
+	c.JSON(http.StatusCreated, note)
+
+	// Broadcast note creation to other clients of the same user
+	h.broadcastNoteChange(c, websocket.MsgTypeNoteCreated, note.ID)
+}
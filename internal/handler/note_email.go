@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	netmail "net/mail"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/mail"
+	"github.com/user/gitnotepad/internal/markdown"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+const (
+	emailMaxRecipients = 20
+	pdfTimeout         = time.Minute
+)
+
+// emailTemplate is the HTML document of a sent or exported note (also the input of the PDF converter)
+var emailTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "Malgun Gothic", "Apple SD Gothic Neo", sans-serif; line-height: 1.6; color: #1f2328; max-width: 760px; margin: 0 auto; padding: 16px; }
+pre { background: #f6f8fa; padding: 12px; border-radius: 6px; overflow-x: auto; white-space: pre-wrap; }
+code { font-family: Consolas, Monaco, monospace; font-size: 0.9em; }
+:not(pre) > code { background: #f6f8fa; padding: 0.1em 0.3em; border-radius: 4px; }
+blockquote { border-left: 4px solid #d0d7de; margin: 1em 0; padding: 0 1em; color: #59636e; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 6px 12px; }
+.message { white-space: pre-wrap; padding-bottom: 16px; margin-bottom: 16px; border-bottom: 1px solid #d0d7de; }
+.footer { margin-top: 24px; font-size: 12px; color: #59636e; }
+</style>
+</head>
+<body>
+{{if .Message}}<div class="message">{{.Message}}</div>{{end}}
+{{if .Body}}<h1>{{.Title}}</h1>
+{{.Body}}{{else}}<p>{{.Title}} is attached as PDF.</p>{{end}}
+{{if .Sender}}<div class="footer">Sent by {{.Sender}} from Git Notepad</div>{{end}}
+</body>
+</html>
+`))
+
+// SendEmailRequest is the request body of SendEmail
+type SendEmailRequest struct {
+	To      []string `json:"to"`
+	Subject string   `json:"subject"`  // Default: note title
+	Message string   `json:"message"`  // Optional text shown above the note
+	Format  string   `json:"format"`   // "inline" (default) or "pdf"
+	ReplyTo string   `json:"reply_to"` // Optional address replies go to
+}
+
+// SendEmail sends a note to the given recipients through the configured SMTP server,
+// rendered in the message body or as a PDF attachment (format=pdf, requires export.pdf_command)
+// Password-protected notes require the X-Note-Password header
+func (h *NoteHandler) SendEmail(c *gin.Context) {
+	if !mail.Configured(h.config.SMTP) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "SMTP is not configured"})
+		return
+	}
+
+	var req SendEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.To) == 0 || len(req.To) > emailMaxRecipients {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Between 1 and %d recipients are required", emailMaxRecipients)})
+		return
+	}
+	recipients := make([]string, 0, len(req.To))
+	for _, to := range req.To {
+		addr, err := netmail.ParseAddress(strings.TrimSpace(to))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipient: " + to})
+			return
+		}
+		recipients = append(recipients, addr.Address)
+	}
+	if req.ReplyTo != "" {
+		addr, err := netmail.ParseAddress(req.ReplyTo)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reply_to address"})
+			return
+		}
+		req.ReplyTo = addr.String()
+	}
+	if req.Format == "" {
+		req.Format = "inline"
+	}
+	if req.Format != "inline" && req.Format != "pdf" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be inline or pdf"})
+		return
+	}
+	if req.Format == "pdf" && h.config.Export.PDFCommand == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "PDF conversion is not configured"})
+		return
+	}
+
+	id := h.resolveNoteID(c, decodeNoteID(c.Param("id")))
+	if folder, _ := splitFolderPath(id); denyShare(c, folder, model.PermissionRead) {
+		return
+	}
+	_, note := h.findNote(h.getNotesPath(c), id, h.getEncryptionKey(c))
+	if note == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+	if note.Private && !note.Unlock(c.GetHeader("X-Note-Password")) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		return
+	}
+
+	sender := ""
+	if user := middleware.GetCurrentUser(c); user != nil {
+		sender = user.Username
+	}
+	subject := strings.TrimSpace(req.Subject)
+	if subject == "" {
+		subject = note.Title
+	}
+
+	body := noteEmailHTML(note, siteOrigin(h.config, c))
+	msg := &mail.Message{
+		To:      recipients,
+		ReplyTo: req.ReplyTo,
+		Subject: subject,
+	}
+
+	if req.Format == "pdf" {
+		document, err := renderEmail(note.Title, req.Message, sender, body)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render note"})
+			return
+		}
+		pdf, err := htmlToPDF(c.Request.Context(), h.config.Export.PDFCommand, document)
+		if err != nil {
+			encoding.Warn("PDF conversion failed: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to convert note to PDF"})
+			return
+		}
+		title := strings.TrimSpace(exportFileNameReplacer.Replace(note.Title))
+		if title == "" {
+			title = "note"
+		}
+		msg.Attachments = []mail.Attachment{{Name: title + ".pdf", ContentType: "application/pdf", Data: pdf}}
+		body = ""
+	}
+
+	msg.HTML, _ = renderEmail(note.Title, req.Message, sender, body)
+	msg.Text = noteEmailText(note, req.Message, req.Format == "pdf")
+
+	if err := mail.Send(h.config.SMTP, msg); err != nil {
+		encoding.Warn("Failed to email note %s: %v", id, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to send email: " + err.Error()})
+		return
+	}
+
+	recordAudit(h.auditRepo, c, model.AuditNoteEmail, id, "format="+req.Format+" to="+strings.Join(recipients, ","))
+	c.JSON(http.StatusOK, gin.H{
+		"sent":   len(recipients),
+		"format": req.Format,
+	})
+}
+
+// noteEmailHTML renders the note body: markdown as HTML, other types as preformatted text
+func noteEmailHTML(note *model.Note, origin string) template.HTML {
+	if note.Type == "markdown" || note.Type == "md" {
+		return template.HTML(markdown.ToHTML(note.Content, origin))
+	}
+	return template.HTML("<pre>" + template.HTMLEscapeString(note.Content) + "</pre>")
+}
+
+// noteEmailText is the plain text alternative: the message followed by the note source
+func noteEmailText(note *model.Note, message string, attached bool) string {
+	var sb strings.Builder
+	if message != "" {
+		sb.WriteString(message + "\n\n---\n\n")
+	}
+	if attached {
+		sb.WriteString(note.Title + " is attached as PDF.\n")
+	} else {
+		sb.WriteString(note.Title + "\n\n" + note.Content + "\n")
+	}
+	return sb.String()
+}
+
+func renderEmail(title, message, sender string, body template.HTML) (string, error) {
+	var buf bytes.Buffer
+	err := emailTemplate.Execute(&buf, struct {
+		Title, Message, Sender string
+		Body                   template.HTML
+	}{title, message, sender, body})
+	return buf.String(), err
+}
+
+// htmlToPDF runs the configured converter with the HTML document on stdin and returns its stdout
+func htmlToPDF(ctx context.Context, command, document string) ([]byte, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("no PDF converter configured")
+	}
+	ctx, cancel := context.WithTimeout(ctx, pdfTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(document)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if !bytes.HasPrefix(stdout.Bytes(), []byte("%PDF")) {
+		return nil, errors.New("converter did not produce a PDF")
+	}
+	return stdout.Bytes(), nil
+}
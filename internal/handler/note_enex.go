@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/enex"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/websocket"
+)
+
+// defaultEvernoteFolder receives imported Evernote notebooks unless another folder is given
+const defaultEvernoteFolder = "Evernote"
+
+// ImportEvernote imports Evernote exports (one or more .enex files, each a notebook) into a folder
+// Each file becomes a subfolder named after it; bodies are converted to markdown, checkboxes to task lists,
+// embedded images and files to attachments, and creation dates, tags and source URLs are kept.
+// The response lists the result of every note
+func (h *NoteHandler) ImportEvernote(c *gin.Context) {
+	folder := c.PostForm("folder")
+	if folder == "" {
+		folder = defaultEvernoteFolder
+	}
+	folderPath, ok := cleanFolderPath(folder)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+		return
+	}
+	if denyShare(c, folderPath, model.PermissionWrite) {
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["file"]) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file provided"})
+		return
+	}
+
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+	folderPath = canonicalFolderPath(notesPath, folderPath)
+	targetDir := filepath.Join(notesPath, folderPath)
+
+	username := "shared"
+	filesPath := filepath.Join(h.basePath, "files")
+	if user := storageOwner(c); user != nil {
+		username = user.Username
+		filesPath = filepath.Join(h.basePath, user.Username, "files")
+	}
+	images := NewImageHandler(h.basePath, h.config.Server.BasePath, h.config.Upload)
+
+	var results []gin.H
+	imported, failed := 0, 0
+	for _, header := range form.File["file"] {
+		notebook := strings.TrimSuffix(path.Base(filepath.ToSlash(header.Filename)), path.Ext(header.Filename))
+
+		file, err := header.Open()
+		if err != nil {
+			results = append(results, gin.H{"file": header.Filename, "error": tr(c, "Failed to read file")})
+			failed++
+			continue
+		}
+		enexNotes, err := enex.Read(file)
+		file.Close()
+		if err != nil {
+			// Notes read before a malformed part of the file are still imported
+			encoding.Warn("Evernote import: %s: %v", header.Filename, err)
+			results = append(results, gin.H{"file": header.Filename, "error": tr(c, "Invalid ENEX file")})
+			failed++
+		}
+		if len(enexNotes) == 0 {
+			continue
+		}
+
+		noteFolder := folderPath
+		if sub, ok := cleanFolderPath(folderPath + "/" + notebook); ok {
+			noteFolder = canonicalFolderPath(notesPath, sub)
+		}
+		noteDir := filepath.Join(notesPath, noteFolder)
+		if err := os.MkdirAll(noteDir, 0755); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create folder"})
+			return
+		}
+
+		for _, en := range enexNotes {
+			title := normalizeName(en.Title)
+
+			var attachments []model.Attachment
+			saved := make(map[*enex.Resource]string)
+			content := en.Markdown(func(res *enex.Resource) (string, bool) {
+				if url, ok := saved[res]; ok {
+					return url, true
+				}
+				att, err := saveImportedAttachment(bytes.NewReader(res.Data), res.Name, res.MimeType, filesPath, username, h.config.Server.BasePath)
+				if err != nil {
+					encoding.Warn("Evernote import: failed to save attachment %s of %q: %v", res.Name, title, err)
+					return "", false
+				}
+				images.saveMetadata(username, path.Base(att.URL), att.Name)
+				attachments = append(attachments, att)
+				saved[res] = att.URL
+				return att.URL, true
+			})
+
+			id := generateID()
+			note := &model.Note{
+				ID:          noteFolder + "/" + id,
+				UID:         id,
+				FolderPath:  noteFolder,
+				Title:       title,
+				Content:     content,
+				Type:        "markdown",
+				Tags:        en.Tags,
+				Attachments: attachments,
+				Source:      en.SourceURL,
+				Created:     en.Created,
+				Modified:    en.Modified,
+			}
+
+			filePath, _ := filepath.Abs(filepath.Join(noteDir, id+note.GetExtension()))
+			if err := h.saveNoteToFile(note, filePath, encryptionKey); err != nil {
+				encoding.Warn("Evernote import: failed to save %q: %v", title, err)
+				results = append(results, gin.H{"file": header.Filename, "title": title, "error": tr(c, "Failed to save note")})
+				failed++
+				continue
+			}
+			h.noteIndex.Put(notesPath, note.UID, note.ID)
+			results = append(results, gin.H{"file": header.Filename, "title": title, "id": note.ID, "attachments": len(attachments)})
+			imported++
+		}
+	}
+
+	// Single commit for the whole import
+	if imported > 0 {
+		if userRepo, err := h.getUserRepo(c); err == nil {
+			message := fmt.Sprintf("Import %d notes from Evernote", imported)
+			if err := userRepo.AddPathsAndCommit([]string{targetDir}, message); err != nil {
+				encoding.Debug("Git commit error: %v", err)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"imported":    imported,
+		"failed":      failed,
+		"folder_path": folderPath,
+		"notes":       results,
+	})
+
+	if imported > 0 {
+		h.broadcastNoteChange(c, websocket.MsgTypeNotesRefresh, "")
+	}
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/markdown"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// exportImageMaxSize is the largest image embedded in an exported note; larger ones stay links
+const exportImageMaxSize = 10 << 20
+
+// exportImagePattern matches image sources pointing at uploaded files or images of a user
+var exportImagePattern = regexp.MustCompile(`src="[^"]*/u/([^/"]+)/(files|images)/([^/"?#]+)"`)
+
+// Export downloads a single note: format=html (default) renders it to a standalone page with
+// embedded images, format=pdf converts that page with export.pdf_command and format=md returns the source
+// Private notes require the X-Note-Password header
+func (h *NoteHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", "html")
+	if format != "html" && format != "pdf" && format != "md" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be html, pdf or md"})
+		return
+	}
+	if format == "pdf" && h.config.Export.PDFCommand == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "PDF conversion is not configured"})
+		return
+	}
+
+	id := h.resolveNoteID(c, decodeNoteID(c.Param("id")))
+	if h.denyNote(c, id, model.PermissionRead) {
+		return
+	}
+	_, note := h.findNote(h.getNotesPath(c), id, h.getEncryptionKey(c))
+	if note == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+	if note.Private && !note.Unlock(c.GetHeader("X-Note-Password")) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		return
+	}
+
+	title := strings.TrimSpace(exportFileNameReplacer.Replace(note.Title))
+	if title == "" {
+		title = "note"
+	}
+
+	if format == "md" {
+		setExportFileName(c, title+note.GetExtension())
+		c.Data(http.StatusOK, noteContentType(note.Type), []byte(note.Content))
+		return
+	}
+
+	body := h.embedExportImages(c, noteExportHTML(note, siteOrigin(h.config, c)))
+	document, err := renderEmail(note.Title, "", "", body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render note"})
+		return
+	}
+
+	if format == "html" {
+		setExportFileName(c, title+".html")
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(document))
+		return
+	}
+
+	pdf, err := htmlToPDF(c.Request.Context(), h.config.Export.PDFCommand, document)
+	if err != nil {
+		encoding.Warn("PDF conversion failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to convert note to PDF"})
+		return
+	}
+	setExportFileName(c, title+".pdf")
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// noteExportHTML renders the note body like noteEmailHTML, with AsciiDoc notes rendered too
+func noteExportHTML(note *model.Note, origin string) template.HTML {
+	if note.Type == "asciidoc" {
+		return template.HTML(markdown.AsciiDocToHTML(note.Content, origin))
+	}
+	return noteEmailHTML(note, origin)
+}
+
+// embedExportImages replaces images uploaded by the note's owner with data URIs,
+// so exported pages and PDFs show them without access to the server
+func (h *NoteHandler) embedExportImages(c *gin.Context, body template.HTML) template.HTML {
+	owner := ""
+	if user := storageOwner(c); user != nil {
+		owner = user.Username
+	}
+	storagePath := h.getUserStoragePath(c)
+
+	return template.HTML(exportImagePattern.ReplaceAllStringFunc(string(body), func(src string) string {
+		m := exportImagePattern.FindStringSubmatch(src)
+		filename, err := url.PathUnescape(m[3])
+		if err != nil || m[1] != owner || !validAttachmentName(filename) {
+			return src
+		}
+		contentType := attachmentContentType(filename)
+		if !strings.HasPrefix(contentType, "image/") {
+			return src
+		}
+
+		filePath := filepath.Join(storagePath, m[2], filename)
+		if _, err := os.Stat(filePath); err != nil {
+			// Legacy global directory used before per-user storage
+			filePath = filepath.Join(h.basePath, m[2], filename)
+		}
+		info, err := os.Stat(filePath)
+		if err != nil || info.Size() > exportImageMaxSize {
+			return src
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return src
+		}
+		return `src="data:` + contentType + `;base64,` + base64.StdEncoding.EncodeToString(data) + `"`
+	}))
+}
+
+// setExportFileName makes the response a download with the given file name
+func setExportFileName(c *gin.Context, name string) {
+	safeFilename := strings.ReplaceAll(name, `\`, `\\`)
+	safeFilename = strings.ReplaceAll(safeFilename, `"`, `\"`)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, safeFilename, url.PathEscape(name)))
+}
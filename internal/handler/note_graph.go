@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/index"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// maxTagGroup skips tag edges for tags on more notes than this (e.g. "telegram"), which would connect everything
+const maxTagGroup = 50
+
+// GraphNode is a note in the link graph
+type GraphNode struct {
+	ID         string   `json:"id"`
+	UID        string   `json:"uid"`
+	Title      string   `json:"title"`
+	FolderPath string   `json:"folder_path"`
+	Tags       []string `json:"tags,omitempty"`
+	Private    bool     `json:"private"`
+}
+
+// GraphEdge connects two notes: type is "link" (source links to target), "attachment" or "tag"
+type GraphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+	Label  string `json:"label,omitempty"` // Tag name or attachment URL
+}
+
+// Graph returns the note graph: wiki-links, shared attachments and shared tags, built from the metadata index
+// Query: types=link,attachment,tag (default all)
+func (h *NoteHandler) Graph(c *gin.Context) {
+	types := map[string]bool{"link": true, "attachment": true, "tag": true}
+	if t := c.Query("types"); t != "" {
+		types = map[string]bool{}
+		for _, name := range strings.Split(t, ",") {
+			types[strings.TrimSpace(name)] = true
+		}
+	}
+
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+	entries := h.noteIndex.Entries(notesPath, func(path string, data []byte) (*model.Note, error) {
+		return h.loadNoteFromBytes(data, path, encryptionKey)
+	})
+
+	var visible []index.Entry
+	for _, entry := range entries {
+		if folder, _ := splitFolderPath(entry.ID); middleware.HasSharePermission(c, folder, model.PermissionRead) {
+			visible = append(visible, entry)
+		}
+	}
+	sort.Slice(visible, func(i, j int) bool {
+		return visible[i].ID < visible[j].ID
+	})
+
+	nodes := make([]GraphNode, 0, len(visible))
+	titles := make(map[string]string) // lowercased title or alias -> note ID
+	for _, entry := range visible {
+		nodes = append(nodes, GraphNode{
+			ID:         entry.ID,
+			UID:        entry.UID,
+			Title:      entry.Title,
+			FolderPath: entry.FolderPath,
+			Tags:       entry.Tags,
+			Private:    entry.Private,
+		})
+		for _, name := range append([]string{entry.Title}, entry.Aliases...) {
+			key := strings.ToLower(normalizeName(name))
+			if _, exists := titles[key]; !exists {
+				titles[key] = entry.ID
+			}
+		}
+	}
+
+	edges := []GraphEdge{}
+	attachments := make(map[string][]string) // URL -> note IDs
+	tags := make(map[string][]string)        // tag -> note IDs
+
+	for _, entry := range visible {
+		// Links and attachments of private notes would reveal their content
+		if !entry.Private {
+			if types["link"] {
+				for _, link := range entry.Links {
+					if target, ok := titles[strings.ToLower(normalizeName(link))]; ok && target != entry.ID {
+						edges = append(edges, GraphEdge{Source: entry.ID, Target: target, Type: "link"})
+					}
+				}
+			}
+			for _, url := range entry.Attachments {
+				attachments[url] = append(attachments[url], entry.ID)
+			}
+		}
+		for _, tag := range entry.Tags {
+			tags[tag] = append(tags[tag], entry.ID)
+		}
+	}
+
+	if types["attachment"] {
+		edges = appendGroupEdges(edges, attachments, "attachment")
+	}
+	if types["tag"] {
+		for tag, ids := range tags {
+			if len(ids) > maxTagGroup {
+				delete(tags, tag)
+			}
+		}
+		edges = appendGroupEdges(edges, tags, "tag")
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"nodes": nodes,
+		"edges": edges,
+	})
+}
+
+// appendGroupEdges connects every pair of notes within each group, labeled with the group key
+func appendGroupEdges(edges []GraphEdge, groups map[string][]string, edgeType string) []GraphEdge {
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		ids := groups[key]
+		for i := 0; i < len(ids); i++ {
+			for j := i + 1; j < len(ids); j++ {
+				if ids[i] == ids[j] {
+					continue
+				}
+				edges = append(edges, GraphEdge{Source: ids[i], Target: ids[j], Type: edgeType, Label: key})
+			}
+		}
+	}
+	return edges
+}
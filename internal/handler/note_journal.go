@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/journal"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/websocket"
+)
+
+// JournalToday returns today's journal note
+// GET only looks the note up (404 if absent); POST creates it from the journal folder's template, responding 201
+func (h *NoteHandler) JournalToday(c *gin.Context) {
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+
+	folderPath := ""
+	if h.config.Journal.Folder != "" {
+		cleaned, ok := cleanFolderPath(h.config.Journal.Folder)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid journal folder"})
+			return
+		}
+		folderPath = canonicalFolderPath(notesPath, cleaned)
+	}
+	if denyShare(c, folderPath, model.PermissionRead) {
+		return
+	}
+
+	folderDir := filepath.Join(notesPath, filepath.FromSlash(folderPath))
+	load := func(path string) (*model.Note, error) {
+		return h.loadNoteFromFile(path, encryptionKey)
+	}
+
+	// The journal day follows the user's time zone
+	now := time.Now().In(middleware.Location(c))
+	if filePath, note := journal.Find(folderDir, journal.Title(h.config.Journal, now), load); note != nil {
+		relPath, _ := filepath.Rel(notesPath, filePath)
+		note.ID = strings.TrimSuffix(filepath.ToSlash(relPath), filepath.Ext(filePath))
+
+		if note.Private {
+			// Client unlocks the note through GET /api/notes/:id
+			c.JSON(http.StatusOK, gin.H{
+				"id":       note.ID,
+				"uid":      note.UID,
+				"title":    note.Title,
+				"type":     note.Type,
+				"private":  note.Private,
+				"locked":   true,
+				"created":  note.Created.In(now.Location()),
+				"modified": note.Modified.In(now.Location()),
+			})
+			return
+		}
+
+		h.recordView(c, note)
+		note.In(now.Location())
+		c.JSON(http.StatusOK, note)
+		return
+	}
+
+	// Creating the note is a change: left to POST, so read-only tokens and maintenance mode can't write
+	if c.Request.Method != http.MethodPost {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Journal note not found"})
+		return
+	}
+	if denyShare(c, folderPath, model.PermissionWrite) {
+		return
+	}
+
+	if err := os.MkdirAll(folderDir, 0755); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create folder"})
+		return
+	}
+
+	note := journal.New(folderDir, h.config.Journal, now, h.config.Editor.DefaultType, load)
+	id := generateID()
+	note.ID = id
+	if folderPath != "" {
+		note.ID = folderPath + "/" + id
+	}
+	note.UID = id
+	note.FolderPath = folderPath
+
+	filePath, _ := filepath.Abs(filepath.Join(folderDir, id+note.GetExtension()))
+	if err := h.saveNoteToFile(note, filePath, encryptionKey); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if userRepo, err := h.getUserRepo(c); err == nil {
+		userRepo.AddAndCommit(filePath, fmt.Sprintf("Create note: %s", note.Title))
+	}
+	h.noteIndex.Put(notesPath, note.UID, note.ID)
+
+	note.In(now.Location())
+	c.JSON(http.StatusCreated, note)
+
+	h.broadcastNoteChange(c, websocket.MsgTypeNoteCreated, note.ID)
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/keep"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/websocket"
+)
+
+// defaultKeepFolder receives imported Keep notes unless another folder is given
+const defaultKeepFolder = "Google Keep"
+
+// ImportKeep imports a Google Takeout ZIP of Keep notes into a folder
+// Checklists become task lists, labels become tags, images become attachments and timestamps are kept.
+// Trashed notes are skipped, archived notes get the "archived" tag, and notes already imported
+// (same title and creation time in the folder) are skipped so the import can be repeated
+func (h *NoteHandler) ImportKeep(c *gin.Context) {
+	folder := c.PostForm("folder")
+	if folder == "" {
+		folder = defaultKeepFolder
+	}
+	folderPath, ok := cleanFolderPath(folder)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+		return
+	}
+	if denyShare(c, folderPath, model.PermissionWrite) {
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file provided"})
+		return
+	}
+	defer file.Close()
+
+	zipReader, err := zip.NewReader(file, header.Size)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ZIP file"})
+		return
+	}
+	keepNotes, err := keep.Read(zipReader)
+	if err != nil || len(keepNotes) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No Google Keep notes found in archive"})
+		return
+	}
+
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+	folderPath = canonicalFolderPath(notesPath, folderPath)
+	targetDir := filepath.Join(notesPath, folderPath)
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create folder"})
+		return
+	}
+
+	// Notes from an earlier import of the same archive
+	existing := make(map[string]bool)
+	for _, entry := range h.noteIndex.Entries(notesPath, func(path string, data []byte) (*model.Note, error) {
+		return h.loadNoteFromBytes(data, path, encryptionKey)
+	}) {
+		if entry.FolderPath == folderPath {
+			existing[keepNoteKey(entry.Title, entry.Created)] = true
+		}
+	}
+
+	username := "shared"
+	filesPath := filepath.Join(h.basePath, "files")
+	if user := storageOwner(c); user != nil {
+		username = user.Username
+		filesPath = filepath.Join(h.basePath, user.Username, "files")
+	}
+	images := NewImageHandler(h.basePath, h.config.Server.BasePath, h.config.Upload)
+
+	imported, skipped := 0, 0
+	for _, kn := range keepNotes {
+		title := normalizeName(kn.NoteTitle())
+		created := kn.Created()
+		if kn.IsTrashed || existing[keepNoteKey(title, created)] {
+			skipped++
+			continue
+		}
+
+		tags := kn.Tags()
+		if kn.IsArchived {
+			tags = append(tags, "archived")
+		}
+
+		content := kn.Markdown()
+		var attachments []model.Attachment
+		for _, ka := range kn.Attachments {
+			if ka.File == nil {
+				encoding.Warn("Keep import: attachment %s of %q not found in archive", ka.FilePath, title)
+				continue
+			}
+			att, err := saveZipAttachment(ka.File, path.Base(ka.FilePath), ka.MimeType, filesPath, username, h.config.Server.BasePath)
+			if err != nil {
+				encoding.Warn("Keep import: failed to save attachment %s: %v", ka.FilePath, err)
+				continue
+			}
+			images.saveMetadata(username, path.Base(att.URL), att.Name)
+			attachments = append(attachments, att)
+			if att.IsImage {
+				content = strings.TrimRight(content, "\n") + "\n\n![" + att.Name + "](" + att.URL + ")\n"
+			}
+		}
+
+		id := generateID()
+		note := &model.Note{
+			ID:          folderPath + "/" + id,
+			UID:         id,
+			FolderPath:  folderPath,
+			Title:       title,
+			Content:     strings.TrimLeft(content, "\n"),
+			Type:        "markdown",
+			Tags:        tags,
+			Attachments: attachments,
+			Created:     created,
+			Modified:    kn.Modified(),
+		}
+
+		filePath, _ := filepath.Abs(filepath.Join(targetDir, id+note.GetExtension()))
+		if err := h.saveNoteToFile(note, filePath, encryptionKey); err != nil {
+			encoding.Warn("Keep import: failed to save %q: %v", title, err)
+			continue
+		}
+		h.noteIndex.Put(notesPath, note.UID, note.ID)
+		existing[keepNoteKey(title, created)] = true
+		imported++
+	}
+
+	// Single commit for the whole import
+	if imported > 0 {
+		if userRepo, err := h.getUserRepo(c); err == nil {
+			message := fmt.Sprintf("Import %d notes from Google Keep", imported)
+			if err := userRepo.AddPathsAndCommit([]string{targetDir}, message); err != nil {
+				encoding.Debug("Git commit error: %v", err)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"imported":    imported,
+		"skipped":     skipped,
+		"folder_path": folderPath,
+	})
+
+	if imported > 0 {
+		h.broadcastNoteChange(c, websocket.MsgTypeNotesRefresh, "")
+	}
+}
+
+// keepNoteKey identifies an imported Keep note by title and creation time (to the second)
+func keepNoteKey(title string, created time.Time) string {
+	return strings.ToLower(title) + "|" + created.UTC().Truncate(time.Second).Format(time.RFC3339)
+}
+
+// saveZipAttachment copies a file of an imported archive into the user's files directory under a UUID name
+// The MIME type is taken from the extension when the archive doesn't give one
+func saveZipAttachment(f *zip.File, name, mimeType, filesPath, username, basePath string) (model.Attachment, error) {
+	src, err := f.Open()
+	if err != nil {
+		return model.Attachment{}, err
+	}
+	defer src.Close()
+	return saveImportedAttachment(src, name, mimeType, filesPath, username, basePath)
+}
+
+// saveImportedAttachment writes an attachment of an imported note into the user's files directory under a UUID name
+func saveImportedAttachment(src io.Reader, name, mimeType, filesPath, username, basePath string) (model.Attachment, error) {
+	if err := os.MkdirAll(filesPath, 0755); err != nil {
+		return model.Attachment{}, err
+	}
+
+	ext := strings.ToLower(path.Ext(name))
+	if mimeType == "" {
+		mimeType = mime.TypeByExtension(ext)
+	}
+	filename := uuid.New().String() + ext
+
+	dst, err := os.Create(filepath.Join(filesPath, filename))
+	if err != nil {
+		return model.Attachment{}, err
+	}
+	size, err := io.Copy(dst, src)
+	dst.Close()
+	if err != nil {
+		os.Remove(filepath.Join(filesPath, filename))
+		return model.Attachment{}, err
+	}
+
+	return model.Attachment{
+		Name:    name,
+		URL:     fmt.Sprintf("%s/u/%s/files/%s", basePath, username, filename),
+		Size:    size,
+		Type:    mimeType,
+		IsImage: strings.HasPrefix(mimeType, "image/"),
+	}, nil
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/websocket"
+)
+
+// noteLockTTL is how long an edit lock lasts without being claimed again
+// The editor renews its lock well before that, so a closed tab frees the note within noteLockTTL
+const noteLockTTL = 2 * time.Minute
+
+// NoteLocks stores the edit locks of notes; they are advisory and only kept in memory
+type NoteLocks struct {
+	sync.Mutex
+	locks map[string]noteLock // owner + ":" + note UID -> lock
+}
+
+type noteLock struct {
+	holder    string // Session (or API token) that claimed the lock, hashed
+	username  string // Shown to other clients as "being edited by"
+	expiresAt time.Time
+}
+
+var noteEditLocks = &NoteLocks{
+	locks: make(map[string]noteLock),
+}
+
+// Claim takes or renews the lock for a holder
+// When another holder has a lock that hasn't expired, that lock is returned with false
+func (l *NoteLocks) Claim(key, holder, username string) (noteLock, bool) {
+	l.Lock()
+	defer l.Unlock()
+
+	// Drop expired locks
+	now := time.Now()
+	for k, v := range l.locks {
+		if now.After(v.expiresAt) {
+			delete(l.locks, k)
+		}
+	}
+
+	if lock, ok := l.locks[key]; ok && lock.holder != holder {
+		return lock, false
+	}
+
+	lock := noteLock{
+		holder:    holder,
+		username:  username,
+		expiresAt: now.Add(noteLockTTL),
+	}
+	l.locks[key] = lock
+	return lock, true
+}
+
+// Release removes the lock of a holder
+// When another holder has the lock, that lock is returned with false
+func (l *NoteLocks) Release(key, holder string) (noteLock, bool) {
+	l.Lock()
+	defer l.Unlock()
+
+	lock, ok := l.locks[key]
+	if !ok || time.Now().After(lock.expiresAt) {
+		delete(l.locks, key)
+		return noteLock{}, true
+	}
+	if lock.holder != holder {
+		return lock, false
+	}
+	delete(l.locks, key)
+	return noteLock{}, true
+}
+
+// lockHolder identifies the session of a request: the session cookie, the API token,
+// or the client address when authentication is disabled
+func lockHolder(c *gin.Context) string {
+	holder := c.ClientIP()
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		holder = strings.TrimSpace(token)
+	} else if cookie, err := c.Cookie(middleware.SessionCookieName); err == nil {
+		holder = cookie
+	}
+	sum := sha256.Sum256([]byte(holder))
+	return hex.EncodeToString(sum[:8])
+}
+
+// noteLockTarget finds the note a lock request is for and returns its lock key,
+// responding with an error if it can't be locked
+func (h *NoteHandler) noteLockTarget(c *gin.Context) (string, string, bool) {
+	id := h.resolveNoteID(c, decodeNoteID(c.Param("id")))
+	if h.denyNote(c, id, model.PermissionWrite) {
+		return "", "", false
+	}
+
+	filePath, note := h.findNote(h.getNotesPath(c), id, h.getEncryptionKey(c))
+	if note == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return "", "", false
+	}
+	return noteLockOwner(c) + ":" + noteFileUID(filePath, note), id, true
+}
+
+// noteLockOwner returns the user whose notes are locked (the folder owner for shared folders)
+func noteLockOwner(c *gin.Context) string {
+	if user := storageOwner(c); user != nil {
+		return user.Username
+	}
+	return "default" // Used when auth is disabled
+}
+
+// Lock claims the edit lock of a note for the session, or renews it
+// Other clients of the note's owner are told over WebSocket who is editing it
+// Responds 423 with the holder when another session is editing the note
+func (h *NoteHandler) Lock(c *gin.Context) {
+	key, id, ok := h.noteLockTarget(c)
+	if !ok {
+		return
+	}
+
+	username := ""
+	if user := middleware.GetCurrentUser(c); user != nil {
+		username = user.Username
+	}
+
+	holder := lockHolder(c)
+	lock, claimed := noteEditLocks.Claim(key, holder, username)
+	if !claimed {
+		c.JSON(http.StatusLocked, gin.H{
+			"error":      "Note is being edited by another user",
+			"locked_by":  lock.username,
+			"expires_at": lock.expiresAt,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"lock_id":    holder,
+		"locked_by":  lock.username,
+		"expires_at": lock.expiresAt,
+	})
+
+	h.broadcastNoteLock(c, websocket.MsgTypeNoteLocked, id, gin.H{
+		"lock_id":    holder,
+		"locked_by":  lock.username,
+		"expires_at": lock.expiresAt,
+	})
+}
+
+// Unlock releases the edit lock of a note held by the session
+func (h *NoteHandler) Unlock(c *gin.Context) {
+	key, id, ok := h.noteLockTarget(c)
+	if !ok {
+		return
+	}
+
+	holder := lockHolder(c)
+	lock, released := noteEditLocks.Release(key, holder)
+	if !released {
+		c.JSON(http.StatusLocked, gin.H{
+			"error":      "Note is being edited by another user",
+			"locked_by":  lock.username,
+			"expires_at": lock.expiresAt,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Lock released"})
+
+	h.broadcastNoteLock(c, websocket.MsgTypeNoteUnlocked, id, gin.H{"lock_id": holder})
+}
+
+// broadcastNoteLock tells the clients of the note's owner that a lock was claimed or released
+func (h *NoteHandler) broadcastNoteLock(c *gin.Context, msgType string, noteID string, data gin.H) {
+	if h.wsHub == nil {
+		return
+	}
+	h.wsHub.BroadcastToUser(noteLockOwner(c), websocket.Message{
+		Type:   msgType,
+		NoteID: noteID,
+		Data:   data,
+	})
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"archive/zip"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/vault"
+	"github.com/user/gitnotepad/internal/websocket"
+)
+
+// defaultMarkdownFolder receives imported markdown notes when neither a folder is given
+// nor the archive is a single zipped folder (whose name is used then)
+const defaultMarkdownFolder = "Markdown Import"
+
+// ImportMarkdown imports a ZIP of a markdown folder, such as an Obsidian vault, into a folder
+// Subfolders are kept, the title comes from front matter or the file name, dates from front matter or the
+// file, and relative links to images and other files are rewritten to uploaded attachments
+func (h *NoteHandler) ImportMarkdown(c *gin.Context) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file provided"})
+		return
+	}
+	defer file.Close()
+
+	zipReader, err := zip.NewReader(file, header.Size)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ZIP file"})
+		return
+	}
+	archive, err := vault.Read(zipReader)
+	if err != nil || len(archive.Notes) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No markdown notes found in archive"})
+		return
+	}
+
+	folder := c.PostForm("folder")
+	if folder == "" {
+		folder = strings.TrimSuffix(archive.Root, "/")
+	}
+	if folder == "" {
+		folder = defaultMarkdownFolder
+	}
+	folderPath, ok := cleanFolderPath(folder)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+		return
+	}
+	if denyShare(c, folderPath, model.PermissionWrite) {
+		return
+	}
+
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+	folderPath = canonicalFolderPath(notesPath, folderPath)
+	targetDir := filepath.Join(notesPath, folderPath)
+
+	username := "shared"
+	filesPath := filepath.Join(h.basePath, "files")
+	if user := storageOwner(c); user != nil {
+		username = user.Username
+		filesPath = filepath.Join(h.basePath, user.Username, "files")
+	}
+	images := NewImageHandler(h.basePath, h.config.Server.BasePath, h.config.Upload)
+
+	// Files linked from several notes are uploaded once
+	uploaded := make(map[*zip.File]model.Attachment)
+	upload := func(f *zip.File) (model.Attachment, bool) {
+		if att, ok := uploaded[f]; ok {
+			return att, true
+		}
+		att, err := saveZipAttachment(f, path.Base(f.Name), "", filesPath, username, h.config.Server.BasePath)
+		if err != nil {
+			encoding.Warn("Markdown import: failed to save attachment %s: %v", f.Name, err)
+			return model.Attachment{}, false
+		}
+		images.saveMetadata(username, path.Base(att.URL), att.Name)
+		uploaded[f] = att
+		return att, true
+	}
+
+	imported, failed := 0, 0
+	for _, vn := range archive.Notes {
+		noteFolder := folderPath
+		if vn.Dir != "" {
+			if sub, ok := cleanFolderPath(folderPath + "/" + vn.Dir); ok {
+				noteFolder = canonicalFolderPath(notesPath, sub)
+			}
+		}
+		noteDir := filepath.Join(notesPath, noteFolder)
+		if err := os.MkdirAll(noteDir, 0755); err != nil {
+			encoding.Warn("Markdown import: failed to create folder %s: %v", noteFolder, err)
+			failed++
+			continue
+		}
+
+		var attachments []model.Attachment
+		content := vn.RewriteLinks(func(f *zip.File) (string, bool) {
+			att, ok := upload(f)
+			if !ok {
+				return "", false
+			}
+			for _, existing := range attachments {
+				if existing.URL == att.URL {
+					return att.URL, true
+				}
+			}
+			attachments = append(attachments, att)
+			return att.URL, true
+		})
+
+		title := normalizeName(vn.Title)
+		id := generateID()
+		note := &model.Note{
+			ID:          noteFolder + "/" + id,
+			UID:         id,
+			FolderPath:  noteFolder,
+			Title:       title,
+			Content:     content,
+			Type:        "markdown",
+			Tags:        vn.Tags,
+			Aliases:     vn.Aliases,
+			Attachments: attachments,
+			Created:     vn.Created,
+			Modified:    vn.Modified,
+		}
+
+		filePath, _ := filepath.Abs(filepath.Join(noteDir, id+note.GetExtension()))
+		if err := h.saveNoteToFile(note, filePath, encryptionKey); err != nil {
+			encoding.Warn("Markdown import: failed to save %q: %v", title, err)
+			failed++
+			continue
+		}
+		h.noteIndex.Put(notesPath, note.UID, note.ID)
+		imported++
+	}
+
+	// Single commit for the whole import
+	if imported > 0 {
+		if userRepo, err := h.getUserRepo(c); err == nil {
+			message := fmt.Sprintf("Import %d markdown notes", imported)
+			if err := userRepo.AddPathsAndCommit([]string{targetDir}, message); err != nil {
+				encoding.Debug("Git commit error: %v", err)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"imported":    imported,
+		"failed":      failed,
+		"attachments": len(uploaded),
+		"folder_path": folderPath,
+	})
+
+	if imported > 0 {
+		h.broadcastNoteChange(c, websocket.MsgTypeNotesRefresh, "")
+	}
+}
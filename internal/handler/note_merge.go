@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/encryption"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/websocket"
+)
+
+// DuplicateGroup is a set of notes sharing the same title
+type DuplicateGroup struct {
+	Title string         `json:"title"`
+	Notes []NoteListItem `json:"notes"`
+}
+
+// ListDuplicates returns groups of notes with identical titles (case-insensitive, NFC)
+// Common after Telegram captures and imports
+func (h *NoteHandler) ListDuplicates(c *gin.Context) {
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+
+	groups := make(map[string]*DuplicateGroup)
+
+	filepath.WalkDir(notesPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		ext := filepath.Ext(path)
+		if ext != ".md" && ext != ".txt" && ext != ".adoc" {
+			return nil
+		}
+
+		rawContent, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		note, err := h.loadNoteFromBytes(rawContent, path, encryptionKey)
+		if err != nil {
+			return nil
+		}
+
+		title := strings.TrimSpace(normalizeName(note.Title))
+		if title == "" {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(notesPath, path)
+		if err != nil {
+			return nil
+		}
+		id := strings.TrimSuffix(filepath.ToSlash(relPath), ext)
+
+		if folder, _ := splitFolderPath(id); !middleware.HasSharePermission(c, folder, model.PermissionRead) {
+			return nil
+		}
+
+		key := strings.ToLower(title)
+		group, ok := groups[key]
+		if !ok {
+			group = &DuplicateGroup{Title: title}
+			groups[key] = group
+		}
+		group.Notes = append(group.Notes, NoteListItem{
+			ID:         id,
+			UID:        note.UID,
+			FolderPath: note.FolderPath,
+			Title:      note.Title,
+			Type:       note.Type,
+			Icon:       note.Icon,
+			Tags:       note.Tags,
+			Aliases:    note.Aliases,
+			Private:    note.Private,
+			Encrypted:  encryption.IsEncrypted(string(rawContent)),
+			Created:    note.Created,
+			Modified:   note.Modified,
+		})
+		return nil
+	})
+
+	result := []DuplicateGroup{}
+	for _, group := range groups {
+		if len(group.Notes) < 2 {
+			continue
+		}
+		// Oldest first: the first note is the natural merge target
+		sort.Slice(group.Notes, func(i, j int) bool {
+			return group.Notes[i].Created.Before(group.Notes[j].Created)
+		})
+		group.Title = group.Notes[0].Title
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return strings.ToLower(result[i].Title) < strings.ToLower(result[j].Title)
+	})
+
+	c.JSON(http.StatusOK, result)
+}
+
+// MergeNotesRequest represents the request to merge notes into a target note
+type MergeNotesRequest struct {
+	TargetID  string   `json:"target_id" binding:"required"`
+	SourceIDs []string `json:"source_ids" binding:"required,min=1"`
+}
+
+// MergeNotes appends the contents of source notes to the target note, unions attachments,
+// tags and aliases, and deletes the sources in a single commit
+func (h *NoteHandler) MergeNotes(c *gin.Context) {
+	var req MergeNotesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+
+	type mergeNote struct {
+		id   string
+		path string
+		note *model.Note
+	}
+
+	// Load target and sources, checking permissions before modifying anything
+	load := func(rawID string) (*mergeNote, bool) {
+		id := h.resolveNoteID(c, normalizeName(rawID))
+		if folder, _ := splitFolderPath(id); denyShare(c, folder, model.PermissionWrite) {
+			return nil, false
+		}
+		filePath, note := h.findNote(notesPath, id, encryptionKey)
+		if note == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Note not found: " + rawID})
+			return nil, false
+		}
+		if note.Private {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Private notes cannot be merged: " + note.Title})
+			return nil, false
+		}
+		note.ID = id
+		return &mergeNote{id: id, path: filePath, note: note}, true
+	}
+
+	target, ok := load(req.TargetID)
+	if !ok {
+		return
+	}
+
+	var sources []*mergeNote
+	seen := map[string]bool{target.id: true}
+	for _, rawID := range req.SourceIDs {
+		source, ok := load(rawID)
+		if !ok {
+			return
+		}
+		if seen[source.id] {
+			continue
+		}
+		seen[source.id] = true
+		sources = append(sources, source)
+	}
+	if len(sources) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No notes to merge"})
+		return
+	}
+
+	// Merge in creation order
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].note.Created.Before(sources[j].note.Created)
+	})
+
+	merged := target.note
+	attachmentURLs := make(map[string]bool)
+	for _, att := range merged.Attachments {
+		attachmentURLs[att.URL] = true
+	}
+	tagSet := make(map[string]bool)
+	for _, tag := range merged.Tags {
+		tagSet[tag] = true
+	}
+
+	for _, source := range sources {
+		content := strings.TrimSpace(source.note.Content)
+		if content != "" {
+			if strings.TrimSpace(merged.Content) != "" {
+				merged.Content = strings.TrimRight(merged.Content, "\n") + "\n\n" + content
+			} else {
+				merged.Content = content
+			}
+		}
+		for _, att := range source.note.Attachments {
+			if !attachmentURLs[att.URL] {
+				attachmentURLs[att.URL] = true
+				merged.Attachments = append(merged.Attachments, att)
+			}
+		}
+		for _, tag := range source.note.Tags {
+			if !tagSet[tag] {
+				tagSet[tag] = true
+				merged.Tags = append(merged.Tags, tag)
+			}
+		}
+		merged.Aliases = append(merged.Aliases, source.note.Aliases...)
+		if source.note.Created.Before(merged.Created) {
+			merged.Created = source.note.Created
+		}
+	}
+	merged.Aliases = normalizeAliases(merged.Aliases)
+	merged.Modified = time.Now()
+	if merged.UID == "" {
+		merged.UID = newNoteUID(target.id)
+	}
+
+	if err := h.saveNoteToFile(merged, target.path, encryptionKey); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	changedPaths := []string{target.path}
+	for _, source := range sources {
+		if err := os.Remove(source.path); err != nil {
+			encoding.Warn("Failed to remove merged note %s: %v", source.id, err)
+			continue
+		}
+		changedPaths = append(changedPaths, source.path)
+		if source.note.UID != "" {
+			h.noteIndex.Remove(notesPath, source.note.UID)
+		}
+	}
+
+	// Single commit for the whole merge
+	if userRepo, err := h.getUserRepo(c); err == nil {
+		message := fmt.Sprintf("Merge %d notes into: %s", len(sources), merged.Title)
+		if err := userRepo.AddPathsAndCommit(changedPaths, message); err != nil {
+			encoding.Debug("Git commit error: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"note":         merged,
+		"merged_notes": len(changedPaths) - 1,
+	})
+
+	h.broadcastNoteChange(c, websocket.MsgTypeNotesRefresh, "")
+}
@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/index"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/websocket"
+)
+
+// maxSyncOps limits the queued writes replayed in one request
+const maxSyncOps = 200
+
+// Offline returns what the web app keeps for reading without a connection:
+// the list of all notes (metadata only) and the content of the most recently modified ones
+// Query: limit (notes with content, default 50, max 200). Private notes never include content.
+func (h *NoteHandler) Offline(c *gin.Context) {
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l >= 0 {
+		limit = l
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	loc := middleware.Location(c)
+
+	cursor := ""
+	if userRepo, err := h.getUserRepo(c); err == nil {
+		cursor, _ = userRepo.Head()
+	}
+
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+	var entries []index.Entry
+	for _, entry := range h.noteIndex.Entries(notesPath, func(path string, data []byte) (*model.Note, error) {
+		return h.loadNoteFromBytes(data, path, encryptionKey)
+	}) {
+		if folder, _ := splitFolderPath(entry.ID); middleware.HasSharePermission(c, folder, model.PermissionRead) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Modified.After(entries[j].Modified)
+	})
+
+	list := make([]NoteListItem, 0, len(entries))
+	recent := []*model.Note{}
+	for _, entry := range entries {
+		list = append(list, noteListItemFromEntry(entry, loc))
+		if len(recent) >= limit || entry.Private {
+			continue
+		}
+		if _, note := h.findNote(notesPath, entry.ID, encryptionKey); note != nil {
+			note.ID = entry.ID
+			note.In(loc)
+			recent = append(recent, note)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cursor":    cursor,
+		"generated": time.Now().In(loc),
+		"notes":     list,
+		"recent":    recent,
+	})
+}
+
+// SyncOp is a write made while offline, replayed in the order it was made
+type SyncOp struct {
+	Op           string     `json:"op" binding:"required"` // create, update or delete
+	ClientID     string     `json:"client_id"`             // Returned with the result so the client can match it
+	ID           string     `json:"id"`                    // update, delete
+	UID          string     `json:"uid"`                   // Preferred over id: still finds the note after a move
+	BaseModified *time.Time `json:"base_modified"`         // Modified time of the note the edit was based on
+	FolderPath   string     `json:"folder_path"`           // create
+	Title        string     `json:"title"`
+	Content      *string    `json:"content"`
+	Type         string     `json:"type"`
+	Tags         []string   `json:"tags"`
+}
+
+// SyncPushRequest is the queue of offline writes
+type SyncPushRequest struct {
+	Ops []SyncOp `json:"ops"`
+}
+
+// SyncResult reports what happened to one replayed write
+// status: applied, conflict (the note changed on the server; the edit was kept as a copy),
+// not_found, forbidden or error
+type SyncResult struct {
+	ClientID   string        `json:"client_id,omitempty"`
+	Op         string        `json:"op"`
+	Status     string        `json:"status"`
+	Note       *NoteListItem `json:"note,omitempty"`        // The note written (the copy for conflicts)
+	ConflictID string        `json:"conflict_id,omitempty"` // The note that changed on the server
+	TrashID    string        `json:"trash_id,omitempty"`    // Where a deleted note went, for POST /api/trash/:id/restore
+	Error      string        `json:"error,omitempty"`
+}
+
+// SyncPush replays writes queued while offline and returns the result of each with the new cursor
+// An update or delete is only applied when the note has not changed on the server since base_modified;
+// otherwise an update is saved as a new "(offline copy)" note next to it and a delete is skipped.
+func (h *NoteHandler) SyncPush(c *gin.Context) {
+	var req SyncPushRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Ops) > maxSyncOps {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Too many operations: max %d", maxSyncOps)})
+		return
+	}
+
+	results := make([]SyncResult, 0, len(req.Ops))
+	for _, op := range req.Ops {
+		result := h.replaySyncOp(c, op)
+		result.ClientID = op.ClientID
+		result.Op = op.Op
+		results = append(results, result)
+	}
+
+	cursor := ""
+	if userRepo, err := h.getUserRepo(c); err == nil {
+		cursor, _ = userRepo.Head()
+	}
+	c.JSON(http.StatusOK, gin.H{"cursor": cursor, "results": results})
+}
+
+// replaySyncOp applies one offline write
+func (h *NoteHandler) replaySyncOp(c *gin.Context, op SyncOp) SyncResult {
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+	loc := middleware.Location(c)
+	encrypted := h.config.Encryption.Enabled && encryptionKey != nil
+
+	if op.Op == "create" {
+		folderPath := ""
+		if strings.Trim(op.FolderPath, "/ ") != "" {
+			var ok bool
+			if folderPath, ok = cleanFolderPath(normalizeName(op.FolderPath)); !ok {
+				return SyncResult{Status: "error", Error: "Invalid folder path"}
+			}
+		}
+		if !middleware.HasSharePermission(c, folderPath, model.PermissionWrite) {
+			return SyncResult{Status: "forbidden", Error: "Insufficient permission for shared folder"}
+		}
+		note, err := h.saveSyncNote(c, canonicalFolderPath(notesPath, folderPath), normalizeName(op.Title), op, "Create note")
+		if err != nil {
+			return SyncResult{Status: "error", Error: err.Error()}
+		}
+		item := noteListItemFromNote(note, encrypted, loc)
+		return SyncResult{Status: "applied", Note: &item}
+	}
+
+	if op.Op != "update" && op.Op != "delete" {
+		return SyncResult{Status: "error", Error: "Invalid op (create, update, delete)"}
+	}
+
+	id := op.ID
+	if op.UID != "" {
+		id = h.resolveNoteID(c, op.UID)
+	}
+	id = h.resolveNoteID(c, id)
+	folder, _ := splitFolderPath(id)
+	if !middleware.HasSharePermission(c, folder, model.PermissionWrite) {
+		return SyncResult{Status: "forbidden", Error: "Insufficient permission for shared folder"}
+	}
+	filePath, note := h.findNote(notesPath, id, encryptionKey)
+	if note == nil {
+		return SyncResult{Status: "not_found", Error: "Note not found"}
+	}
+	if note.Private {
+		return SyncResult{Status: "forbidden", Error: "Private notes cannot be changed offline"}
+	}
+
+	// The note changed on the server after the client last saw it
+	changed := op.BaseModified == nil || note.Modified.After(*op.BaseModified)
+
+	if op.Op == "delete" {
+		if changed {
+			return SyncResult{Status: "conflict", ConflictID: id, Error: "Note changed on the server"}
+		}
+		// Like DELETE /api/notes/:id, the note goes to the trash so it can be restored
+		userPath, _ := filepath.Abs(h.getUserStoragePath(c))
+		trashID, entryDir, err := moveToTrash(userPath, notesPath, id+filepath.Ext(filePath))
+		if err != nil {
+			return SyncResult{Status: "error", Error: "Failed to move note to trash"}
+		}
+		if userRepo, err := h.getUserRepo(c); err == nil {
+			if err := userRepo.AddPathsAndCommit([]string{filePath, entryDir}, fmt.Sprintf("Delete note: %s (to trash)", note.Title)); err != nil {
+				encoding.Debug("Git commit error: %v", err)
+			}
+		}
+		if note.UID != "" {
+			h.noteIndex.Remove(notesPath, note.UID)
+		}
+		h.removeDraft(c, noteFileUID(filePath, note))
+		h.broadcastNoteChange(c, websocket.MsgTypeNoteDeleted, id)
+		return SyncResult{Status: "applied", TrashID: trashID}
+	}
+
+	if op.Content == nil {
+		return SyncResult{Status: "error", Error: "Content is required"}
+	}
+
+	if changed {
+		// Keep both versions: the server's stays in place, the offline edit becomes a copy
+		title := normalizeName(op.Title)
+		if title == "" {
+			title = note.Title
+		}
+		if op.Type == "" {
+			op.Type = note.Type
+		}
+		copyNote, err := h.saveSyncNote(c, folder, title+" (offline copy)", op, "Offline copy")
+		if err != nil {
+			return SyncResult{Status: "error", Error: err.Error()}
+		}
+		item := noteListItemFromNote(copyNote, encrypted, loc)
+		return SyncResult{Status: "conflict", Note: &item, ConflictID: id}
+	}
+
+	if title := normalizeName(op.Title); title != "" {
+		note.Title = title
+	}
+	note.Content = *op.Content
+	if op.Tags != nil {
+		note.Tags = op.Tags
+	}
+	note.Modified = time.Now()
+	if note.UID == "" {
+		note.UID = newNoteUID(id)
+	}
+	if err := h.saveNoteToFile(note, filePath, encryptionKey); err != nil {
+		return SyncResult{Status: "error", Error: err.Error()}
+	}
+	if userRepo, err := h.getUserRepo(c); err == nil {
+		if err := userRepo.AddAndCommit(filePath, fmt.Sprintf("Update note: %s", note.Title)); err != nil {
+			encoding.Debug("Git commit error: %v", err)
+		}
+	}
+	note.ID = id
+	note.FolderPath = folder
+	h.noteIndex.Put(notesPath, note.UID, note.ID)
+	h.broadcastNoteChange(c, websocket.MsgTypeNoteUpdated, note.ID)
+
+	item := noteListItemFromNote(note, encrypted, loc)
+	return SyncResult{Status: "applied", Note: &item}
+}
+
+// saveSyncNote creates a note from an offline write in a folder and commits it
+func (h *NoteHandler) saveSyncNote(c *gin.Context, folderPath, title string, op SyncOp, action string) (*model.Note, error) {
+	notesPath := h.getNotesPath(c)
+	targetDir := filepath.Join(notesPath, folderPath)
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return nil, errors.New("Failed to create folder")
+	}
+
+	if op.Type == "" {
+		op.Type = h.config.Editor.DefaultType
+	}
+	content := ""
+	if op.Content != nil {
+		content = *op.Content
+	}
+
+	id := generateID()
+	now := time.Now()
+	note := &model.Note{
+		ID:         strings.TrimPrefix(folderPath+"/"+id, "/"),
+		UID:        id,
+		FolderPath: folderPath,
+		Title:      title,
+		Content:    content,
+		Type:       op.Type,
+		Tags:       op.Tags,
+		Created:    now,
+		Modified:   now,
+	}
+
+	filePath, _ := filepath.Abs(filepath.Join(targetDir, id+note.GetExtension()))
+	if err := h.saveNoteToFile(note, filePath, h.getEncryptionKey(c)); err != nil {
+		return nil, err
+	}
+	if userRepo, err := h.getUserRepo(c); err == nil {
+		if err := userRepo.AddAndCommit(filePath, fmt.Sprintf("%s: %s", action, note.Title)); err != nil {
+			encoding.Debug("Git commit error: %v", err)
+		}
+	}
+	h.noteIndex.Put(notesPath, note.UID, note.ID)
+	h.broadcastNoteChange(c, websocket.MsgTypeNoteCreated, note.ID)
+	return note, nil
+}
+
+// noteListItemFromNote returns the list entry of a loaded note
+func noteListItemFromNote(note *model.Note, encrypted bool, loc *time.Location) NoteListItem {
+	return NoteListItem{
+		ID:         note.ID,
+		UID:        note.UID,
+		FolderPath: note.FolderPath,
+		Title:      note.Title,
+		Type:       note.Type,
+		Icon:       note.Icon,
+		Tags:       note.Tags,
+		Aliases:    note.Aliases,
+		Private:    note.Private,
+		Encrypted:  encrypted,
+		Due:        note.Due,
+		Created:    note.Created.In(loc),
+		Modified:   note.Modified.In(loc),
+	}
+}
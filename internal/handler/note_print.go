@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// Print renders a note as a standalone page styled for paper, so the browser's
+// "print to PDF" produces a clean document (?print=1 opens the print dialog)
+func (h *NoteHandler) Print(c *gin.Context) {
+	id := h.resolveNoteID(c, decodeNoteID(c.Param("id")))
+	if h.denyNote(c, id, model.PermissionRead) {
+		return
+	}
+	_, note := h.findNote(h.getNotesPath(c), id, h.getEncryptionKey(c))
+	if note == nil {
+		c.String(http.StatusNotFound, tr(c, "Note not found"))
+		return
+	}
+	// The page is opened in a new window, which cannot send the X-Note-Password header
+	if note.Private {
+		c.String(http.StatusForbidden, tr(c, "This note is password protected"))
+		return
+	}
+
+	c.HTML(http.StatusOK, "print.html", printPage(c, h.config.Server.BasePath, note))
+}
+
+// printPage is the data of print.html
+func printPage(c *gin.Context, basePath string, note *model.Note) gin.H {
+	return gin.H{
+		"basePath": basePath,
+		"lang":     middleware.Language(c),
+		"title":    note.Title,
+		"type":     note.Type,
+		"content":  note.Content,
+		"tags":     note.Tags,
+		"modified": note.Modified,
+	}
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/index"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// RecentNote is a note list item with the time the current user last opened it
+type RecentNote struct {
+	NoteListItem
+	Viewed *time.Time `json:"viewed,omitempty"`
+}
+
+// Recent returns the most recently modified or viewed notes from the metadata index
+// Query: by=modified|viewed (default modified), limit (default 10, max 100), fields (JSON keys to keep)
+func (h *NoteHandler) Recent(c *gin.Context) {
+	by := c.DefaultQuery("by", "modified")
+	if by != "modified" && by != "viewed" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid by (modified, viewed)"})
+		return
+	}
+
+	limit := 10
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	loc := middleware.Location(c)
+
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+	entries := h.noteIndex.Entries(notesPath, func(path string, data []byte) (*model.Note, error) {
+		return h.loadNoteFromBytes(data, path, encryptionKey)
+	})
+
+	// Only notes inside folders shared with the current user
+	visible := make(map[string]index.Entry, len(entries))
+	for _, entry := range entries {
+		if folder, _ := splitFolderPath(entry.ID); middleware.HasSharePermission(c, folder, model.PermissionRead) {
+			visible[entry.UID] = entry
+		}
+	}
+
+	result := []RecentNote{}
+
+	if by == "modified" {
+		sorted := make([]index.Entry, 0, len(visible))
+		for _, entry := range visible {
+			sorted = append(sorted, entry)
+		}
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i].Modified.After(sorted[j].Modified)
+		})
+		for _, entry := range sorted {
+			if len(result) >= limit {
+				break
+			}
+			result = append(result, RecentNote{NoteListItem: noteListItemFromEntry(entry, loc)})
+		}
+		c.JSON(http.StatusOK, trimFields(result, requestedFields(c)))
+		return
+	}
+
+	user := middleware.GetCurrentUser(c)
+	if user == nil || h.db == nil {
+		c.JSON(http.StatusOK, result) // View history is only kept for logged-in users
+		return
+	}
+
+	rows, err := h.db.Query(
+		"SELECT note_uid, viewed_at FROM note_views WHERE user_id = ? ORDER BY viewed_at DESC",
+		user.ID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recently viewed notes"})
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() && len(result) < limit {
+		var uid string
+		var viewedAt time.Time
+		if err := rows.Scan(&uid, &viewedAt); err != nil {
+			continue
+		}
+		entry, ok := visible[uid]
+		if !ok {
+			continue // Deleted, or not in this user's (shared) notes
+		}
+		result = append(result, RecentNote{NoteListItem: noteListItemFromEntry(entry, loc), Viewed: &viewedAt})
+	}
+
+	c.JSON(http.StatusOK, trimFields(result, requestedFields(c)))
+}
+
+// recordView stores the time the current user opened a note
+func (h *NoteHandler) recordView(c *gin.Context, note *model.Note) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil || h.db == nil {
+		return
+	}
+
+	// Notes without a UID are indexed by filename
+	uid := note.UID
+	if uid == "" {
+		uid = filepath.Base(note.ID)
+	}
+
+	_, err := h.db.Exec(
+		`INSERT INTO note_views (user_id, note_uid, viewed_at) VALUES (?, ?, ?)
+		 ON CONFLICT(user_id, note_uid) DO UPDATE SET viewed_at = excluded.viewed_at`,
+		user.ID, uid, time.Now(),
+	)
+	if err != nil {
+		encoding.Debug("Failed to record note view: %v", err)
+	}
+}
+
+// noteListItemFromEntry converts an index entry to a note list item
+func noteListItemFromEntry(entry index.Entry, loc *time.Location) NoteListItem {
+	return NoteListItem{
+		ID:         entry.ID,
+		UID:        entry.UID,
+		FolderPath: entry.FolderPath,
+		Title:      entry.Title,
+		Type:       entry.Type,
+		Icon:       entry.Icon,
+		Tags:       entry.Tags,
+		Aliases:    entry.Aliases,
+		Private:    entry.Private,
+		Archived:   entry.Archived,
+		Encrypted:  entry.Encrypted,
+		Due:        entry.Due,
+		Created:    entry.Created.In(loc),
+		Modified:   entry.Modified.In(loc),
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// noteRevision returns the revision of a note file: a hash of its bytes on disk
+// Unlike the ETag of Get it doesn't depend on the representation, so it can be sent back in If-Match
+func noteRevision(filePath string) string {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// setNoteRevision sets the X-Note-Revision header from a note file
+func setNoteRevision(c *gin.Context, filePath string) {
+	if revision := noteRevision(filePath); revision != "" {
+		c.Header("X-Note-Revision", revision)
+	}
+}
+
+// revisionMismatch responds 412 when the request has an If-Match header that doesn't match
+// the note's current revision, i.e. the note was saved by another tab or device after the client loaded it
+// Requests without If-Match are not checked, so older clients and scripts keep working
+func revisionMismatch(c *gin.Context, filePath string) bool {
+	match := c.GetHeader("If-Match")
+	if match == "" {
+		return false
+	}
+
+	current := noteRevision(filePath)
+	for _, candidate := range strings.Split(match, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == current || `"`+candidate+`"` == current {
+			return false
+		}
+	}
+
+	c.Header("X-Note-Revision", current)
+	c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Note was changed since it was loaded"})
+	return true
+}
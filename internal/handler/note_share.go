@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// ShareNoteRequest represents the request to share a note with another user
+type ShareNoteRequest struct {
+	Username   string                `json:"username" binding:"required"`
+	Permission model.SharePermission `json:"permission"` // read (default) or write
+}
+
+// SharedNote is a note another user shared with the current user
+type SharedNote struct {
+	ShareID    int64                 `json:"share_id"`
+	Owner      string                `json:"owner"`
+	ID         string                `json:"id"`
+	UID        string                `json:"uid"`
+	Title      string                `json:"title"`
+	Type       string                `json:"type"`
+	Permission model.SharePermission `json:"permission"`
+	Modified   time.Time             `json:"modified"`
+	SharedAt   time.Time             `json:"shared_at"`
+}
+
+// denyNote is denyShare for the folder of a note, also letting through a note shared with the user on its own
+func (h *NoteHandler) denyNote(c *gin.Context, id string, required model.SharePermission) bool {
+	folder, _ := splitFolderPath(id)
+	if middleware.HasSharePermission(c, folder, required) || h.isSharedNote(c, id, required) {
+		return false
+	}
+	return denyShare(c, folder, required)
+}
+
+// isSharedNote reports whether the note is shared on its own with the current user with the required permission
+func (h *NoteHandler) isSharedNote(c *gin.Context, id string, required model.SharePermission) bool {
+	uids := middleware.SharedNoteUIDs(c, required)
+	if len(uids) == 0 {
+		return false
+	}
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+	for _, uid := range uids {
+		resolved, ok := h.noteIndex.Resolve(notesPath, uid, func(path string, data []byte) (*model.Note, error) {
+			return h.loadNoteFromBytes(data, path, encryptionKey)
+		})
+		if ok && resolved == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ShareNote shares a note with another user, or changes the permission of an existing share
+// The other user then finds it under GET /api/shared-with-me and opens it with ?owner=
+func (h *NoteHandler) ShareNote(c *gin.Context) {
+	owner := storageOwner(c)
+	if owner == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	id := h.resolveNoteID(c, decodeNoteID(c.Param("id")))
+	if folder, _ := splitFolderPath(id); denyShare(c, folder, model.PermissionManage) {
+		return
+	}
+
+	var req ShareNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Permission == "" {
+		req.Permission = model.PermissionRead
+	}
+	if req.Permission != model.PermissionRead && req.Permission != model.PermissionWrite {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid permission (read, write)"})
+		return
+	}
+
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+	filePath, note := h.findNote(notesPath, id, encryptionKey)
+	if note == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+
+	grantee, err := h.users.GetByUsername(req.Username)
+	if err != nil || grantee == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if grantee.ID == owner.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot share with the note owner"})
+		return
+	}
+
+	// The share follows the note by its UID, which older notes don't have yet
+	if note.UID == "" {
+		note.UID = newNoteUID(id)
+		if err := h.saveNoteToFile(note, filePath, encryptionKey); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update note"})
+			return
+		}
+		if userRepo, err := h.getUserRepo(c); err == nil {
+			if err := userRepo.AddAndCommit(filePath, fmt.Sprintf("Update note: %s", note.Title)); err != nil {
+				encoding.Debug("Git commit error: %v", err)
+			}
+		}
+		h.noteIndex.Put(notesPath, note.UID, id)
+	}
+
+	share := &model.NoteShare{
+		OwnerID:         owner.ID,
+		OwnerUsername:   owner.Username,
+		NoteUID:         note.UID,
+		GranteeID:       grantee.ID,
+		GranteeUsername: grantee.Username,
+		Permission:      req.Permission,
+	}
+	if err := h.shares.UpsertNote(share); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save share"})
+		return
+	}
+
+	currentUser := middleware.GetCurrentUser(c)
+	encoding.Info("Note shared: owner=%s, note=%s, with=%s, permission=%s, by=%s, ip=%s",
+		owner.Username, id, grantee.Username, share.Permission, currentUser.Username, c.ClientIP())
+	recordAudit(h.auditRepo, c, model.AuditShareCreate, owner.Username+":"+id,
+		fmt.Sprintf("with=%s, permission=%s", grantee.Username, share.Permission))
+
+	c.JSON(http.StatusOK, share)
+}
+
+// ListNoteShares returns the users a note is shared with
+func (h *NoteHandler) ListNoteShares(c *gin.Context) {
+	owner := storageOwner(c)
+	if owner == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	id := h.resolveNoteID(c, decodeNoteID(c.Param("id")))
+	if folder, _ := splitFolderPath(id); denyShare(c, folder, model.PermissionManage) {
+		return
+	}
+	_, note := h.findNote(h.getNotesPath(c), id, h.getEncryptionKey(c))
+	if note == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+
+	shares := []*model.NoteShare{}
+	if note.UID != "" {
+		list, err := h.shares.ListNoteSharesByNote(owner.ID, note.UID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list shares"})
+			return
+		}
+		shares = append(shares, list...)
+	}
+	c.JSON(http.StatusOK, shares)
+}
+
+// DeleteNoteShare removes a note share (owner, manager of the note's folder, or the grantee leaving the share)
+func (h *NoteHandler) DeleteNoteShare(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	shareID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share ID"})
+		return
+	}
+	share, err := h.shares.GetNoteShare(shareID)
+	if err != nil || share == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share not found"})
+		return
+	}
+
+	allowed := share.GranteeID == user.ID
+	if owner := storageOwner(c); !allowed && share.OwnerID == owner.ID {
+		id := h.resolveNoteID(c, share.NoteUID)
+		folder, _ := splitFolderPath(id)
+		allowed = middleware.HasSharePermission(c, folder, model.PermissionManage)
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission for shared note"})
+		return
+	}
+
+	if err := h.shares.DeleteNoteShare(shareID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete share"})
+		return
+	}
+
+	encoding.Info("Note share removed: owner=%s, note=%s, with=%s, by=%s, ip=%s",
+		share.OwnerUsername, share.NoteUID, share.GranteeUsername, user.Username, c.ClientIP())
+	recordAudit(h.auditRepo, c, model.AuditShareDelete, share.OwnerUsername+":"+share.NoteUID, "with="+share.GranteeUsername)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Share deleted"})
+}
+
+// SharedWithMe returns the notes and folders other users shared with the current user
+// Notes that were deleted, or can't be read because they are encrypted with their owner's key, are left out
+func (h *NoteHandler) SharedWithMe(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	folders, err := h.shares.ListByGrantee(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list shares"})
+		return
+	}
+	noteShares, err := h.shares.ListNoteSharesByGrantee(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list shares"})
+		return
+	}
+
+	loc := middleware.Location(c)
+	notes := []SharedNote{}
+	for _, share := range noteShares {
+		notesPath := filepath.Join(h.basePath, share.OwnerUsername, "notes")
+		id, ok := h.noteIndex.Resolve(notesPath, share.NoteUID, func(path string, data []byte) (*model.Note, error) {
+			return h.loadNoteFromBytes(data, path, nil)
+		})
+		if !ok {
+			continue
+		}
+		_, note := h.findNote(notesPath, id, nil)
+		if note == nil {
+			continue
+		}
+		notes = append(notes, SharedNote{
+			ShareID:    share.ID,
+			Owner:      share.OwnerUsername,
+			ID:         id,
+			UID:        share.NoteUID,
+			Title:      note.Title,
+			Type:       note.Type,
+			Permission: share.Permission,
+			Modified:   note.Modified.In(loc),
+			SharedAt:   share.CreatedAt.In(loc),
+		})
+	}
+	if folders == nil {
+		folders = []*model.FolderShare{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notes": notes, "folders": folders})
+}
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/database"
+	"github.com/user/gitnotepad/internal/middleware"
+)
+
+type NoteSortHandler struct {
+	db *database.DB
+}
+
+func NewNoteSortHandler(db *database.DB) *NoteSortHandler {
+	return &NoteSortHandler{db: db}
+}
+
+// NoteSort is how the notes of one folder are ordered
+type NoteSort struct {
+	Field     string `json:"field"`     // title, created or modified
+	Direction string `json:"direction"` // asc or desc
+}
+
+// NoteSortMap represents the note sort for all folders
+// Key: folder_path ("" for notes at the top level)
+type NoteSortMap map[string]NoteSort
+
+// valid reports whether the field and direction are known
+func (s NoteSort) valid() bool {
+	switch s.Field {
+	case "title", "created", "modified":
+	default:
+		return false
+	}
+	return s.Direction == "asc" || s.Direction == "desc"
+}
+
+// Get returns the note sort of every folder for the current user
+func (h *NoteSortHandler) Get(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		// Return empty map for unauthenticated users
+		c.JSON(http.StatusOK, make(NoteSortMap))
+		return
+	}
+
+	sorts, err := loadNoteSorts(h.db, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch note sort"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sorts)
+}
+
+// SetNoteSortRequest represents the request to set the note sort of a folder
+type SetNoteSortRequest struct {
+	FolderPath string `json:"folder_path"`
+	Field      string `json:"field" binding:"required"`
+	Direction  string `json:"direction" binding:"required"`
+}
+
+// Set creates or updates the note sort of a folder
+func (h *NoteSortHandler) Set(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req SetNoteSortRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !(NoteSort{Field: req.Field, Direction: req.Direction}).valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort (field: title, created, modified; direction: asc, desc)"})
+		return
+	}
+
+	_, err := h.db.Exec(
+		`INSERT INTO note_sort (user_id, folder_path, field, direction, updated_at) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(user_id, folder_path) DO UPDATE SET field = excluded.field, direction = excluded.direction, updated_at = excluded.updated_at`,
+		user.ID, strings.Trim(req.FolderPath, "/"), req.Field, req.Direction, time.Now(),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save note sort"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sort saved"})
+}
+
+// Delete removes the note sort of a folder (back to the default order)
+func (h *NoteSortHandler) Delete(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	_, err := h.db.Exec(
+		"DELETE FROM note_sort WHERE user_id = ? AND folder_path = ?",
+		user.ID, strings.Trim(c.Query("folder_path"), "/"),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete note sort"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sort deleted"})
+}
+
+// loadNoteSorts returns the stored note sort of every folder of a user
+func loadNoteSorts(db *database.DB, userID int64) (NoteSortMap, error) {
+	rows, err := db.Query(
+		"SELECT folder_path, field, direction FROM note_sort WHERE user_id = ?",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(NoteSortMap)
+	for rows.Next() {
+		var folderPath string
+		var s NoteSort
+		if err := rows.Scan(&folderPath, &s.Field, &s.Direction); err != nil {
+			continue
+		}
+		result[folderPath] = s
+	}
+	return result, rows.Err()
+}
+
+// renameNoteSort re-keys the note sort of a folder and its subfolders after a rename or move
+func renameNoteSort(db *database.DB, userID int64, oldPath, newPath string) error {
+	_, err := db.Exec(
+		`UPDATE note_sort SET folder_path = ? || substr(folder_path, ?), updated_at = ?
+		 WHERE user_id = ? AND (folder_path = ? OR folder_path LIKE ? ESCAPE '\')`,
+		newPath, utf8.RuneCountInString(oldPath)+1, time.Now(), userID, oldPath, escapeLike(oldPath)+"/%",
+	)
+	return err
+}
+
+// deleteNoteSort removes the note sort of a folder and its subfolders
+func deleteNoteSort(db *database.DB, userID int64, folderPath string) error {
+	_, err := db.Exec(
+		`DELETE FROM note_sort WHERE user_id = ? AND (folder_path = ? OR folder_path LIKE ? ESCAPE '\')`,
+		userID, folderPath, escapeLike(folderPath)+"/%",
+	)
+	return err
+}
+
+// noteOrder is the order of the notes list: with a valid override (?sort=) by that field across
+// all folders, otherwise grouped by folder with each folder's saved sort
+// Ties are broken by ID, so the order is total and pages of the list don't overlap
+type noteOrder struct {
+	sorts    NoteSortMap
+	override NoteSort
+}
+
+// less reports whether note a comes before note b
+func (o noteOrder) less(a, b NoteListItem) bool {
+	s, ok := o.override, o.override.valid()
+	if !ok {
+		parentA, _ := splitFolderPath(a.ID)
+		parentB, _ := splitFolderPath(b.ID)
+		if parentA != parentB {
+			return parentA < parentB
+		}
+		s, ok = o.sorts[a.FolderPath]
+	}
+	if ok {
+		first, second := a, b
+		if s.Direction == "desc" {
+			first, second = b, a
+		}
+		if lessNote(first, second, s.Field) {
+			return true
+		}
+		if lessNote(second, first, s.Field) {
+			return false
+		}
+	}
+	return a.ID < b.ID
+}
+
+// sortNoteList orders the notes list
+func sortNoteList(notes []NoteListItem, order noteOrder) {
+	sort.Slice(notes, func(i, j int) bool {
+		return order.less(notes[i], notes[j])
+	})
+}
+
+// noteCursor is where a page of the notes list ended: the fields of its last note that the order depends on
+type noteCursor struct {
+	ID         string    `json:"id"`
+	FolderPath string    `json:"folder_path,omitempty"`
+	Title      string    `json:"title,omitempty"`
+	Created    time.Time `json:"created"`
+	Modified   time.Time `json:"modified"`
+}
+
+// encodeNoteCursor returns the opaque ?cursor= value continuing the list after a note
+func encodeNoteCursor(note NoteListItem) string {
+	data, _ := json.Marshal(noteCursor{
+		ID:         note.ID,
+		FolderPath: note.FolderPath,
+		Title:      note.Title,
+		Created:    note.Created,
+		Modified:   note.Modified,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// notesAfterCursor returns the notes of a sorted list that come after a cursor
+// The cursor holds sort keys rather than a position, so notes added or deleted meanwhile don't shift pages
+func notesAfterCursor(notes []NoteListItem, order noteOrder, cursor string) ([]NoteListItem, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, false
+	}
+	var nc noteCursor
+	if err := json.Unmarshal(data, &nc); err != nil || nc.ID == "" {
+		return nil, false
+	}
+	last := NoteListItem{ID: nc.ID, FolderPath: nc.FolderPath, Title: nc.Title, Created: nc.Created, Modified: nc.Modified}
+	start := sort.Search(len(notes), func(i int) bool {
+		return order.less(last, notes[i])
+	})
+	return notes[start:], true
+}
+
+func lessNote(a, b NoteListItem, field string) bool {
+	switch field {
+	case "created":
+		return a.Created.Before(b.Created)
+	case "modified":
+		return a.Modified.Before(b.Modified)
+	default:
+		return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+	}
+}
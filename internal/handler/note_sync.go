@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/git"
+	"github.com/user/gitnotepad/internal/index"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// SyncDeleted identifies a note removed since the cursor
+type SyncDeleted struct {
+	ID  string `json:"id"`
+	UID string `json:"uid,omitempty"`
+}
+
+// Sync returns notes created, updated and deleted since a cursor (a commit of the user's repository)
+// Without since, all notes are returned as created. Moved notes appear as deleted and created with the same uid.
+// Responds 410 when the cursor is unknown; the client should sync again without since.
+// fields (e.g. "id,title,modified,icon") trims the notes to those JSON keys.
+func (h *NoteHandler) Sync(c *gin.Context) {
+	loc := middleware.Location(c)
+	fields := requestedFields(c)
+	userRepo, err := h.getUserRepo(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open repository"})
+		return
+	}
+	cursor, err := userRepo.Head()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read history"})
+		return
+	}
+
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+	entries := make(map[string]index.Entry)
+	for _, entry := range h.noteIndex.Entries(notesPath, func(path string, data []byte) (*model.Note, error) {
+		return h.loadNoteFromBytes(data, path, encryptionKey)
+	}) {
+		entries[entry.ID] = entry
+	}
+
+	visible := func(id string) bool {
+		folder, _ := splitFolderPath(id)
+		return middleware.HasSharePermission(c, folder, model.PermissionRead)
+	}
+
+	created := []NoteListItem{}
+	updated := []NoteListItem{}
+	deleted := []SyncDeleted{}
+
+	since := c.Query("since")
+	if since == "" || since == cursor {
+		if since == "" {
+			for id, entry := range entries {
+				if visible(id) {
+					created = append(created, noteListItemFromEntry(entry, loc))
+				}
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"cursor": cursor, "created": trimFields(created, fields), "updated": trimFields(updated, fields), "deleted": deleted})
+		return
+	}
+
+	changes, err := userRepo.ChangesSince(since)
+	if err != nil {
+		if errors.Is(err, git.ErrCommitNotFound) {
+			c.JSON(http.StatusGone, gin.H{"error": "Unknown cursor", "cursor": cursor})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read history"})
+		return
+	}
+
+	for _, change := range changes {
+		relPath, ok := strings.CutPrefix(change.Path, "notes/")
+		ext := path.Ext(relPath)
+		if !ok || (ext != ".md" && ext != ".txt" && ext != ".adoc") {
+			continue
+		}
+		id := strings.TrimSuffix(relPath, ext)
+		if !visible(id) {
+			continue
+		}
+
+		if change.Action == "deleted" {
+			// The UID lives in the deleted file's frontmatter
+			uid := path.Base(id)
+			filePath := filepath.Join(userRepo.GetPath(), filepath.FromSlash(change.Path))
+			if data, err := userRepo.GetFileAtCommit(filePath, since); err == nil {
+				if note, err := h.loadNoteFromBytes(data, filePath, encryptionKey); err == nil && note.UID != "" {
+					uid = note.UID
+				}
+			}
+			deleted = append(deleted, SyncDeleted{ID: id, UID: uid})
+			continue
+		}
+
+		entry, ok := entries[id]
+		if !ok {
+			continue // Removed from disk after the last commit
+		}
+		if change.Action == "added" {
+			created = append(created, noteListItemFromEntry(entry, loc))
+		} else {
+			updated = append(updated, noteListItemFromEntry(entry, loc))
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cursor": cursor, "created": trimFields(created, fields), "updated": trimFields(updated, fields), "deleted": deleted})
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/websocket"
+)
+
+// RestoreVersion rolls a note back to its content at a commit (as listed by /history)
+// The old version is saved as a new change (re-encrypted if encryption is enabled), so the
+// restore itself can be undone; a deleted note is recreated at its path
+func (h *NoteHandler) RestoreVersion(c *gin.Context) {
+	id := h.resolveNoteID(c, decodeNoteID(c.Param("id")))
+	if folder, _ := splitFolderPath(id); denyShare(c, folder, model.PermissionWrite) {
+		return
+	}
+	commit := c.Param("commit")
+	notesPath := h.getNotesPath(c)
+	encryptionKey := h.getEncryptionKey(c)
+
+	userRepo, err := h.getUserRepo(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to access repository"})
+		return
+	}
+
+	filePath, current := h.findNote(notesPath, id, encryptionKey)
+	var content []byte
+	if current != nil {
+		content, err = userRepo.GetFileAtCommit(filePath, commit)
+	} else {
+		for _, ext := range []string{".md", ".txt", ".adoc"} {
+			filePath, _ = filepath.Abs(filepath.Join(notesPath, id+ext))
+			if content, err = userRepo.GetFileAtCommit(filePath, commit); err == nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Version not found"})
+		return
+	}
+
+	note, err := h.loadNoteFromBytes(content, filePath, encryptionKey)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read version"})
+		return
+	}
+
+	// Check password for private notes (the current one, or the old version of a deleted note)
+	locked := note
+	if current != nil {
+		locked = current
+	}
+	if locked.Private && !locked.CheckPassword(c.GetHeader("X-Note-Password")) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		return
+	}
+
+	if current != nil && current.UID != "" {
+		note.UID = current.UID
+	}
+	note.ID = id
+	note.Modified = time.Now()
+
+	if err := h.saveNoteToFile(note, filePath, encryptionKey); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	shortHash := commit
+	if len(shortHash) > 7 {
+		shortHash = shortHash[:7]
+	}
+	if err := userRepo.AddAndCommit(filePath, fmt.Sprintf("Restore note to %s: %s", shortHash, note.Title)); err != nil {
+		encoding.Debug("Git commit error: %v", err)
+	}
+	h.noteIndex.Put(notesPath, note.UID, note.ID)
+
+	note.In(middleware.Location(c))
+	c.JSON(http.StatusOK, note)
+
+	msgType := websocket.MsgTypeNoteUpdated
+	if current == nil {
+		msgType = websocket.MsgTypeNoteCreated
+	}
+	h.broadcastNoteChange(c, msgType, note.ID)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/i18n"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/repository"
+)
+
+type PreferenceHandler struct {
+	prefRepo *repository.PreferenceRepository
+}
+
+func NewPreferenceHandler(prefRepo *repository.PreferenceRepository) *PreferenceHandler {
+	return &PreferenceHandler{prefRepo: prefRepo}
+}
+
+// UpdatePreferencesRequest changes the given preferences; omitted fields are kept
+type UpdatePreferencesRequest struct {
+	Language *string `json:"language"` // "en", "ko" or "" (follow the browser)
+	Timezone *string `json:"timezone"` // IANA time zone or "" (server time zone)
+}
+
+// Get returns the preferences of the current user
+func (h *PreferenceHandler) Get(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	prefs, err := h.prefRepo.Get(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// Update changes the preferences of the current user
+func (h *PreferenceHandler) Update(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	prefs, err := h.prefRepo.Get(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get preferences"})
+		return
+	}
+	if req.Language != nil {
+		if *req.Language != "" && !i18n.Supported(*req.Language) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid language"})
+			return
+		}
+		prefs.Language = *req.Language
+	}
+	if req.Timezone != nil {
+		if *req.Timezone != "" {
+			if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "Local" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid timezone"})
+				return
+			}
+		}
+		prefs.Timezone = *req.Timezone
+	}
+
+	if err := h.prefRepo.Save(user.ID, prefs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PWAHandler serves the web app manifest and service worker so the app can be installed
+// (e.g. on tablets) and opened without a connection
+type PWAHandler struct {
+	basePath string
+	static   fs.FS
+}
+
+// NewPWAHandler creates a handler serving the service worker from the embedded static files
+func NewPWAHandler(basePath string, static fs.FS) *PWAHandler {
+	return &PWAHandler{
+		basePath: basePath,
+		static:   static,
+	}
+}
+
+// Manifest returns the web app manifest
+func (h *PWAHandler) Manifest(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Header("Content-Type", "application/manifest+json")
+	c.JSON(http.StatusOK, gin.H{
+		"name":             "Git Notepad",
+		"short_name":       "Notepad",
+		"id":               h.basePath + "/",
+		"start_url":        h.basePath + "/",
+		"scope":            h.basePath + "/",
+		"display":          "standalone",
+		"background_color": "#ffffff",
+		"theme_color":      "#24292f",
+		"icons": []gin.H{
+			{"src": h.basePath + "/static/favicon.svg", "sizes": "any", "type": "image/svg+xml", "purpose": "any"},
+		},
+	})
+}
+
+// ServiceWorker serves sw.js from the base path so its scope covers the whole app
+// It is never cached by the browser so a new version is picked up on the next load
+func (h *PWAHandler) ServiceWorker(c *gin.Context) {
+	data, err := fs.ReadFile(h.static, "sw.js")
+	if err != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Service-Worker-Allowed", h.basePath+"/")
+	c.Data(http.StatusOK, "application/javascript; charset=utf-8", data)
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/config"
+)
+
+const seoDescriptionLn = 200 // Characters of plain text in og:description
+
+// markdownImagePattern matches the URL of the first markdown image in a note
+var markdownImagePattern = regexp.MustCompile(`!\[[^\]]*\]\(\s*<?([^)\s>]+)`)
+
+// PageMeta holds the Open Graph / Twitter card data of a public page
+type PageMeta struct {
+	Title       string
+	Description string
+	Image       string // Absolute URL of the cover image ("" = none)
+	URL         string // Absolute canonical URL of the page
+	Type        string // og:type ("article" or "website")
+	NoIndex     bool   // Ask search engines not to index the page
+}
+
+// publicOrigin returns the configured public site URL, or the origin of the request
+func (h *ShortLinkHandler) publicOrigin(c *gin.Context) string {
+	return siteOrigin(h.config, c)
+}
+
+// siteOrigin returns seo.site_url, or the origin of the request when it is not set
+func siteOrigin(cfg *config.Config, c *gin.Context) string {
+	if cfg.SEO.SiteURL != "" {
+		return cfg.SEO.SiteURL
+	}
+	return requestOrigin(c)
+}
+
+// absoluteURL makes a link found in note content absolute; external links are kept as they are
+func absoluteURL(origin, link string) string {
+	if strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://") {
+		return link
+	}
+	if strings.HasPrefix(link, "/") && !strings.HasPrefix(link, "//") {
+		return origin + link
+	}
+	return ""
+}
+
+// coverImage returns the first image attachment of a note, or the first image in its content
+func coverImage(origin, content string, attachments []string) string {
+	for _, url := range attachments {
+		if image := absoluteURL(origin, url); image != "" {
+			return image
+		}
+	}
+	if m := markdownImagePattern.FindStringSubmatch(content); m != nil {
+		return absoluteURL(origin, m[1])
+	}
+	return ""
+}
+
+// notePageMeta returns the page metadata of a public note link (generic metadata for links
+// behind a passphrase and password-protected, encrypted or missing notes, so nothing private leaks into previews)
+func (h *ShortLinkHandler) notePageMeta(c *gin.Context, code string, info *ShortLinkInfo) PageMeta {
+	origin := h.publicOrigin(c)
+	meta := PageMeta{
+		Title:   "Git Notepad",
+		URL:     origin + h.basePath + "/preview/" + code,
+		Type:    "article",
+		NoIndex: !h.config.SEO.Indexable,
+	}
+
+	if info.PasswordHash != "" {
+		return meta
+	}
+	note := h.linkNote(code, info)
+	if note == nil || note.Private {
+		return meta
+	}
+
+	var images []string
+	for _, att := range note.Attachments {
+		if att.IsImage {
+			images = append(images, att.URL)
+		}
+	}
+	meta.Title = note.Title
+	meta.Description = excerpt(note.Content, seoDescriptionLn)
+	meta.Image = coverImage(origin, note.Content, images)
+	return meta
+}
+
+// folderPageMeta returns the page metadata of a public folder link (folder preview or blog)
+func (h *ShortLinkHandler) folderPageMeta(c *gin.Context, info *ShortLinkInfo, pagePath string) PageMeta {
+	return PageMeta{
+		Title:       blogTitle(info),
+		Description: h.blogDescription(info),
+		URL:         h.publicOrigin(c) + h.basePath + pagePath,
+		Type:        "website",
+		NoIndex:     !h.config.SEO.Indexable,
+	}
+}
+
+// postPageMeta returns the page metadata of a single blog post
+func (h *ShortLinkHandler) postPageMeta(c *gin.Context, code string, post BlogPost) PageMeta {
+	origin := h.publicOrigin(c)
+	return PageMeta{
+		Title:       post.Title,
+		Description: excerpt(post.Content, seoDescriptionLn),
+		Image:       coverImage(origin, post.Content, nil),
+		URL:         origin + h.basePath + "/blog/" + code + "/post/" + post.UID,
+		Type:        "article",
+		NoIndex:     !h.config.SEO.Indexable,
+	}
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Sitemap lists all public, unexpired links (only when seo.indexable is enabled)
+// Note links behind a passphrase, or whose note is password-protected, encrypted or gone are left out;
+// blog links also list every post
+func (h *ShortLinkHandler) Sitemap(c *gin.Context) {
+	if !h.config.SEO.Indexable {
+		c.String(http.StatusNotFound, "Not found")
+		return
+	}
+
+	now := time.Now()
+	h.mu.RLock()
+	links := make(map[string]ShortLinkInfo, len(h.links))
+	codes := make([]string, 0, len(h.links))
+	for code, info := range h.links {
+		if info.IsPublic && (info.ExpiresAt == nil || info.ExpiresAt.After(now)) {
+			links[code] = *info
+			codes = append(codes, code)
+		}
+	}
+	h.mu.RUnlock()
+	sort.Strings(codes)
+
+	origin := h.publicOrigin(c) + h.basePath
+	urlSet := sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, code := range codes {
+		info := links[code]
+		switch {
+		case info.FolderPath == "":
+			if info.PasswordHash != "" {
+				continue
+			}
+			note := h.linkNote(code, &info)
+			if note == nil || note.Private {
+				continue
+			}
+			urlSet.URLs = append(urlSet.URLs, sitemapURL{
+				Loc:     origin + "/preview/" + code,
+				LastMod: note.Modified.UTC().Format(time.RFC3339),
+			})
+		case info.Blog:
+			blogURL := origin + "/blog/" + code
+			posts := h.blogPosts(&info)
+			blog := sitemapURL{Loc: blogURL}
+			for _, post := range posts {
+				if blog.LastMod == "" || post.Modified.UTC().Format(time.RFC3339) > blog.LastMod {
+					blog.LastMod = post.Modified.UTC().Format(time.RFC3339)
+				}
+			}
+			urlSet.URLs = append(urlSet.URLs, blog)
+			for _, post := range posts {
+				urlSet.URLs = append(urlSet.URLs, sitemapURL{
+					Loc:     blogURL + "/post/" + post.UID,
+					LastMod: post.Modified.UTC().Format(time.RFC3339),
+				})
+			}
+		default:
+			urlSet.URLs = append(urlSet.URLs, sitemapURL{Loc: origin + "/folder-preview/" + code})
+		}
+	}
+
+	data, err := xml.MarshalIndent(urlSet, "", "  ")
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to build sitemap")
+		return
+	}
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", append([]byte(xml.Header), data...))
+}
+
+// Robots serves robots.txt: public pages and the sitemap when indexable, nothing otherwise
+func (h *ShortLinkHandler) Robots(c *gin.Context) {
+	var sb strings.Builder
+	sb.WriteString("User-agent: *\n")
+	if !h.config.SEO.Indexable {
+		sb.WriteString("Disallow: /\n")
+		c.String(http.StatusOK, sb.String())
+		return
+	}
+	for _, prefix := range []string{"/preview/", "/folder-preview/", "/blog/", "/u/"} {
+		sb.WriteString("Allow: " + h.basePath + prefix + "\n")
+	}
+	sb.WriteString("Disallow: /\n")
+	sb.WriteString("\nSitemap: " + h.publicOrigin(c) + h.basePath + "/sitemap.xml\n")
+	c.String(http.StatusOK, sb.String())
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/encryption"
+	"github.com/user/gitnotepad/internal/middleware"
+)
+
+// ListSessions returns the signed-in devices of the current user, most recently used first
+// The session making the request is marked "current"
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	sessions, err := h.sessionRepo.ListByUser(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+	current, _ := c.Cookie(middleware.SessionCookieName)
+	loc := middleware.Location(c)
+
+	result := make([]gin.H, 0, len(sessions))
+	for _, session := range sessions {
+		lastUsed := session.CreatedAt
+		if session.LastUsedAt != nil {
+			lastUsed = *session.LastUsedAt
+		}
+		result = append(result, gin.H{
+			"id":           session.ID,
+			"device":       describeUserAgent(session.UserAgent),
+			"user_agent":   session.UserAgent,
+			"ip":           session.IP,
+			"created_at":   session.CreatedAt.In(loc),
+			"last_used_at": lastUsed.In(loc),
+			"expires_at":   session.ExpiresAt.In(loc),
+			"current":      session.Token == current,
+		})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteSession signs a device of the current user out
+func (h *AuthHandler) DeleteSession(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	session, err := h.sessionRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+	if session == nil || session.UserID != user.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	encryption.GetKeyStore().Delete(session.Token)
+	if err := h.sessionRepo.Delete(session.Token); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	encoding.Info("Session revoked: username=%s, session=%d, ip=%s", user.Username, session.ID, c.ClientIP())
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+// describeUserAgent returns a short device description such as "Chrome on Windows"
+func describeUserAgent(ua string) string {
+	if ua == "" {
+		return "Unknown device"
+	}
+
+	browser := ""
+	for _, b := range []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"SamsungBrowser/", "Samsung Internet"},
+		{"Firefox/", "Firefox"},
+		{"FxiOS/", "Firefox"},
+		{"CriOS/", "Chrome"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+	} {
+		if strings.Contains(ua, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	platform := ""
+	for _, o := range []struct{ token, name string }{
+		{"iPad", "iPad"},
+		{"iPhone", "iPhone"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(ua, o.token) {
+			platform = o.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	}
+	if name, _, _ := strings.Cut(ua, "/"); len(name) <= 40 {
+		return name
+	}
+	return "Unknown device"
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/database"
+	"github.com/user/gitnotepad/internal/i18n"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/repository"
+)
+
+// uiSettingsFile is the name of the UI settings inside a full notes export
+const uiSettingsFile = "settings.json"
+
+// uiSettingsVersion is the format version of exported UI settings
+const uiSettingsVersion = 1
+
+// maxUISettingsSize limits an uploaded settings file
+const maxUISettingsSize = 5 << 20
+
+// UISettings are the per-user settings kept in the database rather than in the notes repository,
+// exported as JSON so they can move to another server together with the notes
+type UISettings struct {
+	Version     int                   `json:"version"`
+	FolderIcons map[string]string     `json:"folder_icons"`
+	FolderOrder FolderOrderMap        `json:"folder_order"`
+	NoteSort    NoteSortMap           `json:"note_sort"`
+	FolderMeta  map[string]FolderMeta `json:"folder_meta"`
+	Preferences *model.Preferences    `json:"preferences"`
+}
+
+type SettingsHandler struct {
+	db       *database.DB
+	prefRepo *repository.PreferenceRepository
+}
+
+func NewSettingsHandler(db *database.DB, prefRepo *repository.PreferenceRepository) *SettingsHandler {
+	return &SettingsHandler{db: db, prefRepo: prefRepo}
+}
+
+// Export downloads the UI settings of the current user as a JSON file
+func (h *SettingsHandler) Export(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	settings, err := loadUISettings(h.db, h.prefRepo, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export settings"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=gitnotepad-settings-%s.json", time.Now().Format("2006-01-02")))
+	c.JSON(http.StatusOK, settings)
+}
+
+// Import restores UI settings from an exported JSON file (multipart "file") or a JSON body
+// Imported entries replace the ones with the same folder; other settings are kept
+func (h *SettingsHandler) Import(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var reader io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, _, err := c.Request.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No file provided"})
+			return
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	var settings UISettings
+	if err := json.NewDecoder(io.LimitReader(reader, maxUISettingsSize)).Decode(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid settings file"})
+		return
+	}
+	if settings.Version > uiSettingsVersion {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported settings version"})
+		return
+	}
+
+	imported, err := saveUISettings(h.db, h.prefRepo, user.ID, &settings)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"imported": imported})
+}
+
+// loadUISettings collects the UI settings of a user
+func loadUISettings(db *database.DB, prefRepo *repository.PreferenceRepository, userID int64) (*UISettings, error) {
+	settings := &UISettings{
+		Version:     uiSettingsVersion,
+		FolderIcons: make(map[string]string),
+		FolderOrder: make(FolderOrderMap),
+	}
+
+	rows, err := db.Query("SELECT folder_path, icon FROM folder_icons WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get folder icons: %w", err)
+	}
+	for rows.Next() {
+		var folderPath, icon string
+		if err := rows.Scan(&folderPath, &icon); err == nil {
+			settings.FolderIcons[folderPath] = icon
+		}
+	}
+	rows.Close()
+
+	rows, err = db.Query("SELECT parent_path, order_json FROM folder_order WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get folder order: %w", err)
+	}
+	for rows.Next() {
+		var parentPath, orderJSON string
+		var order []string
+		if err := rows.Scan(&parentPath, &orderJSON); err == nil && json.Unmarshal([]byte(orderJSON), &order) == nil {
+			settings.FolderOrder[parentPath] = order
+		}
+	}
+	rows.Close()
+
+	if settings.NoteSort, err = loadNoteSorts(db, userID); err != nil {
+		return nil, fmt.Errorf("failed to get note sort: %w", err)
+	}
+	if settings.FolderMeta, err = loadFolderMeta(db, userID); err != nil {
+		return nil, fmt.Errorf("failed to get folder metadata: %w", err)
+	}
+	if settings.Preferences, err = prefRepo.Get(userID); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// saveUISettings stores imported UI settings and returns how many entries were applied
+// Entries with an invalid folder path or value are skipped
+func saveUISettings(db *database.DB, prefRepo *repository.PreferenceRepository, userID int64, settings *UISettings) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to import settings: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	imported := 0
+	for folderPath, icon := range settings.FolderIcons {
+		folderPath, ok := cleanFolderPath(folderPath)
+		if !ok || icon == "" {
+			continue
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO folder_icons (user_id, folder_path, icon) VALUES (?, ?, ?)
+			 ON CONFLICT(user_id, folder_path) DO UPDATE SET icon = excluded.icon`,
+			userID, folderPath, icon,
+		); err != nil {
+			return 0, fmt.Errorf("failed to import folder icon: %w", err)
+		}
+		imported++
+	}
+
+	for parentPath, order := range settings.FolderOrder {
+		parentPath, ok := cleanSettingsPath(parentPath)
+		if !ok {
+			continue
+		}
+		orderJSON, err := json.Marshal(order)
+		if err != nil {
+			continue
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO folder_order (user_id, parent_path, order_json, updated_at) VALUES (?, ?, ?, ?)
+			 ON CONFLICT(user_id, parent_path) DO UPDATE SET order_json = excluded.order_json, updated_at = excluded.updated_at`,
+			userID, parentPath, string(orderJSON), now,
+		); err != nil {
+			return 0, fmt.Errorf("failed to import folder order: %w", err)
+		}
+		imported++
+	}
+
+	for folderPath, sort := range settings.NoteSort {
+		folderPath, ok := cleanSettingsPath(folderPath)
+		if !ok || !sort.valid() {
+			continue
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO note_sort (user_id, folder_path, field, direction, updated_at) VALUES (?, ?, ?, ?, ?)
+			 ON CONFLICT(user_id, folder_path) DO UPDATE SET field = excluded.field, direction = excluded.direction, updated_at = excluded.updated_at`,
+			userID, folderPath, sort.Field, sort.Direction, now,
+		); err != nil {
+			return 0, fmt.Errorf("failed to import note sort: %w", err)
+		}
+		imported++
+	}
+
+	for folderPath, meta := range settings.FolderMeta {
+		folderPath, ok := cleanFolderPath(folderPath)
+		if !ok || utf8.RuneCountInString(meta.Description) > 500 || (meta.Color != "" && !folderColorPattern.MatchString(meta.Color)) {
+			continue
+		}
+		switch meta.DefaultType {
+		case "", "markdown", "txt", "asciidoc":
+		default:
+			continue
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO folder_meta (user_id, folder_path, description, color, default_type) VALUES (?, ?, ?, ?, ?)
+			 ON CONFLICT(user_id, folder_path) DO UPDATE SET description = excluded.description, color = excluded.color,
+			 default_type = excluded.default_type, updated_at = CURRENT_TIMESTAMP`,
+			userID, folderPath, meta.Description, meta.Color, meta.DefaultType,
+		); err != nil {
+			return 0, fmt.Errorf("failed to import folder metadata: %w", err)
+		}
+		imported++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to import settings: %w", err)
+	}
+
+	if settings.Preferences != nil {
+		prefs, err := prefRepo.Get(userID)
+		if err != nil {
+			return imported, err
+		}
+		if lang := settings.Preferences.Language; lang != "" && i18n.Supported(lang) {
+			prefs.Language = lang
+			imported++
+		}
+		if tz := settings.Preferences.Timezone; tz != "" && tz != "Local" {
+			if _, err := time.LoadLocation(tz); err == nil {
+				prefs.Timezone = tz
+				imported++
+			}
+		}
+		if err := prefRepo.Save(userID, prefs); err != nil {
+			return imported, err
+		}
+	}
+	return imported, nil
+}
+
+// cleanSettingsPath validates a folder path that may be "" for the top level
+func cleanSettingsPath(folderPath string) (string, bool) {
+	if strings.TrimSpace(folderPath) == "" {
+		return "", true
+	}
+	return cleanFolderPath(folderPath)
+}
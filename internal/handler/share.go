@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/repository"
+)
+
+type ShareHandler struct {
+	userRepo    *repository.UserRepository
+	shareRepo   *repository.ShareRepository
+	auditRepo   *repository.AuditRepository
+	storagePath string
+}
+
+func NewShareHandler(userRepo *repository.UserRepository, shareRepo *repository.ShareRepository, auditRepo *repository.AuditRepository, storagePath string) *ShareHandler {
+	return &ShareHandler{
+		userRepo:    userRepo,
+		shareRepo:   shareRepo,
+		auditRepo:   auditRepo,
+		storagePath: storagePath,
+	}
+}
+
+// storageOwner returns the user whose storage is accessed: the share owner (?owner=) or the current user
+func storageOwner(c *gin.Context) *model.User {
+	if owner := middleware.GetShareOwner(c); owner != nil {
+		return owner
+	}
+	return middleware.GetCurrentUser(c)
+}
+
+// denyShare responds 403 and returns true when the folder lacks the required permission
+// (a shared folder, or a folder rule in the user's own notes)
+func denyShare(c *gin.Context, folderPath string, required model.SharePermission) bool {
+	if middleware.HasSharePermission(c, folderPath, required) {
+		return false
+	}
+	if middleware.GetShareOwner(c) == nil {
+		// Limited by a folder rule or a read-only account
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission for this folder"})
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission for shared folder"})
+	return true
+}
+
+// CreateShareRequest represents the request to share a folder with another user
+type CreateShareRequest struct {
+	FolderPath string                `json:"folder_path" binding:"required"`
+	Username   string                `json:"username" binding:"required"`
+	Permission model.SharePermission `json:"permission"`
+}
+
+// List returns folder shares created by the current user (or by the share owner with ?owner=)
+func (h *ShareHandler) List(c *gin.Context) {
+	owner := storageOwner(c)
+	if owner == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	shares, err := h.shareRepo.ListByOwner(owner.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list shares"})
+		return
+	}
+
+	// Managers of a shared folder only see shares inside the folders they manage
+	result := []*model.FolderShare{}
+	for _, share := range shares {
+		if middleware.HasSharePermission(c, share.FolderPath, model.PermissionManage) {
+			result = append(result, share)
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListIncoming returns folder shares granted to the current user
+func (h *ShareHandler) ListIncoming(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	shares, err := h.shareRepo.ListByGrantee(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list shares"})
+		return
+	}
+	if shares == nil {
+		shares = []*model.FolderShare{}
+	}
+
+	c.JSON(http.StatusOK, shares)
+}
+
+// Create shares a folder with another user, or updates the permission of an existing share
+// Owners can share any folder; grantees with manage permission can re-share within it (?owner=)
+func (h *ShareHandler) Create(c *gin.Context) {
+	owner := storageOwner(c)
+	if owner == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req CreateShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Permission == "" {
+		req.Permission = model.PermissionRead
+	}
+	if !req.Permission.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid permission (read, write, manage)"})
+		return
+	}
+
+	folderPath, ok := cleanFolderPath(req.FolderPath)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path"})
+		return
+	}
+	if denyShare(c, folderPath, model.PermissionManage) {
+		return
+	}
+
+	// Folder must exist in the owner's notes
+	info, err := os.Stat(filepath.Join(h.storagePath, owner.Username, "notes", filepath.FromSlash(folderPath)))
+	if err != nil || !info.IsDir() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+		return
+	}
+
+	grantee, err := h.userRepo.GetByUsername(req.Username)
+	if err != nil || grantee == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if grantee.ID == owner.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot share with the folder owner"})
+		return
+	}
+
+	share := &model.FolderShare{
+		OwnerID:         owner.ID,
+		OwnerUsername:   owner.Username,
+		FolderPath:      folderPath,
+		GranteeID:       grantee.ID,
+		GranteeUsername: grantee.Username,
+		Permission:      req.Permission,
+	}
+	if err := h.shareRepo.Upsert(share); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save share"})
+		return
+	}
+
+	currentUser := middleware.GetCurrentUser(c)
+	encoding.Info("Folder shared: owner=%s, folder=%s, with=%s, permission=%s, by=%s, ip=%s",
+		owner.Username, folderPath, grantee.Username, share.Permission, currentUser.Username, c.ClientIP())
+	recordAudit(h.auditRepo, c, model.AuditShareCreate, owner.Username+":"+folderPath,
+		fmt.Sprintf("with=%s, permission=%s", grantee.Username, share.Permission))
+
+	c.JSON(http.StatusOK, share)
+}
+
+// Delete removes a folder share (owner, folder manager, or the grantee leaving the share)
+func (h *ShareHandler) Delete(c *gin.Context) {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share ID"})
+		return
+	}
+
+	share, err := h.shareRepo.GetByID(id)
+	if err != nil || share == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share not found"})
+		return
+	}
+
+	owner := storageOwner(c)
+	allowed := share.GranteeID == user.ID ||
+		(share.OwnerID == owner.ID && middleware.HasSharePermission(c, share.FolderPath, model.PermissionManage))
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission for shared folder"})
+		return
+	}
+
+	if err := h.shareRepo.Delete(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete share"})
+		return
+	}
+
+	encoding.Info("Folder share removed: owner=%s, folder=%s, with=%s, by=%s, ip=%s",
+		share.OwnerUsername, share.FolderPath, share.GranteeUsername, user.Username, c.ClientIP())
+	recordAudit(h.auditRepo, c, model.AuditShareDelete, share.OwnerUsername+":"+share.FolderPath, "with="+share.GranteeUsername)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Share deleted"})
+}
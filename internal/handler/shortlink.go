@@ -2,65 +2,164 @@ package handler
 
 import (
 	"crypto/rand"
-	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/user/gitnotepad/internal/config"
+	"github.com/user/gitnotepad/internal/database"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/encryption"
 	"github.com/user/gitnotepad/internal/git"
+	"github.com/user/gitnotepad/internal/index"
 	"github.com/user/gitnotepad/internal/middleware"
 	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/notify"
+	"github.com/user/gitnotepad/internal/repository"
 )
 
 // ShortLinkInfo contains short link data with optional expiry
-type ShortLinkInfo struct {
-	NoteID     string     `json:"note_id,omitempty"`
-	FolderPath string     `json:"folder_path,omitempty"` // For folder sharing (empty = note link)
-	Username   string     `json:"username"`
-	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
-	CreatedAt  time.Time  `json:"created_at"`
-	IsPublic   bool       `json:"is_public"`
-}
+type ShortLinkInfo = model.ShortLink
+
+// legacyShortLinksFile is the JSON file links were stored in before the short_links table
+const legacyShortLinksFile = ".shortlinks.json"
 
 type ShortLinkHandler struct {
 	repo             *git.Repository
 	config           *config.Config
+	db               *database.DB
 	links            map[string]*ShortLinkInfo // shortCode -> ShortLinkInfo
-	reverseMap       map[string]string         // noteId -> shortCode
-	folderReverseMap map[string]string         // folderPath -> shortCode
+	reverseMap       map[string]string         // linkKey(owner, noteId) -> shortCode
+	folderReverseMap map[string]string         // linkKey(owner, folderPath) -> shortCode
 	mu               sync.RWMutex
-	storagePath      string
 	basePath         string
+	noteIndex        *index.Index
+	events           *notify.Dispatcher
+	auditRepo        *repository.AuditRepository
+	linkRepo         *repository.ShortLinkRepository
+	viewRepo         *repository.LinkViewRepository
+	viewSalt         []byte // In-memory salt of visitor hashes, replaced daily
+	viewSaltDay      string
+	saltMu           sync.Mutex
+	stopCleanup      chan struct{}
+	cleanupDone      chan struct{}
+	stopOnce         sync.Once
 }
 
-func NewShortLinkHandler(repo *git.Repository, cfg *config.Config, basePath string) *ShortLinkHandler {
+func NewShortLinkHandler(repo *git.Repository, cfg *config.Config, db *database.DB, basePath string, noteIndex *index.Index) *ShortLinkHandler {
 	h := &ShortLinkHandler{
 		repo:             repo,
 		config:           cfg,
+		db:               db,
 		links:            make(map[string]*ShortLinkInfo),
 		reverseMap:       make(map[string]string),
 		folderReverseMap: make(map[string]string),
-		storagePath:      filepath.Join(repo.GetPath(), ".shortlinks.json"),
 		basePath:         basePath,
+		noteIndex:        noteIndex,
+		stopCleanup:      make(chan struct{}),
+		cleanupDone:      make(chan struct{}),
+	}
+	if db != nil {
+		h.auditRepo = repository.NewAuditRepository(db.DB)
+		h.linkRepo = repository.NewShortLinkRepository(db.DB)
+		h.viewRepo = repository.NewLinkViewRepository(db.DB)
 	}
 	h.load()
 	h.startCleanupScheduler()
 	return h
 }
 
+// SetEvents sets where views of links are announced (e.g., to the Telegram bot)
+func (h *ShortLinkHandler) SetEvents(events *notify.Dispatcher) {
+	h.events = events
+}
+
+// load reads all links into memory; lookups are served from memory and every change is
+// written through to the database
 func (h *ShortLinkHandler) load() {
-	data, err := os.ReadFile(h.storagePath)
+	if h.linkRepo == nil {
+		return
+	}
+	links, err := h.linkRepo.List()
 	if err != nil {
+		encoding.Warn("Failed to load short links: %v", err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.links = links
+	h.reverseMap = make(map[string]string)
+	h.folderReverseMap = make(map[string]string)
+	for code, info := range links {
+		h.mapLink(code, info)
+	}
+}
+
+// linkKey identifies a note or folder of a user in the reverse maps, so users with
+// notes or folders of the same name get their own links
+func linkKey(username, id string) string {
+	return username + "\x00" + id
+}
+
+// mapLink adds a link to the reverse map of its note or folder (h.mu must be held)
+func (h *ShortLinkHandler) mapLink(code string, info *ShortLinkInfo) {
+	if info.FolderPath != "" {
+		h.folderReverseMap[linkKey(info.Username, info.FolderPath)] = code
+	} else if info.NoteID != "" {
+		h.reverseMap[linkKey(info.Username, info.NoteID)] = code
+	}
+}
+
+// unmapLink removes a link from the reverse maps (h.mu must be held)
+func (h *ShortLinkHandler) unmapLink(info *ShortLinkInfo) {
+	if info.FolderPath != "" {
+		delete(h.folderReverseMap, linkKey(info.Username, info.FolderPath))
+	} else {
+		delete(h.reverseMap, linkKey(info.Username, info.NoteID))
+	}
+}
+
+// storeLink writes a new or changed link to the database (h.mu must be held)
+func (h *ShortLinkHandler) storeLink(code string, info *ShortLinkInfo) {
+	if h.linkRepo == nil {
 		return
 	}
+	if err := h.linkRepo.Save(code, info); err != nil {
+		encoding.Warn("Failed to save short link %s: %v", code, err)
+	}
+}
+
+// dropLink deletes a link from the database
+func (h *ShortLinkHandler) dropLink(code string) {
+	if h.linkRepo == nil {
+		return
+	}
+	if err := h.linkRepo.Delete(code); err != nil {
+		encoding.Warn("Failed to delete short link %s: %v", code, err)
+	}
+}
+
+// MigrateShortLinks imports the legacy .shortlinks.json into the short_links table on first start
+// The file is renamed to .shortlinks.json.migrated afterwards, so it is only imported once
+func MigrateShortLinks(db *database.DB, storagePath string) error {
+	path := filepath.Join(storagePath, legacyShortLinksFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
 
 	// Try new format first
 	var links map[string]*ShortLinkInfo
@@ -68,7 +167,7 @@ func (h *ShortLinkHandler) load() {
 		// Try legacy format (map[string]string)
 		var legacyLinks map[string]string
 		if err := json.Unmarshal(data, &legacyLinks); err != nil {
-			return
+			return fmt.Errorf("failed to parse %s: %w", legacyShortLinksFile, err)
 		}
 		// Convert legacy format
 		links = make(map[string]*ShortLinkInfo)
@@ -80,48 +179,65 @@ func (h *ShortLinkHandler) load() {
 		}
 	}
 
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	h.links = links
-	h.reverseMap = make(map[string]string)
-	h.folderReverseMap = make(map[string]string)
-	for code, info := range links {
-		if info.FolderPath != "" {
-			h.folderReverseMap[info.FolderPath] = code
-		} else if info.NoteID != "" {
-			h.reverseMap[info.NoteID] = code
-		}
-	}
-}
-
-func (h *ShortLinkHandler) save() error {
-	h.mu.RLock()
-	data, err := json.MarshalIndent(h.links, "", "  ")
-	h.mu.RUnlock()
-	if err != nil {
+	if err := repository.NewShortLinkRepository(db.DB).SaveAll(links); err != nil {
 		return err
 	}
-	return os.WriteFile(h.storagePath, data, 0644)
+	if err := os.Rename(path, path+".migrated"); err != nil {
+		return fmt.Errorf("failed to rename %s: %w", legacyShortLinksFile, err)
+	}
+	encoding.Info("Short link migration: imported %d links into the database", len(links))
+	return nil
 }
 
-// startCleanupScheduler runs daily cleanup of expired links
+// startCleanupScheduler removes expired links at startup and then every shortlinks.cleanup_interval
+// hours, counted from local midnight (the default of 24 runs at midnight)
 func (h *ShortLinkHandler) startCleanupScheduler() {
+	interval := time.Duration(h.config.ShortLinks.CleanupInterval) * time.Hour
 	go func() {
-		// Run cleanup once at startup
-		h.cleanupExpiredLinks()
+		defer close(h.cleanupDone)
+		h.CleanupExpired()
 
-		// Then run daily at midnight
 		for {
-			now := time.Now()
-			nextMidnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
-			time.Sleep(time.Until(nextMidnight))
-			h.cleanupExpiredLinks()
+			timer := time.NewTimer(time.Until(nextCleanup(time.Now(), interval)))
+			select {
+			case <-timer.C:
+				h.CleanupExpired()
+			case <-h.stopCleanup:
+				timer.Stop()
+				return
+			}
 		}
 	}()
 }
 
-// cleanupExpiredLinks removes expired short links
-func (h *ShortLinkHandler) cleanupExpiredLinks() {
+// nextCleanup returns the first multiple of interval after local midnight of today that is after now
+func nextCleanup(now time.Time, interval time.Duration) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for !next.After(now) {
+		next = next.Add(interval)
+	}
+	return next
+}
+
+// Stop ends the cleanup scheduler, waiting for a running cleanup
+// Called on shutdown so the database is not written while the process exits
+func (h *ShortLinkHandler) Stop() {
+	h.stopOnce.Do(func() {
+		close(h.stopCleanup)
+		<-h.cleanupDone
+	})
+}
+
+// CleanupExpired removes expired short links and their view counts, returning how many were removed
+func (h *ShortLinkHandler) CleanupExpired() int {
+	expiredCodes := h.removeExpiredLinks()
+	h.forgetViews(expiredCodes...)
+	h.pruneViews()
+	return len(expiredCodes)
+}
+
+// removeExpiredLinks drops expired links from memory and returns their codes
+func (h *ShortLinkHandler) removeExpiredLinks() []string {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
@@ -135,18 +251,169 @@ func (h *ShortLinkHandler) cleanupExpiredLinks() {
 	}
 
 	for _, code := range expiredCodes {
-		info := h.links[code]
+		h.unmapLink(h.links[code])
+		delete(h.links, code)
+		h.dropLink(code)
+	}
+	return expiredCodes
+}
+
+// RenameUser updates the owner of all short links after a username change
+func (h *ShortLinkHandler) RenameUser(oldUsername, newUsername string) {
+	h.mu.Lock()
+	for code, info := range h.links {
+		if info.Username == oldUsername {
+			h.unmapLink(info)
+			info.Username = newUsername
+			h.mapLink(code, info)
+			h.storeLink(code, info)
+		}
+	}
+	h.mu.Unlock()
+}
+
+// RenameFolder updates note and folder short links of a user after a folder is renamed or moved
+func (h *ShortLinkHandler) RenameFolder(username, oldPath, newPath string) {
+	h.mu.Lock()
+	for code, info := range h.links {
+		if info.Username != username {
+			continue
+		}
 		if info.FolderPath != "" {
-			delete(h.folderReverseMap, info.FolderPath)
-		} else {
-			delete(h.reverseMap, info.NoteID)
+			// Folder links are usually stored with the ":>:" separator
+			folderPath := strings.ReplaceAll(info.FolderPath, FolderSeparator, "/")
+			if folderPath == oldPath || strings.HasPrefix(folderPath, oldPath+"/") {
+				renamed := newPath + strings.TrimPrefix(folderPath, oldPath)
+				if !strings.Contains(info.FolderPath, "/") {
+					renamed = strings.ReplaceAll(renamed, "/", FolderSeparator)
+				}
+				h.unmapLink(info)
+				info.FolderPath = renamed
+				h.mapLink(code, info)
+				h.storeLink(code, info)
+			}
+		} else if strings.HasPrefix(info.NoteID, oldPath+"/") {
+			h.unmapLink(info)
+			info.NoteID = newPath + strings.TrimPrefix(info.NoteID, oldPath)
+			h.mapLink(code, info)
+			h.storeLink(code, info)
 		}
-		delete(h.links, code)
 	}
+	h.mu.Unlock()
+}
+
+// MoveNote updates the short link of a note after it was moved to another folder
+func (h *ShortLinkHandler) MoveNote(username, oldID, newID string) {
+	h.mu.Lock()
+	code, exists := h.reverseMap[linkKey(username, oldID)]
+	if exists {
+		info := h.links[code]
+		h.unmapLink(info)
+		info.NoteID = newID
+		h.mapLink(code, info)
+		h.storeLink(code, info)
+	}
+	h.mu.Unlock()
+}
+
+// parsePlainNote parses a note without decryption; encrypted notes return an error
+// (they cannot be shown publicly and keep their previous index entry)
+func parsePlainNote(path string, data []byte) (*model.Note, error) {
+	if encryption.IsEncrypted(string(data)) {
+		return nil, fmt.Errorf("note is encrypted")
+	}
+	return model.ParseNoteFromBytes(data, path)
+}
+
+// resolveNoteID maps a note UID to the current note ID in a user's notes directory
+func (h *ShortLinkHandler) resolveNoteID(username, id string) string {
+	notesPath := filepath.Join(h.config.Storage.Path, username, "notes")
+	if strings.Contains(id, "/") || noteFileExists(notesPath, id) {
+		return id
+	}
+	if resolved, ok := h.noteIndex.Resolve(notesPath, id, parsePlainNote); ok {
+		return resolved
+	}
+	return id
+}
+
+// noteUID returns the UID of a note, falling back to its filename for notes without one
+func (h *ShortLinkHandler) noteUID(username, id string) string {
+	notesPath := filepath.Join(h.config.Storage.Path, username, "notes")
+	for _, ext := range []string{".md", ".txt", ".adoc"} {
+		filePath := filepath.Join(notesPath, id+ext)
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			continue
+		}
+		if note, err := parsePlainNote(filePath, data); err == nil && note.UID != "" {
+			return note.UID
+		}
+		break
+	}
+	return filepath.Base(id)
+}
+
+// linkNoteID returns the current ID of a linked note, following it by UID if it was moved outside the app
+func (h *ShortLinkHandler) linkNoteID(code string, info *ShortLinkInfo) string {
+	notesPath := filepath.Join(h.config.Storage.Path, info.Username, "notes")
+	if noteFileExists(notesPath, info.NoteID) {
+		return info.NoteID
+	}
+
+	uid := info.NoteUID
+	if uid == "" {
+		uid = filepath.Base(info.NoteID) // Links created before UIDs: filename is the UID
+	}
+	newID, ok := h.noteIndex.Resolve(notesPath, uid, parsePlainNote)
+	if !ok {
+		return info.NoteID
+	}
+
+	h.MoveNote(info.Username, info.NoteID, newID)
+	return newID
+}
+
+// NoteLink returns the short link of a user's note, creating a private one (opening the note after sign-in) if it has none
+// Used by the Telegram bot to link the notes it lists
+func (h *ShortLinkHandler) NoteLink(username, noteID string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	code, exists := h.reverseMap[linkKey(username, noteID)]
+	if !exists {
+		code = generateShortCode()
+		for {
+			if _, taken := h.links[code]; !taken {
+				break
+			}
+			code = generateShortCode()
+		}
+		info := &ShortLinkInfo{
+			NoteID:    noteID,
+			NoteUID:   h.noteUID(username, noteID),
+			Username:  username,
+			CreatedAt: time.Now(),
+		}
+		h.links[code] = info
+		h.mapLink(code, info)
+		h.storeLink(code, info)
+	}
+	return h.basePath + "/s/" + code
+}
 
-	if len(expiredCodes) > 0 {
-		go h.save()
+// LinkedNote returns the owner and current ID of the note an unexpired short link points to
+func (h *ShortLinkHandler) LinkedNote(code string) (string, string, bool) {
+	h.mu.RLock()
+	info, exists := h.links[code]
+	h.mu.RUnlock()
+	if !exists || info.FolderPath != "" || info.NoteID == "" {
+		return "", "", false
+	}
+	if info.ExpiresAt != nil && info.ExpiresAt.Before(time.Now()) {
+		return "", "", false
 	}
+	return info.Username, h.linkNoteID(code, info), true
 }
 
 func generateShortCode() string {
@@ -155,53 +422,79 @@ func generateShortCode() string {
 	return hex.EncodeToString(bytes)
 }
 
+// customCodePattern limits custom codes to URL-safe slugs such as "meeting-notes"
+var customCodePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{2,63}$`)
+
 // GenerateRequest represents the request body for generating a short link
 type GenerateRequest struct {
-	ExpiresIn *int  `json:"expires_in"` // Days until expiry (nil = never expires)
-	IsPublic  *bool `json:"is_public"`  // Whether the link is publicly accessible without auth
-}
-
-// decodeNoteIDParam base64-decodes the note ID from path parameter
-// Supports both standard and URL-safe base64 encoding
-func decodeNoteIDParam(id string) string {
-	// Try URL-safe base64 first (used by new frontend)
-	decoded, err := base64.RawURLEncoding.DecodeString(id)
-	if err != nil {
-		// Fallback to standard base64 (for backwards compatibility)
-		decoded, err = base64.StdEncoding.DecodeString(id)
-		if err != nil {
-			return id // Return original if decode fails
-		}
-	}
-	return string(decoded)
+	ExpiresIn *int    `json:"expires_in"` // Days until expiry (nil = never expires)
+	IsPublic  *bool   `json:"is_public"`  // Whether the link is publicly accessible without auth
+	Password  *string `json:"password"`   // Passphrase of the public link (nil = no change, "" = remove)
+	MaxViews  *int    `json:"max_views"`  // Accesses after which the link expires (nil = no change, 0 = unlimited)
+	// Code to use instead of a random one, e.g. "meeting-notes" for /s/meeting-notes
+	// (nil or "" = keep the current code); renames the code of an existing link
+	CustomCode *string `json:"custom_code"`
 }
 
 // Generate creates or returns existing short link for a note
 func (h *ShortLinkHandler) Generate(c *gin.Context) {
-	noteId := decodeNoteIDParam(c.Param("id"))
+	noteId := decodeNoteID(c.Param("id"))
 	if noteId == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Note ID required"})
 		return
 	}
-
-	// Get current user for public links
-	user := middleware.GetCurrentUser(c)
+	// Get note owner for public links (share owner when generating inside a shared folder)
+	user := storageOwner(c)
 	username := ""
 	if user != nil {
 		username = user.Username
 	}
+	noteId = h.resolveNoteID(username, noteId)
+	if folder, _ := splitFolderPath(noteId); denyShare(c, folder, model.PermissionManage) {
+		return
+	}
 
 	// Parse request body for expiry
 	var req GenerateRequest
 	c.ShouldBindJSON(&req)
+	if req.MaxViews != nil && *req.MaxViews < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max views"})
+		return
+	}
+	customCode := ""
+	if req.CustomCode != nil {
+		customCode = strings.TrimSpace(*req.CustomCode)
+	}
+	if customCode != "" && !customCodePattern.MatchString(customCode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid custom code"})
+		return
+	}
 
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	// Check if short link already exists
-	if code, exists := h.reverseMap[noteId]; exists {
+	if code, exists := h.reverseMap[linkKey(username, noteId)]; exists {
 		info := h.links[code]
 		changed := false
+		// Rename the code if a different custom code is requested
+		if customCode != "" && customCode != code {
+			if _, taken := h.links[customCode]; taken {
+				c.JSON(http.StatusConflict, gin.H{"error": "Short link code already in use"})
+				return
+			}
+			delete(h.links, code)
+			h.links[customCode] = info
+			h.mapLink(customCode, info)
+			if h.linkRepo != nil {
+				if err := h.linkRepo.Rename(code, customCode); err != nil {
+					encoding.Warn("Failed to rename short link %s: %v", code, err)
+				}
+			}
+			go h.renameViews(code, customCode)
+			code = customCode
+			changed = true
+		}
 		// Update expiry if provided
 		if req.ExpiresIn != nil {
 			if *req.ExpiresIn == 0 {
@@ -217,25 +510,47 @@ func (h *ShortLinkHandler) Generate(c *gin.Context) {
 			info.IsPublic = *req.IsPublic
 			changed = true
 		}
+		if req.Password != nil {
+			if err := setLinkPassword(info, *req.Password); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			changed = true
+		}
+		if req.MaxViews != nil {
+			setMaxViews(info, *req.MaxViews)
+			changed = true
+		}
 		if changed {
-			go h.save()
+			h.storeLink(code, info)
 		}
 		c.JSON(http.StatusOK, gin.H{
-			"code":      code,
-			"shortLink": h.basePath + "/s/" + code,
-			"expiresAt": info.ExpiresAt,
-			"isPublic":  info.IsPublic,
+			"code":        code,
+			"shortLink":   h.basePath + "/s/" + code,
+			"expiresAt":   info.ExpiresAt,
+			"isPublic":    info.IsPublic,
+			"hasPassword": info.PasswordHash != "",
+			"maxViews":    info.MaxViews,
+			"viewCount":   info.ViewCount,
 		})
 		return
 	}
 
-	// Generate new short code
-	code := generateShortCode()
-	for {
-		if _, exists := h.links[code]; !exists {
-			break
+	// Use the custom code, or generate a new short code
+	code := customCode
+	if code != "" {
+		if _, taken := h.links[code]; taken {
+			c.JSON(http.StatusConflict, gin.H{"error": "Short link code already in use"})
+			return
 		}
+	} else {
 		code = generateShortCode()
+		for {
+			if _, exists := h.links[code]; !exists {
+				break
+			}
+			code = generateShortCode()
+		}
 	}
 
 	// Calculate expiry
@@ -251,22 +566,37 @@ func (h *ShortLinkHandler) Generate(c *gin.Context) {
 		isPublic = *req.IsPublic
 	}
 
-	h.links[code] = &ShortLinkInfo{
+	info := &ShortLinkInfo{
 		NoteID:    noteId,
+		NoteUID:   h.noteUID(username, noteId),
 		Username:  username,
 		ExpiresAt: expiresAt,
 		CreatedAt: time.Now(),
 		IsPublic:  isPublic,
 	}
-	h.reverseMap[noteId] = code
+	if req.Password != nil {
+		if err := setLinkPassword(info, *req.Password); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.MaxViews != nil {
+		info.MaxViews = *req.MaxViews
+	}
+	h.links[code] = info
+	h.mapLink(code, info)
+	h.storeLink(code, info)
 
-	go h.save()
+	recordAudit(h.auditRepo, c, model.AuditShortLinkCreate, username+":"+noteId, "")
 
 	c.JSON(http.StatusOK, gin.H{
-		"code":      code,
-		"shortLink": h.basePath + "/s/" + code,
-		"expiresAt": expiresAt,
-		"isPublic":  isPublic,
+		"code":        code,
+		"shortLink":   h.basePath + "/s/" + code,
+		"expiresAt":   expiresAt,
+		"isPublic":    isPublic,
+		"hasPassword": info.PasswordHash != "",
+		"maxViews":    info.MaxViews,
+		"viewCount":   info.ViewCount,
 	})
 }
 
@@ -291,13 +621,21 @@ func (h *ShortLinkHandler) Redirect(c *gin.Context) {
 	if info.ExpiresAt != nil && info.ExpiresAt.Before(time.Now()) {
 		c.HTML(http.StatusGone, "expired.html", gin.H{
 			"basePath": h.basePath,
+			"lang":     middleware.Language(c),
 		})
 		return
 	}
 
+	// Public note links are counted when the preview page loads the note, blogs when a page is rendered
+	if blog := info.FolderPath != "" && info.IsPublic && info.Blog; !blog && (info.FolderPath != "" || !info.IsPublic) {
+		h.recordAccess(c, code)
+	}
+
 	// For folder links
 	if info.FolderPath != "" {
-		if info.IsPublic {
+		if info.IsPublic && info.Blog {
+			c.Redirect(http.StatusFound, h.basePath+"/blog/"+code)
+		} else if info.IsPublic {
 			c.Redirect(http.StatusFound, h.basePath+"/folder-preview/"+code)
 		} else {
 			c.Redirect(http.StatusFound, h.basePath+"/#folder="+info.FolderPath)
@@ -312,19 +650,24 @@ func (h *ShortLinkHandler) Redirect(c *gin.Context) {
 	}
 
 	// Redirect to main page with note ID as hash
-	c.Redirect(http.StatusFound, h.basePath+"/#note="+info.NoteID)
+	c.Redirect(http.StatusFound, h.basePath+"/#note="+h.linkNoteID(code, info))
 }
 
 // Get returns the short link for a note if it exists
 func (h *ShortLinkHandler) Get(c *gin.Context) {
-	noteId := decodeNoteIDParam(c.Param("id"))
+	noteId := decodeNoteID(c.Param("id"))
 	if noteId == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Note ID required"})
 		return
 	}
 
+	username := ""
+	if user := storageOwner(c); user != nil {
+		username = user.Username
+	}
+
 	h.mu.RLock()
-	code, exists := h.reverseMap[noteId]
+	code, exists := h.reverseMap[linkKey(username, noteId)]
 	var info *ShortLinkInfo
 	if exists {
 		info = h.links[code]
@@ -337,75 +680,122 @@ func (h *ShortLinkHandler) Get(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"code":      code,
-		"shortLink": h.basePath + "/s/" + code,
-		"expiresAt": info.ExpiresAt,
-		"createdAt": info.CreatedAt,
-		"isPublic":  info.IsPublic,
+		"code":        code,
+		"shortLink":   h.basePath + "/s/" + code,
+		"expiresAt":   info.ExpiresAt,
+		"createdAt":   info.CreatedAt,
+		"isPublic":    info.IsPublic,
+		"hasPassword": info.PasswordHash != "",
+		"maxViews":    info.MaxViews,
+		"viewCount":   info.ViewCount,
 	})
 }
 
 // Delete removes a short link
 func (h *ShortLinkHandler) Delete(c *gin.Context) {
-	noteId := decodeNoteIDParam(c.Param("id"))
+	noteId := decodeNoteID(c.Param("id"))
 	if noteId == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Note ID required"})
 		return
 	}
+	if folder, _ := splitFolderPath(noteId); denyShare(c, folder, model.PermissionManage) {
+		return
+	}
+
+	username := ""
+	if user := storageOwner(c); user != nil {
+		username = user.Username
+	}
 
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	code, exists := h.reverseMap[noteId]
+	code, exists := h.reverseMap[linkKey(username, noteId)]
 	if !exists {
 		c.JSON(http.StatusNotFound, gin.H{"error": "No short link for this note"})
 		return
 	}
 
+	h.unmapLink(h.links[code])
 	delete(h.links, code)
-	delete(h.reverseMap, noteId)
+	h.dropLink(code)
 
-	go h.save()
+	go h.forgetViews(code)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Short link deleted"})
 }
 
 // ShortLinkListItem represents a short link item in the list
 type ShortLinkListItem struct {
-	Code      string     `json:"code"`
-	NoteID    string     `json:"note_id"`
-	NoteTitle string     `json:"note_title"`
-	ShortLink string     `json:"short_link"`
-	ExpiresAt *time.Time `json:"expires_at,omitempty"`
-	CreatedAt time.Time  `json:"created_at"`
-	IsPublic  bool       `json:"is_public"`
+	Code        string     `json:"code"`
+	NoteID      string     `json:"note_id"`
+	NoteTitle   string     `json:"note_title"`
+	ShortLink   string     `json:"short_link"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	IsPublic    bool       `json:"is_public"`
+	HasPassword bool       `json:"has_password"`
+	Views       int        `json:"views"`       // Views over the last 30 days
+	TotalViews  int        `json:"total_views"` // Accesses since the link was created
+	MaxViews    int        `json:"max_views,omitempty"`
+	LastAccess  *time.Time `json:"last_access_at,omitempty"`
+	LastIP      string     `json:"last_access_ip,omitempty"`
+	Username    string     `json:"username"`
+	FolderPath  string     `json:"folder_path,omitempty"`
 }
 
 // List returns all short links for the current user
 func (h *ShortLinkHandler) List(c *gin.Context) {
+	username := currentUsername(c)
+	c.JSON(http.StatusOK, h.listLinks(func(info *ShortLinkInfo) bool {
+		return info.Username == username
+	}))
+}
+
+// ListAll returns the short links of all users (for the admin API)
+func (h *ShortLinkHandler) ListAll() []ShortLinkListItem {
+	return h.listLinks(func(*ShortLinkInfo) bool { return true })
+}
+
+// listLinks returns the list items of the links matching include
+func (h *ShortLinkHandler) listLinks(include func(*ShortLinkInfo) bool) []ShortLinkListItem {
+	views := h.viewTotals()
+
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
 	items := make([]ShortLinkListItem, 0, len(h.links))
 	for code, info := range h.links {
+		if !include(info) {
+			continue
+		}
 		items = append(items, ShortLinkListItem{
-			Code:      code,
-			NoteID:    info.NoteID,
-			NoteTitle: "", // Will be populated by frontend or separate lookup
-			ShortLink: h.basePath + "/s/" + code,
-			ExpiresAt: info.ExpiresAt,
-			CreatedAt: info.CreatedAt,
-			IsPublic:  info.IsPublic,
+			Code:        code,
+			NoteID:      info.NoteID,
+			NoteTitle:   "", // Will be populated by frontend or separate lookup
+			ShortLink:   h.basePath + "/s/" + code,
+			ExpiresAt:   info.ExpiresAt,
+			CreatedAt:   info.CreatedAt,
+			IsPublic:    info.IsPublic,
+			HasPassword: info.PasswordHash != "",
+			Views:       views[code],
+			TotalViews:  info.ViewCount,
+			MaxViews:    info.MaxViews,
+			LastAccess:  info.LastAccessAt,
+			LastIP:      info.LastAccessIP,
+			Username:    info.Username,
+			FolderPath:  info.FolderPath,
 		})
 	}
-
-	c.JSON(http.StatusOK, items)
+	return items
 }
 
 // UpdateRequest represents the request body for updating a short link
 type UpdateRequest struct {
-	ExpiresIn *int  `json:"expires_in"` // Days until expiry (nil = no change, 0 = never expires, >0 = days)
-	IsPublic  *bool `json:"is_public"`  // Whether the link is publicly accessible
+	ExpiresIn *int    `json:"expires_in"` // Days until expiry (nil = no change, 0 = never expires, >0 = days)
+	IsPublic  *bool   `json:"is_public"`  // Whether the link is publicly accessible
+	Password  *string `json:"password"`   // Passphrase of a public note link (nil = no change, "" = remove)
+	MaxViews  *int    `json:"max_views"`  // Accesses after which the link expires (nil = no change, 0 = unlimited)
 }
 
 // UpdateByCode updates a short link's expiry by code
@@ -421,12 +811,16 @@ func (h *ShortLinkHandler) UpdateByCode(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.MaxViews != nil && *req.MaxViews < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max views"})
+		return
+	}
 
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	info, exists := h.links[code]
-	if !exists {
+	if !exists || info.Username != currentUsername(c) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Short link not found"})
 		return
 	}
@@ -446,14 +840,28 @@ func (h *ShortLinkHandler) UpdateByCode(c *gin.Context) {
 		info.IsPublic = *req.IsPublic
 	}
 
-	go h.save()
+	if req.Password != nil && info.FolderPath == "" {
+		if err := setLinkPassword(info, *req.Password); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if req.MaxViews != nil {
+		setMaxViews(info, *req.MaxViews)
+	}
+
+	h.storeLink(code, info)
 
 	c.JSON(http.StatusOK, gin.H{
-		"code":      code,
-		"shortLink": h.basePath + "/s/" + code,
-		"expiresAt": info.ExpiresAt,
-		"createdAt": info.CreatedAt,
-		"isPublic":  info.IsPublic,
+		"code":        code,
+		"shortLink":   h.basePath + "/s/" + code,
+		"expiresAt":   info.ExpiresAt,
+		"createdAt":   info.CreatedAt,
+		"isPublic":    info.IsPublic,
+		"hasPassword": info.PasswordHash != "",
+		"maxViews":    info.MaxViews,
+		"viewCount":   info.ViewCount,
 	})
 }
 
@@ -469,15 +877,16 @@ func (h *ShortLinkHandler) DeleteByCode(c *gin.Context) {
 	defer h.mu.Unlock()
 
 	info, exists := h.links[code]
-	if !exists {
+	if !exists || info.Username != currentUsername(c) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Short link not found"})
 		return
 	}
 
-	delete(h.reverseMap, info.NoteID)
+	h.unmapLink(info)
 	delete(h.links, code)
+	h.dropLink(code)
 
-	go h.save()
+	go h.forgetViews(code)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Short link deleted"})
 }
@@ -509,22 +918,114 @@ func (h *ShortLinkHandler) PublicPreview(c *gin.Context) {
 	if info.ExpiresAt != nil && info.ExpiresAt.Before(time.Now()) {
 		c.HTML(http.StatusGone, "expired.html", gin.H{
 			"basePath": h.basePath,
+			"lang":     middleware.Language(c),
 		})
 		return
 	}
 
+	h.recordView(c, code)
 	c.HTML(http.StatusOK, "preview.html", gin.H{
 		"basePath": h.basePath,
+		"lang":     middleware.Language(c),
 		"code":     code,
+		"meta":     h.notePageMeta(c, code, info),
 	})
 }
 
+// PrintPublic renders the note of a public link as a print-optimized page
+func (h *ShortLinkHandler) PrintPublic(c *gin.Context) {
+	code := c.Param("code")
+
+	h.mu.RLock()
+	info, exists := h.links[code]
+	h.mu.RUnlock()
+
+	if !exists || !info.IsPublic || info.FolderPath != "" {
+		c.String(http.StatusNotFound, tr(c, "Link not found"))
+		return
+	}
+
+	if info.ExpiresAt != nil && info.ExpiresAt.Before(time.Now()) {
+		c.HTML(http.StatusGone, "expired.html", gin.H{
+			"basePath": h.basePath,
+			"lang":     middleware.Language(c),
+		})
+		return
+	}
+
+	if !linkUnlocked(c, code, info) {
+		c.String(http.StatusUnauthorized, tr(c, "Password required"))
+		return
+	}
+
+	note := h.linkNote(code, info)
+	if note == nil {
+		c.String(http.StatusNotFound, tr(c, "Note not found"))
+		return
+	}
+	if note.Private {
+		c.String(http.StatusForbidden, tr(c, "This note is password protected"))
+		return
+	}
+
+	h.recordView(c, code)
+	c.HTML(http.StatusOK, "print.html", printPage(c, h.basePath, note))
+}
+
+// linkNote loads the note of a note link, or nil if it no longer exists or is encrypted
+func (h *ShortLinkHandler) linkNote(code string, info *ShortLinkInfo) *model.Note {
+	// Construct the file path: {storagePath}/{username}/notes/{noteId}.{ext}
+	notesPath := filepath.Join(h.config.Storage.Path, info.Username, "notes")
+	noteID := h.linkNoteID(code, info)
+
+	// Try different extensions
+	for _, ext := range []string{".md", ".txt", ".adoc"} {
+		filePath := filepath.Join(notesPath, noteID+ext)
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			continue
+		}
+		if encryption.IsEncrypted(string(data)) {
+			return nil
+		}
+		note, err := model.ParseNoteFromBytes(data, filePath)
+		if err == nil {
+			note.ID = noteID
+			return note
+		}
+	}
+	return nil
+}
+
 // GetPublicNote returns note content for public preview (no authentication required)
 func (h *ShortLinkHandler) GetPublicNote(c *gin.Context) {
+	note, _, ok := h.publicLinkNote(c)
+	if !ok {
+		return
+	}
+	h.recordAccess(c, c.Param("code"))
+
+	attachments := note.Attachments
+	if attachments == nil {
+		attachments = []model.Attachment{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"id":          note.ID,
+		"title":       note.Title,
+		"content":     note.Content,
+		"type":        note.Type,
+		"attachments": attachments,
+		"modified":    note.Modified,
+	})
+}
+
+// publicLinkNote loads the note of the public note link :code, responding with an error when
+// the link is unknown, not public, expired, locked by a passphrase, or the note is missing or password protected
+func (h *ShortLinkHandler) publicLinkNote(c *gin.Context) (*model.Note, *ShortLinkInfo, bool) {
 	code := c.Param("code")
 	if code == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Code required"})
-		return
+		return nil, nil, false
 	}
 
 	h.mu.RLock()
@@ -533,58 +1034,39 @@ func (h *ShortLinkHandler) GetPublicNote(c *gin.Context) {
 
 	if !exists {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
-		return
+		return nil, nil, false
 	}
 
 	// Check if link is public
 	if !info.IsPublic {
 		c.JSON(http.StatusForbidden, gin.H{"error": "This link is not public"})
-		return
+		return nil, nil, false
 	}
 
 	// Check if link has expired
 	if info.ExpiresAt != nil && info.ExpiresAt.Before(time.Now()) {
 		c.JSON(http.StatusGone, gin.H{"error": "Link has expired"})
-		return
+		return nil, nil, false
 	}
 
-	// Construct the file path: {storagePath}/{username}/notes/{noteId}.{ext}
-	notesPath := filepath.Join(h.config.Storage.Path, info.Username, "notes")
-
-	// Try different extensions
-	var note *model.Note
-	var err error
-	for _, ext := range []string{".md", ".txt", ".adoc"} {
-		filePath := filepath.Join(notesPath, info.NoteID+ext)
-		data, readErr := os.ReadFile(filePath)
-		if readErr != nil {
-			continue
-		}
-		note, err = model.ParseNoteFromBytes(data, filePath)
-		if err == nil {
-			note.ID = info.NoteID
-			break
-		}
+	// Links behind a passphrase need a token from /unlock
+	if !linkUnlocked(c, code, info) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password required", "password_required": true})
+		return nil, nil, false
 	}
 
+	note := h.linkNote(code, info)
 	if note == nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
-		return
+		return nil, nil, false
 	}
 
 	// Don't expose password-protected notes publicly
 	if note.Private {
 		c.JSON(http.StatusForbidden, gin.H{"error": "This note is password protected"})
-		return
+		return nil, nil, false
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"id":       note.ID,
-		"title":    note.Title,
-		"content":  note.Content,
-		"type":     note.Type,
-		"modified": note.Modified,
-	})
+	return note, info, true
 }
 
 // FolderGenerateRequest represents the request body for generating a folder short link
@@ -592,6 +1074,7 @@ type FolderGenerateRequest struct {
 	FolderPath string `json:"folder_path"`
 	ExpiresIn  *int   `json:"expires_in"` // Days until expiry (nil = never expires)
 	IsPublic   *bool  `json:"is_public"`  // Whether the link is publicly accessible
+	Blog       *bool  `json:"blog"`       // Publish the folder as a blog (nil = keep current)
 }
 
 // GenerateFolderLink creates or returns existing short link for a folder
@@ -606,9 +1089,12 @@ func (h *ShortLinkHandler) GenerateFolderLink(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Folder path required"})
 		return
 	}
+	if denyShare(c, strings.ReplaceAll(req.FolderPath, FolderSeparator, "/"), model.PermissionManage) {
+		return
+	}
 
-	// Get current user
-	user := middleware.GetCurrentUser(c)
+	// Get folder owner (share owner when sharing a shared folder)
+	user := storageOwner(c)
 	username := ""
 	if user != nil {
 		username = user.Username
@@ -618,7 +1104,7 @@ func (h *ShortLinkHandler) GenerateFolderLink(c *gin.Context) {
 	defer h.mu.Unlock()
 
 	// Check if short link already exists for this folder
-	if code, exists := h.folderReverseMap[req.FolderPath]; exists {
+	if code, exists := h.folderReverseMap[linkKey(username, req.FolderPath)]; exists {
 		info := h.links[code]
 		changed := false
 		// Update expiry if provided
@@ -636,16 +1122,14 @@ func (h *ShortLinkHandler) GenerateFolderLink(c *gin.Context) {
 			info.IsPublic = *req.IsPublic
 			changed = true
 		}
+		if req.Blog != nil {
+			info.Blog = *req.Blog
+			changed = true
+		}
 		if changed {
-			go h.save()
+			h.storeLink(code, info)
 		}
-		c.JSON(http.StatusOK, gin.H{
-			"code":       code,
-			"shortLink":  h.basePath + "/s/" + code,
-			"folderPath": info.FolderPath,
-			"expiresAt":  info.ExpiresAt,
-			"isPublic":   info.IsPublic,
-		})
+		c.JSON(http.StatusOK, h.folderLinkResponse(code, info))
 		return
 	}
 
@@ -671,24 +1155,38 @@ func (h *ShortLinkHandler) GenerateFolderLink(c *gin.Context) {
 		isPublic = *req.IsPublic
 	}
 
-	h.links[code] = &ShortLinkInfo{
+	info := &ShortLinkInfo{
 		FolderPath: req.FolderPath,
 		Username:   username,
 		ExpiresAt:  expiresAt,
 		CreatedAt:  time.Now(),
 		IsPublic:   isPublic,
+		Blog:       req.Blog != nil && *req.Blog,
 	}
-	h.folderReverseMap[req.FolderPath] = code
+	h.links[code] = info
+	h.mapLink(code, info)
+	h.storeLink(code, info)
 
-	go h.save()
+	recordAudit(h.auditRepo, c, model.AuditShortLinkCreate, username+":"+req.FolderPath, "folder")
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, h.folderLinkResponse(code, info))
+}
+
+// folderLinkResponse builds the API response for a folder short link
+func (h *ShortLinkHandler) folderLinkResponse(code string, info *ShortLinkInfo) gin.H {
+	response := gin.H{
 		"code":       code,
 		"shortLink":  h.basePath + "/s/" + code,
-		"folderPath": req.FolderPath,
-		"expiresAt":  expiresAt,
-		"isPublic":   isPublic,
-	})
+		"folderPath": info.FolderPath,
+		"expiresAt":  info.ExpiresAt,
+		"createdAt":  info.CreatedAt,
+		"isPublic":   info.IsPublic,
+		"blog":       info.Blog,
+	}
+	if info.Blog {
+		response["blogLink"] = h.basePath + "/blog/" + code
+	}
+	return response
 }
 
 // GetFolderLink returns the short link for a folder if it exists
@@ -699,8 +1197,13 @@ func (h *ShortLinkHandler) GetFolderLink(c *gin.Context) {
 		return
 	}
 
+	username := ""
+	if user := storageOwner(c); user != nil {
+		username = user.Username
+	}
+
 	h.mu.RLock()
-	code, exists := h.folderReverseMap[folderPath]
+	code, exists := h.folderReverseMap[linkKey(username, folderPath)]
 	var info *ShortLinkInfo
 	if exists {
 		info = h.links[code]
@@ -712,14 +1215,7 @@ func (h *ShortLinkHandler) GetFolderLink(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"code":       code,
-		"shortLink":  h.basePath + "/s/" + code,
-		"folderPath": info.FolderPath,
-		"expiresAt":  info.ExpiresAt,
-		"createdAt":  info.CreatedAt,
-		"isPublic":   info.IsPublic,
-	})
+	c.JSON(http.StatusOK, h.folderLinkResponse(code, info))
 }
 
 // DeleteFolderLink removes a folder short link
@@ -729,20 +1225,29 @@ func (h *ShortLinkHandler) DeleteFolderLink(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Folder path required"})
 		return
 	}
+	if denyShare(c, strings.ReplaceAll(folderPath, FolderSeparator, "/"), model.PermissionManage) {
+		return
+	}
+
+	username := ""
+	if user := storageOwner(c); user != nil {
+		username = user.Username
+	}
 
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	code, exists := h.folderReverseMap[folderPath]
+	code, exists := h.folderReverseMap[linkKey(username, folderPath)]
 	if !exists {
 		c.JSON(http.StatusNotFound, gin.H{"error": "No short link for this folder"})
 		return
 	}
 
+	h.unmapLink(h.links[code])
 	delete(h.links, code)
-	delete(h.folderReverseMap, folderPath)
+	h.dropLink(code)
 
-	go h.save()
+	go h.forgetViews(code)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Folder short link deleted"})
 }
@@ -774,13 +1279,17 @@ func (h *ShortLinkHandler) FolderPreview(c *gin.Context) {
 	if info.ExpiresAt != nil && info.ExpiresAt.Before(time.Now()) {
 		c.HTML(http.StatusGone, "expired.html", gin.H{
 			"basePath": h.basePath,
+			"lang":     middleware.Language(c),
 		})
 		return
 	}
 
+	h.recordView(c, code)
 	c.HTML(http.StatusOK, "folder-preview.html", gin.H{
 		"basePath": h.basePath,
+		"lang":     middleware.Language(c),
 		"code":     code,
+		"meta":     h.folderPageMeta(c, info, "/folder-preview/"+code),
 	})
 }
 
@@ -902,10 +1411,18 @@ func (h *ShortLinkHandler) GetPublicFolder(c *gin.Context) {
 		return nil
 	})
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"folderPath": info.FolderPath,
 		"notes":      notes,
-	})
+	}
+	if h.db != nil {
+		if meta := getFolderMetaByUsername(h.db, info.Username, strings.ReplaceAll(info.FolderPath, FolderSeparator, "/")); meta != nil {
+			response["description"] = meta.Description
+			response["color"] = meta.Color
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // GetPublicFolderNote returns a specific note from a shared folder
@@ -940,7 +1457,7 @@ func (h *ShortLinkHandler) GetPublicFolderNote(c *gin.Context) {
 	}
 
 	// Decode note ID (format: "FolderPath/uuid" with / separator)
-	decodedNoteID := decodeNoteIDParam(noteID)
+	decodedNoteID := decodeNoteID(noteID)
 
 	// Convert shared folder path from :>: to / separator for comparison
 	sharedFolderWithSlash := strings.ReplaceAll(info.FolderPath, ":>:", "/")
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// GetPublicNoteAttachment serves a file or image referenced by the note of a public note link,
+// so shared pages can show attachments without exposing other files of the owner
+// Only attachments listed in the note or linked from its content are served (with Range support)
+func (h *ShortLinkHandler) GetPublicNoteAttachment(c *gin.Context) {
+	note, info, ok := h.publicLinkNote(c)
+	if !ok {
+		return
+	}
+
+	filename := c.Param("filename")
+	if !validAttachmentName(filename) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filename"})
+		return
+	}
+
+	kind, attachment := noteAttachmentRef(note, filename)
+	if kind == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+
+	filePath := filepath.Join(h.config.Storage.Path, info.Username, kind, filename)
+	if _, err := os.Stat(filePath); err != nil {
+		// Legacy global directory used before per-user storage
+		filePath = filepath.Join(h.config.Storage.Path, kind, filename)
+		if _, err := os.Stat(filePath); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+			return
+		}
+	}
+
+	if c.Query("download") == "true" {
+		name := filename
+		if attachment != nil && attachment.Name != "" {
+			name = attachment.Name
+		}
+		safeFilename := strings.ReplaceAll(name, `\`, `\\`)
+		safeFilename = strings.ReplaceAll(safeFilename, `"`, `\"`)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, safeFilename, url.PathEscape(name)))
+	}
+	serveAttachment(c, filePath)
+}
+
+// noteAttachmentRef finds an uploaded file referenced by a note by its stored filename
+// Returns the storage directory ("files" or "images", "" if the note does not reference it)
+// and the note's attachment entry when it has one
+func noteAttachmentRef(note *model.Note, filename string) (string, *model.Attachment) {
+	pattern := regexp.MustCompile(`/(files|images)/` + regexp.QuoteMeta(filename) + `(?:[?#"')\]\s]|$)`)
+
+	for i := range note.Attachments {
+		if m := pattern.FindStringSubmatch(note.Attachments[i].URL); m != nil {
+			return m[1], &note.Attachments[i]
+		}
+	}
+	if m := pattern.FindStringSubmatch(note.Content); m != nil {
+		return m[1], nil
+	}
+	return "", nil
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/middleware"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// linkUnlockTTL is how long a public link stays unlocked after its passphrase was entered
+const linkUnlockTTL = time.Hour
+
+// linkTokenCookie carries the unlock token to attachments and the print page, which can't send headers
+const linkTokenCookie = "link_token"
+
+// linkUnlockAttempts limits wrong passphrases per client and link: 5 in a row lock the client out for 15 minutes
+var linkUnlockAttempts = newAttemptLimiter(5, 15*time.Minute)
+
+// LinkUnlockTokens stores the short-lived tokens issued for passphrase-protected public links
+type LinkUnlockTokens struct {
+	sync.Mutex
+	tokens map[string]linkUnlock // token -> unlocked link
+}
+
+type linkUnlock struct {
+	code         string
+	passwordHash string // Hash the token was issued for; changing the passphrase invalidates it
+	expiresAt    time.Time
+}
+
+var publicLinkTokens = &LinkUnlockTokens{
+	tokens: make(map[string]linkUnlock),
+}
+
+// Issue creates a token unlocking a link until linkUnlockTTL passes
+func (t *LinkUnlockTokens) Issue(code, passwordHash string) (string, time.Time) {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	token := hex.EncodeToString(bytes)
+
+	t.Lock()
+	defer t.Unlock()
+
+	// Drop expired tokens
+	now := time.Now()
+	for k, v := range t.tokens {
+		if now.After(v.expiresAt) {
+			delete(t.tokens, k)
+		}
+	}
+
+	expiresAt := now.Add(linkUnlockTTL)
+	t.tokens[token] = linkUnlock{
+		code:         code,
+		passwordHash: passwordHash,
+		expiresAt:    expiresAt,
+	}
+	return token, expiresAt
+}
+
+// Valid reports whether a token unlocks a link with its current passphrase
+// Unlike confirmation tokens, unlock tokens can be used until they expire
+func (t *LinkUnlockTokens) Valid(token, code, passwordHash string) bool {
+	if token == "" {
+		return false
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	entry, ok := t.tokens[token]
+	if !ok {
+		return false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(t.tokens, token)
+		return false
+	}
+	return entry.code == code && entry.passwordHash == passwordHash
+}
+
+// setLinkPassword sets the passphrase of a link ("" removes it)
+func setLinkPassword(info *ShortLinkInfo, password string) error {
+	if password == "" {
+		info.PasswordHash = ""
+		return nil
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash link password: %w", err)
+	}
+	info.PasswordHash = string(hash)
+	return nil
+}
+
+// linkUnlocked reports whether the request carries a valid unlock token for a link,
+// passed in the X-Link-Token header or the link's cookie (for attachments and print pages)
+// Tokens are never taken from the URL, where they would end up in logs and Referer headers
+// Links without a passphrase are always unlocked
+func linkUnlocked(c *gin.Context, code string, info *ShortLinkInfo) bool {
+	if info.PasswordHash == "" {
+		return true
+	}
+	token := c.GetHeader("X-Link-Token")
+	if token == "" {
+		token, _ = c.Cookie(linkTokenCookie)
+	}
+	return publicLinkTokens.Valid(token, code, info.PasswordHash)
+}
+
+// setLinkTokenCookies stores an unlock token in cookies scoped to the paths of one link:
+// its note and attachments, and its print page
+func (h *ShortLinkHandler) setLinkTokenCookies(c *gin.Context, code, token string) {
+	escaped := url.PathEscape(code)
+	for _, path := range []string{h.basePath + "/api/public/note/" + escaped, h.basePath + "/print/s/" + escaped} {
+		http.SetCookie(c.Writer, &http.Cookie{
+			Name:     linkTokenCookie,
+			Value:    token,
+			MaxAge:   int(linkUnlockTTL.Seconds()),
+			Path:     path,
+			Secure:   middleware.IsHTTPS(c),
+			HttpOnly: true,
+			SameSite: http.SameSiteStrictMode,
+		})
+	}
+}
+
+// UnlockRequest represents the request body for unlocking a public link
+type UnlockRequest struct {
+	Password string `json:"password"`
+}
+
+// UnlockPublicNote checks the passphrase of a public note link and issues a token
+// that unlocks the note and its attachments for linkUnlockTTL
+func (h *ShortLinkHandler) UnlockPublicNote(c *gin.Context) {
+	code := c.Param("code")
+
+	var req UnlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	h.mu.RLock()
+	info, exists := h.links[code]
+	var passwordHash string
+	if exists {
+		passwordHash = info.PasswordHash
+	}
+	h.mu.RUnlock()
+
+	if !exists || info.FolderPath != "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+		return
+	}
+	if !info.IsPublic {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This link is not public"})
+		return
+	}
+	if info.ExpiresAt != nil && info.ExpiresAt.Before(time.Now()) {
+		c.JSON(http.StatusGone, gin.H{"error": "Link has expired"})
+		return
+	}
+	if passwordHash == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This link has no password"})
+		return
+	}
+
+	attemptKey := c.ClientIP() + " " + code
+	if ok, wait := linkUnlockAttempts.Allow(attemptKey); !ok {
+		c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many attempts, try again later"})
+		return
+	}
+	if bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password)) != nil {
+		linkUnlockAttempts.Fail(attemptKey)
+		encoding.Warn("Link unlock failed: code=%s, ip=%s", code, c.ClientIP())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		return
+	}
+	linkUnlockAttempts.Reset(attemptKey)
+
+	token, expiresAt := publicLinkTokens.Issue(code, passwordHash)
+	h.setLinkTokenCookies(c, code, token)
+	c.JSON(http.StatusOK, gin.H{
+		"token":      token,
+		"expires_at": expiresAt,
+	})
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/notify"
+)
+
+const (
+	viewDayLayout   = "2006-01-02"
+	viewListDays    = 30 // Days summed into the view count of the link list
+	viewDefaultDays = 30 // Days returned by the daily views API unless ?days= is given
+)
+
+// botUserAgents marks crawlers and chat unfurlers, whose fetches are not counted as views
+var botUserAgents = []string{"bot", "crawler", "spider", "slurp", "facebookexternalhit", "embedly", "preview", "curl", "wget"}
+
+func isBotUserAgent(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	if userAgent == "" {
+		return true
+	}
+	for _, bot := range botUserAgents {
+		if strings.Contains(userAgent, bot) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordView counts a view of a public page
+// IP addresses are never stored; with analytics.unique_visitors they are hashed with a salt that
+// only lives in memory and is replaced every day, so visitors cannot be followed across days
+func (h *ShortLinkHandler) recordView(c *gin.Context, code string) {
+	if h.viewRepo == nil || h.config.Analytics.Disabled || isBotUserAgent(c.Request.UserAgent()) {
+		return
+	}
+
+	day := time.Now().Format(viewDayLayout)
+	visitorHash := ""
+	if h.config.Analytics.UniqueVisitors {
+		visitorHash = h.visitorHash(day, code, c.ClientIP(), c.Request.UserAgent())
+	}
+	if err := h.viewRepo.Record(code, day, visitorHash); err != nil {
+		encoding.Warn("Failed to record view of %s: %v", code, err)
+	}
+}
+
+// recordAccess counts an access of a link towards its lifetime views and max-view limit, and
+// remembers when it was last opened (and from which IP with analytics.record_ip)
+// A link expires once it reaches its max views; the access that reached the limit is still served
+func (h *ShortLinkHandler) recordAccess(c *gin.Context, code string) {
+	if isBotUserAgent(c.Request.UserAgent()) {
+		return
+	}
+
+	now := time.Now()
+	h.mu.Lock()
+	info, exists := h.links[code]
+	if !exists {
+		h.mu.Unlock()
+		return
+	}
+	info.ViewCount++
+	if !h.config.Analytics.Disabled {
+		info.LastAccessAt = &now
+		if h.config.Analytics.RecordIP {
+			info.LastAccessIP = c.ClientIP()
+		}
+	}
+	if info.MaxViews > 0 && info.ViewCount >= info.MaxViews && (info.ExpiresAt == nil || info.ExpiresAt.After(now)) {
+		info.ExpiresAt = &now
+	}
+	h.storeLink(code, info)
+	event := notify.Event{
+		Type:     notify.LinkViewed,
+		Username: info.Username,
+		NoteID:   info.NoteID,
+		Folder:   info.FolderPath,
+		Code:     code,
+		Views:    info.ViewCount,
+	}
+	h.mu.Unlock()
+
+	h.events.Publish(event)
+}
+
+// setMaxViews sets the accesses after which a link expires (0 = unlimited)
+// A limit the link has already reached expires it right away
+func setMaxViews(info *ShortLinkInfo, maxViews int) {
+	info.MaxViews = maxViews
+	now := time.Now()
+	if maxViews > 0 && info.ViewCount >= maxViews && (info.ExpiresAt == nil || info.ExpiresAt.After(now)) {
+		info.ExpiresAt = &now
+	}
+}
+
+// visitorHash returns an anonymous visitor ID for one link and day
+func (h *ShortLinkHandler) visitorHash(day, code, ip, userAgent string) string {
+	h.saltMu.Lock()
+	if h.viewSaltDay != day {
+		h.viewSalt = make([]byte, 32)
+		rand.Read(h.viewSalt)
+		h.viewSaltDay = day
+	}
+	salt := h.viewSalt
+	h.saltMu.Unlock()
+
+	sum := sha256.New()
+	sum.Write(salt)
+	sum.Write([]byte(code + "\x00" + ip + "\x00" + userAgent))
+	return hex.EncodeToString(sum.Sum(nil)[:16])
+}
+
+// forgetViews removes the counts of deleted links
+func (h *ShortLinkHandler) forgetViews(codes ...string) {
+	if h.viewRepo == nil {
+		return
+	}
+	for _, code := range codes {
+		if err := h.viewRepo.DeleteByCode(code); err != nil {
+			encoding.Warn("Failed to delete views of %s: %v", code, err)
+		}
+	}
+}
+
+// renameViews moves the counts of a link whose code changed
+func (h *ShortLinkHandler) renameViews(oldCode, newCode string) {
+	if h.viewRepo == nil {
+		return
+	}
+	if err := h.viewRepo.RenameCode(oldCode, newCode); err != nil {
+		encoding.Warn("Failed to move views of %s to %s: %v", oldCode, newCode, err)
+	}
+}
+
+// pruneViews drops counts older than analytics.retention_days and yesterday's visitor hashes
+func (h *ShortLinkHandler) pruneViews() {
+	if h.viewRepo == nil {
+		return
+	}
+	now := time.Now()
+	keepSince := now.AddDate(0, 0, -h.config.Analytics.RetentionDays).Format(viewDayLayout)
+	if err := h.viewRepo.Prune(keepSince, now.Format(viewDayLayout)); err != nil {
+		encoding.Warn("Failed to prune link views: %v", err)
+	}
+}
+
+// viewTotals returns the views of each link over the last viewListDays days
+func (h *ShortLinkHandler) viewTotals() map[string]int {
+	if h.viewRepo == nil {
+		return nil
+	}
+	totals, err := h.viewRepo.Totals(time.Now().AddDate(0, 0, -(viewListDays - 1)).Format(viewDayLayout))
+	if err != nil {
+		encoding.Warn("Failed to get link view totals: %v", err)
+		return nil
+	}
+	return totals
+}
+
+// currentUsername returns the name of the signed-in user ("" without authentication),
+// who owns the links managed by code
+func currentUsername(c *gin.Context) string {
+	if user := middleware.GetCurrentUser(c); user != nil {
+		return user.Username
+	}
+	return ""
+}
+
+// GetViews returns the daily views of a link owned by the current user (?days=N, default 30)
+// Every day of the range is listed, including days without views
+func (h *ShortLinkHandler) GetViews(c *gin.Context) {
+	code := c.Param("code")
+
+	h.mu.RLock()
+	info, exists := h.links[code]
+	h.mu.RUnlock()
+
+	if !exists || info.Username != currentUsername(c) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Short link not found"})
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(viewDefaultDays)))
+	if err != nil || days < 1 || days > h.config.Analytics.RetentionDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days"})
+		return
+	}
+
+	series := make([]model.LinkViewDay, days)
+	now := time.Now()
+	for i := range series {
+		series[i].Day = now.AddDate(0, 0, i-days+1).Format(viewDayLayout)
+	}
+
+	views, visitors := 0, 0
+	if h.viewRepo != nil {
+		recorded, err := h.viewRepo.Daily(code, series[0].Day)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get views"})
+			return
+		}
+		byDay := make(map[string]model.LinkViewDay, len(recorded))
+		for _, d := range recorded {
+			byDay[d.Day] = d
+		}
+		for i := range series {
+			if d, ok := byDay[series[i].Day]; ok {
+				series[i] = d
+				views += d.Views
+				visitors += d.Visitors
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":            code,
+		"days":            series,
+		"views":           views,
+		"visitors":        visitors,
+		"unique_visitors": h.config.Analytics.UniqueVisitors,
+		"enabled":         !h.config.Analytics.Disabled,
+	})
+}
@@ -3,29 +3,39 @@ package handler
 import (
 	"archive/zip"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/user/gitnotepad/internal/config"
+	"github.com/user/gitnotepad/internal/database"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/encryption"
 	"github.com/user/gitnotepad/internal/middleware"
 	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/repository"
 )
 
 type StatsHandler struct {
 	config   *config.Config
 	basePath string
+	db       *database.DB                     // UI settings included in full exports
+	prefRepo *repository.PreferenceRepository // (nil = notes only)
 }
 
-func NewStatsHandler(cfg *config.Config) *StatsHandler {
+func NewStatsHandler(cfg *config.Config, db *database.DB, prefRepo *repository.PreferenceRepository) *StatsHandler {
 	return &StatsHandler{
 		config:   cfg,
 		basePath: cfg.Storage.Path,
+		db:       db,
+		prefRepo: prefRepo,
 	}
 }
 
@@ -175,6 +185,24 @@ func (h *StatsHandler) ExportNotes(c *gin.Context) {
 	storagePath := h.getUserStoragePath(c)
 	notesPath := h.getNotesPath(c)
 	folderPath := c.Query("folder") // Optional folder filter
+	format := c.Query("format")     // "" (gitNotepad backup) or "markdown" (portable folder layout)
+	if format != "" && format != "markdown" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export format"})
+		return
+	}
+
+	// ?decrypt=true writes encrypted notes in plaintext so the backup is readable outside GitNotepad
+	// (markdown exports are always decrypted); uses the encryption key of the session
+	encryptionKey := middleware.GetEncryptionKey(c)
+	var decryptKey []byte
+	decrypted := 0
+	if c.Query("decrypt") == "true" {
+		if encryptionKey == nil && h.config.Encryption.Enabled {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Sign in again to export decrypted notes (encryption key not available)"})
+			return
+		}
+		decryptKey = encryptionKey
+	}
 
 	// Create ZIP buffer
 	buf := new(bytes.Buffer)
@@ -183,8 +211,13 @@ func (h *StatsHandler) ExportNotes(c *gin.Context) {
 	// Track which attachment UUIDs are referenced by exported notes
 	referencedAttachments := make(map[string]bool)
 
-	// If folder is specified, only export notes from that folder
-	if folderPath != "" {
+	if format == "markdown" {
+		if _, err := h.exportPortable(c, zipWriter, storagePath, notesPath, folderPath, nil); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create export"})
+			return
+		}
+	} else if folderPath != "" {
+		// If folder is specified, only export notes from that folder
 		// Normalize folder path - frontend sends "/" separator, internal uses "/" for FolderPath
 		normalizedFolder := strings.ReplaceAll(folderPath, ":>:", "/")
 
@@ -209,7 +242,16 @@ func (h *StatsHandler) ExportNotes(c *gin.Context) {
 			}
 
 			// Parse the note to check its folder path
-			note, parseErr := model.ParseNoteFromFile(path)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil
+			}
+			if encryption.IsEncrypted(string(data)) && encryptionKey != nil {
+				if data, err = encryption.Decrypt(string(data), encryptionKey); err != nil {
+					return nil
+				}
+			}
+			note, parseErr := model.ParseNoteFromBytes(data, path)
 			if parseErr != nil {
 				return nil
 			}
@@ -227,28 +269,12 @@ func (h *StatsHandler) ExportNotes(c *gin.Context) {
 				return err
 			}
 
-			// Create header
-			header, err := zip.FileInfoHeader(info)
-			if err != nil {
-				return err
-			}
-
-			header.Name = "notes/" + relPath
-			header.Method = zip.Deflate
-
-			writer, err := zipWriter.CreateHeader(header)
-			if err != nil {
-				return err
-			}
-
-			file, err := os.Open(path)
+			ok, err := addNoteZipFile(zipWriter, path, "notes/"+filepath.ToSlash(relPath), decryptKey)
 			if err != nil {
 				return err
 			}
-			defer file.Close()
-			_, err = io.Copy(writer, file)
-			if err != nil {
-				return err
+			if ok {
+				decrypted++
 			}
 
 			// Track attachments referenced in this note
@@ -356,6 +382,14 @@ func (h *StatsHandler) ExportNotes(c *gin.Context) {
 				return nil
 			}
 
+			if decryptKey != nil && !info.IsDir() && isExportedNoteFile(relPath) {
+				ok, err := addNoteZipFile(zipWriter, path, filepath.ToSlash(relPath), decryptKey)
+				if ok {
+					decrypted++
+				}
+				return err
+			}
+
 			// Create header
 			header, err := zip.FileInfoHeader(info)
 			if err != nil {
@@ -393,6 +427,23 @@ func (h *StatsHandler) ExportNotes(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create export"})
 			return
 		}
+
+		// UI settings (folder icons, order, preferences) live in the database; add them so a move keeps them
+		if user := middleware.GetCurrentUser(c); user != nil && h.db != nil {
+			settings, err := loadUISettings(h.db, h.prefRepo, user.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create export"})
+				return
+			}
+			writer, err := zipWriter.CreateHeader(&zip.FileHeader{Name: uiSettingsFile, Method: zip.Deflate, Modified: time.Now()})
+			if err == nil {
+				err = json.NewEncoder(writer).Encode(settings)
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create export"})
+				return
+			}
+		}
 	}
 
 	zipWriter.Close()
@@ -411,6 +462,15 @@ func (h *StatsHandler) ExportNotes(c *gin.Context) {
 		folderName = strings.ReplaceAll(folderName, ":", "-")
 		filename = fmt.Sprintf("folder-%s-export", folderName)
 	}
+	if format == "markdown" {
+		filename += "-markdown"
+	} else if decryptKey != nil {
+		filename += "-decrypted"
+	}
+	// Warn clients that notes stored encrypted are in the ZIP in plaintext
+	if decryptKey != nil && format == "" {
+		c.Header("X-Export-Decrypted", strconv.Itoa(decrypted))
+	}
 
 	// Send ZIP file
 	c.Header("Content-Type", "application/zip")
@@ -446,6 +506,7 @@ func (h *StatsHandler) ImportNotes(c *gin.Context) {
 	}
 
 	imported := 0
+	settingsImported := 0
 
 	// Extract files
 	for _, zipFile := range zipReader.File {
@@ -454,6 +515,14 @@ func (h *StatsHandler) ImportNotes(c *gin.Context) {
 			continue
 		}
 
+		// UI settings of a full export go back into the database
+		if zipFile.Name == uiSettingsFile {
+			if user := middleware.GetCurrentUser(c); user != nil && h.db != nil {
+				settingsImported += h.importSettingsFile(zipFile, user.ID)
+			}
+			continue
+		}
+
 		// Skip .git files
 		if strings.Contains(zipFile.Name, ".git") {
 			continue
@@ -589,7 +658,26 @@ func (h *StatsHandler) ImportNotes(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"imported": imported})
+	c.JSON(http.StatusOK, gin.H{"imported": imported, "settings": settingsImported})
+}
+
+// importSettingsFile restores the UI settings stored in an export, returning how many were applied
+func (h *StatsHandler) importSettingsFile(zipFile *zip.File, userID int64) int {
+	file, err := zipFile.Open()
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	var settings UISettings
+	if err := json.NewDecoder(io.LimitReader(file, maxUISettingsSize)).Decode(&settings); err != nil || settings.Version > uiSettingsVersion {
+		return 0
+	}
+	imported, err := saveUISettings(h.db, h.prefRepo, userID, &settings)
+	if err != nil {
+		encoding.Warn("Failed to import settings: %v", err)
+	}
+	return imported
 }
 
 func (h *StatsHandler) DeleteAllNotes(c *gin.Context) {
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encryption"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// PlaintextNote is a note stored without encryption
+type PlaintextNote struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// FolderEncryption counts encrypted and plaintext notes directly in a folder ("" for the top level)
+type FolderEncryption struct {
+	Path           string          `json:"path"`
+	Encrypted      int             `json:"encrypted"`
+	Plaintext      int             `json:"plaintext"`
+	PlaintextNotes []PlaintextNote `json:"plaintext_notes"`
+}
+
+// EncryptionStats reports how many notes are stored encrypted vs in the clear, per folder,
+// listing the plaintext ones so users who enabled encryption later can find notes written before
+func (h *StatsHandler) EncryptionStats(c *gin.Context) {
+	notesPath := h.getNotesPath(c)
+
+	folders := make(map[string]*FolderEncryption)
+	total, encrypted := 0, 0
+
+	filepath.WalkDir(notesPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		ext := filepath.Ext(path)
+		if ext != ".md" && ext != ".txt" && ext != ".adoc" {
+			return nil
+		}
+		relPath, err := filepath.Rel(notesPath, path)
+		if err != nil {
+			return nil
+		}
+		id := strings.TrimSuffix(filepath.ToSlash(relPath), ext)
+		folderPath, _ := splitFolderPath(id)
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+
+		folder := folders[folderPath]
+		if folder == nil {
+			folder = &FolderEncryption{Path: folderPath, PlaintextNotes: []PlaintextNote{}}
+			folders[folderPath] = folder
+		}
+		total++
+
+		if encryption.IsEncrypted(string(data)) {
+			encrypted++
+			folder.Encrypted++
+			return nil
+		}
+
+		folder.Plaintext++
+		title := filepath.Base(id)
+		if note, err := model.ParseNoteFromBytes(data, path); err == nil && note.Title != "" {
+			title = note.Title
+		}
+		folder.PlaintextNotes = append(folder.PlaintextNotes, PlaintextNote{ID: id, Title: title})
+		return nil
+	})
+
+	result := make([]*FolderEncryption, 0, len(folders))
+	for _, folder := range folders {
+		sort.Slice(folder.PlaintextNotes, func(i, j int) bool {
+			return strings.ToLower(folder.PlaintextNotes[i].Title) < strings.ToLower(folder.PlaintextNotes[j].Title)
+		})
+		result = append(result, folder)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":   h.config.Encryption.Enabled,
+		"total":     total,
+		"encrypted": encrypted,
+		"plaintext": total - encrypted,
+		"folders":   result,
+	})
+}
@@ -0,0 +1,434 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encryption"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// portableFrontmatter holds only fields that Obsidian, Joplin and other markdown tools understand
+type portableFrontmatter struct {
+	Title   string    `yaml:"title"`
+	Aliases []string  `yaml:"aliases,omitempty"`
+	Tags    []string  `yaml:"tags,omitempty"`
+	Created time.Time `yaml:"created"`
+	Updated time.Time `yaml:"updated"`
+}
+
+// exportFileNameReplacer replaces characters that are invalid in file names on common platforms
+var exportFileNameReplacer = strings.NewReplacer(
+	"/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_",
+)
+
+// portableFolder tracks the names used inside one exported folder
+type portableFolder struct {
+	names       map[string]bool   // Lowercased note and attachment file names in use
+	attachments map[string]string // Stored filename -> exported attachment name
+}
+
+// exportPortable writes notes as plain folder-structured files (one file per note, named by title)
+// Attachments are copied into an attachments/ folder next to the notes that use them and
+// links are rewritten to ./attachments/<name>, so the export opens directly in other tools
+// selected limits the export to notes with those IDs or uids (nil = all notes in the folder filter)
+// Returns the number of notes exported
+func (h *StatsHandler) exportPortable(c *gin.Context, zipWriter *zip.Writer, storagePath, notesPath, folderFilter string, selected map[string]bool) (int, error) {
+	encryptionKey := middleware.GetEncryptionKey(c)
+	folderFilter = strings.ReplaceAll(folderFilter, ":>:", "/")
+	exported := 0
+
+	// Original names of uploaded files (UUID filename -> name)
+	originalNames := loadMetadataFile(filepath.Join(storagePath, "files", ".imagemeta.json"))
+	for name, original := range loadMetadataFile(filepath.Join(storagePath, "files", ".filemeta.json")) {
+		originalNames[name] = original
+	}
+
+	linkPattern := h.attachmentLinkPattern()
+
+	folders := make(map[string]*portableFolder)
+
+	err := filepath.WalkDir(notesPath, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		ext := filepath.Ext(filePath)
+		if ext != ".md" && ext != ".txt" && ext != ".adoc" {
+			return nil
+		}
+
+		relDir, err := filepath.Rel(notesPath, filepath.Dir(filePath))
+		if err != nil {
+			return nil
+		}
+		relDir = filepath.ToSlash(relDir)
+		if relDir == "." {
+			relDir = ""
+		}
+		if folderFilter != "" && relDir != folderFilter && !strings.HasPrefix(relDir, folderFilter+"/") {
+			return nil
+		}
+
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil
+		}
+		if encryption.IsEncrypted(string(data)) {
+			if encryptionKey == nil {
+				return nil
+			}
+			if data, err = encryption.Decrypt(string(data), encryptionKey); err != nil {
+				return nil
+			}
+		}
+		note, err := model.ParseNoteFromBytes(data, filePath)
+		if err != nil {
+			return nil
+		}
+		if selected != nil && !selected[path.Join(relDir, strings.TrimSuffix(d.Name(), ext))] && !selected[note.UID] {
+			return nil
+		}
+
+		folder := folders[relDir]
+		if folder == nil {
+			folder = &portableFolder{names: make(map[string]bool), attachments: make(map[string]string)}
+			folders[relDir] = folder
+		}
+
+		// Copy linked attachments and point the links at ./attachments/
+		attachmentNames := make(map[string]string) // Note attachment URL -> exported name
+		for _, att := range note.Attachments {
+			attachmentNames[att.URL] = att.Name
+		}
+		content := linkPattern.ReplaceAllStringFunc(note.Content, func(link string) string {
+			stored := linkPattern.FindStringSubmatch(link)[1]
+			exported, ok := folder.attachments[stored]
+			if !ok {
+				src := findStoredFile(storagePath, stored)
+				if src == "" {
+					return link
+				}
+				name := attachmentNames[link]
+				if name == "" {
+					name = originalNames[stored]
+				}
+				if name == "" {
+					name = stored
+				}
+				exported = uniqueExportName(folder.names, "attachments/"+exportFileNameReplacer.Replace(name))
+				if err := addZipFile(zipWriter, src, path.Join(relDir, exported)); err != nil {
+					return link
+				}
+				folder.attachments[stored] = exported
+			}
+			return "./" + strings.ReplaceAll(exported, " ", "%20")
+		})
+
+		title := strings.TrimSpace(exportFileNameReplacer.Replace(note.Title))
+		if title == "" {
+			title = strings.TrimSuffix(d.Name(), ext)
+		}
+		fileName := uniqueExportName(folder.names, title+ext)
+
+		var out []byte
+		if ext == ".md" {
+			frontmatter, err := yaml.Marshal(portableFrontmatter{
+				Title:   note.Title,
+				Aliases: note.Aliases,
+				Tags:    note.Tags,
+				Created: note.Created,
+				Updated: note.Modified,
+			})
+			if err != nil {
+				return nil
+			}
+			out = []byte("---\n" + string(frontmatter) + "---\n\n" + content)
+		} else {
+			out = []byte(content)
+		}
+
+		header := &zip.FileHeader{
+			Name:     path.Join(relDir, fileName),
+			Method:   zip.Deflate,
+			Modified: note.Modified,
+		}
+		writer, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err = writer.Write(out); err != nil {
+			return err
+		}
+		exported++
+		return nil
+	})
+	return exported, err
+}
+
+// attachmentLinkPattern matches links to uploaded files: [host]<basePath>/u/<user>/{files,images}/<filename>
+func (h *StatsHandler) attachmentLinkPattern() *regexp.Regexp {
+	return regexp.MustCompile(`(?:https?://[^/\s()"'<>]+)?` + regexp.QuoteMeta(h.config.Server.BasePath) +
+		`/u/[^/\s()"'<>]+/(?:files|images)/([^/\s()"'<>?#]+)`)
+}
+
+// ExportSelectedRequest exports an explicit selection of notes
+type ExportSelectedRequest struct {
+	IDs    []string `json:"ids" binding:"required"` // Note IDs ("folder/name") or uids
+	Format string   `json:"format"`                 // "" (gitNotepad backup) or "markdown" (portable folder layout)
+}
+
+// ExportSelectedNotes exports the listed notes and the attachments they reference as a ZIP,
+// for sharing an ad-hoc selection such as all notes with a tag
+func (h *StatsHandler) ExportSelectedNotes(c *gin.Context) {
+	var req ExportSelectedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Format != "" && req.Format != "markdown" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export format"})
+		return
+	}
+
+	selected := make(map[string]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if id = normalizeName(strings.Trim(id, "/ ")); id != "" {
+			selected[id] = true
+		}
+	}
+	if len(selected) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Note IDs required"})
+		return
+	}
+
+	storagePath := h.getUserStoragePath(c)
+	notesPath := h.getNotesPath(c)
+
+	buf := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(buf)
+	var exported int
+	var err error
+	if req.Format == "markdown" {
+		exported, err = h.exportPortable(c, zipWriter, storagePath, notesPath, "", selected)
+	} else {
+		exported, err = h.exportSelectedBackup(c, zipWriter, storagePath, notesPath, selected)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create export"})
+		return
+	}
+	if exported == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No matching notes found"})
+		return
+	}
+	zipWriter.Close()
+
+	filename := "notes-selection"
+	if req.Format == "markdown" {
+		filename += "-markdown"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s.zip", filename, time.Now().Format("2006-01-02")))
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// exportSelectedBackup writes the selected note files as stored (the notes/, files/ and images/ layout
+// of a full export, so it can be imported again) with the uploaded files they link to or list as attachments
+// Returns the number of notes exported
+func (h *StatsHandler) exportSelectedBackup(c *gin.Context, zipWriter *zip.Writer, storagePath, notesPath string, selected map[string]bool) (int, error) {
+	encryptionKey := middleware.GetEncryptionKey(c)
+	linkPattern := h.attachmentLinkPattern()
+	attachments := make(map[string]bool) // Stored filenames
+	exported := 0
+
+	err := filepath.WalkDir(notesPath, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		ext := filepath.Ext(filePath)
+		if ext != ".md" && ext != ".txt" && ext != ".adoc" {
+			return nil
+		}
+		relPath, err := filepath.Rel(notesPath, filePath)
+		if err != nil {
+			return nil
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		// The note is only parsed to match its uid and find its attachments;
+		// the file itself is exported as stored (encrypted notes stay encrypted)
+		var note *model.Note
+		if data, err := os.ReadFile(filePath); err == nil {
+			if encryption.IsEncrypted(string(data)) && encryptionKey != nil {
+				data, err = encryption.Decrypt(string(data), encryptionKey)
+			}
+			if err == nil && !encryption.IsEncrypted(string(data)) {
+				note, _ = model.ParseNoteFromBytes(data, filePath)
+			}
+		}
+		if !selected[strings.TrimSuffix(relPath, ext)] && (note == nil || note.UID == "" || !selected[note.UID]) {
+			return nil
+		}
+
+		if err := addZipFile(zipWriter, filePath, "notes/"+relPath); err != nil {
+			return err
+		}
+		exported++
+
+		if note != nil {
+			for _, att := range note.Attachments {
+				if match := linkPattern.FindStringSubmatch(att.URL); match != nil {
+					attachments[match[1]] = true
+				}
+			}
+			for _, match := range linkPattern.FindAllStringSubmatch(note.Content, -1) {
+				attachments[match[1]] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return exported, err
+	}
+
+	names := make([]string, 0, len(attachments))
+	for name := range attachments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		src := findStoredFile(storagePath, name)
+		if src == "" {
+			continue
+		}
+		relPath, err := filepath.Rel(storagePath, src)
+		if err != nil {
+			continue
+		}
+		if err := addZipFile(zipWriter, src, filepath.ToSlash(relPath)); err != nil {
+			return exported, err
+		}
+	}
+	return exported, nil
+}
+
+// addNoteZipFile adds a note file like addZipFile, in plaintext when it is encrypted and key is set
+// Returns whether the note was decrypted; notes that can't be decrypted are added as stored
+func addNoteZipFile(zipWriter *zip.Writer, filePath, name string, key []byte) (bool, error) {
+	if key == nil {
+		return false, addZipFile(zipWriter, filePath, name)
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return false, err
+	}
+	if !encryption.IsEncrypted(string(data)) {
+		return false, addZipFile(zipWriter, filePath, name)
+	}
+	plain, err := encryption.Decrypt(string(data), key)
+	if err != nil {
+		return false, addZipFile(zipWriter, filePath, name)
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return false, err
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return false, err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+	writer, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return false, err
+	}
+	_, err = writer.Write(plain)
+	return err == nil, err
+}
+
+// isExportedNoteFile reports whether a path relative to the user's storage is a note file in notes/
+func isExportedNoteFile(relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	ext := path.Ext(relPath)
+	return strings.HasPrefix(relPath, "notes/") && (ext == ".md" || ext == ".txt" || ext == ".adoc")
+}
+
+// findStoredFile returns the path of an uploaded file in the user's storage, or "" if missing
+func findStoredFile(storagePath, filename string) string {
+	for _, dir := range []string{"files", "images"} {
+		filePath := filepath.Join(storagePath, dir, filename)
+		if info, err := os.Stat(filePath); err == nil && !info.IsDir() {
+			return filePath
+		}
+	}
+	return ""
+}
+
+// uniqueExportName returns name, or name with a " (n)" suffix when already used (case-insensitive)
+func uniqueExportName(used map[string]bool, name string) string {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 2; used[strings.ToLower(candidate)]; i++ {
+		candidate = base + " (" + strconv.Itoa(i) + ")" + ext
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+// addZipFile copies a file from disk into the archive
+func addZipFile(zipWriter *zip.Writer, filePath, name string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+
+	writer, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, file)
+	return err
+}
@@ -0,0 +1,413 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/git"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/websocket"
+)
+
+// TrashDirName is the per-user directory (in the user storage root) holding deleted notes
+// Each deletion goes into its own entry: .trash/{trashID}/{original path relative to notes/}
+const TrashDirName = ".trash"
+
+// trashIDLayout is the timestamp prefix of trash entry IDs (sortable, used for auto-purge)
+const trashIDLayout = "20060102-150405"
+
+// newTrashID generates a unique trash entry ID (e.g. "20260115-093000-1a2b3c4d")
+func newTrashID() string {
+	bytes := make([]byte, 4)
+	rand.Read(bytes)
+	return time.Now().Format(trashIDLayout) + "-" + hex.EncodeToString(bytes)
+}
+
+// trashInfoFile records the original location of a trash entry inside the entry directory
+const trashInfoFile = ".trashinfo"
+
+// trashInfo is the content of trashInfoFile
+type trashInfo struct {
+	Path   string `json:"path"`   // Original path relative to notes/ (note file with extension, or folder)
+	Folder bool   `json:"folder"` // A folder deleted with its notes
+}
+
+// moveToTrash moves a note file or folder (relative to notesPath) into a new trash entry
+// Returns the trash entry ID and the absolute path of the entry directory
+func moveToTrash(userPath, notesPath, relPath string) (string, string, error) {
+	trashID := newTrashID()
+	entryDir := filepath.Join(userPath, TrashDirName, trashID)
+	dst := filepath.Join(entryDir, filepath.FromSlash(relPath))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", "", err
+	}
+	if err := os.Rename(filepath.Join(notesPath, filepath.FromSlash(relPath)), dst); err != nil {
+		return "", "", err
+	}
+
+	info := trashInfo{Path: relPath}
+	if fi, err := os.Stat(dst); err == nil {
+		info.Folder = fi.IsDir()
+	}
+	if data, err := json.Marshal(info); err == nil {
+		if err := os.WriteFile(filepath.Join(entryDir, trashInfoFile), data, 0644); err != nil {
+			encoding.Warn("Failed to write trash info for %s: %v", relPath, err)
+		}
+	}
+	return trashID, entryDir, nil
+}
+
+// readTrashInfo returns the original location of a trash entry
+// Entries without trashInfoFile (folders deleted by older versions) are read from their layout:
+// the deepest directory that is the only child of its parent is the deleted folder
+func readTrashInfo(entryDir string) (trashInfo, error) {
+	var info trashInfo
+	if data, err := os.ReadFile(filepath.Join(entryDir, trashInfoFile)); err == nil {
+		if err := json.Unmarshal(data, &info); err == nil && info.Path != "" {
+			return info, nil
+		}
+	}
+
+	relPath := ""
+	dir := entryDir
+	for {
+		children, err := os.ReadDir(dir)
+		if err != nil {
+			return info, err
+		}
+		if len(children) != 1 || (relPath != "" && !children[0].IsDir()) {
+			break
+		}
+		relPath = path.Join(relPath, children[0].Name())
+		dir = filepath.Join(dir, children[0].Name())
+		if !children[0].IsDir() {
+			return trashInfo{Path: relPath}, nil
+		}
+	}
+	if relPath == "" {
+		return info, fmt.Errorf("empty trash entry")
+	}
+	return trashInfo{Path: relPath, Folder: true}, nil
+}
+
+// validTrashID reports whether id names a trash entry (no path separators or parent references)
+func validTrashID(id string) bool {
+	return id != "" && id != "." && id != ".." && !strings.ContainsAny(id, `/\`)
+}
+
+// trashDeletedAt returns the deletion time encoded in a trash entry ID
+func trashDeletedAt(id string) (time.Time, bool) {
+	if len(id) < len(trashIDLayout) {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(trashIDLayout, id[:len(trashIDLayout)], time.Local)
+	return t, err == nil
+}
+
+// TrashItem is a deleted note or folder waiting in the trash
+type TrashItem struct {
+	ID        string     `json:"id"`
+	Path      string     `json:"path"`              // Original location relative to the notes root
+	NoteID    string     `json:"note_id,omitempty"` // Set for a single note
+	Title     string     `json:"title"`
+	Folder    bool       `json:"folder"`
+	NoteCount int        `json:"note_count"`
+	Deleted   time.Time  `json:"deleted"`
+	Expires   *time.Time `json:"expires,omitempty"` // When the entry is purged (unset if the trash is kept)
+}
+
+// trashFolder returns the folder whose share permission covers a trash entry
+func trashFolder(info trashInfo) string {
+	if info.Folder {
+		return info.Path
+	}
+	folder, _ := splitFolderPath(strings.TrimSuffix(info.Path, path.Ext(info.Path)))
+	return folder
+}
+
+// ListTrash returns the deleted notes and folders of the current user, newest first
+func (h *NoteHandler) ListTrash(c *gin.Context) {
+	trashPath := filepath.Join(h.getUserStoragePath(c), TrashDirName)
+	encryptionKey := h.getEncryptionKey(c)
+	loc := middleware.Location(c)
+
+	items := []TrashItem{}
+	dirs, _ := os.ReadDir(trashPath)
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		entryDir := filepath.Join(trashPath, d.Name())
+		info, err := readTrashInfo(entryDir)
+		if err != nil || !middleware.HasSharePermission(c, trashFolder(info), model.PermissionRead) {
+			continue
+		}
+
+		item := TrashItem{ID: d.Name(), Path: info.Path, Folder: info.Folder}
+		src := filepath.Join(entryDir, filepath.FromSlash(info.Path))
+		if info.Folder {
+			item.Title = path.Base(info.Path)
+			filepath.WalkDir(src, func(p string, de fs.DirEntry, err error) error {
+				if err == nil && !de.IsDir() {
+					switch filepath.Ext(p) {
+					case ".md", ".txt", ".adoc":
+						item.NoteCount++
+					}
+				}
+				return nil
+			})
+		} else {
+			item.NoteID = strings.TrimSuffix(info.Path, path.Ext(info.Path))
+			item.Title = path.Base(item.NoteID)
+			item.NoteCount = 1
+			if note, err := h.loadNoteFromFile(src, encryptionKey); err == nil && note.Title != "" {
+				item.Title = note.Title
+			}
+		}
+
+		if deleted, ok := trashDeletedAt(d.Name()); ok {
+			item.Deleted = deleted.In(loc)
+			if days := h.config.Trash.RetentionDays; days > 0 {
+				expires := item.Deleted.AddDate(0, 0, days)
+				item.Expires = &expires
+			}
+		}
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID > items[j].ID
+	})
+	c.JSON(http.StatusOK, items)
+}
+
+// findTrashEntry returns the absolute directory and original location of the trash entry :id,
+// responding 404 when there is none
+func (h *NoteHandler) findTrashEntry(c *gin.Context) (string, trashInfo, bool) {
+	trashID := c.Param("id")
+	userPath, _ := filepath.Abs(h.getUserStoragePath(c))
+	entryDir := filepath.Join(userPath, TrashDirName, trashID)
+	if !validTrashID(trashID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trash entry not found"})
+		return "", trashInfo{}, false
+	}
+	info, err := readTrashInfo(entryDir)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trash entry not found"})
+		return "", trashInfo{}, false
+	}
+	return entryDir, info, true
+}
+
+// RestoreTrash moves a trash entry back to its original location
+func (h *NoteHandler) RestoreTrash(c *gin.Context) {
+	entryDir, info, ok := h.findTrashEntry(c)
+	if !ok {
+		return
+	}
+	if denyShare(c, trashFolder(info), model.PermissionWrite) {
+		return
+	}
+
+	notesPath, _ := filepath.Abs(h.getNotesPath(c))
+	dst := filepath.Join(notesPath, filepath.FromSlash(info.Path))
+	if _, err := os.Stat(dst); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Original location is already taken"})
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore from trash"})
+		return
+	}
+	if err := os.Rename(filepath.Join(entryDir, filepath.FromSlash(info.Path)), dst); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore from trash"})
+		return
+	}
+	os.RemoveAll(entryDir)
+
+	if userRepo, err := h.getUserRepo(c); err == nil {
+		if err := userRepo.AddPathsAndCommit([]string{entryDir, dst}, fmt.Sprintf("Restore from trash: %s", info.Path)); err != nil {
+			encoding.Debug("Git commit error: %v", err)
+		}
+	}
+
+	result := gin.H{"message": "Restored", "path": info.Path, "folder": info.Folder}
+	if !info.Folder {
+		result["note_id"] = strings.TrimSuffix(info.Path, path.Ext(info.Path))
+	}
+	c.JSON(http.StatusOK, result)
+
+	h.broadcastNoteChange(c, websocket.MsgTypeNotesRefresh, "")
+}
+
+// DeleteTrash permanently removes a trash entry
+func (h *NoteHandler) DeleteTrash(c *gin.Context) {
+	entryDir, info, ok := h.findTrashEntry(c)
+	if !ok {
+		return
+	}
+	if denyShare(c, trashFolder(info), model.PermissionWrite) {
+		return
+	}
+
+	if err := os.RemoveAll(entryDir); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete trash entry"})
+		return
+	}
+	if userRepo, err := h.getUserRepo(c); err == nil {
+		if err := userRepo.AddPathsAndCommit([]string{entryDir}, fmt.Sprintf("Purge from trash: %s", info.Path)); err != nil {
+			encoding.Debug("Git commit error: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Trash entry deleted"})
+}
+
+// EmptyTrash permanently removes all trash entries of the current user
+// Not available through a shared folder, as the trash holds the owner's other notes too
+func (h *NoteHandler) EmptyTrash(c *gin.Context) {
+	if middleware.GetShareOwner(c) != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission for shared folder"})
+		return
+	}
+
+	userPath := h.getUserStoragePath(c)
+	removed := purgeTrash(userPath, time.Time{})
+	c.JSON(http.StatusOK, gin.H{"removed": removed})
+}
+
+// purgeTrash removes the trash entries of a user storage deleted before cutoff (all if cutoff is zero)
+// and commits the removal; returns how many entries were removed
+func purgeTrash(userPath string, cutoff time.Time) int {
+	userPath, _ = filepath.Abs(userPath)
+	trashPath := filepath.Join(userPath, TrashDirName)
+	dirs, err := os.ReadDir(trashPath)
+	if err != nil {
+		return 0
+	}
+
+	var removed []string
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		if !cutoff.IsZero() {
+			if deleted, ok := trashDeletedAt(d.Name()); !ok || !deleted.Before(cutoff) {
+				continue
+			}
+		}
+		entryDir := filepath.Join(trashPath, d.Name())
+		if err := os.RemoveAll(entryDir); err != nil {
+			encoding.Warn("Failed to purge trash entry %s: %v", entryDir, err)
+			continue
+		}
+		removed = append(removed, entryDir)
+	}
+
+	if len(removed) > 0 {
+		repo, err := git.NewRepository(userPath)
+		if err == nil {
+			err = repo.AddPathsAndCommit(removed, fmt.Sprintf("Purge trash: %d entries", len(removed)))
+		}
+		if err != nil {
+			encoding.Debug("Git commit error: %v", err)
+		}
+	}
+	return len(removed)
+}
+
+// PurgeExpiredTrash removes trash entries older than retentionDays from every user storage
+// under storagePath (and the storage root itself, used when auth is disabled)
+func PurgeExpiredTrash(storagePath string, retentionDays int) int {
+	if retentionDays <= 0 {
+		return 0
+	}
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+
+	removed := purgeTrash(storagePath, cutoff)
+	dirs, _ := os.ReadDir(storagePath)
+	for _, d := range dirs {
+		if d.IsDir() && !strings.HasPrefix(d.Name(), ".") {
+			removed += purgeTrash(filepath.Join(storagePath, d.Name()), cutoff)
+		}
+	}
+	return removed
+}
+
+// ConfirmTokens stores short-lived tokens confirming destructive operations
+type ConfirmTokens struct {
+	sync.Mutex
+	tokens map[string]confirmToken // token -> target
+}
+
+type confirmToken struct {
+	username  string
+	target    string
+	expiresAt time.Time
+}
+
+// confirmTokenTTL is how long a confirmation token stays valid
+const confirmTokenTTL = 5 * time.Minute
+
+var folderDeleteTokens = &ConfirmTokens{
+	tokens: make(map[string]confirmToken),
+}
+
+// Issue creates a token bound to a user and target (e.g. folder path)
+func (t *ConfirmTokens) Issue(username, target string) string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	token := hex.EncodeToString(bytes)
+
+	t.Lock()
+	defer t.Unlock()
+
+	// Drop expired tokens
+	now := time.Now()
+	for k, v := range t.tokens {
+		if now.After(v.expiresAt) {
+			delete(t.tokens, k)
+		}
+	}
+
+	t.tokens[token] = confirmToken{
+		username:  username,
+		target:    target,
+		expiresAt: now.Add(confirmTokenTTL),
+	}
+	return token
+}
+
+// Consume validates a token for a user and target; a valid token can only be used once
+func (t *ConfirmTokens) Consume(token, username, target string) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	entry, ok := t.tokens[token]
+	if !ok {
+		return false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(t.tokens, token)
+		return false
+	}
+	if entry.username != username || entry.target != target {
+		return false
+	}
+	delete(t.tokens, token)
+	return true
+}
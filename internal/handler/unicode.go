@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/user/gitnotepad/internal/database"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/encryption"
+	"github.com/user/gitnotepad/internal/git"
+	"github.com/user/gitnotepad/internal/model"
+	"golang.org/x/text/unicode/norm"
+)
+
+// normalizeName converts a title or folder path to Unicode NFC
+// macOS produces NFD names (decomposed Hangul/accents) that otherwise don't match the same name typed on Linux
+func normalizeName(s string) string {
+	return norm.NFC.String(s)
+}
+
+// canonicalFolderPath returns the existing folder matching folderPath case-insensitively (segment by segment)
+// Segments without a match are kept as given, so the result is folderPath for new folders
+func canonicalFolderPath(notesPath, folderPath string) string {
+	if folderPath == "" {
+		return ""
+	}
+
+	parts := strings.Split(folderPath, "/")
+	dir := notesPath
+	for i, part := range parts {
+		if info, err := os.Stat(filepath.Join(dir, part)); err == nil && info.IsDir() {
+			dir = filepath.Join(dir, part)
+			continue
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			break
+		}
+		matched := false
+		for _, entry := range entries {
+			if entry.IsDir() && strings.EqualFold(normalizeName(entry.Name()), part) {
+				parts[i] = entry.Name()
+				dir = filepath.Join(dir, entry.Name())
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			break
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// folderCollision returns an existing folder whose path differs from folderPath only by case, or ""
+func folderCollision(notesPath, folderPath string) string {
+	existing := canonicalFolderPath(notesPath, folderPath)
+	if existing == folderPath {
+		return ""
+	}
+	if info, err := os.Stat(filepath.Join(notesPath, filepath.FromSlash(existing))); err != nil || !info.IsDir() {
+		return ""
+	}
+	return existing
+}
+
+// MigrateUnicodeNames renames NFD folders and note files to NFC and normalizes note titles and folder paths.
+// Folders that collide after normalization are merged; conflicting files are left in place.
+func MigrateUnicodeNames(storagePath string) error {
+	entries, err := os.ReadDir(storagePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	migratedCount := 0
+
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		userDir := filepath.Join(storagePath, entry.Name())
+		notesDir := filepath.Join(userDir, "notes")
+		if _, err := os.Stat(notesDir); os.IsNotExist(err) {
+			continue
+		}
+
+		// Collect paths first, then rename deepest entries before their parents
+		var paths []string
+		filepath.WalkDir(notesDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || path == notesDir {
+				return nil
+			}
+			if d.IsDir() && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			paths = append(paths, path)
+			return nil
+		})
+		sort.Slice(paths, func(i, j int) bool {
+			return strings.Count(paths[i], string(filepath.Separator)) > strings.Count(paths[j], string(filepath.Separator))
+		})
+
+		userMigrated := 0
+		for _, path := range paths {
+			name := filepath.Base(path)
+			if norm.NFC.IsNormalString(name) {
+				continue
+			}
+			if err := mergeRename(path, filepath.Join(filepath.Dir(path), normalizeName(name))); err != nil {
+				encoding.Warn("Failed to normalize %s: %v", path, err)
+				continue
+			}
+			userMigrated++
+		}
+
+		// Normalize titles and folder paths in frontmatter
+		filepath.WalkDir(notesDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			ext := filepath.Ext(path)
+			if ext != ".md" && ext != ".txt" && ext != ".adoc" {
+				return nil
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil || norm.NFC.IsNormal(data) || encryption.IsEncrypted(string(data)) {
+				return nil
+			}
+			note, err := model.ParseNoteFromBytes(data, path)
+			if err != nil {
+				return nil
+			}
+			if norm.NFC.IsNormalString(note.Title) && norm.NFC.IsNormalString(note.FolderPath) {
+				return nil
+			}
+
+			note.Title = normalizeName(note.Title)
+			note.FolderPath = normalizeName(note.FolderPath)
+			content, err := note.ToFileContent()
+			if err != nil {
+				return nil
+			}
+			if err := os.WriteFile(path, content, 0644); err == nil {
+				userMigrated++
+			}
+			return nil
+		})
+
+		if userMigrated == 0 {
+			continue
+		}
+		migratedCount += userMigrated
+
+		if userRepo, err := git.NewRepository(userDir); err == nil && userRepo.Init() == nil {
+			if err := userRepo.AddPathsAndCommit([]string{notesDir}, "Normalize Unicode note and folder names"); err != nil {
+				encoding.Debug("Git commit error: %v", err)
+			}
+		}
+	}
+
+	// Short links reference note IDs and folder paths
+	shortLinksPath := filepath.Join(storagePath, ".shortlinks.json")
+	if data, err := os.ReadFile(shortLinksPath); err == nil && !norm.NFC.IsNormal(data) {
+		if err := os.WriteFile(shortLinksPath, norm.NFC.Bytes(data), 0644); err != nil {
+			encoding.Warn("Failed to normalize short links: %v", err)
+		}
+	}
+
+	if migratedCount > 0 {
+		encoding.Info("Unicode name migration: normalized %d folders, files and titles", migratedCount)
+	}
+
+	return nil
+}
+
+// mergeRename renames src to dst; if dst is an existing folder, the contents of src are moved into it
+func mergeRename(src, dst string) error {
+	dstInfo, err := os.Stat(dst)
+	if os.IsNotExist(err) {
+		return os.Rename(src, dst)
+	}
+	if err != nil {
+		return err
+	}
+
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if !srcInfo.IsDir() || !dstInfo.IsDir() {
+		return os.ErrExist
+	}
+
+	children, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		if err := mergeRename(filepath.Join(src, child.Name()), filepath.Join(dst, child.Name())); err != nil {
+			encoding.Warn("Failed to merge %s into %s: %v", child.Name(), dst, err)
+		}
+	}
+	return os.Remove(src) // Fails (and keeps src) if a conflicting file was left behind
+}
+
+// MigrateUnicodeFolderKeys normalizes folder paths stored in the database (icons, order, note sort, metadata, shares)
+func MigrateUnicodeFolderKeys(db *database.DB) error {
+	tables := []struct {
+		table  string
+		column string
+	}{
+		{"folder_icons", "folder_path"},
+		{"folder_meta", "folder_path"},
+		{"folder_shares", "folder_path"},
+		{"folder_order", "parent_path"},
+		{"folder_order", "order_json"},
+		{"note_sort", "folder_path"},
+	}
+
+	for _, t := range tables {
+		rows, err := db.Query("SELECT id, " + t.column + " FROM " + t.table)
+		if err != nil {
+			return err
+		}
+		updates := make(map[int64]string)
+		for rows.Next() {
+			var id int64
+			var value string
+			if err := rows.Scan(&id, &value); err != nil {
+				continue
+			}
+			if !norm.NFC.IsNormalString(value) {
+				updates[id] = normalizeName(value)
+			}
+		}
+		rows.Close()
+
+		for id, value := range updates {
+			// OR IGNORE: keep the NFD row if the normalized key already exists
+			if _, err := db.Exec("UPDATE OR IGNORE "+t.table+" SET "+t.column+" = ? WHERE id = ?", value, id); err != nil {
+				encoding.Warn("Failed to normalize %s.%s: %v", t.table, t.column, err)
+			}
+		}
+		if len(updates) > 0 {
+			encoding.Info("Unicode key migration: normalized %d rows in %s.%s", len(updates), t.table, t.column)
+		}
+	}
+
+	return nil
+}
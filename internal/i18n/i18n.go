@@ -0,0 +1,81 @@
+// Package i18n translates messages produced by the server: API errors, public pages and
+// Telegram replies. Messages are keyed by their English text, so a message missing from a
+// bundle is shown in English.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Supported languages
+const (
+	English = "en"
+	Korean  = "ko"
+	Default = English
+)
+
+// languages lists the supported languages in matcher order
+var languages = []string{English, Korean}
+
+var matcher = language.NewMatcher([]language.Tag{language.English, language.Korean})
+
+// bundles maps a language to its translations (English needs none)
+var bundles = map[string]map[string]string{
+	Korean: korean,
+}
+
+// Supported reports whether lang is a supported language code
+func Supported(lang string) bool {
+	for _, l := range languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the supported language that best fits an Accept-Language header
+// or a bare language code such as Telegram's "ko-KR"; Default when nothing fits
+func Match(accept string) string {
+	if accept == "" {
+		return Default
+	}
+	tags, _, err := language.ParseAcceptLanguage(accept)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+	_, i, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+	return languages[i]
+}
+
+// T translates an English message; with args the translation is formatted like fmt.Sprintf
+// A message of the form "Prefix: detail" without its own entry is translated by its prefix
+func T(lang, msg string, args ...interface{}) string {
+	translated := lookup(lang, msg)
+	if len(args) > 0 {
+		return fmt.Sprintf(translated, args...)
+	}
+	return translated
+}
+
+func lookup(lang, msg string) string {
+	bundle := bundles[lang]
+	if bundle == nil {
+		return msg
+	}
+	if translated, ok := bundle[msg]; ok {
+		return translated
+	}
+	if prefix, detail, ok := strings.Cut(msg, ": "); ok {
+		if translated, ok := bundle[prefix]; ok {
+			return translated + ": " + detail
+		}
+	}
+	return msg
+}
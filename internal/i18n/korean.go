@@ -0,0 +1,334 @@
+package i18n
+
+// korean holds the Korean translations, grouped by where the messages are shown
+var korean = map[string]string{
+	// Authentication and users
+	"Not authenticated":                  "로그인이 필요합니다",
+	"Session expired":                    "세션이 만료되었습니다",
+	"User not authenticated":             "로그인이 필요합니다",
+	"Admin access required":              "관리자 권한이 필요합니다",
+	"Invalid credentials":                "아이디 또는 비밀번호가 올바르지 않습니다",
+	"Invalid password":                   "비밀번호가 올바르지 않습니다",
+	"Invalid username":                   "사용자 이름이 올바르지 않습니다",
+	"Invalid user ID":                    "사용자 ID가 올바르지 않습니다",
+	"User not found":                     "사용자를 찾을 수 없습니다",
+	"Owner not found":                    "소유자를 찾을 수 없습니다",
+	"Username already exists":            "이미 존재하는 사용자 이름입니다",
+	"Cannot delete the last admin":       "마지막 관리자는 삭제할 수 없습니다",
+	"Cannot demote the last admin":       "마지막 관리자의 권한은 해제할 수 없습니다",
+	"Failed to create session":           "세션을 생성하지 못했습니다",
+	"Failed to create user":              "사용자를 생성하지 못했습니다",
+	"Failed to update user":              "사용자를 수정하지 못했습니다",
+	"Failed to delete user":              "사용자를 삭제하지 못했습니다",
+	"Failed to list users":               "사용자 목록을 가져오지 못했습니다",
+	"Failed to hash password":            "비밀번호를 처리하지 못했습니다",
+	"Failed to set password":             "비밀번호를 설정하지 못했습니다",
+	"Failed to update password":          "비밀번호를 변경하지 못했습니다",
+	"Failed to update username":          "사용자 이름을 변경하지 못했습니다",
+	"Failed to rename storage directory": "저장소 디렉토리 이름을 변경하지 못했습니다",
+	"Storage directory already exists":   "저장소 디렉토리가 이미 존재합니다",
+	"User has encrypted notes whose attachment URLs can't be rewritten": "첨부 파일 URL을 바꿀 수 없는 암호화된 노트가 있습니다",
+	"Failed to read notes":              "노트를 읽지 못했습니다",
+	"Failed to rewrite attachment URLs": "첨부 파일 URL을 바꾸지 못했습니다",
+	"Failed to commit rename":           "이름 변경을 커밋하지 못했습니다",
+	"Invalid language":                  "지원하지 않는 언어입니다",
+	"Invalid timezone":                  "지원하지 않는 시간대입니다",
+	"Invalid created time":              "생성 시각 형식이 올바르지 않습니다",
+	"Failed to get preferences":         "설정을 가져오지 못했습니다",
+	"Server is in maintenance mode":     "서버 점검 중입니다 (읽기 전용)",
+	"Failed to export settings":         "설정을 내보내지 못했습니다",
+	"Failed to import settings":         "설정을 가져오지 못했습니다",
+	"Invalid settings file":             "올바른 설정 파일이 아닙니다",
+	"Unsupported settings version":      "지원하지 않는 설정 파일 버전입니다",
+	"Failed to save preferences":        "설정을 저장하지 못했습니다",
+
+	// Notes
+	"Note not found":                        "노트를 찾을 수 없습니다",
+	"Failed to read version":                "버전을 읽지 못했습니다",
+	"Note ID required":                      "노트 ID가 필요합니다",
+	"Note is not encrypted":                 "암호화되지 않은 노트입니다",
+	"Note not in shared folder":             "공유 폴더에 있는 노트가 아닙니다",
+	"This note is password protected":       "비밀번호로 보호된 노트입니다",
+	"No notes to merge":                     "병합할 노트가 없습니다",
+	"Private notes cannot be merged":        "비공개 노트는 병합할 수 없습니다",
+	"Failed to render note":                 "노트를 렌더링하지 못했습니다",
+	"Failed to duplicate note":              "노트를 복제하지 못했습니다",
+	"Failed to update note":                 "노트를 수정하지 못했습니다",
+	"No draft":                              "임시 저장본이 없습니다",
+	"Failed to save draft":                  "임시 저장본을 저장하지 못했습니다",
+	"Note was changed on another device":    "다른 기기에서 노트가 변경되었습니다",
+	"Note was changed since it was loaded":  "노트를 불러온 뒤 변경되었습니다",
+	"Note is being edited by another user":  "다른 사용자가 편집 중인 노트입니다",
+	"Failed to read history":                "버전 기록을 읽지 못했습니다",
+	"Failed to fetch recently viewed notes": "최근 본 노트를 가져오지 못했습니다",
+	"Invalid due date (YYYY-MM-DD)":         "마감일 형식이 올바르지 않습니다 (YYYY-MM-DD)",
+	"Invalid since (YYYY-MM-DD)":            "since 형식이 올바르지 않습니다 (YYYY-MM-DD)",
+	"Invalid by (modified, viewed)":         "by 값이 올바르지 않습니다 (modified, viewed)",
+	"Invalid date range (at most 366 days)": "날짜 범위가 올바르지 않습니다 (최대 366일)",
+	"from and to are required (YYYY-MM-DD)": "from과 to가 필요합니다 (YYYY-MM-DD)",
+	"Invalid journal folder":                "일지 폴더가 올바르지 않습니다",
+	"Journal note not found":                "일지 노트가 아직 없습니다",
+	"Unknown cursor":                        "알 수 없는 커서입니다",
+	"Invalid cursor":                        "커서가 올바르지 않습니다",
+	"Too many operations":                   "작업이 너무 많습니다",
+	"Too many notes":                        "노트가 너무 많습니다",
+	"No matching notes found":               "일치하는 노트가 없습니다",
+	"Note IDs required":                     "노트 ID가 필요합니다",
+	"Invalid request":                       "잘못된 요청입니다",
+	"Invalid export format":                 "지원하지 않는 내보내기 형식입니다",
+	"Failed to create export":               "내보내기 파일을 만들지 못했습니다",
+	"Invalid ZIP file":                      "올바른 ZIP 파일이 아닙니다",
+	"Invalid ENEX file":                     "올바른 ENEX 파일이 아닙니다",
+	"Failed to save note":                   "노트를 저장하지 못했습니다",
+	"No Google Keep notes found in archive": "아카이브에서 Google Keep 노트를 찾지 못했습니다",
+	"No markdown notes found in archive":    "아카이브에서 마크다운 노트를 찾지 못했습니다",
+
+	// Email
+	"SMTP is not configured":           "SMTP가 설정되지 않았습니다",
+	"PDF conversion is not configured": "PDF 변환이 설정되지 않았습니다",
+	"Failed to convert note to PDF":    "노트를 PDF로 변환하지 못했습니다",
+	"Failed to send email":             "이메일을 보내지 못했습니다",
+	"Invalid recipient":                "받는 사람 주소가 올바르지 않습니다",
+	"Invalid reply_to address":         "회신 주소가 올바르지 않습니다",
+	"format must be inline or pdf":     "format은 inline 또는 pdf여야 합니다",
+	"format must be html, pdf or md":   "format은 html, pdf 또는 md여야 합니다",
+
+	// Folders
+	"Folder not found":                           "폴더를 찾을 수 없습니다",
+	"Folder already exists":                      "폴더가 이미 존재합니다",
+	"A folder with the same name already exists": "같은 이름의 폴더가 이미 존재합니다",
+	"Folder is not empty":                        "폴더가 비어 있지 않습니다",
+	"Folder name is required":                    "폴더 이름이 필요합니다",
+	"Folder path required":                       "폴더 경로가 필요합니다",
+	"folder_path is required":                    "folder_path가 필요합니다",
+	"Folder path unchanged":                      "폴더 경로가 변경되지 않았습니다",
+	"Invalid folder name":                        "폴더 이름이 올바르지 않습니다",
+	"Invalid folder path":                        "폴더 경로가 올바르지 않습니다",
+	"Invalid target folder":                      "대상 폴더가 올바르지 않습니다",
+	"Not a folder":                               "폴더가 아닙니다",
+	"Parent folder does not exist":               "상위 폴더가 존재하지 않습니다",
+	"Cannot move a folder into itself":           "폴더를 자기 자신 안으로 이동할 수 없습니다",
+	"Confirmation required":                      "확인이 필요합니다",
+	"Some notes could not be moved; folder kept": "일부 노트를 이동하지 못해 폴더를 유지했습니다",
+	"Invalid color (expected #rrggbb)":           "색상이 올바르지 않습니다 (#rrggbb 형식)",
+	"Invalid default type":                       "기본 노트 형식이 올바르지 않습니다",
+	"Failed to create folder":                    "폴더를 만들지 못했습니다",
+	"Failed to delete folder":                    "폴더를 삭제하지 못했습니다",
+	"Failed to rename folder":                    "폴더 이름을 변경하지 못했습니다",
+	"Failed to read folder":                      "폴더를 읽지 못했습니다",
+	"Failed to move folder to trash":             "폴더를 휴지통으로 옮기지 못했습니다",
+	"Failed to move note to trash":               "노트를 휴지통으로 옮기지 못했습니다",
+	"Failed to delete trash entry":               "휴지통 항목을 삭제하지 못했습니다",
+	"Failed to restore from trash":               "휴지통에서 복원하지 못했습니다",
+	"Original location is already taken":         "원래 위치에 이미 노트나 폴더가 있습니다",
+	"Trash entry not found":                      "휴지통 항목을 찾을 수 없습니다",
+	"Failed to fetch folder icons":               "폴더 아이콘을 가져오지 못했습니다",
+	"Failed to save folder icon":                 "폴더 아이콘을 저장하지 못했습니다",
+	"Failed to delete folder icon":               "폴더 아이콘을 삭제하지 못했습니다",
+	"Failed to fetch folder order":               "폴더 순서를 가져오지 못했습니다",
+	"Failed to save folder order":                "폴더 순서를 저장하지 못했습니다",
+	"Failed to delete folder order":              "폴더 순서를 삭제하지 못했습니다",
+	"Failed to save order":                       "순서를 저장하지 못했습니다",
+	"Failed to serialize order":                  "순서를 저장하지 못했습니다",
+	"Failed to clear existing orders":            "기존 순서를 지우지 못했습니다",
+	"Failed to fetch note sort":                  "노트 정렬을 가져오지 못했습니다",
+	"Failed to save note sort":                   "노트 정렬을 저장하지 못했습니다",
+	"Failed to delete note sort":                 "노트 정렬을 삭제하지 못했습니다",
+	"Invalid sort (field: title, created, modified; direction: asc, desc)": "정렬 기준이 올바르지 않습니다 (field: title, created, modified; direction: asc, desc)",
+	"Failed to fetch folder metadata":                                      "폴더 정보를 가져오지 못했습니다",
+	"Failed to save folder metadata":                                       "폴더 정보를 저장하지 못했습니다",
+	"Failed to delete folder metadata":                                     "폴더 정보를 삭제하지 못했습니다",
+	"Failed to start transaction":                                          "저장을 시작하지 못했습니다",
+	"Failed to commit transaction":                                         "저장을 완료하지 못했습니다",
+
+	// Folder sharing
+	"Insufficient permission for shared folder": "공유 폴더에 대한 권한이 부족합니다",
+	"No access to this user's notes":            "이 사용자의 노트에 접근할 수 없습니다",
+	"Cannot share with the folder owner":        "폴더 소유자와는 공유할 수 없습니다",
+	"Invalid permission (read, write, manage)":  "권한이 올바르지 않습니다 (read, write, manage)",
+	"Invalid share ID":                          "공유 ID가 올바르지 않습니다",
+	"Share not found":                           "공유를 찾을 수 없습니다",
+	"Failed to list shares":                     "공유 목록을 가져오지 못했습니다",
+	"Failed to save share":                      "공유를 저장하지 못했습니다",
+	"Failed to delete share":                    "공유를 삭제하지 못했습니다",
+	"Insufficient permission for shared note":   "공유 노트에 대한 권한이 부족합니다",
+	"Cannot share with the note owner":          "노트 소유자와는 공유할 수 없습니다",
+	"Invalid permission (read, write)":          "권한이 올바르지 않습니다 (read, write)",
+
+	// Account restrictions and folder rules
+	"Insufficient permission for this folder":        "이 폴더에 대한 권한이 부족합니다",
+	"Folder contains restricted subfolders":          "접근이 제한된 하위 폴더가 있습니다",
+	"Read-only account":                              "읽기 전용 계정입니다",
+	"Uploads are disabled for this account":          "이 계정은 업로드가 허용되지 않습니다",
+	"Not available with folder restrictions":         "폴더 접근 제한이 있는 계정에서는 사용할 수 없습니다",
+	"Failed to load folder rules":                    "폴더 규칙을 불러오지 못했습니다",
+	"Failed to list folder rules":                    "폴더 규칙 목록을 가져오지 못했습니다",
+	"Failed to save folder rule":                     "폴더 규칙을 저장하지 못했습니다",
+	"Failed to delete folder rule":                   "폴더 규칙을 삭제하지 못했습니다",
+	"Folder rule not found":                          "폴더 규칙을 찾을 수 없습니다",
+	"Invalid rule ID":                                "규칙 ID가 올바르지 않습니다",
+	"Invalid permission (none, read, write, manage)": "권한이 올바르지 않습니다 (none, read, write, manage)",
+
+	// Files and images
+	"File not found":                                       "파일을 찾을 수 없습니다",
+	"Image not found":                                      "이미지를 찾을 수 없습니다",
+	"No file provided":                                     "파일이 없습니다",
+	"No image provided":                                    "이미지가 없습니다",
+	"Invalid file type":                                    "지원하지 않는 파일 형식입니다",
+	"Invalid filename":                                     "파일 이름이 올바르지 않습니다",
+	"Failed to save file":                                  "파일을 저장하지 못했습니다",
+	"Invalid URL":                                          "잘못된 URL입니다",
+	"URL points to a blocked address":                      "차단된 주소를 가리키는 URL입니다",
+	"File is too large":                                    "파일이 너무 큽니다",
+	"File type is not allowed":                             "허용되지 않는 파일 형식입니다",
+	"Failed to download URL":                               "URL을 다운로드하지 못했습니다",
+	"No readable content found on the page":                "페이지에서 읽을 수 있는 내용을 찾지 못했습니다",
+	"Invalid or expired token":                             "토큰이 잘못되었거나 만료되었습니다",
+	"Token does not allow this request":                    "이 토큰으로는 허용되지 않는 요청입니다",
+	"Token must be renewed (encryption key not available)": "토큰을 새로 발급받아야 합니다 (암호화 키를 사용할 수 없음)",
+	"Invalid scope (read, clip, write)":                    "잘못된 범위입니다 (read, clip, write)",
+	"Sign in again to create a token (encryption key not available)":         "토큰을 만들려면 다시 로그인하세요 (암호화 키를 사용할 수 없음)",
+	"Sign in again to export decrypted notes (encryption key not available)": "복호화된 노트를 내보내려면 다시 로그인하세요 (암호화 키를 사용할 수 없음)",
+	"Failed to create token":          "토큰을 만들지 못했습니다",
+	"Failed to fetch tokens":          "토큰 목록을 가져오지 못했습니다",
+	"Invalid token ID":                "잘못된 토큰 ID입니다",
+	"Failed to delete token":          "토큰을 삭제하지 못했습니다",
+	"Token not found":                 "토큰을 찾을 수 없습니다",
+	"Failed to fetch sessions":        "세션 목록을 가져오지 못했습니다",
+	"Invalid session ID":              "잘못된 세션 ID입니다",
+	"Session not found":               "세션을 찾을 수 없습니다",
+	"Failed to revoke session":        "세션을 종료하지 못했습니다",
+	"Failed to save file name":        "파일 이름을 저장하지 못했습니다",
+	"Failed to move file":             "파일을 이동하지 못했습니다",
+	"Failed to read files":            "파일 목록을 읽지 못했습니다",
+	"Failed to keep previous version": "이전 버전을 보관하지 못했습니다",
+	"Version not found":               "버전을 찾을 수 없습니다",
+	"Invalid name":                    "이름이 올바르지 않습니다",
+	"Failed to save image":            "이미지를 저장하지 못했습니다",
+	"Failed to read file":             "파일을 읽지 못했습니다",
+	"Failed to delete file":           "파일을 삭제하지 못했습니다",
+	"Failed to delete image":          "이미지를 삭제하지 못했습니다",
+
+	// Short links
+	"Link not found":                     "링크를 찾을 수 없습니다",
+	"Short link not found":               "단축 링크를 찾을 수 없습니다",
+	"Folder link not found":              "폴더 링크를 찾을 수 없습니다",
+	"Link has expired":                   "만료된 링크입니다",
+	"This link is not public":            "공개되지 않은 링크입니다",
+	"No short link for this note":        "이 노트의 단축 링크가 없습니다",
+	"No short link for this folder":      "이 폴더의 단축 링크가 없습니다",
+	"Code required":                      "코드가 필요합니다",
+	"Code and note ID required":          "코드와 노트 ID가 필요합니다",
+	"Invalid days":                       "기간이 올바르지 않습니다",
+	"Failed to get views":                "조회수를 가져오지 못했습니다",
+	"Password required":                  "비밀번호가 필요합니다",
+	"This link has no password":          "비밀번호가 설정되지 않은 링크입니다",
+	"Too many attempts, try again later": "시도 횟수가 너무 많습니다. 잠시 후 다시 시도하세요",
+	"Invalid max views":                  "최대 조회수가 올바르지 않습니다",
+	"Invalid custom code":                "링크 코드가 올바르지 않습니다",
+	"Short link code already in use":     "이미 사용 중인 링크 코드입니다",
+
+	// Git, mirrors, backups and audit
+	"Failed to access repository": "저장소에 접근하지 못했습니다",
+	"Failed to open repository":   "저장소를 열지 못했습니다",
+	"No git mirror configured":    "Git 미러가 설정되지 않았습니다",
+	"Failed to get git mirror":    "Git 미러 설정을 가져오지 못했습니다",
+	"Failed to save git mirror":   "Git 미러 설정을 저장하지 못했습니다",
+	"Failed to delete git mirror": "Git 미러를 삭제하지 못했습니다",
+	"Push already in progress":    "이미 푸시 중입니다",
+	"Invalid remote URL (expected https://host/owner/repo.git without credentials)": "원격 URL이 올바르지 않습니다 (인증 정보 없이 https://host/owner/repo.git 형식)",
+	"Backup is not available":       "백업을 사용할 수 없습니다",
+	"Short links are not available": "단축 링크를 사용할 수 없습니다",
+	"Backup already running":        "이미 백업이 진행 중입니다",
+	"Failed to list audit events":   "감사 로그를 가져오지 못했습니다",
+
+	// Telegram bot
+	"⛔ You are not authorized to use this bot.":                                   "⛔ 이 봇을 사용할 권한이 없습니다.",
+	"⚠️ Unsupported message type. Please send text, photos, documents or videos.": "⚠️ 지원하지 않는 메시지 형식입니다. 텍스트, 사진, 문서 또는 동영상을 보내주세요.",
+	"⚠️ The attachment could not be saved: %v":                                    "⚠️ 첨부 파일을 저장하지 못했습니다: %v",
+	"❌ Failed to save note: %v":                                                   "❌ 노트를 저장하지 못했습니다: %v",
+	"✅ Note saved!\n📁 Folder: %s\n📝 Title: %s":                                    "✅ 노트를 저장했습니다!\n📁 폴더: %s\n📝 제목: %s",
+	"👋 Welcome to Git Notepad Bot!\n\nSend me any text message and I'll save it as a note. Start it with #folder to save it in an existing folder.\n\n📋 Commands:\n/start - Show this help\n/info - Show bot info\n/folder [path] - Show or set the folder of this chat (/ = top level, - = default)\n/folders - List folders\n/search <text> - Find notes\n/recent - List recently modified notes\n/get <id> - Show a note\n/journal [text] - Open today's journal note, appending text if given": "👋 Git Notepad 봇입니다!\n\n텍스트 메시지를 보내면 노트로 저장합니다. #폴더로 시작하면 해당 폴더에 저장합니다.\n\n📋 명령어:\n/start - 도움말 보기\n/info - 봇 정보 보기\n/folder [경로] - 이 대화의 저장 폴더 보기/변경 (/ = 최상위, - = 기본값)\n/folders - 폴더 목록\n/search <텍스트> - 노트 검색\n/recent - 최근 수정한 노트\n/get <ID> - 노트 보기\n/journal [텍스트] - 오늘의 일지 열기 (텍스트가 있으면 추가)",
+	"ℹ️ Bot Info\n\n📁 Folder: %s\n👤 Saving as user: %s\n🆔 Your Telegram ID: %d": "ℹ️ 봇 정보\n\n📁 폴더: %s\n👤 저장 사용자: %s\n🆔 텔레그램 ID: %d",
+	"❌ Failed to update journal: %v":  "❌ 일지를 저장하지 못했습니다: %v",
+	"📓 Journal created!\n📝 Title: %s": "📓 일지를 만들었습니다!\n📝 제목: %s",
+	"📓 Journal updated!\n📝 Title: %s": "📓 일지에 추가했습니다!\n📝 제목: %s",
+	"📂 No folders yet.":               "📂 아직 폴더가 없습니다.",
+	"📂 Folders:\n%s":                  "📂 폴더:\n%s",
+	"📁 Notes from this chat are saved in: %s\nUse /folder <path> to change it.": "📁 이 대화의 노트 저장 폴더: %s\n/folder <경로>로 변경할 수 있습니다.",
+	"📁 Notes from this chat are now saved in: %s":                               "📁 이제 이 대화의 노트를 %s 폴더에 저장합니다.",
+	"⚠️ Invalid folder name.":                                                   "⚠️ 폴더 이름이 올바르지 않습니다.",
+	"❌ Failed to set folder: %v":                                                "❌ 폴더를 설정하지 못했습니다: %v",
+	"Usage: /search <text>":                                                     "사용법: /search <텍스트>",
+	"🔍 No notes found for \"%s\".":                                              "🔍 \"%s\"에 해당하는 노트가 없습니다.",
+	"🔍 Notes matching \"%s\":":                                                  "🔍 \"%s\" 검색 결과:",
+	"📭 No notes yet.":                                                           "📭 아직 노트가 없습니다.",
+	"🕒 Recent notes:":                                                           "🕒 최근 노트:",
+	"Usage: /get <id>":                                                          "사용법: /get <ID>",
+	"❓ Note not found.":                                                         "❓ 노트를 찾을 수 없습니다.",
+	"🔒 This note is protected with a password.":                                 "🔒 비밀번호로 보호된 노트입니다.",
+	"📥 %s added a note to your shared folder %s\n📝 %s":                          "📥 %s님이 공유 폴더 %s에 노트를 추가했습니다\n📝 %s",
+	"👀 Your link was opened (%d views)\n📝 %s\n🔗 %s":                             "👀 링크가 열렸습니다 (조회 %d회)\n📝 %s\n🔗 %s",
+	"⏰ Notes due today:":                                                        "⏰ 오늘 마감인 노트:",
+	"❓ Unknown command. Use /start for help.":                                   "❓ 알 수 없는 명령어입니다. /start로 도움말을 확인하세요.",
+	"🚧 The server is in maintenance mode. Please try again later.":              "🚧 서버 점검 중입니다. 잠시 후 다시 시도해 주세요.",
+	"🔒 This account is read-only.":                                              "🔒 읽기 전용 계정입니다.",
+	"⚠️ Uploads are disabled for this account, so the attachments were not saved.": "⚠️ 이 계정은 업로드가 허용되지 않아 첨부 파일을 저장하지 않았습니다.",
+
+	// Public pages
+	"Link Expired": "링크 만료",
+	"This shared link has expired and is no longer available.": "공유 링크가 만료되어 더 이상 볼 수 없습니다.",
+	"Please request a new link from the note owner.":           "노트 소유자에게 새 링크를 요청하세요.",
+	"Go to Home":               "홈으로",
+	"Preview":                  "미리보기",
+	"Shared Note":              "공유 노트",
+	"Shared Folder":            "공유 폴더",
+	"Theme:":                   "테마:",
+	"Light":                    "밝게",
+	"Dark":                     "어둡게",
+	"Dark (High Contrast)":     "어둡게 (고대비)",
+	"Dark (Cyan)":              "어둡게 (시안)",
+	"Loading...":               "불러오는 중...",
+	"Loading note...":          "노트를 불러오는 중...",
+	"Loading notes...":         "노트 목록을 불러오는 중...",
+	"Failed to load note":      "노트를 불러오지 못했습니다",
+	"Failed to load folder":    "폴더를 불러오지 못했습니다",
+	"Failed to load backlinks": "백링크를 불러오지 못했습니다",
+	"Untitled":                 "제목 없음",
+	"Last modified:":           "마지막 수정:",
+	"Error":                    "오류",
+	"Select a note":            "노트 선택",
+	"Select a note from the list to view its content": "목록에서 노트를 선택하면 내용이 표시됩니다",
+	"No notes in this folder":                         "이 폴더에 노트가 없습니다",
+	"{count} note(s)":                                 "노트 {count}개",
+	"Blog not found":                                  "블로그를 찾을 수 없습니다",
+	"Page not found":                                  "페이지를 찾을 수 없습니다",
+	"Post not found":                                  "글을 찾을 수 없습니다",
+	"Connected":                                       "연결됨",
+	"Disconnected":                                    "연결 끊김",
+	"Powered by Git Notepad":                          "Git Notepad로 만들었습니다",
+	"No posts yet.":                                   "아직 글이 없습니다.",
+	"Newer":                                           "최신 글",
+	"Older":                                           "이전 글",
+	"This note is protected by a password.":           "비밀번호로 보호된 노트입니다.",
+	"Enter the password":                              "비밀번호를 입력하세요",
+	"Unlock":                                          "열기",
+
+	// Login page
+	"Login":                        "로그인",
+	"Sign in to access your notes": "로그인하여 노트를 확인하세요",
+	"Username":                     "사용자 이름",
+	"Password":                     "비밀번호",
+	"Enter your username":          "사용자 이름을 입력하세요",
+	"Enter your password":          "비밀번호를 입력하세요",
+	"Sign In":                      "로그인",
+	"Signing in...":                "로그인 중...",
+	"Your notes are automatically saved and version controlled with Git.": "노트는 자동으로 저장되며 Git으로 버전 관리됩니다.",
+	"Please enter both username and password":                             "사용자 이름과 비밀번호를 모두 입력하세요",
+	"Connection error. Please try again.":                                 "연결 오류입니다. 다시 시도하세요.",
+	"or":                                                                  "또는",
+	"Sign in with %s":                                                     "%s(으)로 로그인",
+	"Single sign-on failed. Please try again.":                            "SSO 로그인에 실패했습니다. 다시 시도하세요.",
+	"Your account is not allowed to sign in here.":                        "이 계정으로는 로그인할 수 없습니다.",
+	"A user with this username already exists. Ask an administrator for help.": "같은 사용자 이름이 이미 있습니다. 관리자에게 문의하세요.",
+}
@@ -0,0 +1,326 @@
+// Package index keeps an in-memory metadata index of notes per notes directory.
+// It resolves stable note UIDs (stored in frontmatter) to their current file paths and
+// serves cheap listings (e.g. recently modified notes) without parsing every file.
+// A filesystem watcher marks a directory stale when its files change, so listings of an
+// unchanged directory are served without walking it again.
+package index
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/user/gitnotepad/internal/encryption"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// LoadFunc parses a note file (decrypting if needed); data is the raw file content
+type LoadFunc func(path string, data []byte) (*model.Note, error)
+
+// Entry is the indexed metadata of a note
+type Entry struct {
+	UID         string
+	ID          string // Relative path without extension (e.g. "folder/uuid")
+	FolderPath  string
+	Title       string
+	Type        string
+	Icon        string
+	Tags        []string
+	Aliases     []string
+	Private     bool
+	Archived    bool
+	Encrypted   bool
+	Due         string   // YYYY-MM-DD
+	Links       []string // Wiki-link targets (titles)
+	Attachments []string // Attachment URLs
+	Created     time.Time
+	Modified    time.Time
+
+	fileModTime time.Time
+	fileSize    int64
+	loaded      bool // False when the file could not be parsed (e.g. encrypted without a key)
+}
+
+type dirIndex struct {
+	entries map[string]*Entry // note ID -> entry
+	uids    map[string]string // uid -> note ID
+	clean   bool              // Entries match the files on disk; cleared by Invalidate and watcher events
+	gen     uint64            // Bumped on every invalidation, so a refresh racing a change stays dirty
+	watched bool              // All directories of the notes tree are watched
+}
+
+// Index holds the note indexes of all notes directories
+type Index struct {
+	mu      sync.RWMutex
+	dirs    map[string]*dirIndex // notesPath -> index
+	watcher *fsnotify.Watcher    // nil when unavailable; every listing then walks the directory
+	watched map[string]bool      // Directories added to the watcher
+}
+
+func New() *Index {
+	x := &Index{
+		dirs:    make(map[string]*dirIndex),
+		watched: make(map[string]bool),
+	}
+	x.startWatcher()
+	return x
+}
+
+func (x *Index) dir(notesPath string) *dirIndex {
+	d, ok := x.dirs[notesPath]
+	if !ok {
+		d = &dirIndex{
+			entries: make(map[string]*Entry),
+			uids:    make(map[string]string),
+		}
+		x.dirs[notesPath] = d
+	}
+	return d
+}
+
+// Lookup returns the current note ID for a UID
+func (x *Index) Lookup(notesPath, uid string) (string, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	d, ok := x.dirs[notesPath]
+	if !ok {
+		return "", false
+	}
+	id, ok := d.uids[uid]
+	return id, ok
+}
+
+// Put records the current note ID of a UID
+func (x *Index) Put(notesPath, uid, id string) {
+	if uid == "" {
+		return
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	d := x.dir(notesPath)
+	d.uids[uid] = id
+	d.invalidate()
+}
+
+// Remove drops a UID from the index
+func (x *Index) Remove(notesPath, uid string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if d, ok := x.dirs[notesPath]; ok {
+		if id, ok := d.uids[uid]; ok {
+			delete(d.entries, id)
+		}
+		delete(d.uids, uid)
+		d.invalidate()
+	}
+}
+
+// Invalidate marks the index of a notes directory stale, so the next listing walks it again
+func (x *Index) Invalidate(notesPath string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if d, ok := x.dirs[notesPath]; ok {
+		d.invalidate()
+	}
+}
+
+// InvalidatePath marks the index of every notes directory containing path stale
+func (x *Index) InvalidatePath(path string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.invalidatePath(path)
+}
+
+func (x *Index) invalidatePath(path string) {
+	for notesPath, d := range x.dirs {
+		if within(notesPath, path) {
+			d.invalidate()
+		}
+	}
+}
+
+func (d *dirIndex) invalidate() {
+	d.clean = false
+	d.gen++
+}
+
+// Resolve returns the current note ID for a UID, refreshing the index from disk on a miss
+// or when the indexed file has moved (e.g. by a folder rename)
+func (x *Index) Resolve(notesPath, uid string, load LoadFunc) (string, bool) {
+	if id, ok := x.Lookup(notesPath, uid); ok {
+		if exists(notesPath, id) {
+			return id, true
+		}
+	}
+
+	x.Refresh(notesPath, load)
+	return x.Lookup(notesPath, uid)
+}
+
+// Entries refreshes the index if it is stale and returns a copy of all entries of a notes directory
+func (x *Index) Entries(notesPath string, load LoadFunc) []Entry {
+	x.mu.RLock()
+	clean := x.dirs[notesPath] != nil && x.dirs[notesPath].clean
+	x.mu.RUnlock()
+	if !clean {
+		x.Refresh(notesPath, load)
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	d, ok := x.dirs[notesPath]
+	if !ok {
+		return nil
+	}
+	result := make([]Entry, 0, len(d.entries))
+	for _, entry := range d.entries {
+		if entry.loaded {
+			result = append(result, *entry)
+		}
+	}
+	return result
+}
+
+// Refresh walks notesPath and reparses only new or changed files (by size and mtime)
+// Notes without a UID in frontmatter are indexed by filename; files that cannot be loaded
+// (e.g. encrypted without a key) keep their previous metadata
+func (x *Index) Refresh(notesPath string, load LoadFunc) {
+	type fileInfo struct {
+		path    string
+		modTime time.Time
+		size    int64
+	}
+	files := make(map[string]fileInfo)
+	var dirs []string
+
+	x.mu.Lock()
+	gen := x.dir(notesPath).gen
+	x.mu.Unlock()
+
+	filepath.WalkDir(notesPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != notesPath && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			dirs = append(dirs, path)
+			return nil
+		}
+
+		ext := filepath.Ext(path)
+		if ext != ".md" && ext != ".txt" && ext != ".adoc" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		relPath, err := filepath.Rel(notesPath, path)
+		if err != nil {
+			return nil
+		}
+		id := strings.TrimSuffix(filepath.ToSlash(relPath), ext)
+		files[id] = fileInfo{path: path, modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+
+	// Find changed files under read lock, parse them without holding the lock
+	x.mu.RLock()
+	var previous map[string]*Entry
+	if d, ok := x.dirs[notesPath]; ok {
+		previous = d.entries
+	}
+	changed := make(map[string]fileInfo)
+	for id, f := range files {
+		entry, ok := previous[id]
+		if !ok || !entry.loaded || !entry.fileModTime.Equal(f.modTime) || entry.fileSize != f.size {
+			changed[id] = f
+		}
+	}
+	x.mu.RUnlock()
+
+	parsed := make(map[string]*Entry, len(changed))
+	for id, f := range changed {
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			continue
+		}
+		entry := &Entry{ID: id, fileModTime: f.modTime, fileSize: f.size}
+		note, err := load(f.path, data)
+		if err == nil {
+			entry.loaded = true
+			entry.UID = note.UID
+			entry.FolderPath = note.FolderPath
+			entry.Title = note.Title
+			entry.Type = note.Type
+			entry.Icon = note.Icon
+			entry.Tags = note.Tags
+			entry.Aliases = note.Aliases
+			entry.Private = note.Private
+			entry.Archived = note.Archived
+			entry.Due = note.Due
+			entry.Links = note.WikiLinks()
+			for _, att := range note.Attachments {
+				entry.Attachments = append(entry.Attachments, att.URL)
+			}
+			entry.Created = note.Created
+			entry.Modified = note.Modified
+		}
+		entry.Encrypted = encryption.IsEncrypted(string(data))
+		if entry.UID == "" {
+			entry.UID = filepath.Base(id)
+		}
+		parsed[id] = entry
+	}
+
+	watched := x.watch(dirs)
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	d := x.dir(notesPath)
+	d.watched = watched
+	allLoaded := true
+	entries := make(map[string]*Entry, len(files))
+	uids := make(map[string]string, len(files))
+	for id := range files {
+		entry, ok := parsed[id]
+		if !ok || (!entry.loaded && d.entries[id] != nil && d.entries[id].loaded) {
+			// Unchanged, or unreadable now but indexed earlier (e.g. with an encryption key)
+			entry = d.entries[id]
+		}
+		if entry == nil {
+			continue
+		}
+		entries[id] = entry
+		uids[entry.UID] = id
+		allLoaded = allLoaded && entry.loaded
+	}
+	d.entries = entries
+	d.uids = uids
+	// Notes that could not be loaded are retried on the next listing (e.g. once a key is available)
+	d.clean = d.watched && allLoaded && d.gen == gen
+}
+
+// exists reports whether a note file exists for the ID with any note extension
+func exists(notesPath, id string) bool {
+	for _, ext := range []string{".md", ".txt", ".adoc"} {
+		if _, err := os.Stat(filepath.Join(notesPath, filepath.FromSlash(id)+ext)); err == nil {
+			return true
+		}
+	}
+	return false
+}
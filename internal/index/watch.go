@@ -0,0 +1,99 @@
+package index
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/user/gitnotepad/internal/encoding"
+)
+
+// startWatcher creates the filesystem watcher; without it the index walks the notes on every listing
+func (x *Index) startWatcher() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		encoding.Warn("Note index: file watcher unavailable, notes are rescanned on every listing: %v", err)
+		return
+	}
+	x.watcher = watcher
+	go x.handleEvents(watcher)
+}
+
+// Close stops the filesystem watcher
+func (x *Index) Close() error {
+	if x.watcher == nil {
+		return nil
+	}
+	return x.watcher.Close()
+}
+
+// watch adds directories of a notes tree to the watcher and reports whether all of them are watched
+// New subdirectories are picked up by the refresh that follows their create event
+func (x *Index) watch(dirs []string) bool {
+	if x.watcher == nil {
+		return false
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	ok := true
+	for _, dir := range dirs {
+		if x.watched[dir] {
+			continue
+		}
+		if err := x.watcher.Add(dir); err != nil {
+			encoding.Debug("Note index: failed to watch %s: %v", dir, err)
+			ok = false
+			continue
+		}
+		x.watched[dir] = true
+	}
+	return ok
+}
+
+// handleEvents marks notes directories stale when files below them change
+func (x *Index) handleEvents(watcher *fsnotify.Watcher) {
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			x.mu.Lock()
+			if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+				// The watcher drops removed directories itself
+				delete(x.watched, event.Name)
+			}
+			x.invalidatePath(event.Name)
+			x.mu.Unlock()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			// Events may have been lost (e.g. queue overflow); rescan everything
+			encoding.Warn("Note index: file watcher error: %v", err)
+			x.mu.Lock()
+			for _, d := range x.dirs {
+				d.invalidate()
+			}
+			x.mu.Unlock()
+		}
+	}
+}
+
+// within reports whether path is notesPath or inside it; relative and absolute paths may be mixed
+func within(notesPath, path string) bool {
+	if filepath.IsAbs(notesPath) != filepath.IsAbs(path) {
+		notesPath, _ = filepath.Abs(notesPath)
+		path, _ = filepath.Abs(path)
+	}
+	rel, err := filepath.Rel(notesPath, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
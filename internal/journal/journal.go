@@ -0,0 +1,83 @@
+// Package journal finds and creates daily journal notes.
+// A journal note is a note in the configured journal folder whose title is the formatted date.
+// If the folder contains a template note, new journal notes copy its content, type and tags.
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/user/gitnotepad/internal/config"
+	"github.com/user/gitnotepad/internal/model"
+	"golang.org/x/text/unicode/norm"
+)
+
+// LoadFunc parses a note file (decrypting if needed)
+type LoadFunc func(path string) (*model.Note, error)
+
+// Title returns the journal note title for a day
+func Title(cfg config.JournalConfig, t time.Time) string {
+	return norm.NFC.String(t.Format(cfg.TitleFormat))
+}
+
+// Find returns the note titled title directly inside folderDir, or nil if there is none
+func Find(folderDir, title string, load LoadFunc) (string, *model.Note) {
+	entries, err := os.ReadDir(folderDir)
+	if err != nil {
+		return "", nil
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		if ext != ".md" && ext != ".txt" && ext != ".adoc" {
+			continue
+		}
+
+		path := filepath.Join(folderDir, entry.Name())
+		note, err := load(path)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(norm.NFC.String(note.Title), title) {
+			return path, note
+		}
+	}
+	return "", nil
+}
+
+// New returns a journal note for a day (without ID), filled from the template note in folderDir if present
+// Template placeholders: {{title}}, {{date}} (YYYY-MM-DD), {{weekday}}
+func New(folderDir string, cfg config.JournalConfig, t time.Time, defaultType string, load LoadFunc) *model.Note {
+	title := Title(cfg, t)
+	note := &model.Note{
+		Title:   title,
+		Type:    defaultType,
+		Content: "# " + title + "\n\n",
+		Tags:    []string{"journal"},
+	}
+
+	if cfg.Template != "" {
+		if _, tmpl := Find(folderDir, norm.NFC.String(cfg.Template), load); tmpl != nil {
+			replacer := strings.NewReplacer(
+				"{{title}}", title,
+				"{{date}}", t.Format("2006-01-02"),
+				"{{weekday}}", t.Weekday().String(),
+			)
+			note.Content = replacer.Replace(tmpl.Content)
+			note.Type = tmpl.Type
+			note.Icon = tmpl.Icon
+			if len(tmpl.Tags) > 0 {
+				note.Tags = append([]string{}, tmpl.Tags...)
+			}
+		}
+	}
+
+	note.Created = t
+	note.Modified = t
+	return note
+}
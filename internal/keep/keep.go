@@ -0,0 +1,214 @@
+// Package keep reads Google Keep notes from a Google Takeout archive.
+// Each note is a JSON file in the Keep folder; its images and recordings sit next to it.
+package keep
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxNoteSize guards against oversized JSON entries in the archive
+const maxNoteSize = 16 << 20
+
+// ListItem is a checklist entry
+type ListItem struct {
+	Text      string `json:"text"`
+	IsChecked bool   `json:"isChecked"`
+}
+
+// Attachment is an image or audio file referenced by a note
+type Attachment struct {
+	FilePath string    `json:"filePath"`
+	MimeType string    `json:"mimetype"`
+	File     *zip.File `json:"-"` // nil when the file is missing from the archive
+}
+
+// Annotation is a web link attached to a note
+type Annotation struct {
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	Source string `json:"source"`
+}
+
+// Note is a Google Keep note as exported by Takeout
+type Note struct {
+	Title       string     `json:"title"`
+	TextContent string     `json:"textContent"`
+	ListContent []ListItem `json:"listContent"`
+	Labels      []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Attachments []Attachment `json:"attachments"`
+	Annotations []Annotation `json:"annotations"`
+	IsTrashed   bool         `json:"isTrashed"`
+	IsArchived  bool         `json:"isArchived"`
+	IsPinned    bool         `json:"isPinned"`
+	CreatedUsec int64        `json:"createdTimestampUsec"`
+	EditedUsec  int64        `json:"userEditedTimestampUsec"`
+}
+
+// Read returns the notes of a Takeout archive (or a ZIP of the Keep folder alone)
+// Entries that are not Keep notes are ignored
+func Read(zr *zip.Reader) ([]*Note, error) {
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var notes []*Note
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !strings.EqualFold(path.Ext(f.Name), ".json") {
+			continue
+		}
+		note, err := readNote(f)
+		if err != nil || note == nil {
+			continue
+		}
+
+		dir := path.Dir(f.Name)
+		for i := range note.Attachments {
+			note.Attachments[i].File = findAttachment(files, dir, note.Attachments[i].FilePath)
+		}
+		notes = append(notes, note)
+	}
+	return notes, nil
+}
+
+func readNote(f *zip.File) (*Note, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(io.LimitReader(r, maxNoteSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var note Note
+	if err := json.Unmarshal(data, &note); err != nil {
+		return nil, err
+	}
+	// Other Takeout JSON files (e.g. Labels.json) carry no timestamps
+	if note.EditedUsec == 0 && note.CreatedUsec == 0 {
+		return nil, nil
+	}
+	return &note, nil
+}
+
+// findAttachment looks up an attachment next to its note
+// Takeout sometimes records ".jpeg" for files stored as ".jpg" (and vice versa)
+func findAttachment(files map[string]*zip.File, dir, filePath string) *zip.File {
+	if filePath == "" || strings.Contains(filePath, "..") {
+		return nil
+	}
+	name := path.Join(dir, filePath)
+	if f, ok := files[name]; ok {
+		return f
+	}
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for _, alt := range []string{".jpg", ".jpeg", ".png", ".gif", ".webp", ".3gp", ".m4a"} {
+		if f, ok := files[base+alt]; ok {
+			return f
+		}
+	}
+	return nil
+}
+
+// Created returns the creation time (the edit time for older exports without one)
+func (n *Note) Created() time.Time {
+	if n.CreatedUsec == 0 {
+		return n.Modified()
+	}
+	return time.UnixMicro(n.CreatedUsec)
+}
+
+// Modified returns the last edit time
+func (n *Note) Modified() time.Time {
+	if n.EditedUsec == 0 {
+		return time.UnixMicro(n.CreatedUsec)
+	}
+	return time.UnixMicro(n.EditedUsec)
+}
+
+// Tags returns the note labels
+func (n *Note) Tags() []string {
+	var tags []string
+	for _, label := range n.Labels {
+		if name := strings.TrimSpace(norm.NFC.String(label.Name)); name != "" {
+			tags = append(tags, name)
+		}
+	}
+	return tags
+}
+
+// NoteTitle returns the Keep title, or the first line of the note (Keep titles are optional)
+func (n *Note) NoteTitle() string {
+	title := strings.TrimSpace(n.Title)
+	if title == "" {
+		first := n.TextContent
+		if first == "" && len(n.ListContent) > 0 {
+			first = n.ListContent[0].Text
+		}
+		title = strings.TrimSpace(strings.SplitN(first, "\n", 2)[0])
+		if utf8.RuneCountInString(title) > 50 {
+			title = string([]rune(title)[:47]) + "..."
+		}
+	}
+	if title == "" {
+		title = n.Created().Format("2006-01-02 15:04:05")
+	}
+	return norm.NFC.String(title)
+}
+
+// Markdown renders the note body: text, checklist as task items, then web links
+func (n *Note) Markdown() string {
+	var sb strings.Builder
+	if text := strings.TrimSpace(n.TextContent); text != "" {
+		sb.WriteString(text)
+		sb.WriteString("\n")
+	}
+
+	if len(n.ListContent) > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		for _, item := range n.ListContent {
+			mark := " "
+			if item.IsChecked {
+				mark = "x"
+			}
+			sb.WriteString("- [" + mark + "] " + strings.ReplaceAll(strings.TrimSpace(item.Text), "\n", " ") + "\n")
+		}
+	}
+
+	var links []Annotation
+	for _, a := range n.Annotations {
+		if a.URL != "" {
+			links = append(links, a)
+		}
+	}
+	if len(links) > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		for _, link := range links {
+			label := strings.TrimSpace(link.Title)
+			if label == "" {
+				label = link.URL
+			}
+			sb.WriteString("- [" + label + "](" + link.URL + ")\n")
+		}
+	}
+
+	return norm.NFC.String(sb.String())
+}
@@ -0,0 +1,235 @@
+// Package mail sends email through the SMTP server configured in the smtp section.
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/gitnotepad/internal/config"
+)
+
+// ErrNotConfigured is returned when no SMTP server is configured
+var ErrNotConfigured = errors.New("SMTP is not configured")
+
+const dialTimeout = 30 * time.Second
+
+// Attachment is a file sent with a message
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Message is an email with a plain text and an HTML body
+type Message struct {
+	To          []string
+	ReplyTo     string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Configured reports whether email can be sent
+func Configured(cfg config.SMTPConfig) bool {
+	return cfg.Host != "" && cfg.From != ""
+}
+
+// Send delivers a message to all recipients
+// Port 465 uses implicit TLS; other ports upgrade with STARTTLS when the server offers it
+func Send(cfg config.SMTPConfig, msg *Message) error {
+	if !Configured(cfg) {
+		return ErrNotConfigured
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid smtp.from address: %w", err)
+	}
+	data, err := msg.build(from)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	tlsConfig := &tls.Config{ServerName: cfg.Host}
+
+	var conn net.Conn
+	if cfg.Port == 465 {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: dialTimeout}, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", addr, dialTimeout)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(2 * time.Minute))
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && cfg.Port != 465 {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("SMTP server rejected sender: %w", err)
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("SMTP server rejected recipient %s: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return client.Quit()
+}
+
+// build encodes the message as MIME: multipart/alternative (text, HTML), wrapped in
+// multipart/mixed when there are attachments
+func (m *Message) build(from *mail.Address) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+
+	header("From", from.String())
+	header("To", strings.Join(m.To, ", "))
+	if m.ReplyTo != "" {
+		header("Reply-To", m.ReplyTo)
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", messageID(from.Address))
+	header("MIME-Version", "1.0")
+
+	body := multipart.NewWriter(&buf)
+	if len(m.Attachments) > 0 {
+		header("Content-Type", `multipart/mixed; boundary="`+body.Boundary()+`"`)
+		buf.WriteString("\r\n")
+
+		boundary := multipart.NewWriter(io.Discard).Boundary()
+		part, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type": {`multipart/alternative; boundary="` + boundary + `"`},
+		})
+		if err != nil {
+			return nil, err
+		}
+		alternative := multipart.NewWriter(part)
+		if err := alternative.SetBoundary(boundary); err != nil {
+			return nil, err
+		}
+		if err := m.writeAlternativeParts(alternative); err != nil {
+			return nil, err
+		}
+		if err := alternative.Close(); err != nil {
+			return nil, err
+		}
+
+		for _, att := range m.Attachments {
+			part, err := body.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {att.ContentType},
+				"Content-Transfer-Encoding": {"base64"},
+				"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": att.Name})},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := writeBase64(part, att.Data); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		header("Content-Type", `multipart/alternative; boundary="`+body.Boundary()+`"`)
+		buf.WriteString("\r\n")
+		if err := m.writeAlternativeParts(body); err != nil {
+			return nil, err
+		}
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeAlternativeParts writes the text and HTML bodies
+func (m *Message) writeAlternativeParts(w *multipart.Writer) error {
+	for _, body := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		part, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {body.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+		qp := quotedprintable.NewWriter(part)
+		if _, err := qp.Write([]byte(body.content)); err != nil {
+			return err
+		}
+		if err := qp.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeBase64 writes data base64-encoded in lines of 76 characters
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := w.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := w.Write([]byte(encoded + "\r\n"))
+	return err
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
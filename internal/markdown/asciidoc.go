@@ -0,0 +1,163 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	adocHeadingPattern    = regexp.MustCompile(`^(={1,6})\s+(.*)$`)
+	adocAttributePattern  = regexp.MustCompile(`^:[\w-]+!?:`)
+	adocBlockAttrPattern  = regexp.MustCompile(`^\[[^\]]*\]$`)
+	adocSourcePattern     = regexp.MustCompile(`^\[source(?:,\s*([\w+-]+))?[^\]]*\]$`)
+	adocListPattern       = regexp.MustCompile(`^(\*{1,5}|-|\.{1,5})\s+(.*)$`)
+	adocAdmonitionPattern = regexp.MustCompile(`^(NOTE|TIP|IMPORTANT|WARNING|CAUTION):\s+(.*)$`)
+	adocBlockImagePattern = regexp.MustCompile(`^image::([^\[\s]+)\[([^\]]*)\]$`)
+	adocImagePattern      = regexp.MustCompile(`image:([^\[\s:][^\[\s]*)\[([^\]]*)\]`)
+	adocLinkPattern       = regexp.MustCompile(`(?:link:)?(https?://[^\[\s]+|[^\[\s:]+\.[^\[\s]+)\[([^\]]+)\]`)
+	adocXrefPattern       = regexp.MustCompile(`<<[^,>]+,\s*([^>]+)>>`)
+	adocBoldPattern       = regexp.MustCompile(`(^|[\s(\[])\*([^*\s](?:[^*]*[^*\s])?)\*([\s).,;:!?\]]|$)`)
+)
+
+// AsciiDocToHTML renders the commonly used subset of AsciiDoc (sections, lists, listing and quote blocks,
+// tables, links and images) by translating it to markdown
+func AsciiDocToHTML(src, origin string) string {
+	return ToHTML(AsciiDocToMarkdown(src), origin)
+}
+
+// AsciiDocToMarkdown translates AsciiDoc to markdown; unsupported markup is kept as text
+func AsciiDocToMarkdown(src string) string {
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	var out []string
+	sourceLang := ""
+	quote := "" // Delimiter of the quote, example or sidebar block being translated ("" = none)
+
+	emit := func(line string) {
+		if quote != "" {
+			line = "> " + line
+		}
+		out = append(out, line)
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "////":
+			// Comment block
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "////"; i++ {
+			}
+
+		case strings.HasPrefix(trimmed, "//"), adocAttributePattern.MatchString(trimmed), trimmed == "+":
+
+		case adocSourcePattern.MatchString(trimmed):
+			sourceLang = adocSourcePattern.FindStringSubmatch(trimmed)[1]
+
+		case adocBlockAttrPattern.MatchString(trimmed):
+
+		case trimmed == "----" || trimmed == "....":
+			emit("```" + sourceLang)
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != trimmed; i++ {
+				emit(lines[i])
+			}
+			emit("```")
+			sourceLang = ""
+
+		case trimmed == "____" || trimmed == "====" || trimmed == "****":
+			if quote == trimmed {
+				quote = ""
+			} else if quote == "" {
+				quote = trimmed
+			}
+
+		case trimmed == "|===":
+			for _, row := range adocTable(lines, &i) {
+				emit(row)
+			}
+
+		case trimmed == "'''":
+			emit("---")
+
+		case adocHeadingPattern.MatchString(trimmed):
+			m := adocHeadingPattern.FindStringSubmatch(trimmed)
+			emit(strings.Repeat("#", len(m[1])) + " " + adocInline(m[2]))
+
+		case adocBlockImagePattern.MatchString(trimmed):
+			m := adocBlockImagePattern.FindStringSubmatch(trimmed)
+			emit("![" + adocImageAlt(m[2]) + "](" + m[1] + ")")
+
+		case adocAdmonitionPattern.MatchString(trimmed):
+			m := adocAdmonitionPattern.FindStringSubmatch(trimmed)
+			emit("> **" + m[1] + ":** " + adocInline(m[2]))
+
+		case adocListPattern.MatchString(trimmed):
+			m := adocListPattern.FindStringSubmatch(trimmed)
+			marker, depth := "- ", len(m[1])
+			if m[1][0] == '.' {
+				marker = "1. "
+			} else if m[1] == "-" {
+				depth = 1
+			}
+			emit(strings.Repeat("    ", depth-1) + marker + adocInline(m[2]))
+
+		case strings.HasPrefix(trimmed, ".") && len(trimmed) > 1 && trimmed[1] != '.' && trimmed[1] != ' ':
+			// Block title
+			emit("**" + adocInline(trimmed[1:]) + "**")
+
+		default:
+			emit(adocInline(strings.TrimSuffix(line, " +")))
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// adocTable translates a table starting at lines[*i] ("|===") to a markdown pipe table
+// The column count is taken from the first row; *i is left at the closing "|==="
+func adocTable(lines []string, i *int) []string {
+	var cells []string
+	cols := 0
+	for *i++; *i < len(lines) && strings.TrimSpace(lines[*i]) != "|==="; *i++ {
+		line := strings.TrimSpace(lines[*i])
+		if !strings.HasPrefix(line, "|") {
+			continue
+		}
+		row := strings.Split(line[1:], "|")
+		if cols == 0 {
+			cols = len(row)
+		}
+		for _, cell := range row {
+			cells = append(cells, adocInline(strings.TrimSpace(cell)))
+		}
+	}
+	if cols == 0 {
+		return nil
+	}
+
+	var rows []string
+	for start := 0; start < len(cells); start += cols {
+		end := min(start+cols, len(cells))
+		rows = append(rows, "| "+strings.Join(cells[start:end], " | ")+" |")
+		if start == 0 {
+			rows = append(rows, "|"+strings.Repeat(" --- |", cols))
+		}
+	}
+	return append([]string{""}, append(rows, "")...)
+}
+
+// adocInline translates inline markup: constrained bold, links, cross references and images
+func adocInline(text string) string {
+	text = adocImagePattern.ReplaceAllStringFunc(text, func(image string) string {
+		m := adocImagePattern.FindStringSubmatch(image)
+		return "![" + adocImageAlt(m[2]) + "](" + m[1] + ")"
+	})
+	text = adocLinkPattern.ReplaceAllString(text, "[$2]($1)")
+	text = adocXrefPattern.ReplaceAllString(text, "$1")
+	return adocBoldPattern.ReplaceAllString(text, "$1**$2**$3")
+}
+
+// adocImageAlt returns the alt text of image attributes ("alt,width,height")
+func adocImageAlt(attrs string) string {
+	alt, _, _ := strings.Cut(attrs, ",")
+	return strings.TrimSpace(alt)
+}
@@ -0,0 +1,329 @@
+// Package markdown renders the commonly used subset of GitHub-flavored markdown to HTML on the server,
+// for output that leaves the browser (emails, PDFs). The web UI renders notes with marked instead.
+// All text is escaped; raw HTML in notes is shown as text.
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	headingPattern   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	fencePattern     = regexp.MustCompile("^\\s*(```+|~~~+)\\s*([\\w+-]*)")
+	rulePattern      = regexp.MustCompile(`^\s*([-*_])(\s*([-*_]))*\s*$`)
+	listItemPattern  = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	taskPattern      = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	tableSepPattern  = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	codeSpanPattern  = regexp.MustCompile("`([^`]+)`")
+	imagePattern     = regexp.MustCompile(`!\[([^\]]*)\]\(\s*([^)\s]+)(?:\s+&quot;[^)]*&quot;)?\s*\)`)
+	linkPattern      = regexp.MustCompile(`\[([^\]]+)\]\(\s*([^)\s]+)(?:\s+&quot;[^)]*&quot;)?\s*\)`)
+	autolinkPattern  = regexp.MustCompile(`&lt;(https?://[^\s&]+)&gt;`)
+	boldPattern      = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	italicPattern    = regexp.MustCompile(`\*([^*\s][^*]*?)\*|\b_([^_\s][^_]*?)_\b`)
+	strikePattern    = regexp.MustCompile(`~~(.+?)~~`)
+	placeholderRegex = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// ToHTML renders markdown to an HTML fragment
+// Links and images with site-relative URLs ("/u/...") are prefixed with origin so they work outside the app
+func ToHTML(src, origin string) string {
+	r := &renderer{origin: origin}
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	var sb strings.Builder
+	r.blocks(&sb, lines)
+	return sb.String()
+}
+
+type renderer struct {
+	origin string
+}
+
+// blocks renders a sequence of lines as block elements
+func (r *renderer) blocks(sb *strings.Builder, lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case fencePattern.MatchString(line):
+			m := fencePattern.FindStringSubmatch(line)
+			fence := m[1]
+			var code []string
+			i++
+			for i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+				code = append(code, lines[i])
+				i++
+			}
+			i++ // Closing fence
+			sb.WriteString("<pre><code")
+			if m[2] != "" {
+				sb.WriteString(` class="language-` + html.EscapeString(m[2]) + `"`)
+			}
+			sb.WriteString(">" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+
+		case headingPattern.MatchString(line):
+			m := headingPattern.FindStringSubmatch(line)
+			level := strconv.Itoa(len(m[1]))
+			sb.WriteString("<h" + level + ">" + r.inline(m[2]) + "</h" + level + ">\n")
+			i++
+
+		case rulePattern.MatchString(line) && strings.Count(strings.ReplaceAll(trimmed, " ", ""), trimmed[:1]) >= 3:
+			sb.WriteString("<hr>\n")
+			i++
+
+		case strings.HasPrefix(trimmed, ">"):
+			var quote []string
+			for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">") {
+				quote = append(quote, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">"), " "))
+				i++
+			}
+			sb.WriteString("<blockquote>\n")
+			r.blocks(sb, quote)
+			sb.WriteString("</blockquote>\n")
+
+		case listItemPattern.MatchString(line):
+			i = r.list(sb, lines, i)
+
+		case strings.Contains(line, "|") && i+1 < len(lines) && tableSepPattern.MatchString(lines[i+1]) && strings.Contains(lines[i+1], "-"):
+			i = r.table(sb, lines, i)
+
+		default:
+			var para []string
+			for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !r.startsBlock(lines, i) {
+				para = append(para, r.inline(strings.TrimSpace(lines[i])))
+				i++
+			}
+			if len(para) == 0 {
+				para = append(para, r.inline(trimmed))
+				i++
+			}
+			// Single line breaks are kept, as in the app preview (marked with breaks: true)
+			sb.WriteString("<p>" + strings.Join(para, "<br>\n") + "</p>\n")
+		}
+	}
+}
+
+// startsBlock reports whether a line starts a block other than a paragraph
+func (r *renderer) startsBlock(lines []string, i int) bool {
+	line := lines[i]
+	trimmed := strings.TrimSpace(line)
+	return fencePattern.MatchString(line) || headingPattern.MatchString(line) ||
+		strings.HasPrefix(trimmed, ">") || listItemPattern.MatchString(line) ||
+		(rulePattern.MatchString(line) && len(strings.ReplaceAll(trimmed, " ", "")) >= 3)
+}
+
+// list renders a list starting at lines[start] and returns the index after it
+// Lines indented deeper than the item marker belong to the item (nested lists, continuation text)
+func (r *renderer) list(sb *strings.Builder, lines []string, start int) int {
+	first := listItemPattern.FindStringSubmatch(lines[start])
+	indent := len(first[1])
+	ordered := isOrdered(first[2])
+	sameList := func(m []string) bool {
+		return m != nil && len(m[1]) == indent && isOrdered(m[2]) == ordered
+	}
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+	}
+	sb.WriteString("<" + tag + ">\n")
+
+	i := start
+	for i < len(lines) {
+		m := listItemPattern.FindStringSubmatch(lines[i])
+		if !sameList(m) {
+			break
+		}
+		body := []string{m[3]}
+		i++
+		for i < len(lines) {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				// A blank line ends the item unless indented content follows
+				if i+1 < len(lines) && leadingSpaces(lines[i+1]) > indent {
+					body = append(body, "")
+					i++
+					continue
+				}
+				break
+			}
+			if leadingSpaces(line) <= indent {
+				break
+			}
+			body = append(body, line)
+			i++
+		}
+
+		sb.WriteString("<li>")
+		text := body[0]
+		if tm := taskPattern.FindStringSubmatch(text); tm != nil {
+			checked := ""
+			if tm[1] != " " {
+				checked = " checked"
+			}
+			sb.WriteString(`<input type="checkbox" disabled` + checked + `> `)
+			text = tm[2]
+		}
+		sb.WriteString(r.inline(text))
+		if len(body) > 1 {
+			sb.WriteString("\n")
+			r.blocks(sb, dedent(body[1:]))
+		}
+		sb.WriteString("</li>\n")
+
+		// Skip blank lines between items of the same list
+		j := i
+		for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+			j++
+		}
+		if j < len(lines) && j > i {
+			if sameList(listItemPattern.FindStringSubmatch(lines[j])) {
+				i = j
+			}
+		}
+	}
+
+	sb.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// table renders a pipe table starting at lines[start] and returns the index after it
+func (r *renderer) table(sb *strings.Builder, lines []string, start int) int {
+	var aligns []string
+	for _, cell := range splitRow(lines[start+1]) {
+		cell = strings.TrimSpace(cell)
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			aligns = append(aligns, "center")
+		case strings.HasSuffix(cell, ":"):
+			aligns = append(aligns, "right")
+		default:
+			aligns = append(aligns, "")
+		}
+	}
+
+	row := func(line, cellTag string) {
+		sb.WriteString("<tr>")
+		for n, cell := range splitRow(line) {
+			sb.WriteString("<" + cellTag)
+			if n < len(aligns) && aligns[n] != "" {
+				sb.WriteString(` style="text-align: ` + aligns[n] + `"`)
+			}
+			sb.WriteString(">" + r.inline(strings.TrimSpace(cell)) + "</" + cellTag + ">")
+		}
+		sb.WriteString("</tr>\n")
+	}
+
+	sb.WriteString("<table>\n<thead>\n")
+	row(lines[start], "th")
+	sb.WriteString("</thead>\n<tbody>\n")
+	i := start + 2
+	for i < len(lines) && strings.Contains(lines[i], "|") && strings.TrimSpace(lines[i]) != "" {
+		row(lines[i], "td")
+		i++
+	}
+	sb.WriteString("</tbody>\n</table>\n")
+	return i
+}
+
+// inline renders emphasis, code spans, links and images of one line of text
+func (r *renderer) inline(text string) string {
+	var fragments []string
+	hold := func(fragment string) string {
+		fragments = append(fragments, fragment)
+		return "\x00" + strconv.Itoa(len(fragments)-1) + "\x00"
+	}
+
+	// Code spans keep their content verbatim
+	text = codeSpanPattern.ReplaceAllStringFunc(text, func(span string) string {
+		return hold("<code>" + html.EscapeString(codeSpanPattern.FindStringSubmatch(span)[1]) + "</code>")
+	})
+	text = html.EscapeString(text)
+
+	text = imagePattern.ReplaceAllStringFunc(text, func(image string) string {
+		m := imagePattern.FindStringSubmatch(image)
+		src := r.safeURL(m[2])
+		if src == "" {
+			return m[1]
+		}
+		return hold(`<img src="` + src + `" alt="` + m[1] + `" style="max-width: 100%;">`)
+	})
+	text = linkPattern.ReplaceAllStringFunc(text, func(link string) string {
+		m := linkPattern.FindStringSubmatch(link)
+		href := r.safeURL(m[2])
+		if href == "" {
+			return m[1]
+		}
+		return hold(`<a href="`+href+`">`) + m[1] + hold("</a>")
+	})
+	text = autolinkPattern.ReplaceAllStringFunc(text, func(link string) string {
+		url := autolinkPattern.FindStringSubmatch(link)[1]
+		return hold(`<a href="`+url+`">`+url) + hold("</a>")
+	})
+
+	text = boldPattern.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = italicPattern.ReplaceAllString(text, "<em>$1$2</em>")
+	text = strikePattern.ReplaceAllString(text, "<del>$1</del>")
+
+	return placeholderRegex.ReplaceAllStringFunc(text, func(p string) string {
+		n, _ := strconv.Atoi(placeholderRegex.FindStringSubmatch(p)[1])
+		return fragments[n]
+	})
+}
+
+// safeURL returns an (escaped) URL usable in href/src, or "" for schemes such as javascript:
+func (r *renderer) safeURL(url string) string {
+	lower := strings.ToLower(url)
+	switch {
+	case strings.HasPrefix(lower, "http://"), strings.HasPrefix(lower, "https://"), strings.HasPrefix(lower, "mailto:"),
+		strings.HasPrefix(url, "#"):
+		return url
+	case strings.HasPrefix(url, "/") && !strings.HasPrefix(url, "//"):
+		return html.EscapeString(r.origin) + url
+	case !strings.Contains(url, ":"):
+		return url
+	}
+	return ""
+}
+
+func isOrdered(marker string) bool {
+	return marker[0] >= '0' && marker[0] <= '9'
+}
+
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+	return strings.Split(line, "|")
+}
+
+func leadingSpaces(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// dedent removes the common leading indentation of nested item lines
+func dedent(lines []string) []string {
+	min := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if n := leadingSpaces(line); min < 0 || n < min {
+			min = n
+		}
+	}
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) >= min && min > 0 {
+			out[i] = line[min:]
+		} else {
+			out[i] = strings.TrimLeft(line, " \t")
+		}
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/config"
+	"github.com/user/gitnotepad/internal/encoding"
+)
+
+// AccessLog logs one line per request through the leveled logger
+// Server errors are logged at error level, client errors and slow requests at warn, the rest at info
+// Successful requests are sampled by logging.access_log.sample_rate; errors and slow requests are always logged
+func AccessLog(cfg config.AccessLogConfig) gin.HandlerFunc {
+	slow := time.Duration(cfg.SlowThreshold) * time.Millisecond
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		// The query is left out, since it can carry tokens (e.g. share links)
+		path := c.Request.URL.Path
+
+		c.Next()
+
+		duration := time.Since(start)
+		status := c.Writer.Status()
+
+		log := encoding.Info
+		switch {
+		case status >= http.StatusInternalServerError:
+			log = encoding.Error
+		case status >= http.StatusBadRequest, slow > 0 && duration >= slow:
+			log = encoding.Warn
+		case cfg.SampleRate < 1 && rand.Float64() >= cfg.SampleRate:
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "-"
+		}
+		username := "-"
+		if user := GetCurrentUser(c); user != nil {
+			username = user.Username
+		}
+		log("Request: method=%s, path=%s, route=%s, status=%d, duration=%s, user=%s, ip=%s",
+			c.Request.Method, path, route, status, duration.Round(time.Microsecond), username, c.ClientIP())
+	}
+}
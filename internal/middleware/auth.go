@@ -2,8 +2,11 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/config"
 	"github.com/user/gitnotepad/internal/encryption"
 	"github.com/user/gitnotepad/internal/model"
 	"github.com/user/gitnotepad/internal/repository"
@@ -18,20 +21,29 @@ const (
 type AuthMiddleware struct {
 	userRepo    *repository.UserRepository
 	sessionRepo *repository.SessionRepository
+	tokenRepo   *repository.APITokenRepository
 	basePath    string
+	cookie      config.CookieConfig
 }
 
-func NewAuthMiddleware(userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, basePath string) *AuthMiddleware {
+func NewAuthMiddleware(userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, tokenRepo *repository.APITokenRepository, basePath string, cookie config.CookieConfig) *AuthMiddleware {
 	return &AuthMiddleware{
 		userRepo:    userRepo,
 		sessionRepo: sessionRepo,
+		tokenRepo:   tokenRepo,
 		basePath:    basePath,
+		cookie:      cookie,
 	}
 }
 
 // RequireAuth middleware - redirects to login if not authenticated
 func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if token, ok := bearerToken(c); ok {
+			m.tokenAuth(c, token)
+			return
+		}
+
 		cookie, err := c.Cookie(SessionCookieName)
 		if err != nil {
 			// Check if it's an API request
@@ -48,7 +60,7 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 		session, err := m.sessionRepo.GetByToken(cookie)
 		if err != nil || session == nil || session.IsExpired() {
 			// Clear invalid cookie
-			c.SetCookie(SessionCookieName, "", -1, "/", "", false, true)
+			SetSessionCookie(c, m.cookie, "", -1)
 			if isAPIRequest(c) {
 				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session expired"})
 				c.Abort()
@@ -61,7 +73,7 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 
 		user, err := m.userRepo.GetByID(session.UserID)
 		if err != nil || user == nil {
-			c.SetCookie(SessionCookieName, "", -1, "/", "", false, true)
+			SetSessionCookie(c, m.cookie, "", -1)
 			if isAPIRequest(c) {
 				c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 				c.Abort()
@@ -73,6 +85,7 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 		}
 
 		c.Set(UserContextKey, user)
+		m.touchSession(c, session)
 
 		// Set encryption key in context if available
 		if key, ok := encryption.GetKeyStore().Get(cookie); ok {
@@ -83,9 +96,23 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	}
 }
 
+// touchSession records when and from where a session was last used, at most once a minute
+func (m *AuthMiddleware) touchSession(c *gin.Context, session *model.Session) {
+	ip := c.ClientIP()
+	if session.LastUsedAt != nil && time.Since(*session.LastUsedAt) < time.Minute && session.IP == ip {
+		return
+	}
+	m.sessionRepo.Touch(session.ID, ip)
+}
+
 // OptionalAuth middleware - sets user context if authenticated, but doesn't require it
 func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if token, ok := bearerToken(c); ok {
+			m.tokenAuth(c, token)
+			return
+		}
+
 		cookie, err := c.Cookie(SessionCookieName)
 		if err != nil {
 			c.Next()
@@ -115,6 +142,83 @@ func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 	}
 }
 
+// tokenAuth authenticates a request made with an API token instead of the session cookie
+// Tokens only reach the API routes their scopes allow, never the auth and admin routes
+func (m *AuthMiddleware) tokenAuth(c *gin.Context, secret string) {
+	token, err := m.tokenRepo.GetByToken(secret)
+	if err != nil || token == nil || token.IsExpired() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		c.Abort()
+		return
+	}
+
+	scope := tokenScope(c, m.basePath)
+	if scope == "" || (scope != scopeAny && !token.HasScope(scope)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Token does not allow this request"})
+		c.Abort()
+		return
+	}
+
+	user, err := m.userRepo.GetByID(token.UserID)
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		c.Abort()
+		return
+	}
+
+	// The encryption key of the session the token was made from is only kept in memory
+	if token.Encrypted {
+		key, ok := encryption.GetKeyStore().Get(token.TokenHash)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token must be renewed (encryption key not available)"})
+			c.Abort()
+			return
+		}
+		c.Set(EncryptionKeyContext, key)
+	}
+
+	c.Set(UserContextKey, user)
+	if token.LastUsedAt == nil || time.Since(*token.LastUsedAt) > time.Minute {
+		m.tokenRepo.Touch(token.ID)
+	}
+
+	c.Next()
+}
+
+// scopeAny marks routes every token may use
+const scopeAny = "*"
+
+// tokenScope returns the scope an API token needs for a request ("" when tokens are not accepted)
+func tokenScope(c *gin.Context, basePath string) string {
+	path := strings.TrimPrefix(c.FullPath(), basePath)
+	method := c.Request.Method
+
+	switch {
+	case path == "/api/auth/me":
+		return scopeAny // Lets an extension check its token
+	case !strings.HasPrefix(path, "/api/"), strings.HasPrefix(path, "/api/auth/"), strings.HasPrefix(path, "/api/admin/"),
+		path == "/api/tokens", strings.HasPrefix(path, "/api/tokens/"):
+		return "" // A token can't create or revoke tokens
+	case method == http.MethodGet || method == http.MethodHead:
+		return model.ScopeRead
+	}
+
+	if method == http.MethodPost {
+		switch path {
+		case "/api/clip", "/api/files", "/api/files/fetch", "/api/images":
+			return model.ScopeClip
+		}
+	}
+	return model.ScopeWrite
+}
+
+// bearerToken returns the API token of an "Authorization: Bearer gnp_..." header
+func bearerToken(c *gin.Context) (string, bool) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	token = strings.TrimSpace(token)
+	return token, ok && model.IsAPIToken(token)
+}
+
 // RequireAdmin middleware - requires admin privileges
 func (m *AuthMiddleware) RequireAdmin() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/config"
+)
+
+// IsHTTPS reports whether the browser made the request over HTTPS,
+// directly or through a reverse proxy that sets X-Forwarded-Proto
+func IsHTTPS(c *gin.Context) bool {
+	if c.Request.TLS != nil {
+		return true
+	}
+	proto, _, _ := strings.Cut(c.GetHeader("X-Forwarded-Proto"), ",")
+	return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
+
+// SetSessionCookie writes the session cookie with the attributes from auth.cookie
+// maxAge < 0 deletes the cookie
+func SetSessionCookie(c *gin.Context, cfg config.CookieConfig, value string, maxAge int) {
+	sameSite := http.SameSiteDefaultMode
+	switch strings.ToLower(cfg.SameSite) {
+	case "lax":
+		sameSite = http.SameSiteLaxMode
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+	}
+
+	var secure bool
+	switch strings.ToLower(cfg.Secure) {
+	case "always":
+		secure = true
+	case "never":
+		secure = false
+	default:
+		secure = IsHTTPS(c)
+	}
+	// Browsers drop SameSite=None cookies that aren't Secure
+	if sameSite == http.SameSiteNoneMode {
+		secure = true
+	}
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    url.QueryEscape(value),
+		MaxAge:   maxAge,
+		Path:     "/",
+		Domain:   cfg.Domain,
+		Secure:   secure,
+		HttpOnly: true,
+		SameSite: sameSite,
+	})
+}
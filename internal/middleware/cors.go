@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExtensionCORS allows cross-origin API requests from browser extensions
+// origins are exact origins ("chrome-extension://<id>") or every extension of a browser ("moz-extension://*")
+func ExtensionCORS(origins []string, basePath string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" || !strings.HasPrefix(c.Request.URL.Path, basePath+"/api/") || !originAllowed(origin, origins) {
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Set("Access-Control-Allow-Credentials", "true")
+		header.Add("Vary", "Origin")
+
+		// Preflight
+		if c.Request.Method == http.MethodOptions {
+			header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			header.Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			header.Set("Access-Control-Max-Age", "600")
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// originAllowed matches an origin against exact origins and "scheme://*" entries
+// Wildcards only apply to extension schemes, never to http(s) websites
+func originAllowed(origin string, origins []string) bool {
+	for _, allowed := range origins {
+		if scheme, ok := strings.CutSuffix(allowed, "://*"); ok {
+			if scheme != "http" && scheme != "https" && strings.HasPrefix(origin, scheme+"://") && !strings.Contains(strings.TrimPrefix(origin, scheme+"://"), "/") {
+				return true
+			}
+		} else if origin == allowed {
+			return true
+		}
+	}
+	return false
+}
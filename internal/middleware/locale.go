@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/i18n"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/repository"
+)
+
+const (
+	LanguageContextKey    = "language"
+	localeContextKey      = "locale_middleware"
+	preferencesContextKey = "preferences"
+)
+
+type LocaleMiddleware struct {
+	prefRepo *repository.PreferenceRepository
+}
+
+func NewLocaleMiddleware(prefRepo *repository.PreferenceRepository) *LocaleMiddleware {
+	return &LocaleMiddleware{prefRepo: prefRepo}
+}
+
+// Localize middleware - translates the "error" message of JSON error responses into the
+// language of the request; successful responses and other content types pass through untouched
+func (m *LocaleMiddleware) Localize() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(localeContextKey, m)
+
+		w := &localizedWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		w.flush(c)
+	}
+}
+
+// Language returns the language of the request: the preference of the signed-in user,
+// otherwise the best match for the Accept-Language header
+func Language(c *gin.Context) string {
+	if lang := c.GetString(LanguageContextKey); lang != "" {
+		return lang
+	}
+
+	lang := ""
+	if prefs := preferences(c); prefs != nil {
+		lang = prefs.Language
+	}
+	if !i18n.Supported(lang) {
+		lang = i18n.Match(c.GetHeader("Accept-Language"))
+	}
+
+	c.Set(LanguageContextKey, lang)
+	return lang
+}
+
+// Location returns the time zone of the request: the preference of the signed-in user,
+// otherwise the server's local time zone
+func Location(c *gin.Context) *time.Location {
+	if prefs := preferences(c); prefs != nil && prefs.Timezone != "" {
+		if loc, err := time.LoadLocation(prefs.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+// preferences returns the preferences of the signed-in user, loaded once per request
+// (nil when signed out)
+func preferences(c *gin.Context) *model.Preferences {
+	if prefs, ok := c.Get(preferencesContextKey); ok {
+		return prefs.(*model.Preferences)
+	}
+
+	var prefs *model.Preferences
+	m, ok := c.Get(localeContextKey)
+	user := GetCurrentUser(c)
+	if ok && user != nil {
+		prefs, _ = m.(*LocaleMiddleware).prefRepo.Get(user.ID)
+		c.Set(preferencesContextKey, prefs)
+	}
+	return prefs
+}
+
+// localizedWriter holds back JSON error bodies until the handler chain is done,
+// when the signed-in user (and so the language) is known
+type localizedWriter struct {
+	gin.ResponseWriter
+	buf     *bytes.Buffer
+	decided bool
+}
+
+func (w *localizedWriter) Write(data []byte) (int, error) {
+	if w.buffering() {
+		return w.buf.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *localizedWriter) WriteString(s string) (int, error) {
+	if w.buffering() {
+		return w.buf.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// buffering decides on the first write whether the body is a JSON error
+func (w *localizedWriter) buffering() bool {
+	if !w.decided {
+		w.decided = true
+		if w.Status() >= 400 && strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+			w.buf = &bytes.Buffer{}
+		}
+	}
+	return w.buf != nil
+}
+
+func (w *localizedWriter) flush(c *gin.Context) {
+	if w.buf == nil {
+		return
+	}
+	data := w.buf.Bytes()
+	if lang := Language(c); lang != i18n.English {
+		var body map[string]interface{}
+		if json.Unmarshal(data, &body) == nil {
+			if msg, ok := body["error"].(string); ok {
+				body["error"] = i18n.T(lang, msg)
+				if translated, err := json.Marshal(body); err == nil {
+					data = translated
+				}
+			}
+		}
+	}
+	w.ResponseWriter.Write(data)
+}
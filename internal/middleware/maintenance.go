@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceStatus describes the read-only mode of the server
+type MaintenanceStatus struct {
+	Enabled bool       `json:"enabled"`
+	Message string     `json:"message,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+}
+
+// Maintenance switches the server into read-only mode, e.g. while it is migrated or backed up
+type Maintenance struct {
+	mu     sync.RWMutex
+	status MaintenanceStatus
+	exempt map[string]bool // Route patterns that keep working (login, exports, turning the mode off)
+}
+
+// NewMaintenance creates the maintenance switch in its configured state
+func NewMaintenance(enabled bool, message string) *Maintenance {
+	m := &Maintenance{exempt: make(map[string]bool)}
+	m.Set(enabled, message)
+	return m
+}
+
+// Exempt lets requests to the given route patterns (as in c.FullPath()) through in maintenance mode
+func (m *Maintenance) Exempt(routes ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, route := range routes {
+		m.exempt[route] = true
+	}
+}
+
+// Set turns maintenance mode on or off
+func (m *Maintenance) Set(enabled bool, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !enabled {
+		m.status = MaintenanceStatus{}
+		return
+	}
+	since := m.status.Since
+	if since == nil {
+		now := time.Now()
+		since = &now
+	}
+	m.status = MaintenanceStatus{Enabled: true, Message: message, Since: since}
+}
+
+// Status returns the current state
+func (m *Maintenance) Status() MaintenanceStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+// ReadOnly rejects API requests that change data with 503 while maintenance mode is on
+// Reads (GET, HEAD, OPTIONS) and exempt routes keep working, so routes must not change data on GET
+// (e.g. today's journal note is only created by POST /api/journal/today)
+func (m *Maintenance) ReadOnly(basePath string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if !strings.HasPrefix(c.Request.URL.Path, basePath+"/api/") {
+			c.Next()
+			return
+		}
+
+		m.mu.RLock()
+		status := m.status
+		exempt := m.exempt[c.FullPath()]
+		m.mu.RUnlock()
+
+		if !status.Enabled || exempt {
+			c.Next()
+			return
+		}
+
+		c.Header("Retry-After", "300")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":       "Server is in maintenance mode",
+			"maintenance": status,
+		})
+	}
+}
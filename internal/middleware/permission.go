@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/repository"
+)
+
+const FolderRulesContextKey = "folder_rules"
+
+// PermissionMiddleware enforces the restrictions an admin set on a user:
+// read-only accounts, disabled uploads and per-folder rules
+type PermissionMiddleware struct {
+	ruleRepo *repository.FolderRuleRepository
+	basePath string
+}
+
+func NewPermissionMiddleware(ruleRepo *repository.FolderRuleRepository, basePath string) *PermissionMiddleware {
+	return &PermissionMiddleware{
+		ruleRepo: ruleRepo,
+		basePath: basePath,
+	}
+}
+
+// Enforce rejects requests the current user's account may not make and loads the user's folder rules,
+// which HasSharePermission applies per folder in the handlers
+func (m *PermissionMiddleware) Enforce() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := GetCurrentUser(c)
+		if user == nil {
+			c.Next()
+			return
+		}
+		path := strings.TrimPrefix(c.FullPath(), m.basePath)
+		method := c.Request.Method
+
+		if user.ReadOnly && !isReadMethod(method) && !readOnlyAllowed(path) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Read-only account"})
+			c.Abort()
+			return
+		}
+		if user.UploadDisabled && isUpload(method, path) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Uploads are disabled for this account"})
+			c.Abort()
+			return
+		}
+
+		rules, err := m.ruleRepo.ListByUser(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load folder rules"})
+			c.Abort()
+			return
+		}
+		if len(rules) > 0 {
+			// These work on all notes at once and would get around the rules
+			if GetShareOwner(c) == nil && isWholeStorage(method, path) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Not available with folder restrictions"})
+				c.Abort()
+				return
+			}
+			c.Set(FolderRulesContextKey, rules)
+		}
+		c.Next()
+	}
+}
+
+func isReadMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// readOnlyAllowed reports whether a read-only account may still send a change to path:
+// its own sign-in, tokens and sessions, preferences, and requests that only read notes
+func readOnlyAllowed(path string) bool {
+	switch path {
+	case "/api/tokens", "/api/tokens/:id", "/api/preferences", "/api/notes/export", "/api/notes/:id/send/email":
+		return true
+	}
+	return strings.HasPrefix(path, "/api/auth/")
+}
+
+// isUpload reports whether a request uploads an image or file, including imports and clips
+// that store the attachments they carry
+func isUpload(method, path string) bool {
+	if method != http.MethodPost {
+		return false
+	}
+	switch path {
+	case "/api/images", "/api/files", "/api/files/fetch", "/api/files/:filename/versions",
+		"/api/clip", "/api/notes/import", "/api/notes/import/keep", "/api/notes/import/markdown",
+		"/api/notes/import/evernote":
+		return true
+	}
+	return false
+}
+
+// isWholeStorage reports whether a request exports, imports, mirrors or deletes all of the user's notes
+func isWholeStorage(method, path string) bool {
+	switch path {
+	case "/api/notes/export", "/api/notes/import":
+		return true
+	case "/api/git/mirror":
+		return method == http.MethodPut
+	case "/api/git/mirror/push":
+		return method == http.MethodPost
+	case "/api/notes", "/api/trash":
+		return method == http.MethodDelete
+	}
+	return false
+}
+
+// GetFolderRules returns the folder rules of the current user
+func GetFolderRules(c *gin.Context) []*model.FolderRule {
+	rules, exists := c.Get(FolderRulesContextKey)
+	if !exists {
+		return nil
+	}
+	return rules.([]*model.FolderRule)
+}
+
+// folderPermission returns the permission the current user's folder rules give on a folder of their own notes
+// The deepest rule covering the folder applies; without one the user has full access
+func folderPermission(c *gin.Context, folderPath string) model.SharePermission {
+	permission := model.PermissionManage
+	depth := -1
+	for _, rule := range GetFolderRules(c) {
+		if rule.Covers(folderPath) && len(rule.FolderPath) > depth {
+			permission = rule.Permission
+			depth = len(rule.FolderPath)
+		}
+	}
+	return permission
+}
+
+// RestrictedBelow reports whether a folder rule limits access to a subfolder of folderPath in the user's own notes,
+// so that renaming or deleting folderPath would move or remove notes the user may not manage
+func RestrictedBelow(c *gin.Context, folderPath string) bool {
+	if GetShareOwner(c) != nil {
+		return false
+	}
+	for _, rule := range GetFolderRules(c) {
+		if strings.HasPrefix(rule.FolderPath, folderPath+"/") && !rule.Permission.Allows(model.PermissionManage) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/repository"
+)
+
+const (
+	ShareOwnerContextKey  = "share_owner"
+	ShareGrantsContextKey = "share_grants"
+	NoteGrantsContextKey  = "share_note_grants"
+)
+
+type ShareMiddleware struct {
+	userRepo  *repository.UserRepository
+	shareRepo *repository.ShareRepository
+}
+
+func NewShareMiddleware(userRepo *repository.UserRepository, shareRepo *repository.ShareRepository) *ShareMiddleware {
+	return &ShareMiddleware{
+		userRepo:  userRepo,
+		shareRepo: shareRepo,
+	}
+}
+
+// ResolveOwner switches the storage owner when ?owner= names another user who shared folders or notes
+// with the current user. Handlers then check the granted permission per folder or note.
+func (m *ShareMiddleware) ResolveOwner() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ownerName := c.Query("owner")
+		user := GetCurrentUser(c)
+		if ownerName == "" || user == nil || ownerName == user.Username {
+			c.Next()
+			return
+		}
+
+		owner, err := m.userRepo.GetByUsername(ownerName)
+		if err != nil || owner == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Owner not found"})
+			c.Abort()
+			return
+		}
+
+		grants, err := m.shareRepo.ListByOwnerAndGrantee(owner.ID, user.ID)
+		var noteGrants []*model.NoteShare
+		if err == nil {
+			noteGrants, err = m.shareRepo.ListNoteSharesByOwnerAndGrantee(owner.ID, user.ID)
+		}
+		if err != nil || len(grants)+len(noteGrants) == 0 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "No access to this user's notes"})
+			c.Abort()
+			return
+		}
+
+		c.Set(ShareOwnerContextKey, owner)
+		c.Set(ShareGrantsContextKey, grants)
+		c.Set(NoteGrantsContextKey, noteGrants)
+		c.Next()
+	}
+}
+
+// GetShareOwner returns the owner whose shared storage is being accessed, or nil for own storage
+func GetShareOwner(c *gin.Context) *model.User {
+	owner, exists := c.Get(ShareOwnerContextKey)
+	if !exists {
+		return nil
+	}
+	return owner.(*model.User)
+}
+
+// GetShareGrants returns the shares granted to the current user by the share owner
+func GetShareGrants(c *gin.Context) []*model.FolderShare {
+	grants, exists := c.Get(ShareGrantsContextKey)
+	if !exists {
+		return nil
+	}
+	return grants.([]*model.FolderShare)
+}
+
+// HasSharePermission reports whether the request may access folderPath with the required permission
+// In the user's own storage only the folder rules an admin set can limit access; read-only accounts never write
+func HasSharePermission(c *gin.Context, folderPath string, required model.SharePermission) bool {
+	if user := GetCurrentUser(c); user != nil && user.ReadOnly && required != model.PermissionRead {
+		return false
+	}
+	if GetShareOwner(c) == nil {
+		return folderPermission(c, folderPath).Allows(required)
+	}
+	for _, grant := range GetShareGrants(c) {
+		if grant.Covers(folderPath) && grant.Permission.Allows(required) {
+			return true
+		}
+	}
+	return false
+}
+
+// SharedNoteUIDs returns the UIDs of the share owner's notes shared on their own with the current user
+// that grant at least the required permission
+func SharedNoteUIDs(c *gin.Context, required model.SharePermission) []string {
+	if user := GetCurrentUser(c); user != nil && user.ReadOnly && required != model.PermissionRead {
+		return nil
+	}
+	grants, _ := c.Get(NoteGrantsContextKey)
+	noteGrants, _ := grants.([]*model.NoteShare)
+	var uids []string
+	for _, grant := range noteGrants {
+		if grant.Permission.Allows(required) {
+			uids = append(uids, grant.NoteUID)
+		}
+	}
+	return uids
+}
@@ -0,0 +1,203 @@
+// Package mirror pushes users' notes repositories to their own external git remotes
+// (GitHub, GitLab, ...) after each commit, so every user has an independent versioned backup.
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/git"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/repository"
+)
+
+const (
+	// debounce groups the commits of a burst of saves into one push
+	debounce    = 2 * time.Second
+	pushTimeout = 5 * time.Minute
+)
+
+var (
+	ErrNotConfigured  = errors.New("no git mirror configured")
+	ErrPushInProgress = errors.New("push already in progress")
+)
+
+// retryDelays are the waits between attempts of a failed background push
+var retryDelays = []time.Duration{5 * time.Second, 30 * time.Second, 2 * time.Minute}
+
+// userState tracks the scheduled and running push of one user
+type userState struct {
+	timer   *time.Timer
+	pushing bool
+	dirty   bool // A commit arrived while pushing
+}
+
+// Service schedules mirror pushes for user repositories
+type Service struct {
+	storagePath string
+	mirrors     *repository.MirrorRepository
+
+	mu    sync.Mutex
+	users map[string]*userState
+}
+
+func New(storagePath string, mirrors *repository.MirrorRepository) *Service {
+	absPath, err := filepath.Abs(storagePath)
+	if err != nil {
+		absPath = storagePath
+	}
+	return &Service{
+		storagePath: absPath,
+		mirrors:     mirrors,
+		users:       make(map[string]*userState),
+	}
+}
+
+// Notify schedules a push after a commit in repoPath (used as the git commit hook)
+// Commits outside a user directory (e.g. the root storage repository) are ignored
+func (s *Service) Notify(repoPath string) {
+	relPath, err := filepath.Rel(s.storagePath, repoPath)
+	if err != nil || relPath == "." || strings.HasPrefix(relPath, "..") || strings.ContainsRune(relPath, filepath.Separator) {
+		return
+	}
+	s.schedule(relPath, debounce)
+}
+
+// Trigger schedules a background push for a user (e.g. after the mirror was configured)
+func (s *Service) Trigger(username string) {
+	s.schedule(username, debounce)
+}
+
+func (s *Service) schedule(username string, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.state(username)
+	if state.pushing {
+		state.dirty = true
+		return
+	}
+	if state.timer != nil {
+		state.timer.Reset(delay)
+		return
+	}
+	state.timer = time.AfterFunc(delay, func() { s.pushInBackground(username) })
+}
+
+// state returns the state of a user (s.mu must be held)
+func (s *Service) state(username string) *userState {
+	state, ok := s.users[username]
+	if !ok {
+		state = &userState{}
+		s.users[username] = state
+	}
+	return state
+}
+
+// begin marks a push as running, reporting false when one already is
+func (s *Service) begin(username string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.state(username)
+	if state.pushing {
+		return false
+	}
+	if state.timer != nil {
+		state.timer.Stop()
+		state.timer = nil
+	}
+	state.pushing = true
+	state.dirty = false
+	return true
+}
+
+// end marks a push as finished and reschedules it when commits arrived meanwhile
+func (s *Service) end(username string) {
+	s.mu.Lock()
+	state := s.state(username)
+	state.pushing = false
+	dirty := state.dirty
+	state.dirty = false
+	s.mu.Unlock()
+
+	if dirty {
+		s.schedule(username, debounce)
+	}
+}
+
+func (s *Service) pushInBackground(username string) {
+	if !s.begin(username) {
+		return
+	}
+	defer s.end(username)
+
+	for attempt := 0; ; attempt++ {
+		mirror, err := s.mirrors.GetByUsername(username)
+		if err != nil {
+			encoding.Warn("Failed to load git mirror of %s: %v", username, err)
+			return
+		}
+		if mirror == nil || !mirror.Enabled {
+			return
+		}
+
+		err = s.push(username, mirror)
+		if err == nil {
+			return
+		}
+		if attempt == len(retryDelays) {
+			encoding.Warn("Git mirror push for %s failed, giving up: %v", username, err)
+			return
+		}
+		encoding.Debug("Git mirror push for %s failed, retrying in %s: %v", username, retryDelays[attempt], err)
+		time.Sleep(retryDelays[attempt])
+	}
+}
+
+// PushNow pushes a user's repository immediately, once, and returns the result
+func (s *Service) PushNow(username string) error {
+	mirror, err := s.mirrors.GetByUsername(username)
+	if err != nil {
+		return err
+	}
+	if mirror == nil {
+		return ErrNotConfigured
+	}
+
+	if !s.begin(username) {
+		return ErrPushInProgress
+	}
+	defer s.end(username)
+
+	return s.push(username, mirror)
+}
+
+// push pushes all branches to the mirror and records the result
+func (s *Service) push(username string, mirror *model.GitMirror) error {
+	repo, err := git.NewRepository(filepath.Join(s.storagePath, username))
+	if err != nil {
+		return err
+	}
+	if err := repo.Open(); err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+	defer cancel()
+	pushErr := repo.Push(ctx, mirror.URL, mirror.Username, mirror.Token)
+
+	if err := s.mirrors.UpdateStatus(mirror.UserID, time.Now(), pushErr); err != nil {
+		encoding.Warn("%v", err)
+	}
+	if pushErr == nil {
+		encoding.Debug("Git mirror pushed for %s", username)
+	}
+	return pushErr
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+)
+
+// APITokenPrefix marks API tokens so they are recognizable (e.g. by secret scanners)
+const APITokenPrefix = "gnp_"
+
+// API token scopes
+const (
+	ScopeRead  = "read"  // GET requests: notes, folders, tags, files
+	ScopeClip  = "clip"  // Clip web pages and upload attachments
+	ScopeWrite = "write" // Any other change to notes and folders
+)
+
+// APITokenScopes lists the valid scopes
+var APITokenScopes = []string{ScopeRead, ScopeClip, ScopeWrite}
+
+// APIToken is a scoped token with a limited lifetime, handed out to browser extensions in exchange
+// for a session so they never need the session cookie
+type APIToken struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"-"`
+	TokenHash  string     `json:"-"` // SHA-256 of the token; the token itself is only shown once
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	Encrypted  bool       `json:"-"` // Created with encryption on: requests need the key kept for the token
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NewAPIToken creates a token and returns it with its secret value
+func NewAPIToken(userID int64, name string, scopes []string, duration time.Duration) (*APIToken, string) {
+	secret := APITokenPrefix + generateToken(32)
+	return &APIToken{
+		UserID:    userID,
+		TokenHash: HashAPIToken(secret),
+		Name:      name,
+		Scopes:    scopes,
+		ExpiresAt: time.Now().Add(duration),
+		CreatedAt: time.Now(),
+	}, secret
+}
+
+// HashAPIToken returns the stored form of a token
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIToken reports whether a bearer credential looks like an API token
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// IsExpired checks if the token has expired
+func (t *APIToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// HasScope reports whether the token was granted a scope
+func (t *APIToken) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// Audit actions recorded for operator oversight (note edits come from git history)
+const (
+	AuditShareCreate      = "share.create"
+	AuditShareDelete      = "share.delete"
+	AuditShortLinkCreate  = "shortlink.create"
+	AuditShortLinkCleanup = "shortlink.cleanup"
+	AuditUserCreate       = "user.create"
+	AuditUserDelete       = "user.delete"
+	AuditUserUpdate       = "user.update"
+	AuditUserRename       = "user.rename"
+	AuditUserPassword     = "user.password"
+	AuditBackupRun        = "backup.run"
+	AuditMirrorSet        = "mirror.set"
+	AuditMirrorDelete     = "mirror.delete"
+	AuditNoteEmail        = "note.email"
+	AuditMaintenance      = "maintenance.set"
+	AuditEncryptionRotate = "encryption.rotate"
+)
+
+// AuditEvent is a recorded user action
+type AuditEvent struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username"` // Acting user (kept as text so events survive user deletion)
+	Action    string    `json:"action"`
+	Target    string    `json:"target"`
+	Detail    string    `json:"detail,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
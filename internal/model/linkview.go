@@ -0,0 +1,8 @@
+package model
+
+// LinkViewDay is the number of views of a public link on one day
+type LinkViewDay struct {
+	Day      string `json:"day"`      // YYYY-MM-DD (server local time)
+	Views    int    `json:"views"`    // Page views
+	Visitors int    `json:"visitors"` // Unique visitors (0 unless analytics.unique_visitors is enabled)
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// GitMirror is a user's external remote (GitHub, GitLab, ...) that their notes repository is pushed to
+type GitMirror struct {
+	UserID    int64      `json:"-"`
+	URL       string     `json:"url"`
+	Username  string     `json:"username"`
+	Token     string     `json:"-"` // Never returned by the API
+	Enabled   bool       `json:"enabled"`
+	LastPush  *time.Time `json:"last_push,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
@@ -2,66 +2,173 @@ package model
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/user/gitnotepad/internal/encryption"
 	"golang.org/x/crypto/bcrypt"
 	"gopkg.in/yaml.v3"
 )
 
 // Attachment represents an attached file
 type Attachment struct {
-	Name    string `json:"name" yaml:"name"`
-	URL     string `json:"url" yaml:"url"`
-	Size    int64  `json:"size" yaml:"size"`
-	Type    string `json:"type" yaml:"type"`
-	IsImage bool   `json:"isImage" yaml:"is_image"`
+	Name     string  `json:"name" yaml:"name"`
+	URL      string  `json:"url" yaml:"url"`
+	Size     int64   `json:"size" yaml:"size"`
+	Type     string  `json:"type" yaml:"type"` // MIME type
+	IsImage  bool    `json:"isImage" yaml:"is_image"`
+	Duration float64 `json:"duration,omitempty" yaml:"duration,omitempty"` // Length of audio and video in seconds
 }
 
 type Note struct {
 	ID          string       `json:"id" yaml:"-"`
+	UID         string       `json:"uid,omitempty" yaml:"uid,omitempty"` // Stable ID that survives moves and renames
 	FolderPath  string       `json:"folder_path" yaml:"folder_path,omitempty"`
 	Title       string       `json:"title" yaml:"title"`
 	Content     string       `json:"content" yaml:"-"`
 	Type        string       `json:"type" yaml:"type"`
 	Icon        string       `json:"icon,omitempty" yaml:"icon,omitempty"`
 	Tags        []string     `json:"tags" yaml:"tags,omitempty"`
+	Aliases     []string     `json:"aliases,omitempty" yaml:"aliases,omitempty"`
 	Private     bool         `json:"private" yaml:"private"`
 	Password    string       `json:"-" yaml:"password,omitempty"`
 	Attachments []Attachment `json:"attachments" yaml:"attachments,omitempty"`
+	Due         string       `json:"due,omitempty" yaml:"due,omitempty"`           // Optional due date (YYYY-MM-DD)
+	Source      string       `json:"source,omitempty" yaml:"source,omitempty"`     // URL of the web page a clipped note was made from
+	Archived    bool         `json:"archived,omitempty" yaml:"archived,omitempty"` // Hidden from the note list and calendar, still searchable
 	Created     time.Time    `json:"created" yaml:"created"`
 	Modified    time.Time    `json:"modified" yaml:"modified"`
+
+	// Content of a note with a password is encrypted with a key derived from the password
+	sealedContent string // Encrypted content as stored, while the note is locked
+	contentSalt   string
+	contentKey    []byte
 }
 
 type NoteMetadata struct {
+	UID         string       `yaml:"uid,omitempty"`
 	FolderPath  string       `yaml:"folder_path,omitempty"`
 	Title       string       `yaml:"title"`
 	Type        string       `yaml:"type"`
 	Icon        string       `yaml:"icon,omitempty"`
 	Tags        []string     `yaml:"tags,omitempty"`
+	Aliases     []string     `yaml:"aliases,omitempty"`
 	Private     bool         `yaml:"private"`
 	Password    string       `yaml:"password,omitempty"`
+	ContentSalt string       `yaml:"content_salt,omitempty"` // Salt of the key the content is encrypted with
 	Attachments []Attachment `yaml:"attachments,omitempty"`
+	Due         string       `yaml:"due,omitempty"`
+	Source      string       `yaml:"source,omitempty"`
+	Archived    bool         `yaml:"archived,omitempty"`
 	Created     time.Time    `yaml:"created"`
 	Modified    time.Time    `yaml:"modified"`
 }
 
+// ErrNoteLocked is returned when changing the password of a note whose content hasn't been unlocked
+var ErrNoteLocked = errors.New("note content is locked")
+
+// SetPassword sets the password of the note and the key its content is encrypted with on the next save
+// An empty password removes the protection, so the content is saved as plain text again
 func (n *Note) SetPassword(password string) error {
+	if n.ContentLocked() {
+		return ErrNoteLocked
+	}
 	if password == "" {
 		n.Password = ""
+		n.contentSalt = ""
+		n.contentKey = nil
 		return nil
 	}
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
+	salt, err := encryption.GenerateSalt()
+	if err != nil {
+		return err
+	}
+	key, err := encryption.DeriveKey(password, salt)
+	if err != nil {
+		return err
+	}
 	n.Password = string(hash)
+	n.contentSalt = salt
+	n.contentKey = key
 	return nil
 }
 
+// ContentLocked reports whether the content is encrypted and hasn't been unlocked with the password
+func (n *Note) ContentLocked() bool {
+	return n.sealedContent != "" && n.contentKey == nil
+}
+
+// Unlock checks the password and decrypts the content
+// Notes saved before content encryption get a key, so their content is encrypted on the next save
+func (n *Note) Unlock(password string) bool {
+	if !n.CheckPassword(password) {
+		return false
+	}
+	if n.Password == "" || n.contentKey != nil {
+		return true
+	}
+
+	salt := n.contentSalt
+	if salt == "" {
+		var err error
+		if salt, err = encryption.GenerateSalt(); err != nil {
+			return false
+		}
+	}
+	key, err := encryption.DeriveKey(password, salt)
+	if err != nil {
+		return false
+	}
+	if n.sealedContent != "" {
+		plain, err := encryption.Decrypt(strings.Join(strings.Fields(n.sealedContent), ""), key)
+		if err != nil {
+			return false
+		}
+		n.Content = string(plain)
+		n.sealedContent = ""
+	}
+	n.contentSalt = salt
+	n.contentKey = key
+	return true
+}
+
+// SealText encrypts text with the content key of an unlocked note with a password, and returns other text unchanged
+// It protects copies of the content kept outside the note file, like drafts
+func (n *Note) SealText(text string) (string, error) {
+	if n.Password == "" || n.contentKey == nil {
+		return text, nil
+	}
+	return encryption.Encrypt([]byte(text), n.contentKey)
+}
+
+// OpenText decrypts text sealed with SealText
+func (n *Note) OpenText(text string) (string, error) {
+	if !encryption.IsEncrypted(text) {
+		return text, nil
+	}
+	if n.contentKey == nil {
+		return "", ErrNoteLocked
+	}
+	plain, err := encryption.Decrypt(text, n.contentKey)
+	return string(plain), err
+}
+
+// CopyProtection gives the note the password and content key of another note
+func (n *Note) CopyProtection(from *Note) {
+	n.Password = from.Password
+	n.contentSalt = from.contentSalt
+	n.contentKey = from.contentKey
+}
+
 func (n *Note) CheckPassword(password string) bool {
 	if n.Password == "" {
 		return true
@@ -70,6 +177,25 @@ func (n *Note) CheckPassword(password string) bool {
 	return err == nil
 }
 
+// wikiLinkPattern matches [[Title]], [[Title|label]] and [[Title#heading]]
+var wikiLinkPattern = regexp.MustCompile(`\[\[([^\[\]|#]+)(?:#[^\[\]|]*)?(?:\|[^\[\]]*)?\]\]`)
+
+// WikiLinks returns the titles referenced by wiki-links in the content, without duplicates
+func (n *Note) WikiLinks() []string {
+	var links []string
+	seen := make(map[string]bool)
+	for _, match := range wikiLinkPattern.FindAllStringSubmatch(n.Content, -1) {
+		title := strings.TrimSpace(match[1])
+		key := strings.ToLower(title)
+		if title == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		links = append(links, title)
+	}
+	return links
+}
+
 func (n *Note) GetExtension() string {
 	switch n.Type {
 	case "txt":
@@ -81,22 +207,48 @@ func (n *Note) GetExtension() string {
 	}
 }
 
+// In converts the timestamps to a time zone for display
+func (n *Note) In(loc *time.Location) {
+	n.Created = n.Created.In(loc)
+	n.Modified = n.Modified.In(loc)
+}
+
 func (n *Note) GetFilename() string {
 	return n.ID + n.GetExtension()
 }
 
 func (n *Note) ToFileContent() ([]byte, error) {
 	meta := NoteMetadata{
+		UID:         n.UID,
 		FolderPath:  n.FolderPath,
 		Title:       n.Title,
 		Type:        n.Type,
 		Icon:        n.Icon,
 		Tags:        n.Tags,
+		Aliases:     n.Aliases,
 		Private:     n.Private,
 		Password:    n.Password,
 		Attachments: n.Attachments,
-		Created:     n.Created,
-		Modified:    n.Modified,
+		Due:         n.Due,
+		Source:      n.Source,
+		Archived:    n.Archived,
+		Created:     n.Created.UTC(), // Stored in UTC; shown in each user's time zone
+		Modified:    n.Modified.UTC(),
+	}
+
+	body := n.Content
+	switch {
+	case n.Password != "" && n.contentKey != nil:
+		encrypted, err := encryption.Encrypt([]byte(n.Content), n.contentKey)
+		if err != nil {
+			return nil, err
+		}
+		body = wrapLines(encrypted, sealedLineWidth)
+		meta.ContentSalt = n.contentSalt
+	case n.Password != "" && n.sealedContent != "":
+		// Metadata changes of a locked note keep the content as it was
+		body = n.sealedContent
+		meta.ContentSalt = n.contentSalt
 	}
 
 	metaBytes, err := yaml.Marshal(meta)
@@ -104,10 +256,24 @@ func (n *Note) ToFileContent() ([]byte, error) {
 		return nil, err
 	}
 
-	content := fmt.Sprintf("---\n%s---\n\n%s", string(metaBytes), n.Content)
+	content := fmt.Sprintf("---\n%s---\n\n%s", string(metaBytes), body)
 	return []byte(content), nil
 }
 
+// sealedLineWidth keeps the lines of encrypted content short enough for the line scanner
+const sealedLineWidth = 76
+
+func wrapLines(s string, width int) string {
+	var sb strings.Builder
+	for len(s) > width {
+		sb.WriteString(s[:width])
+		sb.WriteByte('\n')
+		s = s[width:]
+	}
+	sb.WriteString(s)
+	return sb.String()
+}
+
 func ParseNoteFromFile(path string) (*Note, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -181,18 +347,28 @@ func ParseNoteFromBytes(data []byte, path string) (*Note, error) {
 
 	note := &Note{
 		ID:          id,
+		UID:         meta.UID,
 		FolderPath:  folderPath,
 		Title:       title,
 		Content:     content,
 		Type:        meta.Type,
 		Icon:        meta.Icon,
 		Tags:        meta.Tags,
+		Aliases:     meta.Aliases,
 		Private:     meta.Private,
 		Password:    meta.Password,
 		Attachments: meta.Attachments,
+		Due:         meta.Due,
+		Source:      meta.Source,
+		Archived:    meta.Archived,
 		Created:     meta.Created,
 		Modified:    meta.Modified,
 	}
+	if meta.ContentSalt != "" && note.Password != "" && encryption.IsEncrypted(content) {
+		note.sealedContent = content
+		note.contentSalt = meta.ContentSalt
+		note.Content = ""
+	}
 
 	if note.Type == "" {
 		switch ext {
@@ -0,0 +1,31 @@
+package model
+
+// Preference names in the user_preferences table
+const (
+	PrefLanguage = "language"
+	PrefTimezone = "timezone"
+)
+
+// Preferences are per-user settings kept on the server so they follow the user across devices
+type Preferences struct {
+	Language string `json:"language"` // "en", "ko" or empty (language of the browser)
+	Timezone string `json:"timezone"` // IANA time zone such as "Asia/Seoul", or empty (server time zone)
+}
+
+// Values returns the stored preferences by name
+func (p *Preferences) Values() map[string]string {
+	return map[string]string{
+		PrefLanguage: p.Language,
+		PrefTimezone: p.Timezone,
+	}
+}
+
+// Set assigns a stored preference, ignoring unknown names
+func (p *Preferences) Set(name, value string) {
+	switch name {
+	case PrefLanguage:
+		p.Language = value
+	case PrefTimezone:
+		p.Timezone = value
+	}
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// SharePermission is the access level granted on a shared folder
+type SharePermission string
+
+const (
+	PermissionNone   SharePermission = "none"   // No access (folder rules only)
+	PermissionRead   SharePermission = "read"   // View notes
+	PermissionWrite  SharePermission = "write"  // Create, edit, delete notes and upload attachments
+	PermissionManage SharePermission = "manage" // Write plus short links, folder rename/delete and re-sharing
+)
+
+// level returns the numeric rank of a permission (0 = invalid)
+func (p SharePermission) level() int {
+	switch p {
+	case PermissionRead:
+		return 1
+	case PermissionWrite:
+		return 2
+	case PermissionManage:
+		return 3
+	}
+	return 0
+}
+
+// Valid reports whether p is a known permission
+func (p SharePermission) Valid() bool {
+	return p.level() > 0
+}
+
+// Allows reports whether p grants at least the required permission
+func (p SharePermission) Allows(required SharePermission) bool {
+	return p.level() > 0 && p.level() >= required.level()
+}
+
+// FolderShare grants another user access to a folder subtree of the owner's notes
+type FolderShare struct {
+	ID              int64           `json:"id"`
+	OwnerID         int64           `json:"owner_id"`
+	OwnerUsername   string          `json:"owner"`
+	FolderPath      string          `json:"folder_path"`
+	GranteeID       int64           `json:"grantee_id"`
+	GranteeUsername string          `json:"username"`
+	Permission      SharePermission `json:"permission"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// Covers reports whether folderPath is the shared folder or inside it
+func (s *FolderShare) Covers(folderPath string) bool {
+	return folderPath == s.FolderPath || strings.HasPrefix(folderPath, s.FolderPath+"/")
+}
+
+// FolderRule limits a user's access to a folder subtree of their own notes (set by an admin)
+// The rule of the deepest folder containing a note applies
+type FolderRule struct {
+	ID         int64           `json:"id"`
+	UserID     int64           `json:"user_id"`
+	FolderPath string          `json:"folder_path"`
+	Permission SharePermission `json:"permission"` // none, read, write or manage
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// Covers reports whether folderPath is the rule's folder or inside it
+func (r *FolderRule) Covers(folderPath string) bool {
+	return folderPath == r.FolderPath || strings.HasPrefix(folderPath, r.FolderPath+"/")
+}
+
+// NoteShare grants another user access to a single note of the owner, found by its UID wherever it is moved
+type NoteShare struct {
+	ID              int64           `json:"id"`
+	OwnerID         int64           `json:"owner_id"`
+	OwnerUsername   string          `json:"owner"`
+	NoteUID         string          `json:"note_uid"`
+	GranteeID       int64           `json:"grantee_id"`
+	GranteeUsername string          `json:"username"`
+	Permission      SharePermission `json:"permission"` // read or write
+	CreatedAt       time.Time       `json:"created_at"`
+}
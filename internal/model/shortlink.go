@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// ShortLink is a short link (/s/:code) to a note or folder, with optional expiry
+type ShortLink struct {
+	NoteID     string     `json:"note_id,omitempty"`
+	NoteUID    string     `json:"note_uid,omitempty"`    // Stable note UID, used to follow moved notes
+	FolderPath string     `json:"folder_path,omitempty"` // For folder sharing (empty = note link)
+	Username   string     `json:"username"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	IsPublic   bool       `json:"is_public"`
+	Blog       bool       `json:"blog,omitempty"` // Public folder link also published as a blog at /blog/:code
+	// bcrypt hash of the passphrase a public note link asks for (empty = no passphrase)
+	PasswordHash string     `json:"password_hash,omitempty"`
+	ViewCount    int        `json:"view_count,omitempty"` // Accesses since the link was created
+	MaxViews     int        `json:"max_views,omitempty"`  // Accesses after which the link expires (0 = unlimited)
+	LastAccessAt *time.Time `json:"last_access_at,omitempty"`
+	LastAccessIP string     `json:"last_access_ip,omitempty"` // Only kept with analytics.record_ip
+}
@@ -8,11 +8,23 @@ import (
 )
 
 type User struct {
-	ID           int64     `json:"id"`
-	Username     string    `json:"username"`
-	PasswordHash string    `json:"-"` // Never expose in JSON
-	IsAdmin      bool      `json:"is_admin"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID             int64     `json:"id"`
+	Username       string    `json:"username"`
+	PasswordHash   string    `json:"-"` // Never expose in JSON
+	IsAdmin        bool      `json:"is_admin"`
+	ReadOnly       bool      `json:"read_only"`       // Can view notes but not change them
+	UploadDisabled bool      `json:"upload_disabled"` // Cannot upload images or files
+	EncryptionSalt string    `json:"-"`               // Salt of the user's encryption key ("" = encryption.salt)
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// KeySalt returns the salt the user's encryption key is derived with
+// Users get their own salt when their key is rotated; until then they share the configured one
+func (u *User) KeySalt(defaultSalt string) string {
+	if u.EncryptionSalt != "" {
+		return u.EncryptionSalt
+	}
+	return defaultSalt
 }
 
 // SetPassword hashes and sets the user's password
@@ -0,0 +1,52 @@
+// Package notify passes events about a user's notes to subscribers outside the web app, such as the Telegram bot.
+package notify
+
+import "sync"
+
+// Event types
+const (
+	SharedNoteCreated = "shared_note_created" // Another user added a note to a shared folder
+	LinkViewed        = "link_viewed"         // A short link was opened
+)
+
+// Event is something that happened to a user's notes
+type Event struct {
+	Type     string
+	Username string // Owner of the note, folder or link
+	Actor    string // User who caused the event ("" = not signed in)
+	NoteID   string
+	Folder   string
+	Title    string
+	Code     string // Short link code
+	Views    int    // Views of the short link so far
+}
+
+// Dispatcher hands events to the subscribed functions
+type Dispatcher struct {
+	mu          sync.RWMutex
+	subscribers []func(Event)
+}
+
+func New() *Dispatcher {
+	return &Dispatcher{}
+}
+
+// Subscribe adds a function called with every published event
+func (d *Dispatcher) Subscribe(fn func(Event)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subscribers = append(d.subscribers, fn)
+}
+
+// Publish hands an event to the subscribers without waiting for them
+// A nil dispatcher drops the event, so callers need no check
+func (d *Dispatcher) Publish(event Event) {
+	if d == nil {
+		return
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, fn := range d.subscribers {
+		go fn(event)
+	}
+}
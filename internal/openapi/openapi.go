@@ -0,0 +1,321 @@
+// Package openapi builds the OpenAPI 3 description of the JSON API from the registered routes
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Operation documents one route; routes without an Operation are still listed with their parameters
+type Operation struct {
+	Summary  string
+	Query    []string    // Query parameters the route reads
+	Request  interface{} // Value of the JSON request body type (nil = no JSON body)
+	Upload   string      // Form field of a multipart file upload instead of a JSON body
+	Response interface{} // Value of the JSON response body type (nil = generic object)
+	Status   int         // Status of the success response (default: 200)
+	Public   bool        // Usable without signing in
+}
+
+// Document is an OpenAPI 3.0 document
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Servers    []Server                         `json:"servers,omitempty"`
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components components                       `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type operation struct {
+	Summary     string                `json:"summary,omitempty"`
+	OperationID string                `json:"operationId"`
+	Tags        []string              `json:"tags"`
+	Parameters  []parameter           `json:"parameters,omitempty"`
+	RequestBody *requestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]response   `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+}
+
+type parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type response struct {
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]securityScheme `json:"securitySchemes,omitempty"`
+}
+
+type securityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+	In     string `json:"in,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// Schema is a JSON schema of a request or response body
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Build describes the routes under basePath+"/api/", relative to serverURL (where the API is also served, e.g. /api/v1)
+// docs are keyed by method and route path ("GET /api/notes/:id")
+// With auth, routes that aren't Public require the session cookie or an API token
+func Build(info Info, basePath, serverURL string, routes gin.RoutesInfo, docs map[string]Operation, auth bool, sessionCookie string) *Document {
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Servers: []Server{{URL: serverURL}},
+		Paths:   make(map[string]map[string]*operation),
+		Components: components{
+			Schemas: map[string]*Schema{
+				"Error": {Type: "object", Properties: map[string]*Schema{"error": {Type: "string"}}},
+			},
+		},
+	}
+	if auth {
+		doc.Components.SecuritySchemes = map[string]securityScheme{
+			"session": {Type: "apiKey", In: "cookie", Name: sessionCookie},
+			"token":   {Type: "http", Scheme: "bearer"},
+		}
+	}
+	schemas := &schemaBuilder{schemas: doc.Components.Schemas, types: make(map[string]reflect.Type)}
+
+	for _, route := range routes {
+		path := strings.TrimPrefix(route.Path, basePath)
+		if !strings.HasPrefix(path, "/api/") {
+			continue
+		}
+		key := route.Method + " " + path
+		op := docs[key]
+
+		o := &operation{
+			Summary:     op.Summary,
+			OperationID: operationID(route.Method, path),
+			Tags:        []string{tag(path)},
+			Responses:   map[string]response{},
+			Security:    []map[string][]string{},
+		}
+		if auth && !op.Public {
+			o.Security = []map[string][]string{{"session": {}}, {"token": {}}}
+		}
+
+		segments := strings.Split(path, "/")
+		for i, segment := range segments {
+			if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+				name := segment[1:]
+				segments[i] = "{" + name + "}"
+				o.Parameters = append(o.Parameters, parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+			}
+		}
+		for _, name := range op.Query {
+			o.Parameters = append(o.Parameters, parameter{Name: name, In: "query", Schema: &Schema{Type: "string"}})
+		}
+
+		if op.Request != nil {
+			o.RequestBody = &requestBody{Required: true, Content: map[string]mediaType{
+				"application/json": {Schema: schemas.schema(reflect.TypeOf(op.Request))},
+			}}
+		} else if op.Upload != "" {
+			o.RequestBody = &requestBody{Required: true, Content: map[string]mediaType{
+				"multipart/form-data": {Schema: &Schema{
+					Type:       "object",
+					Properties: map[string]*Schema{op.Upload: {Type: "string", Format: "binary"}},
+					Required:   []string{op.Upload},
+				}},
+			}}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		result := &Schema{Type: "object"}
+		if op.Response != nil {
+			result = schemas.schema(reflect.TypeOf(op.Response))
+		}
+		o.Responses[strconv.Itoa(status)] = response{
+			Description: http.StatusText(status),
+			Content:     map[string]mediaType{"application/json": {Schema: result}},
+		}
+		o.Responses["default"] = response{
+			Description: "Error",
+			Content:     map[string]mediaType{"application/json": {Schema: &Schema{Ref: "#/components/schemas/Error"}}},
+		}
+
+		openPath := strings.TrimPrefix(strings.Join(segments, "/"), "/api")
+		if doc.Paths[openPath] == nil {
+			doc.Paths[openPath] = make(map[string]*operation)
+		}
+		doc.Paths[openPath][strings.ToLower(route.Method)] = o
+	}
+	return doc
+}
+
+// tag groups operations by the first path segment after /api ("/api/admin/users" -> "admin")
+func tag(path string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/api/"), "/")
+	return segment
+}
+
+// operationID derives a unique identifier from the method and path ("GET /api/notes/:id" -> "get_notes_id")
+func operationID(method, path string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/api/"), "/") {
+		segment = strings.TrimLeft(segment, ":*")
+		sb.WriteByte('_')
+		sb.WriteString(strings.NewReplacer("-", "_", ".", "_").Replace(segment))
+	}
+	return sb.String()
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaBuilder turns Go types into schemas, adding named structs to the components
+type schemaBuilder struct {
+	schemas map[string]*Schema
+	types   map[string]reflect.Type
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		name := b.name(t)
+		if _, ok := b.schemas[name]; !ok {
+			b.schemas[name] = &Schema{} // Placeholder for recursive types
+			*b.schemas[name] = *b.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
+
+// name returns the component name of a struct type, qualified by its package when two packages use the same name
+func (b *schemaBuilder) name(t reflect.Type) string {
+	name := t.Name()
+	if known, ok := b.types[name]; ok && known != t {
+		name = pathBase(t.PkgPath()) + name
+	}
+	b.types[name] = t
+	return name
+}
+
+func pathBase(pkgPath string) string {
+	if i := strings.LastIndex(pkgPath, "/"); i >= 0 {
+		pkgPath = pkgPath[i+1:]
+	}
+	if pkgPath == "" {
+		return ""
+	}
+	return strings.ToUpper(pkgPath[:1]) + pkgPath[1:]
+}
+
+// structSchema lists the JSON fields of a struct, including those of embedded structs
+// Fields with binding:"required" are required
+func (b *schemaBuilder) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tagValue := field.Tag.Get("json")
+		if tagValue == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, _, _ := strings.Cut(tagValue, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner := b.structSchema(embedded)
+				for key, value := range inner.Properties {
+					s.Properties[key] = value
+				}
+				s.Required = append(s.Required, inner.Required...)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = b.schema(field.Type)
+		if strings.Contains(field.Tag.Get("binding"), "required") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	sort.Strings(s.Required)
+	return s
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/user/gitnotepad/internal/model"
+)
+
+type APITokenRepository struct {
+	db *sql.DB
+}
+
+func NewAPITokenRepository(db *sql.DB) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+const apiTokenSelect = `SELECT id, user_id, token_hash, name, scopes, encrypted, expires_at, last_used_at, created_at
+	FROM api_tokens`
+
+// Create stores a new token
+func (r *APITokenRepository) Create(token *model.APIToken) error {
+	result, err := r.db.Exec(
+		"INSERT INTO api_tokens (user_id, token_hash, name, scopes, encrypted, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		token.UserID, token.TokenHash, token.Name, strings.Join(token.Scopes, ","), token.Encrypted, token.ExpiresAt, token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create api token: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get api token id: %w", err)
+	}
+	token.ID = id
+
+	return nil
+}
+
+// GetByToken retrieves a token by its secret value
+func (r *APITokenRepository) GetByToken(secret string) (*model.APIToken, error) {
+	tokens, err := r.query(apiTokenSelect+" WHERE token_hash = ?", model.HashAPIToken(secret))
+	if err != nil || len(tokens) == 0 {
+		return nil, err
+	}
+	return tokens[0], nil
+}
+
+// ListByUser returns the unexpired tokens of a user, newest first
+func (r *APITokenRepository) ListByUser(userID int64) ([]*model.APIToken, error) {
+	return r.query(apiTokenSelect+" WHERE user_id = ? AND expires_at > ? ORDER BY created_at DESC, id DESC", userID, time.Now())
+}
+
+// Touch records that a token was used
+func (r *APITokenRepository) Touch(id int64) error {
+	_, err := r.db.Exec("UPDATE api_tokens SET last_used_at = ? WHERE id = ?", time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update api token: %w", err)
+	}
+	return nil
+}
+
+// Delete revokes a token of a user; returns false when the user has no such token
+func (r *APITokenRepository) Delete(id, userID int64) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM api_tokens WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete api token: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// ActiveHashes returns the hashes of all unexpired tokens (the key store indexes their encryption keys by hash)
+func (r *APITokenRepository) ActiveHashes() ([]string, error) {
+	return queryStrings(r.db, "SELECT token_hash FROM api_tokens WHERE expires_at > ?", time.Now())
+}
+
+// DeleteExpired deletes all expired tokens
+func (r *APITokenRepository) DeleteExpired() error {
+	_, err := r.db.Exec("DELETE FROM api_tokens WHERE expires_at < ?", time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to delete expired api tokens: %w", err)
+	}
+	return nil
+}
+
+func (r *APITokenRepository) query(query string, args ...interface{}) ([]*model.APIToken, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []*model.APIToken
+	for rows.Next() {
+		token := &model.APIToken{}
+		var scopes string
+		var lastUsed sql.NullTime
+		if err := rows.Scan(&token.ID, &token.UserID, &token.TokenHash, &token.Name, &scopes, &token.Encrypted,
+			&token.ExpiresAt, &lastUsed, &token.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan api token: %w", err)
+		}
+		token.Scopes = strings.Split(scopes, ",")
+		if lastUsed.Valid {
+			token.LastUsedAt = &lastUsed.Time
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
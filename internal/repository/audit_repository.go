@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/user/gitnotepad/internal/model"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create records an audit event
+func (r *AuditRepository) Create(event *model.AuditEvent) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	result, err := r.db.Exec(
+		"INSERT INTO audit_log (username, action, target, detail, ip, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		event.Username, event.Action, event.Target, event.Detail, event.IP, event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit event: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get audit event id: %w", err)
+	}
+	event.ID = id
+	return nil
+}
+
+// List retrieves audit events since a time, newest first (username filter is optional)
+func (r *AuditRepository) List(since time.Time, username string, limit int) ([]*model.AuditEvent, error) {
+	query := "SELECT id, username, action, target, detail, ip, created_at FROM audit_log WHERE created_at >= ?"
+	args := []interface{}{since}
+	if username != "" {
+		query += " AND username = ?"
+		args = append(args, username)
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*model.AuditEvent
+	for rows.Next() {
+		event := &model.AuditEvent{}
+		if err := rows.Scan(&event.ID, &event.Username, &event.Action, &event.Target,
+			&event.Detail, &event.IP, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/user/gitnotepad/internal/model"
+)
+
+type FolderRuleRepository struct {
+	db *sql.DB
+}
+
+func NewFolderRuleRepository(db *sql.DB) *FolderRuleRepository {
+	return &FolderRuleRepository{db: db}
+}
+
+// Upsert creates a rule or updates the permission of the existing rule for the folder
+func (r *FolderRuleRepository) Upsert(rule *model.FolderRule) error {
+	_, err := r.db.Exec(
+		`INSERT INTO folder_rules (user_id, folder_path, permission) VALUES (?, ?, ?)
+		 ON CONFLICT(user_id, folder_path) DO UPDATE SET permission = excluded.permission`,
+		rule.UserID, rule.FolderPath, rule.Permission,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save folder rule: %w", err)
+	}
+
+	err = r.db.QueryRow(
+		"SELECT id, created_at FROM folder_rules WHERE user_id = ? AND folder_path = ?",
+		rule.UserID, rule.FolderPath,
+	).Scan(&rule.ID, &rule.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to get folder rule id: %w", err)
+	}
+	return nil
+}
+
+// ListByUser retrieves the folder rules of a user
+func (r *FolderRuleRepository) ListByUser(userID int64) ([]*model.FolderRule, error) {
+	rows, err := r.db.Query(
+		"SELECT id, user_id, folder_path, permission, created_at FROM folder_rules WHERE user_id = ? ORDER BY folder_path",
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list folder rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*model.FolderRule
+	for rows.Next() {
+		rule := &model.FolderRule{}
+		if err := rows.Scan(&rule.ID, &rule.UserID, &rule.FolderPath, &rule.Permission, &rule.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan folder rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// Delete deletes a rule of a user; returns false when there was no such rule
+func (r *FolderRuleRepository) Delete(userID, id int64) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM folder_rules WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete folder rule: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// RenamePath moves the rules of a folder and its subfolders to a new path
+func (r *FolderRuleRepository) RenamePath(userID int64, oldPath, newPath string) error {
+	_, err := r.db.Exec(
+		`UPDATE folder_rules SET folder_path = ? || substr(folder_path, ?)
+		 WHERE user_id = ? AND (folder_path = ? OR substr(folder_path, 1, ?) = ?)`,
+		newPath, utf8.RuneCountInString(oldPath)+1, userID, oldPath, utf8.RuneCountInString(oldPath)+1, oldPath+"/",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to rename folder rules: %w", err)
+	}
+	return nil
+}
+
+// DeletePath removes the rules of a folder and its subfolders
+func (r *FolderRuleRepository) DeletePath(userID int64, folderPath string) error {
+	_, err := r.db.Exec(
+		`DELETE FROM folder_rules WHERE user_id = ? AND (folder_path = ? OR substr(folder_path, 1, ?) = ?)`,
+		userID, folderPath, utf8.RuneCountInString(folderPath)+1, folderPath+"/",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete folder rules: %w", err)
+	}
+	return nil
+}
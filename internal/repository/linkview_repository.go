@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/user/gitnotepad/internal/model"
+)
+
+type LinkViewRepository struct {
+	db *sql.DB
+}
+
+func NewLinkViewRepository(db *sql.DB) *LinkViewRepository {
+	return &LinkViewRepository{db: db}
+}
+
+// Record counts a view of a link on a day
+// visitorHash identifies the visitor for unique counting ("" = not tracked)
+func (r *LinkViewRepository) Record(code, day, visitorHash string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to record view: %w", err)
+	}
+	defer tx.Rollback()
+
+	newVisitor := 0
+	if visitorHash != "" {
+		result, err := tx.Exec("INSERT OR IGNORE INTO link_visitors (code, day, hash) VALUES (?, ?, ?)", code, day, visitorHash)
+		if err != nil {
+			return fmt.Errorf("failed to record visitor: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			newVisitor = 1
+		}
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO link_views (code, day, views, visitors) VALUES (?, ?, 1, ?)
+		 ON CONFLICT(code, day) DO UPDATE SET views = views + 1, visitors = visitors + excluded.visitors`,
+		code, day, newVisitor,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record view: %w", err)
+	}
+	return tx.Commit()
+}
+
+// Daily returns the daily counts of a link from a day on (oldest first, days without views omitted)
+func (r *LinkViewRepository) Daily(code, since string) ([]model.LinkViewDay, error) {
+	rows, err := r.db.Query(
+		"SELECT day, views, visitors FROM link_views WHERE code = ? AND day >= ? ORDER BY day",
+		code, since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link views: %w", err)
+	}
+	defer rows.Close()
+
+	var days []model.LinkViewDay
+	for rows.Next() {
+		var d model.LinkViewDay
+		if err := rows.Scan(&d.Day, &d.Views, &d.Visitors); err != nil {
+			return nil, fmt.Errorf("failed to scan link views: %w", err)
+		}
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
+
+// Totals returns the number of views per link code from a day on
+func (r *LinkViewRepository) Totals(since string) (map[string]int, error) {
+	rows, err := r.db.Query("SELECT code, SUM(views) FROM link_views WHERE day >= ? GROUP BY code", since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link view totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := make(map[string]int)
+	for rows.Next() {
+		var code string
+		var views int
+		if err := rows.Scan(&code, &views); err != nil {
+			return nil, fmt.Errorf("failed to scan link view totals: %w", err)
+		}
+		totals[code] = views
+	}
+	return totals, rows.Err()
+}
+
+// RenameCode moves the views and visitors of a link to its new code
+func (r *LinkViewRepository) RenameCode(oldCode, newCode string) error {
+	if _, err := r.db.Exec("UPDATE link_views SET code = ? WHERE code = ?", newCode, oldCode); err != nil {
+		return fmt.Errorf("failed to rename link views: %w", err)
+	}
+	if _, err := r.db.Exec("UPDATE link_visitors SET code = ? WHERE code = ?", newCode, oldCode); err != nil {
+		return fmt.Errorf("failed to rename link visitors: %w", err)
+	}
+	return nil
+}
+
+// DeleteByCode removes the counts of a deleted link
+func (r *LinkViewRepository) DeleteByCode(code string) error {
+	if _, err := r.db.Exec("DELETE FROM link_views WHERE code = ?", code); err != nil {
+		return fmt.Errorf("failed to delete link views: %w", err)
+	}
+	if _, err := r.db.Exec("DELETE FROM link_visitors WHERE code = ?", code); err != nil {
+		return fmt.Errorf("failed to delete link visitors: %w", err)
+	}
+	return nil
+}
+
+// Prune removes counts older than keepSince and visitor hashes older than today
+func (r *LinkViewRepository) Prune(keepSince, today string) error {
+	if _, err := r.db.Exec("DELETE FROM link_views WHERE day < ?", keepSince); err != nil {
+		return fmt.Errorf("failed to prune link views: %w", err)
+	}
+	if _, err := r.db.Exec("DELETE FROM link_visitors WHERE day < ?", today); err != nil {
+		return fmt.Errorf("failed to prune link visitors: %w", err)
+	}
+	return nil
+}
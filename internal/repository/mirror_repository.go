@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/user/gitnotepad/internal/model"
+)
+
+type MirrorRepository struct {
+	db *sql.DB
+}
+
+func NewMirrorRepository(db *sql.DB) *MirrorRepository {
+	return &MirrorRepository{db: db}
+}
+
+const mirrorSelect = `SELECT m.user_id, m.url, m.username, m.token, m.enabled, m.last_push, m.last_error, m.updated_at
+	FROM git_mirrors m`
+
+// Get retrieves the mirror of a user
+func (r *MirrorRepository) Get(userID int64) (*model.GitMirror, error) {
+	return r.queryOne(mirrorSelect+" WHERE m.user_id = ?", userID)
+}
+
+// GetByUsername retrieves the mirror of a user by username
+func (r *MirrorRepository) GetByUsername(username string) (*model.GitMirror, error) {
+	return r.queryOne(mirrorSelect+" JOIN users u ON u.id = m.user_id WHERE u.username = ?", username)
+}
+
+// Upsert creates or replaces the mirror of a user (push status is reset)
+func (r *MirrorRepository) Upsert(mirror *model.GitMirror) error {
+	mirror.UpdatedAt = time.Now()
+	_, err := r.db.Exec(
+		`INSERT INTO git_mirrors (user_id, url, username, token, enabled, last_push, last_error, updated_at)
+		 VALUES (?, ?, ?, ?, ?, NULL, '', ?)
+		 ON CONFLICT(user_id) DO UPDATE SET
+		   url = excluded.url, username = excluded.username, token = excluded.token, enabled = excluded.enabled,
+		   last_push = NULL, last_error = '', updated_at = excluded.updated_at`,
+		mirror.UserID, mirror.URL, mirror.Username, mirror.Token, mirror.Enabled, mirror.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save mirror: %w", err)
+	}
+	mirror.LastPush = nil
+	mirror.LastError = ""
+	return nil
+}
+
+// UpdateStatus records the result of a push
+func (r *MirrorRepository) UpdateStatus(userID int64, pushed time.Time, pushErr error) error {
+	var err error
+	if pushErr != nil {
+		_, err = r.db.Exec("UPDATE git_mirrors SET last_error = ? WHERE user_id = ?", pushErr.Error(), userID)
+	} else {
+		_, err = r.db.Exec("UPDATE git_mirrors SET last_push = ?, last_error = '' WHERE user_id = ?", pushed, userID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update mirror status: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the mirror of a user
+func (r *MirrorRepository) Delete(userID int64) error {
+	_, err := r.db.Exec("DELETE FROM git_mirrors WHERE user_id = ?", userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete mirror: %w", err)
+	}
+	return nil
+}
+
+func (r *MirrorRepository) queryOne(query string, args ...interface{}) (*model.GitMirror, error) {
+	mirror := &model.GitMirror{}
+	var lastPush sql.NullTime
+	err := r.db.QueryRow(query, args...).Scan(&mirror.UserID, &mirror.URL, &mirror.Username, &mirror.Token,
+		&mirror.Enabled, &lastPush, &mirror.LastError, &mirror.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get mirror: %w", err)
+	}
+	if lastPush.Valid {
+		mirror.LastPush = &lastPush.Time
+	}
+	return mirror, nil
+}
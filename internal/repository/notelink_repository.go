@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+type NoteLinkRepository struct {
+	db *sql.DB
+}
+
+func NewNoteLinkRepository(db *sql.DB) *NoteLinkRepository {
+	return &NoteLinkRepository{db: db}
+}
+
+// SetLinks replaces the link targets of a note
+func (r *NoteLinkRepository) SetLinks(username, sourceUID string, targets []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save note links: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM note_links WHERE username = ? AND source_uid = ?", username, sourceUID); err != nil {
+		return fmt.Errorf("failed to save note links: %w", err)
+	}
+	for _, target := range targets {
+		if _, err := tx.Exec(
+			"INSERT OR IGNORE INTO note_links (username, source_uid, target) VALUES (?, ?, ?)",
+			username, sourceUID, target,
+		); err != nil {
+			return fmt.Errorf("failed to save note links: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Sources returns the UIDs of a user's notes linking to any of the targets
+func (r *NoteLinkRepository) Sources(username string, targets []string) ([]string, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	args := []interface{}{username}
+	for _, target := range targets {
+		args = append(args, target)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(targets)), ",")
+
+	rows, err := r.db.Query(
+		"SELECT DISTINCT source_uid FROM note_links WHERE username = ? AND target IN ("+placeholders+")",
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backlinks: %w", err)
+	}
+	defer rows.Close()
+
+	var sources []string
+	for rows.Next() {
+		var uid string
+		if err := rows.Scan(&uid); err != nil {
+			return nil, fmt.Errorf("failed to scan backlink: %w", err)
+		}
+		sources = append(sources, uid)
+	}
+	return sources, rows.Err()
+}
+
+// Count returns the number of stored links
+func (r *NoteLinkRepository) Count() (int, error) {
+	var count int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM note_links").Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count note links: %w", err)
+	}
+	return count, nil
+}
+
+// RenameUser moves the links of a user to a new username
+func (r *NoteLinkRepository) RenameUser(oldUsername, newUsername string) error {
+	if _, err := r.db.Exec("UPDATE note_links SET username = ? WHERE username = ?", newUsername, oldUsername); err != nil {
+		return fmt.Errorf("failed to rename note links: %w", err)
+	}
+	return nil
+}
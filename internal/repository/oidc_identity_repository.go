@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// OIDCIdentityRepository links accounts at OpenID Connect providers (issuer + subject) to users
+type OIDCIdentityRepository struct {
+	db *sql.DB
+}
+
+func NewOIDCIdentityRepository(db *sql.DB) *OIDCIdentityRepository {
+	return &OIDCIdentityRepository{db: db}
+}
+
+// GetUserID returns the user linked to an account, or 0 when it isn't linked
+func (r *OIDCIdentityRepository) GetUserID(issuer, subject string) (int64, error) {
+	var userID int64
+	err := r.db.QueryRow("SELECT user_id FROM oidc_identities WHERE issuer = ? AND subject = ?", issuer, subject).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get oidc identity: %w", err)
+	}
+	return userID, nil
+}
+
+// Link links an account to a user, replacing a link to a user that was deleted
+func (r *OIDCIdentityRepository) Link(userID int64, issuer, subject string) error {
+	_, err := r.db.Exec(
+		"INSERT INTO oidc_identities (user_id, issuer, subject) VALUES (?, ?, ?) ON CONFLICT(issuer, subject) DO UPDATE SET user_id = excluded.user_id",
+		userID, issuer, subject,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to link oidc identity: %w", err)
+	}
+	return nil
+}
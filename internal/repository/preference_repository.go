@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/user/gitnotepad/internal/model"
+)
+
+type PreferenceRepository struct {
+	db *sql.DB
+}
+
+func NewPreferenceRepository(db *sql.DB) *PreferenceRepository {
+	return &PreferenceRepository{db: db}
+}
+
+// Get retrieves the preferences of a user (empty when none are set)
+func (r *PreferenceRepository) Get(userID int64) (*model.Preferences, error) {
+	return r.query("SELECT name, value FROM user_preferences WHERE user_id = ?", userID)
+}
+
+// GetByUsername retrieves the preferences of a user by username
+func (r *PreferenceRepository) GetByUsername(username string) (*model.Preferences, error) {
+	return r.query(
+		"SELECT p.name, p.value FROM user_preferences p JOIN users u ON u.id = p.user_id WHERE u.username = ?",
+		username,
+	)
+}
+
+// Save stores all preferences of a user; empty values are removed
+func (r *PreferenceRepository) Save(userID int64, prefs *model.Preferences) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	defer tx.Rollback()
+
+	for name, value := range prefs.Values() {
+		if value == "" {
+			_, err = tx.Exec("DELETE FROM user_preferences WHERE user_id = ? AND name = ?", userID, name)
+		} else {
+			_, err = tx.Exec(
+				`INSERT INTO user_preferences (user_id, name, value) VALUES (?, ?, ?)
+				 ON CONFLICT(user_id, name) DO UPDATE SET value = excluded.value`,
+				userID, name, value,
+			)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save preferences: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return nil
+}
+
+func (r *PreferenceRepository) query(query string, args ...interface{}) (*model.Preferences, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+	defer rows.Close()
+
+	prefs := &model.Preferences{}
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan preference: %w", err)
+		}
+		prefs.Set(name, value)
+	}
+	return prefs, rows.Err()
+}
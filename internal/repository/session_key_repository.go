@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SessionKeyRepository stores session encryption keys wrapped with the server master key,
+// so logged-in users can open their encrypted notes after a restart
+type SessionKeyRepository struct {
+	db *sql.DB
+}
+
+func NewSessionKeyRepository(db *sql.DB) *SessionKeyRepository {
+	return &SessionKeyRepository{db: db}
+}
+
+// SaveKey stores or replaces a wrapped key
+func (r *SessionKeyRepository) SaveKey(id, wrapped string, expiresAt time.Time) error {
+	_, err := r.db.Exec(
+		"INSERT OR REPLACE INTO session_keys (id, wrapped_key, expires_at) VALUES (?, ?, ?)",
+		id, wrapped, expiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save session key: %w", err)
+	}
+	return nil
+}
+
+// LoadKeys removes expired keys and returns the remaining ones by id
+func (r *SessionKeyRepository) LoadKeys() (map[string]string, error) {
+	if err := r.DeleteExpired(); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query("SELECT id, wrapped_key FROM session_keys")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make(map[string]string)
+	for rows.Next() {
+		var id, wrapped string
+		if err := rows.Scan(&id, &wrapped); err != nil {
+			return nil, fmt.Errorf("failed to scan session key: %w", err)
+		}
+		keys[id] = wrapped
+	}
+	return keys, rows.Err()
+}
+
+// DeleteKey deletes a wrapped key
+func (r *SessionKeyRepository) DeleteKey(id string) error {
+	_, err := r.db.Exec("DELETE FROM session_keys WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete session key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired deletes all expired keys
+func (r *SessionKeyRepository) DeleteExpired() error {
+	_, err := r.db.Exec("DELETE FROM session_keys WHERE expires_at < ?", time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to delete expired session keys: %w", err)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/user/gitnotepad/internal/model"
 )
@@ -15,11 +16,14 @@ func NewSessionRepository(db *sql.DB) *SessionRepository {
 	return &SessionRepository{db: db}
 }
 
+const sessionSelect = `SELECT id, user_id, token, expires_at, created_at, user_agent, ip, last_used_at
+	FROM sessions`
+
 // Create creates a new session
 func (r *SessionRepository) Create(session *model.Session) error {
 	result, err := r.db.Exec(
-		"INSERT INTO sessions (user_id, token, expires_at) VALUES (?, ?, ?)",
-		session.UserID, session.Token, session.ExpiresAt,
+		"INSERT INTO sessions (user_id, token, expires_at, user_agent, ip, last_used_at) VALUES (?, ?, ?, ?, ?, ?)",
+		session.UserID, session.Token, session.ExpiresAt, session.UserAgent, session.IP, session.LastUsedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create session: %w", err)
@@ -36,18 +40,34 @@ func (r *SessionRepository) Create(session *model.Session) error {
 
 // GetByToken retrieves a session by token
 func (r *SessionRepository) GetByToken(token string) (*model.Session, error) {
-	session := &model.Session{}
-	err := r.db.QueryRow(
-		"SELECT id, user_id, token, expires_at, created_at FROM sessions WHERE token = ?",
-		token,
-	).Scan(&session.ID, &session.UserID, &session.Token, &session.ExpiresAt, &session.CreatedAt)
+	sessions, err := r.query(sessionSelect+" WHERE token = ?", token)
+	if err != nil || len(sessions) == 0 {
+		return nil, err
+	}
+	return sessions[0], nil
+}
+
+// GetByID retrieves a session by ID
+func (r *SessionRepository) GetByID(id int64) (*model.Session, error) {
+	sessions, err := r.query(sessionSelect+" WHERE id = ?", id)
+	if err != nil || len(sessions) == 0 {
+		return nil, err
+	}
+	return sessions[0], nil
+}
+
+// ListByUser returns the unexpired sessions of a user, most recently used first
+func (r *SessionRepository) ListByUser(userID int64) ([]*model.Session, error) {
+	return r.query(sessionSelect+" WHERE user_id = ? AND expires_at > ? ORDER BY COALESCE(last_used_at, created_at) DESC, id DESC", userID, time.Now())
+}
+
+// Touch records that a session was used, and from where
+func (r *SessionRepository) Touch(id int64, ip string) error {
+	_, err := r.db.Exec("UPDATE sessions SET last_used_at = ?, ip = ? WHERE id = ?", time.Now(), ip, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to get session: %w", err)
+		return fmt.Errorf("failed to update session: %w", err)
 	}
-	return session, nil
+	return nil
 }
 
 // Delete deletes a session by token
@@ -68,11 +88,58 @@ func (r *SessionRepository) DeleteByUserID(userID int64) error {
 	return nil
 }
 
+func (r *SessionRepository) query(query string, args ...interface{}) ([]*model.Session, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*model.Session
+	for rows.Next() {
+		session := &model.Session{}
+		var lastUsed sql.NullTime
+		if err := rows.Scan(&session.ID, &session.UserID, &session.Token, &session.ExpiresAt, &session.CreatedAt,
+			&session.UserAgent, &session.IP, &lastUsed); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		if lastUsed.Valid {
+			session.LastUsedAt = &lastUsed.Time
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// ActiveTokens returns the tokens of all unexpired sessions
+func (r *SessionRepository) ActiveTokens() ([]string, error) {
+	return queryStrings(r.db, "SELECT token FROM sessions WHERE expires_at > ?", time.Now())
+}
+
 // DeleteExpired deletes all expired sessions
 func (r *SessionRepository) DeleteExpired() error {
-	_, err := r.db.Exec("DELETE FROM sessions WHERE expires_at < datetime('now')")
+	_, err := r.db.Exec("DELETE FROM sessions WHERE expires_at < ?", time.Now())
 	if err != nil {
 		return fmt.Errorf("failed to delete expired sessions: %w", err)
 	}
 	return nil
 }
+
+// queryStrings returns the single string column of a query
+func queryStrings(db *sql.DB, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %w", err)
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("failed to scan: %w", err)
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
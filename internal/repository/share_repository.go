@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/user/gitnotepad/internal/model"
+)
+
+type ShareRepository struct {
+	db *sql.DB
+}
+
+func NewShareRepository(db *sql.DB) *ShareRepository {
+	return &ShareRepository{db: db}
+}
+
+const shareSelect = `SELECT s.id, s.owner_id, o.username, s.folder_path, s.grantee_id, g.username, s.permission, s.created_at
+	FROM folder_shares s
+	JOIN users o ON o.id = s.owner_id
+	JOIN users g ON g.id = s.grantee_id`
+
+// Upsert creates a share or updates the permission of an existing one
+func (r *ShareRepository) Upsert(share *model.FolderShare) error {
+	_, err := r.db.Exec(
+		`INSERT INTO folder_shares (owner_id, folder_path, grantee_id, permission) VALUES (?, ?, ?, ?)
+		 ON CONFLICT(owner_id, folder_path, grantee_id) DO UPDATE SET permission = excluded.permission`,
+		share.OwnerID, share.FolderPath, share.GranteeID, share.Permission,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save share: %w", err)
+	}
+
+	err = r.db.QueryRow(
+		"SELECT id, created_at FROM folder_shares WHERE owner_id = ? AND folder_path = ? AND grantee_id = ?",
+		share.OwnerID, share.FolderPath, share.GranteeID,
+	).Scan(&share.ID, &share.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to get share id: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a share by ID
+func (r *ShareRepository) GetByID(id int64) (*model.FolderShare, error) {
+	shares, err := r.query(shareSelect+" WHERE s.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(shares) == 0 {
+		return nil, nil
+	}
+	return shares[0], nil
+}
+
+// ListByOwner retrieves all shares created by a user
+func (r *ShareRepository) ListByOwner(ownerID int64) ([]*model.FolderShare, error) {
+	return r.query(shareSelect+" WHERE s.owner_id = ? ORDER BY s.folder_path, g.username", ownerID)
+}
+
+// ListByGrantee retrieves all shares granted to a user
+func (r *ShareRepository) ListByGrantee(granteeID int64) ([]*model.FolderShare, error) {
+	return r.query(shareSelect+" WHERE s.grantee_id = ? ORDER BY o.username, s.folder_path", granteeID)
+}
+
+// ListByOwnerAndGrantee retrieves shares from one owner to one grantee
+func (r *ShareRepository) ListByOwnerAndGrantee(ownerID, granteeID int64) ([]*model.FolderShare, error) {
+	return r.query(shareSelect+" WHERE s.owner_id = ? AND s.grantee_id = ?", ownerID, granteeID)
+}
+
+// Delete deletes a share by ID
+func (r *ShareRepository) Delete(id int64) error {
+	_, err := r.db.Exec("DELETE FROM folder_shares WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete share: %w", err)
+	}
+	return nil
+}
+
+// RenamePath moves shares of a folder and its subfolders to a new path
+func (r *ShareRepository) RenamePath(ownerID int64, oldPath, newPath string) error {
+	_, err := r.db.Exec(
+		`UPDATE folder_shares SET folder_path = ? || substr(folder_path, ?)
+		 WHERE owner_id = ? AND (folder_path = ? OR substr(folder_path, 1, ?) = ?)`,
+		newPath, utf8.RuneCountInString(oldPath)+1, ownerID, oldPath, utf8.RuneCountInString(oldPath)+1, oldPath+"/",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to rename shares: %w", err)
+	}
+	return nil
+}
+
+// DeletePath removes shares of a folder and its subfolders
+func (r *ShareRepository) DeletePath(ownerID int64, folderPath string) error {
+	_, err := r.db.Exec(
+		`DELETE FROM folder_shares WHERE owner_id = ? AND (folder_path = ? OR substr(folder_path, 1, ?) = ?)`,
+		ownerID, folderPath, utf8.RuneCountInString(folderPath)+1, folderPath+"/",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete shares: %w", err)
+	}
+	return nil
+}
+
+func (r *ShareRepository) query(query string, args ...interface{}) ([]*model.FolderShare, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shares: %w", err)
+	}
+	defer rows.Close()
+
+	var shares []*model.FolderShare
+	for rows.Next() {
+		share := &model.FolderShare{}
+		if err := rows.Scan(&share.ID, &share.OwnerID, &share.OwnerUsername, &share.FolderPath,
+			&share.GranteeID, &share.GranteeUsername, &share.Permission, &share.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan share: %w", err)
+		}
+		shares = append(shares, share)
+	}
+
+	return shares, nil
+}
+
+const noteShareSelect = `SELECT s.id, s.owner_id, o.username, s.note_uid, s.grantee_id, g.username, s.permission, s.created_at
+	FROM note_shares s
+	JOIN users o ON o.id = s.owner_id
+	JOIN users g ON g.id = s.grantee_id`
+
+// UpsertNote creates a note share or updates the permission of an existing one
+func (r *ShareRepository) UpsertNote(share *model.NoteShare) error {
+	_, err := r.db.Exec(
+		`INSERT INTO note_shares (owner_id, note_uid, grantee_id, permission) VALUES (?, ?, ?, ?)
+		 ON CONFLICT(owner_id, note_uid, grantee_id) DO UPDATE SET permission = excluded.permission`,
+		share.OwnerID, share.NoteUID, share.GranteeID, share.Permission,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save note share: %w", err)
+	}
+
+	err = r.db.QueryRow(
+		"SELECT id, created_at FROM note_shares WHERE owner_id = ? AND note_uid = ? AND grantee_id = ?",
+		share.OwnerID, share.NoteUID, share.GranteeID,
+	).Scan(&share.ID, &share.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to get note share id: %w", err)
+	}
+	return nil
+}
+
+// GetNoteShare retrieves a note share by ID
+func (r *ShareRepository) GetNoteShare(id int64) (*model.NoteShare, error) {
+	shares, err := r.queryNotes(noteShareSelect+" WHERE s.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(shares) == 0 {
+		return nil, nil
+	}
+	return shares[0], nil
+}
+
+// ListNoteSharesByNote retrieves the shares of one note
+func (r *ShareRepository) ListNoteSharesByNote(ownerID int64, noteUID string) ([]*model.NoteShare, error) {
+	return r.queryNotes(noteShareSelect+" WHERE s.owner_id = ? AND s.note_uid = ? ORDER BY g.username", ownerID, noteUID)
+}
+
+// ListNoteSharesByGrantee retrieves all notes shared with a user
+func (r *ShareRepository) ListNoteSharesByGrantee(granteeID int64) ([]*model.NoteShare, error) {
+	return r.queryNotes(noteShareSelect+" WHERE s.grantee_id = ? ORDER BY o.username, s.created_at DESC", granteeID)
+}
+
+// ListNoteSharesByOwnerAndGrantee retrieves notes shared from one owner to one grantee
+func (r *ShareRepository) ListNoteSharesByOwnerAndGrantee(ownerID, granteeID int64) ([]*model.NoteShare, error) {
+	return r.queryNotes(noteShareSelect+" WHERE s.owner_id = ? AND s.grantee_id = ?", ownerID, granteeID)
+}
+
+// DeleteNoteShare deletes a note share by ID
+func (r *ShareRepository) DeleteNoteShare(id int64) error {
+	_, err := r.db.Exec("DELETE FROM note_shares WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete note share: %w", err)
+	}
+	return nil
+}
+
+func (r *ShareRepository) queryNotes(query string, args ...interface{}) ([]*model.NoteShare, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list note shares: %w", err)
+	}
+	defer rows.Close()
+
+	var shares []*model.NoteShare
+	for rows.Next() {
+		share := &model.NoteShare{}
+		if err := rows.Scan(&share.ID, &share.OwnerID, &share.OwnerUsername, &share.NoteUID,
+			&share.GranteeID, &share.GranteeUsername, &share.Permission, &share.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan note share: %w", err)
+		}
+		shares = append(shares, share)
+	}
+
+	return shares, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/user/gitnotepad/internal/model"
+)
+
+type ShortLinkRepository struct {
+	db *sql.DB
+}
+
+func NewShortLinkRepository(db *sql.DB) *ShortLinkRepository {
+	return &ShortLinkRepository{db: db}
+}
+
+// Save creates or replaces the link with a code
+func (r *ShortLinkRepository) Save(code string, link *model.ShortLink) error {
+	return saveShortLink(r.db, code, link)
+}
+
+// SaveAll stores links in one transaction (used to import the legacy links file)
+func (r *ShortLinkRepository) SaveAll(links map[string]*model.ShortLink) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for code, link := range links {
+		if err := saveShortLink(tx, code, link); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit short links: %w", err)
+	}
+	return nil
+}
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func saveShortLink(db execer, code string, link *model.ShortLink) error {
+	_, err := db.Exec(
+		`INSERT INTO short_links (code, note_id, note_uid, folder_path, username, expires_at, created_at, is_public, blog,
+		   password_hash, view_count, max_views, last_access_at, last_access_ip)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(code) DO UPDATE SET
+		   note_id = excluded.note_id, note_uid = excluded.note_uid, folder_path = excluded.folder_path,
+		   username = excluded.username, expires_at = excluded.expires_at, created_at = excluded.created_at,
+		   is_public = excluded.is_public, blog = excluded.blog, password_hash = excluded.password_hash,
+		   view_count = excluded.view_count, max_views = excluded.max_views,
+		   last_access_at = excluded.last_access_at, last_access_ip = excluded.last_access_ip`,
+		code, link.NoteID, link.NoteUID, link.FolderPath, link.Username, link.ExpiresAt, link.CreatedAt, link.IsPublic, link.Blog,
+		link.PasswordHash, link.ViewCount, link.MaxViews, link.LastAccessAt, link.LastAccessIP,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save short link: %w", err)
+	}
+	return nil
+}
+
+// List returns all links by code
+func (r *ShortLinkRepository) List() (map[string]*model.ShortLink, error) {
+	rows, err := r.db.Query(
+		`SELECT code, note_id, note_uid, folder_path, username, expires_at, created_at, is_public, blog,
+		   password_hash, view_count, max_views, last_access_at, last_access_ip
+		 FROM short_links`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list short links: %w", err)
+	}
+	defer rows.Close()
+
+	links := make(map[string]*model.ShortLink)
+	for rows.Next() {
+		var code string
+		var expiresAt, lastAccessAt sql.NullTime
+		link := &model.ShortLink{}
+		if err := rows.Scan(&code, &link.NoteID, &link.NoteUID, &link.FolderPath, &link.Username, &expiresAt, &link.CreatedAt,
+			&link.IsPublic, &link.Blog, &link.PasswordHash, &link.ViewCount, &link.MaxViews, &lastAccessAt, &link.LastAccessIP); err != nil {
+			return nil, fmt.Errorf("failed to scan short link: %w", err)
+		}
+		if expiresAt.Valid {
+			link.ExpiresAt = &expiresAt.Time
+		}
+		if lastAccessAt.Valid {
+			link.LastAccessAt = &lastAccessAt.Time
+		}
+		links[code] = link
+	}
+	return links, rows.Err()
+}
+
+// Count returns the number of stored links
+func (r *ShortLinkRepository) Count() (int, error) {
+	var count int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM short_links").Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count short links: %w", err)
+	}
+	return count, nil
+}
+
+// Rename changes the code of a link
+func (r *ShortLinkRepository) Rename(oldCode, newCode string) error {
+	if _, err := r.db.Exec("UPDATE short_links SET code = ? WHERE code = ?", newCode, oldCode); err != nil {
+		return fmt.Errorf("failed to rename short link: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a link
+func (r *ShortLinkRepository) Delete(code string) error {
+	if _, err := r.db.Exec("DELETE FROM short_links WHERE code = ?", code); err != nil {
+		return fmt.Errorf("failed to delete short link: %w", err)
+	}
+	return nil
+}
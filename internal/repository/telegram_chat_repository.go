@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// TelegramChatRepository stores the target folder chosen with /folder per Telegram chat
+type TelegramChatRepository struct {
+	db *sql.DB
+}
+
+func NewTelegramChatRepository(db *sql.DB) *TelegramChatRepository {
+	return &TelegramChatRepository{db: db}
+}
+
+// GetFolder returns the folder chosen for a chat ("" = top level); ok is false when none was chosen
+func (r *TelegramChatRepository) GetFolder(chatID int64) (string, bool, error) {
+	var folder string
+	err := r.db.QueryRow("SELECT folder FROM telegram_chats WHERE chat_id = ?", chatID).Scan(&folder)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get chat folder: %w", err)
+	}
+	return folder, true, nil
+}
+
+// SetFolder stores the folder for a chat
+func (r *TelegramChatRepository) SetFolder(chatID int64, folder string) error {
+	_, err := r.db.Exec(
+		`INSERT INTO telegram_chats (chat_id, folder) VALUES (?, ?)
+		 ON CONFLICT(chat_id) DO UPDATE SET folder = excluded.folder`,
+		chatID, folder,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set chat folder: %w", err)
+	}
+	return nil
+}
+
+// DeleteFolder forgets the folder of a chat, so notes go to the default folder again
+func (r *TelegramChatRepository) DeleteFolder(chatID int64) error {
+	_, err := r.db.Exec("DELETE FROM telegram_chats WHERE chat_id = ?", chatID)
+	if err != nil {
+		return fmt.Errorf("failed to delete chat folder: %w", err)
+	}
+	return nil
+}
@@ -18,8 +18,8 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 // Create creates a new user
 func (r *UserRepository) Create(user *model.User) error {
 	result, err := r.db.Exec(
-		"INSERT INTO users (username, password_hash, is_admin) VALUES (?, ?, ?)",
-		user.Username, user.PasswordHash, user.IsAdmin,
+		"INSERT INTO users (username, password_hash, is_admin, read_only, upload_disabled, encryption_salt) VALUES (?, ?, ?, ?, ?, ?)",
+		user.Username, user.PasswordHash, user.IsAdmin, user.ReadOnly, user.UploadDisabled, user.EncryptionSalt,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
@@ -38,9 +38,9 @@ func (r *UserRepository) Create(user *model.User) error {
 func (r *UserRepository) GetByID(id int64) (*model.User, error) {
 	user := &model.User{}
 	err := r.db.QueryRow(
-		"SELECT id, username, password_hash, is_admin, created_at FROM users WHERE id = ?",
+		"SELECT id, username, password_hash, is_admin, read_only, upload_disabled, encryption_salt, created_at FROM users WHERE id = ?",
 		id,
-	).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.IsAdmin, &user.CreatedAt)
+	).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.IsAdmin, &user.ReadOnly, &user.UploadDisabled, &user.EncryptionSalt, &user.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -54,9 +54,9 @@ func (r *UserRepository) GetByID(id int64) (*model.User, error) {
 func (r *UserRepository) GetByUsername(username string) (*model.User, error) {
 	user := &model.User{}
 	err := r.db.QueryRow(
-		"SELECT id, username, password_hash, is_admin, created_at FROM users WHERE username = ?",
+		"SELECT id, username, password_hash, is_admin, read_only, upload_disabled, encryption_salt, created_at FROM users WHERE username = ?",
 		username,
-	).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.IsAdmin, &user.CreatedAt)
+	).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.IsAdmin, &user.ReadOnly, &user.UploadDisabled, &user.EncryptionSalt, &user.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -69,7 +69,7 @@ func (r *UserRepository) GetByUsername(username string) (*model.User, error) {
 // List retrieves all users
 func (r *UserRepository) List() ([]*model.User, error) {
 	rows, err := r.db.Query(
-		"SELECT id, username, password_hash, is_admin, created_at FROM users ORDER BY created_at DESC",
+		"SELECT id, username, password_hash, is_admin, read_only, upload_disabled, encryption_salt, created_at FROM users ORDER BY created_at DESC",
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list users: %w", err)
@@ -79,7 +79,7 @@ func (r *UserRepository) List() ([]*model.User, error) {
 	var users []*model.User
 	for rows.Next() {
 		user := &model.User{}
-		if err := rows.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.IsAdmin, &user.CreatedAt); err != nil {
+		if err := rows.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.IsAdmin, &user.ReadOnly, &user.UploadDisabled, &user.EncryptionSalt, &user.CreatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
 		users = append(users, user)
@@ -91,8 +91,8 @@ func (r *UserRepository) List() ([]*model.User, error) {
 // Update updates a user
 func (r *UserRepository) Update(user *model.User) error {
 	_, err := r.db.Exec(
-		"UPDATE users SET username = ?, password_hash = ?, is_admin = ? WHERE id = ?",
-		user.Username, user.PasswordHash, user.IsAdmin, user.ID,
+		"UPDATE users SET username = ?, password_hash = ?, is_admin = ?, read_only = ?, upload_disabled = ?, encryption_salt = ? WHERE id = ?",
+		user.Username, user.PasswordHash, user.IsAdmin, user.ReadOnly, user.UploadDisabled, user.EncryptionSalt, user.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/git"
+	"github.com/user/gitnotepad/internal/handler"
+	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/openapi"
+)
+
+// apiDocs documents the API routes for GET /api/openapi.json
+// New routes are listed in the spec without this table; add them here for a summary and their body types
+var apiDocs = map[string]openapi.Operation{
+	// Auth
+	"POST /api/auth/login":          {Summary: "Sign in", Request: handler.LoginRequest{}, Public: true},
+	"POST /api/auth/logout":         {Summary: "Sign out"},
+	"GET /api/auth/me":              {Summary: "Current user"},
+	"POST /api/auth/verify":         {Summary: "Check the current user's password", Request: handler.VerifyRequest{}},
+	"POST /api/auth/token":          {Summary: "Create an API token (shown only once)", Request: handler.CreateTokenRequest{}, Status: http.StatusCreated},
+	"GET /api/auth/tokens":          {Summary: "List your API tokens", Response: []*model.APIToken{}},
+	"DELETE /api/auth/tokens/:id":   {Summary: "Revoke an API token"},
+	"GET /api/auth/sessions":        {Summary: "List signed-in devices"},
+	"DELETE /api/auth/sessions/:id": {Summary: "Sign a device out"},
+	"POST /api/tokens":              {Summary: "Create an API token (shown only once)", Request: handler.CreateTokenRequest{}, Status: http.StatusCreated},
+	"GET /api/tokens":               {Summary: "List your API tokens", Response: []*model.APIToken{}},
+	"DELETE /api/tokens/:id":        {Summary: "Revoke an API token"},
+	"GET /api/config":               {Summary: "Editor settings, base path and version", Public: true},
+
+	// Notes
+	"GET /api/notes":                      {Summary: "List notes (total in X-Total-Count, next page in X-Next-Cursor when paged)", Query: []string{"folder", "tag", "q", "sort", "order", "cursor", "offset", "limit", "include_archived", "fields", "owner"}, Response: []handler.NoteListItem{}},
+	"POST /api/notes":                     {Summary: "Create a note", Request: handler.CreateNoteRequest{}, Response: model.Note{}, Status: http.StatusCreated},
+	"GET /api/notes/:id":                  {Summary: "Get a note", Query: []string{"fields", "owner"}, Response: model.Note{}},
+	"PUT /api/notes/:id":                  {Summary: "Update a note (409 when it was saved elsewhere since base_modified)", Request: handler.UpdateNoteRequest{}, Response: model.Note{}},
+	"DELETE /api/notes/:id":               {Summary: "Move a note to the trash"},
+	"GET /api/notes/:id/raw":              {Summary: "Note content as text/markdown, text/plain or text/asciidoc", Query: []string{"frontmatter"}},
+	"GET /api/notes/:id/export":           {Summary: "Download a note", Query: []string{"format"}},
+	"POST /api/notes/:id/send/email":      {Summary: "Email a note", Request: handler.SendEmailRequest{}},
+	"POST /api/notes/:id/duplicate":       {Summary: "Copy a note", Request: handler.DuplicateNoteRequest{}, Response: model.Note{}, Status: http.StatusCreated},
+	"POST /api/notes/:id/lock":            {Summary: "Claim or renew the edit lock (423 when another session holds it)"},
+	"DELETE /api/notes/:id/lock":          {Summary: "Release the edit lock"},
+	"GET /api/notes/:id/draft":            {Summary: "Unsaved draft of a note"},
+	"PUT /api/notes/:id/draft":            {Summary: "Save a draft", Request: handler.SaveDraftRequest{}},
+	"DELETE /api/notes/:id/draft":         {Summary: "Discard the draft"},
+	"POST /api/notes/:id/archive":         {Summary: "Archive a note"},
+	"POST /api/notes/:id/unarchive":       {Summary: "Unarchive a note"},
+	"POST /api/notes/:id/decrypt":         {Summary: "Store an encrypted note as plain text"},
+	"GET /api/notes/:id/backlinks":        {Summary: "Notes linking to a note"},
+	"GET /api/notes/recent":               {Summary: "Recently modified or viewed notes", Query: []string{"by", "limit", "fields"}, Response: []handler.RecentNote{}},
+	"GET /api/notes/bulk":                 {Summary: "Several notes in one response", Query: []string{"ids", "fields"}},
+	"GET /api/notes/duplicates":           {Summary: "Notes grouped by identical title", Response: []handler.DuplicateGroup{}},
+	"POST /api/notes/merge":               {Summary: "Merge notes into a target note", Request: handler.MergeNotesRequest{}},
+	"GET /api/calendar":                   {Summary: "Notes by created date", Query: []string{"from", "to", "due"}},
+	"GET /api/graph":                      {Summary: "Note graph from links, attachments and tags", Query: []string{"types"}},
+	"GET /api/sync":                       {Summary: "Notes created, updated and deleted since a cursor (410 for an unknown cursor)", Query: []string{"since", "fields"}},
+	"POST /api/sync":                      {Summary: "Replay writes made offline", Request: handler.SyncPushRequest{}},
+	"GET /api/offline":                    {Summary: "Offline bundle of all notes", Query: []string{"limit"}},
+	"GET /api/journal/today":              {Summary: "Today's journal note (404 if not created yet)", Response: model.Note{}},
+	"POST /api/journal/today":             {Summary: "Today's journal note (201 when created)", Response: model.Note{}},
+	"GET /api/tags":                       {Summary: "List tags", Response: []string{}},
+	"POST /api/clip":                      {Summary: "Save a web clip as a note", Request: handler.ClipRequest{}, Response: model.Note{}, Status: http.StatusCreated},
+	"GET /api/maintenance":                {Summary: "Maintenance mode status", Response: middleware.MaintenanceStatus{}},
+	"GET /api/notes/:id/history":          {Summary: "Version history of a note", Response: []git.Commit{}},
+	"GET /api/notes/:id/version/:commit":  {Summary: "A note at a version"},
+	"POST /api/notes/:id/restore/:commit": {Summary: "Restore a note to a version", Response: model.Note{}},
+	"GET /api/notes/export":               {Summary: "Download all notes as a ZIP"},
+	"POST /api/notes/export":              {Summary: "Download selected notes as a ZIP", Request: handler.ExportSelectedRequest{}},
+	"POST /api/notes/import":              {Summary: "Import notes from a ZIP export", Upload: "file"},
+	"POST /api/notes/import/keep":         {Summary: "Import a Google Keep Takeout archive", Upload: "file"},
+	"POST /api/notes/import/markdown":     {Summary: "Import Markdown files", Upload: "file"},
+	"POST /api/notes/import/evernote":     {Summary: "Import Evernote .enex files", Upload: "files"},
+	"DELETE /api/notes":                   {Summary: "Delete all notes"},
+
+	// Note attachments
+	"GET /api/notes/:id/attachments":              {Summary: "Attachments of a note", Response: []model.Attachment{}},
+	"POST /api/notes/:id/attachments":             {Summary: "Attach an uploaded file to a note", Request: handler.AttachFileRequest{}, Response: model.Attachment{}, Status: http.StatusCreated},
+	"DELETE /api/notes/:id/attachments/:filename": {Summary: "Detach a file from a note", Query: []string{"delete_file"}},
+
+	// Trash
+	"GET /api/trash":              {Summary: "Deleted notes and folders, newest first", Response: []handler.TrashItem{}},
+	"DELETE /api/trash":           {Summary: "Empty the trash"},
+	"POST /api/trash/:id/restore": {Summary: "Restore a trash entry (409 when its location is taken)"},
+	"DELETE /api/trash/:id":       {Summary: "Delete a trash entry permanently"},
+
+	// Folders
+	"GET /api/folders":          {Summary: "List folders", Response: []handler.Folder{}},
+	"POST /api/folders":         {Summary: "Create a folder", Request: handler.CreateFolderRequest{}, Response: handler.Folder{}, Status: http.StatusCreated},
+	"PUT /api/folders":          {Summary: "Rename or move a folder", Request: handler.RenameFolderRequest{}},
+	"DELETE /api/folders":       {Summary: "Delete a folder", Query: []string{"path", "recursive", "move_to", "confirm"}},
+	"GET /api/folder-icons":     {Summary: "Folder icons", Response: map[string]string{}, Public: true},
+	"POST /api/folder-icons":    {Summary: "Set a folder icon", Request: handler.SetFolderIconRequest{}},
+	"DELETE /api/folder-icons":  {Summary: "Remove a folder icon", Query: []string{"folder_path"}},
+	"GET /api/folder-order":     {Summary: "Folder order", Response: handler.FolderOrderMap{}, Public: true},
+	"PUT /api/folder-order":     {Summary: "Set the order of a folder's subfolders", Request: handler.SetFolderOrderRequest{}},
+	"PUT /api/folder-order/all": {Summary: "Replace the folder order", Request: handler.SaveAllRequest{}},
+	"DELETE /api/folder-order":  {Summary: "Reset a folder's order", Query: []string{"parent_path"}},
+	"GET /api/note-sort":        {Summary: "Note sort per folder", Response: handler.NoteSortMap{}, Public: true},
+	"PUT /api/note-sort":        {Summary: "Set a folder's note sort", Request: handler.SetNoteSortRequest{}},
+	"DELETE /api/note-sort":     {Summary: "Reset a folder's note sort", Query: []string{"folder_path"}},
+	"GET /api/folder-meta":      {Summary: "Folder metadata", Response: map[string]handler.FolderMeta{}, Public: true},
+	"PUT /api/folder-meta":      {Summary: "Set folder metadata", Request: handler.SetFolderMetaRequest{}},
+	"DELETE /api/folder-meta":   {Summary: "Delete folder metadata", Query: []string{"folder_path"}},
+
+	// Sharing
+	"GET /api/folder-shares":          {Summary: "Folders you shared", Response: []*model.FolderShare{}},
+	"GET /api/folder-shares/incoming": {Summary: "Folders shared with you", Response: []*model.FolderShare{}},
+	"POST /api/folder-shares":         {Summary: "Share a folder", Request: handler.CreateShareRequest{}, Response: model.FolderShare{}},
+	"DELETE /api/folder-shares/:id":   {Summary: "Remove a folder share"},
+	"POST /api/notes/:id/share":       {Summary: "Share a note", Request: handler.ShareNoteRequest{}, Response: model.NoteShare{}},
+	"GET /api/notes/:id/shares":       {Summary: "Users a note is shared with", Response: []*model.NoteShare{}},
+	"DELETE /api/note-shares/:id":     {Summary: "Remove a note share"},
+	"GET /api/shared-with-me":         {Summary: "Notes and folders shared with you"},
+
+	// Git mirror
+	"GET /api/git/mirror":       {Summary: "Git mirror settings and last push", Response: handler.MirrorResponse{}},
+	"PUT /api/git/mirror":       {Summary: "Set the git mirror", Request: handler.SetMirrorRequest{}, Response: handler.MirrorResponse{}},
+	"DELETE /api/git/mirror":    {Summary: "Remove the git mirror"},
+	"POST /api/git/mirror/push": {Summary: "Push to the git mirror now", Response: handler.MirrorResponse{}},
+
+	// Settings
+	"GET /api/preferences":      {Summary: "Your preferences", Response: model.Preferences{}},
+	"PUT /api/preferences":      {Summary: "Update preferences", Request: handler.UpdatePreferencesRequest{}, Response: model.Preferences{}},
+	"GET /api/settings/export":  {Summary: "Download UI settings", Response: handler.UISettings{}},
+	"POST /api/settings/import": {Summary: "Restore UI settings (JSON body or multipart file)", Request: handler.UISettings{}},
+
+	// Short links
+	"POST /api/notes/:id/shortlink":   {Summary: "Create or update a note's short link", Request: handler.GenerateRequest{}},
+	"GET /api/notes/:id/shortlink":    {Summary: "A note's short link"},
+	"DELETE /api/notes/:id/shortlink": {Summary: "Delete a note's short link"},
+	"GET /api/shortlinks":             {Summary: "Your short links", Response: []handler.ShortLinkListItem{}},
+	"PUT /api/shortlinks/:code":       {Summary: "Update a short link", Request: handler.UpdateRequest{}},
+	"DELETE /api/shortlinks/:code":    {Summary: "Delete a short link"},
+	"GET /api/shortlinks/:code/views": {Summary: "Daily views of a short link", Query: []string{"days"}},
+	"POST /api/folder-shortlinks":     {Summary: "Create or update a folder link", Request: handler.FolderGenerateRequest{}},
+	"GET /api/folder-shortlinks":      {Summary: "A folder's link", Query: []string{"path"}},
+	"DELETE /api/folder-shortlinks":   {Summary: "Delete a folder's link", Query: []string{"path"}},
+
+	// Public links
+	"GET /api/public/note/:code":                       {Summary: "Note of a public link", Public: true},
+	"POST /api/public/note/:code/unlock":               {Summary: "Check the password of a public link", Request: handler.UnlockRequest{}, Public: true},
+	"GET /api/public/note/:code/attachments/:filename": {Summary: "Attachment of a public note", Query: []string{"download"}, Public: true},
+	"GET /api/public/folder/:code":                     {Summary: "Notes of a public folder link", Public: true},
+	"GET /api/public/folder/:code/note/:noteId":        {Summary: "A note of a public folder link", Public: true},
+
+	// Files
+	"POST /api/images":                           {Summary: "Upload an image", Query: []string{"dir"}, Upload: "image"},
+	"DELETE /api/images/:filename":               {Summary: "Delete an image"},
+	"POST /api/files":                            {Summary: "Upload a file", Query: []string{"dir"}, Upload: "file"},
+	"POST /api/files/fetch":                      {Summary: "Attach a file from a URL", Query: []string{"dir"}, Request: handler.FetchFileRequest{}},
+	"GET /api/files":                             {Summary: "Browse the files area", Query: []string{"folder", "q"}},
+	"DELETE /api/files/:filename":                {Summary: "Delete a file"},
+	"PUT /api/files/:filename/name":              {Summary: "Rename an attachment", Request: handler.RenameAttachmentRequest{}},
+	"PUT /api/files/:filename/folder":            {Summary: "Move a file to a folder of the files area", Request: handler.MoveFileRequest{}},
+	"POST /api/files/:filename/versions":         {Summary: "Upload a new version of a file", Upload: "file"},
+	"GET /api/files/:filename/versions":          {Summary: "Prior versions of a file"},
+	"GET /api/files/:filename/versions/:version": {Summary: "Download a prior version"},
+	"PUT /api/file-folders":                      {Summary: "Rename or move a folder of the files area", Request: handler.RenameFolderRequest{}},
+
+	// Stats
+	"GET /api/stats":            {Summary: "Your storage usage", Response: handler.UsageStats{}},
+	"GET /api/stats/encryption": {Summary: "Encryption status of your notes"},
+
+	// Admin
+	"GET /api/admin/users":                             {Summary: "List users"},
+	"POST /api/admin/users":                            {Summary: "Create a user", Request: handler.CreateUserRequest{}},
+	"PUT /api/admin/users/:id":                         {Summary: "Update a user", Request: handler.UpdateUserRequest{}},
+	"DELETE /api/admin/users/:id":                      {Summary: "Delete a user"},
+	"PUT /api/admin/users/:id/password":                {Summary: "Set a user's password", Request: handler.UpdatePasswordRequest{}},
+	"PUT /api/admin/users/:id/username":                {Summary: "Rename a user", Request: handler.RenameUsernameRequest{}},
+	"GET /api/admin/users/:id/usage":                   {Summary: "A user's storage usage", Response: handler.UserUsage{}},
+	"GET /api/admin/users/:id/folder-rules":            {Summary: "A user's folder rules", Response: []*model.FolderRule{}},
+	"PUT /api/admin/users/:id/folder-rules":            {Summary: "Set a folder rule", Request: handler.SetFolderRuleRequest{}, Response: model.FolderRule{}},
+	"DELETE /api/admin/users/:id/folder-rules/:ruleId": {Summary: "Delete a folder rule"},
+	"GET /api/admin/activity":                          {Summary: "Activity of all users", Response: []handler.ActivityFeedItem{}},
+	"GET /api/admin/stats":                             {Summary: "Server statistics"},
+	"POST /api/admin/backup":                           {Summary: "Run an off-site backup now"},
+	"GET /api/admin/shortlinks":                        {Summary: "All short links", Response: []handler.ShortLinkListItem{}},
+	"POST /api/admin/shortlinks/cleanup":               {Summary: "Remove expired short links now"},
+	"PUT /api/admin/maintenance":                       {Summary: "Turn maintenance mode on or off", Request: handler.SetMaintenanceRequest{}, Response: middleware.MaintenanceStatus{}},
+	"POST /api/admin/encryption/rotate":                {Summary: "Re-encrypt a user's notes with a new salt or password", Request: handler.RotateEncryptionRequest{}},
+
+	"GET /api/openapi.json": {Summary: "This document", Public: true},
+}
+
+// openAPIHandler serves the OpenAPI document, built on the first request when all routes are registered
+func (s *Server) openAPIHandler() gin.HandlerFunc {
+	var (
+		once sync.Once
+		doc  *openapi.Document
+	)
+	return func(c *gin.Context) {
+		once.Do(func() {
+			doc = openapi.Build(openapi.Info{
+				Title:       "GitNotepad API",
+				Version:     appVersion.Version,
+				Description: "Sign in with the session cookie from POST /auth/login or send an API token as a Bearer token. Add ?owner=<username> to note, folder and file routes to work in a folder or note shared with you.",
+			}, s.config.Server.BasePath, s.config.Server.BasePath+"/api/"+APIVersion, s.router.Routes(), apiDocs, s.config.Auth.Enabled, middleware.SessionCookieName)
+		})
+		c.JSON(http.StatusOK, doc)
+	}
+}
@@ -1,23 +1,39 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"html/template"
 	"io/fs"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
+	"github.com/user/gitnotepad/internal/backup"
 	"github.com/user/gitnotepad/internal/config"
 	"github.com/user/gitnotepad/internal/database"
 	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/encryption"
+	"github.com/user/gitnotepad/internal/fetch"
 	"github.com/user/gitnotepad/internal/git"
 	"github.com/user/gitnotepad/internal/handler"
+	"github.com/user/gitnotepad/internal/i18n"
+	"github.com/user/gitnotepad/internal/index"
 	"github.com/user/gitnotepad/internal/middleware"
+	"github.com/user/gitnotepad/internal/mirror"
+	"github.com/user/gitnotepad/internal/notify"
 	"github.com/user/gitnotepad/internal/repository"
 	"github.com/user/gitnotepad/internal/websocket"
 	"github.com/user/gitnotepad/web"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 type Server struct {
@@ -27,6 +43,14 @@ type Server struct {
 	db      *database.DB
 	version string
 	wsHub   *websocket.Hub
+	backup  *backup.Manager
+	events  *notify.Dispatcher // Note events for the Telegram bot
+
+	shortLinks  *handler.ShortLinkHandler // Stopped on shutdown
+	noteIndex   *index.Index              // Its file watcher is closed on shutdown
+	stopPurge   chan struct{}             // Stops the trash purge
+	stopCleanup chan struct{}             // Stops the session cleanup
+	maintenance *middleware.Maintenance
 }
 
 // VersionInfo holds build version information
@@ -98,52 +122,166 @@ func newServer(cfg *config.Config, repo *git.Repository, db *database.DB) (*Serv
 		encoding.Warn("Attachment metadata migration failed: %v", err)
 	}
 
+	// Run migration for Unicode names (NFD folders/titles created on macOS -> NFC)
+	if err := handler.MigrateUnicodeNames(cfg.Storage.Path); err != nil {
+		encoding.Warn("Unicode name migration failed: %v", err)
+	}
+	if err := handler.MigrateUnicodeFolderKeys(db); err != nil {
+		encoding.Warn("Unicode folder key migration failed: %v", err)
+	}
+
+	// Run migration for short links (.shortlinks.json -> short_links table)
+	if err := handler.MigrateShortLinks(db, cfg.Storage.Path); err != nil {
+		encoding.Warn("Short link migration failed: %v", err)
+	}
+
+	// Run migration for stable note UIDs (backfill frontmatter of existing notes)
+	if err := handler.MigrateNoteUIDs(cfg.Storage.Path); err != nil {
+		encoding.Warn("Note UID migration failed: %v", err)
+	}
+
+	// Run migration for the note link graph (record wiki-links of existing notes)
+	if err := handler.MigrateNoteLinks(db, cfg.Storage.Path); err != nil {
+		encoding.Warn("Note link migration failed: %v", err)
+	}
+
+	// Keep session encryption keys across restarts
+	if cfg.Encryption.Enabled && cfg.Encryption.PersistKeys {
+		if err := persistSessionKeys(cfg, db); err != nil {
+			encoding.Warn("Session key persistence disabled: %v", err)
+		}
+	}
+
 	gin.SetMode(gin.ReleaseMode)
-	router := gin.Default()
+	router := gin.New()
+	router.Use(gin.RecoveryWithWriter(encoding.Writer(encoding.LevelError)))
+	if !cfg.Logging.AccessLog.Disabled {
+		router.Use(middleware.AccessLog(cfg.Logging.AccessLog))
+	}
 	router.UseRawPath = true
 	router.UnescapePathValues = true
 
 	// GZip compression for text-based responses (HTML, JS, CSS, JSON)
-	router.Use(gzip.Gzip(gzip.DefaultCompression))
+	if compression := cfg.Server.Compression; !compression.Disabled {
+		level := compression.Level
+		if level < gzip.BestSpeed || level > gzip.BestCompression {
+			encoding.Warn("Invalid server.compression.level %d, using default", level)
+			level = gzip.DefaultCompression
+		}
+		router.Use(gzip.Gzip(level, gzip.WithCustomShouldCompressFn(shouldCompress(compression.ExcludeExtensions))))
+	}
 
 	// Initialize WebSocket hub for real-time updates
 	wsHub := websocket.NewHub()
 	go wsHub.Run()
 
+	// Scheduled off-site backups
+	backupManager := backup.New(cfg, db)
+	backupManager.Start()
+
 	s := &Server{
 		config: cfg,
 		router: router,
 		repo:   repo,
 		db:     db,
 		wsHub:  wsHub,
+		backup: backupManager,
+		events: notify.New(),
 	}
 
 	s.setupRoutes()
 	return s, nil
 }
 
+// persistSessionKeys makes the key store save session keys wrapped with the master key
+// and restores the ones saved before the restart
+func persistSessionKeys(cfg *config.Config, db *database.DB) error {
+	masterKey, err := encryption.LoadMasterKey(cfg.Encryption.MasterKeyFile)
+	if err != nil {
+		return err
+	}
+	ttl := time.Duration(cfg.Encryption.KeyTTL) * time.Hour
+	loaded, err := encryption.GetKeyStore().Persist(repository.NewSessionKeyRepository(db.DB), masterKey, ttl)
+	if err != nil {
+		return err
+	}
+	encoding.Info("Restored %d session encryption keys", loaded)
+	return nil
+}
+
 func (s *Server) setupRoutes() {
 	// Create repositories
 	userRepo := repository.NewUserRepository(s.db.DB)
 	sessionRepo := repository.NewSessionRepository(s.db.DB)
+	shareRepo := repository.NewShareRepository(s.db.DB)
+	auditRepo := repository.NewAuditRepository(s.db.DB)
+	noteLinkRepo := repository.NewNoteLinkRepository(s.db.DB)
+	mirrorRepo := repository.NewMirrorRepository(s.db.DB)
+	prefRepo := repository.NewPreferenceRepository(s.db.DB)
+	tokenRepo := repository.NewAPITokenRepository(s.db.DB)
+	identityRepo := repository.NewOIDCIdentityRepository(s.db.DB)
+	folderRuleRepo := repository.NewFolderRuleRepository(s.db.DB)
+	s.startSessionCleanup(sessionRepo, tokenRepo)
+
+	// Push user repositories to their git mirrors after each commit
+	mirrorService := mirror.New(s.config.Storage.Path, mirrorRepo)
+	git.SetCommitHook(mirrorService.Notify)
 
 	// Create middleware
-	authMiddleware := middleware.NewAuthMiddleware(userRepo, sessionRepo, s.config.Server.BasePath)
+	authMiddleware := middleware.NewAuthMiddleware(userRepo, sessionRepo, tokenRepo, s.config.Server.BasePath, s.config.Auth.Cookie)
+	shareMiddleware := middleware.NewShareMiddleware(userRepo, shareRepo)
+	permissionMiddleware := middleware.NewPermissionMiddleware(folderRuleRepo, s.config.Server.BasePath)
+	localeMiddleware := middleware.NewLocaleMiddleware(prefRepo)
+
+	// Translate API errors into the user's language
+	s.router.Use(localeMiddleware.Localize())
+
+	// Read-only mode for migrations and backups: changes are refused, reads and exports keep working
+	maintenance := middleware.NewMaintenance(s.config.Maintenance.Enabled, s.config.Maintenance.Message)
+	s.maintenance = maintenance
+	for _, route := range []string{
+		"/api/auth/login", "/api/auth/logout", "/api/auth/verify",
+		"/api/notes/export", "/api/notes/:id/send/email", "/api/git/mirror/push",
+		"/api/admin/maintenance", "/api/admin/backup",
+	} {
+		maintenance.Exempt(s.config.Server.BasePath + route)
+	}
+	s.router.Use(maintenance.ReadOnly(s.config.Server.BasePath))
+
+	// Let browser extensions call the API (preflight requests are answered here)
+	if len(s.config.Auth.ExtensionOrigins) > 0 {
+		s.router.Use(middleware.ExtensionCORS(s.config.Auth.ExtensionOrigins, s.config.Server.BasePath))
+	}
 
 	// Create handlers
-	noteHandler := handler.NewNoteHandler(s.repo, s.config, s.wsHub)
-	gitHandler := handler.NewGitHandler(s.repo)
-	authHandler := handler.NewAuthHandler(s.repo, userRepo, sessionRepo, s.config)
-	shortLinkHandler := handler.NewShortLinkHandler(s.repo, s.config, s.config.Server.BasePath)
-	imageHandler := handler.NewImageHandler(s.config.Storage.Path, s.config.Server.BasePath)
-	fileHandler := handler.NewFileHandler(s.config.Storage.Path, s.config.Server.BasePath)
-	adminHandler := handler.NewAdminHandler(userRepo, s.config.Storage.Path)
-	statsHandler := handler.NewStatsHandler(s.config)
+	noteIndex := index.New()
+	s.noteIndex = noteIndex
+	shortLinkHandler := handler.NewShortLinkHandler(s.repo, s.config, s.db, s.config.Server.BasePath, noteIndex)
+	s.shortLinks = shortLinkHandler
+	shortLinkHandler.SetEvents(s.events)
+	s.startTrashPurge()
+	fetcher := fetch.New(s.config.Fetch)
+	noteHandler := handler.NewNoteHandler(s.repo, s.config, s.wsHub, s.db, shortLinkHandler, noteIndex, fetcher)
+	noteHandler.SetEvents(s.events)
+	gitHandler := handler.NewGitHandler(s.repo, noteIndex)
+	authHandler := handler.NewAuthHandler(s.repo, userRepo, sessionRepo, tokenRepo, identityRepo, s.config)
+	imageHandler := handler.NewImageHandler(s.config.Storage.Path, s.config.Server.BasePath, s.config.Upload)
+	fileHandler := handler.NewFileHandler(s.config.Storage.Path, s.config.Server.BasePath, s.config.Storage.AttachmentVersions, fetcher, s.config.Upload)
+	adminHandler := handler.NewAdminHandler(userRepo, shortLinkHandler, auditRepo, noteLinkRepo, folderRuleRepo, s.backup, s.config.Storage.Path)
+	statsHandler := handler.NewStatsHandler(s.config, s.db, prefRepo)
 	folderIconHandler := handler.NewFolderIconHandler(s.db)
 	folderOrderHandler := handler.NewFolderOrderHandler(s.db)
+	noteSortHandler := handler.NewNoteSortHandler(s.db)
+	folderMetaHandler := handler.NewFolderMetaHandler(s.db)
+	shareHandler := handler.NewShareHandler(userRepo, shareRepo, auditRepo, s.config.Storage.Path)
+	mirrorHandler := handler.NewMirrorHandler(mirrorRepo, auditRepo, mirrorService)
+	preferenceHandler := handler.NewPreferenceHandler(prefRepo)
+	settingsHandler := handler.NewSettingsHandler(s.db, prefRepo)
+	maintenanceHandler := handler.NewMaintenanceHandler(maintenance, s.wsHub, auditRepo)
+	encryptionHandler := handler.NewEncryptionHandler(s.config, userRepo, sessionRepo, tokenRepo, auditRepo, s.wsHub)
 
 	// Load embedded templates
-	tmpl := template.Must(template.New("").ParseFS(web.Templates, "templates/*.html"))
+	tmpl := template.Must(template.New("").Funcs(template.FuncMap{"t": i18n.T}).ParseFS(web.Templates, "templates/*.html"))
 	s.router.SetHTMLTemplate(tmpl)
 
 	// Get base path for routing
@@ -176,29 +314,57 @@ func (s *Server) setupRoutes() {
 		staticFileServer.ServeHTTP(c.Writer, c.Request)
 	})
 
+	// Web app manifest and service worker (public, fetched by the browser without credentials)
+	pwaHandler := handler.NewPWAHandler(basePath, staticFS)
+	base.GET("/manifest.webmanifest", pwaHandler.Manifest)
+	base.GET("/sw.js", pwaHandler.ServiceWorker)
+
 	// Login page (public)
 	base.GET("/login", func(c *gin.Context) {
 		c.HTML(200, "login.html", gin.H{
-			"config":   s.config,
-			"basePath": basePath,
+			"config":    s.config,
+			"basePath":  basePath,
+			"lang":      middleware.Language(c),
+			"oidc":      handler.OIDCEnabled(s.config),
+			"oidcError": c.Query("oidc_error"),
 		})
 	})
 
 	// Public API routes
 	base.POST("/api/auth/login", authHandler.Login)
 
+	// Single sign-on through an OpenID Connect provider (auth.oidc)
+	if handler.OIDCEnabled(s.config) {
+		base.GET("/auth/oidc/login", authHandler.OIDCLogin)
+		base.GET("/auth/oidc/callback", authHandler.OIDCCallback)
+	} else if s.config.Auth.OIDC.Enabled && s.config.Encryption.Enabled {
+		encoding.Warn("auth.oidc is ignored: single sign-on is not available with file encryption")
+	}
+
 	// Short link redirect (public)
 	base.GET("/s/:code", shortLinkHandler.Redirect)
 
 	// Public preview page and API (no authentication required)
 	base.GET("/preview/:code", shortLinkHandler.PublicPreview)
 	base.GET("/api/public/note/:code", shortLinkHandler.GetPublicNote)
+	base.POST("/api/public/note/:code/unlock", shortLinkHandler.UnlockPublicNote)
+	base.GET("/api/public/note/:code/attachments/:filename", shortLinkHandler.GetPublicNoteAttachment)
+	base.GET("/print/s/:code", shortLinkHandler.PrintPublic)
 
 	// Public folder preview page and API (no authentication required)
 	base.GET("/folder-preview/:code", shortLinkHandler.FolderPreview)
 	base.GET("/api/public/folder/:code", shortLinkHandler.GetPublicFolder)
 	base.GET("/api/public/folder/:code/note/:noteId", shortLinkHandler.GetPublicFolderNote)
 
+	// Folders published as a blog (public folder links with blog mode)
+	base.GET("/blog/:code", shortLinkHandler.Blog)
+	base.GET("/blog/:code/post/:uid", shortLinkHandler.BlogPost)
+	base.GET("/blog/:code/rss.xml", shortLinkHandler.BlogFeed)
+
+	// Search engine discovery of public links (sitemap served only when seo.indexable is set)
+	base.GET("/sitemap.xml", shortLinkHandler.Sitemap)
+	base.GET("/robots.txt", shortLinkHandler.Robots)
+
 	// Config endpoint (public)
 	base.GET("/api/config", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -208,12 +374,21 @@ func (s *Server) setupRoutes() {
 		})
 	})
 
+	// OpenAPI description of the API (public)
+	base.GET("/api/openapi.json", s.openAPIHandler())
+
 	// Folder icons GET - public with optional auth (to avoid 401 errors in browser console)
 	base.GET("/api/folder-icons", authMiddleware.OptionalAuth(), folderIconHandler.List)
 
 	// Folder order GET - public with optional auth
 	base.GET("/api/folder-order", authMiddleware.OptionalAuth(), folderOrderHandler.Get)
 
+	// Note sort GET - public with optional auth
+	base.GET("/api/note-sort", authMiddleware.OptionalAuth(), noteSortHandler.Get)
+
+	// Folder metadata GET - public with optional auth
+	base.GET("/api/folder-meta", authMiddleware.OptionalAuth(), folderMetaHandler.List)
+
 	// Protected routes (require authentication)
 	if s.config.Auth.Enabled {
 		// Main page - require auth
@@ -230,36 +405,84 @@ func (s *Server) setupRoutes() {
 		base.GET("/popout-preview", authMiddleware.RequireAuth(), func(c *gin.Context) {
 			c.HTML(200, "popout-preview.html", gin.H{
 				"basePath": basePath,
+				"lang":     middleware.Language(c),
 			})
 		})
 
+		// Print-optimized note page - require auth
+		base.GET("/print/:id", authMiddleware.RequireAuth(), shareMiddleware.ResolveOwner(), permissionMiddleware.Enforce(), noteHandler.Print)
+
 		// WebSocket endpoint for real-time updates
 		base.GET("/ws", authMiddleware.RequireAuth(), s.wsHub.HandleWebSocket)
 
 		// Protected API routes
 		api := base.Group("/api")
-		api.Use(authMiddleware.RequireAuth())
+		api.Use(authMiddleware.RequireAuth(), shareMiddleware.ResolveOwner(), permissionMiddleware.Enforce())
 		{
 			// Auth
 			api.POST("/auth/logout", authHandler.Logout)
 			api.GET("/auth/me", authHandler.GetCurrentUser)
 			api.POST("/auth/verify", authHandler.Verify)
+			api.POST("/auth/token", authHandler.CreateToken)
+			api.GET("/auth/tokens", authHandler.ListTokens)
+			api.DELETE("/auth/tokens/:id", authHandler.DeleteToken)
+			api.GET("/auth/sessions", authHandler.ListSessions)
+			api.DELETE("/auth/sessions/:id", authHandler.DeleteSession)
+
+			// Personal access tokens (same tokens, managed from the settings)
+			api.POST("/tokens", authHandler.CreateToken)
+			api.GET("/tokens", authHandler.ListTokens)
+			api.DELETE("/tokens/:id", authHandler.DeleteToken)
 
 			// Notes CRUD
 			api.GET("/notes", noteHandler.List)
+			api.GET("/notes/recent", noteHandler.Recent)
+			api.GET("/notes/bulk", noteHandler.Bulk)
+			api.GET("/calendar", noteHandler.Calendar)
+			api.GET("/graph", noteHandler.Graph)
+			api.GET("/notes/:id/backlinks", noteHandler.Backlinks)
+			api.GET("/sync", noteHandler.Sync)
+			api.POST("/sync", noteHandler.SyncPush)
+			api.GET("/maintenance", maintenanceHandler.Get)
+			api.GET("/offline", noteHandler.Offline)
+			api.GET("/journal/today", noteHandler.JournalToday)
+			api.POST("/journal/today", noteHandler.JournalToday)
+			api.GET("/notes/duplicates", noteHandler.ListDuplicates)
+			api.POST("/notes/merge", noteHandler.MergeNotes)
 			api.GET("/notes/:id", noteHandler.Get)
+			api.GET("/notes/:id/raw", noteHandler.GetRaw)
+			api.POST("/notes/:id/send/email", noteHandler.SendEmail)
 			api.POST("/notes", noteHandler.Create)
 			api.PUT("/notes/:id", noteHandler.Update)
 			api.DELETE("/notes/:id", noteHandler.Delete)
+			api.POST("/notes/:id/duplicate", noteHandler.Duplicate)
+			api.GET("/notes/:id/export", noteHandler.Export)
+			api.POST("/notes/:id/lock", noteHandler.Lock)
+			api.DELETE("/notes/:id/lock", noteHandler.Unlock)
+			api.GET("/notes/:id/draft", noteHandler.GetDraft)
+			api.PUT("/notes/:id/draft", noteHandler.SaveDraft)
+			api.DELETE("/notes/:id/draft", noteHandler.DeleteDraft)
+			api.POST("/notes/:id/archive", noteHandler.Archive)
+			api.POST("/notes/:id/unarchive", noteHandler.Unarchive)
 			api.POST("/notes/:id/decrypt", noteHandler.DecryptNote)
+			api.GET("/notes/:id/attachments", noteHandler.ListAttachments)
+			api.POST("/notes/:id/attachments", noteHandler.AttachFile)
+			api.DELETE("/notes/:id/attachments/:filename", noteHandler.DetachFile) // ?delete_file=true
 
 			// Tags
 			api.GET("/tags", noteHandler.ListTags)
 
+			// Trash
+			api.GET("/trash", noteHandler.ListTrash)
+			api.DELETE("/trash", noteHandler.EmptyTrash)
+			api.POST("/trash/:id/restore", noteHandler.RestoreTrash)
+			api.DELETE("/trash/:id", noteHandler.DeleteTrash)
+
 			// Folders
 			api.GET("/folders", noteHandler.ListFolders)
 			api.POST("/folders", noteHandler.CreateFolder)
-			api.DELETE("/folders/*path", noteHandler.DeleteFolder)
+			api.PUT("/folders", noteHandler.RenameFolder)
+			api.DELETE("/folders", noteHandler.DeleteFolder) // ?path=
 
 			// Folder icons (GET is public with optional auth, POST/DELETE require auth)
 			api.POST("/folder-icons", folderIconHandler.Set)
@@ -269,10 +492,43 @@ func (s *Server) setupRoutes() {
 			api.PUT("/folder-order", folderOrderHandler.Set)
 			api.PUT("/folder-order/all", folderOrderHandler.SaveAll)
 			api.DELETE("/folder-order", folderOrderHandler.Delete)
+			api.PUT("/note-sort", noteSortHandler.Set)
+			api.DELETE("/note-sort", noteSortHandler.Delete)
+
+			// Folder metadata (GET is already registered as public)
+			api.PUT("/folder-meta", folderMetaHandler.Set)
+			api.DELETE("/folder-meta", folderMetaHandler.Delete)
+
+			// Folder sharing between users (?owner= on other routes accesses shared folders)
+			api.GET("/folder-shares", shareHandler.List)
+			api.GET("/folder-shares/incoming", shareHandler.ListIncoming)
+			api.POST("/folder-shares", shareHandler.Create)
+			api.DELETE("/folder-shares/:id", shareHandler.Delete)
+
+			// Single notes shared between users
+			api.POST("/notes/:id/share", noteHandler.ShareNote)
+			api.GET("/notes/:id/shares", noteHandler.ListNoteShares)
+			api.DELETE("/note-shares/:id", noteHandler.DeleteNoteShare)
+			api.GET("/shared-with-me", noteHandler.SharedWithMe)
 
 			// Git history
 			api.GET("/notes/:id/history", gitHandler.History)
 			api.GET("/notes/:id/version/:commit", gitHandler.Version)
+			api.POST("/notes/:id/restore/:commit", noteHandler.RestoreVersion)
+
+			// Git mirror (push to the user's own remote after each commit)
+			api.GET("/git/mirror", mirrorHandler.Get)
+			api.PUT("/git/mirror", mirrorHandler.Set)
+			api.DELETE("/git/mirror", mirrorHandler.Delete)
+			api.POST("/git/mirror/push", mirrorHandler.Push)
+
+			// Per-user preferences (language)
+			api.GET("/preferences", preferenceHandler.Get)
+			api.PUT("/preferences", preferenceHandler.Update)
+
+			// UI settings export/import (folder icons, folder order, note sort, folder metadata, preferences)
+			api.GET("/settings/export", settingsHandler.Export)
+			api.POST("/settings/import", settingsHandler.Import)
 
 			// Short links
 			api.POST("/notes/:id/shortlink", shortLinkHandler.Generate)
@@ -283,8 +539,9 @@ func (s *Server) setupRoutes() {
 			api.GET("/shortlinks", shortLinkHandler.List)
 			api.PUT("/shortlinks/:code", shortLinkHandler.UpdateByCode)
 			api.DELETE("/shortlinks/:code", shortLinkHandler.DeleteByCode)
+			api.GET("/shortlinks/:code/views", shortLinkHandler.GetViews)
 
-			// Folder short links (use /folder-shortlinks to avoid conflict with /folders/*path)
+			// Folder short links
 			api.POST("/folder-shortlinks", shortLinkHandler.GenerateFolderLink)
 			api.GET("/folder-shortlinks", shortLinkHandler.GetFolderLink)
 			api.DELETE("/folder-shortlinks", shortLinkHandler.DeleteFolderLink)
@@ -295,12 +552,26 @@ func (s *Server) setupRoutes() {
 
 			// File routes
 			api.POST("/files", fileHandler.Upload)
+			api.POST("/files/fetch", fileHandler.Fetch)
 			api.DELETE("/files/:filename", fileHandler.Delete)
+			api.PUT("/files/:filename/name", noteHandler.RenameAttachment)
+			api.POST("/files/:filename/versions", fileHandler.UploadVersion)
+			api.GET("/files/:filename/versions", fileHandler.ListVersions)
+			api.GET("/files/:filename/versions/:version", fileHandler.ServeVersion)
+			api.GET("/files", fileHandler.Browse)
+			api.PUT("/files/:filename/folder", fileHandler.Move)
+			api.PUT("/file-folders", fileHandler.RenameFileFolder)
 
 			// Stats and data management
 			api.GET("/stats", statsHandler.GetStats)
+			api.GET("/stats/encryption", statsHandler.EncryptionStats)
 			api.GET("/notes/export", statsHandler.ExportNotes)
+			api.POST("/notes/export", statsHandler.ExportSelectedNotes)
 			api.POST("/notes/import", statsHandler.ImportNotes)
+			api.POST("/notes/import/keep", noteHandler.ImportKeep)
+			api.POST("/notes/import/markdown", noteHandler.ImportMarkdown)
+			api.POST("/notes/import/evernote", noteHandler.ImportEvernote)
+			api.POST("/clip", noteHandler.Clip)
 			api.DELETE("/notes", statsHandler.DeleteAllNotes)
 		}
 
@@ -311,7 +582,20 @@ func (s *Server) setupRoutes() {
 			admin.GET("/users", adminHandler.ListUsers)
 			admin.POST("/users", adminHandler.CreateUser)
 			admin.DELETE("/users/:id", adminHandler.DeleteUser)
+			admin.PUT("/users/:id", adminHandler.UpdateUser)
 			admin.PUT("/users/:id/password", adminHandler.UpdatePassword)
+			admin.PUT("/users/:id/username", adminHandler.RenameUsername)
+			admin.GET("/users/:id/usage", adminHandler.GetUserUsage)
+			admin.GET("/users/:id/folder-rules", adminHandler.ListFolderRules)
+			admin.PUT("/users/:id/folder-rules", adminHandler.SetFolderRule)
+			admin.DELETE("/users/:id/folder-rules/:ruleId", adminHandler.DeleteFolderRule)
+			admin.GET("/activity", adminHandler.Activity)
+			admin.GET("/stats", adminHandler.Stats)
+			admin.POST("/backup", adminHandler.RunBackup)
+			admin.GET("/shortlinks", adminHandler.ListShortLinks)
+			admin.POST("/shortlinks/cleanup", adminHandler.CleanupShortLinks)
+			admin.PUT("/maintenance", maintenanceHandler.Set)
+			admin.POST("/encryption/rotate", encryptionHandler.Rotate)
 		}
 	} else {
 		// Auth disabled - no authentication required
@@ -326,9 +610,13 @@ func (s *Server) setupRoutes() {
 		base.GET("/popout-preview", func(c *gin.Context) {
 			c.HTML(200, "popout-preview.html", gin.H{
 				"basePath": basePath,
+				"lang":     middleware.Language(c),
 			})
 		})
 
+		// Print-optimized note page
+		base.GET("/print/:id", noteHandler.Print)
+
 		// WebSocket endpoint for real-time updates (no auth - use default username)
 		base.GET("/ws", func(c *gin.Context) {
 			c.Set("username", "default")
@@ -339,19 +627,53 @@ func (s *Server) setupRoutes() {
 		{
 			// Notes CRUD
 			api.GET("/notes", noteHandler.List)
+			api.GET("/notes/recent", noteHandler.Recent)
+			api.GET("/notes/bulk", noteHandler.Bulk)
+			api.GET("/calendar", noteHandler.Calendar)
+			api.GET("/graph", noteHandler.Graph)
+			api.GET("/notes/:id/backlinks", noteHandler.Backlinks)
+			api.GET("/sync", noteHandler.Sync)
+			api.POST("/sync", noteHandler.SyncPush)
+			api.GET("/maintenance", maintenanceHandler.Get)
+			api.GET("/offline", noteHandler.Offline)
+			api.GET("/journal/today", noteHandler.JournalToday)
+			api.POST("/journal/today", noteHandler.JournalToday)
+			api.GET("/notes/duplicates", noteHandler.ListDuplicates)
+			api.POST("/notes/merge", noteHandler.MergeNotes)
 			api.GET("/notes/:id", noteHandler.Get)
+			api.GET("/notes/:id/raw", noteHandler.GetRaw)
+			api.POST("/notes/:id/send/email", noteHandler.SendEmail)
 			api.POST("/notes", noteHandler.Create)
 			api.PUT("/notes/:id", noteHandler.Update)
 			api.DELETE("/notes/:id", noteHandler.Delete)
+			api.POST("/notes/:id/duplicate", noteHandler.Duplicate)
+			api.GET("/notes/:id/export", noteHandler.Export)
+			api.POST("/notes/:id/lock", noteHandler.Lock)
+			api.DELETE("/notes/:id/lock", noteHandler.Unlock)
+			api.GET("/notes/:id/draft", noteHandler.GetDraft)
+			api.PUT("/notes/:id/draft", noteHandler.SaveDraft)
+			api.DELETE("/notes/:id/draft", noteHandler.DeleteDraft)
+			api.POST("/notes/:id/archive", noteHandler.Archive)
+			api.POST("/notes/:id/unarchive", noteHandler.Unarchive)
 			api.POST("/notes/:id/decrypt", noteHandler.DecryptNote)
+			api.GET("/notes/:id/attachments", noteHandler.ListAttachments)
+			api.POST("/notes/:id/attachments", noteHandler.AttachFile)
+			api.DELETE("/notes/:id/attachments/:filename", noteHandler.DetachFile) // ?delete_file=true
 
 			// Tags
 			api.GET("/tags", noteHandler.ListTags)
 
+			// Trash
+			api.GET("/trash", noteHandler.ListTrash)
+			api.DELETE("/trash", noteHandler.EmptyTrash)
+			api.POST("/trash/:id/restore", noteHandler.RestoreTrash)
+			api.DELETE("/trash/:id", noteHandler.DeleteTrash)
+
 			// Folders
 			api.GET("/folders", noteHandler.ListFolders)
 			api.POST("/folders", noteHandler.CreateFolder)
-			api.DELETE("/folders/*path", noteHandler.DeleteFolder)
+			api.PUT("/folders", noteHandler.RenameFolder)
+			api.DELETE("/folders", noteHandler.DeleteFolder) // ?path=
 
 			// Folder icons (GET is already registered as public)
 			api.POST("/folder-icons", folderIconHandler.Set)
@@ -361,10 +683,17 @@ func (s *Server) setupRoutes() {
 			api.PUT("/folder-order", folderOrderHandler.Set)
 			api.PUT("/folder-order/all", folderOrderHandler.SaveAll)
 			api.DELETE("/folder-order", folderOrderHandler.Delete)
+			api.PUT("/note-sort", noteSortHandler.Set)
+			api.DELETE("/note-sort", noteSortHandler.Delete)
+
+			// Folder metadata (GET is already registered as public)
+			api.PUT("/folder-meta", folderMetaHandler.Set)
+			api.DELETE("/folder-meta", folderMetaHandler.Delete)
 
 			// Git history
 			api.GET("/notes/:id/history", gitHandler.History)
 			api.GET("/notes/:id/version/:commit", gitHandler.Version)
+			api.POST("/notes/:id/restore/:commit", noteHandler.RestoreVersion)
 
 			// Auth (legacy)
 			api.POST("/auth/verify", authHandler.Verify)
@@ -378,8 +707,9 @@ func (s *Server) setupRoutes() {
 			api.GET("/shortlinks", shortLinkHandler.List)
 			api.PUT("/shortlinks/:code", shortLinkHandler.UpdateByCode)
 			api.DELETE("/shortlinks/:code", shortLinkHandler.DeleteByCode)
+			api.GET("/shortlinks/:code/views", shortLinkHandler.GetViews)
 
-			// Folder short links (use /folder-shortlinks to avoid conflict with /folders/*path)
+			// Folder short links
 			api.POST("/folder-shortlinks", shortLinkHandler.GenerateFolderLink)
 			api.GET("/folder-shortlinks", shortLinkHandler.GetFolderLink)
 			api.DELETE("/folder-shortlinks", shortLinkHandler.DeleteFolderLink)
@@ -390,12 +720,26 @@ func (s *Server) setupRoutes() {
 
 			// File routes
 			api.POST("/files", fileHandler.Upload)
+			api.POST("/files/fetch", fileHandler.Fetch)
 			api.DELETE("/files/:filename", fileHandler.Delete)
+			api.PUT("/files/:filename/name", noteHandler.RenameAttachment)
+			api.POST("/files/:filename/versions", fileHandler.UploadVersion)
+			api.GET("/files/:filename/versions", fileHandler.ListVersions)
+			api.GET("/files/:filename/versions/:version", fileHandler.ServeVersion)
+			api.GET("/files", fileHandler.Browse)
+			api.PUT("/files/:filename/folder", fileHandler.Move)
+			api.PUT("/file-folders", fileHandler.RenameFileFolder)
 
 			// Stats and data management
 			api.GET("/stats", statsHandler.GetStats)
+			api.GET("/stats/encryption", statsHandler.EncryptionStats)
 			api.GET("/notes/export", statsHandler.ExportNotes)
+			api.POST("/notes/export", statsHandler.ExportSelectedNotes)
 			api.POST("/notes/import", statsHandler.ImportNotes)
+			api.POST("/notes/import/keep", noteHandler.ImportKeep)
+			api.POST("/notes/import/markdown", noteHandler.ImportMarkdown)
+			api.POST("/notes/import/evernote", noteHandler.ImportEvernote)
+			api.POST("/clip", noteHandler.Clip)
 			api.DELETE("/notes", statsHandler.DeleteAllNotes)
 		}
 	}
@@ -409,10 +753,256 @@ func (s *Server) setupRoutes() {
 	base.GET("/images/:filename", imageHandler.ServeLegacy)
 }
 
+// compressedExtensions are files not worth gzipping (already compressed images, media, archives and documents)
+var compressedExtensions = []string{
+	".png", ".gif", ".jpeg", ".jpg", ".webp", ".avif", ".heic",
+	".mp4", ".m4v", ".mov", ".webm", ".ogv", ".mp3", ".m4a", ".m4b", ".aac", ".weba", ".ogg", ".oga", ".opus", ".flac",
+	".zip", ".gz", ".tgz", ".bz2", ".xz", ".zst", ".7z", ".rar", ".pdf",
+	".docx", ".xlsx", ".pptx", ".odt", ".ods", ".odp", ".epub", ".woff", ".woff2",
+}
+
+// shouldCompress decides whether a response is gzipped, skipping compressedExtensions and the extra extensions
+// Range requests are never compressed: the byte ranges of a 206 response refer to the file itself
+func shouldCompress(extra []string) func(c *gin.Context) bool {
+	excluded := gzip.NewExcludedExtensions(compressedExtensions)
+	for _, ext := range extra {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext != "" && !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		excluded[ext] = struct{}{}
+	}
+	return func(c *gin.Context) bool {
+		req := c.Request
+		if !strings.Contains(req.Header.Get("Accept-Encoding"), "gzip") ||
+			strings.Contains(req.Header.Get("Connection"), "Upgrade") ||
+			req.Header.Get("Range") != "" {
+			return false
+		}
+		// Attachment versions are served under .../versions/<n>: the file type is in the :filename parameter
+		name := req.URL.Path
+		if strings.HasSuffix(c.FullPath(), "/versions/:version") {
+			name = c.Param("filename")
+		}
+		return !excluded.Contains(strings.ToLower(filepath.Ext(name)))
+	}
+}
+
+// APIVersion is the version of the JSON API served under /api/v1
+// The unversioned /api paths are aliases of it; a breaking change gets a new version, leaving v1 clients working
+const APIVersion = "v1"
+
+// Handler returns the HTTP handler of the server: the router, with /api/v1/... answered by the /api/... routes
+func (s *Server) Handler() http.Handler {
+	versioned := s.config.Server.BasePath + "/api/" + APIVersion + "/"
+	unversioned := s.config.Server.BasePath + "/api/"
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, versioned) {
+			r.URL.Path = unversioned + strings.TrimPrefix(r.URL.Path, versioned)
+			if strings.HasPrefix(r.URL.RawPath, versioned) {
+				r.URL.RawPath = unversioned + strings.TrimPrefix(r.URL.RawPath, versioned)
+			} else {
+				r.URL.RawPath = ""
+			}
+			w.Header().Set("X-API-Version", APIVersion)
+		}
+		rewriteDeprecatedRoutes(w, r, unversioned)
+		s.router.ServeHTTP(w, r)
+	})
+}
+
+// rewriteDeprecatedRoutes answers the API paths replaced by query parameters with their new routes,
+// so older clients keep working without registering wildcards that would collide with newer routes
+func rewriteDeprecatedRoutes(w http.ResponseWriter, r *http.Request, api string) {
+	// DELETE /api/folders/*path -> DELETE /api/folders?path=
+	folders := api + "folders/"
+	if r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, folders) {
+		query := r.URL.Query()
+		query.Set("path", strings.TrimPrefix(r.URL.Path, folders))
+		r.URL.Path = strings.TrimSuffix(folders, "/")
+		r.URL.RawPath = ""
+		r.URL.RawQuery = query.Encode()
+		w.Header().Set("Deprecation", "true")
+	}
+}
+
+// shutdownTimeout bounds the wait for in-flight requests (the daemon kills the process after 3 seconds)
+const shutdownTimeout = 2 * time.Second
+
+// Run serves until the listener fails or SIGINT/SIGTERM is received, then shuts down gracefully:
+// in-flight requests are finished and background jobs are stopped before returning
 func (s *Server) Run() error {
 	addr := fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Server.Port)
-	encoding.Info("Server starting at http://%s (log level: %s)", addr, encoding.GetLevel())
-	return s.router.Run(addr)
+	httpServer := &http.Server{Addr: addr, Handler: s.Handler()}
+	tlsCfg := s.config.Server.TLS
+
+	// With ACME the certificates come from the manager; otherwise from cert_file/key_file
+	var certManager *autocert.Manager
+	certFile, keyFile := tlsCfg.CertFile, tlsCfg.KeyFile
+	if tlsCfg.Enabled {
+		if tlsCfg.ACME {
+			if len(tlsCfg.Domains) == 0 {
+				return fmt.Errorf("server.tls.domains is required with server.tls.acme")
+			}
+			certManager = &autocert.Manager{
+				Prompt:     autocert.AcceptTOS,
+				HostPolicy: autocert.HostWhitelist(tlsCfg.Domains...),
+				Cache:      autocert.DirCache(tlsCfg.CacheDir),
+				Email:      tlsCfg.Email,
+			}
+			httpServer.TLSConfig = certManager.TLSConfig()
+			certFile, keyFile = "", ""
+		} else if certFile == "" || keyFile == "" {
+			return fmt.Errorf("server.tls.cert_file and server.tls.key_file are required (or enable server.tls.acme)")
+		}
+		encoding.Info("Server starting at https://%s (log level: %s)", addr, encoding.GetLevel())
+	} else {
+		encoding.Info("Server starting at http://%s (log level: %s)", addr, encoding.GetLevel())
+	}
+
+	serveErr := make(chan error, 2)
+	go func() {
+		if tlsCfg.Enabled {
+			serveErr <- httpServer.ListenAndServeTLS(certFile, keyFile)
+		} else {
+			serveErr <- httpServer.ListenAndServe()
+		}
+	}()
+
+	// The plain HTTP port redirects to HTTPS and answers the ACME HTTP-01 challenges
+	var redirectServer *http.Server
+	if tlsCfg.Enabled && tlsCfg.HTTPPort > 0 {
+		var redirect http.Handler = http.HandlerFunc(s.redirectToHTTPS)
+		if certManager != nil {
+			redirect = certManager.HTTPHandler(redirect)
+		}
+		redirectServer = &http.Server{
+			Addr:    fmt.Sprintf("%s:%d", s.config.Server.Host, tlsCfg.HTTPPort),
+			Handler: redirect,
+		}
+		encoding.Info("Redirecting http://%s to HTTPS", redirectServer.Addr)
+		go func() {
+			serveErr <- redirectServer.ListenAndServe()
+		}()
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	select {
+	case err := <-serveErr:
+		s.stopBackground()
+		return err
+	case sig := <-quit:
+		encoding.Info("Shutting down (%v)", sig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(ctx); err != nil {
+		encoding.Warn("Server shutdown: %v", err)
+	}
+	if redirectServer != nil {
+		redirectServer.Shutdown(ctx)
+	}
+	s.stopBackground()
+	return nil
+}
+
+// redirectToHTTPS sends plain HTTP requests to the same URL on the HTTPS port
+func (s *Server) redirectToHTTPS(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if s.config.Server.Port != 443 {
+		host = net.JoinHostPort(host, strconv.Itoa(s.config.Server.Port))
+	}
+	http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+}
+
+// trashPurgeInterval is how often trash entries past trash.retention_days are removed
+const trashPurgeInterval = time.Hour
+
+// startTrashPurge removes expired trash entries now and then every trashPurgeInterval (call after s.maintenance is set)
+func (s *Server) startTrashPurge() {
+	if s.config.Trash.RetentionDays <= 0 {
+		return
+	}
+	s.stopPurge = make(chan struct{})
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+		for {
+			// Files are left alone in maintenance mode; the purge catches up afterwards
+			if !s.maintenance.Status().Enabled {
+				if removed := handler.PurgeExpiredTrash(s.config.Storage.Path, s.config.Trash.RetentionDays); removed > 0 {
+					encoding.Info("Purged %d expired trash entries", removed)
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}(s.stopPurge)
+}
+
+// startSessionCleanup deletes expired sessions and API tokens, and drops the encryption keys
+// of sessions and tokens that no longer exist, now and then every auth.cleanup_interval hours
+func (s *Server) startSessionCleanup(sessionRepo *repository.SessionRepository, tokenRepo *repository.APITokenRepository) {
+	s.stopCleanup = make(chan struct{})
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(time.Duration(s.config.Auth.CleanupInterval) * time.Hour)
+		defer ticker.Stop()
+		for {
+			if err := sessionRepo.DeleteExpired(); err != nil {
+				encoding.Warn("Session cleanup: %v", err)
+			}
+			if err := tokenRepo.DeleteExpired(); err != nil {
+				encoding.Warn("Session cleanup: %v", err)
+			}
+
+			// Keys are only dropped when both lists are known, so a database error never signs everyone out
+			sessions, err := sessionRepo.ActiveTokens()
+			tokens, tokenErr := tokenRepo.ActiveHashes()
+			if err == nil && tokenErr == nil {
+				valid := make(map[string]bool, len(sessions)+len(tokens))
+				for _, token := range append(sessions, tokens...) {
+					valid[token] = true
+				}
+				if removed := encryption.GetKeyStore().Cleanup(valid); removed > 0 {
+					encoding.Info("Removed %d orphaned encryption keys", removed)
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}(s.stopCleanup)
+}
+
+// stopBackground stops the schedulers that write files and the note index watcher
+func (s *Server) stopBackground() {
+	if s.shortLinks != nil {
+		s.shortLinks.Stop()
+	}
+	if s.stopPurge != nil {
+		close(s.stopPurge)
+		s.stopPurge = nil
+	}
+	if s.stopCleanup != nil {
+		close(s.stopCleanup)
+		s.stopCleanup = nil
+	}
+	if s.noteIndex != nil {
+		s.noteIndex.Close()
+	}
 }
 
 func (s *Server) Close() error {
@@ -426,3 +1016,28 @@ func (s *Server) Close() error {
 func (s *Server) GetHub() *websocket.Hub {
 	return s.wsHub
 }
+
+// GetMaintenance returns the maintenance mode switch for external use (e.g., Telegram bot)
+func (s *Server) GetMaintenance() *middleware.Maintenance {
+	return s.maintenance
+}
+
+// GetNoteIndex returns the note index for external use (e.g., Telegram bot)
+func (s *Server) GetNoteIndex() *index.Index {
+	return s.noteIndex
+}
+
+// GetShortLinks returns the short link handler for external use (e.g., Telegram bot)
+func (s *Server) GetShortLinks() *handler.ShortLinkHandler {
+	return s.shortLinks
+}
+
+// GetEvents returns the note events for external use (e.g., Telegram bot)
+func (s *Server) GetEvents() *notify.Dispatcher {
+	return s.events
+}
+
+// GetDB returns the database for external use (e.g., Telegram bot)
+func (s *Server) GetDB() *database.DB {
+	return s.db
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/user/gitnotepad/internal/config"
+)
+
+// newTestServer boots a server with its storage and database in a temporary directory
+func newTestServer(t *testing.T, authEnabled bool) *Server {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := config.Default()
+	cfg.Auth.Enabled = authEnabled
+	cfg.Storage.Path = filepath.Join(dir, "data")
+	cfg.Database.Path = filepath.Join(dir, "data", "gitnotepad.db")
+	cfg.Server.TLS.CacheDir = filepath.Join(dir, "certs")
+	cfg.Backup.Dir = filepath.Join(dir, "backups")
+	cfg.Logging.AccessLog.Disabled = true
+
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSetupRoutes(t *testing.T) {
+	folderRoutes := []struct{ method, path string }{
+		{http.MethodGet, "/api/folders"},
+		{http.MethodPost, "/api/folders"},
+		{http.MethodPut, "/api/folders"},
+		{http.MethodDelete, "/api/folders"},
+		{http.MethodPost, "/api/folder-shortlinks"},
+		{http.MethodGet, "/api/folder-shortlinks"},
+		{http.MethodDelete, "/api/folder-shortlinks"},
+	}
+
+	for _, authEnabled := range []bool{true, false} {
+		s := newTestServer(t, authEnabled)
+
+		registered := make(map[string]bool)
+		for _, route := range s.router.Routes() {
+			registered[route.Method+" "+route.Path] = true
+		}
+		for _, route := range folderRoutes {
+			if !registered[route.method+" "+route.path] {
+				t.Errorf("auth=%v: %s %s is not registered", authEnabled, route.method, route.path)
+			}
+		}
+	}
+}
+
+func TestDeprecatedFolderDelete(t *testing.T) {
+	s := newTestServer(t, false)
+
+	// A hidden folder is refused by the handler, which tells it apart from a route that doesn't resolve
+	for _, path := range []string{"/api/folders/.hidden", "/api/" + APIVersion + "/folders/.hidden"} {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodDelete, path, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("DELETE %s: status %d, want %d", path, w.Code, http.StatusBadRequest)
+		}
+		if w.Header().Get("Deprecation") != "true" {
+			t.Errorf("DELETE %s: missing Deprecation header", path)
+		}
+	}
+}
+
+func TestMaintenanceBlocksJournalCreation(t *testing.T) {
+	s := newTestServer(t, false)
+	s.maintenance.Set(true, "")
+
+	// GET only looks today's note up, so it keeps working without creating the note
+	for _, tc := range []struct {
+		method string
+		status int
+	}{
+		{http.MethodGet, http.StatusNotFound},
+		{http.MethodPost, http.StatusServiceUnavailable},
+		{http.MethodGet, http.StatusNotFound},
+	} {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, httptest.NewRequest(tc.method, "/api/journal/today", nil))
+		if w.Code != tc.status {
+			t.Errorf("%s /api/journal/today: status %d, want %d", tc.method, w.Code, tc.status)
+		}
+	}
+}
@@ -1,10 +1,16 @@
 package telegram
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"io/fs"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,16 +18,54 @@ import (
 	"github.com/user/gitnotepad/internal/config"
 	"github.com/user/gitnotepad/internal/encoding"
 	"github.com/user/gitnotepad/internal/git"
+	"github.com/user/gitnotepad/internal/handler"
+	"github.com/user/gitnotepad/internal/i18n"
+	"github.com/user/gitnotepad/internal/index"
+	"github.com/user/gitnotepad/internal/journal"
+	"github.com/user/gitnotepad/internal/middleware"
 	"github.com/user/gitnotepad/internal/model"
+	"github.com/user/gitnotepad/internal/repository"
 	"github.com/user/gitnotepad/internal/websocket"
+	"golang.org/x/text/unicode/norm"
 )
 
+// mediaClient downloads files sent to the bot
+var mediaClient = &http.Client{Timeout: 5 * time.Minute}
+
+// mediaGroupDelay is how long the bot waits for more messages of an album
+// Telegram sends each photo or video of an album as a separate message with the same media group ID
+const mediaGroupDelay = 2 * time.Second
+
 // Bot represents a Telegram bot instance
 type Bot struct {
-	api    *tgbotapi.BotAPI
-	config *config.Config
-	stopCh chan struct{}
-	wsHub  *websocket.Hub
+	api      *tgbotapi.BotAPI
+	config   *config.Config
+	stopCh   chan struct{}
+	wsHub    *websocket.Hub
+	prefRepo *repository.PreferenceRepository
+	chatRepo *repository.TelegramChatRepository
+	userRepo *repository.UserRepository
+
+	noteIndex  *index.Index
+	shortLinks *handler.ShortLinkHandler
+	linkViews  linkViews
+
+	maintenance *middleware.Maintenance
+
+	// Albums being received, by media group ID
+	albumsMu   sync.Mutex
+	albums     map[string]*mediaGroup
+	albumReady chan string
+}
+
+// mediaGroup collects the messages of an album, saved as one note once no more arrive
+type mediaGroup struct {
+	chatID   int64
+	username string
+	lang     string
+	caption  string
+	media    []*telegramMedia
+	timer    *time.Timer
 }
 
 // New creates a new Telegram bot instance
@@ -48,9 +92,11 @@ func New(cfg *config.Config) (*Bot, error) {
 	encoding.Info("Telegram bot authorized as @%s", api.Self.UserName)
 
 	return &Bot{
-		api:    api,
-		config: cfg,
-		stopCh: make(chan struct{}),
+		api:        api,
+		config:     cfg,
+		stopCh:     make(chan struct{}),
+		albums:     make(map[string]*mediaGroup),
+		albumReady: make(chan string),
 	}, nil
 }
 
@@ -67,11 +113,19 @@ func (b *Bot) Start() {
 
 	encoding.Info("Telegram bot started, listening for messages...")
 
+	if b.config.Telegram.Notify.Reminders {
+		go b.runReminders()
+	}
+
 	for {
 		select {
 		case <-b.stopCh:
 			encoding.Info("Telegram bot stopping...")
+			b.flushMediaGroups()
 			return
+		case id := <-b.albumReady:
+			// Albums are saved here, so notes are never written by two goroutines at once
+			b.flushMediaGroup(id)
 		case update := <-updates:
 			if update.Message == nil {
 				continue
@@ -80,7 +134,8 @@ func (b *Bot) Start() {
 			// Check if user is allowed
 			if !b.isUserAllowed(update.Message.From.ID) {
 				encoding.Debug("Telegram: Unauthorized user %d (%s)", update.Message.From.ID, update.Message.From.UserName)
-				b.sendMessage(update.Message.Chat.ID, "⛔ You are not authorized to use this bot.")
+				lang := i18n.Match(update.Message.From.LanguageCode)
+				b.sendMessage(update.Message.Chat.ID, i18n.T(lang, "⛔ You are not authorized to use this bot."))
 				continue
 			}
 
@@ -107,8 +162,93 @@ func (b *Bot) SetHub(hub *websocket.Hub) {
 	}
 }
 
-// isUserAllowed checks if the user is in the allowed list
+// SetMaintenance makes the bot refuse to save notes while maintenance mode is on
+func (b *Bot) SetMaintenance(maintenance *middleware.Maintenance) {
+	if b != nil {
+		b.maintenance = maintenance
+	}
+}
+
+// SetPreferences sets the repository the reply language is read from
+func (b *Bot) SetPreferences(prefRepo *repository.PreferenceRepository) {
+	if b != nil {
+		b.prefRepo = prefRepo
+	}
+}
+
+// SetUsers sets the repository the read-only and upload settings of accounts are read from
+func (b *Bot) SetUsers(userRepo *repository.UserRepository) {
+	if b != nil {
+		b.userRepo = userRepo
+	}
+}
+
+// account returns the GitNotepad user notes are saved as, or nil when it has no account,
+// as when authentication is disabled
+func (b *Bot) account(username string) *model.User {
+	if b.userRepo == nil {
+		return nil
+	}
+	user, err := b.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil
+	}
+	return user
+}
+
+// username returns the GitNotepad user a Telegram user's notes are saved as:
+// their entry in user_map, otherwise default_username
+func (b *Bot) username(from *tgbotapi.User) string {
+	if from != nil {
+		if username, ok := b.config.Telegram.UserMap[from.ID]; ok && username != "" {
+			return username
+		}
+	}
+	return b.config.Telegram.DefaultUsername
+}
+
+// SetChats sets the repository the folder chosen with /folder is kept in
+func (b *Bot) SetChats(chatRepo *repository.TelegramChatRepository) {
+	if b != nil {
+		b.chatRepo = chatRepo
+	}
+}
+
+// language returns the reply language: the preference of the user notes are saved as,
+// otherwise the language of the Telegram app
+func (b *Bot) language(msg *tgbotapi.Message) string {
+	if b.prefRepo != nil {
+		prefs, err := b.prefRepo.GetByUsername(b.username(msg.From))
+		if err == nil && i18n.Supported(prefs.Language) {
+			return prefs.Language
+		}
+	}
+	if msg.From != nil {
+		return i18n.Match(msg.From.LanguageCode)
+	}
+	return i18n.Default
+}
+
+// location returns the time zone for titles and journal dates: the preference of the user
+// notes are saved as, otherwise the server's local time zone
+func (b *Bot) location(username string) *time.Location {
+	if b.prefRepo != nil {
+		prefs, err := b.prefRepo.GetByUsername(username)
+		if err == nil && prefs.Timezone != "" {
+			if loc, err := time.LoadLocation(prefs.Timezone); err == nil {
+				return loc
+			}
+		}
+	}
+	return time.Local
+}
+
+// isUserAllowed checks if the user is in the allowed list or mapped to a GitNotepad user
 func (b *Bot) isUserAllowed(userID int64) bool {
+	if _, ok := b.config.Telegram.UserMap[userID]; ok {
+		return true
+	}
+
 	// If no allowed users configured, deny all
 	if len(b.config.Telegram.AllowedUsers) == 0 {
 		return false
@@ -125,70 +265,320 @@ func (b *Bot) isUserAllowed(userID int64) bool {
 // handleMessage processes incoming messages
 func (b *Bot) handleMessage(msg *tgbotapi.Message) {
 	var content string
+	lang := b.language(msg)
+	username := b.username(msg.From)
+
+	// Notes are not written while the server is read-only
+	if b.maintenance != nil && b.maintenance.Status().Enabled && (!msg.IsCommand() || msg.Command() == "journal") {
+		b.sendMessage(msg.Chat.ID, i18n.T(lang, "🚧 The server is in maintenance mode. Please try again later."))
+		return
+	}
+
+	// Nor into the storage of a read-only account
+	if user := b.account(username); user != nil && user.ReadOnly && (!msg.IsCommand() || msg.Command() == "journal") {
+		b.sendMessage(msg.Chat.ID, i18n.T(lang, "🔒 This account is read-only."))
+		return
+	}
 
 	// Handle different message types
+	m := messageMedia(msg, time.Now().In(b.location(username)))
 	if msg.Text != "" {
 		content = msg.Text
 	} else if msg.Caption != "" {
-		// Photo or document with caption
+		// Photo, document or video with caption
 		content = msg.Caption
-	} else if msg.Photo != nil {
-		// Photo without caption
-		content = "[Photo received]"
-	} else if msg.Document != nil {
-		// Document without caption
-		content = fmt.Sprintf("[Document: %s]", msg.Document.FileName)
-	} else {
+	} else if m == nil {
 		// Unsupported message type
-		b.sendMessage(msg.Chat.ID, "⚠️ Unsupported message type. Please send text messages.")
+		b.sendMessage(msg.Chat.ID, i18n.T(lang, "⚠️ Unsupported message type. Please send text, photos, documents or videos."))
 		return
 	}
 
 	// Handle commands
 	if msg.IsCommand() {
-		b.handleCommand(msg)
+		b.handleCommand(msg, lang)
+		return
+	}
+
+	// The photos and videos of an album become one note
+	if msg.MediaGroupID != "" && m != nil {
+		b.addToMediaGroup(msg, username, lang, m)
 		return
 	}
 
+	// In journal mode, text messages are added to today's journal note, unless a #folder routes them
+	if b.config.Telegram.JournalMode && m == nil {
+		if _, _, routed := b.hashtagFolder(username, content); !routed {
+			b.saveJournal(msg.Chat.ID, username, lang, content)
+			return
+		}
+	}
+
+	var media []*telegramMedia
+	if m != nil {
+		media = append(media, m)
+	}
+	b.saveMessageNote(msg.Chat.ID, username, lang, content, media)
+}
+
+// saveMessageNote stores the files of a message or album in the user's files and creates the note, replying with the result
+func (b *Bot) saveMessageNote(chatID int64, username, lang, content string, media []*telegramMedia) {
+	// A message starting with the #name of an existing folder is saved there
+	folder := b.chatFolder(chatID)
+	if tagged, rest, ok := b.hashtagFolder(username, content); ok {
+		folder, content = tagged, rest
+	}
+
+	// Without text, the note is named after the first file sent with its name
+	titleSource := content
+	var attachments []model.Attachment
+	user := b.account(username)
+	uploadDisabled := user != nil && user.UploadDisabled
+	if uploadDisabled && len(media) > 0 {
+		b.sendMessage(chatID, i18n.T(lang, "⚠️ Uploads are disabled for this account, so the attachments were not saved."))
+	}
+	for _, m := range media {
+		if titleSource == "" {
+			titleSource = m.fileName
+		}
+		// Without uploads, the note only names the files that were sent
+		if uploadDisabled {
+			content = appendLine(content, m.label)
+			continue
+		}
+		att, err := b.saveMedia(username, m)
+		if err != nil {
+			encoding.Warn("Telegram: Failed to save attachment: %v", err)
+			b.sendMessage(chatID, i18n.T(lang, "⚠️ The attachment could not be saved: %v", err))
+			content = appendLine(content, m.label)
+			continue
+		}
+		attachments = append(attachments, att)
+		content = appendLine(content, attachmentMarkdown(att))
+	}
+
 	// Create note from message
-	title, err := b.createNoteFromMessage(content, msg)
+	title, err := b.createNoteFromMessage(username, folder, titleSource, content, attachments)
 	if err != nil {
 		encoding.Error("Telegram: Failed to create note: %v", err)
-		b.sendMessage(msg.Chat.ID, fmt.Sprintf("❌ Failed to save note: %v", err))
+		b.sendMessage(chatID, i18n.T(lang, "❌ Failed to save note: %v", err))
 		return
 	}
 
 	// Send confirmation
-	folderDisplay := strings.ReplaceAll(b.config.Telegram.DefaultFolder, ":>:", "/")
-	b.sendMessage(msg.Chat.ID, fmt.Sprintf("✅ Note saved!\n📁 Folder: %s\n📝 Title: %s", folderDisplay, title))
+	b.sendMessage(chatID, i18n.T(lang, "✅ Note saved!\n📁 Folder: %s\n📝 Title: %s", folderDisplay(folder), title))
+}
+
+// defaultFolder returns telegram.default_folder as a folder path ("a:>:b" -> "a/b")
+func (b *Bot) defaultFolder() string {
+	return norm.NFC.String(strings.Trim(strings.ReplaceAll(b.config.Telegram.DefaultFolder, ":>:", "/"), "/"))
+}
+
+// chatFolder returns the folder notes from a chat are saved in: the one chosen with /folder, otherwise the default
+func (b *Bot) chatFolder(chatID int64) string {
+	if b.chatRepo != nil {
+		folder, ok, err := b.chatRepo.GetFolder(chatID)
+		if err != nil {
+			encoding.Warn("Telegram: Failed to get chat folder: %v", err)
+		} else if ok {
+			return folder
+		}
+	}
+	return b.defaultFolder()
+}
+
+// hashtagFolder matches a leading #name against the user's folders (case-insensitive, "#work/meetings" for subfolders)
+// It returns the folder and the content without the hashtag; unknown hashtags are left in the text
+func (b *Bot) hashtagFolder(username, content string) (string, string, bool) {
+	fields := strings.Fields(content)
+	if len(fields) == 0 || len(fields[0]) < 2 || fields[0][0] != '#' {
+		return "", content, false
+	}
+	name := norm.NFC.String(fields[0][1:])
+	for _, folder := range b.listFolders(username) {
+		if strings.EqualFold(folder, name) {
+			rest := strings.TrimLeft(strings.TrimSpace(content)[len(fields[0]):], " \t")
+			return folder, strings.TrimPrefix(rest, "\n"), true
+		}
+	}
+	return "", content, false
+}
+
+// listFolders returns the note folders of a user as paths ("a", "a/b")
+func (b *Bot) listFolders(username string) []string {
+	notesPath := filepath.Join(b.config.Storage.Path, username, "notes")
+	var folders []string
+	filepath.WalkDir(notesPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || p == notesPath {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if rel, err := filepath.Rel(notesPath, p); err == nil {
+			folders = append(folders, norm.NFC.String(filepath.ToSlash(rel)))
+		}
+		return nil
+	})
+	return folders
+}
+
+// folderDisplay shows a folder path in replies, "/" for the top level
+func folderDisplay(folder string) string {
+	if folder == "" {
+		return "/"
+	}
+	return folder
+}
+
+// addToMediaGroup buffers a message of an album until no more of it arrive for mediaGroupDelay
+func (b *Bot) addToMediaGroup(msg *tgbotapi.Message, username, lang string, media *telegramMedia) {
+	b.albumsMu.Lock()
+	defer b.albumsMu.Unlock()
+
+	id := msg.MediaGroupID
+	group, ok := b.albums[id]
+	if !ok {
+		group = &mediaGroup{chatID: msg.Chat.ID, username: username, lang: lang}
+		group.timer = time.AfterFunc(mediaGroupDelay, func() {
+			select {
+			case b.albumReady <- id:
+			case <-b.stopCh:
+			}
+		})
+		b.albums[id] = group
+	} else {
+		group.timer.Reset(mediaGroupDelay)
+	}
+	group.media = append(group.media, media)
+	// Telegram puts the album's caption on one of its messages
+	if group.caption == "" {
+		group.caption = msg.Caption
+	}
+}
+
+// flushMediaGroup saves a buffered album as a note
+func (b *Bot) flushMediaGroup(id string) {
+	b.albumsMu.Lock()
+	group, ok := b.albums[id]
+	delete(b.albums, id)
+	b.albumsMu.Unlock()
+	if !ok {
+		return
+	}
+
+	encoding.Debug("Telegram: Saving album %s with %d files", id, len(group.media))
+	b.saveMessageNote(group.chatID, group.username, group.lang, group.caption, group.media)
+}
+
+// flushMediaGroups saves the albums still being received, when the bot stops
+func (b *Bot) flushMediaGroups() {
+	b.albumsMu.Lock()
+	ids := make([]string, 0, len(b.albums))
+	for id, group := range b.albums {
+		group.timer.Stop()
+		ids = append(ids, id)
+	}
+	b.albumsMu.Unlock()
+
+	for _, id := range ids {
+		b.flushMediaGroup(id)
+	}
 }
 
 // handleCommand processes bot commands
-func (b *Bot) handleCommand(msg *tgbotapi.Message) {
+func (b *Bot) handleCommand(msg *tgbotapi.Message, lang string) {
 	switch msg.Command() {
 	case "start":
-		b.sendMessage(msg.Chat.ID, "👋 Welcome to Git Notepad Bot!\n\nSend me any text message and I'll save it as a note.\n\n📋 Commands:\n/start - Show this help\n/info - Show bot info")
+		b.sendMessage(msg.Chat.ID, i18n.T(lang, "👋 Welcome to Git Notepad Bot!\n\nSend me any text message and I'll save it as a note. Start it with #folder to save it in an existing folder.\n\n📋 Commands:\n/start - Show this help\n/info - Show bot info\n/folder [path] - Show or set the folder of this chat (/ = top level, - = default)\n/folders - List folders\n/search <text> - Find notes\n/recent - List recently modified notes\n/get <id> - Show a note\n/journal [text] - Open today's journal note, appending text if given"))
 	case "info":
-		folderDisplay := strings.ReplaceAll(b.config.Telegram.DefaultFolder, ":>:", "/")
-		info := fmt.Sprintf("ℹ️ Bot Info\n\n📁 Default folder: %s\n👤 Saving as user: %s\n🆔 Your Telegram ID: %d",
-			folderDisplay,
-			b.config.Telegram.DefaultUsername,
+		info := i18n.T(lang, "ℹ️ Bot Info\n\n📁 Folder: %s\n👤 Saving as user: %s\n🆔 Your Telegram ID: %d",
+			folderDisplay(b.chatFolder(msg.Chat.ID)),
+			b.username(msg.From),
 			msg.From.ID)
 		b.sendMessage(msg.Chat.ID, info)
+	case "folder":
+		b.setChatFolder(msg, lang)
+	case "search":
+		b.handleSearch(msg, lang)
+	case "recent":
+		b.handleRecent(msg, lang)
+	case "get":
+		b.handleGet(msg, lang)
+	case "folders":
+		folders := b.listFolders(b.username(msg.From))
+		if len(folders) == 0 {
+			b.sendMessage(msg.Chat.ID, i18n.T(lang, "📂 No folders yet."))
+			return
+		}
+		b.sendMessage(msg.Chat.ID, i18n.T(lang, "📂 Folders:\n%s", strings.Join(folders, "\n")))
+	case "journal":
+		b.saveJournal(msg.Chat.ID, b.username(msg.From), lang, strings.TrimSpace(msg.CommandArguments()))
 	default:
-		b.sendMessage(msg.Chat.ID, "❓ Unknown command. Use /start for help.")
+		b.sendMessage(msg.Chat.ID, i18n.T(lang, "❓ Unknown command. Use /start for help."))
 	}
 }
 
-// createNoteFromMessage creates a new note from a Telegram message
-func (b *Bot) createNoteFromMessage(content string, msg *tgbotapi.Message) (string, error) {
-	now := time.Now()
+// saveJournal appends text to today's journal note, replying with the result
+func (b *Bot) saveJournal(chatID int64, username, lang, text string) {
+	title, created, err := b.appendJournal(username, text)
+	if err != nil {
+		encoding.Error("Telegram: Failed to update journal: %v", err)
+		b.sendMessage(chatID, i18n.T(lang, "❌ Failed to update journal: %v", err))
+		return
+	}
+	reply := "📓 Journal updated!\n📝 Title: %s"
+	if created {
+		reply = "📓 Journal created!\n📝 Title: %s"
+	}
+	b.sendMessage(chatID, i18n.T(lang, reply, title))
+}
 
-	// Generate title from content or timestamp
-	title := generateTitle(content, now)
+// setChatFolder handles /folder: without a path it shows the chat's folder, "/" saves at the top level and "-" goes back to the default
+func (b *Bot) setChatFolder(msg *tgbotapi.Message, lang string) {
+	arg := strings.TrimSpace(msg.CommandArguments())
+	if arg == "" {
+		b.sendMessage(msg.Chat.ID, i18n.T(lang, "📁 Notes from this chat are saved in: %s\nUse /folder <path> to change it.", folderDisplay(b.chatFolder(msg.Chat.ID))))
+		return
+	}
+	if b.chatRepo == nil {
+		b.sendMessage(msg.Chat.ID, i18n.T(lang, "❌ Failed to set folder: %v", "not available"))
+		return
+	}
+
+	var err error
+	folder := b.defaultFolder()
+	switch arg {
+	case "-":
+		err = b.chatRepo.DeleteFolder(msg.Chat.ID)
+	case "/":
+		folder = ""
+		err = b.chatRepo.SetFolder(msg.Chat.ID, folder)
+	default:
+		var ok bool
+		folder, ok = handler.CleanFolderPath(strings.ReplaceAll(arg, ":>:", "/"))
+		if !ok {
+			b.sendMessage(msg.Chat.ID, i18n.T(lang, "⚠️ Invalid folder name."))
+			return
+		}
+		err = b.chatRepo.SetFolder(msg.Chat.ID, folder)
+	}
+	if err != nil {
+		encoding.Error("Telegram: Failed to set folder: %v", err)
+		b.sendMessage(msg.Chat.ID, i18n.T(lang, "❌ Failed to set folder: %v", err))
+		return
+	}
+	b.sendMessage(msg.Chat.ID, i18n.T(lang, "📁 Notes from this chat are now saved in: %s", folderDisplay(folder)))
+}
+
+// createNoteFromMessage creates a new note in a folder ("" = top level) from a Telegram message and the attachments stored from it
+// titleSource is the text the title is generated from
+func (b *Bot) createNoteFromMessage(username, folder, titleSource, content string, attachments []model.Attachment) (string, error) {
+	now := time.Now().In(b.location(username))
+
+	// Generate title from the message or timestamp
+	title := norm.NFC.String(generateTitle(titleSource, now))
 
 	// Build paths
-	username := b.config.Telegram.DefaultUsername
 	userPath := filepath.Join(b.config.Storage.Path, username)
 	notesPath := filepath.Join(userPath, "notes")
 
@@ -198,12 +588,9 @@ func (b *Bot) createNoteFromMessage(content string, msg *tgbotapi.Message) (stri
 	}
 
 	// Build folder path
-	folder := b.config.Telegram.DefaultFolder
 	var targetDir string
 	if folder != "" {
-		// Convert :>: separator to path separator if present
-		folderPath := strings.ReplaceAll(folder, ":>:", string(filepath.Separator))
-		targetDir = filepath.Join(notesPath, folderPath)
+		targetDir = filepath.Join(notesPath, filepath.FromSlash(folder))
 		if err := os.MkdirAll(targetDir, 0755); err != nil {
 			return "", fmt.Errorf("failed to create folder: %w", err)
 		}
@@ -223,19 +610,21 @@ func (b *Bot) createNoteFromMessage(content string, msg *tgbotapi.Message) (stri
 	// Build full title with folder path (required for folder sharing to work)
 	fullTitle := title
 	if folder != "" {
-		fullTitle = folder + ":>:" + title
+		fullTitle = strings.ReplaceAll(folder, "/", ":>:") + ":>:" + title
 	}
 
 	// Create note
 	note := &model.Note{
-		ID:         fullID,
-		FolderPath: folder,
-		Title:      fullTitle,
-		Content:    content,
-		Type:       "markdown",
-		Tags:       []string{"telegram"},
-		Created:    now,
-		Modified:   now,
+		ID:          fullID,
+		UID:         id,
+		FolderPath:  folder,
+		Title:       fullTitle,
+		Content:     content,
+		Type:        "markdown",
+		Tags:        []string{"telegram"},
+		Attachments: attachments,
+		Created:     now,
+		Modified:    now,
 	}
 
 	// Generate file content
@@ -273,11 +662,187 @@ func (b *Bot) createNoteFromMessage(content string, msg *tgbotapi.Message) (stri
 		encoding.Debug("Telegram: Broadcasted note creation to user %s", username)
 	}
 
-	encoding.Info("Telegram: Note saved - %s/%s", folder, title)
+	encoding.Info("Telegram: Note saved - %s/%s (user=%s)", folder, title, username)
 
 	return title, nil
 }
 
+// appendJournal finds or creates today's journal note and appends text (if any) as a timestamped line
+func (b *Bot) appendJournal(username, text string) (string, bool, error) {
+	now := time.Now().In(b.location(username))
+
+	userPath := filepath.Join(b.config.Storage.Path, username)
+	folder := norm.NFC.String(strings.Trim(strings.ReplaceAll(b.config.Journal.Folder, ":>:", "/"), "/"))
+	folderDir := filepath.Join(userPath, "notes", filepath.FromSlash(folder))
+
+	if err := os.MkdirAll(folderDir, 0755); err != nil {
+		return "", false, fmt.Errorf("failed to create folder: %w", err)
+	}
+
+	load := func(path string) (*model.Note, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return model.ParseNoteFromBytes(data, path)
+	}
+
+	created := false
+	filePath, note := journal.Find(folderDir, journal.Title(b.config.Journal, now), load)
+	if note == nil {
+		id := uuid.New().String()
+		note = journal.New(folderDir, b.config.Journal, now, "markdown", load)
+		note.ID = id
+		if folder != "" {
+			note.ID = folder + "/" + id
+		}
+		note.UID = id
+		note.FolderPath = folder
+		filePath = filepath.Join(folderDir, id+note.GetExtension())
+		created = true
+	} else if text == "" {
+		return note.Title, false, nil
+	}
+
+	if text != "" {
+		note.Content = strings.TrimRight(note.Content, "\n") + "\n\n- " + now.Format("15:04") + " " + text + "\n"
+		note.Modified = now
+	}
+
+	fileContent, err := note.ToFileContent()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to generate file content: %w", err)
+	}
+	if err := os.WriteFile(filePath, fileContent, 0644); err != nil {
+		return "", false, fmt.Errorf("failed to save note: %w", err)
+	}
+
+	// Git commit
+	commitMsg := fmt.Sprintf("Update note: %s", note.Title)
+	if created {
+		commitMsg = fmt.Sprintf("Create note: %s", note.Title)
+	}
+	repo, err := git.NewRepository(userPath)
+	if err == nil {
+		if err := repo.Init(); err != nil {
+			encoding.Warn("Telegram: Failed to init git repo: %v", err)
+		} else {
+			absFilePath, _ := filepath.Abs(filePath)
+			if err := repo.AddAndCommit(absFilePath, commitMsg); err != nil {
+				encoding.Warn("Telegram: Failed to commit: %v", err)
+			}
+		}
+	}
+
+	// Broadcast via WebSocket
+	if b.wsHub != nil {
+		msgType := websocket.MsgTypeNoteUpdated
+		if created {
+			msgType = websocket.MsgTypeNoteCreated
+		}
+		relPath, _ := filepath.Rel(filepath.Join(userPath, "notes"), filePath)
+		b.wsHub.BroadcastToUser(username, websocket.Message{
+			Type:   msgType,
+			NoteID: strings.TrimSuffix(filepath.ToSlash(relPath), filepath.Ext(filePath)),
+		})
+	}
+
+	encoding.Info("Telegram: Journal saved - %s/%s", folder, note.Title)
+
+	return note.Title, created, nil
+}
+
+// telegramMedia is a photo, document or video sent to the bot
+type telegramMedia struct {
+	fileID   string
+	fileName string // Name the file was sent with ("" for photos)
+	name     string // Name to store the file under ("" = generated, with the extension from Telegram's file path)
+	mimeType string
+	label    string // Placeholder kept in the note when the file can't be stored
+}
+
+// messageMedia returns the photo, document or video of a message, or nil
+func messageMedia(msg *tgbotapi.Message, now time.Time) *telegramMedia {
+	stamp := now.Format("20060102-150405")
+	switch {
+	case len(msg.Photo) > 0:
+		// Telegram sends each photo in several sizes; keep the largest
+		photo := msg.Photo[0]
+		for _, size := range msg.Photo[1:] {
+			if size.Width*size.Height > photo.Width*photo.Height {
+				photo = size
+			}
+		}
+		return &telegramMedia{
+			fileID:   photo.FileID,
+			name:     "photo-" + stamp + ".jpg",
+			mimeType: "image/jpeg",
+			label:    "[Photo received]",
+		}
+	case msg.Document != nil:
+		return &telegramMedia{
+			fileID:   msg.Document.FileID,
+			fileName: msg.Document.FileName,
+			name:     msg.Document.FileName,
+			mimeType: msg.Document.MimeType,
+			label:    fmt.Sprintf("[Document: %s]", msg.Document.FileName),
+		}
+	case msg.Video != nil:
+		return &telegramMedia{
+			fileID:   msg.Video.FileID,
+			fileName: msg.Video.FileName,
+			name:     msg.Video.FileName,
+			mimeType: msg.Video.MimeType,
+			label:    "[Video received]",
+		}
+	}
+	return nil
+}
+
+// saveMedia downloads a file sent to the bot through the Bot API and stores it in the user's files
+func (b *Bot) saveMedia(username string, media *telegramMedia) (model.Attachment, error) {
+	file, err := b.api.GetFile(tgbotapi.FileConfig{FileID: media.fileID})
+	if err != nil {
+		return model.Attachment{}, fmt.Errorf("failed to get file: %w", err)
+	}
+	name := media.name
+	if name == "" {
+		name = "video-" + time.Now().In(b.location(username)).Format("20060102-150405") + path.Ext(file.FilePath)
+	}
+
+	resp, err := mediaClient.Get(file.Link(b.api.Token))
+	if err != nil {
+		// The URL holds the bot token, so it is left out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return model.Attachment{}, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return model.Attachment{}, fmt.Errorf("failed to download file: %s", resp.Status)
+	}
+
+	return handler.SaveAttachment(b.config, username, name, media.mimeType, resp.Body)
+}
+
+// attachmentMarkdown shows an image in the note and links any other file
+func attachmentMarkdown(att model.Attachment) string {
+	if att.IsImage {
+		return "![" + att.Name + "](" + att.URL + ")"
+	}
+	return "[" + att.Name + "](" + att.URL + ")"
+}
+
+// appendLine appends a paragraph to the content
+func appendLine(content, line string) string {
+	if content == "" {
+		return line
+	}
+	return strings.TrimRight(content, "\n") + "\n\n" + line
+}
+
 // sendMessage sends a message to a chat
 func (b *Bot) sendMessage(chatID int64, text string) {
 	msg := tgbotapi.NewMessage(chatID, text)
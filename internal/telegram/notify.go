@@ -0,0 +1,137 @@
+package telegram
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/gitnotepad/internal/encoding"
+	"github.com/user/gitnotepad/internal/i18n"
+	"github.com/user/gitnotepad/internal/index"
+	"github.com/user/gitnotepad/internal/notify"
+)
+
+// linkViewNotifyInterval is the least time between two notifications about views of the same link
+const linkViewNotifyInterval = time.Hour
+
+// linkViews remembers when views of each link were last notified
+type linkViews struct {
+	mu       sync.Mutex
+	notified map[string]time.Time // code -> time of the last notification
+}
+
+// due reports whether views of a link may be notified again, and if so records the notification
+func (v *linkViews) due(code string, now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.notified == nil {
+		v.notified = make(map[string]time.Time)
+	}
+	if last, ok := v.notified[code]; ok && now.Sub(last) < linkViewNotifyInterval {
+		return false
+	}
+	v.notified[code] = now
+	return true
+}
+
+// SetEvents subscribes the bot to note events, pushed to the chats in telegram.notify
+func (b *Bot) SetEvents(events *notify.Dispatcher) {
+	if b != nil && events != nil {
+		events.Subscribe(b.handleEvent)
+	}
+}
+
+// notifyLanguage returns the language of notifications to a user: their preference, otherwise the default
+func (b *Bot) notifyLanguage(username string) string {
+	if b.prefRepo != nil {
+		prefs, err := b.prefRepo.GetByUsername(username)
+		if err == nil && i18n.Supported(prefs.Language) {
+			return prefs.Language
+		}
+	}
+	return i18n.Default
+}
+
+// notifyUser sends a message to the chats notified of a user's events
+func (b *Bot) notifyUser(username, text string) {
+	for _, chatID := range b.config.Telegram.Notify.Chats[username] {
+		if _, err := b.api.Send(tgbotapi.NewMessage(chatID, text)); err != nil {
+			encoding.Warn("Telegram: Failed to send notification to chat %d: %v", chatID, err)
+		}
+	}
+}
+
+// handleEvent pushes a note event to the owner's chats when its kind is enabled
+func (b *Bot) handleEvent(event notify.Event) {
+	cfg := b.config.Telegram.Notify
+	if len(cfg.Chats[event.Username]) == 0 {
+		return
+	}
+	lang := b.notifyLanguage(event.Username)
+
+	switch event.Type {
+	case notify.SharedNoteCreated:
+		if !cfg.SharedNotes {
+			return
+		}
+		actor := event.Actor
+		if actor == "" {
+			actor = "?"
+		}
+		b.notifyUser(event.Username, i18n.T(lang, "📥 %s added a note to your shared folder %s\n📝 %s", actor, folderDisplay(event.Folder), event.Title))
+	case notify.LinkViewed:
+		if !cfg.LinkViews || !b.linkViews.due(event.Code, time.Now()) {
+			return
+		}
+		target := event.Folder
+		if target == "" {
+			target = event.NoteID
+			if note := readNote(b.notesPath(event.Username), event.NoteID); note != nil {
+				target = note.Title
+			}
+		}
+		link := strings.TrimRight(b.config.SEO.SiteURL, "/") + b.config.Server.BasePath + "/s/" + event.Code
+		b.notifyUser(event.Username, i18n.T(lang, "👀 Your link was opened (%d views)\n📝 %s\n🔗 %s", event.Views, target, link))
+	}
+}
+
+// runReminders sends the notes due today to each user's chats once a day, at telegram.notify.reminder_hour
+func (b *Bot) runReminders() {
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Hour).Add(time.Hour)
+		select {
+		case <-b.stopCh:
+			return
+		case <-time.After(next.Sub(now)):
+		}
+		b.sendReminders(time.Now())
+	}
+}
+
+// sendReminders notifies the users for whom it is reminder_hour of their notes due today
+func (b *Bot) sendReminders(now time.Time) {
+	for username := range b.config.Telegram.Notify.Chats {
+		local := now.In(b.location(username))
+		if local.Hour() != b.config.Telegram.Notify.ReminderHour {
+			continue
+		}
+		today := local.Format("2006-01-02")
+		var due []index.Entry
+		for _, entry := range b.recentEntries(username) {
+			if entry.Due == today {
+				due = append(due, entry)
+			}
+		}
+		if len(due) == 0 {
+			continue
+		}
+		if len(due) > noteListLimit {
+			due = due[:noteListLimit]
+		}
+		encoding.Debug("Telegram: Reminding %s of %d notes due today", username, len(due))
+		lang := b.notifyLanguage(username)
+		b.notifyUser(username, i18n.T(lang, "⏰ Notes due today:")+"\n\n"+b.noteList(username, due))
+	}
+}
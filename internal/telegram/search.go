@@ -0,0 +1,224 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/user/gitnotepad/internal/encryption"
+	"github.com/user/gitnotepad/internal/handler"
+	"github.com/user/gitnotepad/internal/i18n"
+	"github.com/user/gitnotepad/internal/index"
+	"github.com/user/gitnotepad/internal/model"
+)
+
+// noteListLimit is how many notes /search and /recent reply with
+const noteListLimit = 10
+
+// noteMessageLimit keeps /get replies under Telegram's limit of 4096 characters per message
+const noteMessageLimit = 4000
+
+// SetNotes sets the note index and short links used by /search, /recent and /get
+func (b *Bot) SetNotes(noteIndex *index.Index, shortLinks *handler.ShortLinkHandler) {
+	if b != nil {
+		b.noteIndex = noteIndex
+		b.shortLinks = shortLinks
+	}
+}
+
+// loadNote parses a note file for the index
+// The bot holds no encryption key, so encrypted notes are left out
+func loadNote(path string, data []byte) (*model.Note, error) {
+	if encryption.IsEncrypted(string(data)) {
+		return nil, errors.New("note is encrypted")
+	}
+	return model.ParseNoteFromBytes(data, path)
+}
+
+// notesPath returns the notes directory of a user
+func (b *Bot) notesPath(username string) string {
+	return filepath.Join(b.config.Storage.Path, username, "notes")
+}
+
+// recentEntries returns the indexed notes of a user that aren't archived, most recently modified first
+func (b *Bot) recentEntries(username string) []index.Entry {
+	if b.noteIndex == nil {
+		return nil
+	}
+	var entries []index.Entry
+	for _, entry := range b.noteIndex.Entries(b.notesPath(username), loadNote) {
+		if !entry.Archived {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Modified.After(entries[j].Modified)
+	})
+	return entries
+}
+
+// searchNotes returns the notes matching a query in their title, aliases, tags, content or attachment names
+// The content of password-protected notes isn't searched
+func (b *Bot) searchNotes(username, query string) []index.Entry {
+	query = strings.ToLower(query)
+	notesPath := b.notesPath(username)
+
+	var found []index.Entry
+	for _, entry := range b.recentEntries(username) {
+		if len(found) == noteListLimit {
+			break
+		}
+		if entryMatches(entry, query) {
+			found = append(found, entry)
+			continue
+		}
+		note := readNote(notesPath, entry.ID)
+		if note == nil || note.Password != "" {
+			continue
+		}
+		match := strings.Contains(strings.ToLower(note.Content), query)
+		for _, att := range note.Attachments {
+			match = match || strings.Contains(strings.ToLower(att.Name), query)
+		}
+		if match {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+// entryMatches reports whether the indexed title, aliases or tags of a note contain the query
+func entryMatches(entry index.Entry, query string) bool {
+	if strings.Contains(strings.ToLower(entry.Title), query) {
+		return true
+	}
+	for _, alias := range entry.Aliases {
+		if strings.Contains(strings.ToLower(alias), query) {
+			return true
+		}
+	}
+	for _, tag := range entry.Tags {
+		if strings.Contains(strings.ToLower(tag), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// readNote loads a note by ID, nil if it doesn't exist or can't be read
+func readNote(notesPath, id string) *model.Note {
+	for _, ext := range []string{".md", ".txt", ".adoc"} {
+		path := filepath.Join(notesPath, filepath.FromSlash(id)+ext)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		note, err := loadNote(path, data)
+		if err != nil {
+			return nil
+		}
+		return note
+	}
+	return nil
+}
+
+// noteList formats notes as titles with their short links and the /get command that sends them
+func (b *Bot) noteList(username string, entries []index.Entry) string {
+	var sb strings.Builder
+	for i, entry := range entries {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		title := entry.Title
+		if entry.FolderPath != "" {
+			title = entry.FolderPath + "/" + title
+		}
+		sb.WriteString("📝 " + title + "\n")
+
+		if b.shortLinks == nil {
+			sb.WriteString("/get " + entry.UID)
+			continue
+		}
+		link := b.shortLinks.NoteLink(username, entry.ID)
+		sb.WriteString("🔗 " + strings.TrimRight(b.config.SEO.SiteURL, "/") + link + "  /get " + path.Base(link))
+	}
+	return sb.String()
+}
+
+// findNote resolves the argument of /get: a short link code, a note UID or a note ID of the user
+func (b *Bot) findNote(username, ref string) *model.Note {
+	notesPath := b.notesPath(username)
+	if b.shortLinks != nil {
+		if owner, id, ok := b.shortLinks.LinkedNote(ref); ok && owner == username {
+			return readNote(notesPath, id)
+		}
+	}
+	if b.noteIndex != nil {
+		if id, ok := b.noteIndex.Resolve(notesPath, ref, loadNote); ok {
+			return readNote(notesPath, id)
+		}
+	}
+	if id, ok := handler.CleanFolderPath(ref); ok {
+		return readNote(notesPath, id)
+	}
+	return nil
+}
+
+// handleSearch replies to /search <query> with the matching notes
+func (b *Bot) handleSearch(msg *tgbotapi.Message, lang string) {
+	query := strings.TrimSpace(msg.CommandArguments())
+	if query == "" {
+		b.sendMessage(msg.Chat.ID, i18n.T(lang, "Usage: /search <text>"))
+		return
+	}
+	username := b.username(msg.From)
+	found := b.searchNotes(username, query)
+	if len(found) == 0 {
+		b.sendMessage(msg.Chat.ID, i18n.T(lang, "🔍 No notes found for \"%s\".", query))
+		return
+	}
+	b.sendMessage(msg.Chat.ID, i18n.T(lang, "🔍 Notes matching \"%s\":", query)+"\n\n"+b.noteList(username, found))
+}
+
+// handleRecent replies to /recent with the most recently modified notes
+func (b *Bot) handleRecent(msg *tgbotapi.Message, lang string) {
+	username := b.username(msg.From)
+	entries := b.recentEntries(username)
+	if len(entries) == 0 {
+		b.sendMessage(msg.Chat.ID, i18n.T(lang, "📭 No notes yet."))
+		return
+	}
+	if len(entries) > noteListLimit {
+		entries = entries[:noteListLimit]
+	}
+	b.sendMessage(msg.Chat.ID, i18n.T(lang, "🕒 Recent notes:")+"\n\n"+b.noteList(username, entries))
+}
+
+// handleGet replies to /get <id> with the content of the note
+func (b *Bot) handleGet(msg *tgbotapi.Message, lang string) {
+	ref := strings.TrimSpace(msg.CommandArguments())
+	if ref == "" {
+		b.sendMessage(msg.Chat.ID, i18n.T(lang, "Usage: /get <id>"))
+		return
+	}
+	note := b.findNote(b.username(msg.From), ref)
+	if note == nil {
+		b.sendMessage(msg.Chat.ID, i18n.T(lang, "❓ Note not found."))
+		return
+	}
+	if note.Password != "" {
+		b.sendMessage(msg.Chat.ID, i18n.T(lang, "🔒 This note is protected with a password."))
+		return
+	}
+
+	text := fmt.Sprintf("📝 %s\n\n%s", note.Title, note.Content)
+	if runes := []rune(text); len(runes) > noteMessageLimit {
+		text = string(runes[:noteMessageLimit]) + "…"
+	}
+	b.sendMessage(msg.Chat.ID, text)
+}
@@ -0,0 +1,300 @@
+// Package vault reads a folder of markdown notes (an Obsidian vault or any plain markdown folder) from a ZIP archive.
+// Front matter is optional; notes without it get their title from the file name and dates from the file.
+package vault
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+	"gopkg.in/yaml.v3"
+)
+
+// maxNoteSize guards against oversized markdown files in the archive
+const maxNoteSize = 16 << 20
+
+var (
+	// Markdown links and images with a relative target: [text](target) or [text](<target with spaces>)
+	linkPattern = regexp.MustCompile(`(!?)\[([^\]]*)\]\((?:<([^>]+)>|([^)\s]+))((?:\s+"[^"]*")?)\)`)
+	// Obsidian embeds and links: ![[file.png]], ![[file.png|alias]], [[note#heading|alias]]
+	wikiPattern = regexp.MustCompile(`(!?)\[\[([^\]|#]+)(#[^\]|]*)?(?:\|([^\]]*))?\]\]`)
+)
+
+// Note is a markdown file of the archive
+type Note struct {
+	Dir      string // Folder inside the archive, slash-separated ("" = top level)
+	Title    string
+	Content  string // Body without front matter
+	Tags     []string
+	Aliases  []string
+	Created  time.Time
+	Modified time.Time
+
+	archive *Archive
+}
+
+// Archive is a markdown folder read from a ZIP
+type Archive struct {
+	Root  string // Single top-level folder wrapping everything ("" if none), left out of Note.Dir
+	Notes []*Note
+
+	files  map[string]*zip.File // Path (without Root) -> file
+	byName map[string]*zip.File // Lowercase base name -> first file with that name
+}
+
+// frontMatter holds the properties read from notes that have front matter
+type frontMatter struct {
+	Title   string      `yaml:"title"`
+	Tags    interface{} `yaml:"tags"`
+	Aliases interface{} `yaml:"aliases"`
+	Created string      `yaml:"created"`
+	Date    string      `yaml:"date"`
+	Updated string      `yaml:"updated"`
+}
+
+// Read returns the markdown notes of an archive
+// Hidden folders (.obsidian, .trash) and macOS metadata are ignored
+func Read(zr *zip.Reader) (*Archive, error) {
+	var entries []*zip.File
+	for _, f := range zr.File {
+		name := norm.NFC.String(f.Name)
+		if f.FileInfo().IsDir() || strings.Contains(name, "..") || hiddenPath(name) {
+			continue
+		}
+		entries = append(entries, f)
+	}
+
+	a := &Archive{
+		Root:   commonRoot(entries),
+		files:  make(map[string]*zip.File, len(entries)),
+		byName: make(map[string]*zip.File, len(entries)),
+	}
+	for _, f := range entries {
+		name := strings.TrimPrefix(norm.NFC.String(f.Name), a.Root)
+		a.files[name] = f
+		base := strings.ToLower(path.Base(name))
+		if _, ok := a.byName[base]; !ok {
+			a.byName[base] = f
+		}
+	}
+
+	for _, f := range entries {
+		name := strings.TrimPrefix(norm.NFC.String(f.Name), a.Root)
+		ext := strings.ToLower(path.Ext(name))
+		if ext != ".md" && ext != ".markdown" {
+			continue
+		}
+		note, err := a.readNote(f, name)
+		if err != nil {
+			continue
+		}
+		a.Notes = append(a.Notes, note)
+	}
+	return a, nil
+}
+
+// hiddenPath reports whether a path is inside (or is) a hidden file or folder
+func hiddenPath(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || part == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
+
+// commonRoot returns "Folder/" when every entry is inside the same top-level folder (a zipped folder)
+func commonRoot(entries []*zip.File) string {
+	root := ""
+	for _, f := range entries {
+		first, _, found := strings.Cut(norm.NFC.String(f.Name), "/")
+		if !found || (root != "" && root != first+"/") {
+			return ""
+		}
+		root = first + "/"
+	}
+	return root
+}
+
+func (a *Archive) readNote(f *zip.File, name string) (*Note, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(io.LimitReader(r, maxNoteSize))
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		data = bytes.ToValidUTF8(data, []byte("�"))
+	}
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	dir := path.Dir(name)
+	if dir == "." {
+		dir = ""
+	}
+	note := &Note{
+		Dir:      dir,
+		Title:    strings.TrimSuffix(path.Base(name), path.Ext(name)),
+		Created:  f.Modified,
+		Modified: f.Modified,
+		archive:  a,
+	}
+
+	// Optional front matter: title, tags, aliases and dates are kept, other properties are dropped
+	if rest, ok := strings.CutPrefix(content, "---\n"); ok {
+		if block, body, found := strings.Cut(rest, "\n---"); found {
+			var fm frontMatter
+			if yaml.Unmarshal([]byte(block), &fm) == nil {
+				content = strings.TrimPrefix(strings.TrimPrefix(body, "-"), "\n")
+				if strings.TrimSpace(fm.Title) != "" {
+					note.Title = strings.TrimSpace(fm.Title)
+				}
+				note.Tags = stringList(fm.Tags)
+				note.Aliases = stringList(fm.Aliases)
+				if t, ok := parseTime(fm.Created, fm.Date); ok {
+					note.Created = t
+				}
+				if t, ok := parseTime(fm.Updated); ok {
+					note.Modified = t
+				}
+			}
+		}
+	}
+	if note.Modified.Before(note.Created) {
+		note.Modified = note.Created
+	}
+	note.Title = norm.NFC.String(note.Title)
+	note.Content = strings.TrimLeft(content, "\n")
+	return note, nil
+}
+
+// stringList reads a front matter list given as a YAML list or a comma/space-separated string
+// Tags lose a leading "#" ("#project" -> "project")
+func stringList(value interface{}) []string {
+	var items []string
+	switch v := value.(type) {
+	case string:
+		items = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				items = append(items, s)
+			}
+		}
+	}
+
+	var result []string
+	for _, item := range items {
+		if item = strings.TrimPrefix(strings.TrimSpace(item), "#"); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// parseTime returns the first value that is a date or timestamp
+func parseTime(values ...string) (time.Time, bool) {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+			if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// RewriteLinks returns the content with links and embeds of files in the archive replaced:
+// resolve receives each linked file (not other notes) and returns its new URL
+// Obsidian embeds (![[image.png]]) become markdown images or links
+func (n *Note) RewriteLinks(resolve func(f *zip.File) (string, bool)) string {
+	content := linkPattern.ReplaceAllStringFunc(n.Content, func(link string) string {
+		m := linkPattern.FindStringSubmatch(link)
+		target := m[3] + m[4]
+		f := n.archive.find(n.Dir, target)
+		if f == nil {
+			return link
+		}
+		newURL, ok := resolve(f)
+		if !ok {
+			return link
+		}
+		return m[1] + "[" + m[2] + "](" + newURL + m[5] + ")"
+	})
+
+	return wikiPattern.ReplaceAllStringFunc(content, func(link string) string {
+		m := wikiPattern.FindStringSubmatch(link)
+		f := n.archive.find(n.Dir, strings.TrimSpace(m[2]))
+		if f == nil {
+			return link // Links between notes are kept as wiki-links
+		}
+		newURL, ok := resolve(f)
+		if !ok {
+			return link
+		}
+		text := m[4]
+		if text == "" || (m[1] == "!" && isSize(text)) {
+			text = path.Base(f.Name)
+		}
+		if m[1] == "!" && isImage(f.Name) {
+			return "![" + text + "](" + newURL + ")"
+		}
+		return "[" + text + "](" + newURL + ")"
+	})
+}
+
+// find resolves a link target to a file of the archive other than a note: relative to the note,
+// then from the top of the archive, then by file name anywhere (Obsidian's shortest path links)
+func (a *Archive) find(dir, target string) *zip.File {
+	lower := strings.ToLower(target)
+	if target == "" || strings.Contains(target, "://") || strings.HasPrefix(target, "#") ||
+		strings.HasPrefix(lower, "mailto:") || strings.HasPrefix(lower, "data:") {
+		return nil
+	}
+	if decoded, err := url.PathUnescape(target); err == nil {
+		target = decoded
+	}
+	target = norm.NFC.String(target)
+	if ext := strings.ToLower(path.Ext(target)); ext == ".md" || ext == ".markdown" || ext == "" {
+		return nil
+	}
+
+	candidates := []string{path.Join(dir, target), strings.TrimPrefix(path.Clean("/"+target), "/")}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, "..") {
+			continue
+		}
+		if f, ok := a.files[candidate]; ok {
+			return f
+		}
+	}
+	return a.byName[strings.ToLower(path.Base(target))]
+}
+
+// isImage reports whether a file is an image by its extension
+func isImage(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".svg", ".bmp", ".avif":
+		return true
+	}
+	return false
+}
+
+// isSize reports whether an embed alias is an Obsidian image size ("300" or "300x200")
+func isSize(text string) bool {
+	width, height, _ := strings.Cut(text, "x")
+	return width != "" && strings.Trim(width, "0123456789") == "" && strings.Trim(height, "0123456789") == ""
+}
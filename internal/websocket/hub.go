@@ -15,6 +15,11 @@ const (
 	MsgTypeNoteUpdated  = "note_updated"
 	MsgTypeNoteDeleted  = "note_deleted"
 	MsgTypeNotesRefresh = "notes_refresh"
+	MsgTypeMaintenance  = "maintenance"
+	MsgTypeNoteLocked   = "note_locked"
+	MsgTypeNoteUnlocked = "note_unlocked"
+
+	MsgTypeEncryptionProgress = "encryption_progress"
 )
 
 // Message represents a WebSocket message
@@ -50,7 +55,7 @@ type Hub struct {
 }
 
 type userMessage struct {
-	username string
+	username string // Empty = every connected user
 	message  Message
 }
 
@@ -99,7 +104,10 @@ func (h *Hub) Run() {
 
 		case msg := <-h.broadcast:
 			h.mu.RLock()
-			if clients, ok := h.clients[msg.username]; ok {
+			for username, clients := range h.clients {
+				if msg.username != "" && username != msg.username {
+					continue
+				}
 				for client := range clients {
 					select {
 					case client.send <- msg.message:
@@ -121,6 +129,11 @@ func (h *Hub) BroadcastToUser(username string, msg Message) {
 	}
 }
 
+// BroadcastToAll sends a message to every connected client (e.g. server announcements)
+func (h *Hub) BroadcastToAll(msg Message) {
+	h.broadcast <- userMessage{message: msg}
+}
+
 // HandleWebSocket handles WebSocket upgrade and connection
 func (h *Hub) HandleWebSocket(c *gin.Context) {
 	// Get username from context (set by auth middleware)
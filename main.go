@@ -83,6 +83,7 @@ Commands:
   restart     Restart the daemon
   status      Show daemon status
   run         Run in foreground (for debugging)
+  note        Add or list notes (note add "title" [-folder X] [-file content.md], note list)
 
 Options:
   -config string
@@ -91,6 +92,8 @@ Options:
         Show nginx reverse proxy configuration
   -reset-password string
         Reset password for specified username
+  -rotate-encryption string
+        Re-encrypt the notes of specified username with a new salt, optionally a new password (server must be stopped)
   -migrate-paths
         Migrate note paths from old separator (/) to new separator (:>:)
   -migrate-titles
@@ -111,6 +114,10 @@ Examples:
   gitnotepad -config my.yaml    # Use custom config
   gitnotepad -migrate-paths     # Manually run path migration
   gitnotepad -migrate-titles    # Migrate note titles for folder sharing
+  gitnotepad -rotate-encryption alice  # New encryption key for alice's notes
+  gitnotepad note add "Idea" -folder Inbox -file idea.md
+  echo "Call back" | gitnotepad note add "Todo"
+  gitnotepad note list -folder Inbox
 `
 
 func main() {
@@ -126,6 +133,9 @@ func main() {
 			// Run in foreground mode - continue with normal execution
 			explicitRun = true
 			os.Args = append([]string{os.Args[0]}, os.Args[2:]...)
+		case "note":
+			handleNoteCommand(os.Args[2:])
+			return
 		case "help", "-h", "--help":
 			fmt.Print(usageHelp)
 			return
@@ -135,6 +145,7 @@ func main() {
 	configPath := flag.String("config", "config.yaml", "Path to config file")
 	showNginx := flag.Bool("nginx", false, "Show nginx reverse proxy configuration")
 	resetPassword := flag.String("reset-password", "", "Reset password for specified username")
+	rotateEncryption := flag.String("rotate-encryption", "", "Re-encrypt the notes of specified username with a new salt, optionally a new password")
 	migratePaths := flag.Bool("migrate-paths", false, "Migrate note paths from old separator (/) to new separator (:>:)")
 	migrateTitles := flag.Bool("migrate-titles", false, "Migrate note titles to include folder path prefix (for folder sharing)")
 	daemonChild := flag.Bool("daemon-child", false, "Internal flag for daemon child process")
@@ -162,6 +173,12 @@ func main() {
 		return
 	}
 
+	// Handle encryption key rotation
+	if *rotateEncryption != "" {
+		handleEncryptionRotation(*configPath, *rotateEncryption)
+		return
+	}
+
 	// Handle path migration
 	if *migratePaths {
 		handlePathMigration(*configPath)
@@ -285,6 +302,18 @@ func main() {
 	} else if bot != nil {
 		// Set WebSocket hub for real-time note list updates
 		bot.SetHub(srv.GetHub())
+		// Reply in the language chosen by the user notes are saved as
+		bot.SetPreferences(repository.NewPreferenceRepository(srv.GetDB().DB))
+		// Remember the folder each chat chose with /folder
+		bot.SetChats(repository.NewTelegramChatRepository(srv.GetDB().DB))
+		// Do not save into read-only accounts, nor store files for accounts without uploads
+		bot.SetUsers(repository.NewUserRepository(srv.GetDB().DB))
+		// Find notes for /search, /recent and /get, linking them by short links
+		bot.SetNotes(srv.GetNoteIndex(), srv.GetShortLinks())
+		// Push note events to the chats in telegram.notify
+		bot.SetEvents(srv.GetEvents())
+		// Do not save notes while the server is read-only
+		bot.SetMaintenance(srv.GetMaintenance())
 		go bot.Start()
 		defer bot.Stop()
 	}
@@ -410,6 +439,112 @@ func handlePasswordReset(configPath, username string) {
 	fmt.Printf("Password for user '%s' has been reset successfully.\n", username)
 }
 
+// handleEncryptionRotation re-encrypts a user's notes with a key derived from a new salt, and optionally a new password
+// The server must be stopped, since its sessions keep the old key
+func handleEncryptionRotation(configPath, username string) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if !cfg.Encryption.Enabled {
+		log.Fatal("Encryption is not enabled")
+	}
+	if daemon.New(cfg, configPath).IsRunning() {
+		log.Fatal("The server is running: stop it first, or use POST /api/admin/encryption/rotate")
+	}
+
+	db, err := database.New(cfg.Database.Path)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	userRepo := repository.NewUserRepository(db.DB)
+	user, err := userRepo.GetByUsername(username)
+	if err != nil {
+		log.Fatalf("Failed to find user: %v", err)
+	}
+	if user == nil {
+		log.Fatalf("User '%s' not found", username)
+	}
+
+	fmt.Printf("Rotating encryption key for user: %s (ID: %d)\n", user.Username, user.ID)
+	fmt.Print("Current password: ")
+	passwordBytes, err := term.ReadPassword(int(syscall.Stdin))
+	if err != nil {
+		log.Fatalf("Failed to read password: %v", err)
+	}
+	fmt.Println()
+	password := string(passwordBytes)
+	if !user.CheckPassword(password) {
+		log.Fatal("Invalid password")
+	}
+
+	fmt.Print("New password (empty to keep the current one): ")
+	newBytes, err := term.ReadPassword(int(syscall.Stdin))
+	if err != nil {
+		log.Fatalf("Failed to read password: %v", err)
+	}
+	fmt.Println()
+	newPassword := string(newBytes)
+	if newPassword != "" {
+		fmt.Print("Confirm new password: ")
+		confirmBytes, err := term.ReadPassword(int(syscall.Stdin))
+		if err != nil {
+			log.Fatalf("Failed to read password confirmation: %v", err)
+		}
+		fmt.Println()
+		if newPassword != string(confirmBytes) {
+			log.Fatal("Passwords do not match")
+		}
+	}
+
+	oldKey, err := encryption.DeriveKey(password, user.KeySalt(cfg.Encryption.Salt))
+	if err != nil {
+		log.Fatalf("Failed to derive key: %v", err)
+	}
+	salt, err := encryption.GenerateSalt()
+	if err != nil {
+		log.Fatalf("Failed to generate salt: %v", err)
+	}
+	keyPassword := newPassword
+	if keyPassword == "" {
+		keyPassword = password
+	}
+	newKey, err := encryption.DeriveKey(keyPassword, salt)
+	if err != nil {
+		log.Fatalf("Failed to derive key: %v", err)
+	}
+
+	userPath := user.GetStoragePath(cfg.Storage.Path)
+	result, err := handler.ReencryptStorage(userPath, oldKey, newKey, func(done, total int) {
+		fmt.Printf("\rRe-encrypting files: %d/%d", done, total)
+	})
+	fmt.Println()
+	if err != nil {
+		log.Fatalf("Re-encryption failed, no files were changed: %v", err)
+	}
+
+	user.EncryptionSalt = salt
+	if newPassword != "" {
+		if err := user.SetPassword(newPassword); err != nil {
+			log.Fatalf("Failed to hash password: %v", err)
+		}
+	}
+	if err := userRepo.Update(user); err != nil {
+		if _, rollbackErr := handler.ReencryptStorage(userPath, newKey, oldKey, nil); rollbackErr != nil {
+			log.Fatalf("Failed to update user (%v) and to restore the old encryption: %v", err, rollbackErr)
+		}
+		log.Fatalf("Failed to update user, the old encryption was restored: %v", err)
+	}
+
+	fmt.Printf("Re-encrypted %d files for user '%s'", result.Reencrypted, username)
+	if result.Skipped > 0 {
+		fmt.Printf(" (%d encrypted with another key were left as they are)", result.Skipped)
+	}
+	fmt.Println(".")
+}
+
 // needsInitialSetup checks if initial setup is required (admin password not set)
 func needsInitialSetup(configPath string) bool {
 	// Check if config file exists
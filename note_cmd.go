@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/user/gitnotepad/internal/config"
+	"github.com/user/gitnotepad/internal/daemon"
+	"github.com/user/gitnotepad/internal/database"
+	"github.com/user/gitnotepad/internal/encryption"
+	"github.com/user/gitnotepad/internal/handler"
+	"github.com/user/gitnotepad/internal/repository"
+	"golang.org/x/term"
+)
+
+const noteUsage = `Usage:
+  gitnotepad note add "title" [-folder X] [-file content.md] [-tags a,b] [-type markdown|txt|asciidoc]
+  gitnotepad note list [-folder X]
+
+Content is read from -file ("-" for stdin), or from stdin when it is piped.
+
+While the server is running, notes go through its API with a personal API token
+(-token or GITNOTEPAD_TOKEN; create one in Settings > Data > API Tokens).
+Otherwise they are read and written directly in storage.
+
+Options:
+  -config string   Path to config file (default "config.yaml")
+  -server string   Server URL (default GITNOTEPAD_URL, or the local server from the config)
+  -token string    API token (default GITNOTEPAD_TOKEN)
+  -user string     User whose notes are used without the server (default: the admin user)
+`
+
+// noteOptions are the flags shared by the note subcommands
+type noteOptions struct {
+	configPath string
+	server     string
+	token      string
+	user       string
+	folder     string
+}
+
+func (o *noteOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.configPath, "config", "config.yaml", "Path to config file")
+	fs.StringVar(&o.server, "server", os.Getenv("GITNOTEPAD_URL"), "Server URL")
+	fs.StringVar(&o.token, "token", os.Getenv("GITNOTEPAD_TOKEN"), "API token")
+	fs.StringVar(&o.user, "user", "", "User whose notes are used without the server")
+	fs.StringVar(&o.folder, "folder", "", "Folder path")
+}
+
+// handleNoteCommand runs "gitnotepad note add|list"
+func handleNoteCommand(args []string) {
+	if len(args) == 0 || (args[0] != "add" && args[0] != "list") {
+		fmt.Fprint(os.Stderr, noteUsage)
+		os.Exit(2)
+	}
+	cmd := args[0]
+
+	var opts noteOptions
+	fs := flag.NewFlagSet("note "+cmd, flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, noteUsage) }
+	opts.register(fs)
+	file := fs.String("file", "", "File with the note content (- = stdin)")
+	tags := fs.String("tags", "", "Comma-separated tags")
+	noteType := fs.String("type", "", "Note type (markdown, txt, asciidoc)")
+	positional := parseInterspersed(fs, args[1:])
+
+	cfg := loadConfigOrDefault(opts.configPath)
+
+	switch cmd {
+	case "add":
+		if len(positional) != 1 || strings.TrimSpace(positional[0]) == "" {
+			log.Fatal("Usage: gitnotepad note add \"title\" [-folder X] [-file content.md]")
+		}
+		content, err := readNoteContent(*file)
+		if err != nil {
+			log.Fatalf("Failed to read content: %v", err)
+		}
+		addNote(cfg, &opts, positional[0], content, *noteType, splitTags(*tags))
+	case "list":
+		if len(positional) != 0 {
+			log.Fatal("Usage: gitnotepad note list [-folder X]")
+		}
+		listNotes(cfg, &opts)
+	}
+}
+
+// parseInterspersed parses flags placed before and after positional arguments, and returns the positional ones
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func loadConfigOrDefault(configPath string) *config.Config {
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return config.Default()
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	return cfg
+}
+
+// readNoteContent reads the note body from a file, from stdin for "-", or from stdin when it is piped
+func readNoteContent(file string) (string, error) {
+	var data []byte
+	var err error
+	switch {
+	case file == "-":
+		data, err = io.ReadAll(os.Stdin)
+	case file != "":
+		data, err = os.ReadFile(file)
+	case !term.IsTerminal(int(os.Stdin.Fd())):
+		data, err = io.ReadAll(os.Stdin)
+	}
+	return string(data), err
+}
+
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// serverURL returns the URL of the server to use, or "" to work directly in storage
+func serverURL(cfg *config.Config, opts *noteOptions) string {
+	if opts.server != "" {
+		return strings.TrimSuffix(opts.server, "/")
+	}
+	if !daemon.New(cfg, opts.configPath).IsRunning() {
+		return ""
+	}
+	host := cfg.Server.Host
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	scheme := "http://"
+	if cfg.Server.TLS.Enabled {
+		scheme = "https://"
+	}
+	return scheme + net.JoinHostPort(host, strconv.Itoa(cfg.Server.Port)) + cfg.Server.BasePath
+}
+
+func addNote(cfg *config.Config, opts *noteOptions, title, content, noteType string, tags []string) {
+	if base := serverURL(cfg, opts); base != "" {
+		var note handler.NoteListItem
+		err := callAPI(cfg, opts, http.MethodPost, base+"/api/v1/notes", map[string]interface{}{
+			"title":       title,
+			"folder_path": opts.folder,
+			"content":     content,
+			"type":        noteType,
+			"tags":        tags,
+		}, &note)
+		if err != nil {
+			log.Fatalf("Failed to create note: %v", err)
+		}
+		fmt.Println(note.ID)
+		return
+	}
+
+	notes, closeDB := openStorageNotes(cfg, opts)
+	defer closeDB()
+	note, err := notes.Add(title, opts.folder, content, noteType, tags)
+	if err != nil {
+		log.Fatalf("Failed to create note: %v", err)
+	}
+	fmt.Println(note.ID)
+}
+
+func listNotes(cfg *config.Config, opts *noteOptions) {
+	var notes []handler.NoteListItem
+	if base := serverURL(cfg, opts); base != "" {
+		endpoint := base + "/api/v1/notes"
+		if opts.folder != "" {
+			endpoint += "?folder=" + url.QueryEscape(opts.folder)
+		}
+		if err := callAPI(cfg, opts, http.MethodGet, endpoint, nil, &notes); err != nil {
+			log.Fatalf("Failed to list notes: %v", err)
+		}
+	} else {
+		storage, closeDB := openStorageNotes(cfg, opts)
+		defer closeDB()
+		var err error
+		if notes, err = storage.List(opts.folder); err != nil {
+			log.Fatalf("Failed to list notes: %v", err)
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, note := range notes {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", note.Modified.Local().Format("2006-01-02 15:04"), note.ID, note.Title)
+	}
+	w.Flush()
+}
+
+// callAPI sends a JSON request with the API token and decodes the JSON response into out
+func callAPI(cfg *config.Config, opts *noteOptions, method, endpoint string, body, out interface{}) error {
+	if opts.token == "" && cfg.Auth.Enabled {
+		return fmt.Errorf("the server requires an API token: set GITNOTEPAD_TOKEN or -token (Settings > Data > API Tokens)")
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if opts.token != "" {
+		req.Header.Set("Authorization", "Bearer "+opts.token)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	if cfg.Server.TLS.Enabled && opts.server == "" {
+		// The local server's certificate is issued for its public host name, not the loopback address
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Error == "" {
+			apiErr.Error = resp.Status
+		}
+		return fmt.Errorf("%s", apiErr.Error)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// openStorageNotes opens the user's notes in storage, asking for the password when notes are encrypted
+func openStorageNotes(cfg *config.Config, opts *noteOptions) (*handler.StorageNotes, func()) {
+	db, err := database.New(cfg.Database.Path)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	username := ""
+	if cfg.Auth.Enabled {
+		username = opts.user
+		if username == "" {
+			username = cfg.Auth.AdminUsername
+		}
+	}
+
+	var key []byte
+	if cfg.Encryption.Enabled {
+		fmt.Fprintf(os.Stderr, "Password for %s: ", username)
+		password, err := term.ReadPassword(int(syscall.Stdin))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			log.Fatalf("Failed to read password: %v", err)
+		}
+		salt := cfg.Encryption.Salt
+		if cfg.Auth.Enabled {
+			user, err := repository.NewUserRepository(db.DB).GetByUsername(username)
+			if err != nil || user == nil || !user.CheckPassword(string(password)) {
+				log.Fatal("Invalid username or password")
+			}
+			salt = user.KeySalt(salt)
+		}
+		if key, err = encryption.DeriveKey(string(password), salt); err != nil {
+			log.Fatalf("Failed to derive encryption key: %v", err)
+		}
+	} else if cfg.Auth.Enabled {
+		user, err := repository.NewUserRepository(db.DB).GetByUsername(username)
+		if err != nil || user == nil {
+			log.Fatalf("User '%s' not found", username)
+		}
+	}
+
+	return handler.NewStorageNotes(cfg, db, username, key), func() { db.Close() }
+}
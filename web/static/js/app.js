@@ -220,6 +220,7 @@ let currentAttachments = []; // Track attachments for current note
 let currentTags = []; // Track tags for current note
 let allTags = []; // All available tags from API for autocomplete
 let isViewMode = true; // View mode by default (preview only)
+let userTimezone = ''; // IANA time zone preference of the signed-in user ('' = browser default)
 
 // CodeMirror Editor
 let cmEditor = null;
@@ -227,9 +228,11 @@ let cmEditorReady = false;
 
 // Auto-save
 let autoSaveTimer = null;
+let draftTimer = null; // Saves a server-side draft while auto-save is off
 let hasUnsavedChanges = false;
 let isSaving = false; // Prevent duplicate saves
 let autoSaveEnabled = localStorage.getItem('autoSaveEnabled') === 'true'; // Default: disabled
+let showArchived = localStorage.getItem('showArchived') === 'true'; // Archived notes are hidden by default
 const AUTO_SAVE_DELAY = 2000; // 2 seconds
 
 // Original content tracking (to prevent unnecessary saves)
@@ -246,6 +249,11 @@ let ws = null;
 let wsReconnectTimer = null;
 const WS_RECONNECT_DELAY = 3000; // 3 seconds
 
+// Edit lock of the note open in the editor, so other clients see it is being edited
+let noteLock = null; // { noteId, lockId, renewTimer }
+let noteLockExpiry = null; // Hides the "being edited by" notice when the other lock expires
+const NOTE_LOCK_RENEW = 60000; // 1 minute (locks expire on the server after 2 minutes)
+
 // Initialize WebSocket connection
 function initWebSocket() {
     // Determine WebSocket URL based on current page URL
@@ -262,6 +270,8 @@ function initWebSocket() {
                 clearTimeout(wsReconnectTimer);
                 wsReconnectTimer = null;
             }
+            // Maintenance may have been switched while disconnected
+            loadMaintenanceStatus();
         };
 
         ws.onmessage = (event) => {
@@ -304,11 +314,106 @@ function handleWebSocketMessage(message) {
                 renderMiniCalendar();
             });
             break;
+        case 'maintenance':
+            updateMaintenanceBanner(message.data);
+            break;
+        case 'note_locked':
+        case 'note_unlocked':
+            // Locks claimed by this session are not shown
+            if (!currentNote || message.noteId !== currentNote.id || !message.data) break;
+            if (noteLock && message.data.lock_id === noteLock.lockId) break;
+            showNoteLockStatus(message.type === 'note_locked' ? message.data : null);
+            break;
         default:
             console.log('Unknown WebSocket message type:', message.type);
     }
 }
 
+// Claim (or renew) the edit lock of the note being edited
+async function claimNoteLock(noteId) {
+    if (noteLock && noteLock.noteId !== noteId) {
+        releaseNoteLock();
+    }
+    if (!noteId || noteId.startsWith('offline-')) return;
+
+    if (noteLock) {
+        clearTimeout(noteLock.renewTimer);
+    } else {
+        noteLock = { noteId, lockId: null };
+    }
+    noteLock.renewTimer = setTimeout(() => claimNoteLock(noteId), NOTE_LOCK_RENEW);
+
+    try {
+        const response = await fetch(`${basePath}/api/notes/${encodeNoteId(noteId)}/lock`, { method: 'POST' });
+        const data = await response.json();
+        if (!noteLock || noteLock.noteId !== noteId) return;
+        if (response.ok) {
+            noteLock.lockId = data.lock_id;
+            showNoteLockStatus(null);
+        } else if (response.status === 423) {
+            // Someone else is editing: keep trying, the note is ours once their lock is released or expires
+            showNoteLockStatus(data);
+        }
+    } catch (e) {
+        // Locks are best effort (e.g. offline)
+    }
+}
+
+// Release the edit lock when the note is closed or only viewed
+function releaseNoteLock() {
+    if (!noteLock) return;
+    clearTimeout(noteLock.renewTimer);
+    const { noteId } = noteLock;
+    noteLock = null;
+    showNoteLockStatus(null);
+    fetch(`${basePath}/api/notes/${encodeNoteId(noteId)}/lock`, { method: 'DELETE', keepalive: true }).catch(() => {});
+}
+
+// Show who else is editing the open note (null hides the notice)
+function showNoteLockStatus(lock) {
+    const statusEl = document.getElementById('noteLockStatus');
+    if (!statusEl) return;
+    if (noteLockExpiry) {
+        clearTimeout(noteLockExpiry);
+        noteLockExpiry = null;
+    }
+    if (!lock) {
+        statusEl.style.display = 'none';
+        statusEl.textContent = '';
+        return;
+    }
+    statusEl.textContent = lock.locked_by
+        ? i18n.t('lock.editedBy', { user: lock.locked_by })
+        : i18n.t('lock.editedElsewhere');
+    statusEl.style.display = '';
+    noteLockExpiry = setTimeout(() => showNoteLockStatus(null), Math.max(new Date(lock.expires_at) - Date.now(), 0));
+}
+
+// Load the maintenance (read-only) state of the server
+async function loadMaintenanceStatus() {
+    try {
+        const response = await fetch(`${basePath}/api/maintenance`);
+        if (!response.ok) return;
+        updateMaintenanceBanner(await response.json());
+    } catch (e) {
+        console.error('Failed to load maintenance status:', e);
+    }
+}
+
+// Show or hide the banner telling users that changes cannot be saved
+function updateMaintenanceBanner(status) {
+    const banner = document.getElementById('maintenanceBanner');
+    if (!banner) return;
+    if (!status || !status.enabled) {
+        banner.style.display = 'none';
+        document.body.classList.remove('maintenance-mode');
+        return;
+    }
+    banner.textContent = i18n.t('maintenance.banner') + (status.message ? ' ' + status.message : '');
+    banner.style.display = '';
+    document.body.classList.add('maintenance-mode');
+}
+
 // DOM Elements
 const noteList = document.getElementById('noteList');
 const searchInput = document.getElementById('searchInput');
@@ -329,6 +434,7 @@ const closeNoteBtn = document.getElementById('closeNoteBtn');
 const prettyJsonBtn = document.getElementById('prettyJsonBtn');
 const syntaxHelpBtn = document.getElementById('syntaxHelpBtn');
 const historyBtn = document.getElementById('historyBtn');
+const printBtn = document.getElementById('printBtn');
 const themeToggle = document.getElementById('themeToggle');
 
 // Modals
@@ -480,7 +586,10 @@ document.addEventListener('DOMContentLoaded', async () => {
     initEditorHeaderScroll();
     initFontSize();
     initWebSocket(); // Real-time sync
+    window.addEventListener('pagehide', releaseNoteLock); // Free the edit lock when the tab closes
+    initOfflineSupport();
     await loadFolderOrder();
+    await loadNoteSort();
     await loadAllTags();
     // Load notes (includes folder icons)
     loadNotes().then(() => {
@@ -1211,6 +1320,10 @@ function createShareModal() {
                 <input type="text" id="shareLinkInput" readonly>
                 <button id="copyLinkBtn" class="btn btn-primary" data-i18n="share.copy">Copy</button>
             </div>
+            <div style="display: flex; gap: 0.5rem; align-items: center; margin-top: 0.5rem;">
+                <input type="text" id="shareLinkCustomCode" data-i18n-placeholder="share.customCodePlaceholder" placeholder="Custom code, e.g. meeting-notes" style="flex: 1; padding: 0.375rem 0.5rem; border-radius: var(--radius); border: 1px solid var(--border); background: var(--background); color: var(--foreground); font-size: 0.875rem;">
+                <button id="shareLinkCustomCodeBtn" class="btn btn-secondary" data-i18n="share.setCustomCode">Change</button>
+            </div>
             <div class="share-expiry-container" style="margin-top: 1rem;">
                 <label style="display: block; margin-bottom: 0.5rem; font-size: 0.875rem; color: var(--text-secondary);" data-i18n="share.expiration">Link expiration:</label>
                 <div style="display: flex; gap: 1rem; align-items: center; flex-wrap: wrap;">
@@ -1240,6 +1353,15 @@ function createShareModal() {
                 </div>
                 <div id="shareLinkVisibilityInfo" style="margin-top: 0.5rem; font-size: 0.75rem; color: var(--text-secondary);"></div>
             </div>
+            <div id="shareLinkPasswordContainer" style="margin-top: 1rem; padding-top: 1rem; border-top: 1px solid var(--border); display: none;">
+                <label style="display: block; margin-bottom: 0.5rem; font-size: 0.875rem; color: var(--text-secondary);" data-i18n="share.password">Link password:</label>
+                <div style="display: flex; gap: 0.5rem; align-items: center;">
+                    <input type="password" id="shareLinkPassword" autocomplete="new-password" data-i18n-placeholder="share.passwordPlaceholder" placeholder="Optional password" style="flex: 1; padding: 0.375rem 0.5rem; border-radius: var(--radius); border: 1px solid var(--border); background: var(--background); color: var(--foreground); font-size: 0.875rem;">
+                    <button id="shareLinkPasswordSetBtn" class="btn btn-secondary" data-i18n="share.setPassword">Set</button>
+                    <button id="shareLinkPasswordRemoveBtn" class="btn btn-secondary" data-i18n="share.removePassword">Remove</button>
+                </div>
+                <div id="shareLinkPasswordInfo" style="margin-top: 0.5rem; font-size: 0.75rem; color: var(--text-secondary);"></div>
+            </div>
             <div id="shareLinkStatus" class="share-status"></div>
             <div class="modal-actions">
                 <button id="regenerateLinkBtn" class="btn btn-secondary" data-i18n="share.regenerate">Regenerate</button>
@@ -1291,6 +1413,14 @@ function createShareModal() {
     visibilityPrivate.addEventListener('change', updateShareLinkVisibility);
     visibilityPublic.addEventListener('change', updateShareLinkVisibility);
 
+    // Passphrase of public links
+    document.getElementById('shareLinkPasswordSetBtn').addEventListener('click', () => {
+        const password = document.getElementById('shareLinkPassword').value;
+        if (password) updateShareLinkPassword(password);
+    });
+    document.getElementById('shareLinkPasswordRemoveBtn').addEventListener('click', () => updateShareLinkPassword(''));
+    document.getElementById('shareLinkCustomCodeBtn').addEventListener('click', updateShareLinkCode);
+
     // Set min date to tomorrow
     const tomorrow = new Date();
     tomorrow.setDate(tomorrow.getDate() + 1);
@@ -1601,6 +1731,8 @@ async function showShareModal() {
     expiryDateInput.disabled = true;
     expiryDateInput.value = '';
     visibilityPrivate.checked = true;
+    showShareLinkPassword(false, false);
+    document.getElementById('shareLinkCustomCode').value = '';
 
     try {
         // Try to get existing short link first
@@ -1641,6 +1773,7 @@ async function showShareModal() {
                 visibilityPrivate.checked = true;
                 visibilityInfo.textContent = i18n.t('share.privateInfo');
             }
+            showShareLinkPassword(data.isPublic, data.hasPassword);
         } else {
             input.value = '';
             status.textContent = i18n.t('share.failedToGenerate');
@@ -1735,11 +1868,13 @@ async function updateShareLinkVisibility() {
         });
 
         if (response.ok) {
+            const data = await response.json();
             if (isPublic) {
                 visibilityInfo.textContent = i18n.t('share.publicInfo');
             } else {
                 visibilityInfo.textContent = i18n.t('share.privateInfo');
             }
+            showShareLinkPassword(isPublic, data.hasPassword);
             status.textContent = i18n.t('share.visibilityUpdated');
             status.className = 'share-status success';
             setTimeout(() => { status.textContent = ''; }, 2000);
@@ -1820,6 +1955,7 @@ async function regenerateShortLink() {
             } else {
                 visibilityInfo.textContent = i18n.t('share.privateInfo');
             }
+            showShareLinkPassword(isPublic, data.hasPassword);
         }
     } catch (error) {
         console.error('Failed to regenerate link:', error);
@@ -1828,6 +1964,76 @@ async function regenerateShortLink() {
     }
 }
 
+// Replace the code of the note's link with a custom one (/s/<code>)
+async function updateShareLinkCode() {
+    if (!currentNote) return;
+
+    const input = document.getElementById('shareLinkInput');
+    const codeInput = document.getElementById('shareLinkCustomCode');
+    const status = document.getElementById('shareLinkStatus');
+    const customCode = codeInput.value.trim();
+    if (!customCode) return;
+
+    try {
+        const response = await fetch(`${basePath}/api/notes/${encodeNoteId(currentNote.id)}/shortlink`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ custom_code: customCode })
+        });
+        const data = await response.json();
+
+        if (response.ok) {
+            input.value = `${window.location.origin}${data.shortLink}`;
+            codeInput.value = '';
+            status.textContent = i18n.t('share.customCodeUpdated');
+            status.className = 'share-status success';
+            setTimeout(() => { status.textContent = ''; }, 2000);
+        } else {
+            status.textContent = data.error || i18n.t('share.errorUpdating');
+            status.className = 'share-status error';
+        }
+    } catch (error) {
+        console.error('Failed to change link code:', error);
+        status.textContent = i18n.t('share.errorUpdating');
+        status.className = 'share-status error';
+    }
+}
+
+// Show the passphrase row of the share modal (public links only)
+function showShareLinkPassword(isPublic, hasPassword) {
+    document.getElementById('shareLinkPasswordContainer').style.display = isPublic ? 'block' : 'none';
+    document.getElementById('shareLinkPassword').value = '';
+    document.getElementById('shareLinkPasswordRemoveBtn').style.display = hasPassword ? '' : 'none';
+    document.getElementById('shareLinkPasswordInfo').textContent =
+        i18n.t(hasPassword ? 'share.passwordSetInfo' : 'share.noPasswordInfo');
+}
+
+// Set ('' removes) the passphrase visitors of the public link must enter
+async function updateShareLinkPassword(password) {
+    if (!currentNote) return;
+
+    const status = document.getElementById('shareLinkStatus');
+    try {
+        const response = await fetch(`${basePath}/api/notes/${encodeNoteId(currentNote.id)}/shortlink`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ password })
+        });
+
+        if (response.ok) {
+            const data = await response.json();
+            showShareLinkPassword(data.isPublic, data.hasPassword);
+            status.textContent = i18n.t('share.passwordUpdated');
+            status.className = 'share-status success';
+            setTimeout(() => { status.textContent = ''; }, 2000);
+        }
+    } catch (error) {
+        console.error('Failed to update link password:', error);
+        status.textContent = i18n.t('share.errorUpdating');
+        status.className = 'share-status error';
+    }
+}
+
 function initRootDropZone() {
     // Make root note list a drop target for moving notes to root
     noteList.addEventListener('dragover', handleDragOver);
@@ -1989,6 +2195,22 @@ function initMarked() {
         return `<pre>${langLabel}${copyBtn}<code class="hljs ${langClass}" data-line-count="${lineCount}">${numberedLines}</code></pre>`;
     };
 
+    // Video and audio attachments embedded like images play inline
+    renderer.image = function(href, title, text) {
+        // Handle object format (newer marked versions)
+        if (typeof href === 'object') {
+            title = href.title;
+            text = href.text;
+            href = href.href;
+        }
+        const media = mediaKind(href);
+        const titleAttr = title ? ` title="${title}"` : '';
+        if (media) {
+            return `<${media} src="${href}"${titleAttr} controls preload="metadata"></${media}>`;
+        }
+        return `<img src="${href}" alt="${text}"${titleAttr}>`;
+    };
+
     // Custom renderer for links - open in new tab
     renderer.link = function(href, title, text) {
         // Handle object format (newer marked versions)
@@ -2032,6 +2254,12 @@ function initContextMenu() {
         <div class="context-menu-item" data-action="change-icon">
             <span class="context-icon">&#127912;</span> <span data-i18n="context.changeIcon">Change Icon</span>
         </div>
+        <div class="context-menu-item" data-action="archive" id="context-archive-item">
+            <span class="context-icon">&#128230;</span> <span data-i18n="context.archive">Archive</span>
+        </div>
+        <div class="context-menu-item" data-action="unarchive" id="context-unarchive-item" style="display: none;">
+            <span class="context-icon">&#128228;</span> <span data-i18n="context.unarchive">Unarchive</span>
+        </div>
         <div class="context-menu-divider"></div>
         <div class="context-menu-item" data-action="history">
             <span class="context-icon">&#128337;</span> <span data-i18n="context.history">History</span>
@@ -2039,6 +2267,15 @@ function initContextMenu() {
         <div class="context-menu-item" data-action="info">
             <span class="context-icon">&#9432;</span> <span data-i18n="context.info">Info</span>
         </div>
+        <div class="context-menu-item" data-action="export" data-format="html">
+            <span class="context-icon">&#128190;</span> <span data-i18n="context.exportHtml">Export as HTML</span>
+        </div>
+        <div class="context-menu-item" data-action="export" data-format="pdf">
+            <span class="context-icon">&#128190;</span> <span data-i18n="context.exportPdf">Export as PDF</span>
+        </div>
+        <div class="context-menu-item" data-action="export" data-format="md">
+            <span class="context-icon">&#128190;</span> <span data-i18n="context.exportSource">Export Source</span>
+        </div>
         <div class="context-menu-item" data-action="decrypt" id="context-decrypt-item" style="display: none;">
             <span class="context-icon">&#128275;</span> <span data-i18n="context.decrypt">Remove Encryption</span>
         </div>
@@ -2050,6 +2287,19 @@ function initContextMenu() {
     contextMenu.style.display = 'none';
     document.body.appendChild(contextMenu);
 
+    // Note sort items, shared by the sidebar (top-level notes) and folder menus
+    const noteSortMenuItems = `
+        <div class="context-menu-item" data-action="sort-notes" data-field="title">
+            <span class="context-icon">&#8645;</span> <span data-i18n="context.sortByTitle">Sort Notes by Title</span>
+        </div>
+        <div class="context-menu-item" data-action="sort-notes" data-field="modified">
+            <span class="context-icon">&#8645;</span> <span data-i18n="context.sortByModified">Sort Notes by Modified</span>
+        </div>
+        <div class="context-menu-item" data-action="sort-notes" data-field="created">
+            <span class="context-icon">&#8645;</span> <span data-i18n="context.sortByCreated">Sort Notes by Created</span>
+        </div>
+    `;
+
     // Create sidebar context menu (for empty area)
     sidebarContextMenu = document.createElement('div');
     sidebarContextMenu.className = 'context-menu';
@@ -2060,6 +2310,17 @@ function initContextMenu() {
         <div class="context-menu-item" data-action="new-folder">
             <span class="context-icon">&#128193;</span> <span data-i18n="context.newFolder">New Folder</span>
         </div>
+        <div class="context-menu-item" data-action="clip-page">
+            <span class="context-icon">&#128279;</span> <span data-i18n="context.clipPage">Clip Web Page</span>
+        </div>
+        <div class="context-menu-item" data-action="toggle-archived" id="context-show-archived-item">
+            <span class="context-icon">&#128230;</span> <span data-i18n="context.showArchived">Show Archived Notes</span>
+        </div>
+        <div class="context-menu-item" data-action="toggle-archived" id="context-hide-archived-item" style="display: none;">
+            <span class="context-icon">&#128230;</span> <span data-i18n="context.hideArchived">Hide Archived Notes</span>
+        </div>
+        <div class="context-menu-divider"></div>
+        ${noteSortMenuItems}
     `;
     sidebarContextMenu.style.display = 'none';
     document.body.appendChild(sidebarContextMenu);
@@ -2074,6 +2335,9 @@ function initContextMenu() {
         <div class="context-menu-item" data-action="new-subfolder">
             <span class="context-icon">&#128193;</span> <span data-i18n="context.newSubfolder">New Subfolder</span>
         </div>
+        <div class="context-menu-item" data-action="clip-page">
+            <span class="context-icon">&#128279;</span> <span data-i18n="context.clipPage">Clip Web Page</span>
+        </div>
         <div class="context-menu-item" data-action="rename-folder">
             <span class="context-icon">&#9998;</span> <span data-i18n="context.renameFolder">Rename Folder</span>
         </div>
@@ -2088,6 +2352,8 @@ function initContextMenu() {
             <span class="context-icon">&#9660;</span> <span data-i18n="context.moveFolderDown">Move Down</span>
         </div>
         <div class="context-menu-divider"></div>
+        ${noteSortMenuItems}
+        <div class="context-menu-divider"></div>
         <div class="context-menu-item" data-action="expand-folder">
             <span class="context-icon">&#9654;</span> <span data-i18n="context.expand">Expand</span>
         </div>
@@ -2174,6 +2440,8 @@ function showContextMenu(e, noteId) {
     if (decryptItem) {
         decryptItem.style.display = note.encrypted ? 'flex' : 'none';
     }
+    document.getElementById('context-archive-item').style.display = note.archived ? 'none' : 'flex';
+    document.getElementById('context-unarchive-item').style.display = note.archived ? 'flex' : 'none';
 
     // Position context menu
     const x = e.clientX;
@@ -2231,6 +2499,15 @@ async function handleContextMenuAction(e) {
             await duplicateNote(contextTarget);
             break;
 
+        case 'export':
+            await exportNote(contextTarget, e.target.closest('.context-menu-item').dataset.format);
+            break;
+
+        case 'archive':
+        case 'unarchive':
+            await setNoteArchived(contextTarget, action === 'archive');
+            break;
+
         case 'move':
             showMoveNoteModal(note);
             break;
@@ -2319,6 +2596,17 @@ async function showNoteInfo(note) {
         // No shortlink or error - ignore
     }
 
+    // Get notes linking here with [[title]] or [[note-ID]]
+    let backlinks = [];
+    try {
+        const response = await authFetch(`/api/notes/${encodeNoteId(note.id)}/backlinks`);
+        if (response.ok) {
+            backlinks = (await response.json()).backlinks || [];
+        }
+    } catch (e) {
+        // Backlinks are optional - ignore
+    }
+
     // Format note type
     const typeLabels = {
         'markdown': 'Markdown',
@@ -2363,16 +2651,22 @@ async function showNoteInfo(note) {
                     <span class="note-info-label">${i18n.t('noteInfo.shared') || 'Shared'}:</span>
                     <span class="note-info-value">${hasShortlink ? '🔗 ' + (i18n.t('noteInfo.yes') || 'Yes') + ` (/${shortlinkInfo.code})` : i18n.t('noteInfo.no') || 'No'}</span>
                 </div>
+                <div class="note-info-row">
+                    <span class="note-info-label">${i18n.t('noteInfo.backlinks') || 'Backlinks'}:</span>
+                    <span class="note-info-value note-info-backlinks">${backlinks.length > 0
+                        ? backlinks.map(b => `<a href="#" data-note-id="${escapeHtml(b.id)}">${escapeHtml(b.title || b.id)}</a>`).join('')
+                        : i18n.t('noteInfo.none') || 'None'}</span>
+                </div>
                 ${noteDetail.created ? `
                 <div class="note-info-row">
                     <span class="note-info-label">${i18n.t('noteInfo.created') || 'Created'}:</span>
-                    <span class="note-info-value">${new Date(noteDetail.created).toLocaleString()}</span>
+                    <span class="note-info-value">${new Date(noteDetail.created).toLocaleString(undefined, { timeZone: userTimezone || undefined })}</span>
                 </div>
                 ` : ''}
                 ${noteDetail.modified ? `
                 <div class="note-info-row">
                     <span class="note-info-label">${i18n.t('noteInfo.modified') || 'Modified'}:</span>
-                    <span class="note-info-value">${new Date(noteDetail.modified).toLocaleString()}</span>
+                    <span class="note-info-value">${new Date(noteDetail.modified).toLocaleString(undefined, { timeZone: userTimezone || undefined })}</span>
                 </div>
                 ` : ''}
             </div>
@@ -2390,6 +2684,14 @@ async function showNoteInfo(note) {
     modal.innerHTML = infoHtml;
     modal.style.display = 'flex';
 
+    modal.querySelectorAll('.note-info-backlinks a').forEach(link => {
+        link.addEventListener('click', (e) => {
+            e.preventDefault();
+            closeNoteInfoModal();
+            loadNote(link.dataset.noteId);
+        });
+    });
+
     // Close on backdrop click
     modal.onclick = (e) => {
         if (e.target === modal) {
@@ -2409,6 +2711,9 @@ function showSidebarContextMenu(e) {
     e.preventDefault();
     currentFolderPath = '';
 
+    document.getElementById('context-show-archived-item').style.display = showArchived ? 'none' : 'flex';
+    document.getElementById('context-hide-archived-item').style.display = showArchived ? 'flex' : 'none';
+
     const x = e.clientX;
     const y = e.clientY;
 
@@ -2479,6 +2784,20 @@ async function handleSidebarContextMenuAction(e) {
                 await createFolder(folderName, '');
             }
             break;
+
+        case 'sort-notes':
+            await setNoteSort('', e.target.closest('.context-menu-item').dataset.field);
+            break;
+
+        case 'clip-page':
+            await clipWebPage('');
+            break;
+
+        case 'toggle-archived':
+            showArchived = !showArchived;
+            localStorage.setItem('showArchived', showArchived);
+            await loadNotes();
+            break;
     }
 }
 
@@ -2572,6 +2891,14 @@ async function handleFolderContextMenuAction(e) {
             await moveFolderOrder(currentFolderPath, 1);
             break;
 
+        case 'sort-notes':
+            await setNoteSort(currentFolderPath, e.target.closest('.context-menu-item').dataset.field);
+            break;
+
+        case 'clip-page':
+            await clipWebPage(currentFolderPath);
+            break;
+
         case 'share-folder':
             await showFolderShareModal(currentFolderPath);
             break;
@@ -2682,7 +3009,7 @@ async function createFolder(name, parentPath) {
 
 async function deleteFolder(path) {
     try {
-        const response = await fetch(`${basePath}/api/folders/${path}`, {
+        const response = await fetch(`${basePath}/api/folders?path=${encodeURIComponent(path)}`, {
             method: 'DELETE'
         });
 
@@ -2721,6 +3048,7 @@ async function renameFolder(oldPath, newName) {
                     await saveFolderOrderForParent(parentPath, folderOrder[parentPath]);
                 }
             }
+            await moveNoteSort(oldPath, newPath);
 
             // Update expanded folders
             if (expandedFolders[oldPath] !== undefined) {
@@ -2779,6 +3107,7 @@ async function renameFolder(oldPath, newName) {
                 await saveFolderOrderForParent(parentPath, folderOrder[parentPath]);
             }
         }
+        await moveNoteSort(oldPath, newPath);
 
         // Update expanded folders
         const newExpandedFolders = {};
@@ -2844,6 +3173,71 @@ async function saveFolderOrderForParent(parentPath, order) {
     }
 }
 
+// Note sort management (field and direction of the notes in each folder)
+let noteSort = {};
+
+async function loadNoteSort() {
+    try {
+        const response = await authFetch('/api/note-sort');
+        noteSort = response.ok ? await response.json() : {};
+    } catch (error) {
+        console.error('Failed to load note sort:', error);
+        noteSort = {};
+    }
+}
+
+// Sort the notes of a folder by field; choosing the current field again flips the direction
+async function setNoteSort(folderPath, field) {
+    const current = noteSort[folderPath];
+    let direction = field === 'title' ? 'asc' : 'desc';
+    if (current && current.field === field) {
+        direction = current.direction === 'asc' ? 'desc' : 'asc';
+    }
+    noteSort[folderPath] = { field, direction };
+    renderNoteTree();
+
+    try {
+        await authFetch('/api/note-sort', {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ folder_path: folderPath, field, direction })
+        });
+    } catch (error) {
+        console.error('Failed to save note sort:', error);
+    }
+}
+
+// Carry the note sort of a renamed folder (and its subfolders) over to the new path
+async function moveNoteSort(oldPath, newPath) {
+    for (const [folderPath, sortSpec] of Object.entries(noteSort)) {
+        if (folderPath !== oldPath && !folderPath.startsWith(oldPath + '/')) continue;
+        const movedPath = newPath + folderPath.substring(oldPath.length);
+        delete noteSort[folderPath];
+        noteSort[movedPath] = sortSpec;
+        try {
+            await authFetch('/api/note-sort', {
+                method: 'PUT',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ folder_path: movedPath, ...sortSpec })
+            });
+            await authFetch(`/api/note-sort?folder_path=${encodeURIComponent(folderPath)}`, { method: 'DELETE' });
+        } catch (error) {
+            console.error('Failed to move note sort:', error);
+        }
+    }
+}
+
+function compareNotes(a, b, sortSpec) {
+    if (!a || !b) return 0;
+    let result;
+    if (sortSpec.field === 'title') {
+        result = a.title.localeCompare(b.title);
+    } else {
+        result = new Date(a[sortSpec.field]) - new Date(b[sortSpec.field]);
+    }
+    return sortSpec.direction === 'desc' ? -result : result;
+}
+
 function getSiblingsAtSameLevel(folderPath) {
     const parentPath = folderPath.includes('/') ? folderPath.substring(0, folderPath.lastIndexOf('/')) : '';
     const folderName = folderPath.split('/').pop();
@@ -2935,39 +3329,87 @@ async function renameNote(id, newTitle) {
     }
 }
 
+// Archive or unarchive a note; archived notes stay searchable but leave the note list
+async function setNoteArchived(id, archived) {
+    try {
+        const response = await fetch(`${basePath}/api/notes/${encodeNoteId(id)}/${archived ? 'archive' : 'unarchive'}`, {
+            method: 'POST'
+        });
+
+        if (response.ok) {
+            showToast(archived ? (i18n.t('toast.noteArchived') || 'Note archived') : (i18n.t('toast.noteUnarchived') || 'Note restored from archive'));
+            await loadNotes();
+        } else {
+            const data = await response.json();
+            showToast(data.error || 'Failed to update note', 'error');
+        }
+    } catch (error) {
+        console.error('Failed to archive note:', error);
+    }
+}
+
 async function duplicateNote(id) {
     const note = notes.find(n => n.id === id);
     if (!note) return;
 
-    // Load full note content
     try {
-        const response = await fetch(`${basePath}/api/notes/${encodeNoteId(id)}`);
-        const fullNote = await response.json();
-
-        // Preserve folder_path when duplicating
-        const folderPath = fullNote.folder_path !== undefined ? fullNote.folder_path : extractFolderPath(fullNote.title);
-        const noteName = fullNote.folder_path !== undefined ? fullNote.title : extractNoteName(fullNote.title);
-
-        const newResponse = await fetch(basePath + '/api/notes', {
+        const headers = {};
+        // Private notes can be copied once unlocked in the editor
+        if (note.private && currentNote && currentNote.id === id && currentPassword) {
+            headers['X-Note-Password'] = currentPassword;
+        }
+        const response = await fetch(`${basePath}/api/notes/${encodeNoteId(id)}/duplicate`, {
             method: 'POST',
-            headers: { 'Content-Type': 'application/json' },
-            body: JSON.stringify({
-                folder_path: folderPath,
-                title: noteName + ' (Copy)',
-                content: fullNote.content || '',
-                type: fullNote.type,
-                private: false
-            })
+            headers
         });
 
-        if (newResponse.ok) {
+        if (response.ok) {
             await loadNotes();
+        } else {
+            const data = await response.json();
+            showToast(data.error || 'Failed to duplicate note', 'error');
         }
     } catch (error) {
         console.error('Failed to duplicate note:', error);
     }
 }
 
+// Download one note rendered to HTML or PDF, or its source
+async function exportNote(id, format) {
+    const note = notes.find(n => n.id === id);
+    if (!note) return;
+
+    try {
+        const headers = {};
+        // Private notes can be exported once unlocked in the editor
+        if (note.private && currentNote && currentNote.id === id && currentPassword) {
+            headers['X-Note-Password'] = currentPassword;
+        }
+        const response = await fetch(`${basePath}/api/notes/${encodeNoteId(id)}/export?format=${format}`, { headers });
+        if (!response.ok) {
+            const data = await response.json();
+            showToast(data.error || i18n.t('toast.exportFailed'), 'error');
+            return;
+        }
+
+        // The server names the file after the note title (filename*=UTF-8''...)
+        const disposition = response.headers.get('Content-Disposition') || '';
+        const match = disposition.match(/filename\*=UTF-8''([^;]+)/);
+        const blob = await response.blob();
+        const url = window.URL.createObjectURL(blob);
+        const a = document.createElement('a');
+        a.href = url;
+        a.download = match ? decodeURIComponent(match[1]) : `note.${format}`;
+        document.body.appendChild(a);
+        a.click();
+        document.body.removeChild(a);
+        window.URL.revokeObjectURL(url);
+    } catch (error) {
+        console.error('Failed to export note:', error);
+        showToast(i18n.t('toast.exportFailed'), 'error');
+    }
+}
+
 // Drag and Drop
 function handleDragStart(e, noteId) {
     draggedNoteId = noteId;
@@ -3509,8 +3951,11 @@ function triggerAutoSave() {
     hasUnsavedChanges = true;
     updateSaveStatus('unsaved');
 
-    // Only auto-save if enabled
-    if (!autoSaveEnabled) return;
+    // Only auto-save if enabled; otherwise keep the edits as a draft on the server
+    if (!autoSaveEnabled) {
+        scheduleDraftSave();
+        return;
+    }
 
     if (autoSaveTimer) {
         clearTimeout(autoSaveTimer);
@@ -3549,9 +3994,17 @@ async function performAutoSave() {
 
         let response;
         if (currentNote) {
+            noteData.base_modified = currentNote.modified;
+            const headers = { 'Content-Type': 'application/json' };
+            if (currentPassword) {
+                headers['X-Note-Password'] = currentPassword;
+            }
+            if (currentNote.revision) {
+                headers['If-Match'] = currentNote.revision;
+            }
             response = await fetch(`${basePath}/api/notes/${encodeNoteId(currentNote.id)}`, {
                 method: 'PUT',
-                headers: { 'Content-Type': 'application/json' },
+                headers,
                 body: JSON.stringify(noteData)
             });
         } else {
@@ -3567,6 +4020,8 @@ async function performAutoSave() {
             if (!currentNote) {
                 currentNote = savedNote;
             }
+            currentNote.modified = savedNote.modified;
+            currentNote.revision = response.headers.get('X-Note-Revision');
             // Update original content after successful save
             originalContent = {
                 title: getFullNoteTitle(),
@@ -3581,6 +4036,10 @@ async function performAutoSave() {
             updateNoteInList(savedNote);
         } else {
             updateSaveStatus('error');
+            if (response.status === 409 || response.status === 412) {
+                // Saved on another device or tab: a manual save asks what to keep
+                showToast(i18n.t('conflict.autoSave'), 'error');
+            }
         }
     } catch (error) {
         console.error('Auto-save failed:', error);
@@ -3734,6 +4193,9 @@ function setupEventListeners() {
     // History
     historyBtn.addEventListener('click', showHistory);
 
+    // Print
+    printBtn.addEventListener('click', printNote);
+
     // Pretty JSON
     prettyJsonBtn.addEventListener('click', prettyJson);
 
@@ -3859,7 +4321,7 @@ async function uploadAndInsertImage(file) {
         const formData = new FormData();
         formData.append('image', file);
 
-        const response = await fetch(basePath + '/api/images', {
+        const response = await fetch(basePath + '/api/images' + attachmentFolderQuery(), {
             method: 'POST',
             body: formData
         });
@@ -3879,7 +4341,7 @@ async function uploadAndInsertImage(file) {
             // Remove placeholder on error
             replaceInEditor(placeholder, '');
             updatePreview();
-            alert(i18n.t('error.uploadImageFailed'));
+            alert(uploadFailureMessage(response, i18n.t('error.uploadImageFailed')));
         }
     } catch (error) {
         console.error('Image upload failed:', error);
@@ -3898,6 +4360,11 @@ function initFileUpload() {
         fileInput.click();
     });
 
+    const uploadUrlBtn = document.getElementById('uploadUrlBtn');
+    if (uploadUrlBtn) {
+        uploadUrlBtn.addEventListener('click', attachFromUrl);
+    }
+
     fileInput.addEventListener('change', async (e) => {
         const files = e.target.files;
         if (!files || files.length === 0) return;
@@ -4154,7 +4621,7 @@ async function uploadAndAttachFile(file) {
         const formData = new FormData();
         formData.append('file', file);
 
-        const response = await fetch(basePath + '/api/files', {
+        const response = await fetch(basePath + '/api/files' + attachmentFolderQuery(), {
             method: 'POST',
             body: formData
         });
@@ -4162,14 +4629,18 @@ async function uploadAndAttachFile(file) {
         if (response.ok) {
             const data = await response.json();
 
-            // Add to attachments list
+            // Add to attachments list (the server tells the type it serves the file with, which players need)
             const attachment = {
                 name: fileName,
                 url: data.url,
                 size: fileSize,
-                type: file.type,
+                type: data.type || file.type,
                 isImage: isImage
             };
+            const duration = await mediaDuration(file, attachment.type);
+            if (duration > 0) {
+                attachment.duration = duration;
+            }
 
             currentAttachments.push(attachment);
             renderAttachments();
@@ -4191,7 +4662,7 @@ async function uploadAndAttachFile(file) {
             }
         } else {
             const errorMsg = i18n ? i18n.t('msg.uploadFailed') || 'Failed to upload file' : 'Failed to upload file';
-            alert(errorMsg);
+            alert(uploadFailureMessage(response, errorMsg));
         }
     } catch (error) {
         console.error('File upload failed:', error);
@@ -4200,32 +4671,103 @@ async function uploadAndAttachFile(file) {
     }
 }
 
-// Legacy function for backward compatibility (drag & drop images)
-async function uploadAndInsertFile(file) {
-    const isImage = file.type.startsWith('image/');
-    const isAsciiDoc = noteType.value === 'asciidoc';
-    const fileName = file.name;
-
-    // Use appropriate syntax based on note type
-    let placeholder;
-    if (isAsciiDoc) {
-        placeholder = isImage
-            ? `image::uploading[Uploading ${fileName}...]`
-            : `link:uploading[Uploading ${fileName}...]`;
-    } else {
-        placeholder = isImage
-            ? `![Uploading ${fileName}...]()`
-            : `[Uploading ${fileName}...]()`;
-    }
-
-    insertAtCursor(placeholder);
-    updatePreview();
+// Attach a remote file by its URL; the server downloads it and stores it like an upload
+async function attachFromUrl() {
+    const url = await showPromptModal({
+        title: i18n.t('editor.uploadUrl'),
+        message: i18n.t('attachment.urlPrompt'),
+        placeholder: 'https://'
+    });
+    if (!url || !url.trim()) return;
 
     try {
-        const formData = new FormData();
-        formData.append('file', file);
+        const response = await authFetch('/api/files/fetch' + attachmentFolderQuery(), {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ url: url.trim() })
+        });
+        const data = await response.json();
+        if (!response.ok) {
+            showToast(data.error || i18n.t('error.uploadFileFailed'), 'error');
+            return;
+        }
+
+        const attachment = {
+            name: data.originalName,
+            url: data.url,
+            size: data.size,
+            type: data.type,
+            isImage: data.isImage
+        };
+        currentAttachments.push(attachment);
+        renderAttachments();
+        triggerAutoSave();
+
+        if (attachment.isImage) {
+            insertAttachmentToContent(attachment);
+        }
+    } catch (error) {
+        console.error('Failed to attach from URL:', error);
+        showToast(i18n.t('error.uploadFileFailed'), 'error');
+    }
+}
 
-        const response = await fetch(basePath + '/api/files', {
+// Create a note from the readable content of a web page (images are downloaded as attachments)
+async function clipWebPage(folderPath) {
+    const url = await showPromptModal({
+        title: i18n.t('context.clipPage'),
+        message: i18n.t('clip.urlPrompt'),
+        placeholder: 'https://'
+    });
+    if (!url || !url.trim()) return;
+
+    showToast(i18n.t('clip.clipping'));
+    try {
+        const response = await authFetch('/api/clip', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ url: url.trim(), folder_path: folderPath })
+        });
+        const data = await response.json();
+        if (!response.ok) {
+            showToast(data.error || i18n.t('clip.failed'), 'error');
+            return;
+        }
+        await loadNotes();
+        await loadNote(data.id);
+        showToast(i18n.t('clip.clipped'));
+    } catch (error) {
+        console.error('Failed to clip page:', error);
+        showToast(i18n.t('clip.failed'), 'error');
+    }
+}
+
+// Legacy function for backward compatibility (drag & drop images)
+async function uploadAndInsertFile(file) {
+    const isImage = file.type.startsWith('image/');
+    const isAsciiDoc = noteType.value === 'asciidoc';
+    const fileName = file.name;
+
+    // Use appropriate syntax based on note type
+    let placeholder;
+    if (isAsciiDoc) {
+        placeholder = isImage
+            ? `image::uploading[Uploading ${fileName}...]`
+            : `link:uploading[Uploading ${fileName}...]`;
+    } else {
+        placeholder = isImage
+            ? `![Uploading ${fileName}...]()`
+            : `[Uploading ${fileName}...]()`;
+    }
+
+    insertAtCursor(placeholder);
+    updatePreview();
+
+    try {
+        const formData = new FormData();
+        formData.append('file', file);
+
+        const response = await fetch(basePath + '/api/files' + attachmentFolderQuery(), {
             method: 'POST',
             body: formData
         });
@@ -4234,12 +4776,15 @@ async function uploadAndInsertFile(file) {
             const data = await response.json();
             // Use AsciiDoc syntax for asciidoc notes, Markdown otherwise
             let markup;
+            const media = mediaKind(data.url);
             if (isAsciiDoc) {
-                markup = isImage
+                markup = media
+                    ? `${media}::${data.url}[]`
+                    : isImage
                     ? `image::${data.url}[${fileName}]`
                     : `link:${data.url}[${fileName}]`;
             } else {
-                markup = isImage
+                markup = isImage || media
                     ? `![${fileName}](${data.url})`
                     : `[${fileName}](${data.url})`;
             }
@@ -4261,7 +4806,7 @@ async function uploadAndInsertFile(file) {
         } else {
             replaceInEditor(placeholder, '');
             updatePreview();
-            alert(i18n.t('error.uploadFileFailed'));
+            alert(uploadFailureMessage(response, i18n.t('error.uploadFileFailed')));
         }
     } catch (error) {
         console.error('File upload failed:', error);
@@ -4307,12 +4852,19 @@ function renderAttachments() {
             <span class="attachment-icon ${getFileTypeClass(att.type)}">${getFileIcon(att.type)}</span>
             <div class="attachment-info">
                 <span class="attachment-name" title="${escapeHtml(att.name)}">${escapeHtml(att.name)}</span>
-                <span class="attachment-size">${formatFileSize(att.size)}</span>
+                <span class="attachment-size">${formatFileSize(att.size)}${att.duration ? ` · ${formatDuration(att.duration)}` : ''}</span>
+                ${(att.type || '').startsWith('audio/') ? `<audio class="attachment-player" src="${att.url}" controls preload="none"></audio>` : ''}
             </div>
             <div class="attachment-actions">
                 <button class="attachment-btn" title="Insert into content" onclick="insertAttachmentToContent(currentAttachments[${index}])">
                     &#8629;
                 </button>
+                <button class="attachment-btn" title="${i18n.t('attachment.rename')}" onclick="renameAttachment(${index})">
+                    &#9998;
+                </button>
+                <button class="attachment-btn" title="${i18n.t('attachment.uploadVersion')}" onclick="uploadAttachmentVersion(${index})">
+                    &#10227;
+                </button>
                 <a href="${att.url}?download=true" download="${escapeHtml(att.name)}" class="attachment-btn" title="Download">
                     &#8681;
                 </a>
@@ -4324,6 +4876,14 @@ function renderAttachments() {
     `).join('');
 }
 
+// mediaKind returns "video" or "audio" for attachment URLs that browsers can play inline
+function mediaKind(url) {
+    const ext = (url || '').split(/[?#]/)[0].split('.').pop().toLowerCase();
+    if (['mp4', 'm4v', 'mov', 'webm', 'ogv'].includes(ext)) return 'video';
+    if (['mp3', 'm4a', 'm4b', 'aac', 'weba', 'ogg', 'oga', 'opus', 'wav', 'flac'].includes(ext)) return 'audio';
+    return null;
+}
+
 function insertAttachmentToContent(attachment) {
     // For non-image files, add ?download=true to enable original filename on download
     const downloadUrl = attachment.isImage
@@ -4331,16 +4891,19 @@ function insertAttachmentToContent(attachment) {
         : `${attachment.url}?download=true`;
 
     const isAsciiDoc = noteType.value === 'asciidoc';
+    const media = mediaKind(attachment.url);
     let markup;
 
     if (isAsciiDoc) {
-        // AsciiDoc syntax: image::url[alt] or link:url[text]
-        markup = attachment.isImage
+        // AsciiDoc syntax: image::url[alt], video::url[]/audio::url[] or link:url[text]
+        markup = media
+            ? `${media}::${attachment.url}[]`
+            : attachment.isImage
             ? `image::${attachment.url}[${attachment.name}]`
             : `link:${downloadUrl}[${attachment.name}]`;
     } else {
-        // Markdown syntax: ![alt](url) or [text](url)
-        markup = attachment.isImage
+        // Markdown syntax: ![alt](url) (also for video and audio, played inline) or [text](url)
+        markup = attachment.isImage || media
             ? `![${attachment.name}](${attachment.url})`
             : `[${attachment.name}](${downloadUrl})`;
     }
@@ -4399,6 +4962,94 @@ function removeAttachment(index) {
     }
 }
 
+// Uploads are organized in the files area under the folder of the note they are attached to
+function attachmentFolderQuery() {
+    return currentNoteFolderPath ? `?dir=${encodeURIComponent(currentNoteFolderPath)}` : '';
+}
+
+// Explains an upload rejected by the server's size or type limits (upload: in config.yaml)
+function uploadFailureMessage(response, fallback) {
+    if (response.status === 413) return i18n.t('error.uploadTooLarge');
+    if (response.status === 415) return i18n.t('error.uploadTypeNotAllowed');
+    return fallback;
+}
+
+// Rename the display name of an attachment (in every note that references the file)
+async function renameAttachment(index) {
+    const attachment = currentAttachments[index];
+    if (!attachment) return;
+
+    const filename = attachment.url.split('/').pop();
+    const newName = await showPromptModal({
+        title: i18n.t('attachment.rename'),
+        message: i18n.t('attachment.renamePrompt'),
+        defaultValue: attachment.name,
+        placeholder: attachment.name
+    });
+    if (!newName || newName.trim() === attachment.name) return;
+
+    try {
+        const response = await authFetch(`/api/files/${filename}/name`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ name: newName.trim() })
+        });
+        const data = await response.json();
+        if (!response.ok) {
+            showToast(data.error || i18n.t('attachment.renameFailed'), 'error');
+            return;
+        }
+        attachment.name = data.originalName;
+        renderAttachments();
+        showToast(i18n.t('attachment.renamed'));
+    } catch (error) {
+        console.error('Failed to rename attachment:', error);
+        showToast(i18n.t('attachment.renameFailed'), 'error');
+    }
+}
+
+// Replace the content of an attachment; the URL stays the same and the old content is kept as a version
+function uploadAttachmentVersion(index) {
+    const attachment = currentAttachments[index];
+    if (!attachment) return;
+
+    const filename = attachment.url.split('/').pop();
+    const input = document.createElement('input');
+    input.type = 'file';
+    input.addEventListener('change', async () => {
+        const file = input.files[0];
+        if (!file) return;
+
+        const formData = new FormData();
+        formData.append('file', file);
+        try {
+            const response = await authFetch(`/api/files/${filename}/versions`, {
+                method: 'POST',
+                body: formData
+            });
+            if (!response.ok) {
+                const data = await response.json();
+                showToast(uploadFailureMessage(response, data.error || i18n.t('attachment.uploadVersionFailed')), 'error');
+                return;
+            }
+            attachment.size = file.size;
+            const duration = await mediaDuration(file, attachment.type || file.type);
+            if (duration > 0) {
+                attachment.duration = duration;
+            } else {
+                delete attachment.duration;
+            }
+            renderAttachments();
+            updatePreview();
+            showToast(i18n.t('attachment.versionUploaded'));
+        } catch (error) {
+            console.error('Failed to upload attachment version:', error);
+            showToast(i18n.t('attachment.uploadVersionFailed'), 'error');
+        }
+    });
+    input.click();
+}
+
 async function deleteAttachmentFile(url) {
     try {
         // Extract filename from URL (format: /u/{username}/files/{filename} or /u/{username}/images/{filename})
@@ -4453,6 +5104,34 @@ function getFileTypeClass(mimeType) {
     return 'default';
 }
 
+// formatDuration shows seconds as m:ss (h:mm:ss from an hour)
+function formatDuration(seconds) {
+    const total = Math.round(seconds);
+    const h = Math.floor(total / 3600);
+    const m = Math.floor((total % 3600) / 60);
+    const s = String(total % 60).padStart(2, '0');
+    return h > 0 ? `${h}:${String(m).padStart(2, '0')}:${s}` : `${m}:${s}`;
+}
+
+// mediaDuration reads the length of an audio or video file in seconds (0 for other files, or when the browser can't play it)
+function mediaDuration(file, type) {
+    if (!type.startsWith('audio/') && !type.startsWith('video/')) {
+        return Promise.resolve(0);
+    }
+    return new Promise((resolve) => {
+        const media = document.createElement(type.startsWith('video/') ? 'video' : 'audio');
+        const url = URL.createObjectURL(file);
+        const done = (seconds) => {
+            URL.revokeObjectURL(url);
+            resolve(Number.isFinite(seconds) ? Math.round(seconds * 10) / 10 : 0);
+        };
+        media.preload = 'metadata';
+        media.onloadedmetadata = () => done(media.duration);
+        media.onerror = () => done(0);
+        media.src = url;
+    });
+}
+
 function formatFileSize(bytes) {
     if (!bytes || bytes === 0) return '0 B';
 
@@ -4506,7 +5185,7 @@ async function loadNotes() {
     try {
         // Fetch notes, folders, and folder icons in parallel
         const [notesResponse, foldersResponse, iconsResponse] = await Promise.all([
-            fetch(basePath + '/api/notes'),
+            fetch(basePath + '/api/notes' + (showArchived ? '?include_archived=true' : '')),
             fetch(basePath + '/api/folders'),
             fetch(basePath + '/api/folder-icons')
         ]);
@@ -4524,10 +5203,13 @@ async function loadNotes() {
 
         renderNoteTree();
         updateCalendarIfVisible();
+        refreshOfflineBundle();
     } catch (error) {
         console.error('Failed to load notes:', error);
-        notes = [];
-        folders = [];
+        if (!(error instanceof TypeError && loadOfflineNotes())) {
+            notes = [];
+            folders = [];
+        }
         renderNoteTree();
         updateCalendarIfVisible();
     }
@@ -4545,6 +5227,7 @@ function updateNoteInList(savedNote) {
         type: savedNote.type,
         icon: savedNote.icon || '',
         private: savedNote.private,
+        archived: savedNote.archived || false,
         encrypted: savedNote.encrypted || false,
         created: savedNote.created,
         modified: savedNote.modified
@@ -4620,6 +5303,7 @@ async function loadNote(id) {
             return;
         }
 
+        note.revision = response.headers.get('X-Note-Revision');
         currentNote = note;
         showPreviewOnly(note);
         updateNoteListSelection(id);
@@ -4630,6 +5314,13 @@ async function loadNote(id) {
         }
     } catch (error) {
         console.error('Failed to load note:', error);
+        const offline = error instanceof TypeError && getOfflineNote(id); // TypeError: no connection
+        if (offline) {
+            currentNote = offline;
+            showPreviewOnly(offline);
+            updateNoteListSelection(id);
+            return;
+        }
         const errorMsg = i18n ? i18n.t('msg.loadFailed') || 'Failed to load note' : 'Failed to load note';
         alert(errorMsg);
     }
@@ -4675,6 +5366,7 @@ async function editNote(id) {
             return;
         }
 
+        note.revision = response.headers.get('X-Note-Revision');
         currentNote = note;
         showEditor(note);
         updateNoteListSelection(id);
@@ -4683,8 +5375,16 @@ async function editNote(id) {
         if (layoutState.tabMode) {
             switchTab('editor');
         }
+        await offerDraft(note);
     } catch (error) {
         console.error('Failed to load note:', error);
+        const offline = error instanceof TypeError && getOfflineNote(id);
+        if (offline) {
+            currentNote = offline;
+            showEditor(offline);
+            updateNoteListSelection(id);
+            return;
+        }
         const errorMsg = i18n ? i18n.t('msg.loadFailed') || 'Failed to load note' : 'Failed to load note';
         alert(errorMsg);
     }
@@ -4763,8 +5463,13 @@ async function saveNote() {
         clearTimeout(autoSaveTimer);
         autoSaveTimer = null;
     }
+    if (draftTimer) {
+        clearTimeout(draftTimer);
+        draftTimer = null;
+    }
 
     isSaving = true;
+    let conflict = null;
 
     const headers = {
         'Content-Type': 'application/json'
@@ -4791,6 +5496,10 @@ async function saveNote() {
     try {
         let response;
         if (currentNote && currentNote.id) {
+            data.base_modified = currentNote.modified;
+            if (currentNote.revision) {
+                headers['If-Match'] = currentNote.revision;
+            }
             response = await fetch(`${basePath}/api/notes/${encodeNoteId(currentNote.id)}`, {
                 method: 'PUT',
                 headers,
@@ -4806,6 +5515,7 @@ async function saveNote() {
 
         if (response.ok) {
             const savedNote = await response.json();
+            savedNote.revision = response.headers.get('X-Note-Revision');
             currentNote = savedNote;
             // Update original content after successful save
             originalContent = {
@@ -4820,15 +5530,146 @@ async function saveNote() {
             showToast(i18n.t('msg.noteSaved'));
             // Optimistic update: update local list instead of full reload
             updateNoteInList(savedNote);
+            updateOfflineNote(savedNote);
+        } else if (response.status === 409) {
+            conflict = await response.json();
+            conflict.revision = response.headers.get('X-Note-Revision');
+        } else if (response.status === 412) {
+            // Changed in another tab without a newer modified time: compare with the note as it is now
+            conflict = await loadSaveConflict();
         } else {
             const error = await response.json();
             alert(error.error || i18n.t('error.saveFailed'));
         }
     } catch (error) {
         console.error('Failed to save note:', error);
+        // No connection: keep the save and send it when back online
+        if (error instanceof TypeError && !isPrivate && !data.password) {
+            const offlineNote = saveNoteOffline(data);
+            currentNote = offlineNote;
+            originalContent = {
+                title: getFullNoteTitle(),
+                content: getEditorContent(),
+                type: noteType.value,
+                private: notePrivate.checked,
+                tags: [...currentTags]
+            };
+            hasUnsavedChanges = false;
+            updateSaveStatus('saved');
+            showToast(i18n.t('offline.savedOffline'));
+            updateNoteInList(offlineNote);
+        }
     } finally {
         isSaving = false;
     }
+
+    if (conflict) {
+        await resolveSaveConflict(conflict);
+    }
+}
+
+// The note was saved on another device after it was opened here:
+// overwrite it, or keep these edits as a draft and open the other version
+async function resolveSaveConflict(conflict) {
+    const overwrite = await showConfirmModal({
+        title: i18n.t('conflict.title'),
+        message: i18n.t('conflict.message', { time: new Date(conflict.current.modified).toLocaleString(undefined, { timeZone: userTimezone || undefined }) }),
+        confirmText: i18n.t('conflict.overwrite'),
+        cancelText: i18n.t('conflict.loadTheirs'),
+        danger: true
+    });
+    if (!currentNote) return;
+
+    if (overwrite) {
+        currentNote.modified = conflict.current.modified;
+        currentNote.revision = conflict.revision;
+        await saveNote();
+    } else {
+        await saveDraftNow();
+        await editNote(currentNote.id);
+    }
+}
+
+// Loads the current version of the open note after a save was refused with 412
+async function loadSaveConflict() {
+    const headers = {};
+    if (currentPassword) {
+        headers['X-Note-Password'] = currentPassword;
+    }
+    const response = await fetch(`${basePath}/api/notes/${encodeNoteId(currentNote.id)}`, { headers });
+    if (!response.ok) {
+        return null;
+    }
+    return {
+        current: await response.json(),
+        revision: response.headers.get('X-Note-Revision')
+    };
+}
+
+function scheduleDraftSave() {
+    if (!currentNote || !currentNote.id) return;
+    if (draftTimer) {
+        clearTimeout(draftTimer);
+    }
+    draftTimer = setTimeout(saveDraftNow, AUTO_SAVE_DELAY);
+}
+
+// Store unsaved edits as a draft (not committed); it is removed when the note is saved
+async function saveDraftNow() {
+    draftTimer = null;
+    if (!currentNote || !currentNote.id || !hasUnsavedChanges) return;
+
+    const headers = { 'Content-Type': 'application/json' };
+    if (currentPassword) {
+        headers['X-Note-Password'] = currentPassword;
+    }
+    try {
+        await fetch(`${basePath}/api/notes/${encodeNoteId(currentNote.id)}/draft`, {
+            method: 'PUT',
+            headers,
+            body: JSON.stringify({
+                title: noteTitle.value.trim(),
+                content: getEditorContent(),
+                base_modified: currentNote.modified
+            })
+        });
+    } catch (error) {
+        // Drafts are best effort (e.g. offline)
+    }
+}
+
+// Offer to restore a draft with edits that were never saved to the note
+async function offerDraft(note) {
+    const headers = {};
+    if (currentPassword) {
+        headers['X-Note-Password'] = currentPassword;
+    }
+    try {
+        const response = await fetch(`${basePath}/api/notes/${encodeNoteId(note.id)}/draft`, { headers });
+        if (!response.ok) return;
+        const draft = await response.json();
+
+        let restore = false;
+        if (draft.content !== note.content) {
+            restore = await showConfirmModal({
+                title: i18n.t('draft.title'),
+                message: i18n.t(draft.stale ? 'draft.restoreStale' : 'draft.restore', { time: new Date(draft.saved_at).toLocaleString(undefined, { timeZone: userTimezone || undefined }) }),
+                confirmText: i18n.t('draft.restoreButton'),
+                cancelText: i18n.t('draft.discardButton'),
+                icon: '📝'
+            });
+        }
+        if (!currentNote || currentNote.id !== note.id) return;
+
+        if (restore) {
+            setEditorContent(draft.content);
+            triggerAutoSave();
+        } else {
+            await fetch(`${basePath}/api/notes/${encodeNoteId(note.id)}/draft`, { method: 'DELETE', headers });
+        }
+    } catch (error) {
+        // No draft
+    }
 }
 
 async function deleteNote() {
@@ -4865,6 +5706,16 @@ async function deleteNote() {
 
 let currentHistoryCommits = [];
 
+// Open the saved note as a print-optimized page and show the print dialog
+function printNote() {
+    if (!currentNote || !currentNote.id) return;
+    if (notePrivate.checked) {
+        showToast(i18n.t('editor.printPrivate'));
+        return;
+    }
+    window.open(`${basePath}/print/${encodeNoteId(currentNote.id)}?print=1`, '_blank');
+}
+
 async function showHistory() {
     if (!currentNote || !currentNote.id) return;
 
@@ -4948,7 +5799,6 @@ async function loadVersionDiff(hash) {
         const data = await response.json();
 
         currentVersionHash = hash;
-        currentVersionContent = data.content;
         versionHash.textContent = hash.substring(0, 8);
 
         // Calculate and render diff
@@ -5036,7 +5886,6 @@ function initSyntaxHelpModal() {
 }
 
 let currentVersionHash = null;
-let currentVersionContent = null;
 
 function renderVersionDiff(oldContent, newContent) {
     const diffOldEl = document.getElementById('diffOldContent');
@@ -5113,15 +5962,33 @@ function syncDiffScroll(el1, el2) {
     });
 }
 
-function restoreVersion() {
-    if (!currentVersionContent) {
-        console.error('No version content to restore');
+// Roll the note back to the selected commit on the server (saved as a new commit), then reload it
+async function restoreVersion() {
+    if (!currentNote || !currentNote.id || !currentVersionHash) {
+        console.error('No version to restore');
         return;
     }
-    setEditorContent(currentVersionContent);
-    updatePreview();
-    versionModal.style.display = 'none';
-    triggerAutoSave();
+
+    const headers = {};
+    if (currentPassword) {
+        headers['X-Note-Password'] = currentPassword;
+    }
+
+    try {
+        const response = await authFetch(`${basePath}/api/notes/${encodeNoteId(currentNote.id)}/restore/${currentVersionHash}`, {
+            method: 'POST',
+            headers
+        });
+        const result = await response.json();
+        if (!response.ok) throw new Error(result.error || 'Restore failed');
+
+        versionModal.style.display = 'none';
+        await loadNote(result.id);
+        await loadNotes();
+    } catch (error) {
+        console.error('Failed to restore version:', error);
+        showToast(error.message);
+    }
 }
 
 // Tree Structure Functions
@@ -5231,6 +6098,12 @@ function renderTreeLevel(tree, container, level, path) {
             if (bIdx !== -1) return 1;
         }
 
+        // Notes follow the folder's saved sort
+        if (!aIsFolder && !bIsFolder && noteSort[parentPath]) {
+            const result = compareNotes(a[1]._notes[0], b[1]._notes[0], noteSort[parentPath]);
+            if (result !== 0) return result;
+        }
+
         return a[0].localeCompare(b[0]);
     });
 
@@ -5308,6 +6181,9 @@ function renderNoteItem(note, container, level, isChild) {
     li.className = 'note-list-item';
     li.draggable = true;
     li.dataset.noteId = note.id;
+    if (note.archived) {
+        li.classList.add('archived');
+    }
 
     if (currentNote && currentNote.id === note.id) {
         li.classList.add('active');
@@ -5825,6 +6701,7 @@ function setNoteTitleAndPath(fullTitle) {
 // Show note in preview-only mode (view mode)
 function showPreviewOnly(note) {
     isViewMode = true;
+    releaseNoteLock();
     // Use separate folder_path field from API (with fallback to extracting from title for backward compatibility)
     currentNoteFolderPath = note.folder_path || extractFolderPath(note.title || '');
     noteFolderPath.textContent = formatFolderPathForDisplay(currentNoteFolderPath);
@@ -5865,6 +6742,7 @@ function showEditMode() {
         cmEditor.focus();
     }
     updateNoteListSelection(currentNote.id);
+    claimNoteLock(currentNote.id);
 
     // Scroll to top on mobile/tablet to ensure toolbar is visible
     scrollEditorToTop();
@@ -5872,6 +6750,7 @@ function showEditMode() {
 
 function showEditor(note) {
     isViewMode = false;
+    claimNoteLock(note.id);
     // Use separate folder_path field from API (with fallback to extracting from title for backward compatibility)
     currentNoteFolderPath = note.folder_path || extractFolderPath(note.title || '');
     noteFolderPath.textContent = formatFolderPathForDisplay(currentNoteFolderPath);
@@ -5970,6 +6849,7 @@ function showPreviewPane() {
 }
 
 function hideEditor() {
+    releaseNoteLock();
     emptyState.style.display = 'flex';
     editor.style.display = 'none';
 }
@@ -6388,8 +7268,10 @@ async function showNotesByTag(tag) {
     const modal = document.getElementById('tagNotesModal');
     const titleEl = document.getElementById('tagNotesTitle');
     const listEl = document.getElementById('tagNotesList');
+    const exportBtn = document.getElementById('tagNotesExport');
 
     if (!modal || !titleEl || !listEl) return;
+    if (exportBtn) exportBtn.style.display = 'none';
 
     // Set title
     titleEl.textContent = `#${tag}`;
@@ -6416,6 +7298,11 @@ async function showNotesByTag(tag) {
             return;
         }
 
+        if (exportBtn) {
+            exportBtn.style.display = '';
+            exportBtn.onclick = () => exportSelectedNotes(notesWithTag.map(note => note.id), tag);
+        }
+
         // Render notes list
         listEl.innerHTML = '';
         notesWithTag.forEach(note => {
@@ -6424,7 +7311,7 @@ async function showNotesByTag(tag) {
 
             const folderPath = note.folder_path ? `<span class="tag-note-folder">${escapeHtml(note.folder_path)}/</span>` : '';
             const noteTitle = escapeHtml(note.title || i18n.t('editor.untitled'));
-            const noteDate = new Date(note.modified).toLocaleDateString();
+            const noteDate = new Date(note.modified).toLocaleDateString(undefined, { timeZone: userTimezone || undefined });
 
             // Render tags for this note
             const noteTags = (note.tags || []).map(t =>
@@ -7602,7 +8489,8 @@ function formatDate(dateString) {
         month: 'short',
         day: 'numeric',
         hour: '2-digit',
-        minute: '2-digit'
+        minute: '2-digit',
+        timeZone: userTimezone || undefined
     });
 }
 
@@ -7709,6 +8597,233 @@ async function authFetch(url, options = {}) {
     return response;
 }
 
+// ========== Offline Support ==========
+// The service worker keeps the app shell; note data comes from the offline bundle
+// (/api/offline) and saves made without a connection are queued for /api/sync
+
+const OFFLINE_BUNDLE_KEY = 'offlineBundle';
+const OFFLINE_QUEUE_KEY = 'offlineQueue';
+const OFFLINE_REFRESH_MS = 5 * 60 * 1000;
+let offlineBundleFetchedAt = 0;
+let replayingOfflineQueue = false;
+
+function registerServiceWorker() {
+    if (!('serviceWorker' in navigator)) return;
+    navigator.serviceWorker.register(basePath + '/sw.js', { scope: basePath + '/' })
+        .catch(error => console.error('Service worker registration failed:', error));
+}
+
+function getOfflineBundle() {
+    try {
+        return JSON.parse(localStorage.getItem(OFFLINE_BUNDLE_KEY) || 'null');
+    } catch (e) {
+        return null;
+    }
+}
+
+function setOfflineBundle(bundle) {
+    // Keep fewer notes with content when the storage quota is exceeded
+    while (true) {
+        try {
+            localStorage.setItem(OFFLINE_BUNDLE_KEY, JSON.stringify(bundle));
+            return;
+        } catch (e) {
+            if (!bundle.recent || bundle.recent.length === 0) return;
+            bundle.recent = bundle.recent.slice(0, Math.floor(bundle.recent.length / 2));
+        }
+    }
+}
+
+async function refreshOfflineBundle(force = false) {
+    if (!navigator.onLine) return;
+    if (!force && Date.now() - offlineBundleFetchedAt < OFFLINE_REFRESH_MS) return;
+    offlineBundleFetchedAt = Date.now();
+    try {
+        const response = await fetch(basePath + '/api/offline');
+        if (response.ok) {
+            setOfflineBundle(await response.json());
+        }
+    } catch (error) {
+        console.error('Failed to refresh offline notes:', error);
+    }
+}
+
+// Keep the offline copy of a note current after it is saved
+function updateOfflineNote(note) {
+    const bundle = getOfflineBundle();
+    if (!bundle || note.private) return;
+    bundle.recent = (bundle.recent || []).filter(n => n.id !== note.id);
+    bundle.recent.unshift(note);
+    bundle.notes = (bundle.notes || []).filter(n => n.id !== note.id);
+    bundle.notes.unshift({
+        id: note.id,
+        uid: note.uid,
+        folder_path: note.folder_path || '',
+        title: note.title,
+        type: note.type,
+        icon: note.icon || '',
+        tags: note.tags || [],
+        private: false,
+        created: note.created,
+        modified: note.modified
+    });
+    setOfflineBundle(bundle);
+}
+
+function getOfflineNote(id) {
+    const bundle = getOfflineBundle();
+    if (!bundle || !bundle.recent) return null;
+    return bundle.recent.find(n => n.id === id || n.uid === id) || null;
+}
+
+// Shows the notes of the offline bundle when the server cannot be reached
+function loadOfflineNotes() {
+    const bundle = getOfflineBundle();
+    if (!bundle || !bundle.notes) return false;
+
+    notes = bundle.notes;
+    const paths = new Set();
+    notes.forEach(note => {
+        // Include parent folders so the tree can be built
+        for (let path = note.folder_path || ''; path; path = path.substring(0, Math.max(path.lastIndexOf('/'), 0))) {
+            paths.add(path);
+        }
+    });
+    folders = [...paths].sort().map(path => ({ path }));
+    showToast(i18n.t('offline.showingOffline'));
+    return true;
+}
+
+function getOfflineQueue() {
+    try {
+        return JSON.parse(localStorage.getItem(OFFLINE_QUEUE_KEY) || '[]');
+    } catch (e) {
+        return [];
+    }
+}
+
+function saveOfflineQueue(queue) {
+    if (queue.length === 0) {
+        localStorage.removeItem(OFFLINE_QUEUE_KEY);
+    } else {
+        localStorage.setItem(OFFLINE_QUEUE_KEY, JSON.stringify(queue));
+    }
+}
+
+// Queues a save made without a connection and shows it as saved
+// Returns the note as it will be shown until the queue is replayed
+function saveNoteOffline(data) {
+    const queue = getOfflineQueue();
+    const isNew = !currentNote || !currentNote.id || currentNote.id.startsWith('offline-');
+    const id = (currentNote && currentNote.id) || 'offline-' + Date.now().toString(36);
+    const clientId = isNew ? id : (currentNote.uid || currentNote.id);
+
+    const op = {
+        op: isNew ? 'create' : 'update',
+        client_id: clientId,
+        title: data.title,
+        content: data.content,
+        type: data.type,
+        tags: data.tags
+    };
+    if (isNew) {
+        op.folder_path = data.folder_path;
+    } else {
+        op.id = currentNote.id;
+        op.uid = currentNote.uid;
+        op.base_modified = currentNote.modified;
+    }
+
+    // Later saves of a note replace the queued one, keeping the version the edit was based on
+    const queued = replayingOfflineQueue ? -1 : queue.findIndex(q => q.client_id === clientId);
+    if (queued >= 0) {
+        op.op = queue[queued].op;
+        op.base_modified = queue[queued].base_modified;
+        queue[queued] = { ...queue[queued], ...op };
+    } else {
+        queue.push(op);
+    }
+    saveOfflineQueue(queue);
+
+    const now = new Date().toISOString();
+    const note = {
+        ...(currentNote || {}),
+        id,
+        folder_path: data.folder_path || '',
+        title: data.title,
+        content: data.content,
+        type: data.type,
+        tags: data.tags,
+        private: false,
+        created: (currentNote && currentNote.created) || now,
+        modified: (currentNote && currentNote.modified) || now
+    };
+    updateOfflineNote(note);
+    return note;
+}
+
+// Sends the saves queued while offline; notes changed on the server meanwhile are kept as copies
+async function replayOfflineQueue() {
+    const queue = getOfflineQueue();
+    if (queue.length === 0 || !navigator.onLine || replayingOfflineQueue) return;
+
+    replayingOfflineQueue = true;
+    try {
+        const response = await fetch(basePath + '/api/sync', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ ops: queue })
+        });
+        if (!response.ok) return; // Tried again on the next reconnect
+
+        const result = await response.json();
+        saveOfflineQueue(getOfflineQueue().slice(queue.length));
+
+        let conflicts = 0;
+        let failed = 0;
+        (result.results || []).forEach(r => {
+            if (r.status === 'conflict') conflicts++;
+            else if (r.status !== 'applied') failed++;
+            // The open note now exists on the server
+            if (r.status === 'applied' && r.note && currentNote && currentNote.id === r.client_id) {
+                currentNote.id = r.note.id;
+                currentNote.uid = r.note.uid;
+                currentNote.modified = r.note.modified;
+            }
+        });
+
+        if (conflicts > 0) {
+            showToast(i18n.t('offline.syncedConflicts', { count: conflicts }));
+        } else if (failed > 0) {
+            showToast(i18n.t('offline.syncFailed', { count: failed }));
+        } else {
+            showToast(i18n.t('offline.synced'));
+        }
+        await loadNotes();
+        refreshOfflineBundle(true);
+    } catch (error) {
+        console.error('Failed to send offline changes:', error);
+    } finally {
+        replayingOfflineQueue = false;
+    }
+}
+
+// Cached notes and queued saves belong to the signed-in user
+async function clearOfflineData() {
+    await replayOfflineQueue();
+    localStorage.removeItem(OFFLINE_BUNDLE_KEY);
+    localStorage.removeItem(OFFLINE_QUEUE_KEY);
+    if ('serviceWorker' in navigator && navigator.serviceWorker.controller) {
+        navigator.serviceWorker.controller.postMessage('clear');
+    }
+}
+
+function initOfflineSupport() {
+    registerServiceWorker();
+    window.addEventListener('online', replayOfflineQueue);
+    replayOfflineQueue();
+}
+
 // Initialize user menu
 function initUserMenu() {
     const userMenuBtn = document.getElementById('userMenuBtn');
@@ -7736,6 +8851,7 @@ function initUserMenu() {
         logoutBtn.addEventListener('click', async (e) => {
             e.preventDefault();
             try {
+                await clearOfflineData();
                 await fetch(basePath + '/api/auth/logout', { method: 'POST' });
             } catch (err) {
                 console.error('Logout error:', err);
@@ -8078,6 +9194,9 @@ function openSettingsModal() {
         const tabName = activeTab.dataset.tab;
         if (tabName === 'users') {
             loadSettingsUsersList();
+        } else if (tabName === 'data') {
+            loadTrash();
+            loadApiTokens();
         } else if (tabName === 'stats') {
             loadUsageStats();
         }
@@ -8108,6 +9227,9 @@ function initSettingsTabs() {
                 loadSettingsUsersList();
             } else if (tabName === 'links') {
                 loadSharedLinks();
+            } else if (tabName === 'data') {
+                loadTrash();
+                loadApiTokens();
             } else if (tabName === 'stats') {
                 loadUsageStats();
             } else if (tabName === 'about') {
@@ -8124,6 +9246,7 @@ function initGeneralSettings() {
     const autoSaveToggle = document.getElementById('settingsAutoSave');
     const lineNumbersToggle = document.getElementById('settingsLineNumbers');
     const fontSizeSelect = document.getElementById('settingsFontSize');
+    const timezoneSelect = document.getElementById('settingsTimezone');
 
     if (themeSelect) {
         themeSelect.addEventListener('change', () => {
@@ -8163,6 +9286,21 @@ function initGeneralSettings() {
             applyFontSize(fontSize);
         });
     }
+
+    if (timezoneSelect) {
+        const zones = typeof Intl.supportedValuesOf === 'function' ? Intl.supportedValuesOf('timeZone') : [browserTimezone()];
+        zones.forEach(zone => {
+            const option = document.createElement('option');
+            option.value = zone;
+            option.textContent = zone.replace(/_/g, ' ');
+            timezoneSelect.appendChild(option);
+        });
+        timezoneSelect.addEventListener('change', async () => {
+            if (await saveTimezonePreference(timezoneSelect.value)) {
+                loadNotes();
+            }
+        });
+    }
 }
 
 function applyLineNumbersSetting(enabled) {
@@ -8227,6 +9365,11 @@ function loadGeneralSettings() {
         fontSizeSelect.value = savedFontSize;
         applyFontSize(savedFontSize);
     }
+
+    const timezoneSelect = document.getElementById('settingsTimezone');
+    if (timezoneSelect) {
+        timezoneSelect.value = userTimezone || browserTimezone();
+    }
 }
 
 // Settings Users (Admin only)
@@ -8356,7 +9499,15 @@ async function loadSharedLinks() {
                             <span class="shared-link-expiry ${expiryInfo.class}">
                                 ${expiryInfo.icon} ${expiryInfo.text}
                             </span>
+                            <span class="shared-link-views" onclick="toggleSharedLinkViews('${escapeHtml(link.code)}', this)" title="${i18n.t('settings.viewsLast30Days') || 'Views in the last 30 days'}">
+                                &#128065; ${link.views || 0}
+                            </span>
+                            <span class="shared-link-total-views" onclick="updateSharedLinkMaxViews('${escapeHtml(link.code)}', ${link.max_views || 0})" title="${i18n.t('settings.totalViewsHint')}">
+                                &#931; ${link.total_views || 0}${link.max_views ? ` / ${link.max_views}` : ''}
+                            </span>
+                            ${link.last_access_at ? `<span class="shared-link-last-access" title="${escapeHtml(link.last_access_ip || '')}">${i18n.t('settings.lastAccess')}: ${formatDateYMD(new Date(link.last_access_at))}</span>` : ''}
                         </div>
+                        <div class="shared-link-views-chart" style="display: none;"></div>
                     </div>
                     <div class="shared-link-actions">
                         <div class="expiry-date-wrapper" onclick="this.querySelector('input').showPicker()">
@@ -8467,6 +9618,70 @@ async function updateSharedLinkExpiryDate(code, dateStr) {
     await updateSharedLinkExpiry(code, diffDays);
 }
 
+// Ask for the number of views after which a link expires (0 = unlimited)
+async function updateSharedLinkMaxViews(code, current) {
+    const value = prompt(i18n.t('settings.maxViewsPrompt'), current || 0);
+    if (value === null) return;
+
+    const maxViews = parseInt(value, 10);
+    if (isNaN(maxViews) || maxViews < 0) {
+        alert(i18n.t('settings.invalidMaxViews'));
+        return;
+    }
+
+    try {
+        const response = await fetch(basePath + `/api/shortlinks/${code}`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ max_views: maxViews })
+        });
+        if (!response.ok) throw new Error('Failed to update max views');
+        await loadSharedLinks();
+    } catch (err) {
+        console.error('Error updating shared link:', err);
+        alert(i18n.t('settings.updateFailed') || 'Failed to update');
+    }
+}
+
+async function toggleSharedLinkViews(code, toggleEl) {
+    const chart = toggleEl.closest('.shared-link-info').querySelector('.shared-link-views-chart');
+    if (chart.style.display !== 'none') {
+        chart.style.display = 'none';
+        return;
+    }
+
+    try {
+        const response = await fetch(basePath + `/api/shortlinks/${code}/views?days=30`);
+        if (!response.ok) throw new Error('Failed to load views');
+        const stats = await response.json();
+
+        const max = Math.max(1, ...stats.days.map(d => d.views));
+        const bars = stats.days.map(d => {
+            const tooltip = stats.unique_visitors
+                ? `${d.day}: ${d.views} ${i18n.t('settings.views') || 'views'}, ${d.visitors} ${i18n.t('settings.visitors') || 'visitors'}`
+                : `${d.day}: ${d.views} ${i18n.t('settings.views') || 'views'}`;
+            return `<div class="shared-link-views-bar" style="height: ${Math.round(d.views / max * 100)}%" title="${escapeHtml(tooltip)}"></div>`;
+        }).join('');
+
+        let summary = `${stats.views} ${i18n.t('settings.views') || 'views'}`;
+        if (stats.unique_visitors) {
+            summary += ` · ${stats.visitors} ${i18n.t('settings.visitors') || 'visitors'}`;
+        }
+        if (!stats.enabled) {
+            summary = i18n.t('settings.viewTrackingDisabled') || 'View tracking is disabled';
+        }
+
+        chart.innerHTML = `
+            <div class="shared-link-views-bars">${bars}</div>
+            <div class="shared-link-views-summary">${escapeHtml(summary)}</div>
+        `;
+        chart.style.display = 'block';
+    } catch (err) {
+        console.error('Error loading link views:', err);
+        showToast(i18n.t('settings.viewsLoadFailed') || 'Failed to load views');
+    }
+}
+
 async function deleteSharedLink(code) {
     if (!confirm(i18n.t('settings.deleteSharedLinkConfirm') || 'Are you sure you want to delete this shared link?')) {
         return;
@@ -8546,6 +9761,11 @@ function initDataManagement() {
     const importFileInput = document.getElementById('importFileInput');
     const deleteAllBtn = document.getElementById('deleteAllNotesBtn');
     const refreshStatsBtn = document.getElementById('refreshStatsBtn');
+    const exportSettingsBtn = document.getElementById('exportSettingsBtn');
+    const importSettingsBtn = document.getElementById('importSettingsBtn');
+    const importSettingsInput = document.getElementById('importSettingsInput');
+    const emptyTrashBtn = document.getElementById('emptyTrashBtn');
+    const createApiTokenBtn = document.getElementById('createApiTokenBtn');
 
     if (exportBtn) {
         exportBtn.addEventListener('click', exportNotes);
@@ -8568,6 +9788,249 @@ function initDataManagement() {
     if (refreshStatsBtn) {
         refreshStatsBtn.addEventListener('click', refreshStats);
     }
+
+    if (exportSettingsBtn) {
+        exportSettingsBtn.addEventListener('click', exportUISettings);
+    }
+
+    if (importSettingsBtn && importSettingsInput) {
+        importSettingsBtn.addEventListener('click', () => importSettingsInput.click());
+        importSettingsInput.addEventListener('change', handleImportSettingsFile);
+    }
+
+    if (emptyTrashBtn) {
+        emptyTrashBtn.addEventListener('click', emptyTrash);
+    }
+
+    if (createApiTokenBtn) {
+        createApiTokenBtn.addEventListener('click', createApiToken);
+    }
+}
+
+// Show the API tokens of the user with revoke buttons; a token just created is shown once above them
+async function loadApiTokens(created = null) {
+    const list = document.getElementById('apiTokensList');
+    if (!list) return;
+
+    try {
+        const response = await authFetch(basePath + '/api/tokens');
+        if (!response.ok) throw new Error('Failed to load tokens');
+        const tokens = await response.json();
+
+        let html = '';
+        if (created) {
+            html += `
+                <div class="trash-item api-token-created">
+                    <span class="trash-item-icon">&#128273;</span>
+                    <div class="trash-item-info">
+                        <input type="text" class="api-token-value" value="${escapeHtml(created)}" readonly>
+                        <span class="trash-item-meta">${escapeHtml(i18n.t('settings.tokenShownOnce'))}</span>
+                    </div>
+                    <button class="btn btn-secondary btn-sm api-token-copy">${escapeHtml(i18n.t('share.copy'))}</button>
+                </div>`;
+        }
+        html += tokens.map(token => {
+            const details = [token.scopes.join(', '), i18n.t('settings.tokenExpires', { date: formatDate(token.expires_at) })];
+            if (token.last_used_at) details.push(i18n.t('settings.tokenLastUsed', { date: formatDate(token.last_used_at) }));
+            return `
+                <div class="trash-item" data-id="${token.id}" data-name="${escapeHtml(token.name)}">
+                    <span class="trash-item-icon">&#128273;</span>
+                    <div class="trash-item-info">
+                        <span class="trash-item-title">${escapeHtml(token.name)}</span>
+                        <span class="trash-item-meta">${escapeHtml(details.join(' · '))}</span>
+                    </div>
+                    <button class="btn btn-danger btn-sm api-token-revoke">${escapeHtml(i18n.t('settings.revoke'))}</button>
+                </div>`;
+        }).join('');
+        list.innerHTML = html;
+
+        const copyBtn = list.querySelector('.api-token-copy');
+        if (copyBtn) {
+            copyBtn.addEventListener('click', () => copyToClipboard(created));
+            list.querySelector('.api-token-value').select();
+        }
+        list.querySelectorAll('.trash-item[data-id]').forEach(row => {
+            row.querySelector('.api-token-revoke').addEventListener('click', () => revokeApiToken(row.dataset.id, row.dataset.name));
+        });
+    } catch (err) {
+        console.error('API tokens error:', err);
+        list.innerHTML = '';
+    }
+}
+
+async function createApiToken() {
+    const name = await showPromptModal({
+        title: i18n.t('settings.createToken'),
+        message: i18n.t('settings.tokenName'),
+        placeholder: i18n.t('settings.tokenNamePlaceholder')
+    });
+    if (!name) return;
+
+    const scopes = document.getElementById('apiTokenScope').value.split(',');
+    try {
+        const response = await authFetch(basePath + '/api/tokens', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ name, scopes })
+        });
+        const result = await response.json();
+        if (!response.ok) throw new Error(result.error || 'Create token failed');
+        loadApiTokens(result.token);
+    } catch (err) {
+        console.error('Create token error:', err);
+        showToast(err.message, 'error');
+    }
+}
+
+async function revokeApiToken(id, name) {
+    const confirmed = await showConfirmModal({
+        title: i18n.t('settings.revoke'),
+        message: i18n.t('settings.confirmRevokeToken', { name }),
+        confirmText: i18n.t('settings.revoke'),
+        danger: true
+    });
+    if (!confirmed) return;
+
+    try {
+        const response = await authFetch(basePath + `/api/tokens/${encodeURIComponent(id)}`, { method: 'DELETE' });
+        const result = await response.json();
+        if (!response.ok) throw new Error(result.error || 'Revoke failed');
+        loadApiTokens();
+    } catch (err) {
+        console.error('Revoke token error:', err);
+        showToast(err.message, 'error');
+    }
+}
+
+// Show deleted notes and folders with restore and delete buttons
+async function loadTrash() {
+    const list = document.getElementById('trashList');
+    if (!list) return;
+
+    try {
+        const response = await authFetch(basePath + '/api/trash');
+        if (!response.ok) throw new Error('Failed to load trash');
+        const items = await response.json();
+
+        if (items.length === 0) {
+            list.innerHTML = `<div class="trash-empty">${escapeHtml(i18n.t('settings.trashEmpty'))}</div>`;
+            return;
+        }
+
+        list.innerHTML = items.map(item => {
+            const details = [formatDate(item.deleted)];
+            if (item.folder) details.push(i18n.t('settings.trashNotes', { count: item.note_count }));
+            if (item.expires) details.push(i18n.t('settings.trashExpires', { date: formatDate(item.expires) }));
+            return `
+                <div class="trash-item" data-id="${escapeHtml(item.id)}" data-title="${escapeHtml(item.title)}">
+                    <span class="trash-item-icon">${item.folder ? '&#128193;' : '&#128196;'}</span>
+                    <div class="trash-item-info">
+                        <span class="trash-item-title">${escapeHtml(item.title)}</span>
+                        <span class="trash-item-meta">${escapeHtml(details.join(' · '))}</span>
+                    </div>
+                    <button class="btn btn-secondary btn-sm trash-restore">${escapeHtml(i18n.t('settings.restore'))}</button>
+                    <button class="btn btn-danger btn-sm trash-delete">${escapeHtml(i18n.t('settings.deleteForever'))}</button>
+                </div>`;
+        }).join('');
+
+        list.querySelectorAll('.trash-item').forEach(row => {
+            row.querySelector('.trash-restore').addEventListener('click', () => restoreTrashItem(row.dataset.id, row.dataset.title));
+            row.querySelector('.trash-delete').addEventListener('click', () => deleteTrashItem(row.dataset.id, row.dataset.title));
+        });
+    } catch (err) {
+        console.error('Trash error:', err);
+        list.innerHTML = '';
+    }
+}
+
+async function restoreTrashItem(id, title) {
+    try {
+        const response = await authFetch(basePath + `/api/trash/${encodeURIComponent(id)}/restore`, { method: 'POST' });
+        const result = await response.json();
+        if (!response.ok) throw new Error(result.error || 'Restore failed');
+
+        showToast(i18n.t('settings.restored', { title }));
+        await loadNotes();
+        loadTrash();
+    } catch (err) {
+        console.error('Restore error:', err);
+        showToast(err.message);
+    }
+}
+
+async function deleteTrashItem(id, title) {
+    if (!confirm(i18n.t('settings.confirmDeleteTrashItem', { title }))) return;
+
+    try {
+        const response = await authFetch(basePath + `/api/trash/${encodeURIComponent(id)}`, { method: 'DELETE' });
+        const result = await response.json();
+        if (!response.ok) throw new Error(result.error || 'Delete failed');
+        loadTrash();
+    } catch (err) {
+        console.error('Trash delete error:', err);
+        showToast(err.message);
+    }
+}
+
+async function emptyTrash() {
+    if (!confirm(i18n.t('settings.confirmEmptyTrash'))) return;
+
+    try {
+        const response = await authFetch(basePath + '/api/trash', { method: 'DELETE' });
+        const result = await response.json();
+        if (!response.ok) throw new Error(result.error || 'Empty trash failed');
+        loadTrash();
+    } catch (err) {
+        console.error('Empty trash error:', err);
+        showToast(err.message);
+    }
+}
+
+// Download folder icons, folder order, note sort, folder metadata and preferences as JSON
+async function exportUISettings() {
+    try {
+        const response = await authFetch(basePath + '/api/settings/export');
+        if (!response.ok) throw new Error('Export failed');
+
+        const blob = await response.blob();
+        const url = window.URL.createObjectURL(blob);
+        const a = document.createElement('a');
+        a.href = url;
+        a.download = `gitnotepad-settings-${new Date().toISOString().split('T')[0]}.json`;
+        document.body.appendChild(a);
+        a.click();
+        document.body.removeChild(a);
+        window.URL.revokeObjectURL(url);
+    } catch (err) {
+        console.error('Settings export error:', err);
+        showToast(i18n.t('settings.settingsExportFailed'));
+    }
+}
+
+// Restore UI settings from an exported file, then reload so they apply everywhere
+async function handleImportSettingsFile(e) {
+    const file = e.target.files[0];
+    if (!file) return;
+
+    try {
+        const formData = new FormData();
+        formData.append('file', file);
+
+        const response = await authFetch(basePath + '/api/settings/import', {
+            method: 'POST',
+            body: formData
+        });
+        const result = await response.json();
+        if (!response.ok) throw new Error(result.error || 'Import failed');
+
+        alert(i18n.t('settings.settingsImported', { count: result.imported }));
+        location.reload();
+    } catch (err) {
+        console.error('Settings import error:', err);
+        showToast(i18n.t('settings.settingsImportFailed') + ': ' + err.message);
+    } finally {
+        e.target.value = '';
+    }
 }
 
 async function refreshStats() {
@@ -8586,6 +10049,18 @@ async function refreshStats() {
 }
 
 async function exportNotes() {
+    // Encrypted notes are exported as stored unless the user asks for plaintext
+    let decrypt = false;
+    if (notes.some(n => n.encrypted)) {
+        decrypt = await showConfirmModal({
+            title: i18n.t('export.decryptTitle'),
+            message: i18n.t('export.decryptMessage'),
+            confirmText: i18n.t('export.decrypt'),
+            cancelText: i18n.t('export.keepEncrypted'),
+            danger: true
+        });
+    }
+
     const exportBtn = document.getElementById('exportNotesBtn');
     if (exportBtn) {
         exportBtn.disabled = true;
@@ -8593,14 +10068,14 @@ async function exportNotes() {
     }
 
     try {
-        const response = await authFetch(basePath + '/api/notes/export');
+        const response = await authFetch(basePath + '/api/notes/export' + (decrypt ? '?decrypt=true' : ''));
         if (!response.ok) throw new Error('Export failed');
 
         const blob = await response.blob();
         const url = window.URL.createObjectURL(blob);
         const a = document.createElement('a');
         a.href = url;
-        a.download = `notes-export-${new Date().toISOString().split('T')[0]}.zip`;
+        a.download = `notes-export${decrypt ? '-decrypted' : ''}-${new Date().toISOString().split('T')[0]}.zip`;
         document.body.appendChild(a);
         a.click();
         document.body.removeChild(a);
@@ -8701,6 +10176,36 @@ async function exportFolder(folderPath) {
     }
 }
 
+// Export an ad-hoc selection of notes (e.g. all notes with a tag) with their attachments
+async function exportSelectedNotes(ids, name) {
+    if (!ids || ids.length === 0) return;
+
+    try {
+        const response = await authFetch('/api/notes/export', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ ids })
+        });
+        if (!response.ok) throw new Error('Export failed');
+
+        const blob = await response.blob();
+        const url = window.URL.createObjectURL(blob);
+        const a = document.createElement('a');
+        a.href = url;
+        const safeName = name.replace(/[\/\\:*?"<>|]/g, '-');
+        a.download = `notes-${safeName}-export-${new Date().toISOString().split('T')[0]}.zip`;
+        document.body.appendChild(a);
+        a.click();
+        document.body.removeChild(a);
+        window.URL.revokeObjectURL(url);
+
+        showToast(i18n.t('tags.exported', { count: ids.length }));
+    } catch (err) {
+        console.error('Selected notes export error:', err);
+        alert(i18n ? i18n.t('error.exportFailed') : 'Export failed');
+    }
+}
+
 async function importToFolder(folderPath) {
     if (!folderPath) return;
 
@@ -8958,6 +10463,8 @@ function updateScreenWidth() {
 // Mini Calendar State
 let miniCalCurrentDate = new Date();
 let miniCalSelectedDate = null;
+let miniCalDays = {}; // dateKey -> { created: [...] } for the visible range
+let miniCalRange = '';
 
 function initMiniCalendar() {
     const miniCalPrev = document.getElementById('miniCalPrev');
@@ -9044,9 +10551,6 @@ function renderMiniCalendar() {
     // Get days from previous month
     const prevMonthLastDay = new Date(year, month, 0).getDate();
 
-    // Build notes map by date
-    const notesMap = buildNotesMapByDate();
-
     // Today's date for comparison
     const today = new Date();
     const todayStr = formatDateKey(today);
@@ -9082,7 +10586,7 @@ function renderMiniCalendar() {
         }
 
         const dateKey = formatDateKey(dateObj);
-        const notesForDay = notesMap[dateKey] || [];
+        const notesForDay = (miniCalDays[dateKey] && miniCalDays[dateKey].created) || [];
         const isToday = dateKey === todayStr;
         const isSelected = miniCalSelectedDate && dateKey === formatDateKey(miniCalSelectedDate);
 
@@ -9101,6 +10605,29 @@ function renderMiniCalendar() {
     }
 
     miniCalGrid.innerHTML = html;
+
+    // Fetch only the visible 6 weeks instead of bucketing the whole notes list
+    const from = formatDateKey(new Date(year, month, 1 - startDayOfWeek));
+    const to = formatDateKey(new Date(year, month, totalCells - startDayOfWeek));
+    loadMiniCalendarRange(from, to);
+}
+
+async function loadMiniCalendarRange(from, to) {
+    const range = `${from}:${to}`;
+    miniCalRange = range;
+    try {
+        const response = await authFetch(`/api/calendar?from=${from}&to=${to}`);
+        if (!response.ok || miniCalRange !== range) return;
+        const data = await response.json();
+        if (miniCalRange !== range) return;
+        miniCalDays = data.days || {};
+        document.querySelectorAll('#miniCalGrid .mini-cal-day').forEach(el => {
+            const day = miniCalDays[el.dataset.date];
+            el.classList.toggle('has-notes', !!(day && day.created.length > 0));
+        });
+    } catch (error) {
+        console.error('Failed to load calendar:', error);
+    }
 }
 
 function createNoteForMiniCalDate(dateKey) {
@@ -9169,11 +10696,17 @@ function showDateNotesPanel(dateKey) {
     title.textContent = date.toLocaleDateString(locale === 'ko' ? 'ko-KR' : 'en-US', options);
 
     // Get notes for this date
-    const notesMap = buildNotesMapByDate();
-    const dateNotes = notesMap[dateKey] || [];
-
-    // Render notes list
-    renderDateNotesList(list, dateNotes);
+    const day = miniCalDays[dateKey];
+    renderDateNotesList(list, (day && day.created) || []);
+    authFetch(`/api/calendar?from=${dateKey}&to=${dateKey}`)
+        .then(response => response.ok ? response.json() : null)
+        .then(data => {
+            const fresh = data && data.days && data.days[dateKey];
+            if (fresh && miniCalSelectedDate && formatDateKey(miniCalSelectedDate) === dateKey) {
+                renderDateNotesList(list, fresh.created);
+            }
+        })
+        .catch(() => {});
 
     // Show panel, hide other views
     panel.style.display = 'flex';
@@ -9242,25 +10775,6 @@ function renderDateNotesList(container, dateNotes) {
     });
 }
 
-function buildNotesMapByDate() {
-    const map = {};
-
-    notes.forEach(note => {
-        // Use created date for mapping
-        const dateStr = note.created || note.modified;
-        if (dateStr) {
-            const date = new Date(dateStr);
-            const dateKey = formatDateKey(date);
-            if (!map[dateKey]) {
-                map[dateKey] = [];
-            }
-            map[dateKey].push(note);
-        }
-    });
-
-    return map;
-}
-
 function formatDateKey(date) {
     const year = date.getFullYear();
     const month = String(date.getMonth() + 1).padStart(2, '0');
@@ -9444,6 +10958,7 @@ function initLocaleSelector() {
             const locale = option.dataset.locale;
             i18n.setLocale(locale);
             updateLocaleDisplay();
+            saveLanguagePreference(locale);
             localeSelector.classList.remove('open');
         });
     });
@@ -9457,6 +10972,63 @@ function initLocaleSelector() {
 
     // Initialize display
     updateLocaleDisplay();
+
+    // Apply the language saved on the server (follows the user across devices)
+    loadLanguagePreference().then(updateLocaleDisplay);
+}
+
+// Load the language and time zone preferences of the signed-in user (no-op when auth is disabled)
+async function loadLanguagePreference() {
+    try {
+        const response = await fetch(`${basePath}/api/preferences`);
+        if (!response.ok) return;
+        const prefs = await response.json();
+        if (prefs.language && prefs.language !== i18n.getLocale()) {
+            i18n.setLocale(prefs.language);
+        }
+        if (prefs.timezone) {
+            userTimezone = prefs.timezone;
+        } else {
+            // First visit: adopt the browser's time zone so the calendar matches it
+            saveTimezonePreference(browserTimezone());
+        }
+    } catch (e) {
+        console.error('Failed to load preferences:', e);
+    }
+}
+
+// Save the language so server messages (API errors, Telegram replies) use it too
+async function saveLanguagePreference(locale) {
+    try {
+        await fetch(`${basePath}/api/preferences`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ language: locale })
+        });
+    } catch (e) {
+        console.error('Failed to save language preference:', e);
+    }
+}
+
+// Save the time zone used for note dates, calendar days and Telegram titles
+async function saveTimezonePreference(timezone) {
+    try {
+        const response = await fetch(`${basePath}/api/preferences`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ timezone })
+        });
+        if (!response.ok) return false;
+        userTimezone = timezone;
+        return true;
+    } catch (e) {
+        console.error('Failed to save time zone preference:', e);
+        return false;
+    }
+}
+
+function browserTimezone() {
+    return Intl.DateTimeFormat().resolvedOptions().timeZone;
 }
 
 // ============================================
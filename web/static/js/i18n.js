@@ -62,12 +62,15 @@ const i18n = {
             'editor.preview': 'Preview',
             'editor.editor': 'Editor',
             'editor.history': 'Version History',
+            'editor.print': 'Print',
+            'editor.printPrivate': 'Password-protected notes cannot be printed',
             'editor.private': 'Private',
             'editor.public': 'Public',
             'editor.saving': 'Saving...',
             'editor.saved': 'Saved',
             'editor.unsaved': 'Unsaved',
             'editor.upload': 'Upload file',
+            'editor.uploadUrl': 'Attach from URL',
             'editor.formatJson': 'Format JSON',
             'editor.formatJsonShortcut': 'Format JSON (Ctrl+Shift+F)',
             'editor.fullscreen': 'Fullscreen',
@@ -219,6 +222,16 @@ const i18n = {
             'share.privateInfo': 'Only accessible by authenticated users',
             'share.publicInfo': 'Anyone with the link can view this note',
             'share.visibilityUpdated': 'Visibility updated!',
+            'share.password': 'Link password:',
+            'share.passwordPlaceholder': 'Optional password',
+            'share.setPassword': 'Set',
+            'share.removePassword': 'Remove',
+            'share.passwordSetInfo': 'Visitors must enter the password to view this note',
+            'share.noPasswordInfo': 'No password is required',
+            'share.passwordUpdated': 'Password updated!',
+            'share.customCodePlaceholder': 'Custom code, e.g. meeting-notes',
+            'share.setCustomCode': 'Change',
+            'share.customCodeUpdated': 'Link changed!',
 
             // Settings
             'settings.title': 'Settings',
@@ -239,6 +252,8 @@ const i18n = {
             'settings.lineNumbersDesc': 'Show line numbers in editor',
             'settings.fontSize': 'Font Size',
             'settings.fontSizeDesc': 'Editor and preview font size',
+            'settings.timezone': 'Time Zone',
+            'settings.timezoneDesc': 'Dates of notes, the calendar and Telegram titles',
             'settings.autoSave': 'Auto Save',
             'settings.autoSaveDesc': 'Automatically save changes',
             'settings.defaultType': 'Default Note Type',
@@ -249,6 +264,37 @@ const i18n = {
             'settings.exportNotesDesc': 'Download all your notes as a ZIP file',
             'settings.importNotes': 'Import Notes',
             'settings.importNotesDesc': 'Import notes from a ZIP file',
+            'maintenance.banner': 'The server is in maintenance mode: notes can be read but changes cannot be saved.',
+            'settings.uiSettings': 'UI Settings',
+            'settings.uiSettingsDesc': 'Folder icons, folder order, sorting and preferences as a JSON file',
+            'settings.apiTokens': 'API Tokens',
+            'settings.apiTokensDesc': 'Let scripts, CLI tools and shortcuts use the API with an Authorization: Bearer header',
+            'settings.tokenReadOnly': 'Read only',
+            'settings.tokenReadWrite': 'Read & write',
+            'settings.createToken': 'Create Token',
+            'settings.tokenName': 'Token name',
+            'settings.tokenNamePlaceholder': 'e.g. Laptop CLI',
+            'settings.tokenShownOnce': 'Copy this token now, it is not shown again',
+            'settings.tokenExpires': 'expires {date}',
+            'settings.tokenLastUsed': 'last used {date}',
+            'settings.revoke': 'Revoke',
+            'settings.confirmRevokeToken': 'Revoke the token "{name}"? Tools using it stop working.',
+            'settings.exportSettings': 'Export',
+            'settings.importSettings': 'Import',
+            'settings.settingsImported': 'Imported {count} settings',
+            'settings.settingsExportFailed': 'Failed to export settings',
+            'settings.settingsImportFailed': 'Failed to import settings',
+            'settings.trash': 'Trash',
+            'settings.trashDesc': 'Deleted notes and folders can be restored until they are purged',
+            'settings.emptyTrash': 'Empty Trash',
+            'settings.trashEmpty': 'The trash is empty',
+            'settings.trashExpires': 'purged {date}',
+            'settings.trashNotes': '{count} notes',
+            'settings.restore': 'Restore',
+            'settings.deleteForever': 'Delete',
+            'settings.confirmEmptyTrash': 'Permanently delete everything in the trash?',
+            'settings.confirmDeleteTrashItem': 'Permanently delete "{title}"?',
+            'settings.restored': 'Restored "{title}"',
             'settings.deleteAllNotes': 'Delete All Notes',
             'settings.deleteAllNotesDesc': 'Permanently delete all your notes',
             'settings.usageStatistics': 'Usage Statistics',
@@ -257,6 +303,15 @@ const i18n = {
             'settings.noSharedLinks': 'No shared links yet',
             'settings.clickToCopy': 'Click to copy',
             'settings.created': 'Created',
+            'settings.views': 'views',
+            'settings.visitors': 'visitors',
+            'settings.viewsLast30Days': 'Views in the last 30 days (click for daily views)',
+            'settings.totalViewsHint': 'Total views / view limit (click to set the limit)',
+            'settings.lastAccess': 'Last access',
+            'settings.maxViewsPrompt': 'Number of views after which the link expires (0 = unlimited):',
+            'settings.invalidMaxViews': 'Please enter 0 or a positive number',
+            'settings.viewTrackingDisabled': 'View tracking is disabled',
+            'settings.viewsLoadFailed': 'Failed to load views',
             'settings.changeExpiry': 'Change expiry',
             'settings.expiry': 'Expiry',
             'settings.never': 'Never',
@@ -350,7 +405,7 @@ const i18n = {
             'stats.refresh': 'Refresh',
 
             // Messages
-            'msg.confirmDelete': 'Are you sure you want to delete this note?',
+            'msg.confirmDelete': 'Move this note to the trash?',
             'msg.confirmDeleteAll': 'Are you sure you want to delete ALL notes? This cannot be undone.',
             'msg.enterTitle': 'Please enter a title',
             'msg.invalidPassword': 'Invalid password',
@@ -382,9 +437,15 @@ const i18n = {
             'error.invalidJson': 'Invalid JSON',
             'error.uploadImageFailed': 'Failed to upload image',
             'error.uploadFileFailed': 'Failed to upload file',
+            'error.uploadTooLarge': 'The file is larger than the upload limit',
+            'error.uploadTypeNotAllowed': 'This file type cannot be uploaded',
             'error.saveFailed': 'Failed to save note',
             'error.deleteFailed': 'Failed to delete note',
             'error.exportFailed': 'Failed to export notes',
+            'export.decryptTitle': 'Export Encrypted Notes',
+            'export.decryptMessage': 'Some notes are stored encrypted and can only be read in GitNotepad. Decrypt them in the export? Anyone with the ZIP file can then read them.',
+            'export.decrypt': 'Decrypt',
+            'export.keepEncrypted': 'Keep Encrypted',
             'error.importFailed': 'Failed to import notes',
             'error.deleteAllFailed': 'Failed to delete notes',
 
@@ -395,6 +456,18 @@ const i18n = {
 
             // Confirm dialogs
             'confirm.deleteNote': 'Delete "{title}"?',
+            'conflict.title': 'Note Changed Elsewhere',
+            'conflict.message': 'This note was saved on another device at {time}. Overwrite it with your version?',
+            'conflict.overwrite': 'Overwrite',
+            'conflict.loadTheirs': 'Open Their Version',
+            'conflict.autoSave': 'Auto-save stopped: the note was changed on another device',
+            'draft.title': 'Unsaved Draft',
+            'draft.restore': 'You have unsaved edits from {time}. Restore them?',
+            'draft.restoreStale': 'You have unsaved edits from {time}, but the note was saved since. Restore them?',
+            'draft.restoreButton': 'Restore',
+            'draft.discardButton': 'Discard',
+            'lock.editedBy': 'Being edited by {user}',
+            'lock.editedElsewhere': 'Being edited in another session',
             'confirm.unsavedChanges': 'You have unsaved changes. Are you sure you want to close?',
             'confirm.discardChanges': 'You have unsaved changes. Do you want to discard them?',
 
@@ -406,6 +479,13 @@ const i18n = {
             'context.edit': 'Edit',
             'context.rename': 'Rename',
             'context.duplicate': 'Duplicate',
+            'context.exportHtml': 'Export as HTML',
+            'context.exportPdf': 'Export as PDF',
+            'context.exportSource': 'Export Source',
+            'context.archive': 'Archive',
+            'context.unarchive': 'Unarchive',
+            'context.showArchived': 'Show Archived Notes',
+            'context.hideArchived': 'Hide Archived Notes',
             'context.move': 'Move to...',
             'context.changeIcon': 'Change Icon',
             'context.history': 'History',
@@ -416,6 +496,7 @@ const i18n = {
             'context.newFolder': 'New Folder',
             'context.newNoteInFolder': 'New Note Here',
             'context.newSubfolder': 'New Subfolder',
+            'context.clipPage': 'Clip Web Page',
             'context.expand': 'Expand',
             'context.expandAll': 'Expand All',
             'context.collapse': 'Collapse',
@@ -423,6 +504,9 @@ const i18n = {
             'context.renameFolder': 'Rename Folder',
             'context.moveFolderUp': 'Move Up',
             'context.moveFolderDown': 'Move Down',
+            'context.sortByTitle': 'Sort Notes by Title',
+            'context.sortByModified': 'Sort Notes by Modified',
+            'context.sortByCreated': 'Sort Notes by Created',
             'context.shareFolder': 'Share Folder',
             'context.exportFolder': 'Export Folder',
             'context.importToFolder': 'Import to Folder',
@@ -454,6 +538,7 @@ const i18n = {
             'noteInfo.none': 'None',
             'noteInfo.encrypted': 'Encrypted',
             'noteInfo.shared': 'Shared',
+            'noteInfo.backlinks': 'Backlinks',
             'noteInfo.yes': 'Yes',
             'noteInfo.no': 'No',
             'noteInfo.created': 'Created',
@@ -499,6 +584,9 @@ const i18n = {
 
             // Toast
             'toast.noteDecrypted': 'Note decrypted successfully',
+            'toast.noteArchived': 'Note archived',
+            'toast.exportFailed': 'Failed to export note',
+            'toast.noteUnarchived': 'Note restored from archive',
             'toast.codeCopied': 'Code copied!',
             'toast.copyFailed': 'Failed to copy',
 
@@ -521,6 +609,23 @@ const i18n = {
 
             // Attachment
             'attachment.removeConfirm': 'Remove this attachment?',
+            'attachment.rename': 'Rename',
+            'attachment.renamePrompt': 'Name shown for the attachment:',
+            'attachment.urlPrompt': 'URL of the image or file to attach:',
+            'clip.urlPrompt': 'URL of the page to save as a note:',
+            'clip.clipping': 'Clipping page...',
+            'clip.clipped': 'Page clipped',
+            'clip.failed': 'Failed to clip page',
+            'offline.showingOffline': 'Offline: showing saved notes',
+            'offline.savedOffline': 'Saved offline; will sync when back online',
+            'offline.synced': 'Offline changes synced',
+            'offline.syncedConflicts': 'Offline changes synced; {count} note(s) changed meanwhile were saved as copies',
+            'offline.syncFailed': '{count} offline change(s) could not be synced',
+            'attachment.renamed': 'Attachment renamed',
+            'attachment.renameFailed': 'Failed to rename attachment',
+            'attachment.uploadVersion': 'Upload new version',
+            'attachment.versionUploaded': 'New version uploaded (previous version kept)',
+            'attachment.uploadVersionFailed': 'Failed to upload new version',
             'attachment.linkInContentWarning': '\n\nThis attachment is referenced {count} time(s) in the note. References will also be removed.',
 
             // Date Notes Panel
@@ -541,6 +646,8 @@ const i18n = {
             'tags.addPlaceholder': 'Add tag...',
             'tags.createNew': 'Create new tag "{tag}"',
             'tags.noNotesWithTag': 'No notes with this tag',
+            'tags.exportNotes': 'Export these notes',
+            'tags.exported': '{count} note(s) exported',
         },
 
         ko: {
@@ -602,12 +709,15 @@ const i18n = {
             'editor.preview': '미리보기',
             'editor.editor': '편집기',
             'editor.history': '버전 기록',
+            'editor.print': '인쇄',
+            'editor.printPrivate': '비밀번호로 보호된 노트는 인쇄할 수 없습니다',
             'editor.private': '비공개',
             'editor.public': '공개',
             'editor.saving': '저장 중...',
             'editor.saved': '저장됨',
             'editor.unsaved': '저장 안됨',
             'editor.upload': '파일 업로드',
+            'editor.uploadUrl': 'URL로 첨부',
             'editor.formatJson': 'JSON 정리',
             'editor.formatJsonShortcut': 'JSON 정리 (Ctrl+Shift+F)',
             'editor.fullscreen': '전체화면',
@@ -759,6 +869,16 @@ const i18n = {
             'share.privateInfo': '인증된 사용자만 접근 가능',
             'share.publicInfo': '링크가 있는 모든 사용자가 이 노트를 볼 수 있습니다',
             'share.visibilityUpdated': '공개 설정이 변경되었습니다!',
+            'share.password': '링크 비밀번호:',
+            'share.passwordPlaceholder': '비밀번호 (선택)',
+            'share.setPassword': '설정',
+            'share.removePassword': '제거',
+            'share.passwordSetInfo': '방문자는 비밀번호를 입력해야 이 노트를 볼 수 있습니다',
+            'share.noPasswordInfo': '비밀번호가 필요하지 않습니다',
+            'share.passwordUpdated': '비밀번호가 변경되었습니다!',
+            'share.customCodePlaceholder': '사용자 지정 코드 (예: meeting-notes)',
+            'share.setCustomCode': '변경',
+            'share.customCodeUpdated': '링크가 변경되었습니다!',
 
             // Settings
             'settings.title': '설정',
@@ -779,6 +899,8 @@ const i18n = {
             'settings.lineNumbersDesc': '편집기에 줄 번호 표시',
             'settings.fontSize': '글꼴 크기',
             'settings.fontSizeDesc': '편집기 및 미리보기 글꼴 크기',
+            'settings.timezone': '시간대',
+            'settings.timezoneDesc': '노트 날짜, 캘린더, 텔레그램 제목에 사용',
             'settings.autoSave': '자동 저장',
             'settings.autoSaveDesc': '변경 사항을 자동으로 저장',
             'settings.defaultType': '기본 노트 형식',
@@ -789,6 +911,37 @@ const i18n = {
             'settings.exportNotesDesc': '모든 노트를 ZIP 파일로 다운로드',
             'settings.importNotes': '노트 가져오기',
             'settings.importNotesDesc': 'ZIP 파일에서 노트 가져오기',
+            'maintenance.banner': '서버 점검 중입니다: 노트를 읽을 수 있지만 변경 사항은 저장되지 않습니다.',
+            'settings.uiSettings': 'UI 설정',
+            'settings.uiSettingsDesc': '폴더 아이콘, 폴더 순서, 정렬, 환경 설정을 JSON 파일로 저장',
+            'settings.apiTokens': 'API 토큰',
+            'settings.apiTokensDesc': '스크립트, CLI 도구, 단축어에서 Authorization: Bearer 헤더로 API를 사용합니다',
+            'settings.tokenReadOnly': '읽기 전용',
+            'settings.tokenReadWrite': '읽기 및 쓰기',
+            'settings.createToken': '토큰 만들기',
+            'settings.tokenName': '토큰 이름',
+            'settings.tokenNamePlaceholder': '예: 노트북 CLI',
+            'settings.tokenShownOnce': '이 토큰은 다시 표시되지 않으니 지금 복사하세요',
+            'settings.tokenExpires': '{date} 만료',
+            'settings.tokenLastUsed': '{date} 마지막 사용',
+            'settings.revoke': '폐기',
+            'settings.confirmRevokeToken': '"{name}" 토큰을 폐기할까요? 이 토큰을 쓰는 도구는 더 이상 동작하지 않습니다.',
+            'settings.exportSettings': '내보내기',
+            'settings.importSettings': '가져오기',
+            'settings.settingsImported': '{count}개의 설정을 가져왔습니다',
+            'settings.settingsExportFailed': '설정 내보내기 실패',
+            'settings.settingsImportFailed': '설정 가져오기 실패',
+            'settings.trash': '휴지통',
+            'settings.trashDesc': '삭제한 노트와 폴더는 영구 삭제되기 전까지 복원할 수 있습니다',
+            'settings.emptyTrash': '휴지통 비우기',
+            'settings.trashEmpty': '휴지통이 비어 있습니다',
+            'settings.trashExpires': '{date} 영구 삭제',
+            'settings.trashNotes': '노트 {count}개',
+            'settings.restore': '복원',
+            'settings.deleteForever': '삭제',
+            'settings.confirmEmptyTrash': '휴지통의 모든 항목을 영구 삭제하시겠습니까?',
+            'settings.confirmDeleteTrashItem': '"{title}"을(를) 영구 삭제하시겠습니까?',
+            'settings.restored': '"{title}"을(를) 복원했습니다',
             'settings.deleteAllNotes': '모든 노트 삭제',
             'settings.deleteAllNotesDesc': '모든 노트를 영구적으로 삭제',
             'settings.usageStatistics': '사용 통계',
@@ -797,6 +950,15 @@ const i18n = {
             'settings.noSharedLinks': '공유 링크가 없습니다',
             'settings.clickToCopy': '클릭하여 복사',
             'settings.created': '생성일',
+            'settings.views': '조회',
+            'settings.visitors': '방문자',
+            'settings.viewsLast30Days': '최근 30일 조회수 (클릭하면 일별 조회수 표시)',
+            'settings.totalViewsHint': '전체 조회수 / 조회 한도 (클릭하면 한도 설정)',
+            'settings.lastAccess': '마지막 접속',
+            'settings.maxViewsPrompt': '링크가 만료될 조회수 (0 = 제한 없음):',
+            'settings.invalidMaxViews': '0 이상의 숫자를 입력하세요',
+            'settings.viewTrackingDisabled': '조회수 집계가 꺼져 있습니다',
+            'settings.viewsLoadFailed': '조회수를 불러오지 못했습니다',
             'settings.changeExpiry': '만료일 변경',
             'settings.expiry': '만료',
             'settings.never': '무기한',
@@ -890,7 +1052,7 @@ const i18n = {
             'stats.refresh': '새로고침',
 
             // Messages
-            'msg.confirmDelete': '이 노트를 삭제하시겠습니까?',
+            'msg.confirmDelete': '이 노트를 휴지통으로 옮기시겠습니까?',
             'msg.confirmDeleteAll': '모든 노트를 삭제하시겠습니까? 이 작업은 되돌릴 수 없습니다.',
             'msg.enterTitle': '제목을 입력하세요',
             'msg.invalidPassword': '비밀번호가 올바르지 않습니다',
@@ -922,9 +1084,15 @@ const i18n = {
             'error.invalidJson': '잘못된 JSON',
             'error.uploadImageFailed': '이미지 업로드 실패',
             'error.uploadFileFailed': '파일 업로드 실패',
+            'error.uploadTooLarge': '업로드 크기 제한을 초과한 파일입니다',
+            'error.uploadTypeNotAllowed': '업로드할 수 없는 파일 형식입니다',
             'error.saveFailed': '노트 저장 실패',
             'error.deleteFailed': '노트 삭제 실패',
             'error.exportFailed': '노트 내보내기 실패',
+            'export.decryptTitle': '암호화된 노트 내보내기',
+            'export.decryptMessage': '일부 노트는 암호화되어 있어 GitNotepad에서만 읽을 수 있습니다. 내보낼 때 복호화하시겠습니까? ZIP 파일을 가진 누구나 읽을 수 있게 됩니다.',
+            'export.decrypt': '복호화',
+            'export.keepEncrypted': '암호화 유지',
             'error.importFailed': '노트 가져오기 실패',
             'error.deleteAllFailed': '노트 삭제 실패',
 
@@ -935,6 +1103,18 @@ const i18n = {
 
             // Confirm dialogs
             'confirm.deleteNote': '"{title}" 삭제하시겠습니까?',
+            'conflict.title': '다른 곳에서 변경된 노트',
+            'conflict.message': '{time}에 다른 기기에서 이 노트가 저장되었습니다. 내 버전으로 덮어쓰시겠습니까?',
+            'conflict.overwrite': '덮어쓰기',
+            'conflict.loadTheirs': '다른 버전 열기',
+            'conflict.autoSave': '다른 기기에서 노트가 변경되어 자동 저장을 멈췄습니다',
+            'draft.title': '저장하지 않은 임시본',
+            'draft.restore': '{time}에 저장하지 않은 편집 내용이 있습니다. 복원하시겠습니까?',
+            'draft.restoreStale': '{time}에 저장하지 않은 편집 내용이 있지만, 그 후 노트가 저장되었습니다. 복원하시겠습니까?',
+            'draft.restoreButton': '복원',
+            'draft.discardButton': '버리기',
+            'lock.editedBy': '{user} 님이 편집 중',
+            'lock.editedElsewhere': '다른 세션에서 편집 중',
             'confirm.unsavedChanges': '저장되지 않은 변경사항이 있습니다. 닫으시겠습니까?',
             'confirm.discardChanges': '저장되지 않은 변경사항이 있습니다. 변경사항을 취소하시겠습니까?',
 
@@ -946,6 +1126,13 @@ const i18n = {
             'context.edit': '편집',
             'context.rename': '이름 변경',
             'context.duplicate': '복제',
+            'context.exportHtml': 'HTML로 내보내기',
+            'context.exportPdf': 'PDF로 내보내기',
+            'context.exportSource': '원본 내보내기',
+            'context.archive': '보관',
+            'context.unarchive': '보관 해제',
+            'context.showArchived': '보관된 노트 표시',
+            'context.hideArchived': '보관된 노트 숨기기',
             'context.move': '이동...',
             'context.changeIcon': '아이콘 변경',
             'context.history': '히스토리',
@@ -956,6 +1143,7 @@ const i18n = {
             'context.newFolder': '새 폴더',
             'context.newNoteInFolder': '여기에 새 노트',
             'context.newSubfolder': '하위 폴더',
+            'context.clipPage': '웹 페이지 저장',
             'context.expand': '펼치기',
             'context.expandAll': '모두 펼치기',
             'context.collapse': '닫기',
@@ -963,6 +1151,9 @@ const i18n = {
             'context.renameFolder': '폴더 이름 변경',
             'context.moveFolderUp': '위로 이동',
             'context.moveFolderDown': '아래로 이동',
+            'context.sortByTitle': '노트를 제목순 정렬',
+            'context.sortByModified': '노트를 수정일순 정렬',
+            'context.sortByCreated': '노트를 생성일순 정렬',
             'context.shareFolder': '폴더 공유',
             'context.exportFolder': '폴더 내보내기',
             'context.importToFolder': '폴더로 가져오기',
@@ -994,6 +1185,7 @@ const i18n = {
             'noteInfo.none': '없음',
             'noteInfo.encrypted': '암호화',
             'noteInfo.shared': '공유',
+            'noteInfo.backlinks': '백링크',
             'noteInfo.yes': '예',
             'noteInfo.no': '아니오',
             'noteInfo.created': '생성일',
@@ -1039,6 +1231,9 @@ const i18n = {
 
             // Toast
             'toast.noteDecrypted': '노트 암호화가 해제되었습니다',
+            'toast.noteArchived': '노트를 보관했습니다',
+            'toast.exportFailed': '노트를 내보내지 못했습니다',
+            'toast.noteUnarchived': '노트를 보관 해제했습니다',
             'toast.codeCopied': '코드가 복사되었습니다!',
             'toast.copyFailed': '복사 실패',
 
@@ -1061,6 +1256,23 @@ const i18n = {
 
             // Attachment
             'attachment.removeConfirm': '이 첨부 파일을 삭제하시겠습니까?',
+            'attachment.rename': '이름 변경',
+            'attachment.renamePrompt': '첨부 파일에 표시할 이름:',
+            'attachment.urlPrompt': '첨부할 이미지나 파일의 URL:',
+            'clip.urlPrompt': '노트로 저장할 페이지의 URL:',
+            'clip.clipping': '페이지를 가져오는 중...',
+            'clip.clipped': '페이지를 저장했습니다',
+            'clip.failed': '페이지를 저장하지 못했습니다',
+            'offline.showingOffline': '오프라인: 저장된 노트를 표시합니다',
+            'offline.savedOffline': '오프라인으로 저장했습니다. 연결되면 동기화됩니다',
+            'offline.synced': '오프라인 변경 사항을 동기화했습니다',
+            'offline.syncedConflicts': '오프라인 변경 사항을 동기화했습니다. 그사이 변경된 노트 {count}개는 사본으로 저장했습니다',
+            'offline.syncFailed': '오프라인 변경 사항 {count}개를 동기화하지 못했습니다',
+            'attachment.renamed': '첨부 파일 이름이 변경되었습니다',
+            'attachment.renameFailed': '첨부 파일 이름을 변경하지 못했습니다',
+            'attachment.uploadVersion': '새 버전 업로드',
+            'attachment.versionUploaded': '새 버전이 업로드되었습니다 (이전 버전 보관)',
+            'attachment.uploadVersionFailed': '새 버전을 업로드하지 못했습니다',
             'attachment.linkInContentWarning': '\n\n본문에서 {count}번 참조되고 있습니다. 참조도 함께 삭제됩니다.',
 
             // Date Notes Panel
@@ -1081,6 +1293,8 @@ const i18n = {
             'tags.addPlaceholder': '태그 추가...',
             'tags.createNew': '새 태그 "{tag}" 만들기',
             'tags.noNotesWithTag': '이 태그가 있는 노트가 없습니다',
+            'tags.exportNotes': '이 노트 내보내기',
+            'tags.exported': '노트 {count}개를 내보냈습니다',
         }
     },
 
@@ -0,0 +1,71 @@
+// Git Notepad service worker
+// Keeps the app shell and static files so the app opens without a connection.
+// Note data is not cached here: the app keeps its own offline bundle (/api/offline)
+// and queues writes made while offline for /api/sync.
+
+const CACHE = 'gitnotepad-v1';
+const scope = new URL(self.registration.scope).pathname; // base path + '/'
+
+self.addEventListener('install', () => {
+    self.skipWaiting();
+});
+
+self.addEventListener('activate', (event) => {
+    event.waitUntil(
+        caches.keys()
+            .then(keys => Promise.all(keys.filter(key => key !== CACHE).map(key => caches.delete(key))))
+            .then(() => self.clients.claim())
+    );
+});
+
+// Logging out removes the cached pages of the user
+self.addEventListener('message', (event) => {
+    if (event.data === 'clear') {
+        event.waitUntil(caches.delete(CACHE));
+    }
+});
+
+self.addEventListener('fetch', (event) => {
+    const request = event.request;
+    if (request.method !== 'GET') return;
+
+    const url = new URL(request.url);
+    if (url.origin !== self.location.origin) return;
+
+    // App page: network first, cached copy when offline
+    if (request.mode === 'navigate' && url.pathname === scope) {
+        event.respondWith(
+            fetch(request)
+                .then(response => {
+                    if (response.ok && !response.redirected) {
+                        const copy = response.clone();
+                        caches.open(CACHE).then(cache => cache.put(scope, copy));
+                    }
+                    return response;
+                })
+                .catch(() => caches.match(scope).then(cached => cached || Response.error()))
+        );
+        return;
+    }
+
+    // Static files: cached copy right away, refreshed in the background
+    if (url.pathname.startsWith(scope + 'static/')) {
+        event.respondWith(
+            caches.open(CACHE).then(cache => cache.match(request).then(cached => {
+                const update = fetch(request)
+                    .then(response => {
+                        if (response.ok) {
+                            cache.put(request, response.clone());
+                        }
+                        return response;
+                    })
+                    .catch(() => cached || Response.error());
+                if (cached) {
+                    event.waitUntil(update);
+                    return cached;
+                }
+                return update;
+            }))
+        );
+    }
+});